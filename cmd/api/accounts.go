@@ -0,0 +1,460 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// createNewAccountHandler() creates a new account for the user. The currency defaults to the
+// user's currency and the opening date to today. A credit card can be linked to the debt tracking
+// the same card so that it is not counted twice in the net worth
+func (app *application) createNewAccountHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name           string           `json:"name"`
+		AccountType    string           `json:"account_type"`
+		CurrencyCode   string           `json:"currency_code"`
+		OpeningBalance decimal.Decimal  `json:"opening_balance"`
+		OpeningDate    data.CustomTime1 `json:"opening_date"`
+		DebtID         int64            `json:"debt_id"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	user := app.contextGetUser(r)
+	if input.CurrencyCode == "" {
+		input.CurrencyCode = user.CurrencyCode
+	}
+	if input.OpeningDate.IsZero() {
+		input.OpeningDate.Time = time.Now().UTC()
+	}
+	account := &data.Account{
+		Name:           input.Name,
+		AccountType:    database.AccountTypeEnum(input.AccountType),
+		CurrencyCode:   input.CurrencyCode,
+		OpeningBalance: input.OpeningBalance,
+		OpeningDate:    input.OpeningDate.ToTime(),
+		DebtID:         input.DebtID,
+	}
+	// validate the account
+	v := validator.New()
+	if data.ValidateAccount(v, account); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if err := app.validateAccountDebtHelper(v, user.ID, account.DebtID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	} else if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// check if provided currency code is supported
+	if err := app.verifyCurrencyInRedis(account.CurrencyCode); err != nil {
+		v.AddError("currency_code", "currency code is not supported")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	err = app.models.AccountManager.CreateNewAccount(user.ID, account)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateAccountName):
+			v.AddError("name", "an account with this name already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrDuplicateAccountDebt):
+			v.AddError("debt_id", "this debt is already linked to another account")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"account": account}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getAccountsHandler() returns all of the user's accounts with their balances
+func (app *application) getAccountsHandler(w http.ResponseWriter, r *http.Request) {
+	accounts, err := app.models.AccountManager.GetAccountsByUserID(app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"accounts": accounts}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateAccountHandler() updates an account's name, type, opening balance or opening date.
+// The currency can not be changed as every transaction on the account is posted in it
+func (app *application) updateAccountHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, err := app.readIDParam(r, "accountID")
+	if err != nil || accountID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	user := app.contextGetUser(r)
+	account, transactions, err := app.getAccountRegisterHelper(user.ID, accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	var input struct {
+		Name           *string              `json:"name"`
+		AccountType    *string              `json:"account_type"`
+		OpeningBalance *decimal.Decimal     `json:"opening_balance"`
+		OpeningDate    *data.CustomTime1    `json:"opening_date"`
+		DebtID         data.Optional[int64] `json:"debt_id"`
+	}
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if input.Name != nil {
+		account.Name = *input.Name
+	}
+	if input.AccountType != nil {
+		account.AccountType = database.AccountTypeEnum(*input.AccountType)
+	}
+	if input.OpeningBalance != nil {
+		account.OpeningBalance = *input.OpeningBalance
+	}
+	if input.OpeningDate != nil {
+		account.OpeningDate = input.OpeningDate.ToTime()
+	}
+	// a null debt_id unlinks the debt
+	if input.DebtID.Set {
+		account.DebtID = input.DebtID.Value
+	}
+	// validate the account
+	v := validator.New()
+	if data.ValidateAccount(v, account); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if err := app.validateAccountDebtHelper(v, user.ID, account.DebtID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	} else if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	err = app.models.AccountManager.UpdateAccountByID(user.ID, account)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateAccountName):
+			v.AddError("name", "an account with this name already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrDuplicateAccountDebt):
+			v.AddError("debt_id", "this debt is already linked to another account")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// the opening balance may have moved every balance on the account
+	data.BuildAccountRegister(account, transactions)
+	err = app.writeJSON(w, http.StatusOK, envelope{"account": account}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteAccountHandler() deletes one of the user's accounts together with its transfers.
+// Transactions on the account are kept but no longer belong to an account
+func (app *application) deleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, err := app.readIDParam(r, "accountID")
+	if err != nil || accountID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	_, err = app.models.AccountManager.DeleteAccountByID(app.contextGetUser(r).ID, accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "account deleted successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getAccountTransactionsHandler() returns an account's register, every transaction posted to it
+// with the running balance after each one. The register can be narrowed down with start_date and
+// end_date (YYYY-MM-DD), the running balances still count everything before the start date
+func (app *application) getAccountTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, err := app.readIDParam(r, "accountID")
+	if err != nil || accountID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	v := validator.New()
+	qs := r.URL.Query()
+	startDate := app.readDate(qs, "start_date", time.Time{}, v)
+	endDate := app.readDate(qs, "end_date", time.Time{}, v)
+	v.Check(startDate.IsZero() || endDate.IsZero() || !endDate.Before(startDate), "end_date", "must not be before the start date")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	account, transactions, err := app.getAccountRegisterHelper(app.contextGetUser(r).ID, accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{
+		"account":      account,
+		"transactions": data.AccountTransactionsBetween(transactions, startDate, endDate),
+	}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// reconcileAccountHandler() reconciles an account against a statement. Every transaction dated on
+// or before the statement date is marked as cleared, which only happens when the cleared balance
+// matches the statement balance. When it does not nothing is cleared and the difference is returned
+func (app *application) reconcileAccountHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, err := app.readIDParam(r, "accountID")
+	if err != nil || accountID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	var input struct {
+		StatementDate    data.CustomTime1 `json:"statement_date"`
+		StatementBalance decimal.Decimal  `json:"statement_balance"`
+	}
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	// validate the statement
+	v := validator.New()
+	if data.ValidateAccountReconciliation(v, input.StatementDate.ToTime()); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	user := app.contextGetUser(r)
+	account, _, reconciliation, err := app.models.AccountManager.ReconcileAccount(user.ID, accountID, input.StatementDate.ToTime(), input.StatementBalance)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if !reconciliation.Reconciled {
+		v.AddError("statement_balance", fmt.Sprintf("does not match the cleared balance of %s, a difference of %s",
+			reconciliation.ClearedBalance.StringFixed(2), reconciliation.Difference.StringFixed(2)))
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"account": account, "reconciliation": reconciliation}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// createAccountTransferHandler() moves money between two of the user's accounts. When the accounts
+// are in different currencies the amount is converted at the rate of the transfer date, unless the
+// amount that actually arrived is provided as to_amount
+func (app *application) createAccountTransferHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		FromAccountID int64            `json:"from_account_id"`
+		ToAccountID   int64            `json:"to_account_id"`
+		Amount        decimal.Decimal  `json:"amount"`
+		ToAmount      *decimal.Decimal `json:"to_amount"`
+		Description   string           `json:"description"`
+		TransferDate  data.CustomTime1 `json:"transfer_date"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	if input.TransferDate.IsZero() {
+		input.TransferDate.Time = time.Now().UTC()
+	}
+	transfer := &data.AccountTransfer{
+		FromAccountID: input.FromAccountID,
+		ToAccountID:   input.ToAccountID,
+		Amount:        input.Amount,
+		Description:   input.Description,
+		TransferDate:  input.TransferDate.ToTime(),
+	}
+	// validate the transfer
+	v := validator.New()
+	if data.ValidateAccountTransfer(v, transfer); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	user := app.contextGetUser(r)
+	fromAccount, err := app.models.AccountManager.GetAccountByID(user.ID, transfer.FromAccountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("from_account_id", "account not found")
+		default:
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+	toAccount, err := app.models.AccountManager.GetAccountByID(user.ID, transfer.ToAccountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("to_account_id", "account not found")
+		default:
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// work out what arrives in the destination account
+	transfer.ToAmount = transfer.Amount
+	transfer.ExchangeRate = decimal.NewFromInt(1)
+	if fromAccount.CurrencyCode != toAccount.CurrencyCode {
+		if input.ToAmount != nil {
+			if data.ValidateAmount(v, *input.ToAmount, "to_amount"); !v.Valid() {
+				app.failedValidationResponse(w, r, v.Errors)
+				return
+			}
+			transfer.ToAmount = *input.ToAmount
+			transfer.ExchangeRate = transfer.ToAmount.Div(transfer.Amount).Round(6)
+		} else {
+			exchangeRate, err := app.convertAndGetExchangeRateOnDate(fromAccount.CurrencyCode, toAccount.CurrencyCode, transfer.TransferDate)
+			if err != nil {
+				v.AddError("to_account_id", "could not convert currency")
+				app.failedValidationResponse(w, r, v.Errors)
+				return
+			}
+			transfer.ToAmount = exchangeRate.ConvertAmount(transfer.Amount).ConvertedAmount.Round(2)
+			transfer.ExchangeRate = exchangeRate.ConversionRate
+		}
+	}
+	err = app.models.AccountManager.CreateNewAccountTransfer(user.ID, transfer)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"transfer": transfer}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteAccountTransferHandler() deletes one of the user's transfers, taking it off both accounts
+func (app *application) deleteAccountTransferHandler(w http.ResponseWriter, r *http.Request) {
+	transferID, err := app.readIDParam(r, "transferID")
+	if err != nil || transferID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	_, err = app.models.AccountManager.DeleteAccountTransferByID(app.contextGetUser(r).ID, transferID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "transfer deleted successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getAccountRegisterHelper() returns one of the user's accounts together with its transactions,
+// with the running balances and the account's balances filled in
+func (app *application) getAccountRegisterHelper(userID, accountID int64) (*data.Account, []*data.AccountTransaction, error) {
+	account, err := app.models.AccountManager.GetAccountByID(userID, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactions, err := app.models.AccountManager.GetAccountTransactionsByAccountID(account.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	data.BuildAccountRegister(account, transactions)
+	return account, transactions, nil
+}
+
+// validateAccountHelper() checks that a transaction can be posted to an account, the account must
+// belong to the user and be in the transaction's currency. An account ID of 0 means no account
+func (app *application) validateAccountHelper(v *validator.Validator, userID, accountID int64, currencyCode string) error {
+	if accountID == 0 {
+		return nil
+	}
+	if data.ValidateURLID(v, accountID, "account_id"); !v.Valid() {
+		return nil
+	}
+	account, err := app.models.AccountManager.GetAccountByID(userID, accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("account_id", "account not found")
+			return nil
+		default:
+			return err
+		}
+	}
+	data.ValidateAccountCurrency(v, account, currencyCode, "account_id")
+	return nil
+}
+
+// validateAccountDebtHelper() checks that the debt linked to an account belongs to the user.
+// A debt ID of 0 means no debt
+func (app *application) validateAccountDebtHelper(v *validator.Validator, userID, debtID int64) error {
+	if debtID == 0 {
+		return nil
+	}
+	_, err := app.models.FinancialTrackingManager.GetDebtByID(userID, debtID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("debt_id", "debt not found")
+			return nil
+		default:
+			return err
+		}
+	}
+	return nil
+}
@@ -155,6 +155,36 @@ func (app *application) buildOCRRecieptAnalysisLLMRequest(ocrAnalysis *data.OCRR
 	return app.buildLLMRequestHelper(ocrAnalysis, instructions)
 }
 
+// buildOCRRecieptExpenseDraftLLMRequest sends the OCR data of a receipt to the LLM API and asks
+// for the fields we need to draft an expense. The keys match data.ParseReceiptAnalysis().
+func (app *application) buildOCRRecieptExpenseDraftLLMRequest(ocrAnalysis *data.OCRResponse) (*data.LLMAnalyzedPortfolio, error) {
+	instructions := `
+{
+  "messages": [
+    {
+      "role": "system",
+      "content": "You are tasked with extracting the details of a purchase receipt from OCR-processed text into a structured JSON format. The JSON output must contain exactly these fields: merchant (the store or business name), date (the purchase date formatted as YYYY-MM-DD), total (the final amount paid as a number without currency symbols), currency (the ISO 4217 currency code such as USD, EUR or KES), and line_items (an array of objects with description, quantity and amount, where amount is the line total as a number). Use null for any field that is missing or unclear. If the text is not a receipt or cannot be read, include an 'error' key describing the issue. Avoid generating any code."
+    },
+    {
+      "role": "user",
+      "content": "Extract the merchant, date, total, currency and line items from the following OCR processed receipt and return them in a JSON code block."
+    },
+    {
+      "role": "user",
+      "content": %s
+    }
+  ],
+  "stop": ["<|eot_id|>"],
+  "model": "Meta-Llama-3.1-405B-Instruct",
+  "stream": true,
+  "stream_options": {
+    "include_usage": true
+  }
+}
+`
+	return app.buildLLMRequestHelper(ocrAnalysis, instructions)
+}
+
 // buildLLMRequestHelper builds and sends the LLM request for analysis
 func (app *application) buildLLMRequestHelper(profile interface{}, instructionsTemplate string) (*data.LLMAnalyzedPortfolio, error) {
 	// Marshal the profile data
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"go.uber.org/zap"
+)
+
+// getSpendingAnomaliesHandler() returns the spending anomalies found over the last number of days
+// (defaults to 30). The list can be narrowed down to a single kind i.e unusual_transaction,
+// duplicate_charge or category_spike
+func (app *application) getSpendingAnomaliesHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+	kind := app.readString(qs, "kind", "")
+	days := app.readInt(qs, "days", data.DefaultAnomalyListDays, v)
+	if data.ValidateAnomalyFilters(v, kind, days); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	anomalies, err := app.models.AnomalyManager.GetSpendingAnomaliesByUserID(app.contextGetUser(r).ID, since, kind)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"anomalies": anomalies}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// scanSpendingAnomaliesHandler() checks the user's recent expenses for anomalies straight away instead
+// of waiting for the daily scan, and returns the anomalies that had not been found before
+func (app *application) scanSpendingAnomaliesHandler(w http.ResponseWriter, r *http.Request) {
+	anomalies, err := app.detectSpendingAnomaliesHelper(app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"anomalies": anomalies}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// detectSpendingAnomaliesHelper() runs anomaly detection over the user's recent expenses and saves
+// the results. The user is notified about each anomaly the first time it is found, which are returned
+func (app *application) detectSpendingAnomaliesHelper(userID int64) ([]*data.SpendingAnomaly, error) {
+	now := time.Now().UTC()
+	// the expenses being checked need the full history window before them
+	since := now.AddDate(0, 0, -(data.AnomalyHistoryDays + data.AnomalyEvaluationDays))
+	expenses, err := app.models.AnomalyManager.GetAnomalyExpensesByUserID(userID, since)
+	if err != nil {
+		return nil, err
+	}
+	newAnomalies := []*data.SpendingAnomaly{}
+	for _, anomaly := range data.DetectSpendingAnomalies(expenses, now) {
+		created, err := app.models.AnomalyManager.CreateSpendingAnomaly(userID, anomaly)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			continue
+		}
+		newAnomalies = append(newAnomalies, anomaly)
+		notificationContent := data.NotificationContent{
+			Message: anomaly.Message,
+			Meta: data.NotificationMeta{
+				Url:      "",
+				ImageUrl: "",
+				Tags:     string(anomaly.Kind),
+			},
+		}
+		err = app.PublishNotificationToRedis(userID, data.NotificationTypeSpendingAnomaly, notificationContent)
+		if err != nil {
+			app.logger.Error("Error publishing spending anomaly notification", zap.Int64("anomaly_id", anomaly.ID), zap.Error(err))
+		}
+	}
+	return newAnomalies, nil
+}
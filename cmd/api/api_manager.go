@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Blue-Davinci/OptiVest/internal/data"
 	"github.com/shopspring/decimal"
@@ -60,3 +62,35 @@ func (app *application) convertAndGetExchangeRate(source_currency, target_curren
 
 	return &exchange, nil
 }
+
+// convertAndGetExchangeRateOnDate() gets the exchange rate between the source and target currencies
+// on a given date. It uses the daily rates stored by the trackDailyExchangeRates() scheduler. When no
+// rate is stored for a past date, the day's rates are fetched from the API's history and stored, while
+// dates from today on use the current rate from convertAndGetExchangeRate()
+func (app *application) convertAndGetExchangeRateOnDate(source_currency, target_currency string, date time.Time) (*data.ExchangeRateResponse, error) {
+	if source_currency == "" || target_currency == "" {
+		return nil, data.ErrorEmptyCurrency
+	}
+	rate, err := app.models.ExchangeRateManager.GetExchangeRateOnDate(app.config.api.defaultcurrency, source_currency, target_currency, date)
+	if errors.Is(err, data.ErrGeneralRecordNotFound) {
+		now := time.Now().UTC()
+		if !date.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+			return app.convertAndGetExchangeRate(source_currency, target_currency)
+		}
+		app.logger.Info("No stored exchange rate for date, fetching the day's rates", zap.String("source_currency", source_currency),
+			zap.String("target_currency", target_currency), zap.Time("date", date))
+		err = app.getAndSaveHistoricalExchangeRates(date)
+		if err != nil {
+			return nil, err
+		}
+		rate, err = app.models.ExchangeRateManager.GetExchangeRateOnDate(app.config.api.defaultcurrency, source_currency, target_currency, date)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &data.ExchangeRateResponse{
+		ConversionRate: rate,
+		BaseCode:       source_currency,
+		TargetCode:     target_currency,
+	}, nil
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/storage"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"go.uber.org/zap"
+)
+
+// uploadExpenseAttachmentHandler() uploads a receipt (image or PDF) and links it to one of
+// the user's expenses. The file is sent as multipart/form-data under the "file" field.
+// We return the saved attachment together with a signed download URL
+func (app *application) uploadExpenseAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	// get the expense ID from the URL
+	expenseID, err := app.readIDParam(r, "expenseID")
+	if err != nil || expenseID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	user := app.contextGetUser(r)
+	// make sure the expense exists and belongs to the user
+	_, err = app.models.FinancialTrackingManager.GetExpenseByID(user.ID, expenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	attachment := &data.ExpenseAttachment{ExpenseID: expenseID}
+	app.handleAttachmentUpload(w, r, user.ID, data.ExpenseAttachmentStoragePrefix, attachment)
+}
+
+// getExpenseAttachmentsHandler() returns all the attachments of a user's expense, each with
+// a download URL that is valid for the configured signed URL expiry
+func (app *application) getExpenseAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	// get the expense ID from the URL
+	expenseID, err := app.readIDParam(r, "expenseID")
+	if err != nil || expenseID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	user := app.contextGetUser(r)
+	// make sure the expense exists and belongs to the user
+	_, err = app.models.FinancialTrackingManager.GetExpenseByID(user.ID, expenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// get the attachments
+	attachments, err := app.models.AttachmentManager.GetExpenseAttachmentsByExpenseID(user.ID, expenseID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.signAttachmentURLs(attachments...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"attachments": attachments}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// uploadGroupExpenseAttachmentHandler() uploads a receipt for a group expense.
+// Only the member who recorded the expense can attach files to it, and only while their role in
+// the group still lets them add expenses
+func (app *application) uploadGroupExpenseAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group expense ID from the URL
+	groupExpenseID, err := app.readIDParam(r, "groupExpenseID")
+	if err != nil || groupExpenseID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	user := app.contextGetUser(r)
+	// get the group expense
+	groupExpense, err := app.models.FinancialGroupManager.GetGroupExpenseByID(groupExpenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// check the user may still add expenses to the group, archived groups are read-only
+	if !app.requireGroupPermission(w, r, groupExpense.GroupID, data.GroupPermissionAddExpense) {
+		return
+	}
+	// only the creator of the expense can attach files
+	if groupExpense.MemberID != user.ID {
+		app.errorResponse(w, r, http.StatusForbidden, "only the member who recorded this expense can attach files to it")
+		return
+	}
+	attachment := &data.ExpenseAttachment{GroupExpenseID: groupExpenseID}
+	app.handleAttachmentUpload(w, r, user.ID, data.GroupExpenseAttachmentStoragePrefix, attachment)
+}
+
+// getGroupExpenseAttachmentsHandler() returns the attachments of a group expense.
+// Any accepted member of the group can view them
+func (app *application) getGroupExpenseAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group expense ID from the URL
+	groupExpenseID, err := app.readIDParam(r, "groupExpenseID")
+	if err != nil || groupExpenseID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	// get the group expense
+	groupExpense, err := app.models.FinancialGroupManager.GetGroupExpenseByID(groupExpenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// check that the user is a member of the group, non members just get a not found
+	if !app.requireGroupPermission(w, r, groupExpense.GroupID, data.GroupPermissionViewGroup) {
+		return
+	}
+	// get the attachments
+	attachments, err := app.models.AttachmentManager.GetExpenseAttachmentsByGroupExpenseID(groupExpenseID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.signAttachmentURLs(attachments...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"attachments": attachments}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteExpenseAttachmentHandler() deletes an attachment uploaded by the user, this works for
+// both expense and group expense attachments. The stored file is removed as well.
+// Attachments on the expenses of an archived group cannot be deleted
+func (app *application) deleteExpenseAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	// get the attachment ID from the URL
+	attachmentID, err := app.readIDParam(r, "attachmentID")
+	if err != nil || attachmentID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	// attachments on the expenses of an archived group are read-only
+	attachment, err := app.models.AttachmentManager.GetExpenseAttachmentByID(app.contextGetUser(r).ID, attachmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if attachment.GroupExpenseID != 0 && !app.requireGroupExpenseOpen(w, r, attachment.GroupExpenseID) {
+		return
+	}
+	// delete the attachment record
+	storageKey, err := app.models.AttachmentManager.DeleteExpenseAttachmentByID(app.contextGetUser(r).ID, attachmentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// remove the file, the record is already gone so a failure here is only logged
+	err = app.storage.Delete(r.Context(), storageKey)
+	if err != nil {
+		app.logger.Error("unable to delete attachment from storage", zap.String("key", storageKey), zap.Error(err))
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "attachment deleted successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// requireGroupExpenseOpen() checks that the group a group expense belongs to is not archived and
+// sends a 409 when it is. It reports whether the handler can go on
+func (app *application) requireGroupExpenseOpen(w http.ResponseWriter, r *http.Request, groupExpenseID int64) bool {
+	groupExpense, err := app.models.FinancialGroupManager.GetGroupExpenseByID(groupExpenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return false
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(groupExpense.GroupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return false
+	}
+	if group.IsArchived() {
+		app.groupArchivedResponse(w, r)
+		return false
+	}
+	return true
+}
+
+// downloadAttachmentHandler() serves files for the local storage backend. The URL is created by
+// LocalStorage.SignedURL() so no authentication is needed, the signature and expiry are checked instead.
+// For the S3 backend download URLs point straight to the bucket and this route is never used.
+func (app *application) downloadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	localStorage, ok := app.storage.(*storage.LocalStorage)
+	if !ok {
+		app.notFoundResponse(w, r)
+		return
+	}
+	qs := r.URL.Query()
+	key := app.readString(qs, "key", "")
+	// verify the signature
+	err := localStorage.VerifySignedURL(key, app.readString(qs, "expires", ""), app.readString(qs, "signature", ""))
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrSignedURLExpired):
+			app.errorResponse(w, r, http.StatusForbidden, "this download link has expired")
+		default:
+			app.errorResponse(w, r, http.StatusForbidden, "invalid download link")
+		}
+		return
+	}
+	// open the file
+	file, err := localStorage.Get(r.Context(), key)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrObjectNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	defer file.Close()
+	// the content type is derived from the extension of the key
+	w.Header().Set("Cache-Control", "private, no-store")
+	if seeker, ok := file.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, path.Base(key), time.Time{}, seeker)
+		return
+	}
+	_, err = io.Copy(w, file)
+	if err != nil {
+		app.logger.Error("unable to stream attachment", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// handleAttachmentUpload() reads the uploaded file, stores it and saves the attachment record.
+// The attachment should already have its expense or group expense ID set by the caller
+func (app *application) handleAttachmentUpload(w http.ResponseWriter, r *http.Request, userID int64, prefix string, attachment *data.ExpenseAttachment) {
+	// read the file
+	file, header, contentType, err := app.readMultipartFile(w, r, "file", app.config.storage.maxuploadsize)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	defer file.Close()
+	defer r.MultipartForm.RemoveAll()
+	attachment.FileName = header.Filename
+	attachment.ContentType = contentType
+	attachment.SizeBytes = header.Size
+	// validate the attachment
+	v := validator.New()
+	if data.ValidateExpenseAttachment(v, attachment, app.config.storage.maxuploadsize); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// save the file and the record
+	err = app.saveExpenseAttachment(r.Context(), userID, prefix, file, attachment)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"attachment": attachment}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// saveExpenseAttachment() puts the file in storage under a freshly generated key and saves
+// the attachment record. If saving the record fails the stored file is removed again.
+// On success the attachment is enriched with a signed download URL
+func (app *application) saveExpenseAttachment(ctx context.Context, userID int64, prefix string, file multipart.File, attachment *data.ExpenseAttachment) error {
+	storageKey, err := storage.GenerateObjectKey(prefix, userID, data.AllowedAttachmentContentTypes[attachment.ContentType])
+	if err != nil {
+		return err
+	}
+	err = app.storage.Put(ctx, storageKey, file, attachment.SizeBytes, attachment.ContentType)
+	if err != nil {
+		return err
+	}
+	attachment.StorageKey = storageKey
+	err = app.models.AttachmentManager.CreateNewExpenseAttachment(userID, attachment)
+	if err != nil {
+		// do not leave an orphaned file behind
+		if deleteErr := app.storage.Delete(ctx, storageKey); deleteErr != nil {
+			app.logger.Error("unable to delete orphaned attachment", zap.String("key", storageKey), zap.Error(deleteErr))
+		}
+		return err
+	}
+	return app.signAttachmentURLs(attachment)
+}
+
+// signAttachmentURLs() sets the download URL of each attachment
+func (app *application) signAttachmentURLs(attachments ...*data.ExpenseAttachment) error {
+	for _, attachment := range attachments {
+		downloadURL, err := app.storage.SignedURL(attachment.StorageKey, app.config.storage.signedurlexpiry)
+		if err != nil {
+			return err
+		}
+		attachment.DownloadURL = downloadURL
+	}
+	return nil
+}
+
+// deleteStoredAttachments() removes the files of attachments whose records have already been
+// deleted, i.e after their expense was deleted. This runs in the background and only logs failures
+func (app *application) deleteStoredAttachments(attachments []*data.ExpenseAttachment) {
+	if len(attachments) == 0 {
+		return
+	}
+	app.background(func() {
+		for _, attachment := range attachments {
+			err := app.storage.Delete(context.Background(), attachment.StorageKey)
+			if err != nil {
+				app.logger.Error("unable to delete attachment from storage", zap.String("key", attachment.StorageKey), zap.Error(err))
+			}
+		}
+	})
+}
@@ -0,0 +1,579 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// batchInput is the body of a batch request, the mode defaults to all or nothing
+type batchInput[T any] struct {
+	Mode  string `json:"mode"`
+	Items []T    `json:"items"`
+}
+
+// batchDeleteItem is a single item of a batch delete
+type batchDeleteItem struct {
+	ID int64 `json:"id"`
+}
+
+// batchExpenseUpdate is a single item of a batch expense update, fields left out keep their value
+type batchExpenseUpdate struct {
+	ID          int64            `json:"id"`
+	Amount      *decimal.Decimal `json:"amount"`
+	Name        *string          `json:"name"`
+	Category    *string          `json:"category"`
+	Description *string          `json:"description"`
+	DateOcurred *time.Time       `json:"date_occurred"`
+	AccountID   *int64           `json:"account_id"`
+}
+
+// batchIncomeUpdate is a single item of a batch income update
+type batchIncomeUpdate struct {
+	ID int64 `json:"id"`
+	incomeUpdateInput
+}
+
+// batchBudget keeps track of the surplus of a budget that is left while the items of a batch are checked
+type batchBudget struct {
+	budget  *data.Budget
+	surplus decimal.Decimal
+}
+
+// batchCreateExpensesHandler() creates up to data.MaxBatchItems one way expenses in a single request.
+// Each item is checked like a single expense, the surplus of a budget goes down with every expense
+// of the batch that is charged to it so a strict budget cannot be overspent by splitting a batch up
+func (app *application) batchCreateExpensesHandler(w http.ResponseWriter, r *http.Request) {
+	message := data.Warning_Messages
+	input, ok := readBatchHelper[struct {
+		BudgetID    int64           `json:"budget_id"`
+		Name        string          `json:"name"`
+		Category    string          `json:"category"`
+		Amount      decimal.Decimal `json:"amount"`
+		Description string          `json:"description"`
+		DateOcurred time.Time       `json:"date_occurred"`
+		AccountID   int64           `json:"account_id"`
+	}](app, w, r)
+	if !ok {
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	budgets := make(map[int64]*batchBudget)
+	operations := make([]data.BatchOperation, len(input.Items))
+	records := make([]any, len(input.Items))
+	for i, item := range input.Items {
+		expense := &data.Expense{
+			UserID:       user.ID,
+			BudgetID:     item.BudgetID,
+			Name:         item.Name,
+			Category:     item.Category,
+			Amount:       item.Amount,
+			IsRecurring:  false,
+			Description:  item.Description,
+			DateOccurred: item.DateOcurred,
+			AccountID:    item.AccountID,
+		}
+		itemV := validator.New()
+		data.ValidateExpense(itemV, expense)
+		budget, err := app.batchBudgetHelper(itemV, user.ID, expense.BudgetID, budgets)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if itemV.Valid() {
+			err = app.validateAccountHelper(itemV, user.ID, expense.AccountID, budget.budget.CurrencyCode)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+		if itemV.Valid() && expense.Amount.Cmp(budget.surplus) > 0 {
+			if budget.budget.IsStrict {
+				itemV.AddError("amount", "expense amount is more than the available surplus")
+			} else {
+				message.Message = append(message.Message, fmt.Sprintf("%s: expense amount is more than the available surplus", data.BatchItemKey(i, "")))
+			}
+		}
+		if !itemV.Valid() {
+			data.AddBatchItemErrors(v, i, itemV.Errors)
+			continue
+		}
+		budget.surplus = budget.surplus.Sub(expense.Amount)
+		records[i] = expense
+		operations[i] = func(q *data.BatchQueries) error {
+			return q.FinancialTrackingManager.CreateNewExpense(user.ID, expense)
+		}
+	}
+	app.runBatchHelper(w, r, input.Mode, v, operations, records, http.StatusCreated, envelope{"warnings": message})
+}
+
+// batchUpdateExpensesHandler() updates up to data.MaxBatchItems expenses in a single request.
+// The amount of an updated expense is first given back to its budget's surplus before the new amount
+// is checked against it, the same way a single update is checked
+func (app *application) batchUpdateExpensesHandler(w http.ResponseWriter, r *http.Request) {
+	message := data.Warning_Messages
+	input, ok := readBatchHelper[batchExpenseUpdate](app, w, r)
+	if !ok {
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	budgets := make(map[int64]*batchBudget)
+	seen := make(map[int64]bool)
+	operations := make([]data.BatchOperation, len(input.Items))
+	records := make([]any, len(input.Items))
+	for i, item := range input.Items {
+		itemV := validator.New()
+		expense, err := app.models.FinancialTrackingManager.GetExpenseByID(user.ID, item.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrGeneralRecordNotFound):
+				v.AddError(data.BatchItemKey(i, "id"), "record not found")
+				continue
+			default:
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+		if seen[item.ID] {
+			v.AddError(data.BatchItemKey(i, "id"), "is already in the batch")
+			continue
+		}
+		seen[item.ID] = true
+		budget, err := app.batchBudgetHelper(itemV, user.ID, expense.BudgetID, budgets)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if !itemV.Valid() {
+			data.AddBatchItemErrors(v, i, itemV.Errors)
+			continue
+		}
+		// pretend the current expense doesn't exist for a moment
+		available := budget.surplus.Add(expense.Amount)
+		if item.Amount != nil {
+			if item.Amount.GreaterThan(available) {
+				if budget.budget.IsStrict {
+					itemV.AddError("amount", "expense amount is more than the available surplus")
+				} else {
+					message.Message = append(message.Message, fmt.Sprintf("%s: expense amount is more than the available surplus", data.BatchItemKey(i, "")))
+				}
+			}
+			expense.Amount = *item.Amount
+		}
+		if item.Category != nil {
+			expense.Category = *item.Category
+		}
+		if item.Name != nil {
+			expense.Name = *item.Name
+		}
+		if item.Description != nil {
+			expense.Description = *item.Description
+		}
+		if item.DateOcurred != nil {
+			expense.DateOccurred = *item.DateOcurred
+		}
+		if item.AccountID != nil && *item.AccountID != expense.AccountID {
+			expense.AccountID = *item.AccountID
+			expense.IsCleared = false
+		}
+		if data.ValidateExpense(itemV, expense); itemV.Valid() && item.AccountID != nil {
+			err = app.validateAccountHelper(itemV, user.ID, expense.AccountID, budget.budget.CurrencyCode)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+		if !itemV.Valid() {
+			data.AddBatchItemErrors(v, i, itemV.Errors)
+			continue
+		}
+		budget.surplus = available.Sub(expense.Amount)
+		records[i] = expense
+		operations[i] = func(q *data.BatchQueries) error {
+			return q.FinancialTrackingManager.UpdateExpenseByID(user.ID, expense)
+		}
+	}
+	app.runBatchHelper(w, r, input.Mode, v, operations, records, http.StatusOK, envelope{"warnings": message})
+}
+
+// batchDeleteExpensesHandler() deletes up to data.MaxBatchItems expenses in a single request.
+// The stored files of their attachments are only removed once the deletions are committed
+func (app *application) batchDeleteExpensesHandler(w http.ResponseWriter, r *http.Request) {
+	input, ok := readBatchHelper[batchDeleteItem](app, w, r)
+	if !ok {
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	attachments := make([][]*data.ExpenseAttachment, len(input.Items))
+	operations := make([]data.BatchOperation, len(input.Items))
+	records := make([]any, len(input.Items))
+	for i, item := range input.Items {
+		itemV := validator.New()
+		if data.ValidateURLID(itemV, item.ID, "id"); !itemV.Valid() {
+			data.AddBatchItemErrors(v, i, itemV.Errors)
+			continue
+		}
+		// get the attachments before they are cascaded away
+		expenseAttachments, err := app.models.AttachmentManager.GetExpenseAttachmentsByExpenseID(user.ID, item.ID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		attachments[i] = expenseAttachments
+		records[i] = item.ID
+		operations[i] = func(q *data.BatchQueries) error {
+			_, err := q.FinancialTrackingManager.DeleteExpenseByID(user.ID, item.ID)
+			return err
+		}
+	}
+	results := app.runBatchHelper(w, r, input.Mode, v, operations, records, http.StatusOK, nil)
+	for _, result := range results {
+		if result.Succeeded {
+			app.deleteStoredAttachments(attachments[result.Index])
+		}
+	}
+}
+
+// batchCreateIncomesHandler() creates up to data.MaxBatchItems incomes in a single request.
+// Incomes in another currency are converted at the rate of the day they were received
+func (app *application) batchCreateIncomesHandler(w http.ResponseWriter, r *http.Request) {
+	input, ok := readBatchHelper[struct {
+		Source       string           `json:"source"`
+		CurrencyCode string           `json:"currency_code"`
+		Amount       decimal.Decimal  `json:"amount_original"`
+		Description  string           `json:"description"`
+		DateReceived data.CustomTime1 `json:"date_received"`
+		AccountID    int64            `json:"account_id"`
+	}](app, w, r)
+	if !ok {
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	operations := make([]data.BatchOperation, len(input.Items))
+	records := make([]any, len(input.Items))
+	for i, item := range input.Items {
+		income := &data.Income{
+			UserID:               user.ID,
+			Source:               item.Source,
+			OriginalCurrencyCode: item.CurrencyCode,
+			AmountOriginal:       item.Amount,
+			Description:          item.Description,
+			DateReceived:         item.DateReceived.Time,
+			AccountID:            item.AccountID,
+		}
+		itemV := validator.New()
+		if app.convertIncomeHelper(itemV, user.CurrencyCode, income); itemV.Valid() {
+			data.ValidateIncome(itemV, income)
+		}
+		if itemV.Valid() {
+			err := app.validateAccountHelper(itemV, user.ID, income.AccountID, income.OriginalCurrencyCode)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+		if !itemV.Valid() {
+			data.AddBatchItemErrors(v, i, itemV.Errors)
+			continue
+		}
+		records[i] = income
+		operations[i] = func(q *data.BatchQueries) error {
+			return q.FinancialTrackingManager.CreateNewIncome(user.ID, income)
+		}
+	}
+	app.runBatchHelper(w, r, input.Mode, v, operations, records, http.StatusCreated, nil)
+}
+
+// batchUpdateIncomesHandler() updates up to data.MaxBatchItems incomes in a single request,
+// following the same rules as a single update
+func (app *application) batchUpdateIncomesHandler(w http.ResponseWriter, r *http.Request) {
+	input, ok := readBatchHelper[batchIncomeUpdate](app, w, r)
+	if !ok {
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	seen := make(map[int64]bool)
+	operations := make([]data.BatchOperation, len(input.Items))
+	records := make([]any, len(input.Items))
+	for i, item := range input.Items {
+		income, err := app.models.FinancialTrackingManager.GetIncomeByID(user.ID, item.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrGeneralRecordNotFound):
+				v.AddError(data.BatchItemKey(i, "id"), "record not found")
+				continue
+			default:
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+		if seen[item.ID] {
+			v.AddError(data.BatchItemKey(i, "id"), "is already in the batch")
+			continue
+		}
+		seen[item.ID] = true
+		itemV := validator.New()
+		if app.applyIncomeUpdateHelper(itemV, user.CurrencyCode, income, &item.incomeUpdateInput); itemV.Valid() {
+			data.ValidateIncome(itemV, income)
+		}
+		if itemV.Valid() && (item.AccountID != nil || item.CurrencyCode != nil) {
+			err = app.validateAccountHelper(itemV, user.ID, income.AccountID, income.OriginalCurrencyCode)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+		if !itemV.Valid() {
+			data.AddBatchItemErrors(v, i, itemV.Errors)
+			continue
+		}
+		records[i] = income
+		operations[i] = func(q *data.BatchQueries) error {
+			return q.FinancialTrackingManager.UpdateIncomeByID(user.ID, income)
+		}
+	}
+	app.runBatchHelper(w, r, input.Mode, v, operations, records, http.StatusOK, nil)
+}
+
+// batchDeleteIncomesHandler() deletes up to data.MaxBatchItems incomes in a single request
+func (app *application) batchDeleteIncomesHandler(w http.ResponseWriter, r *http.Request) {
+	input, ok := readBatchHelper[batchDeleteItem](app, w, r)
+	if !ok {
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	operations := make([]data.BatchOperation, len(input.Items))
+	records := make([]any, len(input.Items))
+	for i, item := range input.Items {
+		itemV := validator.New()
+		if data.ValidateURLID(itemV, item.ID, "id"); !itemV.Valid() {
+			data.AddBatchItemErrors(v, i, itemV.Errors)
+			continue
+		}
+		records[i] = item.ID
+		operations[i] = func(q *data.BatchQueries) error {
+			_, err := q.FinancialTrackingManager.DeleteIncomeByID(user.ID, item.ID)
+			return err
+		}
+	}
+	app.runBatchHelper(w, r, input.Mode, v, operations, records, http.StatusOK, nil)
+}
+
+// batchCreateInvestmentTransactionsHandler() records up to data.MaxBatchItems investment transactions
+// in a single request. Each transaction updates the quantity of its investment in the same transaction,
+// so several transactions on the same investment add up
+func (app *application) batchCreateInvestmentTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	input, ok := readBatchHelper[struct {
+		InvestmentType    string           `json:"investment_type"`
+		InvestmentID      int64            `json:"investment_id"`
+		TransactionType   string           `json:"transaction_type"`
+		TransactionAmount decimal.Decimal  `json:"transaction_amount"`
+		TransactionDate   data.CustomTime1 `json:"transaction_date"`
+		Quantity          decimal.Decimal  `json:"quantity"`
+		AccountID         int64            `json:"account_id"`
+	}](app, w, r)
+	if !ok {
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	operations := make([]data.BatchOperation, len(input.Items))
+	records := make([]any, len(input.Items))
+	for i, item := range input.Items {
+		itemV := validator.New()
+		investmentType, err := app.models.InvestmentPortfolioManager.MapInvestmentTypeToConstant(item.InvestmentType)
+		if err != nil {
+			itemV.AddError("investment_type", "invalid investment type")
+		}
+		transactionType, err := app.models.InvestmentPortfolioManager.MapTransactionTypeToConstant(item.TransactionType)
+		if err != nil {
+			itemV.AddError("transaction_type", "invalid transaction type")
+		}
+		transaction := &data.InvestmentTransaction{
+			UserID:            user.ID,
+			InvestmentType:    investmentType,
+			InvestmentID:      item.InvestmentID,
+			TransactionType:   transactionType,
+			TransactionAmount: item.TransactionAmount,
+			TransactionDate:   item.TransactionDate,
+			Quantity:          item.Quantity,
+			AccountID:         item.AccountID,
+		}
+		if data.ValidateInvestmentTransaction(itemV, transaction); itemV.Valid() {
+			// make sure the investment exists
+			app.investmentTransactionValidatorHelper(itemV, app.models.InvestmentPortfolioManager, transaction)
+		}
+		if itemV.Valid() {
+			err = app.validateAccountHelper(itemV, user.ID, transaction.AccountID, user.CurrencyCode)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+		if !itemV.Valid() {
+			data.AddBatchItemErrors(v, i, itemV.Errors)
+			continue
+		}
+		records[i] = transaction
+		operations[i] = func(q *data.BatchQueries) error {
+			// read the investment in the transaction to see the earlier items of the batch
+			investmentV := validator.New()
+			investment := app.investmentTransactionValidatorHelper(investmentV, q.InvestmentPortfolioManager, transaction)
+			if !investmentV.Valid() {
+				return data.ErrGeneralRecordNotFound
+			}
+			err := q.InvestmentPortfolioManager.CreateNewInvestmentTransaction(user.ID, transaction)
+			if err != nil {
+				return err
+			}
+			return app.updateInvestmentTransactionHelper(q.InvestmentPortfolioManager, user.ID, item.TransactionType, item.Quantity, investment)
+		}
+	}
+	app.runBatchHelper(w, r, input.Mode, v, operations, records, http.StatusCreated, nil)
+}
+
+// batchDeleteInvestmentTransactionsHandler() deletes up to data.MaxBatchItems investment transactions
+// in a single request. Just like a single delete, the quantities of the investments are left as they are
+func (app *application) batchDeleteInvestmentTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	input, ok := readBatchHelper[batchDeleteItem](app, w, r)
+	if !ok {
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	operations := make([]data.BatchOperation, len(input.Items))
+	records := make([]any, len(input.Items))
+	for i, item := range input.Items {
+		itemV := validator.New()
+		if data.ValidateURLID(itemV, item.ID, "id"); !itemV.Valid() {
+			data.AddBatchItemErrors(v, i, itemV.Errors)
+			continue
+		}
+		records[i] = item.ID
+		operations[i] = func(q *data.BatchQueries) error {
+			_, err := q.InvestmentPortfolioManager.DeleteInvestmentTransactionByID(user.ID, item.ID)
+			return err
+		}
+	}
+	app.runBatchHelper(w, r, input.Mode, v, operations, records, http.StatusOK, nil)
+}
+
+// readBatchHelper() reads the body of a batch request and validates its mode and size.
+// It writes the error response itself and returns false when the request is not valid
+func readBatchHelper[T any](app *application, w http.ResponseWriter, r *http.Request) (*batchInput[T], bool) {
+	var input batchInput[T]
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, false
+	}
+	if input.Mode == "" {
+		input.Mode = data.BatchModeAllOrNothing
+	}
+	v := validator.New()
+	if data.ValidateBatch(v, input.Mode, len(input.Items)); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil, false
+	}
+	return &input, true
+}
+
+// batchBudgetHelper() returns the user's budget with its surplus, reading each budget only once per batch.
+// A budget that does not exist or belongs to someone else is added to the item's validator
+func (app *application) batchBudgetHelper(v *validator.Validator, userID, budgetID int64, budgets map[int64]*batchBudget) (*batchBudget, error) {
+	if budget, ok := budgets[budgetID]; ok {
+		return budget, nil
+	}
+	budget, err := app.models.FinancialManager.GetBudgetByID(budgetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("budget_id", "budget not found")
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+	if budget.UserID != userID {
+		v.AddError("budget_id", "budget not found")
+		return nil, nil
+	}
+	goalTotals, err := app.models.FinancialManager.GetAllGoalSummaryBudgetID(budgetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	budgets[budgetID] = &batchBudget{budget: budget, surplus: goalTotals.TotalSurplus}
+	return budgets[budgetID], nil
+}
+
+// runBatchHelper() runs the operations of a batch and writes the response, which lists the result of
+// every item and the errors keyed by the position of the item. In all or nothing mode any invalid or
+// failing item is a failed validation and nothing is saved. When only some items were saved the
+// response is 207 Multi-Status. The results are returned to follow up on the saved items, nil if nothing was saved
+func (app *application) runBatchHelper(w http.ResponseWriter, r *http.Request, mode string, v *validator.Validator, operations []data.BatchOperation, records []any, status int, extra envelope) []*data.BatchItemResult {
+	if mode == data.BatchModeAllOrNothing && !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil
+	}
+	errs, err := app.models.BatchManager.RunBatch(mode, operations)
+	if err != nil && !errors.Is(err, data.ErrBatchRolledBack) {
+		app.serverErrorResponse(w, r, err)
+		return nil
+	}
+	for i, itemErr := range errs {
+		if itemErr == nil {
+			continue
+		}
+		switch {
+		case errors.Is(itemErr, data.ErrGeneralRecordNotFound):
+			v.AddError(data.BatchItemKey(i, "id"), "record not found")
+		case errors.Is(itemErr, data.ErrEditConflict):
+			v.AddError(data.BatchItemKey(i, "id"), "unable to update the record due to an edit conflict, please try again")
+		case mode == data.BatchModeAllOrNothing:
+			app.serverErrorResponse(w, r, itemErr)
+			return nil
+		default:
+			app.logger.Error("Error saving batch item", zap.Int("index", i), zap.Error(itemErr))
+			v.AddError(data.BatchItemKey(i, ""), "could not be saved")
+		}
+	}
+	if err != nil {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil
+	}
+	results := data.NewBatchResults(operations, errs, records)
+	saved := 0
+	for _, result := range results {
+		if result.Succeeded {
+			saved++
+		}
+	}
+	if saved == 0 {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil
+	}
+	if saved < len(results) {
+		status = http.StatusMultiStatus
+	}
+	response := envelope{"results": results, "errors": v.Errors}
+	for key, value := range extra {
+		response[key] = value
+	}
+	err = app.writeJSON(w, status, response, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+	return results
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// getCashFlowCalendarHandler() returns the user's upcoming cash-flow events merged into a
+// day by day timeline with the projected balance of each day. The range starts at start_date
+// (YYYY-MM-DD, defaults to today) and covers days days (defaults to 30, max 366).
+// starting_balance sets the balance the projection starts from and defaults to the current
+// combined balance of the user's accounts
+func (app *application) getCashFlowCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	v := validator.New()
+	qs := r.URL.Query()
+	startDate := app.readDate(qs, "start_date", time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), v)
+	days := app.readInt(qs, "days", data.DefaultCashFlowCalendarDays, v)
+	var startingBalance *decimal.Decimal
+	if qs.Has("starting_balance") {
+		balance := decimal.NewFromFloat(app.readFloat64(qs, "starting_balance", 0, v))
+		startingBalance = &balance
+	}
+	if data.ValidateCashFlowCalendarRange(v, days); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	calendar, err := app.buildCashFlowCalendarHelper(app.contextGetUser(r), startDate, days, startingBalance)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"calendar": calendar}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// createCalendarFeedHandler() creates the user's secret iCalendar feed URL. Calling it again
+// regenerates the URL, the previous URL stops working straight away. The URL expires after
+// DefaultCalendarFeedTokenTTL so a leaked link does not stay valid for long
+func (app *application) createCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+	// a user only ever has one feed
+	err := app.models.Tokens.DeleteAllForUser(data.ScopeCalendarFeed, user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	token, err := app.models.Tokens.New(user.ID, data.DefaultCalendarFeedTokenTTL, data.ScopeCalendarFeed)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	feedURL := app.config.api.baseurl + "/v1/calendar.ics?token=" + token.Plaintext
+	err = app.writeJSON(w, http.StatusCreated, envelope{"feed_url": feedURL, "expiry": token.Expiry}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteCalendarFeedHandler() revokes the user's iCalendar feed URL
+func (app *application) deleteCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	err := app.models.Tokens.DeleteAllForUser(data.ScopeCalendarFeed, app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "calendar feed revoked successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getCashFlowICalendarFeedHandler() serves the cash-flow calendar of the next 90 days as an
+// iCalendar feed for calendar apps. It is authorised by the secret token in the URL, the
+// projection starts from the current combined balance of the user's accounts
+func (app *application) getCashFlowICalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	tokenPlaintext := app.readString(r.URL.Query(), "token", "")
+	v := validator.New()
+	if data.ValidateTokenPlaintext(v, tokenPlaintext); !v.Valid() {
+		app.notFoundResponse(w, r)
+		return
+	}
+	user, err := app.models.Users.GetForToken(data.ScopeCalendarFeed, tokenPlaintext, app.config.encryption.key)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	now := time.Now().UTC()
+	calendar, err := app.buildCashFlowCalendarHelper(user, now, data.DefaultCashFlowFeedDays, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(data.EncodeCashFlowICalendar(calendar, app.config.api.name+" Cash Flow", now))
+	if err != nil {
+		app.logger.Error("unable to write calendar feed", zap.Int64("user_id", user.ID), zap.Error(err))
+	}
+}
+
+// buildCashFlowCalendarHelper() gathers the user's cash-flow events for the range, converts them
+// to the user's currency and builds the calendar. Without a starting balance the projection
+// starts from the current combined balance of the user's accounts
+func (app *application) buildCashFlowCalendarHelper(user *data.User, startDate time.Time, days int, startingBalance *decimal.Decimal) (*data.CashFlowCalendar, error) {
+	startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 0, days-1)
+	currencyCode := user.CurrencyCode
+	if currencyCode == "" {
+		currencyCode = app.config.api.defaultcurrency
+	}
+	events, err := app.models.CalendarManager.GetCashFlowEvents(user.ID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	var accounts []*data.Account
+	if startingBalance == nil {
+		accounts, err = app.models.AccountManager.GetAccountsByUserID(user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	currencies := make([]string, 0, len(events)+len(accounts))
+	for _, event := range events {
+		currencies = append(currencies, event.OriginalCurrencyCode)
+	}
+	for _, account := range accounts {
+		currencies = append(currencies, account.CurrencyCode)
+	}
+	rates, err := app.getConversionRatesHelper(currencies, currencyCode)
+	if err != nil {
+		return nil, err
+	}
+	data.ConvertCashFlowEvents(events, currencyCode, rates)
+	if startingBalance == nil {
+		balance := data.CashFlowStartingBalance(accounts, currencyCode, rates)
+		startingBalance = &balance
+	}
+	return data.BuildCashFlowCalendar(events, startDate, endDate, *startingBalance, currencyCode), nil
+}
+
+// getConversionRatesHelper() returns the conversion rate from each of the currencies to the target
+// currency. Every currency is only looked up once, empty currencies and the target itself are skipped
+func (app *application) getConversionRatesHelper(currencies []string, targetCurrency string) (map[string]decimal.Decimal, error) {
+	rates := make(map[string]decimal.Decimal)
+	for _, currency := range currencies {
+		if currency == "" || currency == targetCurrency {
+			continue
+		}
+		if _, ok := rates[currency]; ok {
+			continue
+		}
+		exchangeRate, err := app.convertAndGetExchangeRate(currency, targetCurrency)
+		if err != nil {
+			return nil, err
+		}
+		rates[currency] = exchangeRate.ConversionRate
+	}
+	return rates, nil
+}
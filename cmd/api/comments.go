@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/database"
 	"github.com/Blue-Davinci/OptiVest/internal/validator"
 )
 
@@ -87,8 +88,32 @@ func (app *application) createNewCommentHandler(w http.ResponseWriter, r *http.R
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	// archived groups are read-only
+	if !app.requireGroupCommentsOpen(w, r, comment.AssociatedType, comment.AssociatedID) {
+		return
+	}
+	// comments from the group's own members make it onto the group's timeline, recorded
+	// together with the comment
+	userID := app.contextGetUser(r).ID
+	var activity *data.GroupActivity
+	if comment.AssociatedType == data.CommentAssociatedTypeGroup {
+		if _, _, err := app.models.FinancialGroupManager.GetGroupMemberAccess(userID, comment.AssociatedID); err == nil {
+			activity = &data.GroupActivity{
+				GroupID:      comment.AssociatedID,
+				UserID:       userID,
+				ActivityType: data.GroupActivityComment,
+				Description:  "commented on the group",
+			}
+		}
+	}
 	// create the comment
-	err = app.models.CommentManagerModel.CreateNewComment(app.contextGetUser(r).ID, comment)
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		err := data.CommentManagerModel{DB: txModel.DB}.CreateNewComment(userID, comment)
+		if activity != nil {
+			activity.SubjectID = comment.ID
+		}
+		return err
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrGeneralRecordNotFound):
@@ -103,6 +128,9 @@ func (app *application) createNewCommentHandler(w http.ResponseWriter, r *http.R
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
+	if activity != nil {
+		app.publishGroupActivity(activity)
+	}
 }
 
 // updateCommentHandler() updates a comment in the database
@@ -136,6 +164,9 @@ func (app *application) updateCommentHandler(w http.ResponseWriter, r *http.Requ
 		}
 		return
 	}
+	if !app.requireCommentGroupOpen(w, r, commentID) {
+		return
+	}
 	// update the comment
 	comment.Content = input.Content
 	comment.Version = input.Version
@@ -196,6 +227,9 @@ func (app *application) createNewReactionHandler(w http.ResponseWriter, r *http.
 		}
 		return
 	}
+	if !app.requireCommentGroupOpen(w, r, reaction.CommentID) {
+		return
+	}
 	// create the reaction
 	err = app.models.CommentManagerModel.CreateNewReaction(app.contextGetUser(r).ID, reaction)
 	if err != nil {
@@ -216,6 +250,8 @@ func (app *application) createNewReactionHandler(w http.ResponseWriter, r *http.
 }
 
 // deleteCommentHandler() deletes a comment in the database
+// Users can delete their own comments, and group moderators and admins can delete any
+// comment left on their group.
 // we take in the comment ID from the URL and return a 200 status if successful
 func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Request) {
 	// get the comment ID from the URL
@@ -230,8 +266,34 @@ func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Requ
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	if !app.requireCommentGroupOpen(w, r, commentID) {
+		return
+	}
 	// delete the comment
 	err = app.models.CommentManagerModel.DeleteComment(app.contextGetUser(r).ID, commentID)
+	switch {
+	case err == nil:
+	case errors.Is(err, data.ErrGeneralRecordNotFound):
+		// not the user's comment, they may still moderate it if it was left on a group
+		if !app.moderateGroupComment(w, r, commentID) {
+			return
+		}
+	default:
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// send the response
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "comment successfully deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// moderateGroupComment() deletes someone else's comment on a group, provided the user may
+// moderate the group's comments. It sends the response when it cannot and reports whether
+// the comment was deleted
+func (app *application) moderateGroupComment(w http.ResponseWriter, r *http.Request, commentID int64) bool {
+	associatedType, associatedID, err := app.models.CommentManagerModel.GetCommentAssociation(commentID)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrGeneralRecordNotFound):
@@ -239,13 +301,26 @@ func (app *application) deleteCommentHandler(w http.ResponseWriter, r *http.Requ
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
-		return
+		return false
 	}
-	// send the response
-	err = app.writeJSON(w, http.StatusOK, envelope{"message": "comment successfully deleted"}, nil)
+	if associatedType != data.CommentAssociatedTypeGroup {
+		app.notFoundResponse(w, r)
+		return false
+	}
+	if !app.requireGroupPermission(w, r, associatedID, data.GroupPermissionModerateComments) {
+		return false
+	}
+	err = app.models.CommentManagerModel.ModeratorDeleteComment(commentID)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return false
 	}
+	return true
 }
 
 // deleteReactionHandler() deletes a reaction/like for a comment
@@ -263,6 +338,9 @@ func (app *application) deleteReactionHandler(w http.ResponseWriter, r *http.Req
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	if !app.requireCommentGroupOpen(w, r, commentID) {
+		return
+	}
 	// delete the reaction
 	err = app.models.CommentManagerModel.DeleteReaction(app.contextGetUser(r).ID, commentID)
 	if err != nil {
@@ -280,3 +358,42 @@ func (app *application) deleteReactionHandler(w http.ResponseWriter, r *http.Req
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// requireGroupCommentsOpen() stops comments left on an archived group from being added to or
+// changed, archived groups are read-only. It sends the response when the group is archived or
+// missing and reports whether the request may go ahead
+func (app *application) requireGroupCommentsOpen(w http.ResponseWriter, r *http.Request, associatedType database.CommentAssociatedType, associatedID int64) bool {
+	if associatedType != data.CommentAssociatedTypeGroup {
+		return true
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(associatedID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return false
+	}
+	if group.IsArchived() {
+		app.groupArchivedResponse(w, r)
+		return false
+	}
+	return true
+}
+
+// requireCommentGroupOpen() is requireGroupCommentsOpen() for an existing comment
+func (app *application) requireCommentGroupOpen(w http.ResponseWriter, r *http.Request, commentID int64) bool {
+	associatedType, associatedID, err := app.models.CommentManagerModel.GetCommentAssociation(commentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return false
+	}
+	return app.requireGroupCommentsOpen(w, r, associatedType, associatedID)
+}
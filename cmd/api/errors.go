@@ -100,3 +100,17 @@ func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request)
 	message := "the requested resource could not be found"
 	app.errorResponse(w, r, http.StatusNotFound, message)
 }
+
+// The notPermittedResponse() method will be used to send a 403 Forbidden status code and
+// JSON response to the client when their group role does not allow the action.
+func (app *application) notPermittedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "your role in this group does not have the necessary permissions for this action"
+	app.errorResponse(w, r, http.StatusForbidden, message)
+}
+
+// The groupArchivedResponse() method will be used to send a 409 Conflict status code and
+// JSON response to the client when they try to change an archived group.
+func (app *application) groupArchivedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "this group is archived and can no longer be changed"
+	app.errorResponse(w, r, http.StatusConflict, message)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+// getExchangeRateHistoryHandler() returns the stored daily rates converting the from currency
+// (defaults to the user's currency) to the to currency between start_date and end_date.
+// The range defaults to the last 30 days, days without a stored rate are left out
+func (app *application) getExchangeRateHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+	currencyCode := user.CurrencyCode
+	if currencyCode == "" {
+		currencyCode = app.config.api.defaultcurrency
+	}
+	now := time.Now().UTC()
+	v := validator.New()
+	qs := r.URL.Query()
+	sourceCurrency := strings.ToUpper(app.readString(qs, "from", currencyCode))
+	targetCurrency := strings.ToUpper(app.readString(qs, "to", ""))
+	endDate := app.readDate(qs, "end_date", time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), v)
+	startDate := app.readDate(qs, "start_date", endDate.AddDate(0, 0, -29), v)
+	if data.ValidateExchangeRateHistoryRange(v, sourceCurrency, targetCurrency, startDate, endDate); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// start earlier so the first days can use the latest rate before the range
+	history, err := app.models.ExchangeRateManager.GetExchangeRateHistory(app.config.api.defaultcurrency,
+		[]string{sourceCurrency, targetCurrency}, startDate.Add(-data.MaxExchangeRateAge), endDate)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	rates := history.Series(sourceCurrency, targetCurrency, startDate, endDate)
+	err = app.writeJSON(w, http.StatusOK, envelope{"exchange_rates": rates}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getFXRevaluationHandler() revalues the user's past incomes and budgets recorded in other
+// currencies at today's rates, showing the gain or loss each made from exchange rate moves
+func (app *application) getFXRevaluationHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+	currencyCode := user.CurrencyCode
+	if currencyCode == "" {
+		currencyCode = app.config.api.defaultcurrency
+	}
+	items, err := app.models.ExchangeRateManager.GetForeignCurrencyItemsByUserID(user.ID, currencyCode)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	now := time.Now().UTC()
+	currencies := []string{currencyCode}
+	earliest := now
+	for _, item := range items {
+		currencies = append(currencies, item.CurrencyCode)
+		if item.Date.Before(earliest) {
+			earliest = item.Date
+		}
+	}
+	// older budgets were saved without their rate, the stored history fills it in
+	history, err := app.models.ExchangeRateManager.GetExchangeRateHistory(app.config.api.defaultcurrency, currencies,
+		earliest.Add(-data.MaxExchangeRateAge), now)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	currentRates, err := app.getConversionRatesHelper(currencies, currencyCode)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	revaluation := data.BuildFXRevaluation(currencyCode, items, history, currentRates, now)
+	err = app.writeJSON(w, http.StatusOK, envelope{"fx_revaluation": revaluation}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
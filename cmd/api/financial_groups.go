@@ -18,6 +18,7 @@ func (app *application) createNewUserGroupHandler(w http.ResponseWriter, r *http
 	var input struct {
 		Name           string `json:"name"`
 		IsPrivate      bool   `json:"is_private"`
+		IsDiscoverable bool   `json:"is_discoverable"`
 		MaxMemberCount int    `json:"max_member_count"`
 		Description    string `json:"description"`
 	}
@@ -32,6 +33,7 @@ func (app *application) createNewUserGroupHandler(w http.ResponseWriter, r *http
 		GroupImageURL:  data.DefaultGroupImageURL,
 		Name:           input.Name,
 		IsPrivate:      input.IsPrivate,
+		IsDiscoverable: input.IsDiscoverable,
 		MaxMemberCount: input.MaxMemberCount,
 		Description:    input.Description,
 	}
@@ -70,6 +72,7 @@ func (app *application) updateUserGroupHandler(w http.ResponseWriter, r *http.Re
 		Name           *string `json:"name"`
 		GroupImageURL  *string `json:"group_image_url"`
 		IsPrivate      *bool   `json:"is_private"`
+		IsDiscoverable *bool   `json:"is_discoverable"`
 		MaxMemberCount *int    `json:"max_member_count"`
 		Description    *string `json:"description"`
 		Version        int     `json:"version"`
@@ -80,6 +83,10 @@ func (app *application) updateUserGroupHandler(w http.ResponseWriter, r *http.Re
 		app.notFoundResponse(w, r)
 		return
 	}
+	// check the user may change the group's settings
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionEditSettings) {
+		return
+	}
 	// get the group by the details
 	group, err := app.models.FinancialGroupManager.GetGroupById(groupID)
 	if err != nil {
@@ -106,6 +113,13 @@ func (app *application) updateUserGroupHandler(w http.ResponseWriter, r *http.Re
 	}
 	if input.IsPrivate != nil {
 		group.IsPrivate = *input.IsPrivate
+		// public groups are always listed, so making a group public drops its discoverability
+		if !group.IsPrivate {
+			group.IsDiscoverable = false
+		}
+	}
+	if input.IsDiscoverable != nil {
+		group.IsDiscoverable = *input.IsDiscoverable
 	}
 	if input.MaxMemberCount != nil {
 		group.MaxMemberCount = *input.MaxMemberCount
@@ -143,7 +157,7 @@ func (app *application) updateUserGroupHandler(w http.ResponseWriter, r *http.Re
 
 // updateGroupUserRoleHandler() is a handler function that updates a user's role in a group
 // we will take the groupID from the URL and an input body which includes the userID and the role
-// The updaterUserID will be obtained from the context as we need to verify that the user is an admin or mod
+// The updaterUserID will be obtained from the context as we need to verify that the user is an admin
 // We will verify that the group exists and the proceed. We will return the updated group
 func (app *application) updateGroupUserRoleHandler(w http.ResponseWriter, r *http.Request) {
 	// get the group ID from the URL
@@ -152,15 +166,8 @@ func (app *application) updateGroupUserRoleHandler(w http.ResponseWriter, r *htt
 		app.notFoundResponse(w, r)
 		return
 	}
-	// get the group by the details
-	_, err = app.models.FinancialGroupManager.GetGroupById(groupID)
-	if err != nil {
-		switch {
-		case errors.Is(err, data.ErrGeneralRecordNotFound):
-			app.notFoundResponse(w, r)
-		default:
-			app.serverErrorResponse(w, r, err)
-		}
+	// check the user may change roles in the group
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionChangeRoles) {
 		return
 	}
 	// input
@@ -185,8 +192,19 @@ func (app *application) updateGroupUserRoleHandler(w http.ResponseWriter, r *htt
 		}
 		return
 	}
-	// update the user role
-	updatedAt, err := app.models.FinancialGroupManager.UpdateGroupUserRole(groupID, input.UserID, app.contextGetUser(r).ID, mappedRole)
+	// update the user role, logging it on the group's timeline
+	activity := &data.GroupActivity{
+		GroupID:      groupID,
+		UserID:       app.contextGetUser(r).ID,
+		ActivityType: data.GroupActivityRoleChanged,
+		SubjectID:    input.UserID,
+		Description:  fmt.Sprintf("changed a member's role to %s", mappedRole),
+	}
+	var updatedAt time.Time
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		updatedAt, err = txModel.UpdateGroupUserRole(groupID, input.UserID, app.contextGetUser(r).ID, mappedRole)
+		return err
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrEditConflict):
@@ -202,7 +220,7 @@ func (app *application) updateGroupUserRoleHandler(w http.ResponseWriter, r *htt
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
-
+	app.publishGroupActivity(activity)
 }
 
 // createNewGroupInvitation() will create a new group invitation for a specific user.
@@ -242,10 +260,8 @@ func (app *application) createNewGroupInvitation(w http.ResponseWriter, r *http.
 		}
 		return
 	}
-	// check if user is Owner of the group
-	// for now only the creator of the group can invite users
-	if group.CreatorUserID != app.contextGetUser(r).ID {
-		app.notFoundResponse(w, r)
+	// check the user may invite people to the group
+	if !app.requireGroupPermission(w, r, group.ID, data.GroupPermissionInviteMembers) {
 		return
 	}
 
@@ -366,9 +382,18 @@ func (app *application) createNewPublicMembershipHandler(w http.ResponseWriter,
 		}
 		return
 	}
-	// check if the group is private
+	if group.IsArchived() {
+		v.AddError("group_id", "this group is archived")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// check if the group is private, discoverable private groups take join requests instead
 	if group.IsPrivate {
-		v.AddError("group_id", "this group is private")
+		if group.AcceptsJoinRequests() {
+			v.AddError("group_id", "this group is private, send a join request instead")
+		} else {
+			v.AddError("group_id", "this group is private")
+		}
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
@@ -383,8 +408,18 @@ func (app *application) createNewPublicMembershipHandler(w http.ResponseWriter,
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
-	// create a new public membership
-	membershipID, err := app.models.FinancialGroupManager.CreateNewPublicMembership(app.contextGetUser(r).ID, input.GroupID)
+	// create a new public membership, logging it on the group's timeline
+	activity := &data.GroupActivity{
+		GroupID:      input.GroupID,
+		UserID:       app.contextGetUser(r).ID,
+		ActivityType: data.GroupActivityMemberJoined,
+		Description:  "joined the group",
+	}
+	var membershipID int64
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		membershipID, err = txModel.CreateNewPublicMembership(app.contextGetUser(r).ID, input.GroupID)
+		return err
+	})
 	// if we get a ErrUserGroupMembershipExists error, we will return a 409
 	if err != nil {
 		switch {
@@ -400,7 +435,7 @@ func (app *application) createNewPublicMembershipHandler(w http.ResponseWriter,
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
-
+	app.publishGroupActivity(activity)
 }
 
 // updateGroupInvitationStatusHandler() will update the status of a group invitation
@@ -451,8 +486,31 @@ func (app *application) updateGroupInvitationStatusHandler(w http.ResponseWriter
 		app.failedValidationResponse(w, r, map[string]string{"status": "status cannot be pending"})
 		return
 	}
-	// update the group invitation status
-	err = app.models.FinancialGroupManager.UpdateGroupInvitationStatus(mappedStatus, groupInvitation)
+	// archived groups cannot be joined
+	if mappedStatus == data.InviationStatusTypeAccepted {
+		group, err := app.models.FinancialGroupManager.GetGroupById(groupInvitation.GroupID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if group.IsArchived() {
+			app.failedValidationResponse(w, r, map[string]string{"status": "this group is archived and can no longer be joined"})
+			return
+		}
+	}
+	// update the group invitation status, an accepted invitation makes it onto the group's timeline
+	var activity *data.GroupActivity
+	if mappedStatus == data.InviationStatusTypeAccepted {
+		activity = &data.GroupActivity{
+			GroupID:      groupInvitation.GroupID,
+			UserID:       app.contextGetUser(r).ID,
+			ActivityType: data.GroupActivityMemberJoined,
+			Description:  "joined the group",
+		}
+	}
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		return txModel.UpdateGroupInvitationStatus(mappedStatus, groupInvitation)
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrEditConflict):
@@ -474,6 +532,9 @@ func (app *application) updateGroupInvitationStatusHandler(w http.ResponseWriter
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
+	if activity != nil {
+		app.publishGroupActivity(activity)
+	}
 	// ToDo: Notify inviter of acceptance
 	notificationContent := data.NotificationContent{
 		Message: fmt.Sprintf("%s has seen your group invitation and %s", app.contextGetUser(r).Email, message),
@@ -508,15 +569,8 @@ func (app *application) createNewGroupGoalHandler(w http.ResponseWriter, r *http
 		app.badRequestResponse(w, r, err)
 		return
 	}
-	// check if the group exists
-	_, err = app.models.FinancialGroupManager.GetGroupById(input.GroupID)
-	if err != nil {
-		switch {
-		case errors.Is(err, data.ErrGeneralRecordNotFound):
-			app.notFoundResponse(w, r)
-		default:
-			app.serverErrorResponse(w, r, err)
-		}
+	// check the group exists and the user may create goals in it
+	if !app.requireGroupPermission(w, r, input.GroupID, data.GroupPermissionCreateGoal) {
 		return
 	}
 	// create a new group goal
@@ -535,8 +589,18 @@ func (app *application) createNewGroupGoalHandler(w http.ResponseWriter, r *http
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
-	// create a new group goal
-	err = app.models.FinancialGroupManager.CreateNewGroupGoal(app.contextGetUser(r).ID, groupGoal)
+	// create a new group goal, logging it on the group's timeline
+	activity := &data.GroupActivity{
+		GroupID:      groupGoal.GroupID,
+		UserID:       app.contextGetUser(r).ID,
+		ActivityType: data.GroupActivityGoalCreated,
+		Description:  fmt.Sprintf("created the goal %s", groupGoal.GoalName),
+	}
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		err := txModel.CreateNewGroupGoal(app.contextGetUser(r).ID, groupGoal)
+		activity.SubjectID = groupGoal.ID
+		return err
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrGroupNameExists):
@@ -552,10 +616,11 @@ func (app *application) createNewGroupGoalHandler(w http.ResponseWriter, r *http
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
+	app.publishGroupActivity(activity)
 }
 
 // updateGroupGoalHandler() will update a group goal for a group
-// This will be a permission route and only the creator of the group or a Group Admin/Moderator
+// This will be a permission route and only a Group Admin/Moderator
 // will be able to update the group goal
 func (app *application) updateGroupGoalHandler(w http.ResponseWriter, r *http.Request) {
 	// grab group ID from the URL
@@ -587,6 +652,10 @@ func (app *application) updateGroupGoalHandler(w http.ResponseWriter, r *http.Re
 		}
 		return
 	}
+	// check the user may edit the goals of the goal's group
+	if !app.requireGroupPermission(w, r, groupGoal.GroupID, data.GroupPermissionEditGoal) {
+		return
+	}
 	// CHECK FOR CHANGES
 	if input.Name != nil {
 		groupGoal.GoalName = *input.Name
@@ -627,6 +696,7 @@ func (app *application) updateGroupGoalHandler(w http.ResponseWriter, r *http.Re
 // createNewGroupTransactionHandler() will create a new group transaction for a group
 // we will take an input from the user, validate it and then create a new group transaction
 // Any added transaction, will be immediately added to the group's current amount
+// and pays off the member's oldest pending dues for the goal that it fully covers
 func (app *application) createNewGroupTransactionHandler(w http.ResponseWriter, r *http.Request) {
 	// input
 	var input struct {
@@ -641,18 +711,11 @@ func (app *application) createNewGroupTransactionHandler(w http.ResponseWriter,
 		app.badRequestResponse(w, r, err)
 		return
 	}
-	// check if user is member and group exists
-	err = app.models.FinancialGroupManager.CheckIfGroupExistsAndUserIsMember(app.contextGetUser(r).ID, input.GroupID)
-	if err != nil {
-		switch {
-		case errors.Is(err, data.ErrGeneralRecordNotFound):
-			app.notFoundResponse(w, r)
-		default:
-			app.serverErrorResponse(w, r, err)
-		}
+	// check the group exists and the user may contribute to it
+	if !app.requireGroupPermission(w, r, input.GroupID, data.GroupPermissionContribute) {
 		return
 	}
-	// check if Goal exists
+	// check if Goal exists and belongs to the group
 	groupGoal, err := app.models.FinancialGroupManager.GetGroupGoalById(input.GoalID)
 	if err != nil {
 		switch {
@@ -663,6 +726,10 @@ func (app *application) createNewGroupTransactionHandler(w http.ResponseWriter,
 		}
 		return
 	}
+	if groupGoal.GroupID != input.GroupID {
+		app.notFoundResponse(w, r)
+		return
+	}
 	// check that groupGoal.CurrentAmount is less than groupGoal.TargetAmount
 	// if it is not less than, then we will not allow the user to add a new transaction
 	if groupGoal.CurrentAmount.GreaterThanOrEqual(groupGoal.TargetAmount) {
@@ -684,8 +751,18 @@ func (app *application) createNewGroupTransactionHandler(w http.ResponseWriter,
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
-	// create a new group transaction
-	err = app.models.FinancialGroupManager.CreateNewGroupTransaction(app.contextGetUser(r).ID, groupTransaction)
+	// create a new group transaction, logging it on the group's timeline
+	activity := &data.GroupActivity{
+		GroupID:      input.GroupID,
+		UserID:       app.contextGetUser(r).ID,
+		ActivityType: data.GroupActivityContribution,
+		Description:  fmt.Sprintf("contributed %s to %s", groupTransaction.Amount.StringFixed(2), groupGoal.GoalName),
+	}
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		err := txModel.CreateNewGroupTransaction(app.contextGetUser(r).ID, groupTransaction)
+		activity.SubjectID = groupTransaction.ID
+		return err
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrOverFunding):
@@ -702,11 +779,11 @@ func (app *application) createNewGroupTransactionHandler(w http.ResponseWriter,
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
-
+	app.publishGroupActivity(activity)
 }
 
 // deleteGroupTransactionHandler() will delete a group transaction provided the user is the creator
-// of the transaction
+// of the transaction, moderators and admins can delete anyone's the same way as expenses
 // We use ErrGeneralRecordNotFound, to see if the deletion was successful
 func (app *application) deleteGroupTransactionHandler(w http.ResponseWriter, r *http.Request) {
 	// get the group transaction ID from the URL
@@ -715,6 +792,24 @@ func (app *application) deleteGroupTransactionHandler(w http.ResponseWriter, r *
 		app.notFoundResponse(w, r)
 		return
 	}
+	groupTransaction, err := app.models.FinancialGroupManager.GetGroupTransactionByID(groupTransactionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// members can always delete their own transactions
+	permission := data.GroupPermissionDeleteOthersExpenses
+	if groupTransaction.MemberID == app.contextGetUser(r).ID {
+		permission = data.GroupPermissionContribute
+	}
+	if !app.requireGroupPermission(w, r, groupTransaction.GroupID, permission) {
+		return
+	}
 	// delete the group transaction
 	_, err = app.models.FinancialGroupManager.DeleteGroupTransaction(app.contextGetUser(r).ID, groupTransactionID)
 	if err != nil {
@@ -735,14 +830,19 @@ func (app *application) deleteGroupTransactionHandler(w http.ResponseWriter, r *
 }
 
 // createNewGroupExpenseHandler() will create a new group expense for a group
-// we will take an input from the user, validate it and then create a new group expense
+// we will take an input from the user, validate it and then create a new group expense.
+// An optional split shares the expense between members of the group
+// If the group has a budget for the expense's category, an expense over a strict budget is
+// rejected while one over any other budget is saved with a warning message
 func (app *application) createNewGroupExpenseHandler(w http.ResponseWriter, r *http.Request) {
+	message := data.Warning_Messages
 	// input
 	var input struct {
-		GroupID     int64           `json:"group_id"`
-		Amount      decimal.Decimal `json:"amount"`
-		Description string          `json:"description"`
-		Category    string          `json:"category"`
+		GroupID     int64                   `json:"group_id"`
+		Amount      decimal.Decimal         `json:"amount"`
+		Description string                  `json:"description"`
+		Category    string                  `json:"category"`
+		Split       *groupExpenseSplitInput `json:"split"`
 	}
 	// decode the input
 	err := app.readJSON(w, r, &input)
@@ -750,15 +850,8 @@ func (app *application) createNewGroupExpenseHandler(w http.ResponseWriter, r *h
 		app.badRequestResponse(w, r, err)
 		return
 	}
-	// check if user is member and group exists
-	err = app.models.FinancialGroupManager.CheckIfGroupExistsAndUserIsMember(app.contextGetUser(r).ID, input.GroupID)
-	if err != nil {
-		switch {
-		case errors.Is(err, data.ErrGeneralRecordNotFound):
-			app.notFoundResponse(w, r)
-		default:
-			app.serverErrorResponse(w, r, err)
-		}
+	// check the group exists and the user may add expenses to it
+	if !app.requireGroupPermission(w, r, input.GroupID, data.GroupPermissionAddExpense) {
 		return
 	}
 	// create a new expense
@@ -775,21 +868,57 @@ func (app *application) createNewGroupExpenseHandler(w http.ResponseWriter, r *h
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
-	// create a new group expense
-	err = app.models.FinancialGroupManager.CreateNewGroupExpense(app.contextGetUser(r).ID, groupExpense)
+	// split the expense between members if asked to
+	if input.Split != nil {
+		err = app.groupExpenseSplitHelper(v, groupExpense, input.Split)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+	// create a new group expense, checking it against the group's budget for its category and
+	// logging it on the group's timeline
+	activity := &data.GroupActivity{
+		GroupID:      groupExpense.GroupID,
+		UserID:       app.contextGetUser(r).ID,
+		ActivityType: data.GroupActivityExpense,
+		Description:  fmt.Sprintf("added an expense of %s for %s", groupExpense.Amount.StringFixed(2), groupExpense.Category),
+	}
+	var budget *data.GroupBudgetSummary
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		budget, err = txModel.CreateNewGroupExpenseWithinBudget(app.contextGetUser(r).ID, groupExpense)
+		activity.SubjectID = groupExpense.ID
+		return err
+	})
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
+		switch {
+		case errors.Is(err, data.ErrGroupBudgetExceeded):
+			v.AddError("amount", groupBudgetExceededMessage(budget))
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
 		return
 	}
+	// going over a budget that is not strict only warns
+	if budget != nil {
+		message.Message = append(message.Message, groupBudgetExceededMessage(budget))
+	}
 	// send the group expense in the response
-	err = app.writeJSON(w, http.StatusCreated, envelope{"group_expense": groupExpense}, nil)
+	err = app.writeJSON(w, http.StatusCreated, envelope{"group_expense": groupExpense, "message": message}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
+	app.publishGroupActivity(activity)
 }
 
 // deleteGroupExpenseHandler() will delete a group expense provided the user is the creator
-// of the expense
+// of the expense or their role lets them delete other members' expenses.
+// Any attachments of the expense are deleted along with it
 // We use ErrGeneralRecordNotFound, to see if the deletion was successful
 func (app *application) deleteGroupExpenseHandler(w http.ResponseWriter, r *http.Request) {
 	// get the group expense ID from the URL
@@ -798,6 +927,30 @@ func (app *application) deleteGroupExpenseHandler(w http.ResponseWriter, r *http
 		app.notFoundResponse(w, r)
 		return
 	}
+	groupExpense, err := app.models.FinancialGroupManager.GetGroupExpenseByID(groupExpenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// members can always delete their own expenses
+	permission := data.GroupPermissionDeleteOthersExpenses
+	if groupExpense.MemberID == app.contextGetUser(r).ID {
+		permission = data.GroupPermissionAddExpense
+	}
+	if !app.requireGroupPermission(w, r, groupExpense.GroupID, permission) {
+		return
+	}
+	// get the attachments before they are cascaded away with the expense
+	attachments, err := app.models.AttachmentManager.GetExpenseAttachmentsByGroupExpenseID(groupExpenseID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
 	// delete the group expense
 	_, err = app.models.FinancialGroupManager.DeleteGroupExpense(app.contextGetUser(r).ID, groupExpenseID)
 	if err != nil {
@@ -809,6 +962,8 @@ func (app *application) deleteGroupExpenseHandler(w http.ResponseWriter, r *http
 		}
 		return
 	}
+	// remove the stored files
+	app.deleteStoredAttachments(attachments)
 
 	// send a message as a response
 	err = app.writeJSON(w, http.StatusOK, envelope{"message": "group expense deleted"}, nil)
@@ -933,6 +1088,10 @@ func (app *application) getGroupTransactionsByGroupIdHandler(w http.ResponseWrit
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	// check the user may see the group
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
 	// get all the transactions for the group
 	transactions, metadata, err := app.models.FinancialGroupManager.GetGroupTransactionsByGroupId(app.contextGetUser(r).ID, groupID, input.GoalID, input.Filters)
 	if err != nil {
@@ -986,6 +1145,10 @@ func (app *application) getGroupExpensesByGroupIdHandler(w http.ResponseWriter,
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	// check the user may see the group
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
 	// get all the expenses for the group
 	expenses, metadata, err := app.models.FinancialGroupManager.GetGroupExpensesByGroupId(app.contextGetUser(r).ID, groupID, input.Category, input.Filters)
 	if err != nil {
@@ -1021,6 +1184,10 @@ func (app *application) getDetailedGroupByIdHandler(w http.ResponseWriter, r *ht
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	// check the user may see the group
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
 	// get the group by the details
 	group, err := app.models.FinancialGroupManager.GetDetailedGroupById(app.contextGetUser(r).ID, groupID)
 	if err != nil {
@@ -1032,6 +1199,13 @@ func (app *application) getDetailedGroupByIdHandler(w http.ResponseWriter, r *ht
 		}
 		return
 	}
+	// the member balances come from the same calculation as the group's balances
+	balances, err := app.getGroupBalancesHelper(groupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	data.SetGroupMemberBalances(group.GroupMembers, balances)
 	// send the group in the response
 	err = app.writeJSON(w, http.StatusOK, envelope{"group": group}, nil)
 	if err != nil {
@@ -1068,8 +1242,22 @@ func (app *application) adminDeleteGroupMemberHandler(w http.ResponseWriter, r *
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
-	// perform the Delete Request
-	_, err = app.models.FinancialGroupManager.AdminDeleteGroupMember(app.contextGetUser(r).ID, groupID, memberID)
+	// check the user may remove members from the group
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionRemoveMembers) {
+		return
+	}
+	// perform the Delete Request, logging it on the group's timeline
+	activity := &data.GroupActivity{
+		GroupID:      groupID,
+		UserID:       app.contextGetUser(r).ID,
+		ActivityType: data.GroupActivityMemberRemoved,
+		SubjectID:    memberID,
+		Description:  "removed a member from the group",
+	}
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		_, err := txModel.AdminDeleteGroupMember(app.contextGetUser(r).ID, groupID, memberID)
+		return err
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrGeneralRecordNotFound):
@@ -1084,10 +1272,13 @@ func (app *application) adminDeleteGroupMemberHandler(w http.ResponseWriter, r *
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
+	app.publishGroupActivity(activity)
 }
 
 // userLeaveGroupHandler() allows users to remove/delete themselves from a given group.
 // wwe expect the groupID from the URL and a userID from the context
+// When an admin or the owner leaves, the group is handed over first so it is never left without
+// either. The last member of a group cannot leave it and has to delete the group instead
 func (app *application) userLeaveGroupHandler(w http.ResponseWriter, r *http.Request) {
 	// get the groupID
 	groupID, err := app.readIDParam(r, "groupID")
@@ -1102,12 +1293,46 @@ func (app *application) userLeaveGroupHandler(w http.ResponseWriter, r *http.Req
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
-	// delete the user from the group
-	_, err = app.models.FinancialGroupManager.UserLeaveGroup(app.contextGetUser(r).ID, groupID)
+	user := app.contextGetUser(r)
+	role, err := app.models.FinancialGroupManager.GetGroupMembershipRole(user.ID, groupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(groupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// the group is handed over before an admin or the owner leaves, together with the leaving
+	// and its entries on the group's timeline
+	handOver := role == data.GroupRoleAdmin || group.CreatorUserID == user.ID
+	activity := &data.GroupActivity{
+		GroupID:      groupID,
+		UserID:       user.ID,
+		ActivityType: data.GroupActivityMemberLeft,
+		Description:  "left the group",
+	}
+	var successorID int64
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		successorID, err = txModel.LeaveGroup(user.ID, group, handOver)
+		return err
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrGeneralRecordNotFound):
 			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrGroupLastMember):
+			v.AddError("groupID", "you are the last member of this group, delete the group instead")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrGroupNameExists):
+			v.AddError("groupID", "the member taking over already owns a group with this name, transfer the group to another admin first")
+			app.failedValidationResponse(w, r, v.Errors)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
@@ -1118,4 +1343,17 @@ func (app *application) userLeaveGroupHandler(w http.ResponseWriter, r *http.Req
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
+	app.publishGroupActivity(activity)
+	// let the member who took over know
+	if successorID != 0 {
+		notificationContent := data.NotificationContent{
+			Message: fmt.Sprintf("%s %s left the group %s and handed it over to you", user.FirstName, user.LastName, group.Name),
+			Meta: data.NotificationMeta{
+				Url:      fmt.Sprintf("%s/%d", app.config.frontend.groupurl, group.ID),
+				ImageUrl: group.GroupImageURL,
+				Tags:     "group,ownership",
+			},
+		}
+		app.PublishNotificationToRedis(successorID, data.NotificationTypeGroupUpdate, notificationContent)
+	}
 }
@@ -75,6 +75,7 @@ func (app *application) createNewBudgetdHandler(w http.ResponseWriter, r *http.R
 		}
 		// set the currency and exchange rate to the user's budget
 		newBudget.TotalAmount = convertedAmount.ConvertAmount(newBudget.TotalAmount).ConvertedAmount
+		newBudget.ConversionRate = convertedAmount.ConversionRate
 	} else {
 		// otherwise we set the exchange rate to 1/ users default currency
 		// set the exchange rate to 1
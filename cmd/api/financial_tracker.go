@@ -5,10 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Blue-Davinci/OptiVest/internal/data"
-	"github.com/Blue-Davinci/OptiVest/internal/database"
 	"github.com/Blue-Davinci/OptiVest/internal/validator"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
@@ -27,6 +27,7 @@ func (app *application) createNewExpenseHandler(w http.ResponseWriter, r *http.R
 		Amount      decimal.Decimal `json:"amount"`
 		Description string          `json:"description"`
 		DateOcurred time.Time       `json:"date_occurred"`
+		AccountID   int64           `json:"account_id"`
 	}
 	// read the request body into the input struct
 	err := app.readJSON(w, r, &input)
@@ -57,6 +58,7 @@ func (app *application) createNewExpenseHandler(w http.ResponseWriter, r *http.R
 		IsRecurring:  false,
 		Description:  input.Description,
 		DateOccurred: input.DateOcurred,
+		AccountID:    input.AccountID,
 	}
 	// create a validator
 	v := validator.New()
@@ -65,6 +67,16 @@ func (app *application) createNewExpenseHandler(w http.ResponseWriter, r *http.R
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	// the expense is paid from the account in the budget's currency
+	err = app.validateAccountHelper(v, user.ID, expense.AccountID, budget.CurrencyCode)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
 	// get the available surplus
 	goalTotals, err := app.models.FinancialManager.GetAllGoalSummaryBudgetID(expense.BudgetID, user.ID)
 	if err != nil {
@@ -112,6 +124,7 @@ func (app *application) updateExpenseByIDHandler(w http.ResponseWriter, r *http.
 		Category    *string          `json:"category"`
 		Description *string          `json:"description"`
 		DateOcurred *time.Time       `json:"date_occurred"`
+		AccountID   *int64           `json:"account_id"`
 	}
 
 	// get the expense ID from the url
@@ -199,6 +212,11 @@ func (app *application) updateExpenseByIDHandler(w http.ResponseWriter, r *http.
 	if input.DateOcurred != nil {
 		expense.DateOccurred = *input.DateOcurred
 	}
+	// moving the expense to another account (or off its account, with 0) un-clears it
+	if input.AccountID != nil && *input.AccountID != expense.AccountID {
+		expense.AccountID = *input.AccountID
+		expense.IsCleared = false
+	}
 
 	// 4. Validate the expense before saving
 	v := validator.New()
@@ -206,6 +224,17 @@ func (app *application) updateExpenseByIDHandler(w http.ResponseWriter, r *http.
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	if input.AccountID != nil {
+		err = app.validateAccountHelper(v, user.ID, expense.AccountID, budget.CurrencyCode)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
 
 	// 5. Save the updated expense to the database
 	err = app.models.FinancialTrackingManager.UpdateExpenseByID(user.ID, expense)
@@ -221,6 +250,42 @@ func (app *application) updateExpenseByIDHandler(w http.ResponseWriter, r *http.
 	}
 }
 
+// deleteExpenseByIDHandler() deletes one of the user's expenses together with its attachments.
+// The attachment records are removed by the database so we fetch them first in order to
+// clean up their stored files once the expense is gone
+func (app *application) deleteExpenseByIDHandler(w http.ResponseWriter, r *http.Request) {
+	// get the expense ID from the URL
+	expenseID, err := app.readIDParam(r, "expenseID")
+	if err != nil || expenseID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	user := app.contextGetUser(r)
+	// get the attachments before they are cascaded away
+	attachments, err := app.models.AttachmentManager.GetExpenseAttachmentsByExpenseID(user.ID, expenseID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// delete the expense
+	_, err = app.models.FinancialTrackingManager.DeleteExpenseByID(user.ID, expenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// remove the stored files
+	app.deleteStoredAttachments(attachments)
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "expense deleted successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
 // createNewRecurringExpenseHandler() is an handler method that will add a recurring expense to the database
 // Expenses are tied to a badget. A badget is either strict or not. If it is strict, the user cannot spend more than the budget.
 // We first check if the budget exists, if it does not, we return an error
@@ -233,11 +298,16 @@ func (app *application) createNewRecurringExpenseHandler(w http.ResponseWriter,
 	var message = data.Warning_Messages
 	// make the input struct for what we will require in a recurrent expense
 	var input struct {
-		BudgetID           int64           `json:"budget_id"`
-		Amount             decimal.Decimal `json:"amount"`
-		Name               string          `json:"name"`
-		Description        string          `json:"description"`
-		RecurrenceInterval string          `json:"recurrence_interval"`
+		BudgetID           int64             `json:"budget_id"`
+		Amount             decimal.Decimal   `json:"amount"`
+		Name               string            `json:"name"`
+		Description        string            `json:"description"`
+		RecurrenceInterval string            `json:"recurrence_interval"`
+		IntervalCount      int32             `json:"interval_count"`
+		DayOfMonth         int32             `json:"day_of_month"`
+		StartDate          *data.CustomTime1 `json:"start_date"`
+		EndDate            *data.CustomTime1 `json:"end_date"`
+		MaxOccurrences     int32             `json:"max_occurrences"`
 	}
 	// Decode the request body into the input struct
 	err := app.readJSON(w, r, &input)
@@ -245,8 +315,8 @@ func (app *application) createNewRecurringExpenseHandler(w http.ResponseWriter,
 		app.badRequestResponse(w, r, err)
 		return
 	}
-	// Map the recurrence interval to the database enum
-	recurrenceInterval, err := app.models.FinancialTrackingManager.MapToDatabaseRecurringExpense(input.RecurrenceInterval)
+	// Map the recurrence interval to the database enum and the number of intervals between occurrences
+	recurrenceInterval, intervalCount, err := app.models.FinancialTrackingManager.MapToRecurrenceRule(input.RecurrenceInterval, input.IntervalCount)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrInvalidRecurringExpenseTime):
@@ -254,6 +324,7 @@ func (app *application) createNewRecurringExpenseHandler(w http.ResponseWriter,
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
+		return
 	}
 
 	// Next Recurrence we will need to calculate
@@ -275,9 +346,24 @@ func (app *application) createNewRecurringExpenseHandler(w http.ResponseWriter,
 		Name:               input.Name,
 		Description:        input.Description,
 		RecurrenceInterval: recurrenceInterval,
+		IntervalCount:      intervalCount,
+		DayOfMonth:         input.DayOfMonth,
+		MaxOccurrences:     input.MaxOccurrences,
+	}
+	if input.EndDate != nil {
+		recurringExpense.EndDate = input.EndDate.Time
 	}
+	// anchor the schedule to its start date, today unless told otherwise. A schedule starting
+	// today has its first occurrence posted straight away
+	today := time.Now().Truncate(24 * time.Hour)
+	startDate := today
+	if input.StartDate != nil {
+		startDate = input.StartDate.Truncate(24 * time.Hour)
+	}
+	recurringExpense.InitializeSchedule(startDate, today)
 	// validate the recurring expense
 	v := validator.New()
+	v.Check(!startDate.Before(today), "start_date", "cannot be in the past")
 	if data.ValidateRecurringExpense(v, recurringExpense); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -303,8 +389,6 @@ func (app *application) createNewRecurringExpenseHandler(w http.ResponseWriter,
 			message.Message = append(message.Message, "recurring expense amount is more than the available surplus")
 		}
 	}
-	// safe, calculate the next occurrence
-	recurringExpense.CalculateNextOccurrence()
 	app.logger.Info("next occurrence", zap.String("next_occurrence", recurringExpense.NextOccurrence.String()))
 	// Create the recurring expense
 	err = app.models.FinancialTrackingManager.CreateNewRecurringExpense(user.ID, recurringExpense)
@@ -339,10 +423,14 @@ func (app *application) createNewRecurringExpenseHandler(w http.ResponseWriter,
 func (app *application) updateRecurringExpenseByIDHandler(w http.ResponseWriter, r *http.Request) {
 	var message = data.Warning_Messages
 	var input struct {
-		Amount             *decimal.Decimal `json:"amount"`
-		Name               *string          `json:"name"`
-		Description        *string          `json:"description"`
-		RecurrenceInterval *string          `json:"recurrence_interval"`
+		Amount             *decimal.Decimal                `json:"amount"`
+		Name               *string                         `json:"name"`
+		Description        *string                         `json:"description"`
+		RecurrenceInterval *string                         `json:"recurrence_interval"`
+		IntervalCount      *int32                          `json:"interval_count"`
+		DayOfMonth         *int32                          `json:"day_of_month"`
+		EndDate            data.Optional[data.CustomTime1] `json:"end_date"`
+		MaxOccurrences     data.Optional[int32]            `json:"max_occurrences"`
 	}
 
 	expenseID, err := app.readIDParam(r, "expenseID")
@@ -382,10 +470,14 @@ func (app *application) updateRecurringExpenseByIDHandler(w http.ResponseWriter,
 		return
 	}
 
-	var recurrenceInterval database.RecurrenceIntervalEnum
+	recurrenceInterval, intervalCount := recurringExpense.RecurrenceInterval, recurringExpense.IntervalCount
 	if input.RecurrenceInterval != nil {
 		var err error
-		recurrenceInterval, err = app.models.FinancialTrackingManager.MapToDatabaseRecurringExpense(*input.RecurrenceInterval)
+		var count int32
+		if input.IntervalCount != nil {
+			count = *input.IntervalCount
+		}
+		recurrenceInterval, intervalCount, err = app.models.FinancialTrackingManager.MapToRecurrenceRule(*input.RecurrenceInterval, count)
 		if err != nil {
 			switch {
 			case errors.Is(err, data.ErrInvalidRecurringExpenseTime):
@@ -395,8 +487,8 @@ func (app *application) updateRecurringExpenseByIDHandler(w http.ResponseWriter,
 			}
 			return
 		}
-	} else {
-		recurrenceInterval = recurringExpense.RecurrenceInterval
+	} else if input.IntervalCount != nil {
+		intervalCount = *input.IntervalCount
 	}
 
 	// Create a validator
@@ -410,9 +502,20 @@ func (app *application) updateRecurringExpenseByIDHandler(w http.ResponseWriter,
 		recurringExpense.Amount = *input.Amount
 	}
 
-	// Update recurrence interval if it's provided and changed
-	if input.RecurrenceInterval != nil && *input.RecurrenceInterval != string(recurringExpense.RecurrenceInterval) {
-		recurringExpense.RecurrenceInterval = recurrenceInterval
+	// Update the recurrence rule if it's provided and changed
+	ruleChanged := recurrenceInterval != recurringExpense.RecurrenceInterval || intervalCount != recurringExpense.IntervalCount
+	recurringExpense.RecurrenceInterval = recurrenceInterval
+	recurringExpense.IntervalCount = intervalCount
+	if input.DayOfMonth != nil && *input.DayOfMonth != recurringExpense.DayOfMonth {
+		recurringExpense.DayOfMonth = *input.DayOfMonth
+		ruleChanged = true
+	}
+	// a null end date or maximum number of occurrences removes it
+	if input.EndDate.Set {
+		recurringExpense.EndDate = input.EndDate.Value.Time
+	}
+	if input.MaxOccurrences.Set {
+		recurringExpense.MaxOccurrences = input.MaxOccurrences.Value
 	}
 
 	// Calculate the new projected amount
@@ -442,8 +545,12 @@ func (app *application) updateRecurringExpenseByIDHandler(w http.ResponseWriter,
 		}
 	}
 
-	// Update next occurrence
-	recurringExpense.CalculateNextOccurrence()
+	// Recalculate the next occurrence from the last occurrence only when the rule changed,
+	// skipping any occurrences that the new rule would have placed in the past
+	if ruleChanged {
+		recurringExpense.RecalculateNextOccurrence()
+		recurringExpense.FastForward(time.Now().Truncate(24 * time.Hour))
+	}
 	// print next occurrence
 	app.logger.Info("next occurrence", zap.String("next_occurrence", recurringExpense.NextOccurrence.String()))
 	// validate recurring expense
@@ -468,11 +575,81 @@ func (app *application) updateRecurringExpenseByIDHandler(w http.ResponseWriter,
 	}
 }
 
+// pauseRecurringExpenseHandler() pauses a recurring expense so that it is no longer posted by the scheduler
+func (app *application) pauseRecurringExpenseHandler(w http.ResponseWriter, r *http.Request) {
+	app.updateRecurringExpenseScheduleHelper(w, r, func(recurringExpense *data.RecurringExpense) {
+		recurringExpense.IsPaused = true
+	})
+}
+
+// resumeRecurringExpenseHandler() resumes a paused recurring expense.
+// Occurrences that fell due while the expense was paused are skipped rather than posted.
+func (app *application) resumeRecurringExpenseHandler(w http.ResponseWriter, r *http.Request) {
+	app.updateRecurringExpenseScheduleHelper(w, r, func(recurringExpense *data.RecurringExpense) {
+		recurringExpense.IsPaused = false
+		recurringExpense.FastForward(time.Now().Truncate(24 * time.Hour))
+	})
+}
+
+// skipRecurringExpenseOccurrenceHandler() skips the next occurrence of a recurring expense
+// without posting it. The skipped occurrence still counts towards the maximum occurrences.
+func (app *application) skipRecurringExpenseOccurrenceHandler(w http.ResponseWriter, r *http.Request) {
+	app.updateRecurringExpenseScheduleHelper(w, r, func(recurringExpense *data.RecurringExpense) {
+		recurringExpense.CalculateNextOccurrence()
+	})
+}
+
+// updateRecurringExpenseScheduleHelper() is a helper for the handlers that only change the schedule
+// of a recurring expense. It gets the recurring expense, applies the update and saves it back.
+// Schedules that have already ended cannot be changed.
+func (app *application) updateRecurringExpenseScheduleHelper(w http.ResponseWriter, r *http.Request, update func(*data.RecurringExpense)) {
+	expenseID, err := app.readIDParam(r, "expenseID")
+	if err != nil || expenseID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	user := app.contextGetUser(r)
+	recurringExpense, err := app.models.FinancialTrackingManager.GetRecurringExpenseByID(user.ID, expenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if recurringExpense.HasEnded() {
+		app.badRequestResponse(w, r, data.ErrRecurringExpenseEnded)
+		return
+	}
+	// apply the update
+	update(recurringExpense)
+	// save the updated recurring expense
+	err = app.models.FinancialTrackingManager.UpdateRecurringExpenseByID(user.ID, recurringExpense)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// send the response
+	err = app.writeJSON(w, http.StatusOK, envelope{"expense": recurringExpense}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
 // getAllExpensesByUserIDHandler() is a handler method that will return all expenses for a user
 // This route supports pagination as well as a name search parameter for the expense's name
+// and a comma separated tags filter i.e ?tags=business-trip-2026,tax-deductible
 func (app *application) getAllExpensesByUserIDHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
 		Name string
+		Tags []string
 		data.Filters
 	}
 	//validate if queries are provided
@@ -481,6 +658,7 @@ func (app *application) getAllExpensesByUserIDHandler(w http.ResponseWriter, r *
 	qs := r.URL.Query()
 	//get the page & pagesizes as ints and set to the embedded struct
 	input.Name = app.readString(qs, "name", "")
+	input.Tags = data.NormalizeTags(app.readCSV(qs, "tags", []string{}))
 	input.Filters.Page = app.readInt(qs, "page", 1, v)
 	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
 	// get the sort values falling back to "created_at" if it is not provided
@@ -488,12 +666,13 @@ func (app *application) getAllExpensesByUserIDHandler(w http.ResponseWriter, r *
 	// Add the supported sort values for this endpoint to the sort safelist.
 	input.Filters.SortSafelist = []string{"created_at", "-created_at"}
 	// Perform validation
+	data.ValidateTags(v, "tags", input.Tags)
 	if data.ValidateFilters(v, input.Filters); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 	// get our expenses
-	expenses, metadata, err := app.models.FinancialTrackingManager.GetAllExpensesByUserID(app.contextGetUser(r).ID, input.Name, input.Filters)
+	expenses, metadata, err := app.models.FinancialTrackingManager.GetAllExpensesByUserID(app.contextGetUser(r).ID, input.Name, input.Tags, input.Filters)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrGeneralRecordNotFound):
@@ -566,6 +745,7 @@ func (app *application) createNewIncomeHandler(w http.ResponseWriter, r *http.Re
 		Amount       decimal.Decimal  `json:"amount_original"`
 		Description  string           `json:"description"`
 		DateReceived data.CustomTime1 `json:"date_received"`
+		AccountID    int64            `json:"account_id"`
 	}
 	// read the request body into the input struct
 	err := app.readJSON(w, r, &input)
@@ -583,38 +763,30 @@ func (app *application) createNewIncomeHandler(w http.ResponseWriter, r *http.Re
 		AmountOriginal:       input.Amount,
 		Description:          input.Description,
 		DateReceived:         input.DateReceived.Time,
+		AccountID:            input.AccountID,
 	}
 	// create a validator
 	v := validator.New()
-	// check if the currency is the users default currency
-	if user.CurrencyCode != income.OriginalCurrencyCode {
-		// check currrncy code is supported
-		if err := app.verifyCurrencyInRedis(income.OriginalCurrencyCode); err != nil {
-			v.AddError("currency_code", "currency code not supported")
-			app.failedValidationResponse(w, r, v.Errors)
-			return
-		}
-		// convert the amount to the user's default currency
-		convertedAmount, err := app.convertAndGetExchangeRate(income.OriginalCurrencyCode, user.CurrencyCode)
-		if err != nil {
-			v.AddError("currency_code", "could not convert currency")
-			app.failedValidationResponse(w, r, v.Errors)
-			return
-		}
-		// set amount and exchange rate
-		income.Amount = convertedAmount.ConvertAmount(income.AmountOriginal).ConvertedAmount
-		income.ExchangeRate = convertedAmount.ConversionRate
-		app.logger.Info("converted amount", zap.String("converted_amount", income.Amount.String()))
-		app.logger.Info("exchange rate", zap.String("exchange_rate", income.ExchangeRate.String()))
-	} else {
-		income.Amount = income.AmountOriginal
-		income.ExchangeRate = decimal.NewFromInt(1)
+	// convert the amount to the user's default currency
+	if app.convertIncomeHelper(v, user.CurrencyCode, income); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
 	}
 	// validate the income
 	if data.ValidateIncome(v, income); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	// the original amount is paid into the account, so it must be in the income's currency
+	err = app.validateAccountHelper(v, user.ID, income.AccountID, income.OriginalCurrencyCode)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
 	// save the income
 	err = app.models.FinancialTrackingManager.CreateNewIncome(user.ID, income)
 	if err != nil {
@@ -631,9 +803,11 @@ func (app *application) createNewIncomeHandler(w http.ResponseWriter, r *http.Re
 
 // getAllIncomesByUserIDHandler() is a handler method that will return all incomes for a user
 // This route supports pagination as well as a source search parameter for the income's source
+// and a comma separated tags filter, only incomes with all the tags are returned
 func (app *application) getAllIncomesByUserIDHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
 		Name string
+		Tags []string
 		data.Filters
 	}
 	//validate if queries are provided
@@ -642,6 +816,7 @@ func (app *application) getAllIncomesByUserIDHandler(w http.ResponseWriter, r *h
 	qs := r.URL.Query()
 	//get the page & pagesizes as ints and set to the embedded struct
 	input.Name = app.readString(qs, "name", "")
+	input.Tags = data.NormalizeTags(app.readCSV(qs, "tags", []string{}))
 	input.Filters.Page = app.readInt(qs, "page", 1, v)
 	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
 	// get the sort values falling back to "created_at" if it is not provided
@@ -649,12 +824,13 @@ func (app *application) getAllIncomesByUserIDHandler(w http.ResponseWriter, r *h
 	// Add the supported sort values for this endpoint to the sort safelist.
 	input.Filters.SortSafelist = []string{"created_at", "-created_at"}
 	// Perform validation
+	data.ValidateTags(v, "tags", input.Tags)
 	if data.ValidateFilters(v, input.Filters); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 	// get our incomes
-	incomes, metadata, err := app.models.FinancialTrackingManager.GetAllIncomesByUserID(app.contextGetUser(r).ID, input.Name, input.Filters)
+	incomes, metadata, err := app.models.FinancialTrackingManager.GetAllIncomesByUserID(app.contextGetUser(r).ID, input.Name, input.Tags, input.Filters)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrGeneralRecordNotFound):
@@ -681,13 +857,7 @@ func (app *application) getAllIncomesByUserIDHandler(w http.ResponseWriter, r *h
 // updateIncomeHandler updates an existing income entry.
 // updateIncomeHandler updates an existing income entry.
 func (app *application) updateIncomeHandler(w http.ResponseWriter, r *http.Request) {
-	var input struct {
-		Source       *string          `json:"source"`
-		CurrencyCode *string          `json:"currency_code"`
-		Amount       *decimal.Decimal `json:"amount_original"`
-		Description  *string          `json:"description"`
-		DateReceived *time.Time       `json:"date_received"`
-	}
+	var input incomeUpdateInput
 
 	// Get the income ID from the URL.
 	incomeID, err := app.readIDParam(r, "incomeID")
@@ -720,6 +890,112 @@ func (app *application) updateIncomeHandler(w http.ResponseWriter, r *http.Reque
 	// Create a validator instance.
 	v := validator.New()
 
+	// Apply the changes, converting the amount when the currency changes.
+	if app.applyIncomeUpdateHelper(v, user.CurrencyCode, income, &input); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// Validate the updated income.
+	if data.ValidateIncome(v, income); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// Check the account when it or the income's currency changed.
+	if input.AccountID != nil || input.CurrencyCode != nil {
+		err = app.validateAccountHelper(v, user.ID, income.AccountID, income.OriginalCurrencyCode)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+
+	// Save the updated income to the database.
+	err = app.models.FinancialTrackingManager.UpdateIncomeByID(user.ID, income)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Send a success response (optional, you may want to return the updated income).
+	err = app.writeJSON(w, http.StatusOK, envelope{"income": income}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteIncomeByIDHandler() deletes one of the user's incomes, its tags and tax categories go with it
+func (app *application) deleteIncomeByIDHandler(w http.ResponseWriter, r *http.Request) {
+	// get the income ID from the URL
+	incomeID, err := app.readIDParam(r, "incomeID")
+	if err != nil || incomeID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	// delete the income
+	_, err = app.models.FinancialTrackingManager.DeleteIncomeByID(app.contextGetUser(r).ID, incomeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "income deleted successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// incomeUpdateInput holds the fields of an income that can be updated, fields left out keep their value
+type incomeUpdateInput struct {
+	Source       *string          `json:"source"`
+	CurrencyCode *string          `json:"currency_code"`
+	Amount       *decimal.Decimal `json:"amount_original"`
+	Description  *string          `json:"description"`
+	DateReceived *time.Time       `json:"date_received"`
+	AccountID    *int64           `json:"account_id"`
+}
+
+// convertIncomeHelper() sets the amount of an income in the user's default currency together with
+// the exchange rate used. Incomes received in another currency are converted at the rate of the day
+// they were received, unsupported currencies and failed conversions are added to the validator
+func (app *application) convertIncomeHelper(v *validator.Validator, userCurrencyCode string, income *data.Income) {
+	// check if the currency is the users default currency
+	if userCurrencyCode == income.OriginalCurrencyCode {
+		income.Amount = income.AmountOriginal
+		income.ExchangeRate = decimal.NewFromInt(1)
+		return
+	}
+	// check currrncy code is supported
+	if err := app.verifyCurrencyInRedis(income.OriginalCurrencyCode); err != nil {
+		v.AddError("currency_code", "currency code not supported")
+		return
+	}
+	// convert the amount to the user's default currency at the rate of the day it was received
+	convertedAmount, err := app.convertAndGetExchangeRateOnDate(income.OriginalCurrencyCode, userCurrencyCode, income.DateReceived)
+	if err != nil {
+		v.AddError("currency_code", "could not convert currency")
+		return
+	}
+	// set amount and exchange rate
+	income.Amount = convertedAmount.ConvertAmount(income.AmountOriginal).ConvertedAmount
+	income.ExchangeRate = convertedAmount.ConversionRate
+	app.logger.Info("converted amount", zap.String("converted_amount", income.Amount.String()))
+	app.logger.Info("exchange rate", zap.String("exchange_rate", income.ExchangeRate.String()))
+}
+
+// applyIncomeUpdateHelper() applies the provided fields to an existing income.
+// When the currency changes the amount has to be provided as well so that it can be reconverted,
+// any problems are added to the validator
+func (app *application) applyIncomeUpdateHelper(v *validator.Validator, userCurrencyCode string, income *data.Income, input *incomeUpdateInput) {
 	// Determine if the currency code or amount has been updated.
 	if input.CurrencyCode != nil && input.Amount != nil {
 		// If both CurrencyCode and Amount are provided, use the new CurrencyCode for conversion.
@@ -728,15 +1004,18 @@ func (app *application) updateIncomeHandler(w http.ResponseWriter, r *http.Reque
 		// Ensure the new currency is supported.
 		if err := app.verifyCurrencyInRedis(newCurrencyCode); err != nil {
 			v.AddError("currency_code", "currency code not supported")
-			app.failedValidationResponse(w, r, v.Errors)
 			return
 		}
 
-		// Convert the provided amount in the new currency to the user's default currency.
-		convertedAmount, err := app.convertAndGetExchangeRate(newCurrencyCode, user.CurrencyCode)
+		// Convert the provided amount in the new currency to the user's default currency,
+		// using the rate of the day the income was received.
+		dateReceived := income.DateReceived
+		if input.DateReceived != nil {
+			dateReceived = *input.DateReceived
+		}
+		convertedAmount, err := app.convertAndGetExchangeRateOnDate(newCurrencyCode, userCurrencyCode, dateReceived)
 		if err != nil {
 			v.AddError("currency_code", "could not convert currency")
-			app.failedValidationResponse(w, r, v.Errors)
 			return
 		}
 
@@ -750,7 +1029,6 @@ func (app *application) updateIncomeHandler(w http.ResponseWriter, r *http.Reque
 	} else if input.CurrencyCode != nil {
 		// If only the CurrencyCode is provided, ensure the amount is also updated.
 		v.AddError("amount", "amount must be provided if the currency code is changed")
-		app.failedValidationResponse(w, r, v.Errors)
 		return
 
 	} else if input.Amount != nil {
@@ -768,80 +1046,368 @@ func (app *application) updateIncomeHandler(w http.ResponseWriter, r *http.Reque
 	if input.DateReceived != nil {
 		income.DateReceived = *input.DateReceived
 	}
-
-	// Validate the updated income.
-	if data.ValidateIncome(v, income); !v.Valid() {
-		app.failedValidationResponse(w, r, v.Errors)
-		return
-	}
-
-	// Save the updated income to the database.
-	err = app.models.FinancialTrackingManager.UpdateIncomeByID(user.ID, income)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
-
-	// Send a success response (optional, you may want to return the updated income).
-	err = app.writeJSON(w, http.StatusOK, envelope{"income": income}, nil)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
+	// moving the income to another account (or off its account, with 0) un-clears it
+	if input.AccountID != nil && *input.AccountID != income.AccountID {
+		income.AccountID = *input.AccountID
+		income.IsCleared = false
 	}
 }
 
-// createNewDebtHandler() creates a new debt user debt for the user
-// We calculate initial values including payoff dates if not provided
-// Perform additional validation, If everything is okya, we save the ne debt
-func (app *application) createNewDebtHandler(w http.ResponseWriter, r *http.Request) {
-	// debt input from user
+// createNewRecurringIncomeHandler() creates a new recurring income for a user
+// Just like a normal income, a recurring income can be received in any supported currency.
+// We only verify that the currency is supported here, the conversion to the user's default
+// currency happens each time the scheduler posts the income.
+// If a goal is provided, we verify that it belongs to the user so that a percentage of
+// each posting can be allocated to it.
+func (app *application) createNewRecurringIncomeHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Name           string           `json:"name"` // Name of the debt
-		Amount         decimal.Decimal  `json:"amount"`
-		InterestRate   decimal.Decimal  `json:"interest_rate"`
-		Description    string           `json:"description"`
-		DueDate        data.CustomTime1 `json:"due_date"` // YYYY-MM-DD
-		MinimumPayment decimal.Decimal  `json:"minimum_payment"`
+		Source                   string            `json:"source"`
+		CurrencyCode             string            `json:"currency_code"`
+		Amount                   decimal.Decimal   `json:"amount_original"`
+		Description              string            `json:"description"`
+		RecurrenceInterval       string            `json:"recurrence_interval"`
+		NextOccurrence           data.CustomTime1  `json:"next_occurrence"`
+		EndDate                  *data.CustomTime1 `json:"end_date"`
+		GoalID                   int64             `json:"goal_id"`
+		GoalAllocationPercentage decimal.Decimal   `json:"goal_allocation_percentage"`
 	}
-
 	// read the request body into the input struct
 	err := app.readJSON(w, r, &input)
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
-
-	// create a new debt from the input data
-	debt := &data.Debt{
-		Name:             input.Name,
-		Amount:           input.Amount,
-		RemainingBalance: input.Amount, // Initially, remaining balance is the total amount
-		InterestRate:     input.InterestRate,
-		Description:      input.Description,
-		DueDate:          input.DueDate.Time,
-		MinimumPayment:   input.MinimumPayment,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
-		NextPaymentDate:  input.DueDate.Time, // Set next payment date to the first due date initially
+	// Map the recurrence interval to the database enum
+	recurrenceInterval, err := app.models.FinancialTrackingManager.MapToDatabaseRecurringExpense(input.RecurrenceInterval)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidRecurringExpenseTime):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
 	}
-
-	// validate the debt
+	// get the user
+	user := app.contextGetUser(r)
+	// make a new recurring income
+	recurringIncome := &data.RecurringIncome{
+		Source:                   input.Source,
+		OriginalCurrencyCode:     input.CurrencyCode,
+		AmountOriginal:           input.Amount,
+		Description:              input.Description,
+		RecurrenceInterval:       recurrenceInterval,
+		NextOccurrence:           input.NextOccurrence.Time,
+		GoalID:                   input.GoalID,
+		GoalAllocationPercentage: input.GoalAllocationPercentage,
+	}
+	if input.EndDate != nil {
+		recurringIncome.EndDate = input.EndDate.Time
+	}
+	recurringIncome.AnchorSchedule()
+	// create a validator
 	v := validator.New()
-	if data.ValidateDebt(v, debt); !v.Valid() {
+	// the first posting cannot be in the past
+	v.Check(!recurringIncome.NextOccurrence.Before(time.Now().Truncate(24*time.Hour)), "next_occurrence", "cannot be in the past")
+	if data.ValidateRecurringIncome(v, recurringIncome); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
-
-	// Calculate some additional fields:
-	// Accrued interest starts at zero since no payments have been made yet
-	debt.AccruedInterest = decimal.NewFromFloat(0.0)
-
-	// The interest calculation date should be the current date, as this is when we start tracking
-	debt.InterestLastCalculated = time.Now()
-
-	debt.TotalInterestPaid = decimal.NewFromFloat(0.0) // Initial interest paid is 0
-
-	// Estimated payoff date can be calculated based on minimum payment
-	estimatedPayoffDate, err := app.calculateEstimatedPayoffDate(debt)
+	// check currency code is supported if it is not the user's default currency
+	if user.CurrencyCode != recurringIncome.OriginalCurrencyCode {
+		if err := app.verifyCurrencyInRedis(recurringIncome.OriginalCurrencyCode); err != nil {
+			v.AddError("currency_code", "currency code not supported")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+	}
+	// check the goal exists and belongs to the user
+	if recurringIncome.GoalID != 0 {
+		_, err = app.models.FinancialManager.GetGoalByID(user.ID, recurringIncome.GoalID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrGeneralRecordNotFound):
+				v.AddError("goal_id", "goal not found")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+	}
+	// save the recurring income
+	err = app.models.FinancialTrackingManager.CreateNewRecurringIncome(user.ID, recurringIncome)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateRecurringIncome):
+			v.AddError("source", "recurring income already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// send the response
+	err = app.writeJSON(w, http.StatusCreated, envelope{"recurring_income": recurringIncome}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getAllRecurringIncomesByUserIDHandler() is a handler method that will return all recurring incomes for a user
+// This route supports pagination as well as a source search parameter for the income's source
+func (app *application) getAllRecurringIncomesByUserIDHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name string
+		data.Filters
+	}
+	//validate if queries are provided
+	v := validator.New()
+	// Call r.URL.Query() to get the url.Values map containing the query string data.
+	qs := r.URL.Query()
+	//get the page & pagesizes as ints and set to the embedded struct
+	input.Name = app.readString(qs, "name", "")
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	// get the sort values falling back to "created_at" if it is not provided
+	input.Filters.Sort = app.readString(qs, "sort", "created_at")
+	// Add the supported sort values for this endpoint to the sort safelist.
+	input.Filters.SortSafelist = []string{"created_at", "-created_at"}
+	// Perform validation
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// get our recurring incomes
+	recurringIncomes, metadata, err := app.models.FinancialTrackingManager.GetAllRecurringIncomesByUserID(app.contextGetUser(r).ID, input.Name, input.Filters)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// send the response
+	err = app.writeJSON(w, http.StatusOK, envelope{"recurring_incomes": recurringIncomes, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateRecurringIncomeByIDHandler() updates an existing recurring income
+// Only the provided fields are updated. If the currency changes, we verify that
+// the new currency is supported. If the goal changes, we verify the new goal belongs to the user.
+func (app *application) updateRecurringIncomeByIDHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Source                   *string           `json:"source"`
+		CurrencyCode             *string           `json:"currency_code"`
+		Amount                   *decimal.Decimal  `json:"amount_original"`
+		Description              *string           `json:"description"`
+		RecurrenceInterval       *string           `json:"recurrence_interval"`
+		NextOccurrence           *data.CustomTime1 `json:"next_occurrence"`
+		EndDate                  *data.CustomTime1 `json:"end_date"`
+		GoalID                   *int64            `json:"goal_id"`
+		GoalAllocationPercentage *decimal.Decimal  `json:"goal_allocation_percentage"`
+	}
+	// Get the recurring income ID from the URL.
+	incomeID, err := app.readIDParam(r, "incomeID")
+	if err != nil || incomeID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	// Read the request body into the input struct.
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	// Get the user from the request context.
+	user := app.contextGetUser(r)
+	// Fetch the existing recurring income from the database.
+	recurringIncome, err := app.models.FinancialTrackingManager.GetRecurringIncomeByID(user.ID, incomeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// Create a validator instance.
+	v := validator.New()
+	// Update optional fields only if they are provided.
+	if input.Source != nil {
+		recurringIncome.Source = *input.Source
+	}
+	if input.CurrencyCode != nil && *input.CurrencyCode != recurringIncome.OriginalCurrencyCode {
+		if *input.CurrencyCode != user.CurrencyCode {
+			if err := app.verifyCurrencyInRedis(*input.CurrencyCode); err != nil {
+				v.AddError("currency_code", "currency code not supported")
+				app.failedValidationResponse(w, r, v.Errors)
+				return
+			}
+		}
+		recurringIncome.OriginalCurrencyCode = *input.CurrencyCode
+	}
+	if input.Amount != nil {
+		recurringIncome.AmountOriginal = *input.Amount
+	}
+	if input.Description != nil {
+		recurringIncome.Description = *input.Description
+	}
+	if input.RecurrenceInterval != nil {
+		recurrenceInterval, err := app.models.FinancialTrackingManager.MapToDatabaseRecurringExpense(*input.RecurrenceInterval)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrInvalidRecurringExpenseTime):
+				app.badRequestResponse(w, r, err)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+		recurringIncome.RecurrenceInterval = recurrenceInterval
+	}
+	if input.NextOccurrence != nil {
+		recurringIncome.NextOccurrence = input.NextOccurrence.Time
+		v.Check(!recurringIncome.NextOccurrence.Before(time.Now().Truncate(24*time.Hour)), "next_occurrence", "cannot be in the past")
+	}
+	// a new next occurrence or interval re-anchors the schedule
+	if input.NextOccurrence != nil || input.RecurrenceInterval != nil {
+		recurringIncome.AnchorSchedule()
+	}
+	if input.EndDate != nil {
+		recurringIncome.EndDate = input.EndDate.Time
+	}
+	if input.GoalAllocationPercentage != nil {
+		recurringIncome.GoalAllocationPercentage = *input.GoalAllocationPercentage
+	}
+	if input.GoalID != nil && *input.GoalID != recurringIncome.GoalID {
+		// a goal ID of 0 unlinks the goal
+		if *input.GoalID != 0 {
+			_, err = app.models.FinancialManager.GetGoalByID(user.ID, *input.GoalID)
+			if err != nil {
+				switch {
+				case errors.Is(err, data.ErrGeneralRecordNotFound):
+					v.AddError("goal_id", "goal not found")
+					app.failedValidationResponse(w, r, v.Errors)
+				default:
+					app.serverErrorResponse(w, r, err)
+				}
+				return
+			}
+		} else {
+			recurringIncome.GoalAllocationPercentage = decimal.Zero
+		}
+		recurringIncome.GoalID = *input.GoalID
+	}
+	// Validate the updated recurring income.
+	if data.ValidateRecurringIncome(v, recurringIncome); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// Save the updated recurring income to the database.
+	err = app.models.FinancialTrackingManager.UpdateRecurringIncomeByID(user.ID, recurringIncome)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateRecurringIncome):
+			v.AddError("source", "recurring income already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// send the response
+	err = app.writeJSON(w, http.StatusOK, envelope{"recurring_income": recurringIncome}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// createNewDebtHandler() creates a new debt user debt for the user
+// We calculate initial values including payoff dates if not provided
+// Perform additional validation, If everything is okya, we save the ne debt
+func (app *application) createNewDebtHandler(w http.ResponseWriter, r *http.Request) {
+	// debt input from user
+	var input struct {
+		Name           string           `json:"name"` // Name of the debt
+		Amount         decimal.Decimal  `json:"amount"`
+		InterestRate   decimal.Decimal  `json:"interest_rate"`
+		Description    string           `json:"description"`
+		DueDate        data.CustomTime1 `json:"due_date"` // YYYY-MM-DD
+		MinimumPayment decimal.Decimal  `json:"minimum_payment"`
+		// interest accrual
+		CompoundingFrequency string          `json:"compounding_frequency"`
+		InterestRateType     string          `json:"interest_rate_type"`
+		LateFee              decimal.Decimal `json:"late_fee"`
+		GracePeriodDays      int32           `json:"grace_period_days"`
+	}
+	// set the defaults for the interest accrual
+	input.CompoundingFrequency = "monthly"
+	input.InterestRateType = "apr"
+
+	// read the request body into the input struct
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// map the compounding frequency and interest rate type
+	compoundingFrequency, err := app.models.FinancialTrackingManager.MapToDebtCompoundingFrequency(input.CompoundingFrequency)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	interestRateType, err := app.models.FinancialTrackingManager.MapToDebtInterestRateType(input.InterestRateType)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// create a new debt from the input data
+	debt := &data.Debt{
+		Name:             input.Name,
+		Amount:           input.Amount,
+		RemainingBalance: input.Amount, // Initially, remaining balance is the total amount
+		InterestRate:     input.InterestRate,
+		Description:      input.Description,
+		DueDate:          input.DueDate.Time,
+		MinimumPayment:   input.MinimumPayment,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+		NextPaymentDate:  input.DueDate.Time, // Set next payment date to the first due date initially
+		// interest accrual
+		CompoundingFrequency: compoundingFrequency,
+		InterestRateType:     interestRateType,
+		LateFee:              input.LateFee,
+		GracePeriodDays:      input.GracePeriodDays,
+	}
+
+	// validate the debt
+	v := validator.New()
+	if data.ValidateDebt(v, debt); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	// Calculate some additional fields:
+	// Accrued interest starts at zero since no payments have been made yet
+	debt.AccruedInterest = decimal.NewFromFloat(0.0)
+
+	// The interest calculation date should be the current date, as this is when we start tracking
+	debt.InterestLastCalculated = time.Now()
+
+	debt.TotalInterestPaid = decimal.NewFromFloat(0.0) // Initial interest paid is 0
+
+	// Estimated payoff date can be calculated based on minimum payment
+	estimatedPayoffDate, err := app.calculateEstimatedPayoffDate(debt)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -870,6 +1436,7 @@ func (app *application) createNewDebtHandler(w http.ResponseWriter, r *http.Requ
 
 // updateDebtByIDHandler() updates an existing debt in the database
 // We perform additional validation and calculations before saving the updated debt
+// A payment_amount, with an optional account_id, is paid the same way makeDebtPaymentHandler() pays it
 func (app *application) updateDebtHandler(w http.ResponseWriter, r *http.Request) {
 	// Get DebtID
 	debtID, err := app.readIDParam(r, "debtID")
@@ -887,6 +1454,12 @@ func (app *application) updateDebtHandler(w http.ResponseWriter, r *http.Request
 		DueDate        *time.Time       `json:"due_date"`
 		MinimumPayment *decimal.Decimal `json:"minimum_payment"`
 		PaymentAmount  *decimal.Decimal `json:"payment_amount"`
+		AccountID      int64            `json:"account_id"`
+		// interest accrual
+		CompoundingFrequency *string          `json:"compounding_frequency"`
+		InterestRateType     *string          `json:"interest_rate_type"`
+		LateFee              *decimal.Decimal `json:"late_fee"`
+		GracePeriodDays      *int32           `json:"grace_period_days"`
 	}
 
 	// Parse the JSON request
@@ -928,6 +1501,30 @@ func (app *application) updateDebtHandler(w http.ResponseWriter, r *http.Request
 	if input.MinimumPayment != nil {
 		debt.MinimumPayment = *input.MinimumPayment
 	}
+	// accrue the interest at the current terms before changing how interest accrues
+	if input.CompoundingFrequency != nil || input.InterestRateType != nil {
+		debt.AccrueInterest(time.Now())
+	}
+	if input.CompoundingFrequency != nil {
+		debt.CompoundingFrequency, err = app.models.FinancialTrackingManager.MapToDebtCompoundingFrequency(*input.CompoundingFrequency)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if input.InterestRateType != nil {
+		debt.InterestRateType, err = app.models.FinancialTrackingManager.MapToDebtInterestRateType(*input.InterestRateType)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	if input.LateFee != nil {
+		debt.LateFee = *input.LateFee
+	}
+	if input.GracePeriodDays != nil {
+		debt.GracePeriodDays = *input.GracePeriodDays
+	}
 
 	// Validate the updated debt
 	v := validator.New()
@@ -937,7 +1534,7 @@ func (app *application) updateDebtHandler(w http.ResponseWriter, r *http.Request
 	}
 
 	// Recalculate estimated payoff date if the amount, interest rate, or minimum payment changed
-	if input.Amount != nil || input.InterestRate != nil || input.MinimumPayment != nil {
+	if input.Amount != nil || input.InterestRate != nil || input.MinimumPayment != nil || input.InterestRateType != nil {
 		estimatedPayoffDate, err := app.calculateEstimatedPayoffDate(debt)
 		if err != nil {
 			app.serverErrorResponse(w, r, err)
@@ -946,52 +1543,24 @@ func (app *application) updateDebtHandler(w http.ResponseWriter, r *http.Request
 		debt.EstimatedPayoffDate = estimatedPayoffDate
 	}
 
-	// Handle payments, if any (ensure payment amount distribution between principal and interest)
+	// Handle payments, if any. They go through the same steps as makeDebtPaymentHandler() and
+	// save the debt's other changes together with the repayment
 	if input.PaymentAmount != nil {
-		paymentAmount := *input.PaymentAmount
-		interestPayment, err := app.calculateInterestPayment(debt) // Function to calculate interest payment based on debt
-		if err != nil {
-			app.serverErrorResponse(w, r, err)
-			return
-		}
-
-		if paymentAmount.LessThan(interestPayment) {
-			app.badRequestResponse(w, r, errors.New("payment amount is less than the interest due"))
-			return
-		}
-
-		principalPayment := paymentAmount.Sub(interestPayment)
-
-		// Ensure that payment amount matches interest + principal
-		if !paymentAmount.Equal(interestPayment.Add(principalPayment)) {
-			app.badRequestResponse(w, r, errors.New("payment amount does not match the sum of interest and principal"))
-			return
-		}
-
-		// Insert payment into debt_payments table
-		payment := &data.DebtRepayment{
-			DebtID:           debtID,
-			UserID:           app.contextGetUser(r).ID,
-			PaymentAmount:    paymentAmount,
-			PaymentDate:      time.Now(),
-			InterestPayment:  interestPayment,
-			PrincipalPayment: principalPayment,
-		}
-
-		err = app.models.FinancialTrackingManager.CreateNewDebtPayment(app.contextGetUser(r).ID, payment)
-		if err != nil {
+		err = app.applyDebtPaymentHelper(v, app.contextGetUser(r), debt, *input.PaymentAmount, input.AccountID)
+	} else {
+		err = app.models.FinancialTrackingManager.UpdateDebtByID(app.contextGetUser(r).ID, debt)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
 			app.serverErrorResponse(w, r, err)
-			return
 		}
-
-		// Update debt's remaining balance after payment
-		debt.RemainingBalance = debt.RemainingBalance.Sub(principalPayment)
+		return
 	}
-
-	// Save the updated debt back to the database
-	err = app.models.FinancialTrackingManager.UpdateDebtByID(app.contextGetUser(r).ID, debt)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
@@ -1161,14 +1730,12 @@ func (app *application) getDebtPaymentsByDebtUserIDHandler(w http.ResponseWriter
 
 // makeDebtPaymentHandler() is a handler method that will handle debt payments
 // We fetch the debt by ID, fetch the user input (payment amount, etc.)
-// We check if the payment is late and accrued interest
-// If it is late, we calculate overdue interest since the last calculated date
-// We update the accrued interest in the debt
+// We accrue the interest since the interest was last calculated, so that the payment
+// always covers the interest up to today even if the accrual job has not run yet
 // We handle the payment by distributing between interest and principal
-// We insert the payment into the debt_payments table
 // We update the remaining balance and reset accrued interest
 // We update the next payment date
-// We save the updated debt
+// We insert the payment into the debt_payments table and save the updated debt in one transaction
 // We respond with the updated debt
 func (app *application) makeDebtPaymentHandler(w http.ResponseWriter, r *http.Request) {
 	// Step 1: Read and validate the debt ID from the URL parameters
@@ -1178,9 +1745,10 @@ func (app *application) makeDebtPaymentHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Step 2: Parse the input payment amount from the request body
+	// Step 2: Parse the input payment amount and the account it is paid from
 	var input struct {
 		PaymentAmount decimal.Decimal `json:"payment_amount"`
+		AccountID     int64           `json:"account_id"`
 	}
 
 	err = app.readJSON(w, r, &input)
@@ -1198,95 +1766,435 @@ func (app *application) makeDebtPaymentHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	// Step 3.1: Check if the paymentAmount is more than the remaining balance
-	if input.PaymentAmount.GreaterThan(debt.RemainingBalance) {
-		// calculate the allowed payment amount
-		allowedPaymentAmount := debt.RemainingBalance.Add(debt.AccruedInterest)
-		v.AddError("payment_amount", fmt.Sprintf("payment amount is more than the remaining balance. The allowed payment amount is %s", allowedPaymentAmount.String()))
+	// Step 4: Pay the debt off, interest first, and save the repayment together with the debt
+	err = app.applyDebtPaymentHelper(v, app.contextGetUser(r), debt, input.PaymentAmount, input.AccountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	// Step 4: Check if the accrued interest needs to be updated (if the cron job hasn't run)
-	if debt.AccruedInterest.IsZero() && time.Now().After(debt.NextPaymentDate) {
-		// If the interest has not been calculated for the overdue period, calculate it now
-		interestAccrued, err := app.calculateInterestPayment(debt)
-		if err != nil {
-			app.serverErrorResponse(w, r, err)
-			return
-		}
-		// Add the accrued interest to the debt's accrued interest
-		debt.AccruedInterest = debt.AccruedInterest.Add(interestAccrued)
+	// Step 5: Respond with the updated debt record
+	err = app.writeJSON(w, http.StatusOK, envelope{"debt": debt}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// applyDebtPaymentHelper() pays an amount off a debt from the given account, an account ID of 0
+// meaning no account. The interest accrued up to today is paid first and the rest goes to the
+// principal, the next payment date moves on and the repayment is saved together with the debt.
+// Problems with the payment are added to v, only unexpected errors are returned
+func (app *application) applyDebtPaymentHelper(v *validator.Validator, user *data.User, debt *data.Debt, paymentAmount decimal.Decimal, accountID int64) error {
+	// The payment is made in the user's currency, so the account must be as well
+	err := app.validateAccountHelper(v, debt.UserID, accountID, user.CurrencyCode)
+	if err != nil || !v.Valid() {
+		return err
 	}
 
-	// Step 5: Calculate the interest and principal portions of the payment
+	// Accrue the interest since the accrual job last ran so that the payment covers it
+	debt.AccrueInterest(time.Now())
+
+	// Check if the paymentAmount is more than the remaining balance and the accrued interest
+	allowedPaymentAmount := debt.RemainingBalance.Add(debt.AccruedInterest)
+	if paymentAmount.GreaterThan(allowedPaymentAmount) {
+		v.AddError("payment_amount", fmt.Sprintf("payment amount is more than the remaining balance. The allowed payment amount is %s", allowedPaymentAmount.String()))
+		return nil
+	}
+
+	// Calculate the interest and principal portions of the payment
 	interestPayment := debt.AccruedInterest
-	principalPayment := input.PaymentAmount.Sub(interestPayment)
+	principalPayment := paymentAmount.Sub(interestPayment)
 
-	// Step 6: If the payment does not cover the accrued interest, return an error
+	// If the payment does not cover the accrued interest, return an error
 	if principalPayment.LessThan(decimal.NewFromFloat(0)) {
 		v.AddError("payment_amount", "payment does not cover accrued interest")
-		app.failedValidationResponse(w, r, v.Errors)
-		return
+		return nil
 	}
 
-	// Step 7: Create a new debt repayment record
+	// Create a new debt repayment record
 	payment := &data.DebtRepayment{
-		DebtID:           debtID,
-		UserID:           app.contextGetUser(r).ID,
-		PaymentAmount:    input.PaymentAmount,
+		DebtID:           debt.ID,
+		UserID:           debt.UserID,
+		PaymentAmount:    paymentAmount,
 		PaymentDate:      time.Now(),
 		InterestPayment:  interestPayment,
 		PrincipalPayment: principalPayment,
+		AccountID:        accountID,
 	}
 
-	// Step 8: Save the new debt repayment record in the database
-	err = app.models.FinancialTrackingManager.CreateNewDebtPayment(debt.UserID, payment)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
-
-	// Step 9: Update the debt's remaining balance by subtracting the principal payment
+	// Update the debt's remaining balance and reset the accrued interest
 	debt.RemainingBalance = debt.RemainingBalance.Sub(principalPayment)
-
-	// Step 10: Reset the accrued interest to 0 after the payment is made
 	debt.AccruedInterest = decimal.NewFromFloat(0)
 
-	// Step 11: Set the next payment date (assuming monthly payments)
-	debt.NextPaymentDate = debt.NextPaymentDate.AddDate(0, 1, 0) // Add one month to the next payment date
+	// Move the next payment date on to the following payment
+	debt.AdvanceNextPaymentDate()
 
-	// Step 12: Recalculate the estimated payoff date based on the updated debt details
+	// Recalculate the estimated payoff date based on the updated debt details
 	newPayoffDate, err := app.calculateEstimatedPayoffDate(debt)
 	if err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
+		return err
 	}
 	debt.EstimatedPayoffDate = newPayoffDate
 
-	// Step 13: Update the last payment date and Total Interest Paid
+	// Update the last payment date and Total Interest Paid
 	debt.LastPaymentDate = time.Now()
 	debt.TotalInterestPaid = debt.TotalInterestPaid.Add(interestPayment)
 
-	// Step 14: Update the debt record in the database with the new balance and dates
-	err = app.models.FinancialTrackingManager.UpdateDebtByID(debt.UserID, debt)
+	// Save the repayment and the debt in one go
+	err = app.models.FinancialTrackingManager.PostDebtPayment(debt, payment)
 	if err != nil {
 		switch {
-		case errors.Is(err, data.ErrGeneralRecordNotFound):
-			app.notFoundResponse(w, r)
 		case errors.Is(err, data.ErrInvalidRemainingBalance):
-			// calculate balance required to be entered and create a v.AddError
 			v.AddError("payment_amount", "payment amount is more than the remaining balance")
+			return nil
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// getDebtAmortizationScheduleHandler() returns the full amortization table of a single debt.
+// The actual payments of the debt are overlaid on the table to show whether the user is ahead
+// or behind schedule. The table can be downloaded as a CSV by setting the format to csv.
+func (app *application) getDebtAmortizationScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	// Get DebtID
+	debtID, err := app.readIDParam(r, "debtID")
+	if err != nil || debtID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	// Read and validate the query parameters
+	v := validator.New()
+	format := app.readString(r.URL.Query(), "format", "json")
+	if v.Check(validator.PermittedValue(format, "json", "csv"), "format", "must be either json or csv"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// get the user
+	user := app.contextGetUser(r)
+	// get the debt
+	debt, err := app.models.FinancialTrackingManager.GetDebtByID(user.ID, debtID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// get all the payments made towards the debt
+	payments, err := app.getAllDebtPaymentsHelper(user.ID, debtID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// build the schedule
+	schedule, err := data.BuildDebtAmortizationSchedule(debt, payments, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDebtNeverAmortizes):
+			v.AddError("minimum_payment", err.Error())
 			app.failedValidationResponse(w, r, v.Errors)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
 		return
 	}
+	// send the CSV if requested
+	if format == "csv" {
+		err = app.writeCSV(w, http.StatusOK, fmt.Sprintf("debt_%d_amortization.csv", debtID), amortizationScheduleToCSV(schedule))
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// Send the response
+	err = app.writeJSON(w, http.StatusOK, envelope{"amortization_schedule": schedule}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
 
-	// Step 14: Respond with the updated debt record
-	err = app.writeJSON(w, http.StatusOK, envelope{"debt": debt}, nil)
+// getAllDebtPaymentsHelper() pages through all the payments made towards a debt and returns them.
+// A debt without payments returns an empty slice.
+func (app *application) getAllDebtPaymentsHelper(userID, debtID int64) ([]*data.DebtRepayment, error) {
+	var payments []*data.DebtRepayment
+	currentPage := 1
+	for {
+		filters := data.Filters{
+			Page:     currentPage,
+			PageSize: 100,
+		}
+		enrichedPayments, metadata, err := app.models.FinancialTrackingManager.GetDebtPaymentsByDebtUserID(userID, debtID, time.Time{}, time.Now(), filters)
+		if err != nil {
+			if errors.Is(err, data.ErrGeneralRecordNotFound) {
+				return payments, nil
+			}
+			return nil, err
+		}
+		for _, enrichedPayment := range enrichedPayments {
+			payments = append(payments, enrichedPayment.DebtPayment)
+		}
+		// check if this is the last page of records
+		if metadata.LastPage == metadata.CurrentPage {
+			return payments, nil
+		}
+		currentPage = metadata.CurrentPage + 1
+	}
+}
+
+// amortizationScheduleToCSV() converts an amortization schedule into CSV records, starting with a header row
+func amortizationScheduleToCSV(schedule *data.DebtAmortizationSchedule) [][]string {
+	records := [][]string{{
+		"payment_number", "payment_date", "payment_amount", "interest_portion", "principal_portion",
+		"remaining_balance", "actual_payment", "actual_principal", "actual_balance", "status",
+	}}
+	for _, entry := range schedule.Entries {
+		actualBalance := ""
+		if entry.ActualBalance != nil {
+			actualBalance = entry.ActualBalance.StringFixed(2)
+		}
+		records = append(records, []string{
+			strconv.Itoa(entry.PaymentNumber),
+			entry.PaymentDate.Format(time.DateOnly),
+			entry.PaymentAmount.StringFixed(2),
+			entry.InterestPortion.StringFixed(2),
+			entry.PrincipalPortion.StringFixed(2),
+			entry.RemainingBalance.StringFixed(2),
+			entry.ActualPayment.StringFixed(2),
+			entry.ActualPrincipal.StringFixed(2),
+			actualBalance,
+			entry.Status,
+		})
+	}
+	return records
+}
+
+// simulateDebtPayoffHandler() simulates paying off all of a user's debts with a monthly budget.
+// The snowball and avalanche strategies are always simulated, while the custom strategy is only
+// simulated when a custom order is provided. We return the simulations and a comparison between them.
+func (app *application) simulateDebtPayoffHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		MonthlyBudget decimal.Decimal   `json:"monthly_budget"`
+		CustomOrder   []int64           `json:"custom_order"`
+		StartDate     *data.CustomTime1 `json:"start_date"`
+	}
+	// read the request body into the input struct
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	// validate the input
+	v := validator.New()
+	if data.ValidateDebtPayoffPlan(v, input.MonthlyBudget, input.CustomOrder); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// get all the outstanding debts of the user
+	debts, err := app.getAllUserDebtsHelper(app.contextGetUser(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// simulate and compare the strategies
+	comparison, err := data.CompareDebtPayoffStrategies(debts, input.MonthlyBudget, input.CustomOrder, debtPayoffStartDate(input.StartDate))
+	if err != nil {
+		app.debtPayoffSimulationErrorResponse(w, r, err)
+		return
+	}
+	// send the response
+	err = app.writeJSON(w, http.StatusOK, envelope{"comparison": comparison}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// saveDebtPayoffPlanHandler() saves the payoff plan chosen by a user.
+// The chosen strategy is simulated against the user's current debts so that the saved plan
+// holds the projected payoff date and total interest at the time it was chosen.
+func (app *application) saveDebtPayoffPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Strategy      string            `json:"strategy"`
+		MonthlyBudget decimal.Decimal   `json:"monthly_budget"`
+		CustomOrder   []int64           `json:"custom_order"`
+		StartDate     *data.CustomTime1 `json:"start_date"`
+	}
+	// read the request body into the input struct
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	// map the strategy
+	strategy, err := app.models.FinancialTrackingManager.MapToDebtPayoffStrategy(input.Strategy)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrInvalidDebtPayoffStrategy):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// the custom order is only kept for the custom strategy
+	if strategy != data.DebtPayoffStrategyCustom {
+		input.CustomOrder = []int64{}
+	}
+	// validate the input
+	v := validator.New()
+	v.Check(strategy != data.DebtPayoffStrategyCustom || len(input.CustomOrder) > 0, "custom_order", "must be provided for the custom strategy")
+	if data.ValidateDebtPayoffPlan(v, input.MonthlyBudget, input.CustomOrder); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// get the user
+	user := app.contextGetUser(r)
+	// get all the outstanding debts of the user
+	debts, err := app.getAllUserDebtsHelper(user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// simulate the chosen strategy
+	simulation, err := data.SimulateDebtPayoff(debts, input.MonthlyBudget, strategy, input.CustomOrder, debtPayoffStartDate(input.StartDate))
+	if err != nil {
+		app.debtPayoffSimulationErrorResponse(w, r, err)
+		return
+	}
+	// save the plan
+	plan := &data.DebtPayoffPlan{
+		Strategy:            strategy,
+		MonthlyBudget:       input.MonthlyBudget,
+		CustomOrder:         input.CustomOrder,
+		ProjectedPayoffDate: simulation.PayoffDate,
+		TotalMonths:         int32(simulation.TotalMonths),
+		TotalInterest:       simulation.TotalInterest,
+	}
+	err = app.models.FinancialTrackingManager.SaveDebtPayoffPlan(user.ID, plan)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// send the response
+	err = app.writeJSON(w, http.StatusCreated, envelope{"payoff_plan": plan, "simulation": simulation}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getDebtPayoffPlanHandler() returns the saved payoff plan of a user together with a fresh
+// simulation of the plan against the user's current debts, which shows whether the user is on track.
+// If the plan can no longer be simulated, we still return the plan along with the reason.
+func (app *application) getDebtPayoffPlanHandler(w http.ResponseWriter, r *http.Request) {
+	// get the user
+	user := app.contextGetUser(r)
+	// get the saved plan
+	plan, err := app.models.FinancialTrackingManager.GetDebtPayoffPlanByUserID(user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// simulate the plan with the current debts
+	debts, err := app.getAllUserDebtsHelper(user.ID)
+	if err == nil {
+		var simulation *data.DebtPayoffSimulation
+		simulation, err = data.SimulateDebtPayoff(debts, plan.MonthlyBudget, plan.Strategy, plan.CustomOrder, debtPayoffStartDate(nil))
+		if err == nil {
+			err = app.writeJSON(w, http.StatusOK, envelope{"payoff_plan": plan, "simulation": simulation}, nil)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+	}
+	switch {
+	case errors.Is(err, data.ErrGeneralRecordNotFound),
+		errors.Is(err, data.ErrInsufficientPayoffBudget),
+		errors.Is(err, data.ErrDebtPayoffNotPossible),
+		errors.Is(err, data.ErrInvalidDebtPayoffOrder):
+		err = app.writeJSON(w, http.StatusOK, envelope{"payoff_plan": plan, "message": err.Error()}, nil)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+		}
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getAllUserDebtsHelper() pages through all the debts of a user and returns them.
+// We return a data.ErrGeneralRecordNotFound if the user has no debts.
+func (app *application) getAllUserDebtsHelper(userID int64) ([]*data.Debt, error) {
+	var debts []*data.Debt
+	currentPage := 1
+	for {
+		filters := data.Filters{
+			Page:     currentPage,
+			PageSize: 100,
+		}
+		debtsWithPayments, metadata, err := app.models.FinancialTrackingManager.GetAllDebtsByUserID(userID, "", filters)
+		if err != nil {
+			return nil, err
+		}
+		for _, debtWithPayments := range debtsWithPayments {
+			debts = append(debts, debtWithPayments.Debt)
+		}
+		// check if this is the last page of records
+		if metadata.LastPage == metadata.CurrentPage {
+			return debts, nil
+		}
+		currentPage = metadata.CurrentPage + 1
+	}
+}
+
+// debtPayoffSimulationErrorResponse() maps the errors of a debt payoff simulation to a response
+func (app *application) debtPayoffSimulationErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	v := validator.New()
+	switch {
+	case errors.Is(err, data.ErrGeneralRecordNotFound):
+		app.notFoundResponse(w, r)
+	case errors.Is(err, data.ErrInsufficientPayoffBudget),
+		errors.Is(err, data.ErrDebtPayoffNotPossible):
+		v.AddError("monthly_budget", err.Error())
+		app.failedValidationResponse(w, r, v.Errors)
+	case errors.Is(err, data.ErrInvalidDebtPayoffOrder):
+		v.AddError("custom_order", err.Error())
+		app.failedValidationResponse(w, r, v.Errors)
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// debtPayoffStartDate() returns the date a debt payoff simulation starts from, defaulting to today
+func debtPayoffStartDate(startDate *data.CustomTime1) time.Time {
+	if startDate != nil && !startDate.Time.IsZero() {
+		return startDate.Time
 	}
+	return time.Now().Truncate(24 * time.Hour)
 }
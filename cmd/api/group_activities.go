@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"go.uber.org/zap"
+)
+
+// publishGroupActivity() pushes an activity, already recorded together with what it describes, to
+// the other members of the group who are connected. The request has already been answered by the
+// time this runs, so failures are only logged
+func (app *application) publishGroupActivity(activity *data.GroupActivity) {
+	app.background(func() {
+		groupID, userID := activity.GroupID, activity.UserID
+		memberIDs, err := app.models.FinancialGroupManager.GetAcceptedGroupMemberIDs(groupID)
+		if err != nil {
+			app.logger.Error("Error getting group members to send activity to", zap.Int64("group_id", groupID), zap.Error(err))
+			return
+		}
+		notificationContent := data.NotificationContent{
+			Message: activity.Message(),
+			Meta: data.NotificationMeta{
+				Url:      fmt.Sprintf("%s/%d", app.config.frontend.groupurl, groupID),
+				ImageUrl: activity.ProfileAvatarURL,
+				Tags:     fmt.Sprintf("group,activity,%s", activity.ActivityType),
+			},
+		}
+		for _, memberID := range memberIDs {
+			if memberID == userID {
+				continue
+			}
+			err = app.PublishLiveNotificationToRedis(memberID, data.NotificationTypeGroupActivity, notificationContent)
+			if err != nil {
+				app.logger.Error("Error publishing group activity to Redis", zap.Int64("user_id", memberID), zap.Error(err))
+			}
+		}
+	})
+}
+
+// getGroupActivitiesHandler() returns a group's timeline, newest first.
+// This route supports Pagination and an optional type filter to show one kind of activity
+func (app *application) getGroupActivitiesHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	var input struct {
+		ActivityType string
+		data.Filters
+	}
+	v := validator.New()
+	qs := r.URL.Query()
+	input.ActivityType = app.readString(qs, "type", "")
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	// the timeline is always newest first
+	input.Filters.Sort = app.readString(qs, "", "")
+	input.Filters.SortSafelist = []string{"", ""}
+	data.ValidateGroupActivityType(v, input.ActivityType)
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// check the user may see the group
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
+	activities, metadata, err := app.models.FinancialGroupManager.GetGroupActivitiesByGroupID(groupID, input.ActivityType, input.Filters)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"group_activities": activities, "metadata": metadata}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
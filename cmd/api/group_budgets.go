@@ -0,0 +1,205 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// createNewGroupBudgetHandler() creates a category budget for a group.
+// Only admins of the group can set budgets and choose whether they are strict
+func (app *application) createNewGroupBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		GroupID     int64           `json:"group_id"`
+		Category    string          `json:"category"`
+		LimitAmount decimal.Decimal `json:"limit_amount"`
+		Period      string          `json:"period"`
+		IsStrict    bool            `json:"is_strict"`
+		Description string          `json:"description"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	budget := &data.GroupBudget{
+		GroupID:     input.GroupID,
+		Category:    input.Category,
+		LimitAmount: input.LimitAmount,
+		Period:      input.Period,
+		IsStrict:    input.IsStrict,
+		Description: input.Description,
+	}
+	// budgets are monthly unless told otherwise
+	if budget.Period == "" {
+		budget.Period = data.GroupBudgetPeriodMonthly
+	}
+	v := validator.New()
+	if data.ValidateGroupBudget(v, budget); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if !app.requireGroupPermission(w, r, budget.GroupID, data.GroupPermissionManageBudgets) {
+		return
+	}
+	err = app.models.FinancialGroupManager.CreateNewGroupBudget(app.contextGetUser(r).ID, budget)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateGroupBudget):
+			v.AddError("category", "the group already has a budget for this category")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"group_budget": budget}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getGroupBudgetsHandler() returns the budgets of a group with what has been spent against each
+// of them in the current period. Any member of the group can see them
+func (app *application) getGroupBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
+	budgets, err := app.models.FinancialGroupManager.GetGroupBudgetSummariesByGroupID(groupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"group_budgets": budgets}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateGroupBudgetHandler() changes a group budget. Only admins of the group can change budgets
+func (app *application) updateGroupBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group budget ID from the URL
+	budgetID, err := app.readIDParam(r, "groupBudgetID")
+	if err != nil || budgetID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	var input struct {
+		Category    *string          `json:"category"`
+		LimitAmount *decimal.Decimal `json:"limit_amount"`
+		Period      *string          `json:"period"`
+		IsStrict    *bool            `json:"is_strict"`
+		Description *string          `json:"description"`
+	}
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	budget, err := app.models.FinancialGroupManager.GetGroupBudgetByID(budgetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if !app.requireGroupPermission(w, r, budget.GroupID, data.GroupPermissionManageBudgets) {
+		return
+	}
+	// CHECK FOR CHANGES
+	if input.Category != nil {
+		budget.Category = *input.Category
+	}
+	if input.LimitAmount != nil {
+		budget.LimitAmount = *input.LimitAmount
+	}
+	if input.Period != nil {
+		budget.Period = *input.Period
+	}
+	if input.IsStrict != nil {
+		budget.IsStrict = *input.IsStrict
+	}
+	if input.Description != nil {
+		budget.Description = *input.Description
+	}
+	v := validator.New()
+	if data.ValidateGroupBudget(v, budget); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	err = app.models.FinancialGroupManager.UpdateGroupBudget(app.contextGetUser(r).ID, budget)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateGroupBudget):
+			v.AddError("category", "the group already has a budget for this category")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"group_budget": budget}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteGroupBudgetHandler() deletes a group budget. Only admins of the group can delete budgets
+func (app *application) deleteGroupBudgetHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group budget ID from the URL
+	budgetID, err := app.readIDParam(r, "groupBudgetID")
+	if err != nil || budgetID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	budget, err := app.models.FinancialGroupManager.GetGroupBudgetByID(budgetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if !app.requireGroupPermission(w, r, budget.GroupID, data.GroupPermissionManageBudgets) {
+		return
+	}
+	err = app.models.FinancialGroupManager.DeleteGroupBudget(app.contextGetUser(r).ID, budgetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "group budget deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// groupBudgetExceededMessage() describes a group expense going over the group's budget for its category
+func groupBudgetExceededMessage(budget *data.GroupBudgetSummary) string {
+	return fmt.Sprintf("expense amount is more than the %s left in the group's %s %s budget",
+		decimal.Max(budget.Remaining, decimal.Zero).StringFixed(2), budget.Period, budget.Category)
+}
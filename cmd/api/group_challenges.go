@@ -0,0 +1,278 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// getGroupChallengeHelper() gets a challenge and checks the user may act on it in its group,
+// sending the response when they may not. It reports whether the handler can go on
+func (app *application) getGroupChallengeHelper(w http.ResponseWriter, r *http.Request, permission data.GroupPermission) (*data.GroupChallenge, bool) {
+	// get the challenge ID from the URL
+	challengeID, err := app.readIDParam(r, "challengeID")
+	if err != nil || challengeID < 1 {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+	challenge, err := app.models.FinancialGroupManager.GetGroupChallengeByID(challengeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+	if !app.requireGroupPermission(w, r, challenge.GroupID, permission) {
+		return nil, false
+	}
+	return challenge, true
+}
+
+// createNewGroupChallengeHandler() creates a time-boxed challenge in a group, such as saving 500
+// this month or not spending at weekends. Only admins and moderators of the group can create
+// challenges. The group's other members are invited to opt in
+func (app *application) createNewGroupChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		GroupID       int64            `json:"group_id"`
+		Name          string           `json:"name"`
+		Description   string           `json:"description"`
+		ChallengeType string           `json:"challenge_type"`
+		TargetAmount  decimal.Decimal  `json:"target_amount"`
+		WeekendsOnly  bool             `json:"weekends_only"`
+		StartsOn      data.CustomTime1 `json:"starts_on"`
+		EndsOn        data.CustomTime1 `json:"ends_on"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	challenge := &data.GroupChallenge{
+		GroupID:       input.GroupID,
+		Name:          input.Name,
+		Description:   input.Description,
+		ChallengeType: input.ChallengeType,
+		TargetAmount:  input.TargetAmount,
+		WeekendsOnly:  input.WeekendsOnly,
+		StartsOn:      input.StartsOn.Truncate(24 * time.Hour),
+		EndsOn:        input.EndsOn.Truncate(24 * time.Hour),
+	}
+	// challenges start today unless told otherwise
+	if challenge.StartsOn.IsZero() {
+		challenge.StartsOn = today
+	}
+	v := validator.New()
+	if data.ValidateGroupChallenge(v, challenge, today); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if !app.requireGroupPermission(w, r, challenge.GroupID, data.GroupPermissionManageChallenges) {
+		return
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(challenge.GroupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	user := app.contextGetUser(r)
+	err = app.models.FinancialGroupManager.CreateNewGroupChallenge(user.ID, challenge)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"group_challenge": challenge}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+	// invite the rest of the group to take part
+	memberIDs, err := app.models.FinancialGroupManager.GetAcceptedGroupMemberIDs(group.ID)
+	if err != nil {
+		app.logger.Error("Error getting group members to tell about a new challenge", zap.Int64("group_id", group.ID), zap.Error(err))
+		return
+	}
+	message := fmt.Sprintf("%s %s started the challenge %s in %s. Join in before it ends on %s",
+		user.FirstName, user.LastName, challenge.Name, group.Name, challenge.EndsOn.Format("2006-01-02"))
+	for _, memberID := range memberIDs {
+		if memberID == user.ID {
+			continue
+		}
+		app.publishGroupChallengeNotification(memberID, challenge, message)
+	}
+}
+
+// getGroupChallengesHandler() returns the challenges of a group to its members
+func (app *application) getGroupChallengesHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
+	challenges, err := app.models.FinancialGroupManager.GetGroupChallengesByGroupID(app.contextGetUser(r).ID, groupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"group_challenges": challenges}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getGroupChallengeLeaderboardHandler() returns a challenge with the standings of its
+// participants, leaders first
+func (app *application) getGroupChallengeLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	challenge, ok := app.getGroupChallengeHelper(w, r, data.GroupPermissionViewGroup)
+	if !ok {
+		return
+	}
+	standings, err := app.models.FinancialGroupManager.GetGroupChallengeLeaderboard(challenge.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"group_challenge": challenge, "leaderboard": standings}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// joinGroupChallengeHandler() opts the user into a challenge that has not ended. A personal goal
+// of the user's can be linked to a savings challenge so their contributions to it count as well
+func (app *application) joinGroupChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		GoalID int64 `json:"goal_id"`
+	}
+	// the body is optional, it only carries a goal to link
+	if r.ContentLength != 0 {
+		err := app.readJSON(w, r, &input)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	challenge, ok := app.getGroupChallengeHelper(w, r, data.GroupPermissionContribute)
+	if !ok {
+		return
+	}
+	v := validator.New()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if v.Check(challenge.Status == data.GroupChallengeStatusActive && !challenge.HasEnded(today), "challenge", "this challenge has ended"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	user := app.contextGetUser(r)
+	if input.GoalID != 0 {
+		if v.Check(challenge.ChallengeType == data.GroupChallengeTypeSavings, "goal_id", "goals can only be linked to savings challenges"); !v.Valid() {
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+		_, err := app.models.FinancialManager.GetGoalByID(user.ID, input.GoalID)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrGeneralRecordNotFound):
+				v.AddError("goal_id", "goal not found")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+	}
+	joinedAt, err := app.models.FinancialGroupManager.JoinGroupChallenge(challenge.ID, user.ID, input.GoalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateGroupChallengeParticipant):
+			v.AddError("challenge", "you are already taking part in this challenge")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"message": "you have joined the challenge", "joined_at": joinedAt}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// leaveGroupChallengeHandler() opts the user out of a challenge that has not ended
+func (app *application) leaveGroupChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	challenge, ok := app.getGroupChallengeHelper(w, r, data.GroupPermissionContribute)
+	if !ok {
+		return
+	}
+	v := validator.New()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if v.Check(challenge.Status == data.GroupChallengeStatusActive && !challenge.HasEnded(today), "challenge", "this challenge has ended"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	err := app.models.FinancialGroupManager.LeaveGroupChallenge(challenge.ID, app.contextGetUser(r).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("challenge", "you are not taking part in this challenge")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "you have left the challenge"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteGroupChallengeHandler() deletes a challenge. Only admins and moderators of the group can
+// delete challenges
+func (app *application) deleteGroupChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	challenge, ok := app.getGroupChallengeHelper(w, r, data.GroupPermissionManageChallenges)
+	if !ok {
+		return
+	}
+	err := app.models.FinancialGroupManager.DeleteGroupChallenge(challenge.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "group challenge deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// publishGroupChallengeNotification() sends a participant a notification about a challenge
+func (app *application) publishGroupChallengeNotification(userID int64, challenge *data.GroupChallenge, message string) {
+	notificationContent := data.NotificationContent{
+		Message: message,
+		Meta: data.NotificationMeta{
+			Url:      fmt.Sprintf("%s/%d", app.config.frontend.groupurl, challenge.GroupID),
+			ImageUrl: "",
+			Tags:     "group,challenge",
+		},
+	}
+	err := app.PublishNotificationToRedis(userID, data.NotificationTypeGroupChallenge, notificationContent)
+	if err != nil {
+		app.logger.Error("Error publishing group challenge notification", zap.Int64("user_id", userID), zap.Int64("challenge_id", challenge.ID), zap.Error(err))
+	}
+}
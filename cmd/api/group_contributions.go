@@ -0,0 +1,237 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// createNewGroupContributionScheduleHandler() schedules recurring dues for a group goal.
+// Only admins and moderators of the group can schedule dues. The first dues fall on the first
+// due day on or after the start date, which defaults to today
+func (app *application) createNewGroupContributionScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		GoalID             int64            `json:"goal_id"`
+		AmountPerMember    decimal.Decimal  `json:"amount_per_member"`
+		RecurrenceInterval string           `json:"recurrence_interval"`
+		DueDay             int32            `json:"due_day"`
+		StartDate          data.CustomTime1 `json:"start_date"`
+		Description        string           `json:"description"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	schedule := &data.GroupContributionSchedule{
+		GoalID:             input.GoalID,
+		AmountPerMember:    input.AmountPerMember,
+		RecurrenceInterval: input.RecurrenceInterval,
+		DueDay:             input.DueDay,
+		Description:        input.Description,
+	}
+	// dues are monthly unless told otherwise
+	if schedule.RecurrenceInterval == "" {
+		schedule.RecurrenceInterval = "monthly"
+	}
+	v := validator.New()
+	if data.ValidateGroupContributionSchedule(v, schedule); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	startDate := input.StartDate.ToTime()
+	if startDate.IsZero() {
+		startDate = today
+	}
+	if v.Check(!startDate.Before(today), "start_date", "cannot be in the past"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// the goal's group decides who may schedule contributions to it
+	groupGoal, err := app.models.FinancialGroupManager.GetGroupGoalById(schedule.GoalID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupGoal.GroupID, data.GroupPermissionManageContributions) {
+		return
+	}
+	schedule.InitializeSchedule(startDate)
+	err = app.models.FinancialGroupManager.CreateNewGroupContributionSchedule(app.contextGetUser(r).ID, schedule)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateGroupContributionSchedule):
+			v.AddError("goal_id", "the goal already has a contribution schedule")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"contribution_schedule": schedule}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getGroupContributionSchedulesHandler() returns the contribution schedules of a group to its members
+func (app *application) getGroupContributionSchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
+	schedules, err := app.models.FinancialGroupManager.GetGroupContributionSchedulesByGroupID(groupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"contribution_schedules": schedules}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// updateGroupContributionScheduleHandler() changes the amount, description or active state of a
+// contribution schedule. Only admins and moderators of the group can change schedules.
+// Resuming a paused schedule skips the dues that would have fallen while it was paused
+func (app *application) updateGroupContributionScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	// get the schedule ID from the URL
+	scheduleID, err := app.readIDParam(r, "scheduleID")
+	if err != nil || scheduleID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	var input struct {
+		AmountPerMember *decimal.Decimal `json:"amount_per_member"`
+		IsActive        *bool            `json:"is_active"`
+		Description     *string          `json:"description"`
+	}
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	schedule, err := app.models.FinancialGroupManager.GetGroupContributionScheduleByID(scheduleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if !app.requireGroupPermission(w, r, schedule.GroupID, data.GroupPermissionManageContributions) {
+		return
+	}
+	// CHECK FOR CHANGES
+	if input.AmountPerMember != nil {
+		schedule.AmountPerMember = *input.AmountPerMember
+	}
+	if input.Description != nil {
+		schedule.Description = *input.Description
+	}
+	if input.IsActive != nil {
+		if *input.IsActive && !schedule.IsActive {
+			schedule.FastForward(time.Now().UTC())
+		}
+		schedule.IsActive = *input.IsActive
+	}
+	v := validator.New()
+	if data.ValidateGroupContributionSchedule(v, schedule); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	err = app.models.FinancialGroupManager.UpdateGroupContributionSchedule(app.contextGetUser(r).ID, schedule)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"contribution_schedule": schedule}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteGroupContributionScheduleHandler() deletes a contribution schedule and its dues.
+// Only admins and moderators of the group can delete schedules
+func (app *application) deleteGroupContributionScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	// get the schedule ID from the URL
+	scheduleID, err := app.readIDParam(r, "scheduleID")
+	if err != nil || scheduleID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	schedule, err := app.models.FinancialGroupManager.GetGroupContributionScheduleByID(scheduleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if !app.requireGroupPermission(w, r, schedule.GroupID, data.GroupPermissionManageContributions) {
+		return
+	}
+	err = app.models.FinancialGroupManager.DeleteGroupContributionSchedule(app.contextGetUser(r).ID, scheduleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "contribution schedule deleted"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getGroupContributionArrearsHandler() returns the arrears report of a group: what each member has
+// paid and still owes of the dues that have fallen due, members owing the most first
+func (app *application) getGroupContributionArrearsHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
+	arrears, err := app.models.FinancialGroupManager.GetGroupContributionArrearsByGroupID(groupID, time.Now().UTC())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"arrears": arrears}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
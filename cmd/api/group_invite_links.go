@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+// createNewGroupInviteLinkHandler() makes a shareable invite link for a group. Only admins of the
+// group can make links. The link's token is only returned here, it cannot be looked up later
+func (app *application) createNewGroupInviteLinkHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		GroupID   int64            `json:"group_id"`
+		Role      string           `json:"role"`
+		MaxUses   int32            `json:"max_uses"`
+		ExpiresAt data.CustomTime1 `json:"expires_at"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	now := time.Now()
+	link := &data.GroupInviteLink{
+		GroupID:   input.GroupID,
+		Role:      input.Role,
+		MaxUses:   input.MaxUses,
+		ExpiresAt: input.ExpiresAt.ToTime(),
+	}
+	// links make plain members that expire in a week unless told otherwise
+	if link.Role == "" {
+		link.Role = string(data.GroupRoleMember)
+	}
+	if link.ExpiresAt.IsZero() {
+		link.ExpiresAt = now.Add(data.DefaultGroupInviteLinkTTL)
+	}
+	v := validator.New()
+	if data.ValidateGroupInviteLink(v, link, now); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if !app.requireGroupPermission(w, r, link.GroupID, data.GroupPermissionManageInviteLinks) {
+		return
+	}
+	err = app.models.FinancialGroupManager.CreateNewGroupInviteLink(app.contextGetUser(r).ID, link)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	inviteURL := app.config.frontend.groupinvitelinkurl + link.Token
+	err = app.writeJSON(w, http.StatusCreated, envelope{"invite_link": link, "invite_url": inviteURL}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getGroupInviteLinksHandler() returns the invite links of a group with how much each has been
+// used. Only admins of the group can see them
+func (app *application) getGroupInviteLinksHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionManageInviteLinks) {
+		return
+	}
+	links, err := app.models.FinancialGroupManager.GetGroupInviteLinksByGroupID(groupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"invite_links": links}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// revokeGroupInviteLinkHandler() stops an invite link from being used. Only admins of the group
+// can revoke links. Members who already joined through the link stay in the group
+func (app *application) revokeGroupInviteLinkHandler(w http.ResponseWriter, r *http.Request) {
+	// get the invite link ID from the URL
+	linkID, err := app.readIDParam(r, "linkID")
+	if err != nil || linkID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	err = app.models.FinancialGroupManager.RevokeGroupInviteLink(app.contextGetUser(r).ID, linkID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "invite link revoked"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// acceptGroupInviteLinkHandler() joins the user to the group an invite link belongs to, with the
+// link's role. The link must still be active and the group must have room
+func (app *application) acceptGroupInviteLinkHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Token string `json:"token"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	v := validator.New()
+	if data.ValidateTokenPlaintext(v, input.Token); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	link, err := app.models.FinancialGroupManager.GetGroupInviteLinkByToken(input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("token", "invalid invite link")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	switch link.Status {
+	case data.GroupInviteLinkStatusExpired:
+		v.AddError("token", "this invite link has expired")
+	case data.GroupInviteLinkStatusRevoked:
+		v.AddError("token", "this invite link has been revoked")
+	case data.GroupInviteLinkStatusUsedUp:
+		v.AddError("token", "this invite link has been used up")
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	user := app.contextGetUser(r)
+	// check if the user is already a member
+	err = app.models.FinancialGroupManager.CheckIfGroupExistsAndUserIsMember(user.ID, link.GroupID)
+	if err == nil {
+		v.AddError("token", "you are already a member of this group")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if !errors.Is(err, data.ErrGeneralRecordNotFound) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(link.GroupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if group.IsArchived() {
+		v.AddError("token", "this group is archived and can no longer be joined")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// the link's uses and the group's member limit are checked as the membership is made
+	activity := &data.GroupActivity{
+		GroupID:      group.ID,
+		UserID:       user.ID,
+		ActivityType: data.GroupActivityMemberJoined,
+		Description:  "joined the group",
+	}
+	var membershipID int64
+	err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+		membershipID, err = txModel.CreateGroupMembershipFromInviteLink(user.ID, link)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGroupMembersMaxedOut):
+			v.AddError("token", "this group has reached its maximum member count")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrGroupInviteLinkInvalid):
+			v.AddError("token", "this invite link can no longer be used")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrUserGroupMembershipExists):
+			v.AddError("token", "you are already a member of this group")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"message": "group membership created", "membership_id": membershipID, "group_id": group.ID}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+	app.publishGroupActivity(activity)
+	// let the admin who made the link know someone used it
+	if link.CreatorUserID != 0 {
+		notificationContent := data.NotificationContent{
+			Message: fmt.Sprintf("%s %s joined the group %s through your invite link", user.FirstName, user.LastName, group.Name),
+			Meta: data.NotificationMeta{
+				Url:      fmt.Sprintf("%s/%d", app.config.frontend.groupurl, group.ID),
+				ImageUrl: user.ProfileAvatarURL,
+				Tags:     "group,invitation",
+			},
+		}
+		app.PublishNotificationToRedis(link.CreatorUserID, data.NotificationTypeGroupInvite, notificationContent)
+	}
+}
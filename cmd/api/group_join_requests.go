@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+// createNewGroupJoinRequestHandler() asks to join a discoverable private group. The request waits
+// for the group's admins and moderators, who are notified about it, to approve or reject it
+func (app *application) createNewGroupJoinRequestHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		GroupID int64  `json:"group_id"`
+		Message string `json:"message"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	user := app.contextGetUser(r)
+	request := &data.GroupJoinRequest{
+		GroupID: input.GroupID,
+		UserID:  user.ID,
+		Message: input.Message,
+	}
+	v := validator.New()
+	if data.ValidateGroupJoinRequest(v, request); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// get the group by the details
+	group, err := app.models.FinancialGroupManager.GetGroupById(input.GroupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("group_id", "this group does not exist")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// public groups are joined directly and hidden private groups are not let on to exist
+	switch {
+	case !group.IsPrivate:
+		v.AddError("group_id", "this group is public, join it directly")
+	case !group.AcceptsJoinRequests():
+		v.AddError("group_id", "this group does not exist")
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// check if the user is already a member
+	err = app.models.FinancialGroupManager.CheckIfGroupExistsAndUserIsMember(user.ID, group.ID)
+	if err == nil {
+		v.AddError("group_id", "you are already a member of this group")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if !errors.Is(err, data.ErrGeneralRecordNotFound) {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// check if the group's members are already maxed out
+	isMaxedOut, err := app.models.FinancialGroupManager.CheckIfGroupMembersAreMaxedOut(group.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if isMaxedOut {
+		v.AddError("group_id", "this group has reached its maximum member count")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	moderatorIDs, err := app.models.FinancialGroupManager.GetGroupModeratorUserIDs(group.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.models.FinancialGroupManager.CreateNewGroupJoinRequest(request)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateGroupJoinRequest):
+			v.AddError("group_id", "you already have a pending request to join this group")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"join_request": request}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+	// let the admins and moderators know there is a request to review
+	notificationContent := data.NotificationContent{
+		Message: fmt.Sprintf("%s %s has asked to join the group %s", user.FirstName, user.LastName, group.Name),
+		Meta: data.NotificationMeta{
+			Url:      fmt.Sprintf("%s/%d", app.config.frontend.groupurl, group.ID),
+			ImageUrl: user.ProfileAvatarURL,
+			Tags:     "group,join_request",
+		},
+	}
+	for _, moderatorID := range moderatorIDs {
+		app.PublishNotificationToRedis(moderatorID, data.NotificationTypeGroupJoinRequest, notificationContent)
+	}
+}
+
+// getGroupJoinRequestsHandler() returns the join requests of a group that are waiting on review.
+// Only admins and moderators of the group can see them
+func (app *application) getGroupJoinRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionReviewJoinRequests) {
+		return
+	}
+	requests, err := app.models.FinancialGroupManager.GetPendingGroupJoinRequestsByGroupID(groupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"join_requests": requests}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// reviewGroupJoinRequestHandler() approves or rejects a pending join request. Only admins and
+// moderators of the group can review requests. Approving makes the user a member as long as the
+// group still has room, and the user is notified of the decision either way
+func (app *application) reviewGroupJoinRequestHandler(w http.ResponseWriter, r *http.Request) {
+	// get the join request ID from the URL
+	requestID, err := app.readIDParam(r, "requestID")
+	if err != nil || requestID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	var input struct {
+		Status string `json:"status"`
+	}
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	v := validator.New()
+	if data.ValidateGroupJoinRequestReview(v, input.Status); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	request, err := app.models.FinancialGroupManager.GetGroupJoinRequestByID(requestID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if !app.requireGroupPermission(w, r, request.GroupID, data.GroupPermissionReviewJoinRequests) {
+		return
+	}
+	if v.Check(request.Status == data.GroupJoinRequestStatusPending, "status", "this request has already been reviewed"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(request.GroupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// approving checks the group still has room and adds the member together with the review and
+	// the member's entry on the group's timeline
+	var activity *data.GroupActivity
+	if input.Status == data.GroupJoinRequestStatusApproved {
+		activity = &data.GroupActivity{
+			GroupID:      group.ID,
+			UserID:       request.UserID,
+			ActivityType: data.GroupActivityMemberJoined,
+			Description:  "joined the group",
+		}
+		err = app.models.FinancialGroupManager.RecordGroupActivity(activity, func(txModel data.FinancialGroupManagerModel) error {
+			return txModel.ApproveGroupJoinRequest(app.contextGetUser(r).ID, request)
+		})
+	} else {
+		err = app.models.FinancialGroupManager.ReviewGroupJoinRequest(app.contextGetUser(r).ID, request, input.Status)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrGroupMembersMaxedOut):
+			v.AddError("status", "this group has reached its maximum member count")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrUserGroupMembershipExists):
+			v.AddError("status", "the user is already a member of this group")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"join_request": request}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+	if activity != nil {
+		app.publishGroupActivity(activity)
+	}
+	// let the user know how their request went
+	notificationContent := data.NotificationContent{
+		Message: fmt.Sprintf("Your request to join the group %s has been %s", group.Name, request.Status),
+		Meta: data.NotificationMeta{
+			Url:      fmt.Sprintf("%s/%d", app.config.frontend.groupurl, group.ID),
+			ImageUrl: group.GroupImageURL,
+			Tags:     "group,join_request",
+		},
+	}
+	app.PublishNotificationToRedis(request.UserID, data.NotificationTypeGroupJoinRequest, notificationContent)
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"go.uber.org/zap"
+)
+
+// getGroupForOwnerHelper() gets a group for an action only its owner can take. Members who do not
+// own the group get a 403 and everyone else a 404. It reports whether the handler can go on
+func (app *application) getGroupForOwnerHelper(w http.ResponseWriter, r *http.Request, groupID int64) (*data.Group, bool) {
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return nil, false
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(groupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return nil, false
+	}
+	if group.CreatorUserID != app.contextGetUser(r).ID {
+		app.errorResponse(w, r, http.StatusForbidden, "only the owner of this group can do this")
+		return nil, false
+	}
+	return group, true
+}
+
+// transferGroupOwnershipHandler() hands a group from its owner to another admin of the group.
+// The old owner stays an admin of the group
+func (app *application) transferGroupOwnershipHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	var input struct {
+		UserID int64 `json:"user_id"`
+	}
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	if data.ValidateURLID(v, input.UserID, "user_id"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if v.Check(input.UserID != user.ID, "user_id", "you already own this group"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	group, ok := app.getGroupForOwnerHelper(w, r, groupID)
+	if !ok {
+		return
+	}
+	err = app.models.FinancialGroupManager.TransferGroupOwnership(user.ID, input.UserID, group)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("user_id", "must be an admin of the group")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrGroupNameExists):
+			v.AddError("user_id", "this user already owns a group with the same name")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"group": group}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+	// let the new owner know
+	notificationContent := data.NotificationContent{
+		Message: fmt.Sprintf("%s %s made you the owner of the group %s", user.FirstName, user.LastName, group.Name),
+		Meta: data.NotificationMeta{
+			Url:      fmt.Sprintf("%s/%d", app.config.frontend.groupurl, group.ID),
+			ImageUrl: group.GroupImageURL,
+			Tags:     "group,ownership",
+		},
+	}
+	app.PublishNotificationToRedis(input.UserID, data.NotificationTypeGroupUpdate, notificationContent)
+}
+
+// archiveGroupHandler() archives a group. Archived groups are read-only and are no longer listed
+// publicly. Only admins of the group can archive it
+func (app *application) archiveGroupHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionArchiveGroup) {
+		return
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(groupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	v := validator.New()
+	if v.Check(!group.IsArchived(), "group", "this group is already archived"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	err = app.models.FinancialGroupManager.ArchiveGroup(app.contextGetUser(r).ID, group)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"group": group}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// unarchiveGroupHandler() restores an archived group. Admins can restore archived groups, but
+// only the owner can restore a group that is waiting to be deleted, which cancels the deletion
+func (app *application) unarchiveGroupHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionArchiveGroup) {
+		return
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(groupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	user := app.contextGetUser(r)
+	v := validator.New()
+	if v.Check(group.IsArchived(), "group", "this group is not archived"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if group.DeletionScheduledAt != nil && group.CreatorUserID != user.ID {
+		app.errorResponse(w, r, http.StatusForbidden, "only the owner of this group can cancel its deletion")
+		return
+	}
+	err = app.models.FinancialGroupManager.UnarchiveGroup(user.ID, group)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"group": group}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteGroupHandler() deletes a group. Only the owner can delete a group. The group is archived
+// straight away and purged once data.GroupDeletionGracePeriod has passed, the members are told
+// and can export the group's data until then. The owner can cancel by restoring the group
+func (app *application) deleteGroupHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	group, ok := app.getGroupForOwnerHelper(w, r, groupID)
+	if !ok {
+		return
+	}
+	v := validator.New()
+	if v.Check(group.DeletionScheduledAt == nil, "group", "this group is already being deleted"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	user := app.contextGetUser(r)
+	deleteAt := time.Now().Add(data.GroupDeletionGracePeriod)
+	err = app.models.FinancialGroupManager.ScheduleGroupDeletion(user.ID, group, deleteAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.editConflictResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusAccepted, envelope{"message": "the group will be deleted", "group": group}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+	// offer every other member the group's data before it goes
+	memberIDs, err := app.models.FinancialGroupManager.GetAcceptedGroupMemberIDs(group.ID)
+	if err != nil {
+		app.logger.Error("Error getting group members to notify of the group's deletion", zap.Int64("group_id", group.ID), zap.Error(err))
+		return
+	}
+	notificationContent := data.NotificationContent{
+		Message: fmt.Sprintf("The group %s will be deleted on %s. Export the group's data before then if you want to keep it",
+			group.Name, deleteAt.Format("2006-01-02")),
+		Meta: data.NotificationMeta{
+			Url:      fmt.Sprintf("%s/%d", app.config.frontend.groupurl, group.ID),
+			ImageUrl: group.GroupImageURL,
+			Tags:     "group,deletion,export",
+		},
+	}
+	for _, memberID := range memberIDs {
+		if memberID == user.ID {
+			continue
+		}
+		app.PublishNotificationToRedis(memberID, data.NotificationTypeGroupUpdate, notificationContent)
+	}
+}
+
+// exportGroupDataHandler() sends everything in a group, from its members and goals to its budgets,
+// dues and comments, as a JSON download. Any member can export the group, archived or not
+func (app *application) exportGroupDataHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
+	group, err := app.models.FinancialGroupManager.GetGroupById(groupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	export, err := app.models.FinancialGroupManager.GetGroupExport(group)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("group_%d_export.json", group.ID)))
+	err = app.writeJSON(w, http.StatusOK, envelope{"group_export": export}, headers)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+)
+
+// requireGroupPermission() checks that the user may do something in a group and sends the
+// response when they may not: a 404 when they are not a member, so the group is not let on to
+// exist, a 403 when their role does not allow it and a 409 when the group is archived.
+// It reports whether the handler can go on
+func (app *application) requireGroupPermission(w http.ResponseWriter, r *http.Request, groupID int64, permission data.GroupPermission) bool {
+	_, err := app.models.FinancialGroupManager.AuthorizeGroupAction(app.contextGetUser(r).ID, groupID, permission)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		case errors.Is(err, data.ErrGroupPermissionDenied):
+			app.notPermittedResponse(w, r)
+		case errors.Is(err, data.ErrGroupArchived):
+			app.groupArchivedResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return false
+	}
+	return true
+}
+
+// getGroupPermissionsHandler() returns the user's role in a group and what that role lets them do.
+// Only viewing and restoring the group are left once it is archived
+func (app *application) getGroupPermissionsHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	role, archived, err := app.models.FinancialGroupManager.GetGroupMemberAccess(app.contextGetUser(r).ID, groupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"role": role, "archived": archived, "permissions": data.GroupAccessPermissions(role, archived)}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
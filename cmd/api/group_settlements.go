@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// groupExpenseSplitInput is how a group expense is split, as sent by the user. Each member's share
+// is their number of shares, exact amount or percentage and is left out for an equal split
+type groupExpenseSplitInput struct {
+	SplitType string                    `json:"split_type"`
+	Members   []*data.GroupExpenseSplit `json:"members"`
+}
+
+// updateGroupExpenseSplitHandler() replaces the split of a group expense.
+// Only the member who paid the expense can change how it is shared
+func (app *application) updateGroupExpenseSplitHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group expense ID from the URL
+	groupExpenseID, err := app.readIDParam(r, "groupExpenseID")
+	if err != nil || groupExpenseID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	var input groupExpenseSplitInput
+	err = app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	user := app.contextGetUser(r)
+	// get the group expense
+	groupExpense, err := app.models.FinancialGroupManager.GetGroupExpenseByID(groupExpenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// only the payer of the expense can split it
+	if groupExpense.MemberID != user.ID {
+		app.errorResponse(w, r, http.StatusForbidden, "only the member who recorded this expense can change how it is split")
+		return
+	}
+	// the payer has to still be in the group
+	if !app.requireGroupPermission(w, r, groupExpense.GroupID, data.GroupPermissionAddExpense) {
+		return
+	}
+	v := validator.New()
+	err = app.groupExpenseSplitHelper(v, groupExpense, &input)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	err = app.models.FinancialGroupManager.SaveGroupExpenseSplit(groupExpense)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"group_expense": groupExpense}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getGroupBalancesHandler() returns what each member of a group owes or is owed, what members owe
+// each other directly and the fewest transfers that would settle the group
+func (app *application) getGroupBalancesHandler(w http.ResponseWriter, r *http.Request) {
+	// get the group ID from the URL
+	groupID, err := app.readIDParam(r, "groupID")
+	if err != nil || groupID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	// only members can see the balances
+	if !app.requireGroupPermission(w, r, groupID, data.GroupPermissionViewGroup) {
+		return
+	}
+	balances, err := app.getGroupBalancesHelper(groupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"balances": balances}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// createNewGroupSettlementHandler() records a payment from the user to another member of the group.
+// Settlements are group transactions, so they are removed like any other group transaction
+func (app *application) createNewGroupSettlementHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		GroupID           int64           `json:"group_id"`
+		RecipientMemberID int64           `json:"recipient_member_id"`
+		Amount            decimal.Decimal `json:"amount"`
+		Description       string          `json:"description"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	user := app.contextGetUser(r)
+	settlement := &data.GroupSettlement{
+		GroupID:           input.GroupID,
+		MemberID:          user.ID,
+		RecipientMemberID: input.RecipientMemberID,
+		Amount:            input.Amount,
+		Description:       input.Description,
+	}
+	v := validator.New()
+	if data.ValidateGroupSettlement(v, settlement); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// the user has to be a member of the group
+	if !app.requireGroupPermission(w, r, settlement.GroupID, data.GroupPermissionContribute) {
+		return
+	}
+	// and so does the recipient
+	memberIDs, err := app.models.FinancialGroupManager.GetAcceptedGroupMemberIDs(settlement.GroupID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if v.Check(slices.Contains(memberIDs, settlement.RecipientMemberID), "recipient_member_id", "must be a member of the group"); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	err = app.models.FinancialGroupManager.CreateNewGroupSettlement(user.ID, settlement)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"group_settlement": settlement}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// groupExpenseSplitHelper() checks a split against the group and works out what each member owes,
+// setting the split on the expense. An equal split without members is shared by the whole group.
+// Validation problems are added to v, only lookup failures are returned
+func (app *application) groupExpenseSplitHelper(v *validator.Validator, expense *data.GroupExpense, input *groupExpenseSplitInput) error {
+	memberIDs, err := app.models.FinancialGroupManager.GetAcceptedGroupMemberIDs(expense.GroupID)
+	if err != nil {
+		return err
+	}
+	members := input.Members
+	if input.SplitType == data.GroupSplitEqual && len(members) == 0 {
+		for _, memberID := range memberIDs {
+			members = append(members, &data.GroupExpenseSplit{MemberID: memberID})
+		}
+	}
+	if data.ValidateGroupExpenseSplit(v, expense.Amount, input.SplitType, members); !v.Valid() {
+		return nil
+	}
+	for _, member := range members {
+		if !slices.Contains(memberIDs, member.MemberID) {
+			v.AddError("split.members", "must only contain members of the group")
+			return nil
+		}
+	}
+	data.CalculateGroupExpenseSplit(expense.Amount, input.SplitType, members)
+	expense.SplitType = input.SplitType
+	expense.Splits = members
+	return nil
+}
+
+// getGroupBalancesHelper() loads the split expenses and settlements of a group and works out its balances
+func (app *application) getGroupBalancesHelper(groupID int64) (*data.GroupBalances, error) {
+	memberIDs, err := app.models.FinancialGroupManager.GetAcceptedGroupMemberIDs(groupID)
+	if err != nil {
+		return nil, err
+	}
+	splits, err := app.models.FinancialGroupManager.GetGroupExpenseSplitsByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	settlements, err := app.models.FinancialGroupManager.GetGroupSettlementsByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	return data.BuildGroupBalances(memberIDs, splits, settlements), nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -54,6 +55,71 @@ func (app *application) writeJSON(w http.ResponseWriter, status int, data envelo
 	return nil
 }
 
+// writeCSV() is a helper for sending CSV downloads. It writes the records to the response
+// with a Content-Disposition header so that clients save the response under the given filename.
+func (app *application) writeCSV(w http.ResponseWriter, status int, filename string, records [][]string) error {
+	// Encode the records into a buffer first so that we can still return an error before
+	// anything is written to the response.
+	var buf bytes.Buffer
+	csvWriter := csv.NewWriter(&buf)
+	if err := csvWriter.WriteAll(records); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+	return nil
+}
+
+// writePDF() is a helper for sending PDF downloads, the document is saved under the given filename.
+func (app *application) writePDF(w http.ResponseWriter, status int, filename string, document []byte) error {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(status)
+	_, err := w.Write(document)
+	return err
+}
+
+// readMultipartFile() reads a single uploaded file from a multipart/form-data request.
+// The body is limited to maxBytes plus a small allowance for the other form parts. The
+// content type is sniffed from the first 512 bytes so we never rely on what the client says.
+// The caller must close the returned file and call r.MultipartForm.RemoveAll() once done.
+func (app *application) readMultipartFile(w http.ResponseWriter, r *http.Request, field string, maxBytes int64) (multipart.File, *multipart.FileHeader, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+1_048_576)
+	// keep up to 1MB in memory, anything bigger is spooled to a temporary file
+	err := r.ParseMultipartForm(1_048_576)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesError):
+			return nil, nil, "", fmt.Errorf("file must not be larger than %d bytes", maxBytes)
+		default:
+			return nil, nil, "", errors.New("request body must be a valid multipart form")
+		}
+	}
+	file, header, err := r.FormFile(field)
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			return nil, nil, "", fmt.Errorf("a file must be provided in the %q field", field)
+		}
+		return nil, nil, "", err
+	}
+	// sniff the content type and rewind
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		file.Close()
+		return nil, nil, "", err
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		file.Close()
+		return nil, nil, "", err
+	}
+	return file, header, http.DetectContentType(sniff[:n]), nil
+}
+
 func (app *application) readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
 	// Use http.MaxBytesReader() to limit the size of the request body to 1MB.
 	maxBytes := 1_048_576
@@ -218,7 +284,8 @@ func (app *application) verifyCurrencyInRedis(currency string) error {
 	return nil
 }
 
-// getAndSaveAvailableCurrencies() gets the available currencies from the exchange rate API
+// getAndSaveAvailableCurrencies() gets the available currencies from the exchange rate API.
+// The currencies are saved to Redis and their rates are kept as the rates of the day they were published
 func (app *application) getAndSaveAvailableCurrencies() error {
 	url := fmt.Sprintf("%s/%s/latest/%s", app.config.api.apikeys.exchangerates.url,
 		app.config.api.apikeys.exchangerates.key, app.config.api.defaultcurrency)
@@ -231,6 +298,35 @@ func (app *application) getAndSaveAvailableCurrencies() error {
 	if err != nil {
 		return err
 	}
+	// Save the day's rates to the exchange rate history
+	rateDate := time.Now().UTC()
+	if currencies.TimeLastUpdate > 0 {
+		rateDate = time.Unix(currencies.TimeLastUpdate, 0).UTC()
+	}
+	savedCount, err := app.models.ExchangeRateManager.SaveExchangeRates(app.config.api.defaultcurrency, rateDate, currencies.ConversionRates)
+	if err != nil {
+		return err
+	}
+	app.logger.Info("Saved exchange rates", zap.Int64("count", savedCount), zap.String("rate_date", rateDate.Format(time.DateOnly)))
+	return nil
+}
+
+// getAndSaveHistoricalExchangeRates() gets the rates of a past day from the exchange rate API's history
+// and saves them to the exchange rate history, so conversions of that day use the rate of the day.
+//
+// Api format is: https://v6.exchangerate-api.com/v6/<api-key>/history/USD/2024/1/31
+func (app *application) getAndSaveHistoricalExchangeRates(date time.Time) error {
+	url := fmt.Sprintf("%s/%s/history/%s/%d/%d/%d", app.config.api.apikeys.exchangerates.url,
+		app.config.api.apikeys.exchangerates.key, app.config.api.defaultcurrency, date.Year(), int(date.Month()), date.Day())
+	currencies, err := GETRequest[data.CurrencyRates](app.http_client, url, nil)
+	if err != nil {
+		return err
+	}
+	savedCount, err := app.models.ExchangeRateManager.SaveExchangeRates(app.config.api.defaultcurrency, date, currencies.ConversionRates)
+	if err != nil {
+		return err
+	}
+	app.logger.Info("Saved historical exchange rates", zap.Int64("count", savedCount), zap.String("rate_date", date.Format(time.DateOnly)))
 	return nil
 }
 
@@ -248,6 +344,20 @@ func (app *application) readString(qs url.Values, key string, defaultValue strin
 	return s
 }
 
+// The readCSV() helper reads a string value from the query string and then splits it
+// into a slice on the comma character. If no matching key could be found, it returns
+// the provided default value.
+func (app *application) readCSV(qs url.Values, key string, defaultValue []string) []string {
+	// Extract the value from the query string.
+	csv := qs.Get(key)
+	// If no key exists (or the value is empty) then return the default value.
+	if csv == "" {
+		return defaultValue
+	}
+	// Otherwise parse the value into a []string slice and return it.
+	return strings.Split(csv, ",")
+}
+
 // The readInt() helper reads a string value from the query string and converts it to an
 // integer before returning. If no matching key could be found it returns the provided
 // default value. If the value couldn't be converted to an integer, then we record an
@@ -364,7 +474,7 @@ func (app *application) calculateEstimatedPayoffDate(debt *data.Debt) (time.Time
 	}
 
 	remainingBalance := debt.Amount
-	interestRatePerMonth := debt.InterestRate.Div(decimal.NewFromInt(12)).Div(decimal.NewFromInt(100)) // Monthly interest
+	interestRatePerMonth := debt.MonthlyInterestRate() // Monthly interest
 	months := 0
 
 	// Loop through months and simulate payments until the balance is zero
@@ -384,36 +494,6 @@ func (app *application) calculateEstimatedPayoffDate(debt *data.Debt) (time.Time
 	return estimatedPayoffDate, nil
 }
 
-// calculateInterestPayment calculates the interest payment for a debt
-func (app *application) calculateInterestPayment(debt *data.Debt) (decimal.Decimal, error) {
-	// Ensure debt is not nil
-	if debt == nil {
-		return decimal.Zero, errors.New("debt cannot be nil")
-	}
-
-	// Ensure interest rate is a valid value
-	if debt.InterestRate.LessThan(decimal.NewFromFloat(0)) {
-		return decimal.Zero, errors.New("interest rate cannot be negative")
-	}
-
-	// Calculate the duration in days since the last interest was calculated
-	daysSinceLastCalculation := time.Since(debt.InterestLastCalculated).Hours() / 24
-	if daysSinceLastCalculation < 0 {
-		return decimal.Zero, errors.New("invalid calculation period")
-	}
-
-	// Convert annual interest rate to daily interest rate (assuming 365 days in a year)
-	dailyInterestRate := debt.InterestRate.Div(decimal.NewFromFloat(365))
-
-	// Calculate interest based on remaining balance and the number of days
-	interestPayment := debt.RemainingBalance.Mul(dailyInterestRate).Mul(decimal.NewFromFloat(daysSinceLastCalculation))
-
-	// Update the last calculated interest date to the current time
-	debt.InterestLastCalculated = time.Now()
-
-	return interestPayment, nil
-}
-
 // aunthenticatorHelper() is a helper function for the authentication middleware
 // It takes in a request and returns a user and an error
 // It retrieves the value of the Authorization header from the request. This will
@@ -465,28 +545,28 @@ func (app *application) aunthenticatorHelper(r *http.Request) (*data.User, error
 }
 
 // investmentTransactionValidatorHelper() is a helper validation function for the investment transaction handler
-// We take in a user ID and a *transaction struct. We extract the investmentID, from there we get the investment type
+// We take in the investment model to read through and a *transaction struct. We extract the investmentID, from there we get the investment type
 // Depending on that investment type i.e (stock,bond,alternative), we check if that ID exists for that user
 // in the respective table
 // If it does not exist, we add an error to the validator
-func (app *application) investmentTransactionValidatorHelper(v *validator.Validator, transaction *data.InvestmentTransaction) interface{} {
+func (app *application) investmentTransactionValidatorHelper(v *validator.Validator, investmentManager data.InvestmentPortfolioModel, transaction *data.InvestmentTransaction) interface{} {
 	var investment interface{}
 	// check if the investment exists
 	switch transaction.InvestmentType {
 	case data.InvPortInvestmentTypeStock:
-		stock, err := app.models.InvestmentPortfolioManager.GetStockByStockID(transaction.InvestmentID)
+		stock, err := investmentManager.GetStockByStockID(transaction.InvestmentID)
 		if err != nil {
 			v.AddError("investment_id", "stock investment does not exist")
 		}
 		investment = stock
 	case data.InvPortInvestmentTypeBond:
-		bond, err := app.models.InvestmentPortfolioManager.GetBondByBondID(transaction.InvestmentID)
+		bond, err := investmentManager.GetBondByBondID(transaction.InvestmentID)
 		if err != nil {
 			v.AddError("investment_id", "bond investment does not exist")
 		}
 		investment = bond
 	case data.InvPortInvestmentTypeAlternative:
-		alternative, err := app.models.InvestmentPortfolioManager.GetAlternativeInvestmentByAlternativeID(transaction.InvestmentID)
+		alternative, err := investmentManager.GetAlternativeInvestmentByAlternativeID(transaction.InvestmentID)
 		if err != nil {
 			v.AddError("investment_id", "alternative investment does not exist")
 		}
@@ -503,29 +583,30 @@ func (app *application) investmentTransactionValidatorHelper(v *validator.Valida
 // we need to recieve the type of transaction and an interface which we need to cast to the correct type
 // Then all we update is the quantity, if transaction type is sell, we substract the quantity
 // if transaction type is buy, we add the quantity. Each unique investment type will have its own
-// case for this function in terms of updates i.e stock, bond and alternative
-func (app *application) updateInvestmentTransactionHelper(userID int64, transactionType string, transactionQuantity decimal.Decimal, investment interface{}) error {
+// case for this function in terms of updates i.e stock, bond and alternative.
+// The investment model is passed in so that batches can make the update in their transaction
+func (app *application) updateInvestmentTransactionHelper(investmentManager data.InvestmentPortfolioModel, userID int64, transactionType string, transactionQuantity decimal.Decimal, investment interface{}) error {
 	switch transactionType {
 	case "buy":
 		switch t := investment.(type) {
 		case *data.StockInvestment:
 			t.Quantity = t.Quantity.Add(transactionQuantity)
 			// update passing the fully updated struct
-			err := app.models.InvestmentPortfolioManager.UpdateStockInvestment(userID, t)
+			err := investmentManager.UpdateStockInvestment(userID, t)
 			if err != nil {
 				return err
 			}
 		case *data.BondInvestment:
 			t.Quantity = t.Quantity.Add(transactionQuantity)
 			// update passing the fully updated struct
-			err := app.models.InvestmentPortfolioManager.UpdateBondInvestment(userID, t)
+			err := investmentManager.UpdateBondInvestment(userID, t)
 			if err != nil {
 				return err
 			}
 		case *data.AlternativeInvestment:
 			t.Quantity = t.Quantity.Add(transactionQuantity)
 			// update passing the fully updated struct
-			err := app.models.InvestmentPortfolioManager.UpdateAlternativeInvestment(userID, t)
+			err := investmentManager.UpdateAlternativeInvestment(userID, t)
 			if err != nil {
 				return err
 			}
@@ -535,21 +616,21 @@ func (app *application) updateInvestmentTransactionHelper(userID int64, transact
 		case *data.StockInvestment:
 			t.Quantity = t.Quantity.Sub(transactionQuantity)
 			// update passing the fully updated struct
-			err := app.models.InvestmentPortfolioManager.UpdateStockInvestment(userID, t)
+			err := investmentManager.UpdateStockInvestment(userID, t)
 			if err != nil {
 				return err
 			}
 		case *data.BondInvestment:
 			t.Quantity = t.Quantity.Sub(transactionQuantity)
 			// update passing the fully updated struct
-			err := app.models.InvestmentPortfolioManager.UpdateBondInvestment(userID, t)
+			err := investmentManager.UpdateBondInvestment(userID, t)
 			if err != nil {
 				return err
 			}
 		case *data.AlternativeInvestment:
 			t.Quantity = t.Quantity.Sub(transactionQuantity)
 			// update passing the fully updated struct
-			err := app.models.InvestmentPortfolioManager.UpdateAlternativeInvestment(userID, t)
+			err := investmentManager.UpdateAlternativeInvestment(userID, t)
 			if err != nil {
 				return err
 			}
@@ -619,6 +700,30 @@ func (app *application) proces1sOCRRequestHelper(url string) (*data.OCRResponse,
 	if err != nil {
 		return nil, err
 	}
+	return app.sendOCRRequestHelper(&requestBody, writer)
+}
+
+// processOCRFileRequestHelper() works like proces1sOCRRequestHelper() but uploads the file
+// itself instead of a URL, this is used for receipts uploaded directly to us.
+// The file name must carry the right extension as OCR.Space uses it to detect the file type
+func (app *application) processOCRFileRequestHelper(fileName string, file io.Reader) (*data.OCRResponse, error) {
+	var requestBody bytes.Buffer
+	writer := multipart.NewWriter(&requestBody)
+	// Add the file
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(part, file)
+	if err != nil {
+		return nil, err
+	}
+	return app.sendOCRRequestHelper(&requestBody, writer)
+}
+
+// sendOCRRequestHelper() adds the OCR engine options to the form, closes it and sends it to
+// the OCR.Space API
+func (app *application) sendOCRRequestHelper(requestBody *bytes.Buffer, writer *multipart.Writer) (*data.OCRResponse, error) {
 	// Add necessary fields for OCR engine 2 and other options
 	fields := map[string]string{
 		"language":                     "eng",
@@ -635,7 +740,7 @@ func (app *application) proces1sOCRRequestHelper(url string) (*data.OCRResponse,
 		}
 	}
 	// Close the writer
-	err = writer.Close()
+	err := writer.Close()
 	if err != nil {
 		return nil, err
 	}
@@ -644,22 +749,17 @@ func (app *application) proces1sOCRRequestHelper(url string) (*data.OCRResponse,
 		"apikey":       app.config.api.apikeys.ocrspace.key,
 		"Content-Type": writer.FormDataContentType(),
 	}
-	// print the body
-	//app.logger.Info(requestBody.String())
 	// call our POSTREQUEST http client with OCRResponse
 	response, err := POSTRequest[data.OCRResponse](
 		app.http_client,
 		app.config.api.apikeys.ocrspace.url,
 		headers,
-		requestBody,
+		*requestBody,
 		true,
 	)
 	if err != nil {
 		return nil, err
 	}
-	// print api key and url used
-	//app.logger.Info("ITEMS USED", zap.String("url", app.config.api.apikeys.ocrspace.url), zap.String("API Key", app.config.api.apikeys.ocrspace.key))
-	//app.logger.Info("OCR Response", zap.Any("response", response))
 	app.logger.Info("Done processing OCR request successfully")
 	return &response, nil
 }
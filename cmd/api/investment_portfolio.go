@@ -714,6 +714,7 @@ func (app *application) createNewInvestmentTransactionHandler(w http.ResponseWri
 		TransactionAmount decimal.Decimal  `json:"transaction_amount"`
 		TransactionDate   data.CustomTime1 `json:"transaction_date"`
 		Quantity          decimal.Decimal  `json:"quantity"`
+		AccountID         int64            `json:"account_id"`
 	}
 	// decode to input
 	err := app.readJSON(w, r, &input)
@@ -757,14 +758,25 @@ func (app *application) createNewInvestmentTransactionHandler(w http.ResponseWri
 		TransactionAmount: input.TransactionAmount,
 		TransactionDate:   input.TransactionDate,
 		Quantity:          input.Quantity,
+		AccountID:         input.AccountID,
 	}
 	// validate the transaction
 	if data.ValidateInvestmentTransaction(v, transaction); !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
+	// investments are held in the user's currency, so the account must be as well
+	err = app.validateAccountHelper(v, user.ID, transaction.AccountID, user.CurrencyCode)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
 	// validate the investment
-	resultValue := app.investmentTransactionValidatorHelper(v, transaction)
+	resultValue := app.investmentTransactionValidatorHelper(v, app.models.InvestmentPortfolioManager, transaction)
 	if !v.Valid() {
 		app.failedValidationResponse(w, r, v.Errors)
 		return
@@ -776,7 +788,7 @@ func (app *application) createNewInvestmentTransactionHandler(w http.ResponseWri
 		return
 	}
 	// if transaction was successful, let us update the investment
-	err = app.updateInvestmentTransactionHelper(user.ID, input.TransactionType, input.Quantity, resultValue)
+	err = app.updateInvestmentTransactionHelper(app.models.InvestmentPortfolioManager, user.ID, input.TransactionType, input.Quantity, resultValue)
 	if err != nil {
 		app.logger.Info("error updating investment", zap.Error(err))
 	}
@@ -15,9 +15,9 @@ import (
 	"time"
 
 	"github.com/Blue-Davinci/OptiVest/internal/data"
-	"github.com/Blue-Davinci/OptiVest/internal/database"
 	"github.com/Blue-Davinci/OptiVest/internal/logger"
 	"github.com/Blue-Davinci/OptiVest/internal/mailer"
+	"github.com/Blue-Davinci/OptiVest/internal/storage"
 	"github.com/Blue-Davinci/OptiVest/internal/vcs"
 	"github.com/go-redis/redis/v8"
 	"github.com/gorilla/websocket"
@@ -44,6 +44,7 @@ type config struct {
 	api  struct {
 		name            string
 		author          string
+		baseurl         string
 		defaultcurrency string
 		apikeys         struct { // api keys
 			alphavantage         apikey_details
@@ -104,6 +105,24 @@ type config struct {
 	encryption struct {
 		key string
 	}
+	storage struct {
+		backend         string
+		signingkey      string
+		signedurlexpiry time.Duration
+		maxuploadsize   int64
+		local           struct {
+			path    string
+			baseurl string
+		}
+		s3 struct {
+			endpoint     string
+			region       string
+			bucket       string
+			accesskey    string
+			secretkey    string
+			usepathstyle bool
+		}
+	}
 	frontend struct {
 		baseurl            string
 		activationurl      string
@@ -114,6 +133,7 @@ type config struct {
 		awardurl           string
 		groupurl           string
 		groupinvitationurl string
+		groupinvitelinkurl string
 		applogourl         string
 		profileurl         string
 		recoveryurl        string
@@ -123,14 +143,24 @@ type config struct {
 		trackGoalProgressStatus      *cron.Cron
 		trackExpiredGroupInvitations *cron.Cron
 		trackRecurringExpenses       *cron.Cron
-		trackOverdueDebts            *cron.Cron
+		trackRecurringIncomes        *cron.Cron
+		trackDebtInterestAccrual     *cron.Cron
 		trackExpiredNotifications    *cron.Cron
+		trackExpiredReceiptDrafts    *cron.Cron
+		trackDailyExchangeRates      *cron.Cron
+		trackSubscriptions           *cron.Cron
+		trackSpendingAnomalies       *cron.Cron
+		trackNetWorthSnapshots       *cron.Cron
+		trackGroupContributionDues   *cron.Cron
+		trackGroupDeletions          *cron.Cron
+		trackGroupChallenges         *cron.Cron
 		rssFeedScraper               *cron.Cron
 	}
 	limit struct {
 		monthlyGoalProcessingBatchLimit      int
 		recurringExpenseTrackerBurstLimit    int
-		overdueDebtTrackerBurstLimit         int
+		recurringIncomeTrackerBurstLimit     int
+		debtInterestAccrualBurstLimit        int
 		expiredNotificationTrackerBurstLimit int
 	}
 }
@@ -141,6 +171,7 @@ type application struct {
 	models            data.Models
 	http_client       *Optivet_Client
 	mailer            mailer.Mailer
+	storage           storage.Storage
 	wg                sync.WaitGroup
 	RedisDB           *redis.Client
 	Mutex             sync.Mutex
@@ -171,6 +202,7 @@ func main() {
 	// API configuration
 	flag.StringVar(&cfg.api.name, "api-name", "OptiVest", "API name")
 	flag.StringVar(&cfg.api.author, "api-author", "Blue_Davinci", "API author")
+	flag.StringVar(&cfg.api.baseurl, "api-base-url", "http://localhost:4000", "Public base URL of the API")
 	flag.StringVar(&cfg.api.defaultcurrency, "api-default-currency", "USD", "Default currency")
 	// API keys
 	// alpha vantage
@@ -214,6 +246,19 @@ func main() {
 	flag.BoolVar(&cfg.sanitization.usestrict, "sanitization-strict", false, "Use strict sanitization")
 	// Encryption key
 	flag.StringVar(&cfg.encryption.key, "encryption-key", os.Getenv("OPTIVEST_DATA_ENCRYPTION_KEY"), "Encryption key")
+	// Attachment storage configuration
+	flag.StringVar(&cfg.storage.backend, "storage-backend", storage.BackendLocal, "Attachment storage backend (local|s3)")
+	flag.StringVar(&cfg.storage.signingkey, "storage-signing-key", os.Getenv("OPTIVEST_STORAGE_SIGNING_KEY"), "Key used to sign local attachment download URLs, required by the local backend")
+	flag.DurationVar(&cfg.storage.signedurlexpiry, "storage-signed-url-expiry", data.DefaultAttachmentSignedURLExpiry, "Lifetime of attachment download URLs")
+	flag.Int64Var(&cfg.storage.maxuploadsize, "storage-max-upload-size", data.DefaultAttachmentMaxUploadSize, "Maximum attachment upload size in bytes")
+	flag.StringVar(&cfg.storage.local.path, "storage-local-path", "./uploads", "Directory used by the local storage backend")
+	flag.StringVar(&cfg.storage.local.baseurl, "storage-local-base-url", "http://localhost:4000/v1/attachments/download", "Public URL of the local attachment download endpoint")
+	flag.StringVar(&cfg.storage.s3.endpoint, "storage-s3-endpoint", "https://s3.amazonaws.com", "S3-compatible storage endpoint")
+	flag.StringVar(&cfg.storage.s3.region, "storage-s3-region", "us-east-1", "S3-compatible storage region")
+	flag.StringVar(&cfg.storage.s3.bucket, "storage-s3-bucket", os.Getenv("OPTIVEST_S3_BUCKET"), "S3-compatible storage bucket")
+	flag.StringVar(&cfg.storage.s3.accesskey, "storage-s3-access-key", os.Getenv("OPTIVEST_S3_ACCESS_KEY"), "S3-compatible storage access key")
+	flag.StringVar(&cfg.storage.s3.secretkey, "storage-s3-secret-key", os.Getenv("OPTIVEST_S3_SECRET_KEY"), "S3-compatible storage secret key")
+	flag.BoolVar(&cfg.storage.s3.usepathstyle, "storage-s3-path-style", true, "Use path style addressing for the S3-compatible storage")
 	// CORS configuration
 	flag.Func("cors-trusted-origins", "Trusted CORS origins (space separated)", func(val string) error {
 		cfg.cors.trustedOrigins = strings.Fields(val)
@@ -240,6 +285,7 @@ func main() {
 	flag.StringVar(&cfg.frontend.awardurl, "frontend-award-url", "http://localhost:5173/awards", "Frontend Award URL")
 	flag.StringVar(&cfg.frontend.groupurl, "frontend-group-url", "http://localhost:5173/dashboard/groups", "Frontend Group URL")
 	flag.StringVar(&cfg.frontend.groupinvitationurl, "frontend-group-invite-url", "http://localhost:5173/dashboard/groups/invitation", "Frontend Group invitation URL")
+	flag.StringVar(&cfg.frontend.groupinvitelinkurl, "frontend-group-invite-link-url", "http://localhost:5173/dashboard/groups/join?token=", "Frontend Group invite link URL")
 	flag.StringVar(&cfg.frontend.applogourl, "frontend-app-logo-url", "https://i.ibb.co/hZdMWvh/optivest-cropped.png", "Frontend App Logo URL")
 	flag.StringVar(&cfg.frontend.accountsettings, "frontend-account-settings", "http://localhost:5173/dashboard/account", "Frontend Account Settings URL")
 	flag.StringVar(&cfg.frontend.profileurl, "frontend-profile-url", "http://localhost:5173/dashboard/account", "Frontend Profile URL")
@@ -247,7 +293,8 @@ func main() {
 	// Limit configuration
 	flag.IntVar(&cfg.limit.monthlyGoalProcessingBatchLimit, "monthly-goal-batch-limit", 100, "Batching Limit for Monthly Goal Processing")
 	flag.IntVar(&cfg.limit.recurringExpenseTrackerBurstLimit, "recurring-expense-burst-limit", 100, "Batch Limit for Recurring Expense Tracker")
-	flag.IntVar(&cfg.limit.overdueDebtTrackerBurstLimit, "overdue-debt-burst-limit", 100, "Batch Limit for Overdue Debt Tracker")
+	flag.IntVar(&cfg.limit.recurringIncomeTrackerBurstLimit, "recurring-income-burst-limit", 100, "Batch Limit for Recurring Income Tracker")
+	flag.IntVar(&cfg.limit.debtInterestAccrualBurstLimit, "debt-interest-accrual-burst-limit", 100, "Batch Limit for Debt Interest Accrual")
 	flag.IntVar(&cfg.limit.expiredNotificationTrackerBurstLimit, "expired-notification-burst-limit", 100, "Batch Limit for Expired Notification Tracker")
 	// Parse the flags
 	flag.Parse()
@@ -256,8 +303,18 @@ func main() {
 	cfg.scheduler.trackGoalProgressStatus = cron.New()
 	cfg.scheduler.trackExpiredGroupInvitations = cron.New()
 	cfg.scheduler.trackRecurringExpenses = cron.New()
-	cfg.scheduler.trackOverdueDebts = cron.New()
+	cfg.scheduler.trackRecurringIncomes = cron.New()
+	cfg.scheduler.trackDebtInterestAccrual = cron.New()
 	cfg.scheduler.trackExpiredNotifications = cron.New()
+	cfg.scheduler.trackExpiredReceiptDrafts = cron.New()
+	cfg.scheduler.trackDailyExchangeRates = cron.New()
+	cfg.scheduler.trackSubscriptions = cron.New()
+	cfg.scheduler.trackSpendingAnomalies = cron.New()
+	// snapshots are keyed on UTC dates, so the job runs at the end of the UTC day
+	cfg.scheduler.trackNetWorthSnapshots = cron.New(cron.WithLocation(time.UTC))
+	cfg.scheduler.trackGroupContributionDues = cron.New()
+	cfg.scheduler.trackGroupDeletions = cron.New()
+	cfg.scheduler.trackGroupChallenges = cron.New()
 	cfg.scheduler.rssFeedScraper = cron.New()
 	// if the usestrict flag is set to true, then use the StrictPolicy() method to create a new Policy object.
 	// Otherwise, use the UGCPolicy() method to create a new Policy object.
@@ -287,6 +344,12 @@ func main() {
 	if err != nil {
 		logger.Fatal(err.Error(), zap.String("dsn", cfg.db.dsn))
 	}
+	// create our attachment storage
+	store, err := openStorage(cfg)
+	if err != nil {
+		logger.Fatal("Error while initializing attachment storage.", zap.String("error", err.Error()), zap.String("backend", cfg.storage.backend))
+	}
+	logger.Info("attachment storage initialized", zap.String("backend", cfg.storage.backend))
 	// create out http client
 	httpClient := NewClient(cfg.http_client.timeout, cfg.http_client.retrymax)
 	// log our connection pool
@@ -300,6 +363,7 @@ func main() {
 		models:      data.NewModels(db),
 		http_client: httpClient,
 		mailer:      mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
+		storage:     store,
 		RedisDB:     rdb,
 		Mutex:       sync.Mutex{},
 		WebSocketUpgrader: websocket.Upgrader{
@@ -360,8 +424,17 @@ func (app *application) startSchedulers() {
 		app.updateGoalProgressOnExpiredGoalsHandler() // updateGoalProgressOnExpiredGoals
 		app.trackExpiredGroupInvitationsHandler()     // trackExpiredGroupInvitations
 		app.trackRecurringExpensesHandler()           // trackRecurringExpenses
-		app.trackOverdueDebtsHandler()                // trackOverdueDebts
+		app.trackRecurringIncomesHandler()            // trackRecurringIncomes
+		app.trackDebtInterestAccrualHandler()         // trackDebtInterestAccrual
 		app.trackExpiredNotificationsHandler()        // trackExpiredNotification
+		app.trackExpiredReceiptDraftsHandler()        // trackExpiredReceiptDrafts
+		app.trackDailyExchangeRatesHandler()          // trackDailyExchangeRates
+		app.trackSubscriptionsHandler()               // trackSubscriptions
+		app.trackSpendingAnomaliesHandler()           // trackSpendingAnomalies
+		app.trackNetWorthSnapshotsHandler()           // trackNetWorthSnapshots
+		app.trackGroupContributionDuesHandler()       // trackGroupContributionDues
+		app.trackGroupDeletionsHandler()              // trackGroupDeletions
+		app.trackGroupChallengesHandler()             // trackGroupChallenges
 		app.startRssFeedScraperHandler()              // rssFeedScraper
 		app.listenToAwardNotifications()              // listenToAwardNotifications
 	})
@@ -414,7 +487,7 @@ func getEnvPath(logger *zap.Logger) string {
 
 // openDB() opens a new database connection using the provided configuration.
 // It returns a pointer to the sql.DB connection pool and an error value.
-func openDB(cfg config) (*database.Queries, error) {
+func openDB(cfg config) (*sql.DB, error) {
 	db, err := sql.Open("postgres", cfg.db.dsn)
 	if err != nil {
 		return nil, err
@@ -431,8 +504,7 @@ func openDB(cfg config) (*database.Queries, error) {
 	if err != nil {
 		return nil, err
 	}
-	queries := database.New(db)
-	return queries, nil
+	return db, nil
 }
 
 // openRedis() opens a new Redis connection using the provided configuration.
@@ -453,3 +525,27 @@ func openRedis(cfg config) (*redis.Client, error) {
 
 	return rdb, nil
 }
+
+// openStorage() creates the blob storage used for attachments based on the configured backend.
+// The local backend serves files through the API while s3 talks to any S3-compatible service.
+func openStorage(cfg config) (storage.Storage, error) {
+	switch cfg.storage.backend {
+	case storage.BackendLocal:
+		// download links must not be signed with the key that encrypts user data
+		if cfg.storage.signingkey != "" && cfg.storage.signingkey == cfg.encryption.key {
+			return nil, errors.New("the storage signing key must differ from the encryption key")
+		}
+		return storage.NewLocalStorage(cfg.storage.local.path, cfg.storage.local.baseurl, cfg.storage.signingkey)
+	case storage.BackendS3:
+		return storage.NewS3Storage(storage.S3Config{
+			Endpoint:     cfg.storage.s3.endpoint,
+			Region:       cfg.storage.s3.region,
+			Bucket:       cfg.storage.s3.bucket,
+			AccessKey:    cfg.storage.s3.accesskey,
+			SecretKey:    cfg.storage.s3.secretkey,
+			UsePathStyle: cfg.storage.s3.usepathstyle,
+		})
+	default:
+		return nil, storage.ErrUnsupportedBackend
+	}
+}
@@ -207,9 +207,23 @@ func (app *application) RemoveClient(userID int64) {
 
 // PublishNotification publishes a message to a specific user's SSE channel if they are online.
 // If the user is offline, it stores the notification in Redis for future delivery.
+// Live notifications, which were never saved and so have no ID, are dropped for offline users
 func (app *application) PublishNotification(userID int64, notification data.NotificationContent) {
 	app.Mutex.Lock()
 	defer app.Mutex.Unlock()
+	// live notifications are only worth sending while the user is connected
+	if notification.NotificationID == 0 {
+		if ch, exists := app.Clients[userID]; exists {
+			notification.SentAt = time.Now()
+			notificationJSON, err := json.Marshal(notification)
+			if err != nil {
+				app.logger.Error("Failed to marshal notification content", zap.Error(err))
+				return
+			}
+			ch <- string(notificationJSON)
+		}
+		return
+	}
 
 	// Check if the user has an active connection
 	if ch, exists := app.Clients[userID]; exists {
@@ -326,6 +340,19 @@ func (app *application) PublishNotificationToRedis(userID int64, notificationTyp
 	return nil
 }
 
+// PublishLiveNotificationToRedis publishes a message to a specific user's Redis pub/sub channel
+// without saving it. It reaches the user only if they are connected, which suits updates that are
+// stale by the time the user reconnects, such as a group's activity
+func (app *application) PublishLiveNotificationToRedis(userID int64, notificationType string, notification data.NotificationContent) error {
+	channel := fmt.Sprintf("%s:%d", data.RedisNotManNotificationKey, userID)
+	notification.NotificationType = notificationType
+	notificationJSON, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	return app.RedisDB.Publish(context.Background(), channel, string(notificationJSON)).Err()
+}
+
 // ListenForUserMessages listens to Redis pub/sub and sends messages to the specific user's SSE channel
 func (app *application) ListenForRedisPubSubUserMessages(ctx context.Context, userID int64) {
 	pubsub := app.RedisDB.Subscribe(ctx, fmt.Sprintf("%s:%d", data.RedisNotManNotificationKey, userID))
@@ -292,3 +292,69 @@ func (app *application) getExpenseIncomeSummaryReportHandler(w http.ResponseWrit
 		app.serverErrorResponse(w, r, err)
 	}
 }
+
+// getNetWorthHandler() returns the user's net worth over time with its asset and liability breakdown.
+// The history is made up of the daily snapshots between the start and end date (defaults to the last 90 days),
+// when it reaches today it ends with the net worth as it stands right now
+func (app *application) getNetWorthHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := app.readDate(qs, "end_date", today, v)
+	startDate := app.readDate(qs, "start_date", endDate.AddDate(0, 0, -(data.DefaultNetWorthHistoryDays-1)), v)
+	if data.ValidateNetWorthHistoryRange(v, startDate, endDate); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	user := app.contextGetUser(r)
+	current, err := app.currentNetWorthHelper(user.ID, now)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	history, err := app.models.NetWorthManager.GetNetWorthSnapshotsByUserID(user.ID, startDate, endDate)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !endDate.Before(today) {
+		history = data.AppendCurrentNetWorth(history, current)
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"net_worth": envelope{
+		"current": current,
+		"history": history,
+		"change":  data.CalculateNetWorthChange(history),
+	}}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// currentNetWorthHelper() calculates the user's net worth right now, in the default currency
+// when the user has not set one. Stocks and bonds are quoted in the default currency, they and the
+// account balances in other currencies are converted at today's rates
+func (app *application) currentNetWorthHelper(userID int64, now time.Time) (*data.NetWorthSnapshot, error) {
+	current, err := app.models.NetWorthManager.GetCurrentNetWorthByUserID(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if current.CurrencyCode == "" {
+		current.CurrencyCode = app.config.api.defaultcurrency
+	}
+	accounts, err := app.models.AccountManager.GetAccountsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	currencies := make([]string, 0, len(accounts)+1)
+	currencies = append(currencies, app.config.api.defaultcurrency)
+	for _, account := range accounts {
+		currencies = append(currencies, account.CurrencyCode)
+	}
+	rates, err := app.getConversionRatesHelper(currencies, current.CurrencyCode)
+	if err != nil {
+		return nil, err
+	}
+	current = data.ConvertNetWorthInvestments(current, app.config.api.defaultcurrency, rates)
+	return data.AddAccountsToNetWorth(current, accounts, rates), nil
+}
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/storage"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// createReceiptExpenseDraftHandler() turns an uploaded receipt into a draft expense in one step.
+// The receipt is sent as multipart/form-data under the "file" field. We run it through OCR and the
+// LLM, apply the user's category rules, suggest a budget and convert the total to that budget's
+// currency. The receipt is stored straight away and attached to the expense once the draft is confirmed
+func (app *application) createReceiptExpenseDraftHandler(w http.ResponseWriter, r *http.Request) {
+	user := app.contextGetUser(r)
+	// read the receipt
+	file, header, contentType, err := app.readMultipartFile(w, r, "file", app.config.storage.maxuploadsize)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	defer file.Close()
+	defer r.MultipartForm.RemoveAll()
+	receipt := &data.ExpenseAttachment{
+		FileName:    header.Filename,
+		ContentType: contentType,
+		SizeBytes:   header.Size,
+	}
+	v := validator.New()
+	if data.ValidateAttachmentFile(v, receipt, app.config.storage.maxuploadsize); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// OCR the receipt, the extension lets OCR.Space know what kind of file it is
+	extension := data.AllowedAttachmentContentTypes[contentType]
+	ocrResponse, err := app.processOCRFileRequestHelper("receipt"+extension, file)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if len(ocrResponse.ParsedResults) == 0 {
+		v.AddError("file", data.ErrReceiptNotReadable.Error())
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// let the LLM structure the receipt and parse the result
+	llmAnalysis, err := app.buildOCRRecieptExpenseDraftLLMRequest(ocrResponse)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	extraction, err := data.ParseReceiptAnalysis(llmAnalysis.Analysis)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrReceiptNotReadable),
+			errors.Is(err, data.ErrReceiptTotalNotFound):
+			v.AddError("file", err.Error())
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// build the draft from the user's rules and budgets
+	rules, err := app.models.ReceiptManager.GetExpenseCategoryRulesByUserID(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	budgets, err := app.getAllUserBudgetsHelper(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	draft := data.BuildReceiptExpenseDraft(extraction, rules, budgets, time.Now())
+	if draft.BudgetSuggestion != nil {
+		draft.Expense.Amount, err = app.convertReceiptAmountHelper(draft.OriginalAmount, draft.CurrencyCode, draft.BudgetSuggestion.CurrencyCode, draft.Expense.DateOccurred)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+	// store the receipt
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	receipt.StorageKey, err = storage.GenerateObjectKey(data.ExpenseAttachmentStoragePrefix, user.ID, extension)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.storage.Put(r.Context(), receipt.StorageKey, file, receipt.SizeBytes, receipt.ContentType)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	draft.Receipt = receipt
+	// save the draft
+	err = app.models.ReceiptManager.CreateNewReceiptDraft(user.ID, draft)
+	if err != nil {
+		app.deleteStoredAttachments([]*data.ExpenseAttachment{receipt})
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.signAttachmentURLs(draft.Receipt)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"receipt_draft": draft}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getReceiptExpenseDraftHandler() returns one of the user's pending receipt drafts
+func (app *application) getReceiptExpenseDraftHandler(w http.ResponseWriter, r *http.Request) {
+	draftID, err := app.readIDParam(r, "draftID")
+	if err != nil || draftID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	draft, err := app.models.ReceiptManager.GetReceiptDraftByID(app.contextGetUser(r).ID, draftID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// fill in the budget details of the suggestion
+	if draft.BudgetSuggestion != nil {
+		budget, err := app.models.FinancialManager.GetBudgetByID(draft.BudgetSuggestion.BudgetID)
+		if err != nil && !errors.Is(err, data.ErrGeneralRecordNotFound) {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		if budget != nil {
+			draft.BudgetSuggestion.BudgetName = budget.Name
+			draft.BudgetSuggestion.CurrencyCode = budget.CurrencyCode
+		}
+	}
+	err = app.signAttachmentURLs(draft.Receipt)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"receipt_draft": draft}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// confirmReceiptExpenseDraftHandler() creates the expense from a receipt draft and attaches the receipt.
+// Any of the drafted fields can be edited in the request body, send an empty body to confirm as is.
+// If the budget is changed and no amount is given, the receipt total is converted to the new budget's currency.
+// The same budget surplus rules as createNewExpenseHandler() apply
+func (app *application) confirmReceiptExpenseDraftHandler(w http.ResponseWriter, r *http.Request) {
+	message := data.Warning_Messages
+	draftID, err := app.readIDParam(r, "draftID")
+	if err != nil || draftID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	var input struct {
+		BudgetID     *int64           `json:"budget_id"`
+		Name         *string          `json:"name"`
+		Category     *string          `json:"category"`
+		Amount       *decimal.Decimal `json:"amount"`
+		Description  *string          `json:"description"`
+		DateOccurred *time.Time       `json:"date_occurred"`
+	}
+	// the body is optional
+	if r.ContentLength != 0 {
+		err = app.readJSON(w, r, &input)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+	}
+	user := app.contextGetUser(r)
+	// get the draft
+	draft, err := app.models.ReceiptManager.GetReceiptDraftByID(user.ID, draftID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// apply the user's edits
+	expense := draft.Expense
+	draftBudgetID := expense.BudgetID
+	if input.BudgetID != nil {
+		expense.BudgetID = *input.BudgetID
+	}
+	if input.Name != nil {
+		expense.Name = *input.Name
+	}
+	if input.Category != nil {
+		expense.Category = *input.Category
+	}
+	if input.Amount != nil {
+		expense.Amount = *input.Amount
+	}
+	if input.Description != nil {
+		expense.Description = *input.Description
+	}
+	if input.DateOccurred != nil {
+		expense.DateOccurred = *input.DateOccurred
+	}
+	v := validator.New()
+	if expense.BudgetID < 1 {
+		v.AddError("budget_id", "must be provided as no budget could be suggested for this receipt")
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// get the budget and make sure it belongs to the user
+	budget, err := app.models.FinancialManager.GetBudgetByID(expense.BudgetID)
+	if err != nil || budget.UserID != user.ID {
+		switch {
+		case err == nil, errors.Is(err, data.ErrGeneralRecordNotFound):
+			v.AddError("budget_id", "budget not found")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	// a new budget may use another currency
+	if input.Amount == nil && expense.BudgetID != draftBudgetID {
+		expense.Amount, err = app.convertReceiptAmountHelper(draft.OriginalAmount, draft.CurrencyCode, budget.CurrencyCode, expense.DateOccurred)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+	// validate the expense
+	if data.ValidateExpense(v, expense); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// get the available surplus
+	goalTotals, err := app.models.FinancialManager.GetAllGoalSummaryBudgetID(expense.BudgetID, user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// check if the expense is more than the surplus
+	if expense.Amount.Cmp(goalTotals.TotalSurplus) > 0 {
+		if budget.IsStrict {
+			v.AddError("amount", "expense amount is more than the available surplus")
+			app.failedValidationResponse(w, r, v.Errors)
+			return
+		}
+		message.Message = append(message.Message, "expense amount is more than the available surplus")
+	}
+	// save the expense with the receipt attached, this also removes the draft so it is only confirmed once
+	attachment := draft.Receipt
+	err = app.models.ReceiptManager.ConfirmReceiptDraft(user.ID, draftID, expense, attachment)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.signAttachmentURLs(attachment)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"expense": expense, "attachment": attachment, "message": message, "totals": goalTotals}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// discardReceiptExpenseDraftHandler() deletes a receipt draft together with its stored receipt
+func (app *application) discardReceiptExpenseDraftHandler(w http.ResponseWriter, r *http.Request) {
+	draftID, err := app.readIDParam(r, "draftID")
+	if err != nil || draftID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	storageKey, err := app.models.ReceiptManager.DeleteReceiptDraftByID(app.contextGetUser(r).ID, draftID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.storage.Delete(r.Context(), storageKey)
+	if err != nil {
+		app.logger.Error("unable to delete receipt from storage", zap.String("key", storageKey), zap.Error(err))
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "receipt draft discarded"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getExpenseCategoryRulesHandler() returns the user's category rules, highest priority first
+func (app *application) getExpenseCategoryRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := app.models.ReceiptManager.GetExpenseCategoryRulesByUserID(app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"category_rules": rules}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// createExpenseCategoryRuleHandler() creates a rule that categorizes receipts whose merchant or
+// line items contain the pattern. A budget can be linked to the rule to be suggested as well
+func (app *application) createExpenseCategoryRuleHandler(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Pattern  string `json:"pattern"`
+		Category string `json:"category"`
+		BudgetID int64  `json:"budget_id"`
+		Priority int32  `json:"priority"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	user := app.contextGetUser(r)
+	rule := &data.ExpenseCategoryRule{
+		Pattern:  input.Pattern,
+		Category: input.Category,
+		BudgetID: input.BudgetID,
+		Priority: input.Priority,
+	}
+	v := validator.New()
+	if data.ValidateExpenseCategoryRule(v, rule); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	// make sure the linked budget belongs to the user
+	if rule.BudgetID != 0 {
+		budget, err := app.models.FinancialManager.GetBudgetByID(rule.BudgetID)
+		if err != nil || budget.UserID != user.ID {
+			switch {
+			case err == nil, errors.Is(err, data.ErrGeneralRecordNotFound):
+				v.AddError("budget_id", "budget not found")
+				app.failedValidationResponse(w, r, v.Errors)
+			default:
+				app.serverErrorResponse(w, r, err)
+			}
+			return
+		}
+	}
+	// save the rule
+	err = app.models.ReceiptManager.CreateNewExpenseCategoryRule(user.ID, rule)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateExpenseCategoryRule):
+			v.AddError("pattern", err.Error())
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"category_rule": rule}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteExpenseCategoryRuleHandler() deletes one of the user's category rules
+func (app *application) deleteExpenseCategoryRuleHandler(w http.ResponseWriter, r *http.Request) {
+	ruleID, err := app.readIDParam(r, "ruleID")
+	if err != nil || ruleID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	_, err = app.models.ReceiptManager.DeleteExpenseCategoryRuleByID(app.contextGetUser(r).ID, ruleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "category rule deleted successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// convertReceiptAmountHelper() converts a receipt total into the target currency at the rate of the
+// receipt's date. When either currency is unknown or both are the same the amount is returned unchanged
+func (app *application) convertReceiptAmountHelper(amount decimal.Decimal, fromCurrency, toCurrency string, date time.Time) (decimal.Decimal, error) {
+	if fromCurrency == "" || toCurrency == "" || fromCurrency == toCurrency {
+		return amount, nil
+	}
+	exchangeRate, err := app.convertAndGetExchangeRateOnDate(fromCurrency, toCurrency, date)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return exchangeRate.ConvertAmount(amount).ConvertedAmount.Round(2), nil
+}
+
+// getAllUserBudgetsHelper() pages through all of the user's budgets
+func (app *application) getAllUserBudgetsHelper(userID int64) ([]*data.Budget, error) {
+	var budgets []*data.Budget
+	currentPage := 1
+	for {
+		filters := data.Filters{
+			Page:     currentPage,
+			PageSize: 100,
+		}
+		enrichedBudgets, metadata, err := app.models.FinancialManager.GetBudgetsForUser(userID, "", filters)
+		if err != nil {
+			// a user without budgets simply gets no suggestion
+			if errors.Is(err, data.ErrGeneralRecordNotFound) {
+				return budgets, nil
+			}
+			return nil, err
+		}
+		for _, enrichedBudget := range enrichedBudgets {
+			budget := enrichedBudget.Budget
+			budgets = append(budgets, &budget)
+		}
+		// check if this is the last page of records
+		if metadata.LastPage == metadata.CurrentPage {
+			return budgets, nil
+		}
+		currentPage = metadata.CurrentPage + 1
+	}
+}
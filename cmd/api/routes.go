@@ -66,8 +66,19 @@ func (app *application) routes() http.Handler {
 	v1Router.With(dynamicMiddleware.Then).Mount("/search-options", app.searchOptionRoutes())
 	v1Router.With(dynamicMiddleware.Then).Mount("/notifications", app.notifications())
 	v1Router.With(dynamicMiddleware.Then).Mount("/comments", app.comments())
+	v1Router.With(dynamicMiddleware.Then).Mount("/tags", app.tagRoutes())
+	v1Router.With(dynamicMiddleware.Then).Mount("/calendar", app.calendarRoutes())
+	v1Router.With(dynamicMiddleware.Then).Mount("/taxes", app.taxRoutes())
+	v1Router.With(dynamicMiddleware.Then).Mount("/exchange-rates", app.exchangeRateRoutes())
+	v1Router.With(dynamicMiddleware.Then).Mount("/subscriptions", app.subscriptionRoutes())
+	v1Router.With(dynamicMiddleware.Then).Mount("/anomalies", app.anomalyRoutes())
+	v1Router.With(dynamicMiddleware.Then).Mount("/accounts", app.accountRoutes())
 	// mount general routes directly
 	v1Router.Post("/contact-us", app.createContactUsHandler)
+	// signed attachment downloads, authorised by the signature in the URL
+	v1Router.Get("/attachments/download", app.downloadAttachmentHandler)
+	// iCalendar feed, authorised by the secret token in the URL
+	v1Router.Get("/calendar.ics", app.getCashFlowICalendarFeedHandler)
 
 	// Moount the v1Router to the main base router
 	router.Mount("/v1", v1Router)
@@ -139,6 +150,14 @@ func (app *application) groupRoutes() chi.Router {
 	groupRoutes.Post("/", app.createNewUserGroupHandler)
 	groupRoutes.Patch("/{groupID}", app.updateUserGroupHandler)
 
+	groupRoutes.Delete("/{groupID}", app.deleteGroupHandler)
+
+	// ownership, archival and data export
+	groupRoutes.Patch("/owner/{groupID}", app.transferGroupOwnershipHandler)
+	groupRoutes.Post("/archive/{groupID}", app.archiveGroupHandler)
+	groupRoutes.Delete("/archive/{groupID}", app.unarchiveGroupHandler)
+	groupRoutes.Get("/export/{groupID}", app.exportGroupDataHandler)
+
 	// members
 	groupRoutes.Patch("/member/{groupID}", app.updateGroupUserRoleHandler)
 	groupRoutes.Delete("/member/{groupID}/{memberID}", app.adminDeleteGroupMemberHandler) // admin deletion {},{}
@@ -147,10 +166,27 @@ func (app *application) groupRoutes() chi.Router {
 	// get for creators
 	groupRoutes.Get("/created", app.getAllGroupsCreatedByUserHandler)
 
+	// group role permissions
+	groupRoutes.Get("/permissions/{groupID}", app.getGroupPermissionsHandler)
+
+	// group activity timeline
+	groupRoutes.Get("/activity/{groupID}", app.getGroupActivitiesHandler)
+
 	// group invitations
 	groupRoutes.Post("/invite", app.createNewGroupInvitation)
 	groupRoutes.Patch("/invite/{groupID}", app.updateGroupInvitationStatusHandler)
 
+	// group invite links
+	groupRoutes.Get("/invite-links/{groupID}", app.getGroupInviteLinksHandler)
+	groupRoutes.Post("/invite-links", app.createNewGroupInviteLinkHandler)
+	groupRoutes.Delete("/invite-links/{linkID}", app.revokeGroupInviteLinkHandler)
+	groupRoutes.Post("/invite-links/accept", app.acceptGroupInviteLinkHandler)
+
+	// group join requests (discoverable private groups)
+	groupRoutes.Get("/join-requests/{groupID}", app.getGroupJoinRequestsHandler)
+	groupRoutes.Post("/join-requests", app.createNewGroupJoinRequestHandler)
+	groupRoutes.Patch("/join-requests/{requestID}", app.reviewGroupJoinRequestHandler)
+
 	// group goals
 	groupRoutes.Post("/goal", app.createNewGroupGoalHandler)
 	groupRoutes.Patch("/goal/{groupGoalID}", app.updateGroupGoalHandler)
@@ -164,6 +200,34 @@ func (app *application) groupRoutes() chi.Router {
 	groupRoutes.Get("/expenses/{groupID}", app.getGroupExpensesByGroupIdHandler)
 	groupRoutes.Post("/expenses", app.createNewGroupExpenseHandler)
 	groupRoutes.Delete("/expenses/{groupExpenseID}", app.deleteGroupExpenseHandler)
+	groupRoutes.Get("/expenses/{groupExpenseID}/attachments", app.getGroupExpenseAttachmentsHandler)
+	groupRoutes.Post("/expenses/{groupExpenseID}/attachments", app.uploadGroupExpenseAttachmentHandler)
+	groupRoutes.Put("/expenses/{groupExpenseID}/split", app.updateGroupExpenseSplitHandler)
+
+	// group budgets
+	groupRoutes.Get("/budgets/{groupID}", app.getGroupBudgetsHandler)
+	groupRoutes.Post("/budgets", app.createNewGroupBudgetHandler)
+	groupRoutes.Patch("/budgets/{groupBudgetID}", app.updateGroupBudgetHandler)
+	groupRoutes.Delete("/budgets/{groupBudgetID}", app.deleteGroupBudgetHandler)
+
+	// group contribution schedules (member dues)
+	groupRoutes.Get("/contributions/{groupID}", app.getGroupContributionSchedulesHandler)
+	groupRoutes.Post("/contributions", app.createNewGroupContributionScheduleHandler)
+	groupRoutes.Patch("/contributions/{scheduleID}", app.updateGroupContributionScheduleHandler)
+	groupRoutes.Delete("/contributions/{scheduleID}", app.deleteGroupContributionScheduleHandler)
+	groupRoutes.Get("/contributions/arrears/{groupID}", app.getGroupContributionArrearsHandler)
+
+	// group balances and settlements
+	groupRoutes.Get("/balances/{groupID}", app.getGroupBalancesHandler)
+	groupRoutes.Post("/settlements", app.createNewGroupSettlementHandler)
+
+	// group challenges
+	groupRoutes.Get("/challenges/{groupID}", app.getGroupChallengesHandler)
+	groupRoutes.Post("/challenges", app.createNewGroupChallengeHandler)
+	groupRoutes.Delete("/challenges/{challengeID}", app.deleteGroupChallengeHandler)
+	groupRoutes.Get("/challenges/{challengeID}/leaderboard", app.getGroupChallengeLeaderboardHandler)
+	groupRoutes.Post("/challenges/{challengeID}/participants", app.joinGroupChallengeHandler)
+	groupRoutes.Delete("/challenges/{challengeID}/participants", app.leaveGroupChallengeHandler)
 
 	// Public groups
 	groupRoutes.Get("/public", app.getAllPublicGroupsHandler)
@@ -176,10 +240,28 @@ func (app *application) expenseRoutes() chi.Router {
 	expenseRoutes := chi.NewRouter()
 	expenseRoutes.Get("/", app.getAllExpensesByUserIDHandler)
 	expenseRoutes.Post("/", app.createNewExpenseHandler)
+	expenseRoutes.Post("/batch", app.batchCreateExpensesHandler)
+	expenseRoutes.Patch("/batch", app.batchUpdateExpensesHandler)
+	expenseRoutes.Delete("/batch", app.batchDeleteExpensesHandler)
 	expenseRoutes.Patch("/{expenseID}", app.updateExpenseByIDHandler)
+	expenseRoutes.Delete("/{expenseID}", app.deleteExpenseByIDHandler)
+	expenseRoutes.Put("/{expenseID}/tax-categories", app.setExpenseTaxCategoriesHandler)
+	expenseRoutes.Get("/{expenseID}/attachments", app.getExpenseAttachmentsHandler)
+	expenseRoutes.Post("/{expenseID}/attachments", app.uploadExpenseAttachmentHandler)
+	expenseRoutes.Delete("/attachments/{attachmentID}", app.deleteExpenseAttachmentHandler)
+	expenseRoutes.Get("/category-rules", app.getExpenseCategoryRulesHandler)
+	expenseRoutes.Post("/category-rules", app.createExpenseCategoryRuleHandler)
+	expenseRoutes.Delete("/category-rules/{ruleID}", app.deleteExpenseCategoryRuleHandler)
+	expenseRoutes.Post("/receipts/drafts", app.createReceiptExpenseDraftHandler)
+	expenseRoutes.Get("/receipts/drafts/{draftID}", app.getReceiptExpenseDraftHandler)
+	expenseRoutes.Delete("/receipts/drafts/{draftID}", app.discardReceiptExpenseDraftHandler)
+	expenseRoutes.Post("/receipts/drafts/{draftID}/confirm", app.confirmReceiptExpenseDraftHandler)
 	expenseRoutes.Post("/recurring", app.createNewRecurringExpenseHandler)
 	expenseRoutes.Get("/recurring", app.getAllRecurringExpensesByUserIDHandler)
 	expenseRoutes.Patch("/recurring/{expenseID}", app.updateRecurringExpenseByIDHandler)
+	expenseRoutes.Patch("/recurring/{expenseID}/pause", app.pauseRecurringExpenseHandler)
+	expenseRoutes.Patch("/recurring/{expenseID}/resume", app.resumeRecurringExpenseHandler)
+	expenseRoutes.Patch("/recurring/{expenseID}/skip", app.skipRecurringExpenseOccurrenceHandler)
 
 	expenseRoutes.Post("/receipts", app.getOCRDRecieptDataAnalysisHandler)
 
@@ -190,7 +272,15 @@ func (app *application) incomeRouter() chi.Router {
 	incomeRoutes := chi.NewRouter()
 	incomeRoutes.Get("/", app.getAllIncomesByUserIDHandler)
 	incomeRoutes.Post("/", app.createNewIncomeHandler)
+	incomeRoutes.Post("/batch", app.batchCreateIncomesHandler)
+	incomeRoutes.Patch("/batch", app.batchUpdateIncomesHandler)
+	incomeRoutes.Delete("/batch", app.batchDeleteIncomesHandler)
 	incomeRoutes.Patch("/{incomeID}", app.updateIncomeHandler)
+	incomeRoutes.Delete("/{incomeID}", app.deleteIncomeByIDHandler)
+	incomeRoutes.Put("/{incomeID}/tax-categories", app.setIncomeTaxCategoriesHandler)
+	incomeRoutes.Post("/recurring", app.createNewRecurringIncomeHandler)
+	incomeRoutes.Get("/recurring", app.getAllRecurringIncomesByUserIDHandler)
+	incomeRoutes.Patch("/recurring/{incomeID}", app.updateRecurringIncomeByIDHandler)
 	return incomeRoutes
 }
 
@@ -204,6 +294,14 @@ func (app *application) debtRoutes() chi.Router {
 	debtRoutes.Get("/installment/{debtID}", app.getDebtPaymentsByDebtUserIDHandler)
 	debtRoutes.Patch("/installment/{debtID}", app.makeDebtPaymentHandler)
 
+	// amortization
+	debtRoutes.Get("/amortization/{debtID}", app.getDebtAmortizationScheduleHandler)
+
+	// payoff plans
+	debtRoutes.Get("/payoff-plan", app.getDebtPayoffPlanHandler)
+	debtRoutes.Post("/payoff-plan", app.saveDebtPayoffPlanHandler)
+	debtRoutes.Post("/payoff-plan/simulate", app.simulateDebtPayoffHandler)
+
 	return debtRoutes
 }
 
@@ -226,6 +324,8 @@ func (app *application) investmentPortfolioRoutes() chi.Router {
 	investmentPortfolioRoutes.Delete("/alternative/{alternativeID}", app.deleteAlternativeInvestmentByIDHandler)
 	// investment transactiona
 	investmentPortfolioRoutes.Post("/transactions", app.createNewInvestmentTransactionHandler)
+	investmentPortfolioRoutes.Post("/transactions/batch", app.batchCreateInvestmentTransactionsHandler)
+	investmentPortfolioRoutes.Delete("/transactions/batch", app.batchDeleteInvestmentTransactionsHandler)
 	investmentPortfolioRoutes.Delete("/transactions/{transactionID}", app.deleteInvestmentTransactionByIDHandler)
 
 	// Analysis
@@ -258,6 +358,7 @@ func (app *application) personalFinanceRoutes() chi.Router {
 	personalFinanceRoutes.Get("/summary", app.getAllInvestmentInfoByUserIDHandler)
 	personalFinanceRoutes.Get("/prediction", app.getPersonalFinancePrediction)
 	personalFinanceRoutes.Get("/expense-income/summary", app.getExpenseIncomeSummaryReportHandler)
+	personalFinanceRoutes.Get("/networth", app.getNetWorthHandler)
 	return personalFinanceRoutes
 }
 
@@ -301,3 +402,71 @@ func (app *application) comments() chi.Router {
 	commentRoutes.Delete("/reaction/{commentID}", app.deleteReactionHandler)
 	return commentRoutes
 }
+
+// tagRoutes() is a method that returns a chi.Router that contains all the routes for the tags
+func (app *application) tagRoutes() chi.Router {
+	tagRoutes := chi.NewRouter()
+	tagRoutes.Get("/", app.getAllTagsHandler)
+	tagRoutes.Delete("/{tagID}", app.deleteTagHandler)
+	tagRoutes.Post("/bulk", app.bulkTagHandler)
+	tagRoutes.Get("/report", app.getTagSpendingReportHandler)
+	return tagRoutes
+}
+
+// calendarRoutes() is a method that returns a chi.Router that contains all the routes for the cash-flow calendar
+func (app *application) calendarRoutes() chi.Router {
+	calendarRoutes := chi.NewRouter()
+	calendarRoutes.Get("/", app.getCashFlowCalendarHandler)
+	calendarRoutes.Post("/feed", app.createCalendarFeedHandler)
+	calendarRoutes.Delete("/feed", app.deleteCalendarFeedHandler)
+	return calendarRoutes
+}
+
+// taxRoutes() is a method that returns a chi.Router that contains all the routes for the tax reports
+func (app *application) taxRoutes() chi.Router {
+	taxRoutes := chi.NewRouter()
+	taxRoutes.Get("/summary", app.getAnnualTaxSummaryHandler)
+	return taxRoutes
+}
+
+// exchangeRateRoutes() is a method that returns a chi.Router that contains all the routes for exchange rates
+func (app *application) exchangeRateRoutes() chi.Router {
+	exchangeRateRoutes := chi.NewRouter()
+	exchangeRateRoutes.Get("/history", app.getExchangeRateHistoryHandler)
+	exchangeRateRoutes.Get("/revaluation", app.getFXRevaluationHandler)
+	return exchangeRateRoutes
+}
+
+// subscriptionRoutes() is a method that returns a chi.Router that contains all the routes for detected subscriptions
+func (app *application) subscriptionRoutes() chi.Router {
+	subscriptionRoutes := chi.NewRouter()
+	subscriptionRoutes.Get("/", app.getDetectedSubscriptionsHandler)
+	subscriptionRoutes.Post("/scan", app.scanSubscriptionsHandler)
+	subscriptionRoutes.Post("/{subscriptionID}/convert", app.convertSubscriptionHandler)
+	subscriptionRoutes.Patch("/{subscriptionID}/dismiss", app.dismissSubscriptionHandler)
+	return subscriptionRoutes
+}
+
+// anomalyRoutes() is a method that returns a chi.Router that contains all the routes for spending anomalies
+func (app *application) anomalyRoutes() chi.Router {
+	anomalyRoutes := chi.NewRouter()
+	anomalyRoutes.Get("/", app.getSpendingAnomaliesHandler)
+	anomalyRoutes.Post("/scan", app.scanSpendingAnomaliesHandler)
+	return anomalyRoutes
+}
+
+// accountRoutes() is a method that returns a chi.Router that contains all the routes for accounts,
+// their transfers and reconciliation
+func (app *application) accountRoutes() chi.Router {
+	accountRoutes := chi.NewRouter()
+	accountRoutes.Get("/", app.getAccountsHandler)
+	accountRoutes.Post("/", app.createNewAccountHandler)
+	accountRoutes.Patch("/{accountID}", app.updateAccountHandler)
+	accountRoutes.Delete("/{accountID}", app.deleteAccountHandler)
+	accountRoutes.Get("/{accountID}/transactions", app.getAccountTransactionsHandler)
+	accountRoutes.Post("/{accountID}/reconcile", app.reconcileAccountHandler)
+	// transfers
+	accountRoutes.Post("/transfers", app.createAccountTransferHandler)
+	accountRoutes.Delete("/transfers/{transferID}", app.deleteAccountTransferHandler)
+	return accountRoutes
+}
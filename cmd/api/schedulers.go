@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
@@ -74,19 +78,36 @@ func (app *application) trackRecurringExpensesHandler() {
 	app.config.scheduler.trackRecurringExpenses.Start()
 }
 
-// trackOverdueDebtsHandler() is the cronjob method that will track all overdue debts
-func (app *application) trackOverdueDebtsHandler() {
-	app.logger.Info("Starting the overdue debt tracking cron job..", zap.String("time", time.Now().String()))
+// trackRecurringIncomesHandler() is a cronjob method that sets a cronJob to run at the end of every day
+// to post all the recurring incomes that are due for users
+func (app *application) trackRecurringIncomesHandler() {
+	app.logger.Info("Starting the recurring incomes tracking cron job..", zap.String("time", time.Now().String()))
 	updateInterval := "0 0 * * *"
 
-	_, err := app.config.scheduler.trackOverdueDebts.AddFunc(updateInterval, app.trackOverdueDebts)
+	_, err := app.config.scheduler.trackRecurringIncomes.AddFunc(updateInterval, app.trackRecurringIncomes)
 	if err != nil {
-		app.logger.Error("Error adding [trackOverdueDebts] to scheduler", zap.Error(err))
+		app.logger.Error("Error adding [trackRecurringIncomes] to scheduler", zap.Error(err))
 	}
 	// Run the tracking first before starting the cron
-	app.trackOverdueDebts()
+	app.trackRecurringIncomes()
 	// start the cron scheduler
-	app.config.scheduler.trackOverdueDebts.Start()
+	app.config.scheduler.trackRecurringIncomes.Start()
+}
+
+// trackDebtInterestAccrualHandler() is the cronjob method that accrues the interest of all debts daily
+// and handles missed debt payments
+func (app *application) trackDebtInterestAccrualHandler() {
+	app.logger.Info("Starting the debt interest accrual cron job..", zap.String("time", time.Now().String()))
+	updateInterval := "0 0 * * *"
+
+	_, err := app.config.scheduler.trackDebtInterestAccrual.AddFunc(updateInterval, app.trackDebtInterestAccrual)
+	if err != nil {
+		app.logger.Error("Error adding [trackDebtInterestAccrual] to scheduler", zap.Error(err))
+	}
+	// Run the tracking first before starting the cron
+	app.trackDebtInterestAccrual()
+	// start the cron scheduler
+	app.config.scheduler.trackDebtInterestAccrual.Start()
 }
 
 // trackExpiredNotificationsHandler() is the method called by the cronjob to track all expired notifications
@@ -105,6 +126,134 @@ func (app *application) trackExpiredNotificationsHandler() {
 	app.config.scheduler.trackRecurringExpenses.Start()
 }
 
+// trackExpiredReceiptDraftsHandler() is the cronjob method that removes receipt drafts that were
+// never confirmed together with their stored receipts. Will run every hour
+func (app *application) trackExpiredReceiptDraftsHandler() {
+	app.logger.Info("Starting the expired receipt drafts tracking cron job..", zap.String("time", time.Now().String()))
+	updateInterval := "0 * * * *"
+
+	_, err := app.config.scheduler.trackExpiredReceiptDrafts.AddFunc(updateInterval, app.trackExpiredReceiptDrafts)
+	if err != nil {
+		app.logger.Error("Error adding [trackExpiredReceiptDrafts] to scheduler", zap.Error(err))
+	}
+	// Run the tracking first before starting the cron
+	app.trackExpiredReceiptDrafts()
+	// start the cron scheduler
+	app.config.scheduler.trackExpiredReceiptDrafts.Start()
+}
+
+// trackDailyExchangeRatesHandler() is the cronjob method that stores the day's exchange rates
+// so conversions can use the rate of a transaction's date. Will run every day
+func (app *application) trackDailyExchangeRatesHandler() {
+	app.logger.Info("Starting the daily exchange rates tracking cron job..", zap.String("time", time.Now().String()))
+	updateInterval := "15 0 * * *"
+
+	_, err := app.config.scheduler.trackDailyExchangeRates.AddFunc(updateInterval, app.trackDailyExchangeRates)
+	if err != nil {
+		app.logger.Error("Error adding [trackDailyExchangeRates] to scheduler", zap.Error(err))
+	}
+	// Run the tracking first before starting the cron
+	app.trackDailyExchangeRates()
+	// start the cron scheduler
+	app.config.scheduler.trackDailyExchangeRates.Start()
+}
+
+// trackSubscriptionsHandler() is the cronjob method that looks for subscriptions in the expenses
+// users recorded during the day and alerts them about price increases. Will run every day
+func (app *application) trackSubscriptionsHandler() {
+	app.logger.Info("Starting the subscription detection cron job..", zap.String("time", time.Now().String()))
+	updateInterval := "30 0 * * *"
+
+	_, err := app.config.scheduler.trackSubscriptions.AddFunc(updateInterval, app.trackSubscriptions)
+	if err != nil {
+		app.logger.Error("Error adding [trackSubscriptions] to scheduler", zap.Error(err))
+	}
+	// Run the tracking first before starting the cron
+	app.trackSubscriptions()
+	// start the cron scheduler
+	app.config.scheduler.trackSubscriptions.Start()
+}
+
+// trackSpendingAnomaliesHandler() is the cronjob method that checks the expenses users recorded
+// during the day for unusual spending and alerts them. Will run every day
+func (app *application) trackSpendingAnomaliesHandler() {
+	app.logger.Info("Starting the spending anomaly detection cron job..", zap.String("time", time.Now().String()))
+	updateInterval := "45 0 * * *"
+
+	_, err := app.config.scheduler.trackSpendingAnomalies.AddFunc(updateInterval, app.trackSpendingAnomalies)
+	if err != nil {
+		app.logger.Error("Error adding [trackSpendingAnomalies] to scheduler", zap.Error(err))
+	}
+	// Run the tracking first before starting the cron
+	app.trackSpendingAnomalies()
+	// start the cron scheduler
+	app.config.scheduler.trackSpendingAnomalies.Start()
+}
+
+// trackNetWorthSnapshotsHandler() is the cronjob method that stores the net worth of every user
+// holding investments or debts at the end of the UTC day. Will run every day
+func (app *application) trackNetWorthSnapshotsHandler() {
+	app.logger.Info("Starting the net worth snapshot cron job..", zap.String("time", time.Now().String()))
+	updateInterval := "50 23 * * *"
+
+	_, err := app.config.scheduler.trackNetWorthSnapshots.AddFunc(updateInterval, app.trackNetWorthSnapshots)
+	if err != nil {
+		app.logger.Error("Error adding [trackNetWorthSnapshots] to scheduler", zap.Error(err))
+	}
+	// Run the tracking first before starting the cron
+	app.trackNetWorthSnapshots()
+	// start the cron scheduler
+	app.config.scheduler.trackNetWorthSnapshots.Start()
+}
+
+// trackGroupContributionDuesHandler() is the cronjob method that creates the member dues of group
+// contribution schedules that fell due and reminds members about overdue dues. Will run every day
+func (app *application) trackGroupContributionDuesHandler() {
+	app.logger.Info("Starting the group contribution dues cron job..", zap.String("time", time.Now().String()))
+	updateInterval := "15 1 * * *"
+
+	_, err := app.config.scheduler.trackGroupContributionDues.AddFunc(updateInterval, app.trackGroupContributionDues)
+	if err != nil {
+		app.logger.Error("Error adding [trackGroupContributionDues] to scheduler", zap.Error(err))
+	}
+	// Run the tracking first before starting the cron
+	app.trackGroupContributionDues()
+	// start the cron scheduler
+	app.config.scheduler.trackGroupContributionDues.Start()
+}
+
+// trackGroupDeletionsHandler() is the cronjob method that purges the groups whose deletion grace
+// period has passed. Will run every hour
+func (app *application) trackGroupDeletionsHandler() {
+	app.logger.Info("Starting the group deletion cron job..", zap.String("time", time.Now().String()))
+	updateInterval := "30 * * * *"
+
+	_, err := app.config.scheduler.trackGroupDeletions.AddFunc(updateInterval, app.trackGroupDeletions)
+	if err != nil {
+		app.logger.Error("Error adding [trackGroupDeletions] to scheduler", zap.Error(err))
+	}
+	// Run the tracking first before starting the cron
+	app.trackGroupDeletions()
+	// start the cron scheduler
+	app.config.scheduler.trackGroupDeletions.Start()
+}
+
+// trackGroupChallengesHandler() is the cronjob method that updates the standings of running group
+// challenges and settles the ones that ended. Will run every hour
+func (app *application) trackGroupChallengesHandler() {
+	app.logger.Info("Starting the group challenges cron job..", zap.String("time", time.Now().String()))
+	updateInterval := "45 * * * *"
+
+	_, err := app.config.scheduler.trackGroupChallenges.AddFunc(updateInterval, app.trackGroupChallenges)
+	if err != nil {
+		app.logger.Error("Error adding [trackGroupChallenges] to scheduler", zap.Error(err))
+	}
+	// Run the tracking first before starting the cron
+	app.trackGroupChallenges()
+	// start the cron scheduler
+	app.config.scheduler.trackGroupChallenges.Start()
+}
+
 func (app *application) startRssFeedScraperHandler() {
 	app.logger.Info("Starting the RSS feed scraper..", zap.String("time", time.Now().String()))
 	// set interval to every 5 minutes
@@ -190,27 +339,21 @@ func (app *application) trackExpiredGroupInvitations() {
 }
 
 // trackRecurringExpenses() is the method called by the cronjob to track all the recurring expenses for users
-// We will need to pass a burst and offset. After each burst, wwe recieve the  expenses than need to be tracked
-// For each of those expenses, we add them to the expenses table after which we update the next tracking date
-// of the current recurring expense.
-// After processing we increment the offset by the burst and repeat the process until we get
-// an ErrGenerealRecordNotFound error which just means we have no more expenses to track and we can stop
+// We process the due expenses in bursts. For each of those expenses, we add them to the expenses table after
+// which we update the next tracking date of the current recurring expense. If runs were missed, every
+// occurrence that is due is posted on its own date.
+// Posted expenses leave the due set, so the bursts are paged by the last ID seen rather than by offset,
+// until we get an ErrGenerealRecordNotFound error which just means we have no more expenses to track
 func (app *application) trackRecurringExpenses() {
 	app.logger.Info("Tracking recurring expenses", zap.String("time", time.Now().String()))
 
-	// Define burst size and start from the first page
+	// Define burst size and start from the first expense
 	burst := app.config.limit.recurringExpenseTrackerBurstLimit
-	currentPage := 1 // Start with page 1
+	var lastID int64
 
 	for {
-		// Create filter with current page and burst size
-		filter := data.Filters{
-			Page:     currentPage,
-			PageSize: burst,
-		}
-
-		// Retrieve expenses that need to be tracked for the current page
-		recurringExpensesToTrack, metadata, err := app.models.FinancialTrackingManager.GetAllRecurringExpensesDueForProcessing(filter)
+		// Retrieve the next burst of recurring expenses that are due
+		recurringExpensesToTrack, err := app.models.FinancialTrackingManager.GetAllRecurringExpensesDueForProcessing(lastID, burst)
 		if err != nil {
 			// Handle case where no more records are found, break out of the loop
 			if errors.Is(err, data.ErrGeneralRecordNotFound) {
@@ -224,100 +367,241 @@ func (app *application) trackRecurringExpenses() {
 
 		// Process each recurring expense in the batch
 		for _, recurringExpenseToTrack := range recurringExpensesToTrack {
-			// Create a new expense record
-			expense := &data.Expense{
-				BudgetID:     recurringExpenseToTrack.BudgetID,
-				Name:         recurringExpenseToTrack.Name,
-				Category:     "recurring",
-				Amount:       recurringExpenseToTrack.Amount,
-				IsRecurring:  true,
-				Description:  recurringExpenseToTrack.Description,
-				DateOccurred: time.Now(),
-			}
+			app.postRecurringExpense(recurringExpenseToTrack)
+			lastID = recurringExpenseToTrack.ID
+		}
 
-			// Add the expense to the expenses table
-			err := app.models.FinancialTrackingManager.CreateNewExpense(recurringExpenseToTrack.UserID, expense)
-			if err != nil {
-				app.logger.Error("Error adding recurring expense to expenses table", zap.Error(err))
-				continue
+		// Check if this is the last burst of records
+		if len(recurringExpensesToTrack) < burst {
+			app.logger.Info("All recurring expenses processed. Ending tracking.")
+			break
+		}
+	}
+}
+
+// postRecurringExpense() posts all the due occurrences of a single recurring expense.
+// Each occurrence is saved together with the move to the next occurrence in a single transaction.
+func (app *application) postRecurringExpense(recurringExpense *data.RecurringExpense) {
+	now := time.Now()
+	// Post every occurrence that is due, catching up on any runs that were missed
+	for !recurringExpense.NextOccurrence.After(now) && !recurringExpense.HasEnded() {
+		// Create a new expense record dated on the occurrence it represents
+		expense := &data.Expense{
+			BudgetID:     recurringExpense.BudgetID,
+			Name:         recurringExpense.Name,
+			Category:     "recurring",
+			Amount:       recurringExpense.Amount,
+			IsRecurring:  true,
+			Description:  recurringExpense.Description,
+			DateOccurred: recurringExpense.NextOccurrence,
+		}
+		// Move the schedule forward, keeping a copy to restore if the posting fails
+		previous := *recurringExpense
+		recurringExpense.CalculateNextOccurrence()
+		err := app.models.FinancialTrackingManager.PostRecurringExpense(recurringExpense, expense)
+		if err != nil {
+			*recurringExpense = previous
+			app.logger.Error("Error posting recurring expense", zap.Int64("recurring_expense_id", recurringExpense.ID), zap.Error(err))
+			break
+		}
+	}
+}
+
+// trackRecurringIncomes() is the method called by the cronjob to post all the recurring incomes that are due
+// Just like trackRecurringExpenses, we process the due incomes in bursts. For each recurring income we:
+// 1. Convert the original amount to the user's default currency using the exchange rate at posting time
+// 2. Post an income for every occurrence that is due, catching up on any runs that were missed
+// 3. Allocate the configured percentage of each posting to the linked goal, if any
+// 4. Move the next occurrence forward and notify the user
+// An income whose next occurrence passes its end date will no longer be picked up.
+// Posted incomes leave the due set, so the bursts are paged by the last ID seen rather than by offset.
+func (app *application) trackRecurringIncomes() {
+	app.logger.Info("Tracking recurring incomes", zap.String("time", time.Now().String()))
+	// Define burst size and start from the first income
+	burst := app.config.limit.recurringIncomeTrackerBurstLimit
+	var lastID int64
+	for {
+		// Retrieve the next burst of recurring incomes that are due
+		recurringIncomesToTrack, err := app.models.FinancialTrackingManager.GetAllRecurringIncomesDueForProcessing(lastID, burst)
+		if err != nil {
+			if errors.Is(err, data.ErrGeneralRecordNotFound) {
+				app.logger.Info("No more recurring incomes to track", zap.Error(err))
+				break
 			}
+			app.logger.Error("Error tracking recurring incomes", zap.Error(err))
+			break
+		}
+		// Process each recurring income in the batch
+		for _, recurringIncomeToTrack := range recurringIncomesToTrack {
+			app.postRecurringIncome(recurringIncomeToTrack.RecurringIncome, recurringIncomeToTrack.UserCurrencyCode)
+			lastID = recurringIncomeToTrack.RecurringIncome.ID
+		}
+		// Check if this is the last burst of records
+		if len(recurringIncomesToTrack) < burst {
+			app.logger.Info("All recurring incomes processed. Ending tracking.")
+			break
+		}
+	}
+}
 
-			// Update the next tracking date for the current recurring expense
-			recurringExpenseToTrack.CalculateNextOccurrence()
-			err = app.models.FinancialTrackingManager.UpdateRecurringExpenseByID(recurringExpenseToTrack.UserID, recurringExpenseToTrack)
+// postRecurringIncome() posts all the due occurrences of a single recurring income.
+// Each occurrence is converted at the exchange rate of the day it was due on, and is saved together
+// with its goal allocation and the move to the next occurrence in a single transaction.
+func (app *application) postRecurringIncome(recurringIncome *data.RecurringIncome, userCurrencyCode string) {
+	today := time.Now()
+	postedCount := 0
+	for !recurringIncome.NextOccurrence.After(today) && !recurringIncome.HasEnded() {
+		// get the exchange rate, falling back to a 1:1 rate when the currencies match
+		exchangeRate := decimal.NewFromInt(1)
+		amount := recurringIncome.AmountOriginal
+		if userCurrencyCode != "" && userCurrencyCode != recurringIncome.OriginalCurrencyCode {
+			convertedAmount, err := app.convertAndGetExchangeRateOnDate(recurringIncome.OriginalCurrencyCode, userCurrencyCode, recurringIncome.NextOccurrence)
 			if err != nil {
-				app.logger.Error("Error updating recurring expense", zap.Error(err))
-				continue
+				app.logger.Error("Error converting recurring income currency", zap.Int64("recurring_income_id", recurringIncome.ID), zap.Error(err))
+				break
 			}
+			amount = convertedAmount.ConvertAmount(recurringIncome.AmountOriginal).ConvertedAmount
+			exchangeRate = convertedAmount.ConversionRate
 		}
-
-		// Check if this is the last page of records
-		if metadata.LastPage == metadata.CurrentPage {
-			app.logger.Info("All recurring expenses processed. Ending tracking.")
+		income := &data.Income{
+			Source:               recurringIncome.Source,
+			OriginalCurrencyCode: recurringIncome.OriginalCurrencyCode,
+			AmountOriginal:       recurringIncome.AmountOriginal,
+			Amount:               amount,
+			ExchangeRate:         exchangeRate,
+			Description:          recurringIncome.Description,
+			DateReceived:         recurringIncome.NextOccurrence,
+		}
+		// allocate part of the income to the linked goal
+		var trackedGoal *data.TrackedGoal
+		if allocation := recurringIncome.CalculateGoalAllocation(amount); allocation.IsPositive() {
+			trackedGoal = &data.TrackedGoal{
+				GoalID:            recurringIncome.GoalID,
+				TrackingDate:      income.DateReceived,
+				ContributedAmount: allocation,
+				TrackingType:      data.FinManTrackingTypeEnumOther,
+			}
+		}
+		// move the schedule forward, keeping a copy to restore if the posting fails
+		previous := *recurringIncome
+		recurringIncome.LastPostedAt = income.DateReceived
+		recurringIncome.CalculateNextOccurrence()
+		err := app.models.FinancialTrackingManager.PostRecurringIncome(recurringIncome, income, trackedGoal)
+		if err != nil {
+			*recurringIncome = previous
+			app.logger.Error("Error posting recurring income", zap.Int64("recurring_income_id", recurringIncome.ID), zap.Error(err))
 			break
 		}
-
-		// Move to the next page
-		currentPage = metadata.CurrentPage + 1
+		postedCount++
+	}
+	// nothing was posted, no need to notify
+	if postedCount == 0 {
+		return
+	}
+	// notify the user
+	notificationContent := data.NotificationContent{
+		Message: fmt.Sprintf("Your recurring income from %s of %s %s has been received", recurringIncome.Source, recurringIncome.AmountOriginal.String(), recurringIncome.OriginalCurrencyCode),
+		Meta: data.NotificationMeta{
+			Url:      "",
+			ImageUrl: "",
+			Tags:     "recurring_income",
+		},
+	}
+	if postedCount > 1 {
+		notificationContent.Message = fmt.Sprintf("%d missed postings of your recurring income from %s have been received", postedCount, recurringIncome.Source)
+	}
+	err := app.PublishNotificationToRedis(recurringIncome.UserID, data.NotificationTypeFinancialTracking, notificationContent)
+	if err != nil {
+		app.logger.Error("Error publishing recurring income notification", zap.Error(err))
 	}
 }
 
-// trackOverdueDebts() is the method called by the cronjob to track all the debts that are overdue
-// Just like the trackRecurringExpenses, we will need to pass a burst and offset. After each burst, wwe recieve the  debts than need to be tracked
-// or rather the debts that need accrued interest recalculation
-// For each debt, recalculate the updated interest, update the accrued interest and remaining balance
-// After that, save the updated debt record back, and send a notification to the user
-// After processing we increment the offset by the burst and repeat the process until we get
+// trackDebtInterestAccrual() is the method called by the cronjob to accrue the interest of all debts
+// Just like the trackRecurringExpenses, we will need to pass a burst and offset. After each burst, we recieve the debts
+// whose interest has not been accrued today or whose next payment was missed
+// For each debt, we accrue the interest since it was last calculated and handle any missed payments
+// After processing we increment the offset by the burst and repeat the process until
 // we are in the last page of the records.
-func (app *application) trackOverdueDebts() {
+func (app *application) trackDebtInterestAccrual() {
+	app.logger.Info("Accruing debt interest", zap.String("time", time.Now().String()))
 	// burst
-	burst := app.config.limit.overdueDebtTrackerBurstLimit
-	currentPage := 1
+	burst := app.config.limit.debtInterestAccrualBurstLimit
+	// accrued debts drop out of the results, so we page by the last debt we handled
+	var lastID int64
 	for {
-		//make a filter
-		filter := data.Filters{
-			Page:     currentPage,
-			PageSize: burst,
-		}
-		// get all overdue debt
-		debts, metadata, err := app.models.FinancialTrackingManager.GetAllOverdueDebts(filter)
+		// get the next batch of debts due for interest accrual
+		debts, err := app.models.FinancialTrackingManager.GetAllDebtsDueForInterestAccrual(lastID, burst)
 		if err != nil {
 			if errors.Is(err, data.ErrGeneralRecordNotFound) {
-				app.logger.Info("No more overdue debts to track", zap.Error(err))
+				app.logger.Info("No more debts to accrue interest for", zap.Error(err))
 				break
 			}
-			app.logger.Error("Error tracking overdue debts", zap.Error(err))
+			app.logger.Error("Error accruing debt interest", zap.Error(err))
 			break
 		}
 		for _, debt := range debts {
-			//app.logger.Info("Processing debt ID:", zap.Int64("debtID", debt.ID))
-			// calculate the updated interest
-			accruedInterest, err := app.calculateInterestPayment(debt)
-			if err != nil {
-				app.logger.Info("Error calculating interest for debt ID:", (zap.Error(err)))
-				continue
-			}
-			// Update the accrued interest and remaining balance
-			debt.AccruedInterest = accruedInterest
-			debt.RemainingBalance = debt.RemainingBalance.Add(accruedInterest) // Add interest to balance
-			debt.InterestLastCalculated = time.Now()                           // Update interest last calculated date
-			// Step 3: Save updated debt back to the database
-			err = app.models.FinancialTrackingManager.UpdateDebtByID(debt.UserID, debt)
-			if err != nil {
-				app.logger.Info("Error updating debt", zap.Error(err))
-				continue
-			}
-			// ToDO: SEND notification
+			app.accrueDebtInterest(debt)
+			lastID = debt.ID
 		}
-		// Check if this is the last page of records
-		if metadata.LastPage == metadata.CurrentPage {
-			app.logger.Info("All overdue debtsprocessed, ending tracking.")
+		// Check if this is the last batch of records
+		if len(debts) < burst {
+			app.logger.Info("All debts processed, ending interest accrual.")
 			break
 		}
+	}
+}
 
-		// Move to the next page
-		currentPage = metadata.CurrentPage + 1
-
+// accrueDebtInterest() accrues the interest of a single debt up to today and handles its missed payments.
+// Every missed payment is charged its late fee, which is recorded as a late fee entry, and the user
+// is notified once about all the payments that were missed.
+func (app *application) accrueDebtInterest(debt *data.Debt) {
+	today := time.Now()
+	// accrue the interest since it was last calculated
+	debt.AccrueInterest(today)
+	// handle every payment that was missed, catching up if the job did not run
+	var missedDueDates []string
+	lateFees := decimal.Zero
+	for debt.IsOverdue(today) {
+		missedDueDate, lateFee := debt.ApplyOverdueCharges()
+		if lateFee.IsPositive() {
+			err := app.models.FinancialTrackingManager.CreateNewDebtLateFee(debt.UserID, &data.DebtLateFee{
+				DebtID:        debt.ID,
+				Amount:        lateFee,
+				MissedDueDate: missedDueDate,
+			})
+			if err != nil && !errors.Is(err, data.ErrDuplicateDebtLateFee) {
+				app.logger.Error("Error creating debt late fee", zap.Int64("debt_id", debt.ID), zap.Error(err))
+				return
+			}
+			lateFees = lateFees.Add(lateFee)
+		}
+		missedDueDates = append(missedDueDates, missedDueDate.Format(time.DateOnly))
+	}
+	// save the updated debt
+	err := app.models.FinancialTrackingManager.UpdateDebtByID(debt.UserID, debt)
+	if err != nil {
+		app.logger.Error("Error updating debt", zap.Int64("debt_id", debt.ID), zap.Error(err))
+		return
+	}
+	if len(missedDueDates) == 0 {
+		return
+	}
+	// notify the user about the missed payments
+	message := fmt.Sprintf("Your payment for %s due on %s is overdue", debt.Name, strings.Join(missedDueDates, ", "))
+	if lateFees.IsPositive() {
+		message = fmt.Sprintf("%s. A late fee of %s has been added to your balance", message, lateFees.StringFixed(2))
+	}
+	notificationContent := data.NotificationContent{
+		Message: message,
+		Meta: data.NotificationMeta{
+			Url:      "",
+			ImageUrl: "",
+			Tags:     "debt_overdue",
+		},
+	}
+	err = app.PublishNotificationToRedis(debt.UserID, data.NotificationTypeFinancialTracking, notificationContent)
+	if err != nil {
+		app.logger.Error("Error publishing overdue debt notification", zap.Error(err))
 	}
 }
 
@@ -371,3 +655,291 @@ func (app *application) trackExpiredNotifications() {
 		currentPage = metadata.CurrentPage + 1
 	}
 }
+
+// trackExpiredReceiptDrafts() deletes receipt drafts that were never confirmed along with their receipts
+func (app *application) trackExpiredReceiptDrafts() {
+	app.logger.Info("Tracking expired receipt drafts..", zap.String("time", time.Now().String()))
+	storageKeys, err := app.models.ReceiptManager.DeleteExpiredReceiptDrafts()
+	if err != nil {
+		app.logger.Error("Error deleting expired receipt drafts", zap.Error(err))
+		return
+	}
+	for _, storageKey := range storageKeys {
+		err = app.storage.Delete(context.Background(), storageKey)
+		if err != nil {
+			app.logger.Error("unable to delete receipt from storage", zap.String("key", storageKey), zap.Error(err))
+		}
+	}
+	app.logger.Info("Expired receipt drafts deleted", zap.Int("count", len(storageKeys)))
+}
+
+// trackDailyExchangeRates() fetches the latest exchange rates, refreshing the cached currencies
+// and storing the rates in the exchange rate history
+func (app *application) trackDailyExchangeRates() {
+	app.logger.Info("Tracking daily exchange rates..", zap.String("time", time.Now().String()))
+	err := app.getAndSaveAvailableCurrencies()
+	if err != nil {
+		app.logger.Error("Error tracking daily exchange rates", zap.Error(err))
+	}
+}
+
+// trackSubscriptions() runs subscription detection for every user that recorded an expense since the
+// last run. Other users' subscriptions cannot have changed
+func (app *application) trackSubscriptions() {
+	app.logger.Info("Tracking subscriptions..", zap.String("time", time.Now().String()))
+	userIDs, err := app.models.SubscriptionManager.GetUserIDsWithExpensesSince(time.Now().Add(-25 * time.Hour))
+	if err != nil {
+		app.logger.Error("Error getting users with new expenses", zap.Error(err))
+		return
+	}
+	for _, userID := range userIDs {
+		_, err := app.detectSubscriptionsHelper(userID)
+		if err != nil {
+			app.logger.Error("Error detecting subscriptions", zap.Int64("user_id", userID), zap.Error(err))
+		}
+	}
+	app.logger.Info("Subscriptions tracked", zap.Int("users", len(userIDs)))
+}
+
+// trackSpendingAnomalies() runs anomaly detection for every user that recorded an expense since the
+// last run. Anomalies that were already found are not alerted about again
+func (app *application) trackSpendingAnomalies() {
+	app.logger.Info("Tracking spending anomalies..", zap.String("time", time.Now().String()))
+	userIDs, err := app.models.AnomalyManager.GetAnomalyUserIDsSince(time.Now().Add(-25 * time.Hour))
+	if err != nil {
+		app.logger.Error("Error getting users with new expenses", zap.Error(err))
+		return
+	}
+	for _, userID := range userIDs {
+		_, err := app.detectSpendingAnomaliesHelper(userID)
+		if err != nil {
+			app.logger.Error("Error detecting spending anomalies", zap.Int64("user_id", userID), zap.Error(err))
+		}
+	}
+	app.logger.Info("Spending anomalies tracked", zap.Int("users", len(userIDs)))
+}
+
+// trackNetWorthSnapshots() saves today's net worth snapshot for every user holding investments or debts.
+// Running it again on the same day replaces that day's snapshot
+func (app *application) trackNetWorthSnapshots() {
+	app.logger.Info("Tracking net worth snapshots..", zap.String("time", time.Now().String()))
+	userIDs, err := app.models.NetWorthManager.GetNetWorthSnapshotUserIDs()
+	if err != nil {
+		app.logger.Error("Error getting users for net worth snapshots", zap.Error(err))
+		return
+	}
+	now := time.Now().UTC()
+	for _, userID := range userIDs {
+		snapshot, err := app.currentNetWorthHelper(userID, now)
+		if err != nil {
+			app.logger.Error("Error calculating net worth", zap.Int64("user_id", userID), zap.Error(err))
+			continue
+		}
+		err = app.models.NetWorthManager.SaveNetWorthSnapshot(userID, snapshot)
+		if err != nil {
+			app.logger.Error("Error saving net worth snapshot", zap.Int64("user_id", userID), zap.Error(err))
+		}
+	}
+	app.logger.Info("Net worth snapshots tracked", zap.Int("users", len(userIDs)))
+}
+
+// trackGroupContributionDues() creates the dues of every contribution schedule that fell due, then
+// reminds members about dues that are still unpaid. Dues are paid off by the database as members
+// contribute to the goal
+func (app *application) trackGroupContributionDues() {
+	app.logger.Info("Tracking group contribution dues..", zap.String("time", time.Now().String()))
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	schedules, err := app.models.FinancialGroupManager.GetGroupContributionSchedulesDue(today)
+	if err != nil {
+		app.logger.Error("Error getting due group contribution schedules", zap.Error(err))
+		return
+	}
+	for _, schedule := range schedules {
+		app.createGroupContributionDues(schedule, today)
+	}
+	app.remindGroupContributionDues(today)
+	app.logger.Info("Group contribution dues tracked", zap.Int("schedules", len(schedules)))
+}
+
+// createGroupContributionDues() creates the dues of a schedule for every due date up to today,
+// catching up on any runs that were missed, tells the members and moves the schedule on
+func (app *application) createGroupContributionDues(schedule *data.GroupContributionSchedule, today time.Time) {
+	createdCount := 0
+	for !schedule.NextDueDate.After(today) {
+		memberIDs, err := app.models.FinancialGroupManager.CreateGroupContributionDues(schedule.ID, schedule.NextDueDate)
+		if err != nil {
+			app.logger.Error("Error creating group contribution dues", zap.Int64("schedule_id", schedule.ID), zap.Error(err))
+			break
+		}
+		// dues created while catching up on missed runs have already fallen due
+		dueMessage := "is due on"
+		if schedule.NextDueDate.Before(today) {
+			dueMessage = "was due on"
+		}
+		for _, memberID := range memberIDs {
+			notificationContent := data.NotificationContent{
+				Message: fmt.Sprintf("Your contribution of %s to %s %s %s", schedule.AmountPerMember.StringFixed(2), schedule.GoalName, dueMessage, schedule.NextDueDate.Format("2006-01-02")),
+				Meta: data.NotificationMeta{
+					Url:      "",
+					ImageUrl: "",
+					Tags:     "group_contribution",
+				},
+			}
+			err = app.PublishNotificationToRedis(memberID, data.NotificationTypeGroupContribution, notificationContent)
+			if err != nil {
+				app.logger.Error("Error publishing group contribution notification", zap.Error(err))
+			}
+		}
+		schedule.AdvanceDueDate()
+		createdCount++
+	}
+	// nothing was created, no need to move the schedule
+	if createdCount == 0 {
+		return
+	}
+	err := app.models.FinancialGroupManager.UpdateGroupContributionScheduleNextDueDate(schedule)
+	if err != nil {
+		app.logger.Error("Error updating group contribution schedule", zap.Int64("schedule_id", schedule.ID), zap.Error(err))
+	}
+}
+
+// remindGroupContributionDues() reminds members about their overdue dues, at most once every
+// data.GroupDueReminderInterval
+func (app *application) remindGroupContributionDues(today time.Time) {
+	dues, err := app.models.FinancialGroupManager.GetGroupContributionDuesToRemind(today)
+	if err != nil {
+		app.logger.Error("Error getting group contribution dues to remind", zap.Error(err))
+		return
+	}
+	remindedIDs := []int64{}
+	for _, due := range dues {
+		notificationContent := data.NotificationContent{
+			Message: fmt.Sprintf("Your contribution of %s to %s in %s was due on %s and is still unpaid", due.Amount.StringFixed(2), due.GoalName, due.GroupName, due.DueDate.Format("2006-01-02")),
+			Meta: data.NotificationMeta{
+				Url:      "",
+				ImageUrl: "",
+				Tags:     "group_contribution",
+			},
+		}
+		err = app.PublishNotificationToRedis(due.MemberID, data.NotificationTypeGroupContribution, notificationContent)
+		if err != nil {
+			app.logger.Error("Error publishing group contribution reminder", zap.Error(err))
+			continue
+		}
+		remindedIDs = append(remindedIDs, due.ID)
+	}
+	if len(remindedIDs) == 0 {
+		return
+	}
+	err = app.models.FinancialGroupManager.MarkGroupContributionDuesReminded(remindedIDs)
+	if err != nil {
+		app.logger.Error("Error marking group contribution dues as reminded", zap.Error(err))
+	}
+}
+
+// trackGroupDeletions() purges the groups that were deleted and whose grace period has passed.
+// The attachments of the group's expenses are removed from storage as their rows go with the group
+func (app *application) trackGroupDeletions() {
+	app.logger.Info("Tracking group deletions..", zap.String("time", time.Now().String()))
+	groups, err := app.models.FinancialGroupManager.GetGroupsDueForDeletion(time.Now(), data.GroupDeletionBatchLimit)
+	if err != nil {
+		app.logger.Error("Error getting groups due for deletion", zap.Error(err))
+		return
+	}
+	deletedCount := 0
+	for _, group := range groups {
+		attachments, err := app.models.AttachmentManager.GetExpenseAttachmentsByGroupID(group.ID)
+		if err != nil {
+			app.logger.Error("Error getting the attachments of a deleted group", zap.Int64("group_id", group.ID), zap.Error(err))
+			continue
+		}
+		err = app.models.FinancialGroupManager.DeleteGroup(group.ID)
+		if err != nil {
+			app.logger.Error("Error deleting group", zap.Int64("group_id", group.ID), zap.Error(err))
+			continue
+		}
+		app.deleteStoredAttachments(attachments)
+		deletedCount++
+	}
+	app.logger.Info("Group deletions tracked", zap.Int("deleted", deletedCount))
+}
+
+// trackGroupChallenges() updates the standings of every running group challenge, telling
+// participants when they move up or down, and settles the challenges whose last day has passed
+func (app *application) trackGroupChallenges() {
+	app.logger.Info("Tracking group challenges..", zap.String("time", time.Now().String()))
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	challenges, err := app.models.FinancialGroupManager.GetActiveGroupChallenges(today)
+	if err != nil {
+		app.logger.Error("Error getting active group challenges", zap.Error(err))
+		return
+	}
+	for _, challenge := range challenges {
+		app.updateGroupChallengeStandings(challenge, today)
+	}
+	app.logger.Info("Group challenges tracked", zap.Int("challenges", len(challenges)))
+}
+
+// updateGroupChallengeStandings() checks the leaderboard of a challenge. Participants who completed
+// the challenge get the challenge award, those whose rank changed are told and once the challenge
+// has ended everyone hears where they finished. A challenge is marked as ended before its results
+// are sent, and only the run that ended it sends them
+func (app *application) updateGroupChallengeStandings(challenge *data.GroupChallenge, today time.Time) {
+	ended := challenge.HasEnded(today)
+	if ended {
+		endedNow, err := app.models.FinancialGroupManager.EndGroupChallenge(challenge.ID)
+		if err != nil {
+			app.logger.Error("Error ending group challenge", zap.Int64("challenge_id", challenge.ID), zap.Error(err))
+			return
+		}
+		if !endedNow {
+			return
+		}
+	}
+	standings, err := app.models.FinancialGroupManager.GetGroupChallengeLeaderboard(challenge.ID)
+	if err != nil {
+		app.logger.Error("Error getting group challenge leaderboard", zap.Int64("challenge_id", challenge.ID), zap.Error(err))
+		return
+	}
+	for _, standing := range standings {
+		if standing.CompletedAt == nil && challenge.IsCompletedBy(standing.Progress, ended) {
+			app.completeGroupChallenge(challenge, standing.UserID)
+		}
+		if standing.Rank != standing.PreviousRank {
+			err = app.models.FinancialGroupManager.UpdateGroupChallengeParticipantRank(challenge.ID, standing.UserID, standing.Rank)
+			if err != nil {
+				app.logger.Error("Error updating group challenge rank", zap.Int64("challenge_id", challenge.ID), zap.Error(err))
+			}
+			// standings only change while the challenge runs, the final results are sent below
+			if change := standing.RankChange(); change != 0 && !ended {
+				message := fmt.Sprintf("You moved up to #%d in the challenge %s", standing.Rank, challenge.Name)
+				if change < 0 {
+					message = fmt.Sprintf("You dropped to #%d in the challenge %s", standing.Rank, challenge.Name)
+				}
+				app.publishGroupChallengeNotification(standing.UserID, challenge, message)
+			}
+		}
+		if ended {
+			app.publishGroupChallengeNotification(standing.UserID, challenge,
+				fmt.Sprintf("The challenge %s has ended, you finished #%d of %d", challenge.Name, standing.Rank, len(standings)))
+		}
+	}
+}
+
+// completeGroupChallenge() marks a participant as having completed a challenge and grants them
+// the challenge award. The award's own notification is sent by the award listener
+func (app *application) completeGroupChallenge(challenge *data.GroupChallenge, userID int64) {
+	completed, err := app.models.FinancialGroupManager.CompleteGroupChallengeParticipant(challenge.ID, userID)
+	if err != nil {
+		app.logger.Error("Error completing group challenge", zap.Int64("challenge_id", challenge.ID), zap.Error(err))
+		return
+	}
+	if !completed {
+		return
+	}
+	_, err = app.models.AwardManager.CreateNewUserAwardByCode(userID, data.GroupChallengeAwardCode)
+	if err != nil {
+		app.logger.Error("Error granting group challenge award", zap.Int64("user_id", userID), zap.Error(err))
+	}
+	app.publishGroupChallengeNotification(userID, challenge, fmt.Sprintf("You completed the challenge %s", challenge.Name))
+}
@@ -53,8 +53,15 @@ func (app *application) server() error {
 			app.config.scheduler.trackGoalProgressStatus,
 			app.config.scheduler.trackExpiredGroupInvitations,
 			app.config.scheduler.trackRecurringExpenses,
-			app.config.scheduler.trackOverdueDebts,
+			app.config.scheduler.trackRecurringIncomes,
+			app.config.scheduler.trackDebtInterestAccrual,
 			app.config.scheduler.trackExpiredNotifications,
+			app.config.scheduler.trackExpiredReceiptDrafts,
+			app.config.scheduler.trackDailyExchangeRates,
+			app.config.scheduler.trackSubscriptions,
+			app.config.scheduler.trackSpendingAnomalies,
+			app.config.scheduler.trackNetWorthSnapshots,
+			app.config.scheduler.trackGroupContributionDues,
 			app.config.scheduler.rssFeedScraper,
 		)
 		// Call Shutdown() on our server, passing in the context we just made.
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"go.uber.org/zap"
+)
+
+// getDetectedSubscriptionsHandler() returns the subscriptions detected in the user's expenses.
+// The list can be narrowed down to a single status i.e active, converted or dismissed
+func (app *application) getDetectedSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	status := app.readString(r.URL.Query(), "status", "")
+	v := validator.New()
+	if data.ValidateSubscriptionStatus(v, status); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	subscriptions, err := app.models.SubscriptionManager.GetDetectedSubscriptionsByUserID(app.contextGetUser(r).ID, status)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"subscriptions": subscriptions}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// scanSubscriptionsHandler() scans the user's expenses for subscriptions straight away instead of
+// waiting for the daily scan, and returns the subscriptions that were found
+func (app *application) scanSubscriptionsHandler(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := app.detectSubscriptionsHelper(app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"subscriptions": subscriptions}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// convertSubscriptionHandler() turns a detected subscription into a recurring expense on the budget of
+// its latest charge. The schedule follows on from the latest charge, which is already recorded.
+// As the charges are already being made, going over the budget's surplus is reported but not refused
+func (app *application) convertSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	var message = data.Warning_Messages
+	subscriptionID, err := app.readIDParam(r, "subscriptionID")
+	if err != nil || subscriptionID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	user := app.contextGetUser(r)
+	subscription, err := app.models.SubscriptionManager.GetDetectedSubscriptionByID(user.ID, subscriptionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if subscription.Status != data.SubscriptionStatusActive {
+		app.badRequestResponse(w, r, data.ErrSubscriptionNotActive)
+		return
+	}
+	recurringExpense := subscription.ToRecurringExpense(time.Now())
+	v := validator.New()
+	if data.ValidateRecurringExpense(v, recurringExpense); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	goalTotals, err := app.models.FinancialManager.GetAllGoalSummaryBudgetID(recurringExpense.BudgetID, user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if recurringExpense.ProjectedAmount.Cmp(goalTotals.TotalSurplus) > 0 {
+		message.Message = append(message.Message, "recurring expense amount is more than the available surplus")
+	}
+	// create the recurring expense and remember the conversion so the subscription is not suggested again
+	err = app.models.SubscriptionManager.ConvertDetectedSubscription(user.ID, subscription, recurringExpense)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateRecurringExpense):
+			v.AddError("name", "recurring expense already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusCreated, envelope{"expense": recurringExpense, "subscription": subscription, "message": message}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// dismissSubscriptionHandler() marks a detected subscription as not being a subscription.
+// It stays dismissed in later scans and no longer sends price increase alerts
+func (app *application) dismissSubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+	subscriptionID, err := app.readIDParam(r, "subscriptionID")
+	if err != nil || subscriptionID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	user := app.contextGetUser(r)
+	subscription, err := app.models.SubscriptionManager.GetDetectedSubscriptionByID(user.ID, subscriptionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	if subscription.Status != data.SubscriptionStatusActive {
+		app.badRequestResponse(w, r, data.ErrSubscriptionNotActive)
+		return
+	}
+	subscription.Status = data.SubscriptionStatusDismissed
+	err = app.models.SubscriptionManager.UpdateDetectedSubscriptionStatus(user.ID, subscription)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"subscription": subscription}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// detectSubscriptionsHelper() runs subscription detection over the user's recent expenses and saves
+// the results. Active subscriptions whose latest charge went up send the user a price increase alert
+func (app *application) detectSubscriptionsHelper(userID int64) ([]*data.DetectedSubscription, error) {
+	now := time.Now().UTC()
+	charges, err := app.models.SubscriptionManager.GetSubscriptionChargesByUserID(userID, now.Add(-data.SubscriptionLookbackPeriod))
+	if err != nil {
+		return nil, err
+	}
+	recurringExpenseNames, err := app.models.SubscriptionManager.GetRecurringExpenseNamesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := app.models.SubscriptionManager.GetDetectedSubscriptionsByUserID(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	previous := make(map[string]*data.DetectedSubscription, len(stored))
+	for _, subscription := range stored {
+		previous[subscription.MerchantKey] = subscription
+	}
+	subscriptions := data.DetectSubscriptions(charges, recurringExpenseNames, now)
+	for _, subscription := range subscriptions {
+		increase, increased := subscription.PriceIncreaseSince(previous[subscription.MerchantKey])
+		err = app.models.SubscriptionManager.UpsertDetectedSubscription(userID, subscription)
+		if err != nil {
+			return nil, err
+		}
+		if !increased {
+			continue
+		}
+		notificationContent := data.NotificationContent{
+			Message: fmt.Sprintf("Your %s subscription went up by %s to %s", subscription.Name, increase.StringFixed(2), subscription.Amount.StringFixed(2)),
+			Meta: data.NotificationMeta{
+				Url:      "",
+				ImageUrl: "",
+				Tags:     "subscription_price_increase",
+			},
+		}
+		err = app.PublishNotificationToRedis(userID, data.NotificationTypeFinancialTracking, notificationContent)
+		if err != nil {
+			app.logger.Error("Error publishing subscription price increase notification", zap.Int64("subscription_id", subscription.ID), zap.Error(err))
+		}
+	}
+	return subscriptions, nil
+}
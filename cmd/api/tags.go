@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+// getAllTagsHandler() returns all of the user's tags together with how many
+// expenses, incomes and group expenses carry each tag
+func (app *application) getAllTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tags, err := app.models.TagManager.GetTagsByUserID(app.contextGetUser(r).ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"tags": tags}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// deleteTagHandler() deletes one of the user's tags, the tag is removed from all its records
+func (app *application) deleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	tagID, err := app.readIDParam(r, "tagID")
+	if err != nil || tagID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	err = app.models.TagManager.DeleteTagByID(app.contextGetUser(r).ID, tagID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"message": "tag deleted successfully"}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// bulkTagHandler() adds or removes tags on many expenses, incomes and group expenses at once.
+// Tags are normalized (lower case, spaces replaced by dashes) and created when first used.
+// Records the user cannot access are skipped, the response holds the number of records changed
+func (app *application) bulkTagHandler(w http.ResponseWriter, r *http.Request) {
+	var input data.BulkTagOperation
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+	input.Tags = data.NormalizeTags(input.Tags)
+	// validate the operation
+	v := validator.New()
+	if data.ValidateBulkTagOperation(v, &input); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	result, err := app.models.TagManager.BulkTag(app.contextGetUser(r).ID, &input)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"result": result}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getTagSpendingReportHandler() returns the spending and income per tag for a period.
+// The period is set with start_date and end_date (YYYY-MM-DD) and defaults to the current year
+func (app *application) getTagSpendingReportHandler(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	v := validator.New()
+	qs := r.URL.Query()
+	startDate := app.readDate(qs, "start_date", time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), v)
+	endDate := app.readDate(qs, "end_date", time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), v)
+	v.Check(!endDate.Before(startDate), "end_date", "must not be before the start date")
+	if !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	report, err := app.models.TagManager.GetTagSpendingReport(app.contextGetUser(r).ID, startDate, endDate)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"report": report, "start_date": startDate.Format("2006-01-02"), "end_date": endDate.Format("2006-01-02")}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/data"
+	"github.com/Blue-Davinci/OptiVest/internal/pdf"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+// setExpenseTaxCategoriesHandler() replaces the tax categories of an expense.
+// An empty list removes all the categories from the expense
+func (app *application) setExpenseTaxCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	expenseID, err := app.readIDParam(r, "expenseID")
+	if err != nil || expenseID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	categories, ok := app.readTaxCategoriesHelper(w, r)
+	if !ok {
+		return
+	}
+	err = app.models.TaxManager.SetExpenseTaxCategories(app.contextGetUser(r).ID, expenseID, categories)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"expense_id": expenseID, "tax_categories": categories}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// setIncomeTaxCategoriesHandler() replaces the tax categories of an income.
+// An empty list removes all the categories from the income
+func (app *application) setIncomeTaxCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	incomeID, err := app.readIDParam(r, "incomeID")
+	if err != nil || incomeID < 1 {
+		app.notFoundResponse(w, r)
+		return
+	}
+	categories, ok := app.readTaxCategoriesHelper(w, r)
+	if !ok {
+		return
+	}
+	err = app.models.TaxManager.SetIncomeTaxCategories(app.contextGetUser(r).ID, incomeID, categories)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrGeneralRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	err = app.writeJSON(w, http.StatusOK, envelope{"income_id": incomeID, "tax_categories": categories}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// getAnnualTaxSummaryHandler() returns the user's tax summary for a year (defaults to last year).
+// Tax flagged expenses and incomes are grouped by category and converted to the user's currency at
+// the rate of their date, the investment gains and losses realized during the year are listed as well.
+// The summary can be downloaded for an accountant by setting the format to csv or pdf
+func (app *application) getAnnualTaxSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	qs := r.URL.Query()
+	year := app.readInt(qs, "year", time.Now().UTC().Year()-1, v)
+	format := app.readString(qs, "format", "json")
+	v.Check(validator.PermittedValue(format, "json", "csv", "pdf"), "format", "must be either json, csv or pdf")
+	if data.ValidateTaxYear(v, year); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+	user := app.contextGetUser(r)
+	currencyCode := user.CurrencyCode
+	if currencyCode == "" {
+		currencyCode = app.config.api.defaultcurrency
+	}
+	startDate := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	// get the flagged records and convert the expenses recorded in other currencies
+	expenses, incomes, err := app.models.TaxManager.GetTaxRecordsByUserID(user.ID, startDate, endDate)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// expenses are converted at the rate of the day they occurred on
+	currencies := []string{currencyCode}
+	for _, expense := range expenses {
+		currencies = append(currencies, expense.CurrencyCode)
+	}
+	history, err := app.models.ExchangeRateManager.GetExchangeRateHistory(app.config.api.defaultcurrency, currencies,
+		startDate.Add(-data.MaxExchangeRateAge), endDate)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	// the rates of the days without a stored rate are fetched from the API's history
+	missingDates := make(map[time.Time]bool)
+	for _, expense := range expenses {
+		if _, ok := history.Rate(expense.CurrencyCode, currencyCode, expense.Date); !ok && expense.CurrencyCode != currencyCode {
+			missingDates[expense.Date] = true
+		}
+	}
+	if len(missingDates) > 0 {
+		for date := range missingDates {
+			err = app.getAndSaveHistoricalExchangeRates(date)
+			if err != nil {
+				app.serverErrorResponse(w, r, err)
+				return
+			}
+		}
+		history, err = app.models.ExchangeRateManager.GetExchangeRateHistory(app.config.api.defaultcurrency, currencies,
+			startDate.Add(-data.MaxExchangeRateAge), endDate)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+	data.ConvertTaxRecords(expenses, currencyCode, history, nil)
+	data.ConvertTaxRecords(incomes, currencyCode, history, nil)
+	// realized gains need every transaction up to the end of the year for the cost basis
+	transactions, err := app.models.TaxManager.GetInvestmentTransactionsForTax(user.ID, endDate)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	gains := data.CalculateRealizedGains(transactions, startDate, endDate)
+	summary := data.BuildAnnualTaxSummary(year, currencyCode, expenses, incomes, gains)
+	switch format {
+	case "csv":
+		err = app.writeCSV(w, http.StatusOK, fmt.Sprintf("tax_summary_%d.csv", year), taxSummaryToCSV(summary))
+	case "pdf":
+		err = app.writePDF(w, http.StatusOK, fmt.Sprintf("tax_summary_%d.pdf", year), taxSummaryToPDF(summary, user.FirstName+" "+user.LastName, time.Now()))
+	default:
+		err = app.writeJSON(w, http.StatusOK, envelope{"tax_summary": summary}, nil)
+	}
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// readTaxCategoriesHelper() reads, normalizes and validates the tax categories of a request body.
+// It writes the error response itself and returns false when the input is not valid
+func (app *application) readTaxCategoriesHelper(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	var input struct {
+		TaxCategories []string `json:"tax_categories"`
+	}
+	err := app.readJSON(w, r, &input)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, false
+	}
+	categories := data.NormalizeTaxCategories(input.TaxCategories)
+	v := validator.New()
+	if data.ValidateTaxCategories(v, categories); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return nil, false
+	}
+	return categories, true
+}
+
+// taxSummaryToCSV() converts a tax summary into CSV records. The file has three sections:
+// the totals per category, the realized gains and the individual records
+func taxSummaryToCSV(summary *data.AnnualTaxSummary) [][]string {
+	records := [][]string{
+		{"tax_summary", strconv.Itoa(summary.Year), summary.CurrencyCode},
+		{},
+		{"tax_category", "total_expenses", "expense_count", "total_income", "income_count"},
+	}
+	for _, category := range summary.Categories {
+		records = append(records, []string{
+			category.TaxCategory,
+			category.TotalExpenses.StringFixed(2),
+			strconv.Itoa(category.ExpenseCount),
+			category.TotalIncome.StringFixed(2),
+			strconv.Itoa(category.IncomeCount),
+		})
+	}
+	records = append(records,
+		[]string{"total", summary.TotalTaxCategorizedExpenses.StringFixed(2), "", summary.TotalTaxCategorizedIncome.StringFixed(2), ""},
+		[]string{},
+		[]string{"sale_date", "investment_type", "investment", "quantity", "proceeds", "cost_basis", "gain_loss"},
+	)
+	for _, gain := range summary.RealizedGains {
+		records = append(records, []string{
+			gain.SaleDate.Format(time.DateOnly),
+			gain.InvestmentType,
+			gain.InvestmentName,
+			gain.Quantity.String(),
+			gain.Proceeds.StringFixed(2),
+			gain.CostBasis.StringFixed(2),
+			gain.GainLoss.StringFixed(2),
+		})
+	}
+	records = append(records,
+		[]string{"net_realized_gain", "", "", "", "", "", summary.NetRealizedGain.StringFixed(2)},
+		[]string{},
+		[]string{"date", "kind", "name", "category", "tax_category", "amount", "original_amount", "original_currency_code"},
+	)
+	for _, record := range append(append([]*data.TaxRecord{}, summary.Expenses...), summary.Incomes...) {
+		records = append(records, []string{
+			record.Date.Format(time.DateOnly),
+			record.Kind,
+			record.Name,
+			record.Category,
+			record.TaxCategory,
+			record.Amount.StringFixed(2),
+			record.OriginalAmount.StringFixed(2),
+			record.CurrencyCode,
+		})
+	}
+	return records
+}
+
+// taxSummaryToPDF() renders a tax summary as a PDF document for the user's accountant
+func taxSummaryToPDF(summary *data.AnnualTaxSummary, owner string, now time.Time) []byte {
+	doc := pdf.New(fmt.Sprintf("Tax Summary %d", summary.Year), owner)
+	doc.Heading(fmt.Sprintf("Annual Tax Summary %d", summary.Year))
+	doc.Text(fmt.Sprintf("Prepared for %s on %s, amounts in %s", owner, now.Format(time.DateOnly), summary.CurrencyCode))
+	doc.Text(fmt.Sprintf("Period %s to %s", summary.StartDate.Format(time.DateOnly), summary.EndDate.Format(time.DateOnly)))
+	doc.Blank()
+	doc.Heading("Totals by tax category")
+	doc.Text(fmt.Sprintf("%-14s %16s %8s %16s %8s", "Category", "Expenses", "Count", "Income", "Count"))
+	for _, category := range summary.Categories {
+		doc.Text(fmt.Sprintf("%-14s %16s %8d %16s %8d", category.TaxCategory, category.TotalExpenses.StringFixed(2),
+			category.ExpenseCount, category.TotalIncome.StringFixed(2), category.IncomeCount))
+	}
+	doc.Text(fmt.Sprintf("%-14s %16s %8s %16s", "Total", summary.TotalTaxCategorizedExpenses.StringFixed(2), "", summary.TotalTaxCategorizedIncome.StringFixed(2)))
+	doc.Blank()
+	doc.Heading("Realized investment gains and losses")
+	if len(summary.RealizedGains) == 0 {
+		doc.Text("No investments were sold during the year")
+	} else {
+		doc.Text(fmt.Sprintf("%-10s %-18s %10s %14s %14s %14s", "Date", "Investment", "Quantity", "Proceeds", "Cost basis", "Gain/Loss"))
+		for _, gain := range summary.RealizedGains {
+			doc.Text(fmt.Sprintf("%-10s %-18.18s %10s %14s %14s %14s", gain.SaleDate.Format(time.DateOnly), gain.InvestmentName,
+				gain.Quantity.String(), gain.Proceeds.StringFixed(2), gain.CostBasis.StringFixed(2), gain.GainLoss.StringFixed(2)))
+		}
+	}
+	doc.Text(fmt.Sprintf("Gains %s, losses %s, net %s", summary.TotalRealizedGains.StringFixed(2),
+		summary.TotalRealizedLosses.StringFixed(2), summary.NetRealizedGain.StringFixed(2)))
+	doc.Blank()
+	doc.Heading("Records")
+	doc.Text(fmt.Sprintf("%-10s %-7s %-30s %-12s %14s %s", "Date", "Kind", "Name", "Tax category", "Amount", "Original"))
+	for _, record := range append(append([]*data.TaxRecord{}, summary.Expenses...), summary.Incomes...) {
+		original := ""
+		if record.CurrencyCode != "" && record.CurrencyCode != summary.CurrencyCode {
+			original = record.OriginalAmount.StringFixed(2) + " " + record.CurrencyCode
+		}
+		doc.Text(fmt.Sprintf("%-10s %-7s %-30.30s %-12s %14s %s", record.Date.Format(time.DateOnly), record.Kind,
+			record.Name, record.TaxCategory, record.Amount.StringFixed(2), original))
+	}
+	return doc.Bytes(now)
+}
@@ -0,0 +1,516 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type AccountManagerModel struct {
+	DB   *database.Queries
+	Conn *sql.DB
+}
+
+// The kinds of accounts a user can hold
+const (
+	AccountTypeChecking   = string(database.AccountTypeEnumChecking)
+	AccountTypeSavings    = string(database.AccountTypeEnumSavings)
+	AccountTypeCreditCard = string(database.AccountTypeEnumCreditCard)
+	AccountTypeCash       = string(database.AccountTypeEnumCash)
+	AccountTypeBrokerage  = string(database.AccountTypeEnumBrokerage)
+)
+
+// The kinds of transactions on an account's register. A transfer shows up on both of its
+// accounts, as money out on the one and money in on the other
+const (
+	AccountTransactionExpense               = "expense"
+	AccountTransactionIncome                = "income"
+	AccountTransactionDebtPayment           = "debt_payment"
+	AccountTransactionInvestmentTransaction = "investment_transaction"
+	AccountTransactionTransferOut           = "transfer_out"
+	AccountTransactionTransferIn            = "transfer_in"
+)
+
+var (
+	DefaultAccountDBContextTimeout = 10 * time.Second
+)
+
+var (
+	ErrDuplicateAccountName = errors.New("an account with this name already exists")
+	ErrDuplicateAccountDebt = errors.New("this debt is already linked to another account")
+)
+
+// Account is a place the user's money sits in i.e a checking account or a credit card.
+// Balance is the opening balance plus everything posted to the account, ClearedBalance only
+// counts the transactions that have been cleared against a statement. Credit cards carrying
+// debt have a negative balance, DebtID links a credit card to the debt tracking the same card
+type Account struct {
+	ID                int64                    `json:"id"`
+	UserID            int64                    `json:"user_id"`
+	Name              string                   `json:"name"`
+	AccountType       database.AccountTypeEnum `json:"account_type"`
+	CurrencyCode      string                   `json:"currency_code"`
+	OpeningBalance    decimal.Decimal          `json:"opening_balance"`
+	OpeningDate       time.Time                `json:"opening_date"`
+	Balance           decimal.Decimal          `json:"balance"`
+	ClearedBalance    decimal.Decimal          `json:"cleared_balance"`
+	ReconciledBalance decimal.Decimal          `json:"reconciled_balance"`
+	ReconciledAt      time.Time                `json:"reconciled_at"`
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         time.Time                `json:"updated_at"`
+	DebtID            int64                    `json:"debt_id,omitempty"`
+}
+
+// AccountTransfer moves money between two of the user's accounts. Amount leaves the source
+// account and ToAmount arrives in the destination account, in its own currency
+type AccountTransfer struct {
+	ID            int64           `json:"id"`
+	UserID        int64           `json:"user_id"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	ToAmount      decimal.Decimal `json:"to_amount"`
+	ExchangeRate  decimal.Decimal `json:"exchange_rate"`
+	Description   string          `json:"description"`
+	TransferDate  time.Time       `json:"transfer_date"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// AccountTransaction is a single line of an account's register. Amount is what the transaction
+// added to (or took from when negative) the account and Balance is the running balance after it
+type AccountTransaction struct {
+	TransactionType string          `json:"transaction_type"`
+	ID              int64           `json:"id"`
+	Description     string          `json:"description"`
+	Amount          decimal.Decimal `json:"amount"`
+	TransactionDate time.Time       `json:"transaction_date"`
+	IsCleared       bool            `json:"is_cleared"`
+	Balance         decimal.Decimal `json:"balance"`
+}
+
+// AccountReconciliation compares a statement to the account. Everything dated on or before the
+// statement date counts as cleared, the account reconciles when the cleared balance matches the
+// statement balance and Difference is what is still unaccounted for when it does not
+type AccountReconciliation struct {
+	StatementDate    time.Time       `json:"statement_date"`
+	StatementBalance decimal.Decimal `json:"statement_balance"`
+	ClearedBalance   decimal.Decimal `json:"cleared_balance"`
+	Difference       decimal.Decimal `json:"difference"`
+	NewlyCleared     int             `json:"newly_cleared"`
+	Reconciled       bool            `json:"reconciled"`
+}
+
+// ValidateAccount() validates an account's details
+func ValidateAccount(v *validator.Validator, account *Account) {
+	v.Check(account.Name != "", "name", "must be provided")
+	v.Check(len(account.Name) <= 255, "name", "must not be more than 255 bytes long")
+	v.Check(validator.PermittedValue(string(account.AccountType), AccountTypeChecking, AccountTypeSavings, AccountTypeCreditCard, AccountTypeCash, AccountTypeBrokerage),
+		"account_type", "must be one of checking, savings, credit_card, cash or brokerage")
+	ValidateCurrencyCode(v, account.CurrencyCode)
+	ValidatePurchaseDate(v, account.OpeningDate, "opening_date")
+	if account.DebtID != 0 {
+		ValidateURLID(v, account.DebtID, "debt_id")
+		v.Check(string(account.AccountType) == AccountTypeCreditCard, "debt_id", "can only be linked to a credit card account")
+	}
+}
+
+// ValidateAccountTransfer() validates a transfer between two accounts
+func ValidateAccountTransfer(v *validator.Validator, transfer *AccountTransfer) {
+	ValidateURLID(v, transfer.FromAccountID, "from_account_id")
+	ValidateURLID(v, transfer.ToAccountID, "to_account_id")
+	v.Check(transfer.FromAccountID != transfer.ToAccountID, "to_account_id", "must be a different account")
+	ValidateAmount(v, transfer.Amount, "amount")
+	ValidatePurchaseDate(v, transfer.TransferDate, "transfer_date")
+	v.Check(len(transfer.Description) <= 500, "description", "must not be more than 500 bytes long")
+}
+
+// ValidateAccountReconciliation() validates the statement an account is reconciled against
+func ValidateAccountReconciliation(v *validator.Validator, statementDate time.Time) {
+	ValidatePurchaseDate(v, statementDate, "statement_date")
+	v.Check(!statementDate.After(time.Now().UTC()), "statement_date", "must not be in the future")
+}
+
+// ValidateAccountCurrency() checks that a transaction posted to an account is in the account's
+// currency. An empty currency code means the transaction's currency is unknown and is not checked
+func ValidateAccountCurrency(v *validator.Validator, account *Account, currencyCode, key string) {
+	v.Check(currencyCode == "" || account.CurrencyCode == currencyCode, key, "must be an account in "+currencyCode)
+}
+
+// BuildAccountRegister() fills in the running balance of an account's transactions, which must be
+// in date order, and the balances of the account itself
+func BuildAccountRegister(account *Account, transactions []*AccountTransaction) {
+	account.Balance = account.OpeningBalance
+	account.ClearedBalance = account.OpeningBalance
+	for _, transaction := range transactions {
+		account.Balance = account.Balance.Add(transaction.Amount)
+		if transaction.IsCleared {
+			account.ClearedBalance = account.ClearedBalance.Add(transaction.Amount)
+		}
+		transaction.Balance = account.Balance
+	}
+}
+
+// AccountTransactionsBetween() returns the transactions dated between the start and end date.
+// A zero date leaves that side of the range open
+func AccountTransactionsBetween(transactions []*AccountTransaction, startDate, endDate time.Time) []*AccountTransaction {
+	filtered := []*AccountTransaction{}
+	for _, transaction := range transactions {
+		if !startDate.IsZero() && transaction.TransactionDate.Before(dateOnly(startDate)) {
+			continue
+		}
+		if !endDate.IsZero() && transaction.TransactionDate.After(dateOnly(endDate)) {
+			continue
+		}
+		filtered = append(filtered, transaction)
+	}
+	return filtered
+}
+
+// ReconcileAccount() works out whether a statement matches the account once every transaction
+// dated on or before the statement date is cleared
+func ReconcileAccount(account *Account, transactions []*AccountTransaction, statementDate time.Time, statementBalance decimal.Decimal) *AccountReconciliation {
+	statementDate = dateOnly(statementDate)
+	reconciliation := &AccountReconciliation{
+		StatementDate:    statementDate,
+		StatementBalance: statementBalance,
+		ClearedBalance:   account.OpeningBalance,
+	}
+	for _, transaction := range transactions {
+		dueToClear := !transaction.TransactionDate.After(statementDate)
+		if transaction.IsCleared || dueToClear {
+			reconciliation.ClearedBalance = reconciliation.ClearedBalance.Add(transaction.Amount)
+		}
+		if !transaction.IsCleared && dueToClear {
+			reconciliation.NewlyCleared++
+		}
+	}
+	reconciliation.Difference = statementBalance.Sub(reconciliation.ClearedBalance)
+	reconciliation.Reconciled = reconciliation.Difference.IsZero()
+	return reconciliation
+}
+
+// CreateNewAccount() creates a new account for a user
+func (m AccountManagerModel) CreateNewAccount(userID int64, account *Account) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateNewAccount(ctx, database.CreateNewAccountParams{
+		UserID:         userID,
+		Name:           account.Name,
+		AccountType:    account.AccountType,
+		CurrencyCode:   account.CurrencyCode,
+		OpeningBalance: account.OpeningBalance.String(),
+		OpeningDate:    account.OpeningDate,
+		DebtID:         sql.NullInt64{Int64: account.DebtID, Valid: account.DebtID != 0},
+	})
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_account_name"`:
+			return ErrDuplicateAccountName
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_account_debt"`:
+			return ErrDuplicateAccountDebt
+		default:
+			return err
+		}
+	}
+	account.ID = row.ID
+	account.UserID = userID
+	account.Balance = account.OpeningBalance
+	account.ClearedBalance = account.OpeningBalance
+	account.CreatedAt = row.CreatedAt.Time
+	account.UpdatedAt = row.UpdatedAt.Time
+	return nil
+}
+
+// GetAccountByID() returns one of the user's accounts without its balances, which come from
+// its register
+func (m AccountManagerModel) GetAccountByID(userID, accountID int64) (*Account, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	account, err := m.DB.GetAccountByID(ctx, database.GetAccountByIDParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return populateAccount(account), nil
+}
+
+// GetAccountsByUserID() returns all of the user's accounts with their balances
+func (m AccountManagerModel) GetAccountsByUserID(userID int64) ([]*Account, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetAccountsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	accounts := []*Account{}
+	for _, row := range rows {
+		accounts = append(accounts, populateAccount(row))
+	}
+	return accounts, nil
+}
+
+// UpdateAccountByID() updates an account's details. The currency can not change once set as
+// every transaction on the account is posted in it
+func (m AccountManagerModel) UpdateAccountByID(userID int64, account *Account) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	updatedAt, err := m.DB.UpdateAccountByID(ctx, database.UpdateAccountByIDParams{
+		Name:           account.Name,
+		AccountType:    account.AccountType,
+		OpeningBalance: account.OpeningBalance.String(),
+		OpeningDate:    account.OpeningDate,
+		DebtID:         sql.NullInt64{Int64: account.DebtID, Valid: account.DebtID != 0},
+		ID:             account.ID,
+		UserID:         userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_account_name"`:
+			return ErrDuplicateAccountName
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_account_debt"`:
+			return ErrDuplicateAccountDebt
+		default:
+			return err
+		}
+	}
+	account.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+// DeleteAccountByID() deletes an account together with its transfers. The expenses, incomes,
+// debt payments and investment transactions on it are kept, they are just no longer on an account
+func (m AccountManagerModel) DeleteAccountByID(userID, accountID int64) (int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	deletedID, err := m.DB.DeleteAccountByID(ctx, database.DeleteAccountByIDParams{
+		ID:     accountID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrGeneralRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+	return deletedID, nil
+}
+
+// GetAccountTransactionsByAccountID() returns everything posted to an account in date order.
+// The running balances are filled in by BuildAccountRegister()
+func (m AccountManagerModel) GetAccountTransactionsByAccountID(accountID int64) ([]*AccountTransaction, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetAccountTransactionsByAccountID(ctx, sql.NullInt64{Int64: accountID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	transactions := []*AccountTransaction{}
+	for _, row := range rows {
+		transactions = append(transactions, &AccountTransaction{
+			TransactionType: row.TransactionType,
+			ID:              row.ID,
+			Description:     row.Description,
+			Amount:          decimal.RequireFromString(row.Amount),
+			TransactionDate: row.TransactionDate,
+			IsCleared:       row.IsCleared,
+		})
+	}
+	return transactions, nil
+}
+
+// CreateNewAccountTransfer() saves a transfer between two of the user's accounts
+func (m AccountManagerModel) CreateNewAccountTransfer(userID int64, transfer *AccountTransfer) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateNewAccountTransfer(ctx, database.CreateNewAccountTransferParams{
+		UserID:        userID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount.String(),
+		ToAmount:      transfer.ToAmount.String(),
+		ExchangeRate:  transfer.ExchangeRate.String(),
+		Description:   sql.NullString{String: transfer.Description, Valid: true},
+		TransferDate:  transfer.TransferDate,
+	})
+	if err != nil {
+		return err
+	}
+	transfer.ID = row.ID
+	transfer.UserID = userID
+	transfer.CreatedAt = row.CreatedAt.Time
+	return nil
+}
+
+// DeleteAccountTransferByID() deletes one of the user's transfers
+func (m AccountManagerModel) DeleteAccountTransferByID(userID, transferID int64) (int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	deletedID, err := m.DB.DeleteAccountTransferByID(ctx, database.DeleteAccountTransferByIDParams{
+		ID:     transferID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrGeneralRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+	return deletedID, nil
+}
+
+// ReconcileAccount() reconciles one of the user's accounts against a statement. The account stays
+// locked while its register is read and cleared, so nothing posted in between can be cleared without
+// being counted. Nothing is cleared when the statement does not match, the returned account and
+// register reflect what was cleared
+func (m AccountManagerModel) ReconcileAccount(userID, accountID int64, statementDate time.Time, statementBalance decimal.Decimal) (*Account, []*AccountTransaction, *AccountReconciliation, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	var (
+		account        *Account
+		transactions   []*AccountTransaction
+		reconciliation *AccountReconciliation
+	)
+	err := withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		_, err := q.LockAccountByID(ctx, database.LockAccountByIDParams{
+			ID:     accountID,
+			UserID: userID,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrGeneralRecordNotFound
+			default:
+				return err
+			}
+		}
+		txModel := AccountManagerModel{DB: q}
+		account, err = txModel.GetAccountByID(userID, accountID)
+		if err != nil {
+			return err
+		}
+		transactions, err = txModel.GetAccountTransactionsByAccountID(account.ID)
+		if err != nil {
+			return err
+		}
+		BuildAccountRegister(account, transactions)
+		reconciliation = ReconcileAccount(account, transactions, statementDate, statementBalance)
+		if !reconciliation.Reconciled {
+			return nil
+		}
+		return txModel.ReconcileAccountByID(userID, account, reconciliation)
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if reconciliation.Reconciled {
+		// mirror what was cleared in the database so the balances are current
+		for _, transaction := range transactions {
+			if !transaction.TransactionDate.After(reconciliation.StatementDate) {
+				transaction.IsCleared = true
+			}
+		}
+		BuildAccountRegister(account, transactions)
+	}
+	return account, transactions, reconciliation, nil
+}
+
+// ReconcileAccountByID() clears every transaction on the account dated on or before the statement
+// date and records the statement as the account's last reconciliation
+func (m AccountManagerModel) ReconcileAccountByID(userID int64, account *Account, reconciliation *AccountReconciliation) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAccountDBContextTimeout)
+	defer cancel()
+	updatedAt, err := m.DB.ReconcileAccountByID(ctx, database.ReconcileAccountByIDParams{
+		AccountID:         sql.NullInt64{Int64: account.ID, Valid: true},
+		Column2:           reconciliation.StatementDate,
+		ReconciledBalance: sql.NullString{String: reconciliation.StatementBalance.String(), Valid: true},
+		UserID:            userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	account.ReconciledBalance = reconciliation.StatementBalance
+	account.ReconciledAt = reconciliation.StatementDate
+	account.UpdatedAt = updatedAt.Time
+	return nil
+}
+
+// populateAccount() converts an account row into an Account
+func populateAccount(accountRow interface{}) *Account {
+	switch account := accountRow.(type) {
+	case database.Account:
+		reconciledBalance := decimal.Zero
+		if account.ReconciledBalance.Valid {
+			reconciledBalance = decimal.RequireFromString(account.ReconciledBalance.String)
+		}
+		return &Account{
+			ID:                account.ID,
+			UserID:            account.UserID,
+			Name:              account.Name,
+			AccountType:       account.AccountType,
+			CurrencyCode:      account.CurrencyCode,
+			OpeningBalance:    decimal.RequireFromString(account.OpeningBalance),
+			OpeningDate:       account.OpeningDate,
+			ReconciledBalance: reconciledBalance,
+			ReconciledAt:      account.ReconciledAt.Time,
+			CreatedAt:         account.CreatedAt.Time,
+			UpdatedAt:         account.UpdatedAt.Time,
+			DebtID:            account.DebtID.Int64,
+		}
+	case database.GetAccountsByUserIDRow:
+		reconciledBalance := decimal.Zero
+		if account.ReconciledBalance.Valid {
+			reconciledBalance = decimal.RequireFromString(account.ReconciledBalance.String)
+		}
+		return &Account{
+			ID:                account.ID,
+			UserID:            account.UserID,
+			Name:              account.Name,
+			AccountType:       account.AccountType,
+			CurrencyCode:      account.CurrencyCode,
+			OpeningBalance:    decimal.RequireFromString(account.OpeningBalance),
+			OpeningDate:       account.OpeningDate,
+			Balance:           decimal.RequireFromString(account.Balance),
+			ClearedBalance:    decimal.RequireFromString(account.ClearedBalance),
+			ReconciledBalance: reconciledBalance,
+			ReconciledAt:      account.ReconciledAt.Time,
+			CreatedAt:         account.CreatedAt.Time,
+			UpdatedAt:         account.UpdatedAt.Time,
+			DebtID:            account.DebtID.Int64,
+		}
+	default:
+		return nil
+	}
+}
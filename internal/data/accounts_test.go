@@ -0,0 +1,163 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// accountTestTransactions returns a small register: a cleared income, an expense and a transfer out
+func accountTestTransactions() []*AccountTransaction {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	return []*AccountTransaction{
+		{TransactionType: AccountTransactionIncome, ID: 1, Amount: decimal.NewFromInt(500), TransactionDate: day(1), IsCleared: true},
+		{TransactionType: AccountTransactionExpense, ID: 2, Amount: decimal.RequireFromString("-120.50"), TransactionDate: day(5)},
+		{TransactionType: AccountTransactionTransferOut, ID: 3, Amount: decimal.NewFromInt(-200), TransactionDate: day(20)},
+	}
+}
+
+func TestBuildAccountRegister(t *testing.T) {
+	account := &Account{OpeningBalance: decimal.NewFromInt(1000)}
+	transactions := accountTestTransactions()
+	BuildAccountRegister(account, transactions)
+
+	wantBalances := []string{"1500", "1379.5", "1179.5"}
+	for i, transaction := range transactions {
+		if transaction.Balance.String() != wantBalances[i] {
+			t.Errorf("BuildAccountRegister() balance of transaction %d = %s, want %s", transaction.ID, transaction.Balance, wantBalances[i])
+		}
+	}
+	if account.Balance.String() != "1179.5" {
+		t.Errorf("BuildAccountRegister() account balance = %s, want 1179.5", account.Balance)
+	}
+	if account.ClearedBalance.String() != "1500" {
+		t.Errorf("BuildAccountRegister() cleared balance = %s, want 1500", account.ClearedBalance)
+	}
+}
+
+func TestAccountTransactionsBetween(t *testing.T) {
+	tests := []struct {
+		name      string
+		startDate time.Time
+		endDate   time.Time
+		wantIDs   []int64
+	}{
+		{name: "Open range", wantIDs: []int64{1, 2, 3}},
+		{name: "From a date", startDate: time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC), wantIDs: []int64{2, 3}},
+		{name: "Up to a date", endDate: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), wantIDs: []int64{1, 2}},
+		{name: "Nothing in range", startDate: time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC), endDate: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := AccountTransactionsBetween(accountTestTransactions(), tt.startDate, tt.endDate)
+			if len(filtered) != len(tt.wantIDs) {
+				t.Fatalf("AccountTransactionsBetween() returned %d transactions, want %d", len(filtered), len(tt.wantIDs))
+			}
+			for i, transaction := range filtered {
+				if transaction.ID != tt.wantIDs[i] {
+					t.Errorf("AccountTransactionsBetween()[%d] ID = %d, want %d", i, transaction.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileAccount(t *testing.T) {
+	tests := []struct {
+		name             string
+		statementDate    time.Time
+		statementBalance string
+		wantCleared      string
+		wantDifference   string
+		wantNewlyCleared int
+		wantReconciled   bool
+	}{
+		{name: "Statement matches", statementDate: time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC), statementBalance: "1379.50", wantCleared: "1379.5", wantDifference: "0", wantNewlyCleared: 1, wantReconciled: true},
+		{name: "Statement includes the transfer", statementDate: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), statementBalance: "1179.5", wantCleared: "1179.5", wantDifference: "0", wantNewlyCleared: 2, wantReconciled: true},
+		{name: "Missing transaction", statementDate: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), statementBalance: "1350", wantCleared: "1379.5", wantDifference: "-29.5", wantNewlyCleared: 1},
+		{name: "Nothing new", statementDate: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), statementBalance: "1500", wantCleared: "1500", wantDifference: "0", wantReconciled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &Account{OpeningBalance: decimal.NewFromInt(1000)}
+			reconciliation := ReconcileAccount(account, accountTestTransactions(), tt.statementDate, decimal.RequireFromString(tt.statementBalance))
+			if reconciliation.ClearedBalance.String() != tt.wantCleared {
+				t.Errorf("ReconcileAccount() cleared balance = %s, want %s", reconciliation.ClearedBalance, tt.wantCleared)
+			}
+			if reconciliation.Difference.String() != tt.wantDifference {
+				t.Errorf("ReconcileAccount() difference = %s, want %s", reconciliation.Difference, tt.wantDifference)
+			}
+			if reconciliation.NewlyCleared != tt.wantNewlyCleared {
+				t.Errorf("ReconcileAccount() newly cleared = %d, want %d", reconciliation.NewlyCleared, tt.wantNewlyCleared)
+			}
+			if reconciliation.Reconciled != tt.wantReconciled {
+				t.Errorf("ReconcileAccount() reconciled = %v, want %v", reconciliation.Reconciled, tt.wantReconciled)
+			}
+		})
+	}
+}
+
+func TestValidateAccount(t *testing.T) {
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	checking, creditCard := database.AccountTypeEnumChecking, database.AccountTypeEnumCreditCard
+	tests := []struct {
+		name    string
+		account Account
+		wantKey string
+	}{
+		{name: "Valid account", account: Account{Name: "Everyday checking", AccountType: checking, CurrencyCode: "USD", OpeningDate: date}},
+		{name: "Credit card with debt", account: Account{Name: "Visa", AccountType: creditCard, CurrencyCode: "USD", OpeningBalance: decimal.NewFromInt(-300), OpeningDate: date}},
+		{name: "Credit card linked to its debt", account: Account{Name: "Visa", AccountType: creditCard, CurrencyCode: "USD", OpeningDate: date, DebtID: 4}},
+		{name: "Checking linked to a debt", account: Account{Name: "Everyday checking", AccountType: checking, CurrencyCode: "USD", OpeningDate: date, DebtID: 4}, wantKey: "debt_id"},
+		{name: "Negative debt", account: Account{Name: "Visa", AccountType: creditCard, CurrencyCode: "USD", OpeningDate: date, DebtID: -1}, wantKey: "debt_id"},
+		{name: "Missing name", account: Account{AccountType: checking, CurrencyCode: "USD", OpeningDate: date}, wantKey: "name"},
+		{name: "Unknown type", account: Account{Name: "Pension", AccountType: "pension", CurrencyCode: "USD", OpeningDate: date}, wantKey: "account_type"},
+		{name: "Missing opening date", account: Account{Name: "Everyday checking", AccountType: checking, CurrencyCode: "USD"}, wantKey: "opening_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateAccount(v, &tt.account)
+			if tt.wantKey == "" && !v.Valid() {
+				t.Errorf("ValidateAccount() errors = %v, want none", v.Errors)
+			}
+			if _, ok := v.Errors[tt.wantKey]; tt.wantKey != "" && !ok {
+				t.Errorf("ValidateAccount() errors = %v, want an error for %s", v.Errors, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestValidateAccountTransfer(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		transfer AccountTransfer
+		wantKey  string
+	}{
+		{name: "Valid transfer", transfer: AccountTransfer{FromAccountID: 1, ToAccountID: 2, Amount: decimal.NewFromInt(50), TransferDate: date}},
+		{name: "Same account", transfer: AccountTransfer{FromAccountID: 1, ToAccountID: 1, Amount: decimal.NewFromInt(50), TransferDate: date}, wantKey: "to_account_id"},
+		{name: "Missing source", transfer: AccountTransfer{ToAccountID: 2, Amount: decimal.NewFromInt(50), TransferDate: date}, wantKey: "from_account_id"},
+		{name: "Zero amount", transfer: AccountTransfer{FromAccountID: 1, ToAccountID: 2, TransferDate: date}, wantKey: "amount"},
+		{name: "Missing date", transfer: AccountTransfer{FromAccountID: 1, ToAccountID: 2, Amount: decimal.NewFromInt(50)}, wantKey: "transfer_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateAccountTransfer(v, &tt.transfer)
+			if tt.wantKey == "" && !v.Valid() {
+				t.Errorf("ValidateAccountTransfer() errors = %v, want none", v.Errors)
+			}
+			if _, ok := v.Errors[tt.wantKey]; tt.wantKey != "" && !ok {
+				t.Errorf("ValidateAccountTransfer() errors = %v, want an error for %s", v.Errors, tt.wantKey)
+			}
+		})
+	}
+}
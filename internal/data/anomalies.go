@@ -0,0 +1,384 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type AnomalyManagerModel struct {
+	DB *database.Queries
+}
+
+// The kinds of spending anomalies
+const (
+	AnomalyKindUnusualTransaction = database.AnomalyKindEnumUnusualTransaction
+	AnomalyKindDuplicateCharge    = database.AnomalyKindEnumDuplicateCharge
+	AnomalyKindCategorySpike      = database.AnomalyKindEnumCategorySpike
+)
+
+var (
+	DefaultAnomalyDBContextTimeout = 5 * time.Second
+	// AnomalyHistoryDays is the rolling window an expense is compared against
+	AnomalyHistoryDays = 90
+	// AnomalyEvaluationDays is how far back expenses are checked, so expenses recorded late are still caught
+	AnomalyEvaluationDays = 7
+	// AnomalyMinSamples is the least number of past amounts needed before anything is flagged
+	AnomalyMinSamples = 5
+	// AnomalyZScoreThreshold is how many standard deviations above the mean an amount has to be
+	AnomalyZScoreThreshold = decimal.NewFromInt(3)
+	// AnomalyMinRatio keeps small deviations in very steady spending from being flagged,
+	// an amount has to be at least 1.5 times the mean as well
+	AnomalyMinRatio = decimal.RequireFromString("1.5")
+	// maxAnomalyScore caps the stored score, the score of a change in steady spending is infinite
+	maxAnomalyScore = decimal.NewFromInt(99999999)
+	// DuplicateChargeWindowDays is how many days apart two identical charges are considered duplicates
+	DuplicateChargeWindowDays = 2
+	// CategorySpikeWeeks is the number of past weeks the current week of a category is compared to
+	CategorySpikeWeeks = 12
+	// DefaultAnomalyListDays is how far back the anomaly list goes by default
+	DefaultAnomalyListDays = 30
+)
+
+// AnomalyExpense is an expense as seen by the anomaly detector
+type AnomalyExpense struct {
+	ID           int64
+	Name         string
+	Category     string
+	Amount       decimal.Decimal
+	IsRecurring  bool
+	DateOccurred time.Time
+	CurrencyCode string
+}
+
+// SpendingAnomaly is an unusual transaction, a duplicate looking charge or a sudden rise in the
+// spending of a category. ExpectedAmount is what the spending was compared to: the usual amount,
+// the charge it duplicates or the usual weekly total. ExpenseID is 0 for category spikes
+type SpendingAnomaly struct {
+	ID             int64                    `json:"id"`
+	UserID         int64                    `json:"user_id"`
+	ExpenseID      int64                    `json:"expense_id,omitempty"`
+	Kind           database.AnomalyKindEnum `json:"kind"`
+	Category       string                   `json:"category"`
+	Name           string                   `json:"name,omitempty"`
+	CurrencyCode   string                   `json:"currency_code"`
+	Amount         decimal.Decimal          `json:"amount"`
+	ExpectedAmount decimal.Decimal          `json:"expected_amount"`
+	Score          decimal.Decimal          `json:"score"`
+	OccurredOn     time.Time                `json:"occurred_on"`
+	Message        string                   `json:"message"`
+	DedupeKey      string                   `json:"-"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
+
+// ValidateAnomalyFilters() validates the filters of the anomaly list
+func ValidateAnomalyFilters(v *validator.Validator, kind string, days int) {
+	v.Check(validator.PermittedValue(kind, "", string(AnomalyKindUnusualTransaction), string(AnomalyKindDuplicateCharge), string(AnomalyKindCategorySpike)),
+		"kind", "must be either unusual_transaction, duplicate_charge or category_spike")
+	v.Check(days >= 1 && days <= 366, "days", "must be between 1 and 366")
+}
+
+// DetectSpendingAnomalies() looks for anomalies in the expenses of the last AnomalyEvaluationDays.
+// Expenses must include the AnomalyHistoryDays before that, amounts are only ever compared within
+// the same currency
+func DetectSpendingAnomalies(expenses []*AnomalyExpense, now time.Time) []*SpendingAnomaly {
+	sort.SliceStable(expenses, func(i, j int) bool {
+		return expenses[i].DateOccurred.Before(expenses[j].DateOccurred)
+	})
+	evaluationStart := dateOnly(now).AddDate(0, 0, -(AnomalyEvaluationDays - 1))
+	anomalies := []*SpendingAnomaly{}
+	anomalies = append(anomalies, detectUnusualTransactions(expenses, evaluationStart)...)
+	anomalies = append(anomalies, detectDuplicateCharges(expenses, evaluationStart)...)
+	anomalies = append(anomalies, detectCategorySpikes(expenses, now)...)
+	return anomalies
+}
+
+// detectUnusualTransactions() flags expenses that are far above what the user usually spends at the
+// same merchant, or in the same category when there is too little history for the merchant
+func detectUnusualTransactions(expenses []*AnomalyExpense, evaluationStart time.Time) []*SpendingAnomaly {
+	anomalies := []*SpendingAnomaly{}
+	for _, expense := range expenses {
+		if expense.DateOccurred.Before(evaluationStart) || expense.IsRecurring {
+			continue
+		}
+		windowStart := dateOnly(expense.DateOccurred).AddDate(0, 0, -AnomalyHistoryDays)
+		var merchantSamples, categorySamples []decimal.Decimal
+		merchantKey := SubscriptionMerchantKey(expense.Name)
+		for _, past := range expenses {
+			if past.ID == expense.ID || past.CurrencyCode != expense.CurrencyCode || past.DateOccurred.Before(windowStart) || past.DateOccurred.After(expense.DateOccurred) {
+				continue
+			}
+			if past.Category == expense.Category {
+				categorySamples = append(categorySamples, past.Amount)
+			}
+			if merchantKey != "" && SubscriptionMerchantKey(past.Name) == merchantKey {
+				merchantSamples = append(merchantSamples, past.Amount)
+			}
+		}
+		samples, comparedTo := merchantSamples, expense.Name
+		if len(samples) < AnomalyMinSamples {
+			samples, comparedTo = categorySamples, expense.Category
+		}
+		mean, score, ok := anomalyOutlier(expense.Amount, samples)
+		if !ok {
+			continue
+		}
+		expectedAmount := mean.Round(2)
+		anomalies = append(anomalies, &SpendingAnomaly{
+			ExpenseID:      expense.ID,
+			Kind:           AnomalyKindUnusualTransaction,
+			Category:       expense.Category,
+			Name:           expense.Name,
+			CurrencyCode:   expense.CurrencyCode,
+			Amount:         expense.Amount,
+			ExpectedAmount: expectedAmount,
+			Score:          anomalyScore(score),
+			OccurredOn:     dateOnly(expense.DateOccurred),
+			Message: fmt.Sprintf("Unusual expense: %s of %s %s is well above the usual %s for %s",
+				expense.Name, expense.Amount.StringFixed(2), expense.CurrencyCode, expectedAmount.StringFixed(2), comparedTo),
+			DedupeKey: fmt.Sprintf("%s:%d", AnomalyKindUnusualTransaction, expense.ID),
+		})
+	}
+	return anomalies
+}
+
+// detectDuplicateCharges() flags charges with the same merchant and amount as another charge a few days
+// earlier. Expenses posted by recurring expenses are expected to repeat and are left out
+func detectDuplicateCharges(expenses []*AnomalyExpense, evaluationStart time.Time) []*SpendingAnomaly {
+	anomalies := []*SpendingAnomaly{}
+	for i, expense := range expenses {
+		if expense.DateOccurred.Before(evaluationStart) || expense.IsRecurring {
+			continue
+		}
+		merchantKey := SubscriptionMerchantKey(expense.Name)
+		for _, earlier := range expenses[:i] {
+			if earlier.IsRecurring || earlier.CurrencyCode != expense.CurrencyCode || !earlier.Amount.Equal(expense.Amount) ||
+				SubscriptionMerchantKey(earlier.Name) != merchantKey || daysBetween(earlier.DateOccurred, expense.DateOccurred) > DuplicateChargeWindowDays {
+				continue
+			}
+			anomalies = append(anomalies, &SpendingAnomaly{
+				ExpenseID:      expense.ID,
+				Kind:           AnomalyKindDuplicateCharge,
+				Category:       expense.Category,
+				Name:           expense.Name,
+				CurrencyCode:   expense.CurrencyCode,
+				Amount:         expense.Amount,
+				ExpectedAmount: earlier.Amount,
+				Score:          decimal.Zero,
+				OccurredOn:     dateOnly(expense.DateOccurred),
+				Message: fmt.Sprintf("Possible duplicate charge: %s of %s %s on %s matches a charge on %s",
+					expense.Name, expense.Amount.StringFixed(2), expense.CurrencyCode, expense.DateOccurred.Format(time.DateOnly), earlier.DateOccurred.Format(time.DateOnly)),
+				DedupeKey: fmt.Sprintf("%s:%d", AnomalyKindDuplicateCharge, expense.ID),
+			})
+			break
+		}
+	}
+	return anomalies
+}
+
+// detectCategorySpikes() compares the spending of each category over the last 7 days to the
+// same category's spending in each of the CategorySpikeWeeks before. A category is only alerted
+// about once per calendar week
+func detectCategorySpikes(expenses []*AnomalyExpense, now time.Time) []*SpendingAnomaly {
+	type categoryKey struct {
+		currencyCode string
+		category     string
+	}
+	periodEnd := dateOnly(now)
+	periodStart := periodEnd.AddDate(0, 0, -6)
+	totals := make(map[categoryKey][]decimal.Decimal)
+	keys := []categoryKey{}
+	for _, expense := range expenses {
+		occurred := dateOnly(expense.DateOccurred)
+		if occurred.After(periodEnd) {
+			continue
+		}
+		// week 0 is the current period, week 1 the 7 days before it and so on
+		week := daysBetween(occurred, periodEnd) / 7
+		if week > CategorySpikeWeeks {
+			continue
+		}
+		key := categoryKey{expense.CurrencyCode, expense.Category}
+		if _, ok := totals[key]; !ok {
+			totals[key] = make([]decimal.Decimal, CategorySpikeWeeks+1)
+			keys = append(keys, key)
+		}
+		totals[key][week] = totals[key][week].Add(expense.Amount)
+	}
+	year, week := periodEnd.ISOWeek()
+	anomalies := []*SpendingAnomaly{}
+	for _, key := range keys {
+		weeks := totals[key]
+		// only weeks with spending count, a category that was only just started has no usual amount
+		var samples []decimal.Decimal
+		for _, total := range weeks[1:] {
+			if total.IsPositive() {
+				samples = append(samples, total)
+			}
+		}
+		mean, score, ok := anomalyOutlier(weeks[0], samples)
+		if !ok {
+			continue
+		}
+		amount := weeks[0].Round(2)
+		expectedAmount := mean.Round(2)
+		anomalies = append(anomalies, &SpendingAnomaly{
+			Kind:           AnomalyKindCategorySpike,
+			Category:       key.category,
+			CurrencyCode:   key.currencyCode,
+			Amount:         amount,
+			ExpectedAmount: expectedAmount,
+			Score:          anomalyScore(score),
+			OccurredOn:     periodStart,
+			Message: fmt.Sprintf("Spending spike: %s %s spent on %s over the last 7 days, compared to a usual %s a week",
+				amount.StringFixed(2), key.currencyCode, key.category, expectedAmount.StringFixed(2)),
+			DedupeKey: fmt.Sprintf("%s:%s:%s:%d-W%02d", AnomalyKindCategorySpike, key.currencyCode, key.category, year, week),
+		})
+	}
+	return anomalies
+}
+
+// anomalyOutlier() checks whether a value is an outlier among the samples using its z-score.
+// It returns the mean of the samples and the z-score. When the samples do not vary at all,
+// the value only has to be AnomalyMinRatio times the mean and the score is maxAnomalyScore
+func anomalyOutlier(value decimal.Decimal, samples []decimal.Decimal) (decimal.Decimal, decimal.Decimal, bool) {
+	if len(samples) < AnomalyMinSamples {
+		return decimal.Zero, decimal.Zero, false
+	}
+	count := decimal.NewFromInt(int64(len(samples)))
+	mean := decimal.Sum(samples[0], samples[1:]...).Div(count)
+	variance := decimal.Zero
+	for _, sample := range samples {
+		deviation := sample.Sub(mean)
+		variance = variance.Add(deviation.Mul(deviation))
+	}
+	variance = variance.Div(count)
+	if !mean.IsPositive() || value.LessThan(mean.Mul(AnomalyMinRatio)) {
+		return mean, decimal.Zero, false
+	}
+	if variance.IsZero() {
+		return mean, maxAnomalyScore, true
+	}
+	// the variance is positive here, so its square root always exists
+	stdDev, _ := variance.PowWithPrecision(decimal.RequireFromString("0.5"), 16)
+	score := value.Sub(mean).Div(stdDev)
+	return mean, score, score.GreaterThanOrEqual(AnomalyZScoreThreshold)
+}
+
+// anomalyScore() rounds a z-score for storage, capping it at maxAnomalyScore
+func anomalyScore(score decimal.Decimal) decimal.Decimal {
+	return decimal.Min(score, maxAnomalyScore).Round(2)
+}
+
+// GetAnomalyUserIDsSince() returns the users that recorded expenses since a time, including the ones
+// posted by recurring expenses. They are the only ones whose spending may have become unusual
+func (m AnomalyManagerModel) GetAnomalyUserIDsSince(since time.Time) ([]int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAnomalyDBContextTimeout)
+	defer cancel()
+	return m.DB.GetAnomalyUserIDsSince(ctx, since)
+}
+
+// GetAnomalyExpensesByUserID() returns the user's expenses since a date with their budget's currency
+func (m AnomalyManagerModel) GetAnomalyExpensesByUserID(userID int64, since time.Time) ([]*AnomalyExpense, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAnomalyDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetAnomalyExpensesByUserID(ctx, database.GetAnomalyExpensesByUserIDParams{
+		UserID:  userID,
+		Column2: since,
+	})
+	if err != nil {
+		return nil, err
+	}
+	expenses := make([]*AnomalyExpense, len(rows))
+	for i, row := range rows {
+		expenses[i] = &AnomalyExpense{
+			ID:           row.ID,
+			Name:         row.Name,
+			Category:     row.Category,
+			Amount:       decimal.RequireFromString(row.Amount),
+			IsRecurring:  row.IsRecurring,
+			DateOccurred: row.DateOccurred,
+			CurrencyCode: row.CurrencyCode,
+		}
+	}
+	return expenses, nil
+}
+
+// CreateSpendingAnomaly() saves an anomaly. It returns false without an error when the anomaly
+// was already saved by an earlier scan
+func (m AnomalyManagerModel) CreateSpendingAnomaly(userID int64, anomaly *SpendingAnomaly) (bool, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAnomalyDBContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateSpendingAnomaly(ctx, database.CreateSpendingAnomalyParams{
+		UserID:         userID,
+		ExpenseID:      sql.NullInt64{Int64: anomaly.ExpenseID, Valid: anomaly.ExpenseID != 0},
+		Kind:           anomaly.Kind,
+		Category:       anomaly.Category,
+		Name:           anomaly.Name,
+		CurrencyCode:   anomaly.CurrencyCode,
+		Amount:         anomaly.Amount.String(),
+		ExpectedAmount: anomaly.ExpectedAmount.String(),
+		Score:          anomaly.Score.String(),
+		OccurredOn:     anomaly.OccurredOn,
+		Message:        anomaly.Message,
+		DedupeKey:      anomaly.DedupeKey,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+	anomaly.ID = row.ID
+	anomaly.UserID = userID
+	anomaly.CreatedAt = row.CreatedAt.Time
+	return true, nil
+}
+
+// GetSpendingAnomaliesByUserID() returns the anomalies found since a time, newest first,
+// optionally of a single kind
+func (m AnomalyManagerModel) GetSpendingAnomaliesByUserID(userID int64, since time.Time, kind string) ([]*SpendingAnomaly, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAnomalyDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetSpendingAnomaliesByUserID(ctx, database.GetSpendingAnomaliesByUserIDParams{
+		UserID:  userID,
+		Column2: since,
+		Column3: kind,
+	})
+	if err != nil {
+		return nil, err
+	}
+	anomalies := make([]*SpendingAnomaly, len(rows))
+	for i, row := range rows {
+		anomalies[i] = &SpendingAnomaly{
+			ID:             row.ID,
+			UserID:         row.UserID,
+			ExpenseID:      row.ExpenseID.Int64,
+			Kind:           row.Kind,
+			Category:       row.Category,
+			Name:           row.Name,
+			CurrencyCode:   row.CurrencyCode,
+			Amount:         decimal.RequireFromString(row.Amount),
+			ExpectedAmount: decimal.RequireFromString(row.ExpectedAmount),
+			Score:          decimal.RequireFromString(row.Score),
+			OccurredOn:     row.OccurredOn,
+			Message:        row.Message,
+			DedupeKey:      row.DedupeKey,
+			CreatedAt:      row.CreatedAt.Time,
+		}
+	}
+	return anomalies, nil
+}
@@ -0,0 +1,147 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/shopspring/decimal"
+)
+
+func TestAnomalyOutlier(t *testing.T) {
+	amounts := func(values ...int64) []decimal.Decimal {
+		samples := make([]decimal.Decimal, len(values))
+		for i, value := range values {
+			samples[i] = decimal.NewFromInt(value)
+		}
+		return samples
+	}
+	tests := []struct {
+		name      string
+		value     int64
+		samples   []decimal.Decimal
+		wantMean  string
+		wantScore string
+		wantOK    bool
+	}{
+		{name: "Too few samples", value: 1000, samples: amounts(10, 10, 10), wantMean: "0", wantScore: "0", wantOK: false},
+		// the standard deviation of the samples is the square root of 2
+		{name: "Far above varied spending", value: 100, samples: amounts(10, 12, 8, 11, 9), wantMean: "10", wantScore: "63.64", wantOK: true},
+		{name: "Within the usual range", value: 13, samples: amounts(10, 12, 8, 11, 9), wantMean: "10", wantScore: "0", wantOK: false},
+		{name: "Steady spending needs the minimum ratio", value: 14, samples: amounts(10, 10, 10, 10, 10), wantMean: "10", wantScore: "0", wantOK: false},
+		{name: "Steady spending above the minimum ratio", value: 15, samples: amounts(10, 10, 10, 10, 10), wantMean: "10", wantScore: "99999999", wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, score, ok := anomalyOutlier(decimal.NewFromInt(tt.value), tt.samples)
+			if ok != tt.wantOK {
+				t.Errorf("anomalyOutlier() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !mean.Equal(decimal.RequireFromString(tt.wantMean)) {
+				t.Errorf("anomalyOutlier() mean = %v, want %v", mean, tt.wantMean)
+			}
+			if got := anomalyScore(score); !got.Equal(decimal.RequireFromString(tt.wantScore)) {
+				t.Errorf("anomalyScore() = %v, want %v", got, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestDetectSpendingAnomalies(t *testing.T) {
+	now := time.Date(2025, time.June, 20, 12, 0, 0, 0, time.UTC)
+	var nextID int64
+	expense := func(name, category, currency string, daysAgo int, amount string, recurring bool) *AnomalyExpense {
+		nextID++
+		return &AnomalyExpense{
+			ID:           nextID,
+			Name:         name,
+			Category:     category,
+			Amount:       decimal.RequireFromString(amount),
+			IsRecurring:  recurring,
+			DateOccurred: dateOnly(now).AddDate(0, 0, -daysAgo),
+			CurrencyCode: currency,
+		}
+	}
+	// weekly groceries of around 50 over the past 12 weeks
+	groceries := func() []*AnomalyExpense {
+		expenses := []*AnomalyExpense{}
+		for week := 1; week <= 12; week++ {
+			amount := "50"
+			if week%2 == 0 {
+				amount = "54"
+			}
+			expenses = append(expenses, expense("Market", "groceries", "USD", week*7+1, amount, false))
+		}
+		return expenses
+	}
+	tests := []struct {
+		name      string
+		expenses  []*AnomalyExpense
+		wantKinds map[database.AnomalyKindEnum]int
+	}{
+		{
+			name:      "Usual spending",
+			expenses:  append(groceries(), expense("Market", "groceries", "USD", 1, "52", false)),
+			wantKinds: map[database.AnomalyKindEnum]int{},
+		},
+		{
+			name:     "Unusual transaction also spikes the category",
+			expenses: append(groceries(), expense("Market", "groceries", "USD", 1, "400", false)),
+			wantKinds: map[database.AnomalyKindEnum]int{
+				AnomalyKindUnusualTransaction: 1,
+				AnomalyKindCategorySpike:      1,
+			},
+		},
+		{
+			name:      "Other currencies are not compared",
+			expenses:  append(groceries(), expense("Market", "groceries", "EUR", 1, "400", false)),
+			wantKinds: map[database.AnomalyKindEnum]int{},
+		},
+		{
+			name: "Duplicate charge within the window",
+			expenses: append(groceries(),
+				expense("Electronics Store", "shopping", "USD", 2, "30", false),
+				expense("ELECTRONICS STORE", "shopping", "USD", 1, "30", false),
+			),
+			wantKinds: map[database.AnomalyKindEnum]int{AnomalyKindDuplicateCharge: 1},
+		},
+		{
+			name: "Identical charges further apart",
+			expenses: append(groceries(),
+				expense("Electronics Store", "shopping", "USD", 5, "30", false),
+				expense("Electronics Store", "shopping", "USD", 1, "30", false),
+			),
+			wantKinds: map[database.AnomalyKindEnum]int{},
+		},
+		{
+			name: "Recurring expenses are not duplicates",
+			expenses: append(groceries(),
+				expense("Parking", "transport", "USD", 2, "5", true),
+				expense("Parking", "transport", "USD", 1, "5", true),
+			),
+			wantKinds: map[database.AnomalyKindEnum]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := DetectSpendingAnomalies(tt.expenses, now)
+			gotKinds := make(map[database.AnomalyKindEnum]int)
+			for _, anomaly := range anomalies {
+				gotKinds[anomaly.Kind]++
+				if anomaly.DedupeKey == "" || anomaly.Message == "" {
+					t.Errorf("DetectSpendingAnomalies() anomaly %s is missing its dedupe key or message", anomaly.Kind)
+				}
+			}
+			if len(gotKinds) != len(tt.wantKinds) {
+				t.Fatalf("DetectSpendingAnomalies() kinds = %v, want %v", gotKinds, tt.wantKinds)
+			}
+			for kind, want := range tt.wantKinds {
+				if gotKinds[kind] != want {
+					t.Errorf("DetectSpendingAnomalies() %s = %d, want %d", kind, gotKinds[kind], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+type AttachmentManagerModel struct {
+	DB *database.Queries
+}
+
+const (
+	ExpenseAttachmentStoragePrefix      = "receipts"
+	GroupExpenseAttachmentStoragePrefix = "group_receipts"
+)
+
+var (
+	DefaultAttachmentDBContextTimeout = 5 * time.Second
+	DefaultAttachmentMaxUploadSize    = int64(10 << 20) // 10MB
+	DefaultAttachmentSignedURLExpiry  = 15 * time.Minute
+)
+
+// AllowedAttachmentContentTypes maps the content types we accept for receipts to the
+// extension used when storing them. Content types are sniffed from the file itself
+// so the client supplied Content-Type header is never trusted.
+var AllowedAttachmentContentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// ExpenseAttachment represents a file such as a receipt attached to either a
+// personal expense or a group expense
+type ExpenseAttachment struct {
+	ID             int64     `json:"id"`
+	UserID         int64     `json:"user_id"`
+	ExpenseID      int64     `json:"expense_id,omitempty"`
+	GroupExpenseID int64     `json:"group_expense_id,omitempty"`
+	StorageKey     string    `json:"-"`
+	FileName       string    `json:"file_name"`
+	ContentType    string    `json:"content_type"`
+	SizeBytes      int64     `json:"size_bytes"`
+	DownloadURL    string    `json:"download_url,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ValidateAttachmentFile() validates the name, type and size of an uploaded file
+func ValidateAttachmentFile(v *validator.Validator, attachment *ExpenseAttachment, maxSize int64) {
+	v.Check(attachment.FileName != "", "file", "must be provided")
+	v.Check(len(attachment.FileName) <= 255, "file", "file name must not be more than 255 bytes long")
+	_, ok := AllowedAttachmentContentTypes[attachment.ContentType]
+	v.Check(ok, "file", "must be a JPEG, PNG, WEBP image or a PDF document")
+	v.Check(attachment.SizeBytes > 0, "file", "must not be empty")
+	v.Check(attachment.SizeBytes <= maxSize, "file", "must not be larger than the maximum upload size")
+}
+
+// ValidateExpenseAttachment() validates an uploaded attachment against the maximum upload size
+func ValidateExpenseAttachment(v *validator.Validator, attachment *ExpenseAttachment, maxSize int64) {
+	ValidateAttachmentFile(v, attachment, maxSize)
+	// an attachment belongs to exactly one expense
+	v.Check((attachment.ExpenseID > 0) != (attachment.GroupExpenseID > 0), "expense_id", "attachment must belong to a single expense")
+}
+
+// CreateNewExpenseAttachment() saves the metadata of an uploaded attachment.
+// The file itself should already be in storage under attachment.StorageKey
+func (m AttachmentManagerModel) CreateNewExpenseAttachment(userID int64, attachment *ExpenseAttachment) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAttachmentDBContextTimeout)
+	defer cancel()
+	// save the attachment
+	createdAttachment, err := m.DB.CreateNewExpenseAttachment(ctx, database.CreateNewExpenseAttachmentParams{
+		UserID:         userID,
+		ExpenseID:      sql.NullInt64{Int64: attachment.ExpenseID, Valid: attachment.ExpenseID > 0},
+		GroupExpenseID: sql.NullInt64{Int64: attachment.GroupExpenseID, Valid: attachment.GroupExpenseID > 0},
+		StorageKey:     attachment.StorageKey,
+		FileName:       attachment.FileName,
+		ContentType:    attachment.ContentType,
+		SizeBytes:      attachment.SizeBytes,
+	})
+	if err != nil {
+		return err
+	}
+	// fill in the generated values
+	attachment.ID = createdAttachment.ID
+	attachment.UserID = userID
+	attachment.CreatedAt = createdAttachment.CreatedAt.Time
+	return nil
+}
+
+// GetExpenseAttachmentsByExpenseID() returns all the attachments of a user's expense
+func (m AttachmentManagerModel) GetExpenseAttachmentsByExpenseID(userID, expenseID int64) ([]*ExpenseAttachment, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAttachmentDBContextTimeout)
+	defer cancel()
+	// get the attachments
+	attachments, err := m.DB.GetExpenseAttachmentsByExpenseID(ctx, database.GetExpenseAttachmentsByExpenseIDParams{
+		ExpenseID: sql.NullInt64{Int64: expenseID, Valid: true},
+		UserID:    userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	populatedAttachments := []*ExpenseAttachment{}
+	for _, attachment := range attachments {
+		populatedAttachments = append(populatedAttachments, populateExpenseAttachment(attachment))
+	}
+	return populatedAttachments, nil
+}
+
+// GetExpenseAttachmentsByGroupExpenseID() returns all the attachments of a group expense.
+// The caller is responsible for verifying that the user is a member of the group
+func (m AttachmentManagerModel) GetExpenseAttachmentsByGroupExpenseID(groupExpenseID int64) ([]*ExpenseAttachment, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAttachmentDBContextTimeout)
+	defer cancel()
+	// get the attachments
+	attachments, err := m.DB.GetExpenseAttachmentsByGroupExpenseID(ctx, sql.NullInt64{Int64: groupExpenseID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	populatedAttachments := []*ExpenseAttachment{}
+	for _, attachment := range attachments {
+		populatedAttachments = append(populatedAttachments, populateExpenseAttachment(attachment))
+	}
+	return populatedAttachments, nil
+}
+
+// GetExpenseAttachmentsByGroupID() returns the attachments of every expense in a group
+func (m AttachmentManagerModel) GetExpenseAttachmentsByGroupID(groupID int64) ([]*ExpenseAttachment, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAttachmentDBContextTimeout)
+	defer cancel()
+	// get the attachments
+	attachments, err := m.DB.GetExpenseAttachmentsByGroupID(ctx, sql.NullInt64{Int64: groupID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	populatedAttachments := []*ExpenseAttachment{}
+	for _, attachment := range attachments {
+		populatedAttachments = append(populatedAttachments, populateExpenseAttachment(attachment))
+	}
+	return populatedAttachments, nil
+}
+
+// GetExpenseAttachmentByID() gets an attachment uploaded by the user
+func (m AttachmentManagerModel) GetExpenseAttachmentByID(userID, attachmentID int64) (*ExpenseAttachment, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAttachmentDBContextTimeout)
+	defer cancel()
+	// get the attachment
+	attachment, err := m.DB.GetExpenseAttachmentByID(ctx, database.GetExpenseAttachmentByIDParams{
+		ID:     attachmentID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return populateExpenseAttachment(attachment), nil
+}
+
+// DeleteExpenseAttachmentByID() deletes an attachment uploaded by the user.
+// We return the storage key so the caller can remove the file from storage
+func (m AttachmentManagerModel) DeleteExpenseAttachmentByID(userID, attachmentID int64) (string, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultAttachmentDBContextTimeout)
+	defer cancel()
+	// delete the attachment
+	storageKey, err := m.DB.DeleteExpenseAttachmentByID(ctx, database.DeleteExpenseAttachmentByIDParams{
+		ID:     attachmentID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrGeneralRecordNotFound
+		default:
+			return "", err
+		}
+	}
+	return storageKey, nil
+}
+
+// populateExpenseAttachment() maps a database attachment row to an ExpenseAttachment
+func populateExpenseAttachment(attachmentRow interface{}) *ExpenseAttachment {
+	switch attachment := attachmentRow.(type) {
+	case database.ExpenseAttachment:
+		return &ExpenseAttachment{
+			ID:             attachment.ID,
+			UserID:         attachment.UserID,
+			ExpenseID:      attachment.ExpenseID.Int64,
+			GroupExpenseID: attachment.GroupExpenseID.Int64,
+			StorageKey:     attachment.StorageKey,
+			FileName:       attachment.FileName,
+			ContentType:    attachment.ContentType,
+			SizeBytes:      attachment.SizeBytes,
+			CreatedAt:      attachment.CreatedAt.Time,
+		}
+	default:
+		return nil
+	}
+}
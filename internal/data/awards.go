@@ -42,6 +42,21 @@ func (m AwardManagerModel) CreateNewUserAward(userID int64, awardID int32) (time
 	return createdAt, nil
 }
 
+// CreateNewUserAwardByCode() grants a user the award with the given code. Awards are only
+// granted once, so it reports whether the user got the award now rather than already holding it
+func (m AwardManagerModel) CreateNewUserAwardByCode(userID int64, code string) (bool, error) {
+	ctx, cancel := contextGenerator(context.Background(), DefaultAwManDBContextTimeout)
+	defer cancel()
+	granted, err := m.DB.CreateNewUserAwardByCode(ctx, database.CreateNewUserAwardByCodeParams{
+		UserID: userID,
+		Code:   code,
+	})
+	if err != nil {
+		return false, err
+	}
+	return granted > 0, nil
+}
+
 // GetAwardByAwardID() is a method that returns an award by ID
 // We accept an award ID
 // We return an award and an error if there is one
@@ -0,0 +1,143 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+// BatchManagerModel runs the batch endpoints. Unlike the other models it also holds the
+// connection pool, as a batch is saved in a single database transaction
+type BatchManagerModel struct {
+	DB   *database.Queries
+	Conn *sql.DB
+}
+
+// The modes a batch can run in. In all or nothing mode a single invalid or failing item
+// stops the whole batch, in best effort mode the remaining items are still saved
+const (
+	BatchModeAllOrNothing = "all_or_nothing"
+	BatchModeBestEffort   = "best_effort"
+)
+
+var (
+	DefaultBatchDBContextTimeout = 60 * time.Second
+	MaxBatchItems                = 100
+)
+
+var (
+	ErrBatchRolledBack = errors.New("batch rolled back")
+)
+
+// BatchQueries are the models the operations of a batch use, bound to the batch's transaction
+type BatchQueries struct {
+	FinancialTrackingManager   FinancialTrackingModel
+	InvestmentPortfolioManager InvestmentPortfolioModel
+}
+
+// BatchOperation is the database work for a single item of a batch
+type BatchOperation func(q *BatchQueries) error
+
+// BatchItemResult is the outcome of a single item of a batch. Record is the saved record,
+// or the ID of a deleted record
+type BatchItemResult struct {
+	Index     int  `json:"index"`
+	Succeeded bool `json:"succeeded"`
+	Record    any  `json:"record,omitempty"`
+}
+
+// ValidateBatch() validates the mode and size of a batch
+func ValidateBatch(v *validator.Validator, mode string, items int) {
+	v.Check(validator.PermittedValue(mode, BatchModeAllOrNothing, BatchModeBestEffort), "mode", "must be either all_or_nothing or best_effort")
+	v.Check(items > 0, "items", "must contain at least one item")
+	v.Check(items <= MaxBatchItems, "items", fmt.Sprintf("must not contain more than %d items", MaxBatchItems))
+}
+
+// BatchItemKey() returns the validator key of a field of a batch item i.e items[3].amount.
+// An empty field returns the key of the item itself
+func BatchItemKey(index int, field string) string {
+	if field == "" {
+		return fmt.Sprintf("items[%d]", index)
+	}
+	return fmt.Sprintf("items[%d].%s", index, field)
+}
+
+// AddBatchItemErrors() copies the errors of a single item's validator into the batch's validator
+// under the position of the item
+func AddBatchItemErrors(v *validator.Validator, index int, itemErrors map[string]string) {
+	for field, message := range itemErrors {
+		v.AddError(BatchItemKey(index, field), message)
+	}
+}
+
+// NewBatchResults() lines up the outcome of each operation with its record. Items without an
+// operation failed validation and did not run
+func NewBatchResults(operations []BatchOperation, errs []error, records []any) []*BatchItemResult {
+	results := make([]*BatchItemResult, len(operations))
+	for i := range operations {
+		result := &BatchItemResult{Index: i}
+		if operations[i] != nil && errs[i] == nil {
+			result.Succeeded = true
+			result.Record = records[i]
+		}
+		results[i] = result
+	}
+	return results
+}
+
+// RunBatch() runs the operations of a batch in one transaction, skipping the nil operations of
+// items that failed validation. In all or nothing mode the first failing operation rolls the whole
+// batch back and ErrBatchRolledBack is returned. In best effort mode every operation runs in its own
+// savepoint so that a failure only undoes that item. The returned errors line up with the operations
+func (m BatchManagerModel) RunBatch(mode string, operations []BatchOperation) ([]error, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultBatchDBContextTimeout)
+	defer cancel()
+	tx, err := m.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	// rolling back after a commit does nothing
+	defer tx.Rollback()
+	queries := m.DB.WithTx(tx)
+	batchQueries := &BatchQueries{
+		FinancialTrackingManager:   FinancialTrackingModel{DB: queries},
+		InvestmentPortfolioManager: InvestmentPortfolioModel{DB: queries},
+	}
+	errs := make([]error, len(operations))
+	for i, operation := range operations {
+		if operation == nil {
+			continue
+		}
+		if mode == BatchModeAllOrNothing {
+			errs[i] = operation(batchQueries)
+			if errs[i] != nil {
+				return errs, ErrBatchRolledBack
+			}
+			continue
+		}
+		_, err = tx.ExecContext(ctx, "SAVEPOINT batch_item")
+		if err != nil {
+			return nil, err
+		}
+		errs[i] = operation(batchQueries)
+		if errs[i] != nil {
+			_, err = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_item")
+		} else {
+			_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_item")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
@@ -0,0 +1,114 @@
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+func TestValidateBatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		items     int
+		wantError string
+	}{
+		{name: "All or nothing", mode: BatchModeAllOrNothing, items: 3},
+		{name: "Best effort at the limit", mode: BatchModeBestEffort, items: MaxBatchItems},
+		{name: "Unknown mode", mode: "some", items: 3, wantError: "mode"},
+		{name: "Empty batch", mode: BatchModeBestEffort, items: 0, wantError: "items"},
+		{name: "Too many items", mode: BatchModeAllOrNothing, items: MaxBatchItems + 1, wantError: "items"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateBatch(v, tt.mode, tt.items)
+			if tt.wantError == "" && !v.Valid() {
+				t.Errorf("ValidateBatch() errors = %v, want none", v.Errors)
+			}
+			if _, ok := v.Errors[tt.wantError]; tt.wantError != "" && !ok {
+				t.Errorf("ValidateBatch() errors = %v, want an error for %q", v.Errors, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestAddBatchItemErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		index      int
+		itemErrors map[string]string
+		want       map[string]string
+	}{
+		{
+			name:       "Fields are keyed by position",
+			index:      2,
+			itemErrors: map[string]string{"amount": "must be greater than zero", "name": "must be provided"},
+			want:       map[string]string{"items[2].amount": "must be greater than zero", "items[2].name": "must be provided"},
+		},
+		{
+			name:       "Item errors",
+			index:      0,
+			itemErrors: map[string]string{"": "could not be saved"},
+			want:       map[string]string{"items[0]": "could not be saved"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			AddBatchItemErrors(v, tt.index, tt.itemErrors)
+			if len(v.Errors) != len(tt.want) {
+				t.Fatalf("AddBatchItemErrors() errors = %v, want %v", v.Errors, tt.want)
+			}
+			for key, message := range tt.want {
+				if v.Errors[key] != message {
+					t.Errorf("AddBatchItemErrors() %s = %q, want %q", key, v.Errors[key], message)
+				}
+			}
+		})
+	}
+}
+
+func TestNewBatchResults(t *testing.T) {
+	operation := func(q *BatchQueries) error { return nil }
+	tests := []struct {
+		name          string
+		operations    []BatchOperation
+		errs          []error
+		wantSucceeded []bool
+	}{
+		{
+			name:          "All saved",
+			operations:    []BatchOperation{operation, operation},
+			errs:          []error{nil, nil},
+			wantSucceeded: []bool{true, true},
+		},
+		{
+			name:          "Invalid and failing items",
+			operations:    []BatchOperation{nil, operation, operation},
+			errs:          []error{nil, errors.New("failed"), nil},
+			wantSucceeded: []bool{false, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := make([]any, len(tt.operations))
+			for i := range records {
+				records[i] = int64(i + 1)
+			}
+			results := NewBatchResults(tt.operations, tt.errs, records)
+			for i, result := range results {
+				if result.Index != i || result.Succeeded != tt.wantSucceeded[i] {
+					t.Errorf("NewBatchResults()[%d] = %+v, want succeeded %v", i, result, tt.wantSucceeded[i])
+				}
+				if !result.Succeeded && result.Record != nil {
+					t.Errorf("NewBatchResults()[%d] record = %v, want none for a failed item", i, result.Record)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type CalendarManagerModel struct {
+	DB *database.Queries
+}
+
+// The kinds of events on the cash-flow calendar
+const (
+	CashFlowEventRecurringExpense = "recurring_expense"
+	CashFlowEventRecurringIncome  = "recurring_income"
+	CashFlowEventDebtPayment      = "debt_payment"
+	CashFlowEventGoalDeadline     = "goal_deadline"
+	CashFlowEventBondMaturity     = "bond_maturity"
+)
+
+// The direction of an event, milestones such as goal deadlines do not move the balance
+const (
+	CashFlowDirectionInflow  = "inflow"
+	CashFlowDirectionOutflow = "outflow"
+	CashFlowDirectionNone    = "none"
+)
+
+var (
+	DefaultCalendarDBContextTimeout = 5 * time.Second
+	DefaultCashFlowCalendarDays     = 30
+	DefaultCashFlowFeedDays         = 90
+	MaxCashFlowCalendarDays         = 366
+	DefaultCalendarFeedTokenTTL     = 30 * 24 * time.Hour
+	// maxProjectedOccurrences stops a misconfigured schedule from looping forever
+	maxProjectedOccurrences = 1000
+)
+
+// CashFlowEvent is a single dated item on the cash-flow calendar. OriginalAmount is in
+// OriginalCurrencyCode, an empty currency means the user's default currency. Amount is the
+// value in the calendar's currency and is set by ConvertCashFlowEvents()
+type CashFlowEvent struct {
+	Type                 string          `json:"type"`
+	SourceID             int64           `json:"source_id"`
+	Title                string          `json:"title"`
+	Date                 time.Time       `json:"date"`
+	Direction            string          `json:"direction"`
+	Amount               decimal.Decimal `json:"amount"`
+	OriginalAmount       decimal.Decimal `json:"original_amount"`
+	OriginalCurrencyCode string          `json:"original_currency_code"`
+}
+
+// CashFlowDay holds the events of a single day and the projected balance at the end of the day
+type CashFlowDay struct {
+	Date    time.Time        `json:"date"`
+	Events  []*CashFlowEvent `json:"events"`
+	Inflow  decimal.Decimal  `json:"inflow"`
+	Outflow decimal.Decimal  `json:"outflow"`
+	Net     decimal.Decimal  `json:"net"`
+	Balance decimal.Decimal  `json:"balance"`
+}
+
+// CashFlowCalendar is the merged timeline of upcoming cash-flow events with a running balance
+type CashFlowCalendar struct {
+	StartDate         time.Time       `json:"start_date"`
+	EndDate           time.Time       `json:"end_date"`
+	CurrencyCode      string          `json:"currency_code"`
+	StartingBalance   decimal.Decimal `json:"starting_balance"`
+	EndingBalance     decimal.Decimal `json:"ending_balance"`
+	TotalInflow       decimal.Decimal `json:"total_inflow"`
+	TotalOutflow      decimal.Decimal `json:"total_outflow"`
+	LowestBalance     decimal.Decimal `json:"lowest_balance"`
+	LowestBalanceDate time.Time       `json:"lowest_balance_date"`
+	Days              []*CashFlowDay  `json:"days"`
+}
+
+// ValidateCashFlowCalendarRange() validates the number of days the calendar covers
+func ValidateCashFlowCalendarRange(v *validator.Validator, days int) {
+	v.Check(days > 0, "days", "must be greater than zero")
+	v.Check(days <= MaxCashFlowCalendarDays, "days", "must not be more than 366 days")
+}
+
+// ConvertCashFlowEvents() sets the amount of each event in the calendar's currency.
+// The rates map holds the multiplier from each foreign currency to the calendar's currency,
+// events in the calendar's currency (or without a currency) keep their original amount
+func ConvertCashFlowEvents(events []*CashFlowEvent, currencyCode string, rates map[string]decimal.Decimal) {
+	for _, event := range events {
+		rate, ok := rates[event.OriginalCurrencyCode]
+		if event.OriginalCurrencyCode == "" || event.OriginalCurrencyCode == currencyCode || !ok {
+			event.Amount = event.OriginalAmount
+			continue
+		}
+		event.Amount = event.OriginalAmount.Mul(rate).Round(2)
+	}
+}
+
+// CashFlowStartingBalance() returns the combined balance of the user's accounts in the calendar's
+// currency, which is where the projection starts when no starting balance is given. Balances are
+// converted with the rates from each account's currency, overdrawn accounts reduce the total
+func CashFlowStartingBalance(accounts []*Account, currencyCode string, rates map[string]decimal.Decimal) decimal.Decimal {
+	total := decimal.Zero
+	for _, account := range accounts {
+		balance := account.Balance
+		if rate, ok := rates[account.CurrencyCode]; ok && account.CurrencyCode != currencyCode {
+			balance = balance.Mul(rate).Round(2)
+		}
+		total = total.Add(balance)
+	}
+	return total
+}
+
+// BuildCashFlowCalendar() places the events on a day by day timeline between the start and end
+// dates (inclusive) and projects the balance at the end of each day from the starting balance.
+// Every day in the range is returned, including days without events
+func BuildCashFlowCalendar(events []*CashFlowEvent, startDate, endDate time.Time, startingBalance decimal.Decimal, currencyCode string) *CashFlowCalendar {
+	startDate, endDate = dateOnly(startDate), dateOnly(endDate)
+	calendar := &CashFlowCalendar{
+		StartDate:         startDate,
+		EndDate:           endDate,
+		CurrencyCode:      currencyCode,
+		StartingBalance:   startingBalance,
+		LowestBalance:     startingBalance,
+		LowestBalanceDate: startDate,
+		Days:              []*CashFlowDay{},
+	}
+	// order the events so days list them predictably
+	sortedEvents := make([]*CashFlowEvent, len(events))
+	copy(sortedEvents, events)
+	sort.SliceStable(sortedEvents, func(i, j int) bool {
+		if !sortedEvents[i].Date.Equal(sortedEvents[j].Date) {
+			return sortedEvents[i].Date.Before(sortedEvents[j].Date)
+		}
+		if sortedEvents[i].Type != sortedEvents[j].Type {
+			return sortedEvents[i].Type < sortedEvents[j].Type
+		}
+		return sortedEvents[i].Title < sortedEvents[j].Title
+	})
+	eventsByDay := make(map[time.Time][]*CashFlowEvent)
+	for _, event := range sortedEvents {
+		day := dateOnly(event.Date)
+		eventsByDay[day] = append(eventsByDay[day], event)
+	}
+	balance := startingBalance
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		day := &CashFlowDay{Date: date, Events: []*CashFlowEvent{}}
+		for _, event := range eventsByDay[date] {
+			switch event.Direction {
+			case CashFlowDirectionInflow:
+				day.Inflow = day.Inflow.Add(event.Amount)
+			case CashFlowDirectionOutflow:
+				day.Outflow = day.Outflow.Add(event.Amount)
+			}
+			day.Events = append(day.Events, event)
+		}
+		day.Net = day.Inflow.Sub(day.Outflow)
+		balance = balance.Add(day.Net)
+		day.Balance = balance
+		calendar.TotalInflow = calendar.TotalInflow.Add(day.Inflow)
+		calendar.TotalOutflow = calendar.TotalOutflow.Add(day.Outflow)
+		if balance.LessThan(calendar.LowestBalance) {
+			calendar.LowestBalance = balance
+			calendar.LowestBalanceDate = date
+		}
+		calendar.Days = append(calendar.Days, day)
+	}
+	calendar.EndingBalance = balance
+	return calendar
+}
+
+// EncodeCashFlowICalendar() renders the events of a calendar as an iCalendar (RFC 5545) feed.
+// Each event is an all day event, its description holds the projected balance of that day
+func EncodeCashFlowICalendar(calendar *CashFlowCalendar, calendarName string, now time.Time) []byte {
+	var builder strings.Builder
+	writeLine := func(line string) {
+		builder.WriteString(foldICalendarLine(line))
+		builder.WriteString("\r\n")
+	}
+	dtStamp := now.UTC().Format("20060102T150405Z")
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//OptiVest//Cash Flow Calendar//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:" + escapeICalendarText(calendarName))
+	writeLine("X-PUBLISHED-TTL:PT12H")
+	for _, day := range calendar.Days {
+		for _, event := range day.Events {
+			date := day.Date.Format("20060102")
+			summary := event.Title
+			if event.Direction != CashFlowDirectionNone {
+				sign := "+"
+				if event.Direction == CashFlowDirectionOutflow {
+					sign = "-"
+				}
+				summary = fmt.Sprintf("%s %s%s %s", event.Title, sign, event.Amount.StringFixed(2), calendar.CurrencyCode)
+			}
+			description := fmt.Sprintf("%s\nProjected balance: %s %s", strings.ReplaceAll(event.Type, "_", " "), day.Balance.StringFixed(2), calendar.CurrencyCode)
+			writeLine("BEGIN:VEVENT")
+			writeLine(fmt.Sprintf("UID:%s-%d-%s@optivest", event.Type, event.SourceID, date))
+			writeLine("DTSTAMP:" + dtStamp)
+			writeLine("DTSTART;VALUE=DATE:" + date)
+			writeLine("DTEND;VALUE=DATE:" + day.Date.AddDate(0, 0, 1).Format("20060102"))
+			writeLine("SUMMARY:" + escapeICalendarText(summary))
+			writeLine("DESCRIPTION:" + escapeICalendarText(description))
+			writeLine("CATEGORIES:" + strings.ToUpper(event.Type))
+			writeLine("TRANSP:TRANSPARENT")
+			writeLine("END:VEVENT")
+		}
+	}
+	writeLine("END:VCALENDAR")
+	return []byte(builder.String())
+}
+
+// escapeICalendarText() escapes a TEXT value as required by RFC 5545
+func escapeICalendarText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(text)
+}
+
+// foldICalendarLine() splits lines longer than 75 octets, continuation lines start with a space.
+// We never split in the middle of a multi-byte character
+func foldICalendarLine(line string) string {
+	const maxOctets = 75
+	if len(line) <= maxOctets {
+		return line
+	}
+	var builder strings.Builder
+	lineLength := 0
+	for _, r := range line {
+		runeLength := len(string(r))
+		if lineLength+runeLength > maxOctets {
+			builder.WriteString("\r\n ")
+			lineLength = 1
+		}
+		builder.WriteRune(r)
+		lineLength += runeLength
+	}
+	return builder.String()
+}
+
+// projectRecurringExpense() returns the events of a recurring expense between the start and end dates
+func projectRecurringExpense(recurringExpense *RecurringExpense, currencyCode string, startDate, endDate time.Time) []*CashFlowEvent {
+	events := []*CashFlowEvent{}
+	// work on a copy so the schedule itself is not moved
+	schedule := *recurringExpense
+	for i := 0; i < maxProjectedOccurrences && !schedule.HasEnded() && !schedule.NextOccurrence.After(endDate); i++ {
+		if !schedule.NextOccurrence.Before(startDate) {
+			events = append(events, &CashFlowEvent{
+				Type:                 CashFlowEventRecurringExpense,
+				SourceID:             schedule.ID,
+				Title:                schedule.Name,
+				Date:                 schedule.NextOccurrence,
+				Direction:            CashFlowDirectionOutflow,
+				OriginalAmount:       schedule.Amount,
+				OriginalCurrencyCode: currencyCode,
+			})
+		}
+		schedule.CalculateNextOccurrence()
+	}
+	return events
+}
+
+// projectRecurringIncome() returns the expected postings of a recurring income between the start and end dates
+func projectRecurringIncome(recurringIncome *RecurringIncome, startDate, endDate time.Time) []*CashFlowEvent {
+	events := []*CashFlowEvent{}
+	schedule := *recurringIncome
+	for i := 0; i < maxProjectedOccurrences && !schedule.HasEnded() && !schedule.NextOccurrence.After(endDate); i++ {
+		if !schedule.NextOccurrence.Before(startDate) {
+			events = append(events, &CashFlowEvent{
+				Type:                 CashFlowEventRecurringIncome,
+				SourceID:             schedule.ID,
+				Title:                schedule.Source,
+				Date:                 schedule.NextOccurrence,
+				Direction:            CashFlowDirectionInflow,
+				OriginalAmount:       schedule.AmountOriginal,
+				OriginalCurrencyCode: schedule.OriginalCurrencyCode,
+			})
+		}
+		next := schedule.NextOccurrence
+		schedule.CalculateNextOccurrence()
+		if !schedule.NextOccurrence.After(next) {
+			break
+		}
+	}
+	return events
+}
+
+// projectDebtPayments() returns the monthly minimum payments of a debt from its next payment date
+// until the remaining balance is paid off. The last payment only covers what is left.
+func projectDebtPayments(debt *Debt, startDate, endDate time.Time) []*CashFlowEvent {
+	events := []*CashFlowEvent{}
+	if !debt.MinimumPayment.IsPositive() {
+		return events
+	}
+	remaining := debt.RemainingBalance
+	paymentDay := debt.paymentDay()
+	paymentDate := debt.NextPaymentDate
+	for i := 0; i < maxProjectedOccurrences && remaining.IsPositive() && !paymentDate.After(endDate); i++ {
+		payment := decimal.Min(debt.MinimumPayment, remaining)
+		remaining = remaining.Sub(payment)
+		if !paymentDate.Before(startDate) {
+			events = append(events, &CashFlowEvent{
+				Type:           CashFlowEventDebtPayment,
+				SourceID:       debt.ID,
+				Title:          debt.Name + " payment",
+				Date:           paymentDate,
+				Direction:      CashFlowDirectionOutflow,
+				OriginalAmount: payment,
+			})
+		}
+		paymentDate = addMonthsClamped(paymentDate, 1, paymentDay)
+	}
+	return events
+}
+
+// GetCashFlowEvents() gathers the user's upcoming cash-flow events between the start and end dates:
+// recurring expense occurrences, expected recurring incomes, debt payments, goal deadlines and bond maturities.
+// Debts and bonds are assumed to be in the user's default currency. A bond is expected to be redeemed
+// at its purchase price, goal deadlines show the amount still needed and do not move the balance.
+func (m CalendarManagerModel) GetCashFlowEvents(userID int64, startDate, endDate time.Time) ([]*CashFlowEvent, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultCalendarDBContextTimeout)
+	defer cancel()
+	events := []*CashFlowEvent{}
+	// recurring expenses
+	recurringExpenses, err := m.DB.GetCalendarRecurringExpensesByUserID(ctx, database.GetCalendarRecurringExpensesByUserIDParams{
+		UserID:  userID,
+		Column2: endDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range recurringExpenses {
+		recurringExpense := &RecurringExpense{
+			ID:                 row.ID,
+			Name:               row.Name,
+			Amount:             decimal.RequireFromString(row.Amount),
+			RecurrenceInterval: row.RecurrenceInterval,
+			NextOccurrence:     row.NextOccurrence,
+			IntervalCount:      row.IntervalCount,
+			DayOfMonth:         int32(row.DayOfMonth.Int16),
+			EndDate:            row.EndDate.Time,
+			MaxOccurrences:     row.MaxOccurrences.Int32,
+			OccurrenceCount:    row.OccurrenceCount,
+		}
+		events = append(events, projectRecurringExpense(recurringExpense, row.CurrencyCode, startDate, endDate)...)
+	}
+	// recurring incomes
+	recurringIncomes, err := m.DB.GetCalendarRecurringIncomesByUserID(ctx, database.GetCalendarRecurringIncomesByUserIDParams{
+		UserID:  userID,
+		Column2: endDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range recurringIncomes {
+		recurringIncome := &RecurringIncome{
+			ID:                   row.ID,
+			Source:               row.Source,
+			AmountOriginal:       decimal.RequireFromString(row.AmountOriginal),
+			OriginalCurrencyCode: row.OriginalCurrencyCode,
+			RecurrenceInterval:   row.RecurrenceInterval,
+			NextOccurrence:       row.NextOccurrence,
+			DayOfMonth:           row.DayOfMonth,
+			EndDate:              row.EndDate.Time,
+		}
+		events = append(events, projectRecurringIncome(recurringIncome, startDate, endDate)...)
+	}
+	// debt payments
+	debts, err := m.DB.GetCalendarDebtsByUserID(ctx, database.GetCalendarDebtsByUserIDParams{
+		UserID:  userID,
+		Column2: endDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range debts {
+		debt := &Debt{
+			ID:               row.ID,
+			Name:             row.Name,
+			RemainingBalance: decimal.RequireFromString(row.RemainingBalance),
+			MinimumPayment:   decimal.RequireFromString(row.MinimumPayment),
+			NextPaymentDate:  row.NextPaymentDate,
+		}
+		events = append(events, projectDebtPayments(debt, startDate, endDate)...)
+	}
+	// goal deadlines
+	goals, err := m.DB.GetCalendarGoalsByUserID(ctx, database.GetCalendarGoalsByUserIDParams{
+		UserID:  userID,
+		Column2: startDate,
+		Column3: endDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range goals {
+		currentAmount := decimal.Zero
+		if row.CurrentAmount.Valid {
+			currentAmount = decimal.RequireFromString(row.CurrentAmount.String)
+		}
+		events = append(events, &CashFlowEvent{
+			Type:           CashFlowEventGoalDeadline,
+			SourceID:       row.ID,
+			Title:          "Goal deadline: " + row.Name,
+			Date:           row.EndDate,
+			Direction:      CashFlowDirectionNone,
+			OriginalAmount: decimal.Max(decimal.RequireFromString(row.TargetAmount).Sub(currentAmount), decimal.Zero),
+		})
+	}
+	// bond maturities
+	bonds, err := m.DB.GetCalendarBondMaturitiesByUserID(ctx, database.GetCalendarBondMaturitiesByUserIDParams{
+		UserID:  userID,
+		Column2: startDate,
+		Column3: endDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range bonds {
+		events = append(events, &CashFlowEvent{
+			Type:           CashFlowEventBondMaturity,
+			SourceID:       row.ID,
+			Title:          "Bond maturity: " + row.BondSymbol,
+			Date:           row.MaturityDate,
+			Direction:      CashFlowDirectionInflow,
+			OriginalAmount: decimal.RequireFromString(row.Quantity).Mul(decimal.RequireFromString(row.PurchasePrice)).Round(2),
+		})
+	}
+	return events, nil
+}
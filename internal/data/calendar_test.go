@@ -0,0 +1,325 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/shopspring/decimal"
+)
+
+func calendarDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func eventDates(events []*CashFlowEvent) []string {
+	dates := make([]string, len(events))
+	for i, event := range events {
+		dates[i] = event.Date.Format("2006-01-02")
+	}
+	return dates
+}
+
+func TestProjectRecurringExpense(t *testing.T) {
+	tests := []struct {
+		name             string
+		recurringExpense RecurringExpense
+		start            time.Time
+		end              time.Time
+		wantDates        []string
+	}{
+		{
+			name: "Weekly within range",
+			recurringExpense: RecurringExpense{
+				RecurrenceInterval: database.RecurrenceIntervalEnumWeekly,
+				IntervalCount:      1,
+				NextOccurrence:     calendarDate(2026, time.November, 2),
+			},
+			start:     calendarDate(2026, time.November, 1),
+			end:       calendarDate(2026, time.November, 20),
+			wantDates: []string{"2026-11-02", "2026-11-09", "2026-11-16"},
+		},
+		{
+			name: "Monthly on the 31st is clamped",
+			recurringExpense: RecurringExpense{
+				RecurrenceInterval: database.RecurrenceIntervalEnumMonthly,
+				IntervalCount:      1,
+				DayOfMonth:         31,
+				NextOccurrence:     calendarDate(2027, time.January, 31),
+			},
+			start:     calendarDate(2027, time.January, 1),
+			end:       calendarDate(2027, time.April, 30),
+			wantDates: []string{"2027-01-31", "2027-02-28", "2027-03-31", "2027-04-30"},
+		},
+		{
+			name: "Occurrences before the start are skipped",
+			recurringExpense: RecurringExpense{
+				RecurrenceInterval: database.RecurrenceIntervalEnumDaily,
+				IntervalCount:      2,
+				NextOccurrence:     calendarDate(2026, time.November, 1),
+			},
+			start:     calendarDate(2026, time.November, 4),
+			end:       calendarDate(2026, time.November, 8),
+			wantDates: []string{"2026-11-05", "2026-11-07"},
+		},
+		{
+			name: "Stops at the maximum occurrences",
+			recurringExpense: RecurringExpense{
+				RecurrenceInterval: database.RecurrenceIntervalEnumWeekly,
+				IntervalCount:      1,
+				NextOccurrence:     calendarDate(2026, time.November, 2),
+				MaxOccurrences:     5,
+				OccurrenceCount:    3,
+			},
+			start:     calendarDate(2026, time.November, 1),
+			end:       calendarDate(2026, time.December, 31),
+			wantDates: []string{"2026-11-02", "2026-11-09"},
+		},
+		{
+			name: "Stops at the end date",
+			recurringExpense: RecurringExpense{
+				RecurrenceInterval: database.RecurrenceIntervalEnumWeekly,
+				IntervalCount:      1,
+				NextOccurrence:     calendarDate(2026, time.November, 2),
+				EndDate:            calendarDate(2026, time.November, 10),
+			},
+			start:     calendarDate(2026, time.November, 1),
+			end:       calendarDate(2026, time.December, 31),
+			wantDates: []string{"2026-11-02", "2026-11-09"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurringExpense := tt.recurringExpense
+			events := projectRecurringExpense(&recurringExpense, "USD", tt.start, tt.end)
+			got := eventDates(events)
+			if strings.Join(got, ",") != strings.Join(tt.wantDates, ",") {
+				t.Errorf("projectRecurringExpense() dates = %v, want %v", got, tt.wantDates)
+			}
+			// the schedule itself must not move
+			if !recurringExpense.NextOccurrence.Equal(tt.recurringExpense.NextOccurrence) {
+				t.Errorf("projectRecurringExpense() moved the schedule to %v", recurringExpense.NextOccurrence)
+			}
+		})
+	}
+}
+
+func TestProjectRecurringIncome(t *testing.T) {
+	tests := []struct {
+		name            string
+		recurringIncome RecurringIncome
+		wantDates       []string
+	}{
+		{
+			name: "Monthly salary",
+			recurringIncome: RecurringIncome{
+				RecurrenceInterval: database.RecurrenceIntervalEnumMonthly,
+				NextOccurrence:     calendarDate(2026, time.November, 25),
+			},
+			wantDates: []string{"2026-11-25", "2026-12-25"},
+		},
+		{
+			name: "Month-end salary does not drift",
+			recurringIncome: RecurringIncome{
+				RecurrenceInterval: database.RecurrenceIntervalEnumMonthly,
+				NextOccurrence:     calendarDate(2026, time.October, 31),
+				DayOfMonth:         31,
+			},
+			wantDates: []string{"2026-11-30", "2026-12-31"},
+		},
+		{
+			name: "Ends before the range does",
+			recurringIncome: RecurringIncome{
+				RecurrenceInterval: database.RecurrenceIntervalEnumWeekly,
+				NextOccurrence:     calendarDate(2026, time.November, 6),
+				EndDate:            calendarDate(2026, time.November, 20),
+			},
+			wantDates: []string{"2026-11-06", "2026-11-13", "2026-11-20"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := projectRecurringIncome(&tt.recurringIncome, calendarDate(2026, time.November, 1), calendarDate(2026, time.December, 31))
+			got := eventDates(events)
+			if strings.Join(got, ",") != strings.Join(tt.wantDates, ",") {
+				t.Errorf("projectRecurringIncome() dates = %v, want %v", got, tt.wantDates)
+			}
+		})
+	}
+}
+
+func TestProjectDebtPayments(t *testing.T) {
+	tests := []struct {
+		name        string
+		debt        Debt
+		wantDates   []string
+		wantAmounts []string
+	}{
+		{
+			name: "Last payment only covers the remaining balance",
+			debt: Debt{
+				RemainingBalance: decimal.NewFromInt(250),
+				MinimumPayment:   decimal.NewFromInt(100),
+				NextPaymentDate:  calendarDate(2026, time.November, 15),
+			},
+			wantDates:   []string{"2026-11-15", "2026-12-15", "2027-01-15"},
+			wantAmounts: []string{"100", "100", "50"},
+		},
+		{
+			name: "Payment day is clamped in short months",
+			debt: Debt{
+				RemainingBalance: decimal.NewFromInt(1000),
+				MinimumPayment:   decimal.NewFromInt(100),
+				NextPaymentDate:  calendarDate(2027, time.January, 31),
+			},
+			wantDates:   []string{"2027-01-31", "2027-02-28"},
+			wantAmounts: []string{"100", "100"},
+		},
+		{
+			name: "No minimum payment",
+			debt: Debt{
+				RemainingBalance: decimal.NewFromInt(1000),
+				NextPaymentDate:  calendarDate(2026, time.November, 15),
+			},
+			wantDates:   []string{},
+			wantAmounts: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := projectDebtPayments(&tt.debt, calendarDate(2026, time.November, 1), calendarDate(2027, time.February, 28))
+			got := eventDates(events)
+			if strings.Join(got, ",") != strings.Join(tt.wantDates, ",") {
+				t.Fatalf("projectDebtPayments() dates = %v, want %v", got, tt.wantDates)
+			}
+			for i, event := range events {
+				if !event.OriginalAmount.Equal(decimal.RequireFromString(tt.wantAmounts[i])) {
+					t.Errorf("projectDebtPayments() payment %d = %s, want %s", i, event.OriginalAmount, tt.wantAmounts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildCashFlowCalendar(t *testing.T) {
+	start := calendarDate(2026, time.November, 1)
+	end := calendarDate(2026, time.November, 5)
+	tests := []struct {
+		name           string
+		events         []*CashFlowEvent
+		wantBalances   []string
+		wantLowest     string
+		wantLowestDate string
+		wantTotalIn    string
+		wantTotalOut   string
+	}{
+		{
+			name:           "No events",
+			events:         nil,
+			wantBalances:   []string{"100", "100", "100", "100", "100"},
+			wantLowest:     "100",
+			wantLowestDate: "2026-11-01",
+			wantTotalIn:    "0",
+			wantTotalOut:   "0",
+		},
+		{
+			name: "Running balance with inflows, outflows and milestones",
+			events: []*CashFlowEvent{
+				{Type: CashFlowEventRecurringExpense, Date: calendarDate(2026, time.November, 2), Direction: CashFlowDirectionOutflow, Amount: decimal.NewFromInt(150)},
+				{Type: CashFlowEventDebtPayment, Date: calendarDate(2026, time.November, 2), Direction: CashFlowDirectionOutflow, Amount: decimal.NewFromInt(20)},
+				{Type: CashFlowEventGoalDeadline, Date: calendarDate(2026, time.November, 3), Direction: CashFlowDirectionNone, Amount: decimal.NewFromInt(5000)},
+				{Type: CashFlowEventRecurringIncome, Date: calendarDate(2026, time.November, 4), Direction: CashFlowDirectionInflow, Amount: decimal.NewFromInt(500)},
+				// outside the range
+				{Type: CashFlowEventBondMaturity, Date: calendarDate(2026, time.November, 9), Direction: CashFlowDirectionInflow, Amount: decimal.NewFromInt(1000)},
+			},
+			wantBalances:   []string{"100", "-70", "-70", "430", "430"},
+			wantLowest:     "-70",
+			wantLowestDate: "2026-11-02",
+			wantTotalIn:    "500",
+			wantTotalOut:   "170",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := BuildCashFlowCalendar(tt.events, start, end, decimal.NewFromInt(100), "USD")
+			if len(calendar.Days) != len(tt.wantBalances) {
+				t.Fatalf("BuildCashFlowCalendar() days = %d, want %d", len(calendar.Days), len(tt.wantBalances))
+			}
+			for i, day := range calendar.Days {
+				if !day.Balance.Equal(decimal.RequireFromString(tt.wantBalances[i])) {
+					t.Errorf("BuildCashFlowCalendar() balance on %s = %s, want %s", day.Date.Format("2006-01-02"), day.Balance, tt.wantBalances[i])
+				}
+			}
+			if !calendar.LowestBalance.Equal(decimal.RequireFromString(tt.wantLowest)) || calendar.LowestBalanceDate.Format("2006-01-02") != tt.wantLowestDate {
+				t.Errorf("BuildCashFlowCalendar() lowest = %s on %s, want %s on %s", calendar.LowestBalance, calendar.LowestBalanceDate.Format("2006-01-02"), tt.wantLowest, tt.wantLowestDate)
+			}
+			if !calendar.TotalInflow.Equal(decimal.RequireFromString(tt.wantTotalIn)) || !calendar.TotalOutflow.Equal(decimal.RequireFromString(tt.wantTotalOut)) {
+				t.Errorf("BuildCashFlowCalendar() totals = %s/%s, want %s/%s", calendar.TotalInflow, calendar.TotalOutflow, tt.wantTotalIn, tt.wantTotalOut)
+			}
+			if !calendar.EndingBalance.Equal(decimal.RequireFromString(tt.wantBalances[len(tt.wantBalances)-1])) {
+				t.Errorf("BuildCashFlowCalendar() ending balance = %s", calendar.EndingBalance)
+			}
+		})
+	}
+}
+
+func TestCashFlowStartingBalance(t *testing.T) {
+	accounts := []*Account{
+		{CurrencyCode: "USD", Balance: decimal.RequireFromString("1200")},
+		{CurrencyCode: "EUR", Balance: decimal.RequireFromString("100")},
+		{CurrencyCode: "USD", Balance: decimal.RequireFromString("-300")},
+	}
+	rates := map[string]decimal.Decimal{"EUR": decimal.RequireFromString("1.1")}
+	if got := CashFlowStartingBalance(accounts, "USD", rates); !got.Equal(decimal.RequireFromString("1010")) {
+		t.Errorf("CashFlowStartingBalance() = %s, want 1010", got)
+	}
+	if got := CashFlowStartingBalance(nil, "USD", rates); !got.IsZero() {
+		t.Errorf("CashFlowStartingBalance() without accounts = %s, want 0", got)
+	}
+}
+
+func TestEncodeCashFlowICalendar(t *testing.T) {
+	day := calendarDate(2026, time.November, 2)
+	calendar := BuildCashFlowCalendar([]*CashFlowEvent{
+		{
+			Type:      CashFlowEventRecurringExpense,
+			SourceID:  12,
+			Title:     "Rent, utilities; and a very long description that needs folding across lines",
+			Date:      day,
+			Direction: CashFlowDirectionOutflow,
+			Amount:    decimal.NewFromInt(1200),
+		},
+	}, day, day, decimal.NewFromInt(2000), "USD")
+	ics := string(EncodeCashFlowICalendar(calendar, "OptiVest Cash Flow", calendarDate(2026, time.October, 18)))
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Calendar header", want: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"},
+		{name: "Stable UID", want: "UID:recurring_expense-12-20261102@optivest\r\n"},
+		{name: "All day start", want: "DTSTART;VALUE=DATE:20261102\r\n"},
+		{name: "All day end", want: "DTEND;VALUE=DATE:20261103\r\n"},
+		{name: "Escaped and folded summary", want: "SUMMARY:Rent\\, utilities\\; and a very long description that needs folding a\r\n cross lines -1200.00 USD\r\n"},
+		{name: "Projected balance", want: "DESCRIPTION:recurring expense\\nProjected balance: 800.00 USD\r\n"},
+		{name: "Calendar footer", want: "END:VCALENDAR\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(ics, tt.want) {
+				t.Errorf("EncodeCashFlowICalendar() missing %q in\n%s", tt.want, ics)
+			}
+		})
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("EncodeCashFlowICalendar() line longer than 75 octets: %q", line)
+		}
+	}
+}
@@ -207,6 +207,40 @@ func (m CommentManagerModel) DeleteComment(userID, commentID int64) error {
 	return nil
 }
 
+// ModeratorDeleteComment deletes any comment, whoever wrote it. Callers must check the user
+// may moderate where the comment was left, see GetCommentAssociation
+func (m CommentManagerModel) ModeratorDeleteComment(commentID int64) error {
+	ctx, cancel := contextGenerator(context.Background(), DefaultCommManDBContextTimeout)
+	defer cancel()
+	// delete
+	_, err := m.DB.DeleteCommentByID(ctx, commentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCommentAssociation returns what a comment was left on, i.e its associated type and ID
+func (m CommentManagerModel) GetCommentAssociation(commentID int64) (database.CommentAssociatedType, int64, error) {
+	ctx, cancel := contextGenerator(context.Background(), DefaultCommManDBContextTimeout)
+	defer cancel()
+	association, err := m.DB.GetCommentAssociation(ctx, commentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", 0, ErrGeneralRecordNotFound
+		default:
+			return "", 0, err
+		}
+	}
+	return association.AssociatedType, association.AssociatedID, nil
+}
+
 // GetCommentById gets a comment by its ID
 // We take in the comment ID and return the comment and an error if there is one
 func (m CommentManagerModel) GetCommentById(userID, commentID int64) (*Comment, error) {
@@ -0,0 +1,327 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type ExchangeRateManagerModel struct {
+	DB *database.Queries
+}
+
+// The kinds of items on an FX revaluation
+const (
+	FXRevaluationIncome = "income"
+	FXRevaluationBudget = "budget"
+)
+
+var (
+	DefaultExchangeRateDBContextTimeout = 5 * time.Second
+	// MaxExchangeRateAge is how old a stored rate can be and still be used for a date,
+	// it covers weekends and the days the scheduler could not reach the rates API
+	MaxExchangeRateAge = 7 * 24 * time.Hour
+	// MaxExchangeRateHistoryDays is the longest range of rates returned at once
+	MaxExchangeRateHistoryDays = 366
+)
+
+// ExchangeRate is the rate converting one unit of BaseCurrency to TargetCurrency on RateDate
+type ExchangeRate struct {
+	BaseCurrency   string          `json:"base_currency"`
+	TargetCurrency string          `json:"target_currency"`
+	Rate           decimal.Decimal `json:"rate"`
+	RateDate       time.Time       `json:"rate_date"`
+}
+
+// ExchangeRateHistory holds the stored daily rates from a single base currency. Rates between
+// any two currencies are derived from their rates to the base currency
+type ExchangeRateHistory struct {
+	BaseCurrency string
+	rates        map[string][]*ExchangeRate
+}
+
+// FXRevaluationItem is a past foreign-currency income or budget valued at the rate it was
+// booked at and at the current rate. GainLoss is positive when the current rate is better
+type FXRevaluationItem struct {
+	Kind           string          `json:"kind"`
+	ID             int64           `json:"id"`
+	Name           string          `json:"name"`
+	Date           time.Time       `json:"date"`
+	CurrencyCode   string          `json:"currency_code"`
+	OriginalAmount decimal.Decimal `json:"original_amount"`
+	BookedRate     decimal.Decimal `json:"booked_rate"`
+	BookedAmount   decimal.Decimal `json:"booked_amount"`
+	CurrentRate    decimal.Decimal `json:"current_rate"`
+	CurrentAmount  decimal.Decimal `json:"current_amount"`
+	GainLoss       decimal.Decimal `json:"gain_loss"`
+}
+
+// FXRevaluation is the revaluation of all of a user's foreign-currency incomes and budgets.
+// Items without a known booked or current rate are left out and counted in SkippedCount
+type FXRevaluation struct {
+	CurrencyCode       string               `json:"currency_code"`
+	RevaluedAt         time.Time            `json:"revalued_at"`
+	Items              []*FXRevaluationItem `json:"items"`
+	TotalBookedAmount  decimal.Decimal      `json:"total_booked_amount"`
+	TotalCurrentAmount decimal.Decimal      `json:"total_current_amount"`
+	TotalGainLoss      decimal.Decimal      `json:"total_gain_loss"`
+	SkippedCount       int                  `json:"skipped_count"`
+}
+
+// ValidateExchangeRateHistoryRange() checks the currencies and date range of a rate history request
+func ValidateExchangeRateHistoryRange(v *validator.Validator, sourceCurrency, targetCurrency string, startDate, endDate time.Time) {
+	v.Check(len(sourceCurrency) == 3, "from", "must be a 3 letter currency code")
+	v.Check(len(targetCurrency) == 3, "to", "must be a 3 letter currency code")
+	v.Check(!endDate.Before(startDate), "end_date", "must not be before the start date")
+	v.Check(endDate.Sub(startDate) < time.Duration(MaxExchangeRateHistoryDays)*24*time.Hour, "end_date", "range must not be longer than 366 days")
+}
+
+// NewExchangeRateHistory() creates a rate history from stored rates of a single base currency
+func NewExchangeRateHistory(baseCurrency string, rates []*ExchangeRate) *ExchangeRateHistory {
+	history := &ExchangeRateHistory{BaseCurrency: baseCurrency, rates: make(map[string][]*ExchangeRate)}
+	for _, rate := range rates {
+		history.rates[rate.TargetCurrency] = append(history.rates[rate.TargetCurrency], rate)
+	}
+	for _, currencyRates := range history.rates {
+		sort.Slice(currencyRates, func(i, j int) bool {
+			return currencyRates[i].RateDate.Before(currencyRates[j].RateDate)
+		})
+	}
+	return history
+}
+
+// leg() returns the base currency rate of a currency on a date. That is the latest stored
+// rate on or before the date that is no older than MaxExchangeRateAge
+func (h *ExchangeRateHistory) leg(currency string, date time.Time) (decimal.Decimal, bool) {
+	if currency == h.BaseCurrency {
+		return decimal.NewFromInt(1), true
+	}
+	date = dateOnly(date)
+	currencyRates := h.rates[currency]
+	i := sort.Search(len(currencyRates), func(i int) bool {
+		return currencyRates[i].RateDate.After(date)
+	})
+	if i == 0 {
+		return decimal.Zero, false
+	}
+	rate := currencyRates[i-1]
+	if date.Sub(rate.RateDate) > MaxExchangeRateAge {
+		return decimal.Zero, false
+	}
+	return rate.Rate, true
+}
+
+// Rate() returns the rate converting one unit of the source currency to the target currency on a date.
+// It returns false when either currency has no recent enough rate stored for that date
+func (h *ExchangeRateHistory) Rate(sourceCurrency, targetCurrency string, date time.Time) (decimal.Decimal, bool) {
+	if sourceCurrency == targetCurrency {
+		return decimal.NewFromInt(1), true
+	}
+	sourceRate, ok := h.leg(sourceCurrency, date)
+	if !ok || sourceRate.IsZero() {
+		return decimal.Zero, false
+	}
+	targetRate, ok := h.leg(targetCurrency, date)
+	if !ok {
+		return decimal.Zero, false
+	}
+	return targetRate.DivRound(sourceRate, 10), true
+}
+
+// Series() returns the rate from the source to the target currency for every day in the range
+// that has one, oldest first
+func (h *ExchangeRateHistory) Series(sourceCurrency, targetCurrency string, startDate, endDate time.Time) []*ExchangeRate {
+	series := []*ExchangeRate{}
+	for day := dateOnly(startDate); !day.After(dateOnly(endDate)); day = day.AddDate(0, 0, 1) {
+		rate, ok := h.Rate(sourceCurrency, targetCurrency, day)
+		if !ok {
+			continue
+		}
+		series = append(series, &ExchangeRate{
+			BaseCurrency:   sourceCurrency,
+			TargetCurrency: targetCurrency,
+			Rate:           rate,
+			RateDate:       day,
+		})
+	}
+	return series
+}
+
+// BuildFXRevaluation() values the items at the current rates and totals the gains and losses.
+// Items booked without a rate (older budgets) get the stored rate of the day they were created.
+// currentRates maps each currency to its current rate to the revaluation currency
+func BuildFXRevaluation(currencyCode string, items []*FXRevaluationItem, history *ExchangeRateHistory, currentRates map[string]decimal.Decimal, now time.Time) *FXRevaluation {
+	revaluation := &FXRevaluation{
+		CurrencyCode:       currencyCode,
+		RevaluedAt:         now,
+		Items:              []*FXRevaluationItem{},
+		TotalBookedAmount:  decimal.Zero,
+		TotalCurrentAmount: decimal.Zero,
+		TotalGainLoss:      decimal.Zero,
+	}
+	for _, item := range items {
+		if item.BookedRate.IsZero() && history != nil {
+			if rate, ok := history.Rate(item.CurrencyCode, currencyCode, item.Date); ok {
+				item.BookedRate = rate
+			}
+		}
+		currentRate, ok := currentRates[item.CurrencyCode]
+		if item.BookedRate.IsZero() || !ok {
+			revaluation.SkippedCount++
+			continue
+		}
+		if item.OriginalAmount.IsZero() {
+			item.OriginalAmount = item.BookedAmount.DivRound(item.BookedRate, 2)
+		}
+		item.CurrentRate = currentRate
+		item.CurrentAmount = item.OriginalAmount.Mul(currentRate).Round(2)
+		item.GainLoss = item.CurrentAmount.Sub(item.BookedAmount)
+		revaluation.Items = append(revaluation.Items, item)
+		revaluation.TotalBookedAmount = revaluation.TotalBookedAmount.Add(item.BookedAmount)
+		revaluation.TotalCurrentAmount = revaluation.TotalCurrentAmount.Add(item.CurrentAmount)
+		revaluation.TotalGainLoss = revaluation.TotalGainLoss.Add(item.GainLoss)
+	}
+	return revaluation
+}
+
+// SaveExchangeRates() stores the rates from the base currency for a day, replacing any rates
+// already stored for that day. It returns the number of rates saved
+func (m ExchangeRateManagerModel) SaveExchangeRates(baseCurrency string, rateDate time.Time, rates map[string]float64) (int64, error) {
+	currencies := make([]string, 0, len(rates))
+	values := make([]string, 0, len(rates))
+	for currency, rate := range rates {
+		// the API lists the base currency itself and we never want to store a non-positive rate
+		if currency == baseCurrency || rate <= 0 || len(currency) != 3 {
+			continue
+		}
+		currencies = append(currencies, strings.ToUpper(currency))
+		values = append(values, decimal.NewFromFloat(rate).String())
+	}
+	if len(currencies) == 0 {
+		return 0, nil
+	}
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultExchangeRateDBContextTimeout)
+	defer cancel()
+	return m.DB.SaveExchangeRates(ctx, database.SaveExchangeRatesParams{
+		Column1: baseCurrency,
+		Column2: currencies,
+		Column3: values,
+		Column4: dateOnly(rateDate),
+	})
+}
+
+// GetExchangeRateOnDate() returns the stored rate converting the source to the target currency
+// on a date. It returns ErrGeneralRecordNotFound when no recent enough rate is stored
+func (m ExchangeRateManagerModel) GetExchangeRateOnDate(baseCurrency, sourceCurrency, targetCurrency string, date time.Time) (decimal.Decimal, error) {
+	if sourceCurrency == targetCurrency {
+		return decimal.NewFromInt(1), nil
+	}
+	date = dateOnly(date)
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultExchangeRateDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetLatestExchangeRatesOnDate(ctx, database.GetLatestExchangeRatesOnDateParams{
+		BaseCurrency: baseCurrency,
+		Column2:      []string{sourceCurrency, targetCurrency},
+		Column3:      date.Add(-MaxExchangeRateAge),
+		Column4:      date,
+	})
+	if err != nil {
+		return decimal.Zero, err
+	}
+	rates := make([]*ExchangeRate, len(rows))
+	for i, row := range rows {
+		rates[i] = populateExchangeRate(baseCurrency, row.TargetCurrency, row.Rate, row.RateDate)
+	}
+	rate, ok := NewExchangeRateHistory(baseCurrency, rates).Rate(sourceCurrency, targetCurrency, date)
+	if !ok {
+		return decimal.Zero, ErrGeneralRecordNotFound
+	}
+	return rate, nil
+}
+
+// GetExchangeRateHistory() returns the stored rates of the currencies between the start and end dates.
+// Callers needing rates from the start date on should start MaxExchangeRateAge earlier
+func (m ExchangeRateManagerModel) GetExchangeRateHistory(baseCurrency string, currencies []string, startDate, endDate time.Time) (*ExchangeRateHistory, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultExchangeRateDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetExchangeRateHistory(ctx, database.GetExchangeRateHistoryParams{
+		BaseCurrency: baseCurrency,
+		Column2:      currencies,
+		Column3:      dateOnly(startDate),
+		Column4:      dateOnly(endDate),
+	})
+	if err != nil {
+		return nil, err
+	}
+	rates := make([]*ExchangeRate, len(rows))
+	for i, row := range rows {
+		rates[i] = populateExchangeRate(baseCurrency, row.TargetCurrency, row.Rate, row.RateDate)
+	}
+	return NewExchangeRateHistory(baseCurrency, rates), nil
+}
+
+// GetForeignCurrencyItemsByUserID() returns the user's incomes and budgets recorded in a currency
+// other than the user's, ready to be revalued with BuildFXRevaluation()
+func (m ExchangeRateManagerModel) GetForeignCurrencyItemsByUserID(userID int64, currencyCode string) ([]*FXRevaluationItem, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultExchangeRateDBContextTimeout)
+	defer cancel()
+	incomeRows, err := m.DB.GetForeignCurrencyIncomesByUserID(ctx, database.GetForeignCurrencyIncomesByUserIDParams{
+		UserID:               userID,
+		OriginalCurrencyCode: currencyCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := []*FXRevaluationItem{}
+	for _, row := range incomeRows {
+		items = append(items, &FXRevaluationItem{
+			Kind:           FXRevaluationIncome,
+			ID:             row.ID,
+			Name:           row.Source,
+			Date:           row.DateReceived,
+			CurrencyCode:   row.OriginalCurrencyCode,
+			OriginalAmount: decimal.RequireFromString(row.AmountOriginal),
+			BookedRate:     decimal.RequireFromString(row.ExchangeRate),
+			BookedAmount:   decimal.RequireFromString(row.Amount),
+		})
+	}
+	budgetRows, err := m.DB.GetForeignCurrencyBudgetsByUserID(ctx, database.GetForeignCurrencyBudgetsByUserIDParams{
+		UserID:       userID,
+		CurrencyCode: currencyCode,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range budgetRows {
+		// budgets store their total in the user's currency, the original amount is worked out from the rate
+		items = append(items, &FXRevaluationItem{
+			Kind:         FXRevaluationBudget,
+			ID:           row.ID,
+			Name:         row.Name,
+			Date:         row.CreatedAt,
+			CurrencyCode: row.CurrencyCode,
+			BookedRate:   decimal.RequireFromString(row.ConversionRate),
+			BookedAmount: decimal.RequireFromString(row.TotalAmount),
+		})
+	}
+	return items, nil
+}
+
+// populateExchangeRate() converts a stored rate row into an ExchangeRate
+func populateExchangeRate(baseCurrency, targetCurrency, rate string, rateDate time.Time) *ExchangeRate {
+	return &ExchangeRate{
+		BaseCurrency:   baseCurrency,
+		TargetCurrency: strings.TrimSpace(targetCurrency),
+		Rate:           decimal.RequireFromString(rate),
+		RateDate:       dateOnly(rateDate),
+	}
+}
@@ -0,0 +1,116 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestExchangeRateHistoryRate(t *testing.T) {
+	date := func(day int) time.Time {
+		return time.Date(2025, time.March, day, 0, 0, 0, 0, time.UTC)
+	}
+	rate := func(currency string, day int, value string) *ExchangeRate {
+		return &ExchangeRate{BaseCurrency: "USD", TargetCurrency: currency, Rate: decimal.RequireFromString(value), RateDate: date(day)}
+	}
+	history := NewExchangeRateHistory("USD", []*ExchangeRate{
+		rate("EUR", 3, "0.9"),
+		rate("EUR", 1, "0.8"),
+		rate("KES", 1, "130"),
+		rate("KES", 3, "135"),
+	})
+	tests := []struct {
+		name   string
+		source string
+		target string
+		on     time.Time
+		want   string
+		wantOK bool
+	}{
+		{name: "Base to currency", source: "USD", target: "EUR", on: date(1), want: "0.8", wantOK: true},
+		{name: "Currency to base", source: "EUR", target: "USD", on: date(1), want: "1.25", wantOK: true},
+		{name: "Cross rate", source: "EUR", target: "KES", on: date(3), want: "150", wantOK: true},
+		{name: "Days without a rate use the previous rate", source: "USD", target: "EUR", on: date(2).Add(15 * time.Hour), want: "0.8", wantOK: true},
+		{name: "Same currency", source: "GBP", target: "GBP", on: date(1), want: "1", wantOK: true},
+		{name: "Before the first rate", source: "USD", target: "EUR", on: time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC), wantOK: false},
+		{name: "Rate too old", source: "USD", target: "EUR", on: date(11), wantOK: false},
+		{name: "Unknown currency", source: "GBP", target: "USD", on: date(3), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := history.Rate(tt.source, tt.target, tt.on)
+			if ok != tt.wantOK {
+				t.Fatalf("Rate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("Rate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildFXRevaluation(t *testing.T) {
+	booked := time.Date(2025, time.January, 10, 0, 0, 0, 0, time.UTC)
+	history := NewExchangeRateHistory("USD", []*ExchangeRate{
+		{BaseCurrency: "USD", TargetCurrency: "EUR", Rate: decimal.RequireFromString("0.8"), RateDate: booked},
+	})
+	tests := []struct {
+		name         string
+		item         *FXRevaluationItem
+		wantOriginal string
+		wantGainLoss string
+		wantSkipped  int
+	}{
+		{
+			name: "Income gains when its currency strengthens",
+			item: &FXRevaluationItem{Kind: FXRevaluationIncome, CurrencyCode: "EUR", Date: booked,
+				OriginalAmount: decimal.NewFromInt(100), BookedRate: decimal.RequireFromString("1.1"), BookedAmount: decimal.NewFromInt(110)},
+			wantOriginal: "100",
+			wantGainLoss: "10",
+		},
+		{
+			name: "Budget original amount comes from its booked rate",
+			item: &FXRevaluationItem{Kind: FXRevaluationBudget, CurrencyCode: "EUR", Date: booked,
+				BookedRate: decimal.RequireFromString("1.25"), BookedAmount: decimal.NewFromInt(250)},
+			wantOriginal: "200",
+			wantGainLoss: "-10",
+		},
+		{
+			name:         "Budget without a booked rate uses the stored rate of its day",
+			item:         &FXRevaluationItem{Kind: FXRevaluationBudget, CurrencyCode: "EUR", Date: booked, BookedAmount: decimal.NewFromInt(125)},
+			wantOriginal: "100",
+			wantGainLoss: "-5",
+		},
+		{
+			name:        "Budget without any rate is skipped",
+			item:        &FXRevaluationItem{Kind: FXRevaluationBudget, CurrencyCode: "EUR", Date: booked.AddDate(-1, 0, 0), BookedAmount: decimal.NewFromInt(125)},
+			wantSkipped: 1,
+		},
+		{
+			name: "Currency without a current rate is skipped",
+			item: &FXRevaluationItem{Kind: FXRevaluationIncome, CurrencyCode: "JPY", Date: booked,
+				OriginalAmount: decimal.NewFromInt(100), BookedRate: decimal.RequireFromString("0.007"), BookedAmount: decimal.RequireFromString("0.7")},
+			wantSkipped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revaluation := BuildFXRevaluation("USD", []*FXRevaluationItem{tt.item}, history, map[string]decimal.Decimal{"EUR": decimal.RequireFromString("1.2")}, booked)
+			if revaluation.SkippedCount != tt.wantSkipped {
+				t.Fatalf("BuildFXRevaluation() skipped = %d, want %d", revaluation.SkippedCount, tt.wantSkipped)
+			}
+			if tt.wantSkipped > 0 {
+				return
+			}
+			if !tt.item.OriginalAmount.Equal(decimal.RequireFromString(tt.wantOriginal)) {
+				t.Errorf("BuildFXRevaluation() original amount = %s, want %s", tt.item.OriginalAmount, tt.wantOriginal)
+			}
+			if !revaluation.TotalGainLoss.Equal(decimal.RequireFromString(tt.wantGainLoss)) {
+				t.Errorf("BuildFXRevaluation() gain/loss = %s, want %s", revaluation.TotalGainLoss, tt.wantGainLoss)
+			}
+		})
+	}
+}
@@ -34,10 +34,12 @@ var (
 	ErrGroupInvitationExists     = errors.New("group invitation already exists")
 	ErrOverFunding               = errors.New("overfunding is not allowed, please check the amount")
 	ErrUserGroupMembershipExists = errors.New("user group membership already exists")
+	ErrGroupMembersMaxedOut      = errors.New("this group has reached its maximum member count")
 )
 
 type FinancialGroupManagerModel struct {
-	DB *database.Queries
+	DB   *database.Queries
+	Conn *sql.DB
 }
 
 // Group struct represents a group in the database
@@ -54,6 +56,10 @@ type Group struct {
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 	Version        int       `json:"version"`
+	IsDiscoverable bool      `json:"is_discoverable"`
+	// Archived groups are read-only, deleted groups stay archived until they are purged
+	ArchivedAt          *time.Time `json:"archived_at,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
 }
 
 // GroupGoal struct represents how we group our goals
@@ -106,12 +112,16 @@ type GroupTransactionWithGoalName struct {
 // GroupTransaction struct represents a group transaction in the database
 type GroupTransaction struct {
 	ID          int64           `json:"id"`
+	GroupID     int64           `json:"group_id,omitempty"`
 	GoalID      int64           `json:"goal_id"`
 	MemberID    int64           `json:"member_id"`
 	Amount      decimal.Decimal `json:"amount"`
 	Description string          `json:"description"`
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
+	// Only set in a group's data export, where settlements are listed with the contributions
+	TransactionType   string `json:"transaction_type,omitempty"`
+	RecipientMemberID int64  `json:"recipient_member_id,omitempty"`
 }
 
 // EnrichedExpense struct represents a group expense with additional information
@@ -123,14 +133,16 @@ type EnrichedExpense struct {
 
 // GroupExpense struct represents a group expense in the database
 type GroupExpense struct {
-	ID          int64           `json:"id"`          // Unique expense ID
-	GroupID     int64           `json:"group_id"`    // Reference to the group
-	MemberID    int64           `json:"member_id"`   // Reference to the member who made the expense
-	Amount      decimal.Decimal `json:"amount"`      // Amount of the expense
-	Description string          `json:"description"` // Optional description of the expense
-	Category    string          `json:"category"`    // Category of the expense (e.g., 'operations', 'purchase', etc.)
-	CreatedAt   time.Time       `json:"created_at"`  // Time when the expense was created
-	UpdatedAt   time.Time       `json:"updated_at"`  // Time when the expense was last updated
+	ID          int64                `json:"id"`                   // Unique expense ID
+	GroupID     int64                `json:"group_id"`             // Reference to the group
+	MemberID    int64                `json:"member_id"`            // Reference to the member who made the expense
+	Amount      decimal.Decimal      `json:"amount"`               // Amount of the expense
+	Description string               `json:"description"`          // Optional description of the expense
+	Category    string               `json:"category"`             // Category of the expense (e.g., 'operations', 'purchase', etc.)
+	CreatedAt   time.Time            `json:"created_at"`           // Time when the expense was created
+	UpdatedAt   time.Time            `json:"updated_at"`           // Time when the expense was last updated
+	SplitType   string               `json:"split_type,omitempty"` // How the expense is shared, empty when it is not split
+	Splits      []*GroupExpenseSplit `json:"splits,omitempty"`     // What each member owes of the expense
 }
 
 // Enriched Group struct represents a group with additional information
@@ -153,6 +165,9 @@ type DetailedGroup struct {
 	PendingInvitations     []*GroupInvitation
 	TotalGroupTransactions decimal.Decimal
 	TotalGroupExpenses     decimal.Decimal
+	Settlements            []*GroupSettlement
+	TotalGroupSettlements  decimal.Decimal
+	Budgets                []*GroupBudgetSummary
 }
 
 // SampleGroupGoal struct represents a sample group goal
@@ -164,13 +179,14 @@ type SampleGroupGoal struct {
 
 // GroupMember holds data for each member within a group.
 type GroupMember struct {
-	UserID                int64           `json:"user_id"`
-	FirstName             string          `json:"first_name"`
-	Role                  string          `json:"role"`
-	ProfileAvatarURL      string          `json:"profile_avatar_url"`
-	JoinDate              CustomTime1     `json:"join_date,omitempty"`
-	TransactionCount      int64           `json:"transaction_count,omitempty"`
-	TotalTransactioAmount decimal.Decimal `json:"total_transaction_amount,omitempty"`
+	UserID                int64            `json:"user_id"`
+	FirstName             string           `json:"first_name"`
+	Role                  string           `json:"role"`
+	ProfileAvatarURL      string           `json:"profile_avatar_url"`
+	JoinDate              CustomTime1      `json:"join_date,omitempty"`
+	TransactionCount      int64            `json:"transaction_count,omitempty"`
+	TotalTransactioAmount decimal.Decimal  `json:"total_transaction_amount,omitempty"`
+	Balance               *decimal.Decimal `json:"balance,omitempty"` // Only set on a detailed group, positive when the member is owed
 }
 
 // MapInvitationInvitationStatusTypeToConstant() maps the invitation status type to a constant
@@ -208,6 +224,9 @@ func ValidateGroupName(v *validator.Validator, name string) {
 func ValidateGroupPrivacy(v *validator.Validator, isPrivate bool) {
 	v.Check(reflect.TypeOf(isPrivate).Kind() == reflect.Bool, "is_private", "must be a boolean")
 }
+func ValidateGroupDiscoverability(v *validator.Validator, isPrivate, isDiscoverable bool) {
+	v.Check(!isDiscoverable || isPrivate, "is_discoverable", "only private groups can be made discoverable")
+}
 func ValidateGroupMaxMemberCount(v *validator.Validator, maxMemberCount int) {
 	v.Check(maxMemberCount > 0, "max_member_count", "must be greater than 0")
 	v.Check(maxMemberCount < 100, "max_member_count", "must be less than 100")
@@ -223,6 +242,7 @@ func ValidateGroupVersion(v *validator.Validator, version int) {
 func ValidateGroup(v *validator.Validator, group *Group) {
 	ValidateGroupName(v, group.Name)
 	ValidateGroupPrivacy(v, group.IsPrivate)
+	ValidateGroupDiscoverability(v, group.IsPrivate, group.IsDiscoverable)
 	ValidateGroupMaxMemberCount(v, group.MaxMemberCount)
 	ValidateGroupDescription(v, group.Description)
 }
@@ -231,6 +251,7 @@ func ValidateGroup(v *validator.Validator, group *Group) {
 func ValidateGroupUpdate(v *validator.Validator, group *Group) {
 	ValidateGroupName(v, group.Name)
 	ValidateGroupPrivacy(v, group.IsPrivate)
+	ValidateGroupDiscoverability(v, group.IsPrivate, group.IsDiscoverable)
 	ValidateGroupMaxMemberCount(v, group.MaxMemberCount)
 	ValidateGroupDescription(v, group.Description)
 	ValidateGroupVersion(v, group.Version)
@@ -450,6 +471,11 @@ func (m FinancialGroupManagerModel) GetDetailedGroupById(userID, groupID int64)
 	if err != nil {
 		return nil, err
 	}
+	// add how the group is doing against its budgets
+	detailedGroup.Budgets, err = m.GetGroupBudgetSummariesByGroupID(groupID)
+	if err != nil {
+		return nil, err
+	}
 	// we are good now
 	return detailedGroup, nil
 }
@@ -620,6 +646,7 @@ func (m FinancialGroupManagerModel) CreateNewUserGroup(userID int64, group *Grou
 		IsPrivate:      sql.NullBool{Bool: group.IsPrivate, Valid: true},
 		MaxMemberCount: sql.NullInt32{Int32: int32(group.MaxMemberCount), Valid: true},
 		Description:    sql.NullString{String: group.Description, Valid: true},
+		IsDiscoverable: group.IsDiscoverable,
 	})
 	if err != nil {
 		switch {
@@ -642,10 +669,10 @@ func (m FinancialGroupManagerModel) CreateNewUserGroup(userID int64, group *Grou
 }
 
 // UpdateUserGroup() updates the user group in the database
-// Only Admins can perform this, even though we will have a middleware for this, we
-// the update also includes the creator's user ID.
-// We expect the group ID, the creator's user ID, and the group struct to be passed in
-func (m FinancialGroupManagerModel) UpdateUserGroup(groupID, creatorUserID int64, group *Group) error {
+// Only Admins can perform this, even though the handlers check the group's permissions, the
+// update also checks that the updating user is an admin of the group.
+// We expect the group ID, the updating user's ID, and the group struct to be passed in
+func (m FinancialGroupManagerModel) UpdateUserGroup(groupID, userID int64, group *Group) error {
 	// get our context
 	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
 	defer cancel()
@@ -660,7 +687,9 @@ func (m FinancialGroupManagerModel) UpdateUserGroup(groupID, creatorUserID int64
 		LastActivityAt: sql.NullTime{Time: group.LastActivityAt, Valid: true},
 		ID:             groupID,
 		Version:        sql.NullInt32{Int32: int32(group.Version), Valid: true},
-		CreatorUserID:  sql.NullInt64{Int64: creatorUserID, Valid: true},
+		UserID:         sql.NullInt64{Int64: userID, Valid: true},
+		IsDiscoverable: group.IsDiscoverable,
+		Column11:       GroupPermissionRoles(GroupPermissionEditSettings),
 	})
 	if err != nil {
 		switch {
@@ -692,6 +721,7 @@ func (m FinancialGroupManagerModel) UpdateGroupUserRole(groupID, userID, updater
 		UserID:   sql.NullInt64{Int64: userID, Valid: true},
 		Role:     database.NullMembershipRole{MembershipRole: newRole, Valid: true},
 		UserID_2: sql.NullInt64{Int64: updaterUserID, Valid: true},
+		Column5:  GroupPermissionRoles(GroupPermissionChangeRoles),
 	})
 	if err != nil {
 		switch {
@@ -948,8 +978,27 @@ func (m FinancialGroupManagerModel) CreateNewGroupTransaction(userID int64, tran
 	return nil
 }
 
+// GetGroupTransactionByID() returns a group transaction by its ID
+func (m FinancialGroupManagerModel) GetGroupTransactionByID(transactionID int64) (*GroupTransaction, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	// get the transaction
+	transaction, err := m.DB.GetGroupTransactionByID(ctx, transactionID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	// we are good now
+	return populateTransactions(transaction), nil
+}
+
 // DeleteGroupTransaction() deletes a group transaction by its ID and member_id/user_id of
-// the person who created the transaction
+// the person who created the transaction, or of a moderator or admin of the group
 // We return the ID of the deleted transaction and an error especially for sql no rows
 func (m FinancialGroupManagerModel) DeleteGroupTransaction(userID, transactionID int64) (int64, error) {
 	// get our context
@@ -959,6 +1008,7 @@ func (m FinancialGroupManagerModel) DeleteGroupTransaction(userID, transactionID
 	deletedTransactionID, err := m.DB.DeleteGroupTransaction(ctx, database.DeleteGroupTransactionParams{
 		ID:       transactionID,
 		MemberID: sql.NullInt64{Int64: userID, Valid: true},
+		Column3:  GroupPermissionRoles(GroupPermissionDeleteOthersExpenses),
 	})
 	if err != nil {
 		switch {
@@ -996,12 +1046,40 @@ func (m FinancialGroupManagerModel) CheckIfGroupExistsAndUserIsMember(userID, gr
 	return nil
 }
 
+// GetGroupMembershipRole() returns the role a user holds in a group
+// If the user is not an accepted member of the group, ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) GetGroupMembershipRole(userID, groupID int64) (database.MembershipRole, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	// get the role
+	role, err := m.DB.GetGroupMembershipRole(ctx, database.GetGroupMembershipRoleParams{
+		GroupID: sql.NullInt64{Int64: groupID, Valid: true},
+		UserID:  sql.NullInt64{Int64: userID, Valid: true},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrGeneralRecordNotFound
+		default:
+			return "", err
+		}
+	}
+	// members without a role are plain members
+	if !role.Valid {
+		return GroupRoleMember, nil
+	}
+	return role.MembershipRole, nil
+}
+
 // CreateNewGroupExpense() creates a new group expense in the database
 // We take in pointers to a group expense and userID and return an error if any
 func (m FinancialGroupManagerModel) CreateNewGroupExpense(userID int64, expense *GroupExpense) error {
 	// get our context
 	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
 	defer cancel()
+	// the split, if any, is saved together with the expense
+	memberIDs, shares, amounts := groupExpenseSplitColumns(expense.Splits)
 	// insert the data
 	expenseDetail, err := m.DB.CreateNewGroupExpense(ctx, database.CreateNewGroupExpenseParams{
 		GroupID:     sql.NullInt64{Int64: expense.GroupID, Valid: true},
@@ -1009,6 +1087,10 @@ func (m FinancialGroupManagerModel) CreateNewGroupExpense(userID int64, expense
 		Amount:      expense.Amount.String(),
 		Description: sql.NullString{String: expense.Description, Valid: true},
 		Category:    sql.NullString{String: expense.Category, Valid: true},
+		SplitType:   database.NullGroupSplitTypeEnum{GroupSplitTypeEnum: database.GroupSplitTypeEnum(expense.SplitType), Valid: expense.SplitType != ""},
+		Column7:     memberIDs,
+		Column8:     shares,
+		Column9:     amounts,
 	})
 	if err != nil {
 		return err
@@ -1022,8 +1104,28 @@ func (m FinancialGroupManagerModel) CreateNewGroupExpense(userID int64, expense
 	return nil
 }
 
+// GetGroupExpenseByID() gets a group expense by its ID
+// We return ErrGeneralRecordNotFound if the expense does not exist
+func (m FinancialGroupManagerModel) GetGroupExpenseByID(expenseID int64) (*GroupExpense, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	// get the expense
+	expense, err := m.DB.GetGroupExpenseByID(ctx, expenseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	// we are good now
+	return populateExpenses(expense), nil
+}
+
 // DeleteGroupExpense() deletes a group expense by its ID and member_id/user_id of
-// the person who created the expense
+// the person who created the expense, or of a moderator or admin of the group
 // We return the ID of the deleted expense and an error especially for sql no rows
 func (m FinancialGroupManagerModel) DeleteGroupExpense(userID, expenseID int64) (int64, error) {
 	// get our context
@@ -1033,6 +1135,7 @@ func (m FinancialGroupManagerModel) DeleteGroupExpense(userID, expenseID int64)
 	deletedExpenseID, err := m.DB.DeleteGroupExpense(ctx, database.DeleteGroupExpenseParams{
 		ID:       expenseID,
 		MemberID: sql.NullInt64{Int64: userID, Valid: true},
+		Column3:  GroupPermissionRoles(GroupPermissionDeleteOthersExpenses),
 	})
 	if err != nil {
 		switch {
@@ -1058,6 +1161,7 @@ func (m FinancialGroupManagerModel) AdminDeleteGroupMember(adminID, groupID, mem
 		GroupID:  sql.NullInt64{Int64: groupID, Valid: true},
 		UserID:   sql.NullInt64{Int64: memberID, Valid: true},
 		UserID_2: sql.NullInt64{Int64: adminID, Valid: true},
+		Column4:  GroupPermissionRoles(GroupPermissionRemoveMembers),
 	})
 	if err != nil {
 		switch {
@@ -1127,6 +1231,18 @@ func populateExpenses(expenseRow interface{}) *GroupExpense {
 			CreatedAt:   expense.CreatedAt.Time,
 			UpdatedAt:   expense.UpdatedAt.Time,
 		}
+	case database.GroupExpense:
+		return &GroupExpense{
+			ID:          expense.ID,
+			GroupID:     expense.GroupID.Int64,
+			MemberID:    expense.MemberID.Int64,
+			Amount:      decimal.RequireFromString(expense.Amount),
+			Description: expense.Description.String,
+			Category:    expense.Category.String,
+			CreatedAt:   expense.CreatedAt.Time,
+			UpdatedAt:   expense.UpdatedAt.Time,
+			SplitType:   string(expense.SplitType.GroupSplitTypeEnum),
+		}
 	default:
 		return nil
 	}
@@ -1145,6 +1261,19 @@ func populateTransactions(transactionsRow interface{}) *GroupTransaction {
 			CreatedAt:   transaction.CreatedAt.Time,
 			UpdatedAt:   transaction.UpdatedAt.Time,
 		}
+	case database.GroupTransaction:
+		return &GroupTransaction{
+			ID:                transaction.ID,
+			GroupID:           transaction.GroupID.Int64,
+			GoalID:            transaction.GoalID.Int64,
+			MemberID:          transaction.MemberID.Int64,
+			Amount:            decimal.RequireFromString(transaction.Amount),
+			Description:       transaction.Description.String,
+			CreatedAt:         transaction.CreatedAt.Time,
+			UpdatedAt:         transaction.UpdatedAt.Time,
+			TransactionType:   string(transaction.TransactionType),
+			RecipientMemberID: transaction.RecipientMemberID.Int64,
+		}
 	default:
 		return nil
 	}
@@ -1309,7 +1438,7 @@ func populateEnrichedGroup(enrichedGroupRow interface{}) (*EnrichedGroup, error)
 func populateGroup(groupRow interface{}) *Group {
 	switch group := groupRow.(type) {
 	case database.Group:
-		return &Group{
+		return withGroupLifecycle(&Group{
 			ID:             group.ID,
 			CreatorUserID:  group.CreatorUserID.Int64,
 			GroupImageURL:  group.GroupImageUrl,
@@ -1322,9 +1451,10 @@ func populateGroup(groupRow interface{}) *Group {
 			CreatedAt:      group.CreatedAt.Time,
 			UpdatedAt:      group.UpdatedAt.Time,
 			Version:        int(group.Version.Int32),
-		}
+			IsDiscoverable: group.IsDiscoverable,
+		}, group.ArchivedAt, group.DeletionScheduledAt)
 	case database.GetAllGroupsCreatedByUserRow: // database.GetAllGroupsUserIsMemberOfRow
-		return &Group{
+		return withGroupLifecycle(&Group{
 			ID:             group.ID,
 			CreatorUserID:  group.CreatorUserID.Int64,
 			GroupImageURL:  group.GroupImageUrl,
@@ -1337,9 +1467,10 @@ func populateGroup(groupRow interface{}) *Group {
 			CreatedAt:      group.CreatedAt.Time,
 			UpdatedAt:      group.UpdatedAt.Time,
 			Version:        int(group.Version.Int32),
-		}
+			IsDiscoverable: group.IsDiscoverable,
+		}, group.ArchivedAt, group.DeletionScheduledAt)
 	case database.GetAllGroupsUserIsMemberOfRow:
-		return &Group{
+		return withGroupLifecycle(&Group{
 			ID:             group.ID,
 			CreatorUserID:  group.CreatorUserID.Int64,
 			GroupImageURL:  group.GroupImageUrl,
@@ -1352,9 +1483,10 @@ func populateGroup(groupRow interface{}) *Group {
 			CreatedAt:      group.CreatedAt.Time,
 			UpdatedAt:      group.UpdatedAt.Time,
 			Version:        int(group.Version.Int32),
-		}
+			IsDiscoverable: group.IsDiscoverable,
+		}, group.ArchivedAt, group.DeletionScheduledAt)
 	case database.GetDetailedGroupByIdRow:
-		return &Group{
+		return withGroupLifecycle(&Group{
 			ID:             group.ID,
 			CreatorUserID:  group.CreatorUserID.Int64,
 			GroupImageURL:  group.GroupImageUrl,
@@ -1367,9 +1499,10 @@ func populateGroup(groupRow interface{}) *Group {
 			CreatedAt:      group.CreatedAt.Time,
 			UpdatedAt:      group.UpdatedAt.Time,
 			Version:        int(group.Version.Int32),
-		}
+			IsDiscoverable: group.IsDiscoverable,
+		}, group.ArchivedAt, group.DeletionScheduledAt)
 	case database.GetAllPublicGroupsRow:
-		return &Group{
+		return withGroupLifecycle(&Group{
 			ID:             group.ID,
 			CreatorUserID:  group.CreatorUserID.Int64,
 			GroupImageURL:  group.GroupImageUrl,
@@ -1382,7 +1515,8 @@ func populateGroup(groupRow interface{}) *Group {
 			CreatedAt:      group.CreatedAt.Time,
 			UpdatedAt:      group.UpdatedAt.Time,
 			Version:        int(group.Version.Int32),
-		}
+			IsDiscoverable: group.IsDiscoverable,
+		}, group.ArchivedAt, group.DeletionScheduledAt)
 	default:
 		return nil
 	}
@@ -1491,11 +1625,24 @@ func populateDetailedGroup(groupDetails interface{}, userID int64) (*DetailedGro
 		if err != nil {
 			return nil, err
 		}
+		// get the settlements between members
+		var settlements []*GroupSettlement
+		// type assert settlements to byte
+		settlementsByte, ok := groupDetails.Settlements.([]byte)
+		if !ok {
+			return nil, ErrTypeConversionError
+		}
+		// unmarshal
+		err = json.Unmarshal(settlementsByte, &settlements)
+		if err != nil {
+			return nil, err
+		}
 		// populate our group
 		group := populateGroup(groupDetails)
 		// get the totals
 		totalGroupTransactions := decimal.RequireFromString(groupDetails.TotalGroupTransactions)
 		totalGroupExpenses := decimal.RequireFromString(groupDetails.TotalGroupExpenses)
+		totalGroupSettlements := decimal.RequireFromString(groupDetails.TotalGroupSettlements)
 		// we are good now
 		// if the user's role is an admin or moderator, we will return everything
 		// otherwise, we will return all except the pending invitations
@@ -1509,6 +1656,8 @@ func populateDetailedGroup(groupDetails interface{}, userID int64) (*DetailedGro
 				PendingInvitations:     pendingInvitations,
 				TotalGroupTransactions: totalGroupTransactions,
 				TotalGroupExpenses:     totalGroupExpenses,
+				Settlements:            settlements,
+				TotalGroupSettlements:  totalGroupSettlements,
 			}
 		} else {
 			detailedGroup = &DetailedGroup{
@@ -1517,6 +1666,8 @@ func populateDetailedGroup(groupDetails interface{}, userID int64) (*DetailedGro
 				GroupMembers:           groupMembers,
 				TotalGroupTransactions: totalGroupTransactions,
 				TotalGroupExpenses:     totalGroupExpenses,
+				Settlements:            settlements,
+				TotalGroupSettlements:  totalGroupSettlements,
 			}
 		}
 		return detailedGroup, nil
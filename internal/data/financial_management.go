@@ -813,6 +813,33 @@ func populateTrackedGoal(trackedGoalRow interface{}) *TrackedGoal {
 	}
 }
 
+// CreateNewGoalTracking() inserts a single contribution into the goal tracking table
+// The goal's current amount is updated by the goal tracking trigger.
+// This is used when money is allocated to a goal outside of the monthly tracking e.g
+// when a recurring income is posted.
+func (m FinancialManagerModel) CreateNewGoalTracking(userID int64, trackedGoal *TrackedGoal) error {
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinManDBContextTimeout)
+	defer cancel()
+	goalTracking, err := m.DB.CreateNewGoalTracking(ctx, database.CreateNewGoalTrackingParams{
+		UserID:            userID,
+		GoalID:            sql.NullInt64{Int64: trackedGoal.GoalID, Valid: true},
+		TrackingDate:      trackedGoal.TrackingDate,
+		ContributedAmount: trackedGoal.ContributedAmount.String(),
+		TrackingType:      trackedGoal.TrackingType,
+	})
+	if err != nil {
+		return err
+	}
+	// fill in the tracked goal with the returned details
+	trackedGoal.ID = goalTracking.ID
+	trackedGoal.UserID = userID
+	trackedGoal.CreatedAt = goalTracking.CreatedAt.Time
+	trackedGoal.UpdatedAt = goalTracking.UpdatedAt.Time
+	trackedGoal.TruncatedTrackingDate = goalTracking.TruncatedTrackingDate.Time
+	// everything went well
+	return nil
+}
+
 // ============================================================================================================
 // Goal Tracking
 // ============================================================================================================
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/Blue-Davinci/OptiVest/internal/database"
@@ -13,7 +14,8 @@ import (
 )
 
 type FinancialTrackingModel struct {
-	DB *database.Queries
+	DB   *database.Queries
+	Conn *sql.DB
 }
 
 const (
@@ -23,6 +25,29 @@ const (
 	FinTrackEnumRecurenceYearly     = database.RecurrenceIntervalEnumYearly
 )
 
+const (
+	DebtCompoundingDaily   = database.DebtCompoundingFrequencyEnumDaily
+	DebtCompoundingMonthly = database.DebtCompoundingFrequencyEnumMonthly
+	DebtInterestRateAPR    = database.DebtInterestRateTypeEnumApr
+	DebtInterestRateAPY    = database.DebtInterestRateTypeEnumApy
+)
+
+const (
+	DebtPayoffStrategySnowball  = database.DebtPayoffStrategyEnumSnowball
+	DebtPayoffStrategyAvalanche = database.DebtPayoffStrategyEnumAvalanche
+	DebtPayoffStrategyCustom    = database.DebtPayoffStrategyEnumCustom
+)
+
+const (
+	AmortizationStatusPaid      = "paid"
+	AmortizationStatusPartial   = "partial"
+	AmortizationStatusMissed    = "missed"
+	AmortizationStatusProjected = "projected"
+	DebtScheduleStatusAhead     = "ahead"
+	DebtScheduleStatusBehind    = "behind"
+	DebtScheduleStatusOnTrack   = "on_track"
+)
+
 const (
 	RedisFinTrackDebtSearchPrefix        = "fintrack_debt_search"
 	RedisFinTrackDebtPaymentSearchPrefix = "fintrack_debt_payment_search"
@@ -31,28 +56,43 @@ const (
 var (
 	DefaultFinTrackDBContextTimeout = 5 * time.Second
 	DefaultFinTrackRedisDebtTTL     = 10 * time.Minute
+	DefaultDebtPayoffMaxMonths      = 600 // 50 years, simulations that take longer are considered unpayable
 )
 
 var (
 	ErrInvalidRecurringExpenseTime = errors.New("invalid recurring expense time")
+	ErrRecurringExpenseEnded       = errors.New("recurring expense has already ended")
 	ErrDuplicateRecurringExpense   = errors.New("recurring expense already exists")
+	ErrDuplicateRecurringIncome    = errors.New("recurring income already exists")
 	ErrDuplicateDebt               = errors.New("debt with a similar description already exists")
 	ErrInvalidRemainingBalance     = errors.New("remaining balance cannot be less than zero, please check your payment amount")
+	ErrInvalidDebtPayoffStrategy   = errors.New("invalid debt payoff strategy")
+	ErrInvalidDebtPayoffOrder      = errors.New("custom order contains a debt that does not exist")
+	ErrInsufficientPayoffBudget    = errors.New("monthly budget must cover the minimum payments of all debts")
+	ErrDebtPayoffNotPossible       = errors.New("debts cannot be paid off with the given monthly budget")
+	ErrDebtNeverAmortizes          = errors.New("minimum payment does not cover the interest of the debt")
+	ErrInvalidDebtCompounding      = errors.New("invalid compounding frequency")
+	ErrInvalidDebtInterestRateType = errors.New("invalid interest rate type")
+	ErrDuplicateDebtLateFee        = errors.New("a late fee has already been charged for this payment")
 )
 
 // Represents an expense
 type Expense struct {
-	ID           int64           `json:"id"`
-	UserID       int64           `json:"user_id"`
-	BudgetID     int64           `json:"budget_id"`
-	Name         string          `json:"name"`
-	Category     string          `json:"category"`
-	Amount       decimal.Decimal `json:"amount"`
-	IsRecurring  bool            `json:"is_recurring"`
-	Description  string          `json:"description"`
-	DateOccurred time.Time       `json:"date_occurred"`
-	CreatedAt    time.Time       `json:"created_at"`
-	UpdatedAt    time.Time       `json:"updated_at"`
+	ID            int64           `json:"id"`
+	UserID        int64           `json:"user_id"`
+	BudgetID      int64           `json:"budget_id"`
+	Name          string          `json:"name"`
+	Category      string          `json:"category"`
+	Amount        decimal.Decimal `json:"amount"`
+	IsRecurring   bool            `json:"is_recurring"`
+	Description   string          `json:"description"`
+	DateOccurred  time.Time       `json:"date_occurred"`
+	AccountID     int64           `json:"account_id,omitempty"`
+	IsCleared     bool            `json:"is_cleared"`
+	Tags          []string        `json:"tags,omitempty"`
+	TaxCategories []string        `json:"tax_categories,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
 }
 
 // EnrichedRecurringExpense represents ta recurring expense with various totals and budget name
@@ -64,17 +104,26 @@ type EnrichedRecurringExpense struct {
 
 // Represents a recurring expense
 type RecurringExpense struct {
-	ID                 int64                           `json:"id"`                  // Unique ID for the recurring expense
-	UserID             int64                           `json:"user_id"`             // Reference to the user
-	BudgetID           int64                           `json:"budget_id"`           // Link to the budget
-	Amount             decimal.Decimal                 `json:"amount"`              // Amount of the recurring expense
-	Name               string                          `json:"name"`                // Name of the expense
-	Description        string                          `json:"description"`         // Description of the expense
-	RecurrenceInterval database.RecurrenceIntervalEnum `json:"recurrence_interval"` // Interval type (e.g., daily, weekly, monthly, etc.)
-	ProjectedAmount    decimal.Decimal                 `json:"projected_amount"`    // The total amount of the expense per month
-	NextOccurrence     time.Time                       `json:"next_occurrence"`     // The next date the expense should be added
-	CreatedAt          time.Time                       `json:"created_at"`          // Creation timestamp
-	UpdatedAt          time.Time                       `json:"updated_at"`          // Last updated timestamp
+	ID                      int64                           `json:"id"`                  // Unique ID for the recurring expense
+	UserID                  int64                           `json:"user_id"`             // Reference to the user
+	BudgetID                int64                           `json:"budget_id"`           // Link to the budget
+	Amount                  decimal.Decimal                 `json:"amount"`              // Amount of the recurring expense
+	Name                    string                          `json:"name"`                // Name of the expense
+	Description             string                          `json:"description"`         // Description of the expense
+	RecurrenceInterval      database.RecurrenceIntervalEnum `json:"recurrence_interval"` // Interval type (e.g., daily, weekly, monthly, etc.)
+	ProjectedAmount         decimal.Decimal                 `json:"projected_amount"`    // The total amount of the expense per month
+	NextOccurrence          time.Time                       `json:"next_occurrence"`     // The next date the expense should be added
+	CreatedAt               time.Time                       `json:"created_at"`          // Creation timestamp
+	UpdatedAt               time.Time                       `json:"updated_at"`          // Last updated timestamp
+	IntervalCount           int32                           `json:"interval_count"`      // Number of intervals between occurrences (e.g every 2 weeks)
+	DayOfMonth              int32                           `json:"day_of_month"`        // Day of the month for monthly and yearly rules
+	StartDate               time.Time                       `json:"start_date"`          // The original date the schedule is anchored to
+	LastOccurrence          time.Time                       `json:"last_occurrence"`     // The last occurrence that was posted or skipped
+	EndDate                 time.Time                       `json:"end_date"`            // Optional date after which the schedule ends
+	MaxOccurrences          int32                           `json:"max_occurrences"`     // Optional number of occurrences after which the schedule ends
+	OccurrenceCount         int32                           `json:"occurrence_count"`    // Number of occurrences that have elapsed (posted or skipped)
+	IsPaused                bool                            `json:"is_paused"`           // Paused schedules are not processed
+	FirstOccurrenceRecorded bool                            `json:"-"`                   // The first occurrence is already an expense i.e a converted subscription
 }
 
 // EnrichedIncome represents an income with its total amount, total amount in original currency and exchange rate
@@ -95,10 +144,39 @@ type Income struct {
 	ExchangeRate         decimal.Decimal `json:"exchange_rate"`
 	Description          string          `json:"description"`
 	DateReceived         time.Time       `json:"date_received"`
+	AccountID            int64           `json:"account_id,omitempty"`
+	IsCleared            bool            `json:"is_cleared"`
+	Tags                 []string        `json:"tags,omitempty"`
+	TaxCategories        []string        `json:"tax_categories,omitempty"`
 	CreatedAt            time.Time       `json:"created_at"`
 	UpdatedAt            time.Time       `json:"updated_at"`
 }
 
+// EnrichedRecurringIncome represents a recurring income alongside the user's default currency
+type EnrichedRecurringIncome struct {
+	RecurringIncome  *RecurringIncome `json:"recurring_income"`
+	UserCurrencyCode string           `json:"user_currency_code"`
+}
+
+// Represents a recurring income such as a salary, rent received or dividends
+type RecurringIncome struct {
+	ID                       int64                           `json:"id"`                         // Unique ID for the recurring income
+	UserID                   int64                           `json:"user_id"`                    // Reference to the user
+	Source                   string                          `json:"source"`                     // Source of the income
+	OriginalCurrencyCode     string                          `json:"original_currency_code"`     // Currency the income is received in
+	AmountOriginal           decimal.Decimal                 `json:"amount_original"`            // Amount in the original currency
+	Description              string                          `json:"description"`                // Description of the income
+	RecurrenceInterval       database.RecurrenceIntervalEnum `json:"recurrence_interval"`        // Interval type (e.g., daily, weekly, monthly, etc.)
+	NextOccurrence           time.Time                       `json:"next_occurrence"`            // The next date the income should be posted
+	DayOfMonth               int32                           `json:"day_of_month"`               // Day of the month monthly and yearly incomes are anchored to
+	EndDate                  time.Time                       `json:"end_date,omitempty"`         // Optional date after which no more incomes are posted
+	GoalID                   int64                           `json:"goal_id,omitempty"`          // Optional goal to allocate a part of each posting to
+	GoalAllocationPercentage decimal.Decimal                 `json:"goal_allocation_percentage"` // Percentage of each posting allocated to the goal
+	LastPostedAt             time.Time                       `json:"last_posted_at,omitempty"`   // The last date an income was posted
+	CreatedAt                time.Time                       `json:"created_at"`                 // Creation timestamp
+	UpdatedAt                time.Time                       `json:"updated_at"`                 // Last updated timestamp
+}
+
 // DebtWithPayments represents a debt with its payments
 type DebtWithPayments struct {
 	Debt                   *Debt                  `json:"debt"`
@@ -111,23 +189,38 @@ type DebtWithPayments struct {
 
 // Represents a Debt
 type Debt struct {
-	ID                     int64           `json:"id"`
-	UserID                 int64           `json:"user_id"`
-	Name                   string          `json:"name"`
-	Amount                 decimal.Decimal `json:"amount"`
-	RemainingBalance       decimal.Decimal `json:"remaining_balance"`
-	InterestRate           decimal.Decimal `json:"interest_rate"`
-	Description            string          `json:"description,omitempty"`
-	DueDate                time.Time       `json:"due_date"`
-	MinimumPayment         decimal.Decimal `json:"minimum_payment"`
-	CreatedAt              time.Time       `json:"created_at"`
-	UpdatedAt              time.Time       `json:"updated_at"`
-	NextPaymentDate        time.Time       `json:"next_payment_date"`
-	EstimatedPayoffDate    time.Time       `json:"estimated_payoff_date,omitempty"`
-	AccruedInterest        decimal.Decimal `json:"accrued_interest"`
-	InterestLastCalculated time.Time       `json:"interest_last_calculated"`
-	LastPaymentDate        time.Time       `json:"last_payment_date,omitempty"`
-	TotalInterestPaid      decimal.Decimal `json:"total_interest_paid"`
+	ID                     int64                                 `json:"id"`
+	UserID                 int64                                 `json:"user_id"`
+	Name                   string                                `json:"name"`
+	Amount                 decimal.Decimal                       `json:"amount"`
+	RemainingBalance       decimal.Decimal                       `json:"remaining_balance"`
+	InterestRate           decimal.Decimal                       `json:"interest_rate"`
+	Description            string                                `json:"description,omitempty"`
+	DueDate                time.Time                             `json:"due_date"`
+	MinimumPayment         decimal.Decimal                       `json:"minimum_payment"`
+	CreatedAt              time.Time                             `json:"created_at"`
+	UpdatedAt              time.Time                             `json:"updated_at"`
+	NextPaymentDate        time.Time                             `json:"next_payment_date"`
+	EstimatedPayoffDate    time.Time                             `json:"estimated_payoff_date,omitempty"`
+	AccruedInterest        decimal.Decimal                       `json:"accrued_interest"`
+	InterestLastCalculated time.Time                             `json:"interest_last_calculated"`
+	LastPaymentDate        time.Time                             `json:"last_payment_date,omitempty"`
+	TotalInterestPaid      decimal.Decimal                       `json:"total_interest_paid"`
+	CompoundingFrequency   database.DebtCompoundingFrequencyEnum `json:"compounding_frequency"`
+	InterestRateType       database.DebtInterestRateTypeEnum     `json:"interest_rate_type"`
+	LateFee                decimal.Decimal                       `json:"late_fee"`
+	GracePeriodDays        int32                                 `json:"grace_period_days"`
+	LastOverdueDueDate     time.Time                             `json:"last_overdue_due_date,omitempty"`
+}
+
+// DebtLateFee represents a late fee charged on a missed debt payment
+type DebtLateFee struct {
+	ID            int64           `json:"id"`
+	DebtID        int64           `json:"debt_id"`
+	UserID        int64           `json:"user_id"`
+	Amount        decimal.Decimal `json:"amount"`
+	MissedDueDate time.Time       `json:"missed_due_date"`
+	CreatedAt     time.Time       `json:"created_at"`
 }
 
 // Enriched Debt Payment returns a debt payment with the debt details
@@ -147,9 +240,87 @@ type DebtRepayment struct {
 	PaymentDate      time.Time       `json:"payment_date"`
 	InterestPayment  decimal.Decimal `json:"interest_payment"`
 	PrincipalPayment decimal.Decimal `json:"principal_payment"`
+	AccountID        int64           `json:"account_id,omitempty"`
 	CreatedAt        time.Time       `json:"created_at"`
 }
 
+// DebtPayoffPlan represents a user's saved debt payoff plan
+type DebtPayoffPlan struct {
+	ID                  int64                           `json:"id"`
+	UserID              int64                           `json:"user_id"`
+	Strategy            database.DebtPayoffStrategyEnum `json:"strategy"`
+	MonthlyBudget       decimal.Decimal                 `json:"monthly_budget"`
+	CustomOrder         []int64                         `json:"custom_order"`
+	ProjectedPayoffDate time.Time                       `json:"projected_payoff_date"`
+	TotalMonths         int32                           `json:"total_months"`
+	TotalInterest       decimal.Decimal                 `json:"total_interest"`
+	CreatedAt           time.Time                       `json:"created_at"`
+	UpdatedAt           time.Time                       `json:"updated_at"`
+}
+
+// DebtPayoffSchedule represents the projected payoff of a single debt within a simulation
+type DebtPayoffSchedule struct {
+	DebtID          int64           `json:"debt_id"`
+	Name            string          `json:"name"`
+	PayoffOrder     int             `json:"payoff_order"`
+	StartingBalance decimal.Decimal `json:"starting_balance"`
+	InterestRate    decimal.Decimal `json:"interest_rate"`
+	MonthsToPayoff  int             `json:"months_to_payoff"`
+	PayoffDate      time.Time       `json:"payoff_date"`
+	InterestPaid    decimal.Decimal `json:"interest_paid"`
+	TotalPaid       decimal.Decimal `json:"total_paid"`
+}
+
+// DebtPayoffSimulation represents the month by month simulation of a payoff strategy
+type DebtPayoffSimulation struct {
+	Strategy      database.DebtPayoffStrategyEnum `json:"strategy"`
+	MonthlyBudget decimal.Decimal                 `json:"monthly_budget"`
+	TotalMonths   int                             `json:"total_months"`
+	PayoffDate    time.Time                       `json:"payoff_date"`
+	TotalInterest decimal.Decimal                 `json:"total_interest"`
+	TotalPaid     decimal.Decimal                 `json:"total_paid"`
+	Debts         []*DebtPayoffSchedule           `json:"debts"`
+}
+
+// DebtPayoffComparison compares the simulations of the different payoff strategies
+type DebtPayoffComparison struct {
+	Simulations         []*DebtPayoffSimulation         `json:"simulations"`
+	RecommendedStrategy database.DebtPayoffStrategyEnum `json:"recommended_strategy"`
+	InterestSavings     decimal.Decimal                 `json:"interest_savings"`
+	MonthsSaved         int                             `json:"months_saved"`
+}
+
+// AmortizationEntry represents a single payment in a debt's amortization schedule.
+// Past entries follow the debt's original schedule and carry the actual payments made in
+// their period, while future entries are projected from the current remaining balance.
+type AmortizationEntry struct {
+	PaymentNumber    int              `json:"payment_number"`
+	PaymentDate      time.Time        `json:"payment_date"`
+	PaymentAmount    decimal.Decimal  `json:"payment_amount"`
+	InterestPortion  decimal.Decimal  `json:"interest_portion"`
+	PrincipalPortion decimal.Decimal  `json:"principal_portion"`
+	RemainingBalance decimal.Decimal  `json:"remaining_balance"`
+	ActualPayment    decimal.Decimal  `json:"actual_payment"`
+	ActualPrincipal  decimal.Decimal  `json:"actual_principal"`
+	ActualBalance    *decimal.Decimal `json:"actual_balance,omitempty"`
+	Status           string           `json:"status"`
+}
+
+// DebtAmortizationSchedule represents the full amortization table of a debt
+type DebtAmortizationSchedule struct {
+	DebtID                 int64                `json:"debt_id"`
+	Name                   string               `json:"name"`
+	InterestRate           decimal.Decimal      `json:"interest_rate"`
+	MinimumPayment         decimal.Decimal      `json:"minimum_payment"`
+	ScheduledBalance       decimal.Decimal      `json:"scheduled_balance"`
+	ActualBalance          decimal.Decimal      `json:"actual_balance"`
+	Variance               decimal.Decimal      `json:"variance"`
+	ScheduleStatus         string               `json:"schedule_status"`
+	ProjectedPayoffDate    time.Time            `json:"projected_payoff_date"`
+	TotalProjectedInterest decimal.Decimal      `json:"total_projected_interest"`
+	Entries                []*AmortizationEntry `json:"entries"`
+}
+
 // Map a recurring expense to a corresponding constant
 func (expense *FinancialTrackingModel) MapToDatabaseRecurringExpense(interval string) (database.RecurrenceIntervalEnum, error) {
 	switch interval {
@@ -166,51 +337,172 @@ func (expense *FinancialTrackingModel) MapToDatabaseRecurringExpense(interval st
 	}
 }
 
+// MapToRecurrenceRule() maps a recurrence interval and an optional interval count to a frequency
+// and the number of intervals between occurrences. On top of the database intervals we support
+// "biweekly" (every 2 weeks) and "quarterly" (every 3 months), which are stored as multiples
+// of the weekly and monthly frequencies respectively.
+func (expense *FinancialTrackingModel) MapToRecurrenceRule(interval string, intervalCount int32) (database.RecurrenceIntervalEnum, int32, error) {
+	if intervalCount < 1 {
+		intervalCount = 1
+	}
+	switch interval {
+	case "biweekly":
+		return database.RecurrenceIntervalEnumWeekly, intervalCount * 2, nil
+	case "quarterly":
+		return database.RecurrenceIntervalEnumMonthly, intervalCount * 3, nil
+	default:
+		frequency, err := expense.MapToDatabaseRecurringExpense(interval)
+		if err != nil {
+			return "", 0, err
+		}
+		return frequency, intervalCount, nil
+	}
+}
+
 // Per month, calculate the total amount of an expense based on the recurrence interval
 // If a recurring expense is set to monthly, the total amount will be the amount of the expense
 // If a recurring expense is set to weekly, the total amount will be the amount of the expense * 4
 // If a recurring expense is set to daily, the total amount will be the amount of the expense * number of days in a month
+// The projection is then divided by the interval count, so an expense every 2 weeks is the weekly projection / 2
 func (re *RecurringExpense) CalculateTotalAmountPerMonth() decimal.Decimal {
+	intervalCount := decimal.NewFromInt32(max(re.IntervalCount, 1))
 	switch re.RecurrenceInterval {
 	case database.RecurrenceIntervalEnumDaily:
-		return re.Amount.Mul(decimal.NewFromFloat(30)) // Monthly projection
+		return re.Amount.Mul(decimal.NewFromFloat(30)).Div(intervalCount) // Monthly projection
 	case database.RecurrenceIntervalEnumWeekly:
-		return re.Amount.Mul(decimal.NewFromFloat(4)) // Monthly projection
+		return re.Amount.Mul(decimal.NewFromFloat(4)).Div(intervalCount) // Monthly projection
 	case database.RecurrenceIntervalEnumMonthly:
-		return re.Amount
+		return re.Amount.Div(intervalCount)
 	case database.RecurrenceIntervalEnumYearly:
-		return re.Amount.Div(decimal.NewFromFloat(12)) // Monthly projection
+		return re.Amount.Div(decimal.NewFromFloat(12)).Div(intervalCount) // Monthly projection
 	default:
 		return decimal.Zero
 	}
 }
 
-// Calculate the next occurrence of a recurring expense
-// We will get the start date and frequency of the expense
-// We will then calculate the next occurrence based on the frequency
+// InitializeSchedule() anchors a new recurring expense to its start date.
+// When the schedule starts today or earlier, the first occurrence is posted on the start date by the
+// insert trigger, so we count it as elapsed and move the next occurrence one interval forward.
+// A schedule starting after today has nothing elapsed yet, its first occurrence is the start date
+// and is posted by the scheduler.
+// Monthly and yearly rules without a day of the month are pinned to the start date's day.
+func (re *RecurringExpense) InitializeSchedule(startDate, today time.Time) {
+	re.StartDate = startDate
+	if re.IntervalCount < 1 {
+		re.IntervalCount = 1
+	}
+	if re.DayOfMonth == 0 && (re.RecurrenceInterval == database.RecurrenceIntervalEnumMonthly || re.RecurrenceInterval == database.RecurrenceIntervalEnumYearly) {
+		re.DayOfMonth = int32(startDate.Day())
+	}
+	if startDate.After(today) {
+		re.LastOccurrence = time.Time{}
+		re.OccurrenceCount = 0
+		re.NextOccurrence = startDate
+		return
+	}
+	re.LastOccurrence = startDate
+	re.OccurrenceCount = 1
+	re.NextOccurrence = re.occurrenceAfter(startDate)
+}
+
+// CalculateNextOccurrence() marks the current next occurrence as elapsed and moves the schedule
+// forward by one interval. The next occurrence is calculated from the previous occurrence and not
+// from time.Now(), so that schedules do not drift when the cron runs late.
 func (re *RecurringExpense) CalculateNextOccurrence() {
-	now := time.Now()
-	switch re.RecurrenceInterval {
+	re.LastOccurrence = re.NextOccurrence
+	re.OccurrenceCount++
+	re.NextOccurrence = re.occurrenceAfter(re.LastOccurrence)
+}
+
+// RecalculateNextOccurrence() recalculates the next occurrence from the last elapsed occurrence.
+// It is used when the recurrence rule of an existing schedule changes.
+// Nothing has elapsed on a schedule that has not started yet, so it still first occurs on its start date.
+func (re *RecurringExpense) RecalculateNextOccurrence() {
+	if re.LastOccurrence.IsZero() {
+		re.NextOccurrence = re.StartDate
+		return
+	}
+	re.NextOccurrence = re.occurrenceAfter(re.LastOccurrence)
+}
+
+// FastForward() moves the schedule past every occurrence before the provided date without posting them.
+// This is used when resuming a paused schedule so that the occurrences missed while paused are skipped.
+func (re *RecurringExpense) FastForward(until time.Time) {
+	for re.NextOccurrence.Before(until) && !re.HasEnded() {
+		re.CalculateNextOccurrence()
+	}
+}
+
+// HasEnded() checks whether a recurring expense has reached its end date or its maximum occurrences
+func (re *RecurringExpense) HasEnded() bool {
+	if !re.EndDate.IsZero() && re.NextOccurrence.After(re.EndDate) {
+		return true
+	}
+	return re.MaxOccurrences > 0 && re.OccurrenceCount >= re.MaxOccurrences
+}
+
+// occurrenceAfter() returns the occurrence that follows the provided occurrence
+func (re *RecurringExpense) occurrenceAfter(occurrence time.Time) time.Time {
+	return calculateRecurrence(occurrence, re.RecurrenceInterval, re.IntervalCount, re.DayOfMonth)
+}
+
+// calculateRecurrence() returns the occurrence that follows from, given a frequency, the number
+// of intervals between occurrences and an optional day of the month.
+// For monthly and yearly rules, the day of the month is clamped to the last day of shorter months,
+// so a schedule on the 31st occurs on the 30th of April and the 28th/29th of February, and goes
+// back to the 31st in longer months.
+func calculateRecurrence(from time.Time, frequency database.RecurrenceIntervalEnum, intervalCount, dayOfMonth int32) time.Time {
+	if intervalCount < 1 {
+		intervalCount = 1
+	}
+	switch frequency {
 	case database.RecurrenceIntervalEnumDaily:
-		re.NextOccurrence = now.Add(24 * time.Hour)
+		return from.AddDate(0, 0, int(intervalCount))
 	case database.RecurrenceIntervalEnumWeekly:
-		re.NextOccurrence = now.Add(7 * 24 * time.Hour)
+		return from.AddDate(0, 0, 7*int(intervalCount))
 	case database.RecurrenceIntervalEnumMonthly:
-		re.NextOccurrence = now.AddDate(0, 1, 0)
+		return addMonthsClamped(from, int(intervalCount), dayOfMonth)
 	case database.RecurrenceIntervalEnumYearly:
-		re.NextOccurrence = now.AddDate(1, 0, 0)
+		return addMonthsClamped(from, 12*int(intervalCount), dayOfMonth)
+	default:
+		return from
 	}
 }
+
+// addMonthsClamped() adds months to a date, placing the result on the provided day of the month
+// or the last day of the month if the month is shorter. A day of 0 keeps the day of the date.
+func addMonthsClamped(from time.Time, months int, dayOfMonth int32) time.Time {
+	day := int(dayOfMonth)
+	if day < 1 {
+		day = from.Day()
+	}
+	// the first day of the target month, time.Date normalizes month overflow
+	firstOfMonth := time.Date(from.Year(), from.Month()+time.Month(months), 1, from.Hour(), from.Minute(), from.Second(), from.Nanosecond(), from.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
 func ValidateNextOccurrence(v *validator.Validator, nextOccurrence time.Time) {
-	v.Check(nextOccurrence.Before(time.Now()), "next_occurrence", "cannot be in the past")
+	v.Check(!nextOccurrence.Before(time.Now().Truncate(24*time.Hour)), "next_occurrence", "cannot be in the past")
 }
 
 // validate a recurring expense
+// A day_of_month of 0 means monthly and yearly rules use the start date's day
 func ValidateRecurringExpense(v *validator.Validator, expense *RecurringExpense) {
 	ValidateAmount(v, expense.Amount, "amount")
 	ValidateBudgetDescription(v, expense.Description)
-	ValidateNextOccurrence(v, expense.NextOccurrence)
+	// paused schedules keep their next occurrence until they are resumed
+	if !expense.IsPaused {
+		ValidateNextOccurrence(v, expense.NextOccurrence)
+	}
 	ValidateName(v, expense.Name, "name")
+	v.Check(expense.IntervalCount >= 1 && expense.IntervalCount <= 365, "interval_count", "must be between 1 and 365")
+	v.Check(expense.DayOfMonth >= 0 && expense.DayOfMonth <= 31, "day_of_month", "must be between 1 and 31, or 0 to use the start date's day")
+	v.Check(expense.MaxOccurrences >= 0, "max_occurrences", "cannot be negative")
+	v.Check(expense.EndDate.IsZero() || !expense.EndDate.Before(expense.StartDate), "end_date", "cannot be before the start date")
 }
 
 // validate expense
@@ -231,12 +523,509 @@ func ValidateIncome(v *validator.Validator, income *Income) {
 	ValidateName(v, income.OriginalCurrencyCode, "original_currency_code")
 }
 
+// AnchorSchedule() pins monthly and yearly recurring incomes to the day of the month of their
+// next occurrence. It is called whenever the next occurrence or the interval is set by the user.
+func (ri *RecurringIncome) AnchorSchedule() {
+	ri.DayOfMonth = 0
+	if ri.RecurrenceInterval == database.RecurrenceIntervalEnumMonthly || ri.RecurrenceInterval == database.RecurrenceIntervalEnumYearly {
+		ri.DayOfMonth = int32(ri.NextOccurrence.Day())
+	}
+}
+
+// CalculateNextOccurrence() moves the next occurrence of a recurring income forward by
+// one interval. We advance from the current next occurrence rather than from time.Now() so that
+// postings do not drift when the cron runs late, and monthly and yearly incomes go back to their
+// anchored day after a shorter month.
+func (ri *RecurringIncome) CalculateNextOccurrence() {
+	ri.NextOccurrence = calculateRecurrence(ri.NextOccurrence, ri.RecurrenceInterval, 1, ri.DayOfMonth)
+}
+
+// CalculateGoalAllocation() returns the part of an amount that should be allocated to the
+// linked goal. If no goal is linked, we return zero.
+func (ri *RecurringIncome) CalculateGoalAllocation(amount decimal.Decimal) decimal.Decimal {
+	if ri.GoalID == 0 || !ri.GoalAllocationPercentage.IsPositive() {
+		return decimal.Zero
+	}
+	return amount.Mul(ri.GoalAllocationPercentage).Div(decimal.NewFromInt(100)).Round(2)
+}
+
+// HasEnded() checks whether the next occurrence of a recurring income is past its end date
+func (ri *RecurringIncome) HasEnded() bool {
+	return !ri.EndDate.IsZero() && ri.NextOccurrence.After(ri.EndDate)
+}
+
+// validate a recurring income
+func ValidateRecurringIncome(v *validator.Validator, income *RecurringIncome) {
+	ValidateAmount(v, income.AmountOriginal, "amount_original")
+	ValidateBudgetDescription(v, income.Description)
+	ValidateName(v, income.Source, "source")
+	ValidateName(v, income.OriginalCurrencyCode, "original_currency_code")
+	v.Check(!income.NextOccurrence.IsZero(), "next_occurrence", "must be provided")
+	v.Check(income.EndDate.IsZero() || !income.EndDate.Before(income.NextOccurrence), "end_date", "cannot be before the next occurrence")
+	v.Check(!income.GoalAllocationPercentage.IsNegative(), "goal_allocation_percentage", "cannot be negative")
+	v.Check(income.GoalAllocationPercentage.LessThanOrEqual(decimal.NewFromInt(100)), "goal_allocation_percentage", "cannot be more than 100")
+	v.Check(income.GoalID != 0 || income.GoalAllocationPercentage.IsZero(), "goal_id", "must be provided when allocating to a goal")
+}
+
+// MapToDebtPayoffStrategy() maps a strategy string to its corresponding database constant
+func (m *FinancialTrackingModel) MapToDebtPayoffStrategy(strategy string) (database.DebtPayoffStrategyEnum, error) {
+	switch strategy {
+	case "snowball":
+		return DebtPayoffStrategySnowball, nil
+	case "avalanche":
+		return DebtPayoffStrategyAvalanche, nil
+	case "custom":
+		return DebtPayoffStrategyCustom, nil
+	default:
+		return "", ErrInvalidDebtPayoffStrategy
+	}
+}
+
+// MapToDebtCompoundingFrequency() maps a compounding frequency string to its corresponding database constant
+func (m *FinancialTrackingModel) MapToDebtCompoundingFrequency(frequency string) (database.DebtCompoundingFrequencyEnum, error) {
+	switch frequency {
+	case "daily":
+		return DebtCompoundingDaily, nil
+	case "monthly":
+		return DebtCompoundingMonthly, nil
+	default:
+		return "", ErrInvalidDebtCompounding
+	}
+}
+
+// MapToDebtInterestRateType() maps an interest rate type string to its corresponding database constant
+func (m *FinancialTrackingModel) MapToDebtInterestRateType(rateType string) (database.DebtInterestRateTypeEnum, error) {
+	switch rateType {
+	case "apr":
+		return DebtInterestRateAPR, nil
+	case "apy":
+		return DebtInterestRateAPY, nil
+	default:
+		return "", ErrInvalidDebtInterestRateType
+	}
+}
+
+// PeriodicInterestRate() returns the interest rate of a debt for one of the given number of periods
+// in a year as a fraction. The interest rate is stored as an annual percentage, which is either a
+// nominal APR that is split evenly across the periods, or an effective APY that compounds to the
+// annual rate over the periods.
+func (debt *Debt) PeriodicInterestRate(periodsPerYear int) decimal.Decimal {
+	annualRate := debt.InterestRate.Div(decimal.NewFromInt(100))
+	if debt.InterestRateType == DebtInterestRateAPY {
+		// the base is at least 1 as rates are never negative, so the power cannot fail
+		one := decimal.NewFromInt(1)
+		growth, _ := one.Add(annualRate).PowWithPrecision(one.Div(decimal.NewFromInt(int64(periodsPerYear))), 16)
+		return growth.Sub(one)
+	}
+	return annualRate.Div(decimal.NewFromInt(int64(periodsPerYear)))
+}
+
+// MonthlyInterestRate() returns the monthly interest rate of a debt as a fraction
+func (debt *Debt) MonthlyInterestRate() decimal.Decimal {
+	return debt.PeriodicInterestRate(12)
+}
+
+// AccrueInterest() accrues the interest of a debt for every day since the interest was last
+// calculated up to the given date, adding it to the accrued interest. Daily compounding accrues
+// interest on the remaining balance and the unpaid accrued interest, while monthly compounding
+// accrues simple interest on the remaining balance at the monthly rate until it is capitalized.
+// We return the interest accrued.
+func (debt *Debt) AccrueInterest(asOf time.Time) decimal.Decimal {
+	from := debt.InterestLastCalculated
+	if from.IsZero() {
+		from = debt.CreatedAt
+	}
+	days := int(dateOnly(asOf).Sub(dateOnly(from)).Hours() / 24)
+	if days <= 0 || !debt.RemainingBalance.IsPositive() {
+		// never leave the calculation date unset, otherwise the next accrual starts from year one
+		if days > 0 || debt.InterestLastCalculated.IsZero() {
+			debt.InterestLastCalculated = asOf
+		}
+		return decimal.Zero
+	}
+	accrued := decimal.Zero
+	switch debt.CompoundingFrequency {
+	case DebtCompoundingDaily:
+		dailyRate := debt.PeriodicInterestRate(365)
+		balance := debt.RemainingBalance.Add(debt.AccruedInterest)
+		for day := 0; day < days; day++ {
+			interest := balance.Mul(dailyRate)
+			accrued = accrued.Add(interest)
+			balance = balance.Add(interest)
+		}
+	default:
+		dailyRate := debt.MonthlyInterestRate().Mul(decimal.NewFromInt(12)).Div(decimal.NewFromInt(365))
+		accrued = debt.RemainingBalance.Mul(dailyRate).Mul(decimal.NewFromInt(int64(days)))
+	}
+	accrued = accrued.Round(2)
+	debt.AccruedInterest = debt.AccruedInterest.Add(accrued)
+	debt.InterestLastCalculated = asOf
+	return accrued
+}
+
+// paymentDay() returns the day of the month the payments of a debt fall due on
+func (debt *Debt) paymentDay() int32 {
+	if debt.DueDate.IsZero() {
+		return int32(debt.NextPaymentDate.Day())
+	}
+	return int32(debt.DueDate.Day())
+}
+
+// AdvanceNextPaymentDate() moves the next payment date of a debt on to the following month once a
+// payment is made. This is the only place the next payment date moves, so it always points at the
+// oldest payment that has not been made.
+func (debt *Debt) AdvanceNextPaymentDate() {
+	debt.NextPaymentDate = addMonthsClamped(debt.NextPaymentDate, 1, debt.paymentDay())
+}
+
+// unchargedDueDate() returns the earliest due date of a debt whose missed payment has not been charged.
+// Missed payments after the next payment date are counted on from the last one that was charged.
+func (debt *Debt) unchargedDueDate() time.Time {
+	if debt.LastOverdueDueDate.IsZero() || debt.LastOverdueDueDate.Before(debt.NextPaymentDate) {
+		return debt.NextPaymentDate
+	}
+	return addMonthsClamped(debt.LastOverdueDueDate, 1, debt.paymentDay())
+}
+
+// IsOverdue() checks whether a payment of a debt was missed and not charged yet, that is the payment
+// date and its grace period have passed on the given date without the payment being made.
+func (debt *Debt) IsOverdue(asOf time.Time) bool {
+	if !debt.RemainingBalance.IsPositive() {
+		return false
+	}
+	overdueAfter := dateOnly(debt.unchargedDueDate()).AddDate(0, 0, int(debt.GracePeriodDays))
+	return overdueAfter.Before(dateOnly(asOf))
+}
+
+// ApplyOverdueCharges() handles the earliest missed payment that was not charged. The late fee is
+// added to the remaining balance and unpaid interest of monthly compounding debts is capitalized.
+// The missed due date is remembered so that every missed payment is only charged once, while the
+// next payment date stays on the unpaid payment until a payment is made.
+// We return the missed due date and the late fee that was charged.
+func (debt *Debt) ApplyOverdueCharges() (time.Time, decimal.Decimal) {
+	missedDueDate := debt.unchargedDueDate()
+	if debt.CompoundingFrequency != DebtCompoundingDaily {
+		debt.RemainingBalance = debt.RemainingBalance.Add(debt.AccruedInterest)
+		debt.AccruedInterest = decimal.Zero
+	}
+	debt.RemainingBalance = debt.RemainingBalance.Add(debt.LateFee)
+	debt.LastOverdueDueDate = missedDueDate
+	return missedDueDate, debt.LateFee
+}
+
+// dateOnly() strips the time of day from a time, keeping its calendar date
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// CompareDebtPayoffStrategies() simulates the snowball and avalanche strategies, as well as the
+// custom strategy if a custom order is provided, and recommends the one that pays the least interest.
+func CompareDebtPayoffStrategies(debts []*Debt, monthlyBudget decimal.Decimal, customOrder []int64, startDate time.Time) (*DebtPayoffComparison, error) {
+	strategies := []database.DebtPayoffStrategyEnum{DebtPayoffStrategyAvalanche, DebtPayoffStrategySnowball}
+	if len(customOrder) > 0 {
+		strategies = append(strategies, DebtPayoffStrategyCustom)
+	}
+	comparison := &DebtPayoffComparison{}
+	for _, strategy := range strategies {
+		simulation, err := SimulateDebtPayoff(debts, monthlyBudget, strategy, customOrder, startDate)
+		if err != nil {
+			return nil, err
+		}
+		comparison.Simulations = append(comparison.Simulations, simulation)
+	}
+	// recommend the cheapest strategy, using the fastest one to break ties
+	recommended, mostInterest, mostMonths := comparison.Simulations[0], comparison.Simulations[0].TotalInterest, comparison.Simulations[0].TotalMonths
+	for _, simulation := range comparison.Simulations[1:] {
+		if simulation.TotalInterest.LessThan(recommended.TotalInterest) ||
+			(simulation.TotalInterest.Equal(recommended.TotalInterest) && simulation.TotalMonths < recommended.TotalMonths) {
+			recommended = simulation
+		}
+		mostInterest = decimal.Max(mostInterest, simulation.TotalInterest)
+		mostMonths = max(mostMonths, simulation.TotalMonths)
+	}
+	comparison.RecommendedStrategy = recommended.Strategy
+	comparison.InterestSavings = mostInterest.Sub(recommended.TotalInterest)
+	comparison.MonthsSaved = mostMonths - recommended.TotalMonths
+	return comparison, nil
+}
+
+// SimulateDebtPayoff() simulates paying off the debts month by month with a fixed monthly budget.
+// Every month, interest is added to each balance using the same monthly rate as the estimated payoff
+// date, the minimum payment is made on every debt and whatever is left of the budget goes to the
+// debts in the order of the strategy. Minimum payments of paid off debts roll over to the next debt.
+func SimulateDebtPayoff(debts []*Debt, monthlyBudget decimal.Decimal, strategy database.DebtPayoffStrategyEnum, customOrder []int64, startDate time.Time) (*DebtPayoffSimulation, error) {
+	// only debts with an outstanding balance are part of the plan
+	var schedules []*DebtPayoffSchedule
+	var outstandingDebts []*Debt
+	minimumPayments := decimal.Zero
+	for _, debt := range debts {
+		if !debt.RemainingBalance.IsPositive() {
+			continue
+		}
+		outstandingDebts = append(outstandingDebts, debt)
+		minimumPayments = minimumPayments.Add(decimal.Min(debt.MinimumPayment, debt.RemainingBalance))
+	}
+	if len(outstandingDebts) == 0 {
+		return nil, ErrGeneralRecordNotFound
+	}
+	if monthlyBudget.LessThan(minimumPayments) {
+		return nil, ErrInsufficientPayoffBudget
+	}
+	outstandingDebts, err := orderDebtsForPayoff(outstandingDebts, strategy, customOrder)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]decimal.Decimal, len(outstandingDebts))
+	for i, debt := range outstandingDebts {
+		balances[i] = debt.RemainingBalance
+		schedules = append(schedules, &DebtPayoffSchedule{
+			DebtID:          debt.ID,
+			Name:            debt.Name,
+			PayoffOrder:     i + 1,
+			StartingBalance: debt.RemainingBalance,
+			InterestRate:    debt.InterestRate,
+			InterestPaid:    decimal.Zero,
+			TotalPaid:       decimal.Zero,
+		})
+	}
+	simulation := &DebtPayoffSimulation{
+		Strategy:      strategy,
+		MonthlyBudget: monthlyBudget,
+		TotalInterest: decimal.Zero,
+		TotalPaid:     decimal.Zero,
+		Debts:         schedules,
+	}
+	remainingDebts := len(outstandingDebts)
+	for month := 1; remainingDebts > 0; month++ {
+		if month > DefaultDebtPayoffMaxMonths {
+			return nil, ErrDebtPayoffNotPossible
+		}
+		budget := monthlyBudget
+		// add the interest for the month and make the minimum payments
+		for i, debt := range outstandingDebts {
+			if !balances[i].IsPositive() {
+				continue
+			}
+			interest := balances[i].Mul(debt.MonthlyInterestRate()).Round(2)
+			balances[i] = balances[i].Add(interest)
+			schedules[i].InterestPaid = schedules[i].InterestPaid.Add(interest)
+			payment := decimal.Min(debt.MinimumPayment, balances[i])
+			balances[i] = balances[i].Sub(payment)
+			schedules[i].TotalPaid = schedules[i].TotalPaid.Add(payment)
+			budget = budget.Sub(payment)
+		}
+		// apply the rest of the budget in the order of the strategy
+		for i := range outstandingDebts {
+			if !budget.IsPositive() {
+				break
+			}
+			if !balances[i].IsPositive() {
+				continue
+			}
+			payment := decimal.Min(budget, balances[i])
+			balances[i] = balances[i].Sub(payment)
+			schedules[i].TotalPaid = schedules[i].TotalPaid.Add(payment)
+			budget = budget.Sub(payment)
+		}
+		// record the debts that were paid off this month
+		for i := range outstandingDebts {
+			if schedules[i].MonthsToPayoff == 0 && !balances[i].IsPositive() {
+				schedules[i].MonthsToPayoff = month
+				schedules[i].PayoffDate = startDate.AddDate(0, month, 0)
+				remainingDebts--
+			}
+		}
+		simulation.TotalMonths = month
+	}
+	// set the totals
+	for _, schedule := range schedules {
+		simulation.TotalInterest = simulation.TotalInterest.Add(schedule.InterestPaid)
+		simulation.TotalPaid = simulation.TotalPaid.Add(schedule.TotalPaid)
+	}
+	simulation.PayoffDate = startDate.AddDate(0, simulation.TotalMonths, 0)
+	return simulation, nil
+}
+
+// orderDebtsForPayoff() returns the debts in the order extra payments are applied.
+// Snowball pays the smallest balance first, avalanche the highest interest rate first and
+// custom follows the given debt IDs, with any debts left out appended in snowball order.
+func orderDebtsForPayoff(debts []*Debt, strategy database.DebtPayoffStrategyEnum, customOrder []int64) ([]*Debt, error) {
+	ordered := make([]*Debt, len(debts))
+	copy(ordered, debts)
+	snowball := func(i, j int) bool {
+		if !ordered[i].RemainingBalance.Equal(ordered[j].RemainingBalance) {
+			return ordered[i].RemainingBalance.LessThan(ordered[j].RemainingBalance)
+		}
+		return ordered[i].ID < ordered[j].ID
+	}
+	switch strategy {
+	case DebtPayoffStrategySnowball:
+		sort.SliceStable(ordered, snowball)
+	case DebtPayoffStrategyAvalanche:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			if !ordered[i].InterestRate.Equal(ordered[j].InterestRate) {
+				return ordered[i].InterestRate.GreaterThan(ordered[j].InterestRate)
+			}
+			return snowball(i, j)
+		})
+	case DebtPayoffStrategyCustom:
+		sort.SliceStable(ordered, snowball)
+		positions := make(map[int64]int, len(customOrder))
+		for i, debtID := range customOrder {
+			positions[debtID] = i
+		}
+		// every debt in the custom order must be part of the plan
+		found := 0
+		for _, debt := range ordered {
+			if _, ok := positions[debt.ID]; ok {
+				found++
+			}
+		}
+		if found != len(positions) {
+			return nil, ErrInvalidDebtPayoffOrder
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			pi, iok := positions[ordered[i].ID]
+			pj, jok := positions[ordered[j].ID]
+			switch {
+			case iok && jok:
+				return pi < pj
+			default:
+				return iok && !jok
+			}
+		})
+	default:
+		return nil, ErrInvalidDebtPayoffStrategy
+	}
+	return ordered, nil
+}
+
+// BuildDebtAmortizationSchedule() builds the amortization table of a debt as of a date.
+// Payments fall due monthly from the debt's first due date. Entries up to the date follow the
+// original schedule starting from the full amount, and the actual payments are overlaid on the
+// period they were made in. The actual balance of the past entries accrues the monthly interest and
+// the late fee of every missed payment, so interest that was not paid is capitalized like on the debt
+// itself, and the current actual balance includes the interest accrued since. The remaining entries
+// are projected from the current remaining balance. The variance is the scheduled balance less the actual balance, so a positive variance means
+// the user is ahead of schedule. Variances of less than 1 are considered on track.
+func BuildDebtAmortizationSchedule(debt *Debt, payments []*DebtRepayment, asOf time.Time) (*DebtAmortizationSchedule, error) {
+	if !debt.MinimumPayment.IsPositive() {
+		return nil, ErrDebtNeverAmortizes
+	}
+	schedule := &DebtAmortizationSchedule{
+		DebtID:                 debt.ID,
+		Name:                   debt.Name,
+		InterestRate:           debt.InterestRate,
+		MinimumPayment:         debt.MinimumPayment,
+		ActualBalance:          debt.RemainingBalance.Add(debt.AccruedInterest),
+		TotalProjectedInterest: decimal.Zero,
+		ProjectedPayoffDate:    debt.LastPaymentDate,
+	}
+	dayOfMonth := int32(debt.DueDate.Day())
+	// the original schedule up to the date
+	balance := debt.Amount
+	paymentNumber := 0
+	for ; balance.IsPositive(); paymentNumber++ {
+		paymentDate := addMonthsClamped(debt.DueDate, paymentNumber, dayOfMonth)
+		if paymentDate.After(asOf) {
+			break
+		}
+		schedule.Entries = append(schedule.Entries, amortizeDebtPayment(debt, &balance, paymentNumber, paymentDate))
+	}
+	schedule.ScheduledBalance = balance
+	pastEntries := len(schedule.Entries)
+	// the projection from the remaining balance starts at the first payment after the date
+	for !addMonthsClamped(debt.DueDate, paymentNumber, dayOfMonth).After(asOf) {
+		paymentNumber++
+	}
+	balance = debt.RemainingBalance
+	for months := 0; balance.IsPositive(); months++ {
+		if months >= DefaultDebtPayoffMaxMonths {
+			return nil, ErrDebtNeverAmortizes
+		}
+		entry := amortizeDebtPayment(debt, &balance, paymentNumber+months, addMonthsClamped(debt.DueDate, paymentNumber+months, dayOfMonth))
+		entry.Status = AmortizationStatusProjected
+		schedule.Entries = append(schedule.Entries, entry)
+		schedule.TotalProjectedInterest = schedule.TotalProjectedInterest.Add(entry.InterestPortion)
+		schedule.ProjectedPayoffDate = entry.PaymentDate
+	}
+	// overlay the actual payments on the period they were made in
+	for _, payment := range payments {
+		for _, entry := range schedule.Entries {
+			if !payment.PaymentDate.Truncate(24 * time.Hour).After(entry.PaymentDate) {
+				entry.ActualPayment = entry.ActualPayment.Add(payment.PaymentAmount)
+				entry.ActualPrincipal = entry.ActualPrincipal.Add(payment.PrincipalPayment)
+				break
+			}
+		}
+	}
+	// set the status of the past entries
+	actualBalance := debt.Amount
+	for _, entry := range schedule.Entries[:pastEntries] {
+		interest := actualBalance.Mul(debt.MonthlyInterestRate()).Round(2)
+		actualBalance = decimal.Max(actualBalance.Add(interest).Sub(entry.ActualPayment), decimal.Zero)
+		switch {
+		case entry.ActualPayment.GreaterThanOrEqual(entry.PaymentAmount):
+			entry.Status = AmortizationStatusPaid
+		case entry.ActualPayment.IsPositive():
+			entry.Status = AmortizationStatusPartial
+		default:
+			entry.Status = AmortizationStatusMissed
+			// the late fee is only charged once the grace period has passed
+			if entry.PaymentDate.AddDate(0, 0, int(debt.GracePeriodDays)).Before(dateOnly(asOf)) {
+				actualBalance = actualBalance.Add(debt.LateFee)
+			}
+		}
+		entryBalance := actualBalance
+		entry.ActualBalance = &entryBalance
+	}
+	// compare the actual balance to the scheduled balance
+	schedule.Variance = schedule.ScheduledBalance.Sub(schedule.ActualBalance)
+	switch {
+	case schedule.Variance.Abs().LessThan(decimal.NewFromInt(1)):
+		schedule.ScheduleStatus = DebtScheduleStatusOnTrack
+	case schedule.Variance.IsPositive():
+		schedule.ScheduleStatus = DebtScheduleStatusAhead
+	default:
+		schedule.ScheduleStatus = DebtScheduleStatusBehind
+	}
+	return schedule, nil
+}
+
+// amortizeDebtPayment() applies a single monthly payment to a balance using the debt's monthly
+// interest rate and minimum payment, returning the resulting amortization entry.
+func amortizeDebtPayment(debt *Debt, balance *decimal.Decimal, paymentNumber int, paymentDate time.Time) *AmortizationEntry {
+	interest := balance.Mul(debt.MonthlyInterestRate()).Round(2)
+	payment := decimal.Min(debt.MinimumPayment, balance.Add(interest))
+	principal := payment.Sub(interest)
+	*balance = balance.Sub(principal)
+	return &AmortizationEntry{
+		PaymentNumber:    paymentNumber + 1,
+		PaymentDate:      paymentDate,
+		PaymentAmount:    payment,
+		InterestPortion:  interest,
+		PrincipalPortion: principal,
+		RemainingBalance: *balance,
+		ActualPayment:    decimal.Zero,
+		ActualPrincipal:  decimal.Zero,
+	}
+}
+
+// ValidateDebtPayoffPlan() validates the inputs of a debt payoff plan
+func ValidateDebtPayoffPlan(v *validator.Validator, monthlyBudget decimal.Decimal, customOrder []int64) {
+	ValidateAmount(v, monthlyBudget, "monthly_budget")
+	v.Check(validator.Unique(customOrder), "custom_order", "must not contain duplicate debts")
+}
+
 // Validate Debt
 func ValidateDebt(v *validator.Validator, debt *Debt) {
 	ValidateAmount(v, debt.Amount, "amount")
 	ValidateAmount(v, debt.MinimumPayment, "minimum_payment")
 	ValidateBudgetDescription(v, debt.Description)
 	ValidateName(v, debt.Name, "name")
+	v.Check(!debt.LateFee.IsNegative(), "late_fee", "cannot be negative")
+	v.Check(debt.GracePeriodDays >= 0, "grace_period_days", "cannot be negative")
+	v.Check(debt.GracePeriodDays <= 31, "grace_period_days", "cannot be more than 31 days")
 }
 
 // CreateNewRecurringExpense() Creates a new recurrent expens in the recurrence table
@@ -247,14 +1036,22 @@ func (m *FinancialTrackingModel) CreateNewRecurringExpense(userID int64, recurri
 	defer cancel()
 	// create the expense
 	updatedDetails, err := m.DB.CreateNewRecurringExpense(ctx, database.CreateNewRecurringExpenseParams{
-		UserID:             userID,
-		BudgetID:           recurringExpense.BudgetID,
-		Amount:             recurringExpense.Amount.String(),
-		Name:               recurringExpense.Name,
-		Description:        sql.NullString{String: recurringExpense.Description, Valid: true},
-		RecurrenceInterval: recurringExpense.RecurrenceInterval,
-		ProjectedAmount:    recurringExpense.CalculateTotalAmountPerMonth().String(),
-		NextOccurrence:     recurringExpense.NextOccurrence,
+		UserID:                  userID,
+		BudgetID:                recurringExpense.BudgetID,
+		Amount:                  recurringExpense.Amount.String(),
+		Name:                    recurringExpense.Name,
+		Description:             sql.NullString{String: recurringExpense.Description, Valid: true},
+		RecurrenceInterval:      recurringExpense.RecurrenceInterval,
+		ProjectedAmount:         recurringExpense.CalculateTotalAmountPerMonth().String(),
+		NextOccurrence:          recurringExpense.NextOccurrence,
+		IntervalCount:           recurringExpense.IntervalCount,
+		DayOfMonth:              sql.NullInt16{Int16: int16(recurringExpense.DayOfMonth), Valid: recurringExpense.DayOfMonth != 0},
+		StartDate:               recurringExpense.StartDate,
+		LastOccurrence:          sql.NullTime{Time: recurringExpense.LastOccurrence, Valid: !recurringExpense.LastOccurrence.IsZero()},
+		EndDate:                 sql.NullTime{Time: recurringExpense.EndDate, Valid: !recurringExpense.EndDate.IsZero()},
+		MaxOccurrences:          sql.NullInt32{Int32: recurringExpense.MaxOccurrences, Valid: recurringExpense.MaxOccurrences != 0},
+		OccurrenceCount:         recurringExpense.OccurrenceCount,
+		FirstOccurrenceRecorded: recurringExpense.FirstOccurrenceRecorded,
 	})
 	if err != nil {
 		switch {
@@ -289,6 +1086,13 @@ func (m *FinancialTrackingModel) UpdateRecurringExpenseByID(userID int64, recurr
 		RecurrenceInterval: recurringExpense.RecurrenceInterval,
 		ProjectedAmount:    recurringExpense.CalculateTotalAmountPerMonth().String(),
 		NextOccurrence:     recurringExpense.NextOccurrence,
+		IntervalCount:      recurringExpense.IntervalCount,
+		DayOfMonth:         sql.NullInt16{Int16: int16(recurringExpense.DayOfMonth), Valid: recurringExpense.DayOfMonth != 0},
+		LastOccurrence:     sql.NullTime{Time: recurringExpense.LastOccurrence, Valid: !recurringExpense.LastOccurrence.IsZero()},
+		EndDate:            sql.NullTime{Time: recurringExpense.EndDate, Valid: !recurringExpense.EndDate.IsZero()},
+		MaxOccurrences:     sql.NullInt32{Int32: recurringExpense.MaxOccurrences, Valid: recurringExpense.MaxOccurrences != 0},
+		OccurrenceCount:    recurringExpense.OccurrenceCount,
+		IsPaused:           recurringExpense.IsPaused,
 	})
 	if err != nil {
 		switch {
@@ -307,10 +1111,10 @@ func (m *FinancialTrackingModel) UpdateRecurringExpenseByID(userID int64, recurr
 }
 
 // GetAllExpensesByUserID() gets all the expenses by a user ID
-// This route supports pagination and a name search parameter for the
-// expense's name.
+// This route supports pagination, a name search parameter for the
+// expense's name and a tag filter, only expenses with all the tags are returned.
 // We return an array of expenses, metadata struct and an error if any was found
-func (m *FinancialTrackingModel) GetAllExpensesByUserID(userID int64, expenseName string, filters Filters) ([]*Expense, Metadata, error) {
+func (m *FinancialTrackingModel) GetAllExpensesByUserID(userID int64, expenseName string, tags []string, filters Filters) ([]*Expense, Metadata, error) {
 	// set our context
 	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
 	defer cancel()
@@ -320,6 +1124,7 @@ func (m *FinancialTrackingModel) GetAllExpensesByUserID(userID int64, expenseNam
 		Column2: expenseName,
 		Limit:   int32(filters.limit()),
 		Offset:  int32(filters.offset()),
+		Column5: tags,
 	})
 	if err != nil {
 		switch {
@@ -420,41 +1225,49 @@ func (m *FinancialTrackingModel) GetRecurringExpenseByID(userID, recurringExpens
 
 // GetAllRecurringExpensesDueForProcessing() gets all the recurring expenses that are due for processing
 // That is, we get all recurring expenses that have a next occurrence that is less than or equal to the current time
-// We will need an offset and a limit so that we support batch processing.
+// The expenses are paged by ID, pass the ID of the last expense of the previous page to get the next one.
+// Posting moves an expense out of the due set, so offsets would skip expenses.
 // This method is made to work in tandem with our cron job
-func (m *FinancialTrackingModel) GetAllRecurringExpensesDueForProcessing(filters Filters) ([]*RecurringExpense, Metadata, error) {
+func (m *FinancialTrackingModel) GetAllRecurringExpensesDueForProcessing(afterID int64, limit int) ([]*RecurringExpense, error) {
 	// set our context
 	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
 	defer cancel()
 	// get the expenses
 	recurringExpenses, err := m.DB.GetAllRecurringExpensesDueForProcessing(ctx, database.GetAllRecurringExpensesDueForProcessingParams{
-		Limit:  int32(filters.limit()),
-		Offset: int32(filters.offset()),
+		ID:    afterID,
+		Limit: int32(limit),
 	})
 	if err != nil {
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			return nil, Metadata{}, ErrGeneralRecordNotFound
-		default:
-			return nil, Metadata{}, err
-		}
+		return nil, err
 	}
 	if len(recurringExpenses) == 0 {
-		return nil, Metadata{}, ErrGeneralRecordNotFound
+		return nil, ErrGeneralRecordNotFound
 	}
-	// set totals
-	totalRecords := 0
 	// populate the expenses
 	var populatedExpenses []*RecurringExpense
 	for _, expense := range recurringExpenses {
-		totalRecords = int(expense.TotalCount)
 		populatedExpenses = append(populatedExpenses, populateRecurringExpense(expense))
 	}
-	// calculate metadata
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
 	// we are good
-	return populatedExpenses, metadata, nil
+	return populatedExpenses, nil
+}
 
+// PostRecurringExpense() posts a single occurrence of a recurring expense in one transaction:
+// the expense is created and the recurring expense is saved with its next occurrence. If either
+// step fails nothing is saved, so the occurrence is posted again on the next run instead of twice.
+// The recurring expense is expected to have already been moved to its next occurrence.
+func (m *FinancialTrackingModel) PostRecurringExpense(recurringExpense *RecurringExpense, expense *Expense) error {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	return withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		trackingModel := &FinancialTrackingModel{DB: q}
+		err := trackingModel.CreateNewExpense(recurringExpense.UserID, expense)
+		if err != nil {
+			return err
+		}
+		return trackingModel.UpdateRecurringExpenseByID(recurringExpense.UserID, recurringExpense)
+	})
 }
 
 // CreateNewExpense() creates a new expense in the expenses table
@@ -474,6 +1287,7 @@ func (m *FinancialTrackingModel) CreateNewExpense(userID int64, expense *Expense
 		IsRecurring:  expense.IsRecurring,
 		Description:  sql.NullString{String: expense.Description, Valid: true},
 		DateOccurred: expense.DateOccurred,
+		AccountID:    sql.NullInt64{Int64: expense.AccountID, Valid: expense.AccountID != 0},
 	})
 	if err != nil {
 		return err
@@ -501,6 +1315,7 @@ func (m *FinancialTrackingModel) UpdateExpenseByID(userID int64, expense *Expens
 		IsRecurring:  expense.IsRecurring,
 		Description:  sql.NullString{String: expense.Description, Valid: true},
 		DateOccurred: expense.DateOccurred,
+		AccountID:    sql.NullInt64{Int64: expense.AccountID, Valid: expense.AccountID != 0},
 		ID:           expense.ID,
 		UserID:       userID,
 	})
@@ -543,6 +1358,29 @@ func (m *FinancialTrackingModel) GetExpenseByID(userID, expenseID int64) (*Expen
 	return updatedExpense, nil
 }
 
+// DeleteExpenseByID() deletes an expense by its ID and the user ID.
+// Attachments are removed by the database, the caller is responsible for their stored files
+func (m *FinancialTrackingModel) DeleteExpenseByID(userID, expenseID int64) (int64, error) {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// delete the expense
+	deletedExpenseID, err := m.DB.DeleteExpenseByID(ctx, database.DeleteExpenseByIDParams{
+		ID:     expenseID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrGeneralRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+	// we are good
+	return deletedExpenseID, nil
+}
+
 // =========================================================================================================
 // Income
 // =========================================================================================================
@@ -564,6 +1402,7 @@ func (m *FinancialTrackingModel) CreateNewIncome(userID int64, income *Income) e
 		ExchangeRate:         income.ExchangeRate.String(),
 		Description:          sql.NullString{String: income.Description, Valid: true},
 		DateReceived:         income.DateReceived,
+		AccountID:            sql.NullInt64{Int64: income.AccountID, Valid: income.AccountID != 0},
 	})
 	if err != nil {
 		return err
@@ -578,9 +1417,10 @@ func (m *FinancialTrackingModel) CreateNewIncome(userID int64, income *Income) e
 }
 
 // GetAllIncomesByUserID() gets all the incomes by a user ID.
-// This route supports pagination and a name search parameter for the income's source
+// This route supports pagination, a name search parameter for the income's source
+// and a tag filter, only incomes with all the tags are returned.
 // We return an array of enriched incomes[] *EnrichedIncome, a metadata struct and an error if any was found
-func (m *FinancialTrackingModel) GetAllIncomesByUserID(userID int64, incomeSource string, filters Filters) ([]*EnrichedIncome, Metadata, error) {
+func (m *FinancialTrackingModel) GetAllIncomesByUserID(userID int64, incomeSource string, tags []string, filters Filters) ([]*EnrichedIncome, Metadata, error) {
 	// set our context
 	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
 	defer cancel()
@@ -590,6 +1430,7 @@ func (m *FinancialTrackingModel) GetAllIncomesByUserID(userID int64, incomeSourc
 		Column2: incomeSource,
 		Limit:   int32(filters.limit()),
 		Offset:  int32(filters.offset()),
+		Column5: tags,
 	})
 	if err != nil {
 		switch {
@@ -636,6 +1477,7 @@ func (m *FinancialTrackingModel) UpdateIncomeByID(userID int64, income *Income)
 		ExchangeRate:         income.ExchangeRate.String(),
 		Description:          sql.NullString{String: income.Description, Valid: true},
 		DateReceived:         income.DateReceived,
+		AccountID:            sql.NullInt64{Int64: income.AccountID, Valid: income.AccountID != 0},
 		ID:                   income.ID,
 		UserID:               userID,
 	})
@@ -678,6 +1520,227 @@ func (m *FinancialTrackingModel) GetIncomeByID(userID, incomeID int64) (*Income,
 	return updatedIncome, nil
 }
 
+// DeleteIncomeByID() deletes an income by its ID and the user ID.
+// Its tags and tax categories are removed by the database
+func (m *FinancialTrackingModel) DeleteIncomeByID(userID, incomeID int64) (int64, error) {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// delete the income
+	deletedIncomeID, err := m.DB.DeleteIncomeByID(ctx, database.DeleteIncomeByIDParams{
+		ID:     incomeID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrGeneralRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+	// we are good
+	return deletedIncomeID, nil
+}
+
+// =========================================================================================================
+// Recurring Income
+// =========================================================================================================
+
+// CreateNewRecurringIncome() creates a new recurring income in the recurring incomes table
+// The scheduler will pick it up and post an income once the next occurrence is due
+func (m *FinancialTrackingModel) CreateNewRecurringIncome(userID int64, recurringIncome *RecurringIncome) error {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// create the recurring income
+	recurringIncomeDetails, err := m.DB.CreateNewRecurringIncome(ctx, database.CreateNewRecurringIncomeParams{
+		UserID:                   userID,
+		Source:                   recurringIncome.Source,
+		OriginalCurrencyCode:     recurringIncome.OriginalCurrencyCode,
+		AmountOriginal:           recurringIncome.AmountOriginal.String(),
+		Description:              sql.NullString{String: recurringIncome.Description, Valid: true},
+		RecurrenceInterval:       recurringIncome.RecurrenceInterval,
+		NextOccurrence:           recurringIncome.NextOccurrence,
+		EndDate:                  sql.NullTime{Time: recurringIncome.EndDate, Valid: !recurringIncome.EndDate.IsZero()},
+		GoalID:                   sql.NullInt64{Int64: recurringIncome.GoalID, Valid: recurringIncome.GoalID != 0},
+		GoalAllocationPercentage: recurringIncome.GoalAllocationPercentage.String(),
+		DayOfMonth:               recurringIncome.DayOfMonth,
+	})
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_recurring_income"`:
+			return ErrDuplicateRecurringIncome
+		default:
+			return err
+		}
+	}
+	// update the recurring income with the new details
+	recurringIncome.ID = recurringIncomeDetails.ID
+	recurringIncome.UserID = userID
+	recurringIncome.CreatedAt = recurringIncomeDetails.CreatedAt.Time
+	recurringIncome.UpdatedAt = recurringIncomeDetails.UpdatedAt.Time
+	// we are good
+	return nil
+}
+
+// UpdateRecurringIncomeByID() updates a recurring income by its ID and user ID
+// This is used both by the update handler and by the scheduler after posting an income
+func (m *FinancialTrackingModel) UpdateRecurringIncomeByID(userID int64, recurringIncome *RecurringIncome) error {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// update the recurring income
+	updatedAt, err := m.DB.UpdateRecurringIncomeByID(ctx, database.UpdateRecurringIncomeByIDParams{
+		Source:                   recurringIncome.Source,
+		OriginalCurrencyCode:     recurringIncome.OriginalCurrencyCode,
+		AmountOriginal:           recurringIncome.AmountOriginal.String(),
+		Description:              sql.NullString{String: recurringIncome.Description, Valid: true},
+		RecurrenceInterval:       recurringIncome.RecurrenceInterval,
+		NextOccurrence:           recurringIncome.NextOccurrence,
+		EndDate:                  sql.NullTime{Time: recurringIncome.EndDate, Valid: !recurringIncome.EndDate.IsZero()},
+		GoalID:                   sql.NullInt64{Int64: recurringIncome.GoalID, Valid: recurringIncome.GoalID != 0},
+		GoalAllocationPercentage: recurringIncome.GoalAllocationPercentage.String(),
+		LastPostedAt:             sql.NullTime{Time: recurringIncome.LastPostedAt, Valid: !recurringIncome.LastPostedAt.IsZero()},
+		DayOfMonth:               recurringIncome.DayOfMonth,
+		ID:                       recurringIncome.ID,
+		UserID:                   userID,
+	})
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_recurring_income"`:
+			return ErrDuplicateRecurringIncome
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		default:
+			return err
+		}
+	}
+	// update the recurring income with the new details
+	recurringIncome.UpdatedAt = updatedAt.Time
+	// we are good
+	return nil
+}
+
+// GetRecurringIncomeByID() gets a recurring income by its ID and user ID
+func (m *FinancialTrackingModel) GetRecurringIncomeByID(userID, recurringIncomeID int64) (*RecurringIncome, error) {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// get the recurring income
+	recurringIncome, err := m.DB.GetRecurringIncomeByID(ctx, database.GetRecurringIncomeByIDParams{
+		ID:     recurringIncomeID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	// we are good
+	return populateRecurringIncome(recurringIncome), nil
+}
+
+// GetAllRecurringIncomesByUserID() gets all the recurring incomes by a user ID
+// This route supports pagination and a search parameter for the income's source
+// We return an array of recurring incomes, a metadata struct and an error if any was found
+func (m *FinancialTrackingModel) GetAllRecurringIncomesByUserID(userID int64, incomeSource string, filters Filters) ([]*RecurringIncome, Metadata, error) {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// get the recurring incomes
+	recurringIncomes, err := m.DB.GetAllRecurringIncomesByUserID(ctx, database.GetAllRecurringIncomesByUserIDParams{
+		UserID:  userID,
+		Column2: incomeSource,
+		Limit:   int32(filters.limit()),
+		Offset:  int32(filters.offset()),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, Metadata{}, ErrGeneralRecordNotFound
+		default:
+			return nil, Metadata{}, err
+		}
+	}
+	if len(recurringIncomes) == 0 {
+		return nil, Metadata{}, ErrGeneralRecordNotFound
+	}
+	// set totals
+	totalRecords := 0
+	// populate the recurring incomes
+	var populatedIncomes []*RecurringIncome
+	for _, recurringIncome := range recurringIncomes {
+		totalRecords = int(recurringIncome.TotalCount)
+		populatedIncomes = append(populatedIncomes, populateRecurringIncome(recurringIncome))
+	}
+	// calculate metadata
+	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
+	// we are good
+	return populatedIncomes, metadata, nil
+}
+
+// GetAllRecurringIncomesDueForProcessing() gets the recurring incomes that are due for posting
+// That is, all recurring incomes whose next occurrence is today or earlier and have not yet ended.
+// Each income is enriched with the user's default currency so the caller can convert at posting time.
+// The incomes are paged by ID, pass the ID of the last income of the previous page to get the next one.
+// Posting moves an income out of the due set, so offsets would skip incomes.
+// This method is made to work in tandem with our cron job
+func (m *FinancialTrackingModel) GetAllRecurringIncomesDueForProcessing(afterID int64, limit int) ([]*EnrichedRecurringIncome, error) {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// get the recurring incomes
+	recurringIncomes, err := m.DB.GetAllRecurringIncomesDueForProcessing(ctx, database.GetAllRecurringIncomesDueForProcessingParams{
+		ID:    afterID,
+		Limit: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(recurringIncomes) == 0 {
+		return nil, ErrGeneralRecordNotFound
+	}
+	// populate the recurring incomes
+	var populatedIncomes []*EnrichedRecurringIncome
+	for _, recurringIncome := range recurringIncomes {
+		populatedIncomes = append(populatedIncomes, &EnrichedRecurringIncome{
+			RecurringIncome:  populateRecurringIncome(recurringIncome),
+			UserCurrencyCode: recurringIncome.UserCurrencyCode.String,
+		})
+	}
+	// we are good
+	return populatedIncomes, nil
+}
+
+// PostRecurringIncome() posts a single occurrence of a recurring income in one transaction:
+// the income is created, the allocation is tracked against the linked goal and the recurring income
+// is saved with its next occurrence. If any step fails nothing is saved, so the occurrence is
+// posted again on the next run instead of twice.
+// The recurring income is expected to have already been moved to its next occurrence.
+func (m *FinancialTrackingModel) PostRecurringIncome(recurringIncome *RecurringIncome, income *Income, allocation *TrackedGoal) error {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	return withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		trackingModel := &FinancialTrackingModel{DB: q}
+		err := trackingModel.CreateNewIncome(recurringIncome.UserID, income)
+		if err != nil {
+			return err
+		}
+		if allocation != nil {
+			err = FinancialManagerModel{DB: q}.CreateNewGoalTracking(recurringIncome.UserID, allocation)
+			if err != nil {
+				return err
+			}
+		}
+		return trackingModel.UpdateRecurringIncomeByID(recurringIncome.UserID, recurringIncome)
+	})
+}
+
 // =========================================================================================================
 // Debts
 // =========================================================================================================
@@ -704,6 +1767,10 @@ func (m *FinancialTrackingModel) CreateNewDebt(userID int64, debt *Debt) error {
 		AccruedInterest:        sql.NullString{String: debt.AccruedInterest.String(), Valid: true},
 		InterestLastCalculated: sql.NullTime{Time: debt.InterestLastCalculated, Valid: true},
 		TotalInterestPaid:      sql.NullString{String: debt.TotalInterestPaid.String(), Valid: true},
+		CompoundingFrequency:   debt.CompoundingFrequency,
+		InterestRateType:       debt.InterestRateType,
+		LateFee:                debt.LateFee.String(),
+		GracePeriodDays:        debt.GracePeriodDays,
 	})
 	if err != nil {
 		switch {
@@ -745,6 +1812,11 @@ func (m *FinancialTrackingModel) UpdateDebtByID(userID int64, debt *Debt) error
 		TotalInterestPaid:      sql.NullString{String: debt.TotalInterestPaid.String(), Valid: true},
 		LastPaymentDate:        sql.NullTime{Time: debt.LastPaymentDate, Valid: !debt.LastPaymentDate.IsZero()},
 		ID:                     debt.ID,
+		CompoundingFrequency:   debt.CompoundingFrequency,
+		InterestRateType:       debt.InterestRateType,
+		LateFee:                debt.LateFee.String(),
+		GracePeriodDays:        debt.GracePeriodDays,
+		LastOverdueDueDate:     sql.NullTime{Time: debt.LastOverdueDueDate, Valid: !debt.LastOverdueDueDate.IsZero()},
 	})
 	if err != nil {
 		switch {
@@ -902,6 +1974,7 @@ func (m *FinancialTrackingModel) CreateNewDebtPayment(userID int64, debtRepaymen
 		PaymentDate:      debtRepayment.PaymentDate,
 		InterestPayment:  debtRepayment.InterestPayment.String(),
 		PrincipalPayment: debtRepayment.PrincipalPayment.String(),
+		AccountID:        sql.NullInt64{Int64: debtRepayment.AccountID, Valid: debtRepayment.AccountID != 0},
 	})
 	if err != nil {
 		return err
@@ -913,45 +1986,83 @@ func (m *FinancialTrackingModel) CreateNewDebtPayment(userID int64, debtRepaymen
 	return nil
 }
 
-// GetAllOverdueDebts() gets all the debts that are overdue
-// This is meant to be used in tandem with a cron job
-// We also return a Metadata struct and an error if any was found
-func (m *FinancialTrackingModel) GetAllOverdueDebts(filters Filters) ([]*Debt, Metadata, error) {
+// PostDebtPayment() saves a payment on a debt in one transaction: the repayment is created and the
+// debt is saved with its new balance and next payment date. If either fails nothing is saved.
+// The debt is expected to have already had the payment applied to it.
+func (m *FinancialTrackingModel) PostDebtPayment(debt *Debt, debtRepayment *DebtRepayment) error {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	return withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		trackingModel := &FinancialTrackingModel{DB: q}
+		err := trackingModel.CreateNewDebtPayment(debt.UserID, debtRepayment)
+		if err != nil {
+			return err
+		}
+		return trackingModel.UpdateDebtByID(debt.UserID, debt)
+	})
+}
+
+// GetAllDebtsDueForInterestAccrual() gets the next batch of debts after the given id whose interest
+// has not been accrued today or whose missed payment has not been charged yet.
+// This is meant to be used in tandem with a cron job, which pages by the last id it handled since
+// accrued debts drop out of the results.
+func (m *FinancialTrackingModel) GetAllDebtsDueForInterestAccrual(afterID int64, limit int) ([]*Debt, error) {
 	// set our context
 	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
 	defer cancel()
 	// get the debts
-	debts, err := m.DB.GetAllOverdueDebts(ctx, database.GetAllOverdueDebtsParams{
-		Limit:  int32(filters.limit()),
-		Offset: int32(filters.offset()),
+	debts, err := m.DB.GetAllDebtsDueForInterestAccrual(ctx, database.GetAllDebtsDueForInterestAccrualParams{
+		ID:    afterID,
+		Limit: int32(limit),
 	})
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
-			return nil, Metadata{}, ErrGeneralRecordNotFound
+			return nil, ErrGeneralRecordNotFound
 		default:
-			return nil, Metadata{}, err
+			return nil, err
 		}
 	}
 	if len(debts) == 0 {
-		return nil, Metadata{}, ErrGeneralRecordNotFound
+		return nil, ErrGeneralRecordNotFound
 	}
-	// set totals
-	totalRecords := 0
 	// populate the debts
 	var populatedDebts []*Debt
 	for _, debt := range debts {
-		totalRecords = int(debt.TotalCount)
 		populatedDebts = append(populatedDebts, populateDebt(debt))
 	}
+	// we are good
+	return populatedDebts, nil
+}
 
-	fmt.Println("Length of populated debts", len(populatedDebts))
-	fmt.Printf("Populated debts: %v\n", populatedDebts)
-	// calculate metadata
-	metadata := calculateMetadata(totalRecords, filters.Page, filters.PageSize)
-	fmt.Println("Id of first debt", populatedDebts[0].ID)
+// CreateNewDebtLateFee() records a late fee charged on a missed debt payment.
+// A missed payment can only be charged once, so we return ErrDuplicateDebtLateFee for repeated charges.
+func (m *FinancialTrackingModel) CreateNewDebtLateFee(userID int64, lateFee *DebtLateFee) error {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// create the late fee
+	lateFeeDetails, err := m.DB.CreateNewDebtLateFee(ctx, database.CreateNewDebtLateFeeParams{
+		DebtID:        lateFee.DebtID,
+		UserID:        userID,
+		Amount:        lateFee.Amount.String(),
+		MissedDueDate: lateFee.MissedDueDate,
+	})
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_debt_late_fee_per_due_date"`:
+			return ErrDuplicateDebtLateFee
+		default:
+			return err
+		}
+	}
+	// set the created late fee
+	lateFee.ID = lateFeeDetails.ID
+	lateFee.UserID = userID
+	lateFee.CreatedAt = lateFeeDetails.CreatedAt.Time
 	// we are good
-	return populatedDebts, metadata, nil
+	return nil
 }
 
 // populateDebtPayment() populates a debt payment
@@ -994,25 +2105,11 @@ func populateDebt(debtRow interface{}) *Debt {
 			AccruedInterest:        decimal.RequireFromString(debt.AccruedInterest.String),
 			InterestLastCalculated: debt.InterestLastCalculated.Time,
 			TotalInterestPaid:      decimal.RequireFromString(debt.TotalInterestPaid.String),
-		}
-	case database.GetAllOverdueDebtsRow:
-		return &Debt{
-			ID:                     debt.ID,
-			UserID:                 debt.UserID,
-			Name:                   debt.Name,
-			Amount:                 decimal.RequireFromString(debt.Amount),
-			RemainingBalance:       decimal.RequireFromString(debt.RemainingBalance),
-			InterestRate:           decimal.RequireFromString(debt.InterestRate.String),
-			Description:            debt.Description.String,
-			DueDate:                debt.DueDate,
-			MinimumPayment:         decimal.RequireFromString(debt.MinimumPayment),
-			CreatedAt:              debt.CreatedAt.Time,
-			UpdatedAt:              debt.UpdatedAt.Time,
-			NextPaymentDate:        debt.NextPaymentDate,
-			EstimatedPayoffDate:    debt.EstimatedPayoffDate.Time,
-			AccruedInterest:        decimal.RequireFromString(debt.AccruedInterest.String),
-			InterestLastCalculated: debt.InterestLastCalculated.Time,
-			TotalInterestPaid:      decimal.RequireFromString(debt.TotalInterestPaid.String),
+			CompoundingFrequency:   debt.CompoundingFrequency,
+			InterestRateType:       debt.InterestRateType,
+			LateFee:                decimal.RequireFromString(debt.LateFee),
+			GracePeriodDays:        debt.GracePeriodDays,
+			LastOverdueDueDate:     debt.LastOverdueDueDate.Time,
 		}
 	case database.GetAllDebtsByUserIDRow:
 		return &Debt{
@@ -1032,6 +2129,11 @@ func populateDebt(debtRow interface{}) *Debt {
 			AccruedInterest:        decimal.RequireFromString(debt.AccruedInterest.String),
 			InterestLastCalculated: debt.InterestLastCalculated.Time,
 			TotalInterestPaid:      decimal.RequireFromString(debt.TotalInterestPaid.String),
+			CompoundingFrequency:   debt.CompoundingFrequency,
+			InterestRateType:       debt.InterestRateType,
+			LateFee:                decimal.RequireFromString(debt.LateFee),
+			GracePeriodDays:        debt.GracePeriodDays,
+			LastOverdueDueDate:     debt.LastOverdueDueDate.Time,
 		}
 
 	default:
@@ -1053,6 +2155,8 @@ func populateIncome(incomeRow interface{}) *Income {
 			ExchangeRate:         decimal.RequireFromString(income.ExchangeRate),
 			Description:          income.Description.String,
 			DateReceived:         income.DateReceived,
+			AccountID:            income.AccountID.Int64,
+			IsCleared:            income.IsCleared,
 			CreatedAt:            income.CreatedAt.Time,
 			UpdatedAt:            income.UpdatedAt.Time,
 		}
@@ -1067,6 +2171,10 @@ func populateIncome(incomeRow interface{}) *Income {
 			ExchangeRate:         decimal.RequireFromString(income.ExchangeRate),
 			Description:          income.Description.String,
 			DateReceived:         income.DateReceived,
+			AccountID:            income.AccountID.Int64,
+			IsCleared:            income.IsCleared,
+			Tags:                 income.Tags,
+			TaxCategories:        income.TaxCategories,
 			CreatedAt:            income.CreatedAt.Time,
 			UpdatedAt:            income.UpdatedAt.Time,
 		}
@@ -1075,22 +2183,161 @@ func populateIncome(incomeRow interface{}) *Income {
 	}
 }
 
+// SaveDebtPayoffPlan() saves the chosen debt payoff plan of a user.
+// A user only has one plan, so saving a new plan replaces the previous one.
+func (m *FinancialTrackingModel) SaveDebtPayoffPlan(userID int64, plan *DebtPayoffPlan) error {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// save the plan
+	savedPlan, err := m.DB.SaveDebtPayoffPlan(ctx, database.SaveDebtPayoffPlanParams{
+		UserID:              userID,
+		Strategy:            plan.Strategy,
+		MonthlyBudget:       plan.MonthlyBudget.String(),
+		CustomOrder:         plan.CustomOrder,
+		ProjectedPayoffDate: plan.ProjectedPayoffDate,
+		TotalMonths:         plan.TotalMonths,
+		TotalInterest:       plan.TotalInterest.String(),
+	})
+	if err != nil {
+		return err
+	}
+	// set the saved plan
+	plan.ID = savedPlan.ID
+	plan.UserID = userID
+	plan.CreatedAt = savedPlan.CreatedAt.Time
+	plan.UpdatedAt = savedPlan.UpdatedAt.Time
+	// we are good
+	return nil
+}
+
+// GetDebtPayoffPlanByUserID() gets the saved debt payoff plan of a user
+func (m *FinancialTrackingModel) GetDebtPayoffPlanByUserID(userID int64) (*DebtPayoffPlan, error) {
+	// set our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultFinTrackDBContextTimeout)
+	defer cancel()
+	// get the plan
+	plan, err := m.DB.GetDebtPayoffPlanByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	// we are good
+	return populateDebtPayoffPlan(plan), nil
+}
+
+// Populate the debt payoff plan
+func populateDebtPayoffPlan(debtPayoffPlanRow interface{}) *DebtPayoffPlan {
+	switch plan := debtPayoffPlanRow.(type) {
+	case database.DebtPayoffPlan:
+		return &DebtPayoffPlan{
+			ID:                  plan.ID,
+			UserID:              plan.UserID,
+			Strategy:            plan.Strategy,
+			MonthlyBudget:       decimal.RequireFromString(plan.MonthlyBudget),
+			CustomOrder:         plan.CustomOrder,
+			ProjectedPayoffDate: plan.ProjectedPayoffDate,
+			TotalMonths:         plan.TotalMonths,
+			TotalInterest:       decimal.RequireFromString(plan.TotalInterest),
+			CreatedAt:           plan.CreatedAt.Time,
+			UpdatedAt:           plan.UpdatedAt.Time,
+		}
+	default:
+		return nil
+	}
+}
+
+// Populate the recurring income
+func populateRecurringIncome(recurringIncomeRow interface{}) *RecurringIncome {
+	switch recurringIncome := recurringIncomeRow.(type) {
+	case database.RecurringIncome:
+		return &RecurringIncome{
+			ID:                       recurringIncome.ID,
+			UserID:                   recurringIncome.UserID,
+			Source:                   recurringIncome.Source,
+			OriginalCurrencyCode:     recurringIncome.OriginalCurrencyCode,
+			AmountOriginal:           decimal.RequireFromString(recurringIncome.AmountOriginal),
+			Description:              recurringIncome.Description.String,
+			RecurrenceInterval:       recurringIncome.RecurrenceInterval,
+			NextOccurrence:           recurringIncome.NextOccurrence,
+			EndDate:                  recurringIncome.EndDate.Time,
+			GoalID:                   recurringIncome.GoalID.Int64,
+			GoalAllocationPercentage: decimal.RequireFromString(recurringIncome.GoalAllocationPercentage),
+			LastPostedAt:             recurringIncome.LastPostedAt.Time,
+			CreatedAt:                recurringIncome.CreatedAt.Time,
+			UpdatedAt:                recurringIncome.UpdatedAt.Time,
+			DayOfMonth:               recurringIncome.DayOfMonth,
+		}
+	case database.GetAllRecurringIncomesByUserIDRow:
+		return &RecurringIncome{
+			ID:                       recurringIncome.ID,
+			UserID:                   recurringIncome.UserID,
+			Source:                   recurringIncome.Source,
+			OriginalCurrencyCode:     recurringIncome.OriginalCurrencyCode,
+			AmountOriginal:           decimal.RequireFromString(recurringIncome.AmountOriginal),
+			Description:              recurringIncome.Description.String,
+			RecurrenceInterval:       recurringIncome.RecurrenceInterval,
+			NextOccurrence:           recurringIncome.NextOccurrence,
+			EndDate:                  recurringIncome.EndDate.Time,
+			GoalID:                   recurringIncome.GoalID.Int64,
+			GoalAllocationPercentage: decimal.RequireFromString(recurringIncome.GoalAllocationPercentage),
+			LastPostedAt:             recurringIncome.LastPostedAt.Time,
+			CreatedAt:                recurringIncome.CreatedAt.Time,
+			UpdatedAt:                recurringIncome.UpdatedAt.Time,
+			DayOfMonth:               recurringIncome.DayOfMonth,
+		}
+	case database.GetAllRecurringIncomesDueForProcessingRow:
+		return &RecurringIncome{
+			ID:                       recurringIncome.ID,
+			UserID:                   recurringIncome.UserID,
+			Source:                   recurringIncome.Source,
+			OriginalCurrencyCode:     recurringIncome.OriginalCurrencyCode,
+			AmountOriginal:           decimal.RequireFromString(recurringIncome.AmountOriginal),
+			Description:              recurringIncome.Description.String,
+			RecurrenceInterval:       recurringIncome.RecurrenceInterval,
+			NextOccurrence:           recurringIncome.NextOccurrence,
+			EndDate:                  recurringIncome.EndDate.Time,
+			GoalID:                   recurringIncome.GoalID.Int64,
+			GoalAllocationPercentage: decimal.RequireFromString(recurringIncome.GoalAllocationPercentage),
+			LastPostedAt:             recurringIncome.LastPostedAt.Time,
+			CreatedAt:                recurringIncome.CreatedAt.Time,
+			UpdatedAt:                recurringIncome.UpdatedAt.Time,
+			DayOfMonth:               recurringIncome.DayOfMonth,
+		}
+	default:
+		return nil
+	}
+}
+
 // Populate the recurring expense
 func populateRecurringExpense(recurringExpensRow interface{}) *RecurringExpense {
 	switch recurringExpense := recurringExpensRow.(type) {
 	case database.RecurringExpense:
 		return &RecurringExpense{
-			ID:                 recurringExpense.ID,
-			UserID:             recurringExpense.UserID,
-			BudgetID:           recurringExpense.BudgetID,
-			Amount:             decimal.RequireFromString(recurringExpense.Amount),
-			Name:               recurringExpense.Name,
-			Description:        recurringExpense.Description.String,
-			RecurrenceInterval: recurringExpense.RecurrenceInterval,
-			NextOccurrence:     recurringExpense.NextOccurrence,
-			ProjectedAmount:    decimal.RequireFromString(recurringExpense.ProjectedAmount),
-			CreatedAt:          recurringExpense.CreatedAt.Time,
-			UpdatedAt:          recurringExpense.UpdatedAt.Time,
+			ID:                      recurringExpense.ID,
+			UserID:                  recurringExpense.UserID,
+			BudgetID:                recurringExpense.BudgetID,
+			Amount:                  decimal.RequireFromString(recurringExpense.Amount),
+			Name:                    recurringExpense.Name,
+			Description:             recurringExpense.Description.String,
+			RecurrenceInterval:      recurringExpense.RecurrenceInterval,
+			NextOccurrence:          recurringExpense.NextOccurrence,
+			ProjectedAmount:         decimal.RequireFromString(recurringExpense.ProjectedAmount),
+			CreatedAt:               recurringExpense.CreatedAt.Time,
+			UpdatedAt:               recurringExpense.UpdatedAt.Time,
+			IntervalCount:           recurringExpense.IntervalCount,
+			DayOfMonth:              int32(recurringExpense.DayOfMonth.Int16),
+			StartDate:               recurringExpense.StartDate,
+			LastOccurrence:          recurringExpense.LastOccurrence.Time,
+			EndDate:                 recurringExpense.EndDate.Time,
+			MaxOccurrences:          recurringExpense.MaxOccurrences.Int32,
+			OccurrenceCount:         recurringExpense.OccurrenceCount,
+			IsPaused:                recurringExpense.IsPaused,
+			FirstOccurrenceRecorded: recurringExpense.FirstOccurrenceRecorded,
 		}
 	case database.GetAllRecurringExpensesByUserIDRow:
 		return &RecurringExpense{
@@ -1105,6 +2352,14 @@ func populateRecurringExpense(recurringExpensRow interface{}) *RecurringExpense
 			ProjectedAmount:    decimal.RequireFromString(recurringExpense.ProjectedAmount),
 			CreatedAt:          recurringExpense.CreatedAt.Time,
 			UpdatedAt:          recurringExpense.UpdatedAt.Time,
+			IntervalCount:      recurringExpense.IntervalCount,
+			DayOfMonth:         int32(recurringExpense.DayOfMonth.Int16),
+			StartDate:          recurringExpense.StartDate,
+			LastOccurrence:     recurringExpense.LastOccurrence.Time,
+			EndDate:            recurringExpense.EndDate.Time,
+			MaxOccurrences:     recurringExpense.MaxOccurrences.Int32,
+			OccurrenceCount:    recurringExpense.OccurrenceCount,
+			IsPaused:           recurringExpense.IsPaused,
 		}
 	case database.GetAllRecurringExpensesDueForProcessingRow:
 		return &RecurringExpense{
@@ -1119,6 +2374,14 @@ func populateRecurringExpense(recurringExpensRow interface{}) *RecurringExpense
 			NextOccurrence:     recurringExpense.NextOccurrence,
 			CreatedAt:          recurringExpense.CreatedAt.Time,
 			UpdatedAt:          recurringExpense.UpdatedAt.Time,
+			IntervalCount:      recurringExpense.IntervalCount,
+			DayOfMonth:         int32(recurringExpense.DayOfMonth.Int16),
+			StartDate:          recurringExpense.StartDate,
+			LastOccurrence:     recurringExpense.LastOccurrence.Time,
+			EndDate:            recurringExpense.EndDate.Time,
+			MaxOccurrences:     recurringExpense.MaxOccurrences.Int32,
+			OccurrenceCount:    recurringExpense.OccurrenceCount,
+			IsPaused:           recurringExpense.IsPaused,
 		}
 	default:
 		return nil
@@ -1138,22 +2401,28 @@ func populateExpense(expenseRow interface{}) *Expense {
 			IsRecurring:  expense.IsRecurring,
 			Description:  expense.Description.String,
 			DateOccurred: expense.DateOccurred,
+			AccountID:    expense.AccountID.Int64,
+			IsCleared:    expense.IsCleared,
 			CreatedAt:    expense.CreatedAt.Time,
 			UpdatedAt:    expense.UpdatedAt.Time,
 		}
 	case database.GetAllExpensesByUserIDRow:
 		return &Expense{
-			ID:           expense.ID,
-			UserID:       expense.UserID,
-			BudgetID:     expense.BudgetID,
-			Name:         expense.Name,
-			Category:     expense.Category,
-			Amount:       decimal.RequireFromString(expense.Amount),
-			IsRecurring:  expense.IsRecurring,
-			Description:  expense.Description.String,
-			DateOccurred: expense.DateOccurred,
-			CreatedAt:    expense.CreatedAt.Time,
-			UpdatedAt:    expense.UpdatedAt.Time,
+			ID:            expense.ID,
+			UserID:        expense.UserID,
+			BudgetID:      expense.BudgetID,
+			Name:          expense.Name,
+			Category:      expense.Category,
+			Amount:        decimal.RequireFromString(expense.Amount),
+			IsRecurring:   expense.IsRecurring,
+			Description:   expense.Description.String,
+			DateOccurred:  expense.DateOccurred,
+			AccountID:     expense.AccountID.Int64,
+			IsCleared:     expense.IsCleared,
+			Tags:          expense.Tags,
+			TaxCategories: expense.TaxCategories,
+			CreatedAt:     expense.CreatedAt.Time,
+			UpdatedAt:     expense.UpdatedAt.Time,
 		}
 	default:
 		return nil
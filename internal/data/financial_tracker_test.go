@@ -0,0 +1,760 @@
+package data
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/shopspring/decimal"
+)
+
+// TestRecurringIncome_CalculateNextOccurrence tests that the next occurrence is anchored
+// to the previous occurrence and that monthly and yearly incomes keep their day of the month.
+func TestRecurringIncome_CalculateNextOccurrence(t *testing.T) {
+	tests := []struct {
+		name       string
+		interval   database.RecurrenceIntervalEnum
+		from       time.Time
+		dayOfMonth int32
+		want       time.Time
+	}{
+		{
+			name:     "Daily",
+			interval: database.RecurrenceIntervalEnumDaily,
+			from:     time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Weekly",
+			interval: database.RecurrenceIntervalEnumWeekly,
+			from:     time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, time.February, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Monthly clamps to the end of a shorter month",
+			interval:   database.RecurrenceIntervalEnumMonthly,
+			from:       time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+			dayOfMonth: 31,
+			want:       time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Monthly goes back to the anchored day",
+			interval:   database.RecurrenceIntervalEnumMonthly,
+			from:       time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			dayOfMonth: 31,
+			want:       time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Yearly from a leap day",
+			interval:   database.RecurrenceIntervalEnumYearly,
+			from:       time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			dayOfMonth: 29,
+			want:       time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ri := &RecurringIncome{RecurrenceInterval: tt.interval, NextOccurrence: tt.from, DayOfMonth: tt.dayOfMonth}
+			ri.CalculateNextOccurrence()
+			if !ri.NextOccurrence.Equal(tt.want) {
+				t.Errorf("CalculateNextOccurrence() = %v, want %v", ri.NextOccurrence, tt.want)
+			}
+		})
+	}
+}
+
+// TestRecurringIncome_AnchorSchedule tests which schedules are anchored to a day of the month.
+func TestRecurringIncome_AnchorSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval database.RecurrenceIntervalEnum
+		want     int32
+	}{
+		{name: "Weekly", interval: database.RecurrenceIntervalEnumWeekly, want: 0},
+		{name: "Monthly", interval: database.RecurrenceIntervalEnumMonthly, want: 31},
+		{name: "Yearly", interval: database.RecurrenceIntervalEnumYearly, want: 31},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ri := &RecurringIncome{RecurrenceInterval: tt.interval, NextOccurrence: time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), DayOfMonth: 15}
+			ri.AnchorSchedule()
+			if ri.DayOfMonth != tt.want {
+				t.Errorf("AnchorSchedule() day of month = %d, want %d", ri.DayOfMonth, tt.want)
+			}
+		})
+	}
+}
+
+// TestRecurringIncome_CalculateGoalAllocation tests the goal allocation of a posted income.
+func TestRecurringIncome_CalculateGoalAllocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		goalID     int64
+		percentage string
+		amount     string
+		want       string
+	}{
+		{
+			name:       "No goal linked",
+			goalID:     0,
+			percentage: "10",
+			amount:     "1000",
+			want:       "0",
+		},
+		{
+			name:       "Zero percentage",
+			goalID:     1,
+			percentage: "0",
+			amount:     "1000",
+			want:       "0",
+		},
+		{
+			name:       "Ten percent",
+			goalID:     1,
+			percentage: "10",
+			amount:     "1000",
+			want:       "100",
+		},
+		{
+			name:       "Rounded to two decimal places",
+			goalID:     1,
+			percentage: "33.33",
+			amount:     "100.01",
+			want:       "33.33",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ri := &RecurringIncome{
+				GoalID:                   tt.goalID,
+				GoalAllocationPercentage: decimal.RequireFromString(tt.percentage),
+			}
+			got := ri.CalculateGoalAllocation(decimal.RequireFromString(tt.amount))
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("CalculateGoalAllocation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRecurringIncome_HasEnded tests whether a recurring income is past its end date.
+func TestRecurringIncome_HasEnded(t *testing.T) {
+	next := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		endDate time.Time
+		want    bool
+	}{
+		{name: "No end date", endDate: time.Time{}, want: false},
+		{name: "End date after next occurrence", endDate: next.AddDate(0, 1, 0), want: false},
+		{name: "End date on next occurrence", endDate: next, want: false},
+		{name: "End date before next occurrence", endDate: next.AddDate(0, 0, -1), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ri := &RecurringIncome{NextOccurrence: next, EndDate: tt.endDate}
+			if got := ri.HasEnded(); got != tt.want {
+				t.Errorf("HasEnded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_calculateRecurrence tests the RRULE-style recurrence calculation including
+// interval counts and end-of-month handling.
+func Test_calculateRecurrence(t *testing.T) {
+	tests := []struct {
+		name          string
+		from          time.Time
+		frequency     database.RecurrenceIntervalEnum
+		intervalCount int32
+		dayOfMonth    int32
+		want          time.Time
+	}{
+		{
+			name:          "Every 3 days",
+			from:          time.Date(2024, time.February, 27, 0, 0, 0, 0, time.UTC),
+			frequency:     database.RecurrenceIntervalEnumDaily,
+			intervalCount: 3,
+			want:          time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Bi-weekly",
+			from:          time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+			frequency:     database.RecurrenceIntervalEnumWeekly,
+			intervalCount: 2,
+			want:          time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Monthly on the 31st clamps to a leap February",
+			from:          time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+			frequency:     database.RecurrenceIntervalEnumMonthly,
+			intervalCount: 1,
+			dayOfMonth:    31,
+			want:          time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Monthly on the 31st returns to the 31st after a short month",
+			from:          time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			frequency:     database.RecurrenceIntervalEnumMonthly,
+			intervalCount: 1,
+			dayOfMonth:    31,
+			want:          time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Quarterly across a year end",
+			from:          time.Date(2024, time.November, 30, 0, 0, 0, 0, time.UTC),
+			frequency:     database.RecurrenceIntervalEnumMonthly,
+			intervalCount: 3,
+			dayOfMonth:    30,
+			want:          time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Specific day of month",
+			from:          time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC),
+			frequency:     database.RecurrenceIntervalEnumMonthly,
+			intervalCount: 1,
+			dayOfMonth:    15,
+			want:          time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "Yearly on a leap day",
+			from:          time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			frequency:     database.RecurrenceIntervalEnumYearly,
+			intervalCount: 1,
+			dayOfMonth:    29,
+			want:          time.Date(2025, time.February, 28, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateRecurrence(tt.from, tt.frequency, tt.intervalCount, tt.dayOfMonth)
+			if !got.Equal(tt.want) {
+				t.Errorf("calculateRecurrence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFinancialTrackingModel_MapToRecurrenceRule tests the mapping of the supported recurrence intervals.
+func TestFinancialTrackingModel_MapToRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		name          string
+		interval      string
+		intervalCount int32
+		wantFrequency database.RecurrenceIntervalEnum
+		wantCount     int32
+		wantErr       bool
+	}{
+		{name: "Monthly defaults to 1", interval: "monthly", wantFrequency: database.RecurrenceIntervalEnumMonthly, wantCount: 1},
+		{name: "Every 10 days", interval: "daily", intervalCount: 10, wantFrequency: database.RecurrenceIntervalEnumDaily, wantCount: 10},
+		{name: "Bi-weekly", interval: "biweekly", wantFrequency: database.RecurrenceIntervalEnumWeekly, wantCount: 2},
+		{name: "Quarterly", interval: "quarterly", wantFrequency: database.RecurrenceIntervalEnumMonthly, wantCount: 3},
+		{name: "Invalid interval", interval: "hourly", wantErr: true},
+	}
+
+	m := &FinancialTrackingModel{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frequency, count, err := m.MapToRecurrenceRule(tt.interval, tt.intervalCount)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MapToRecurrenceRule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if frequency != tt.wantFrequency || count != tt.wantCount {
+				t.Errorf("MapToRecurrenceRule() = %v, %v, want %v, %v", frequency, count, tt.wantFrequency, tt.wantCount)
+			}
+		})
+	}
+}
+
+// TestRecurringExpense_Schedule tests that a recurring expense schedule is anchored to its
+// start date and respects occurrence counts when catching up or fast forwarding.
+func TestRecurringExpense_Schedule(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	re := &RecurringExpense{RecurrenceInterval: database.RecurrenceIntervalEnumMonthly, MaxOccurrences: 4}
+	re.InitializeSchedule(start, start)
+
+	if re.DayOfMonth != 31 {
+		t.Fatalf("InitializeSchedule() day of month = %v, want 31", re.DayOfMonth)
+	}
+	want := []time.Time{
+		time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC),
+	}
+	for _, occurrence := range want {
+		if re.HasEnded() {
+			t.Fatalf("HasEnded() = true before %v", occurrence)
+		}
+		if !re.NextOccurrence.Equal(occurrence) {
+			t.Fatalf("NextOccurrence = %v, want %v", re.NextOccurrence, occurrence)
+		}
+		re.CalculateNextOccurrence()
+	}
+	if !re.HasEnded() {
+		t.Errorf("HasEnded() = false after %v occurrences, want true", re.OccurrenceCount)
+	}
+
+	// fast forwarding skips past occurrences without going past the end of the schedule
+	re = &RecurringExpense{RecurrenceInterval: database.RecurrenceIntervalEnumWeekly, EndDate: start.AddDate(0, 0, 21)}
+	re.InitializeSchedule(start, start)
+	re.FastForward(start.AddDate(1, 0, 0))
+	if !re.HasEnded() || re.OccurrenceCount != 4 {
+		t.Errorf("FastForward() occurrence count = %v, ended = %v, want 4, true", re.OccurrenceCount, re.HasEnded())
+	}
+
+	// a schedule starting later has nothing elapsed and first occurs on its start date
+	re = &RecurringExpense{RecurrenceInterval: database.RecurrenceIntervalEnumMonthly}
+	re.InitializeSchedule(start, start.AddDate(0, 0, -10))
+	if !re.NextOccurrence.Equal(start) || re.OccurrenceCount != 0 || !re.LastOccurrence.IsZero() || re.DayOfMonth != 31 {
+		t.Errorf("InitializeSchedule() next = %v, count = %v, last = %v, day = %v, want %v, 0, zero, 31",
+			re.NextOccurrence, re.OccurrenceCount, re.LastOccurrence, re.DayOfMonth, start)
+	}
+}
+
+// TestSimulateDebtPayoff tests the month by month debt payoff simulation and its strategies.
+func TestSimulateDebtPayoff(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	newDebt := func(id int64, balance, rate, minimum string) *Debt {
+		return &Debt{
+			ID:               id,
+			RemainingBalance: decimal.RequireFromString(balance),
+			InterestRate:     decimal.RequireFromString(rate),
+			MinimumPayment:   decimal.RequireFromString(minimum),
+		}
+	}
+	tests := []struct {
+		name           string
+		debts          []*Debt
+		budget         string
+		strategy       database.DebtPayoffStrategyEnum
+		customOrder    []int64
+		wantOrder      []int64
+		wantMonths     []int
+		wantTotalPaid  string
+		wantTotalMonth int
+		wantErr        error
+	}{
+		{
+			name:           "Snowball without interest rolls over minimum payments",
+			debts:          []*Debt{newDebt(1, "1000", "0", "100"), newDebt(2, "300", "0", "50")},
+			budget:         "200",
+			strategy:       DebtPayoffStrategySnowball,
+			wantOrder:      []int64{2, 1},
+			wantMonths:     []int{3, 7},
+			wantTotalPaid:  "1300",
+			wantTotalMonth: 7,
+		},
+		{
+			name:           "Avalanche pays the highest interest rate first",
+			debts:          []*Debt{newDebt(1, "1000", "5", "100"), newDebt(2, "300", "20", "50"), newDebt(3, "500", "10", "50")},
+			budget:         "300",
+			strategy:       DebtPayoffStrategyAvalanche,
+			wantOrder:      []int64{2, 3, 1},
+			wantTotalMonth: 7,
+		},
+		{
+			name:        "Custom order with remaining debts in snowball order",
+			debts:       []*Debt{newDebt(1, "1000", "0", "100"), newDebt(2, "300", "0", "50"), newDebt(3, "500", "0", "50")},
+			budget:      "300",
+			strategy:    DebtPayoffStrategyCustom,
+			customOrder: []int64{1},
+			wantOrder:   []int64{1, 2, 3},
+		},
+		{
+			name:     "Paid off debts are left out",
+			debts:    []*Debt{newDebt(1, "0", "0", "100")},
+			budget:   "100",
+			strategy: DebtPayoffStrategySnowball,
+			wantErr:  ErrGeneralRecordNotFound,
+		},
+		{
+			name:     "Budget below the minimum payments",
+			debts:    []*Debt{newDebt(1, "1000", "0", "100"), newDebt(2, "300", "0", "50")},
+			budget:   "100",
+			strategy: DebtPayoffStrategySnowball,
+			wantErr:  ErrInsufficientPayoffBudget,
+		},
+		{
+			name:     "Interest outgrows the budget",
+			debts:    []*Debt{newDebt(1, "10000", "24", "100")},
+			budget:   "150",
+			strategy: DebtPayoffStrategyAvalanche,
+			wantErr:  ErrDebtPayoffNotPossible,
+		},
+		{
+			name:        "Custom order with an unknown debt",
+			debts:       []*Debt{newDebt(1, "1000", "0", "100")},
+			budget:      "100",
+			strategy:    DebtPayoffStrategyCustom,
+			customOrder: []int64{2},
+			wantErr:     ErrInvalidDebtPayoffOrder,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SimulateDebtPayoff(tt.debts, decimal.RequireFromString(tt.budget), tt.strategy, tt.customOrder, start)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SimulateDebtPayoff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			for i, debtID := range tt.wantOrder {
+				if got.Debts[i].DebtID != debtID {
+					t.Errorf("SimulateDebtPayoff() debt %d = %v, want %v", i, got.Debts[i].DebtID, debtID)
+				}
+			}
+			for i, months := range tt.wantMonths {
+				if got.Debts[i].MonthsToPayoff != months || !got.Debts[i].PayoffDate.Equal(start.AddDate(0, months, 0)) {
+					t.Errorf("SimulateDebtPayoff() debt %d paid off after %v months on %v, want %v", i, got.Debts[i].MonthsToPayoff, got.Debts[i].PayoffDate, months)
+				}
+			}
+			if tt.wantTotalPaid != "" && !got.TotalPaid.Equal(decimal.RequireFromString(tt.wantTotalPaid)) {
+				t.Errorf("SimulateDebtPayoff() total paid = %v, want %v", got.TotalPaid, tt.wantTotalPaid)
+			}
+			if tt.wantTotalMonth != 0 && got.TotalMonths != tt.wantTotalMonth {
+				t.Errorf("SimulateDebtPayoff() total months = %v, want %v", got.TotalMonths, tt.wantTotalMonth)
+			}
+			// everything that was owed plus the interest must have been paid
+			owed := got.TotalInterest
+			for _, debt := range got.Debts {
+				owed = owed.Add(debt.StartingBalance)
+			}
+			if !got.TotalPaid.Equal(owed) {
+				t.Errorf("SimulateDebtPayoff() total paid = %v, want %v", got.TotalPaid, owed)
+			}
+		})
+	}
+}
+
+// TestCompareDebtPayoffStrategies tests that the comparison recommends the cheapest strategy.
+func TestCompareDebtPayoffStrategies(t *testing.T) {
+	debts := []*Debt{
+		{ID: 1, RemainingBalance: decimal.RequireFromString("500"), InterestRate: decimal.RequireFromString("5"), MinimumPayment: decimal.RequireFromString("25")},
+		{ID: 2, RemainingBalance: decimal.RequireFromString("5000"), InterestRate: decimal.RequireFromString("22"), MinimumPayment: decimal.RequireFromString("100")},
+	}
+	got, err := CompareDebtPayoffStrategies(debts, decimal.RequireFromString("400"), []int64{1, 2}, time.Now())
+	if err != nil {
+		t.Fatalf("CompareDebtPayoffStrategies() error = %v", err)
+	}
+	if len(got.Simulations) != 3 {
+		t.Fatalf("CompareDebtPayoffStrategies() simulations = %v, want 3", len(got.Simulations))
+	}
+	if got.RecommendedStrategy != DebtPayoffStrategyAvalanche {
+		t.Errorf("CompareDebtPayoffStrategies() recommended = %v, want %v", got.RecommendedStrategy, DebtPayoffStrategyAvalanche)
+	}
+	if !got.InterestSavings.IsPositive() {
+		t.Errorf("CompareDebtPayoffStrategies() interest savings = %v, want a positive amount", got.InterestSavings)
+	}
+}
+
+// TestBuildDebtAmortizationSchedule tests the amortization table of a debt and the overlay of the actual payments.
+func TestBuildDebtAmortizationSchedule(t *testing.T) {
+	dueDate := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	newPayment := func(date time.Time, amount string) *DebtRepayment {
+		return &DebtRepayment{PaymentDate: date, PaymentAmount: decimal.RequireFromString(amount), PrincipalPayment: decimal.RequireFromString(amount)}
+	}
+	tests := []struct {
+		name                string
+		remainingBalance    string
+		payments            []*DebtRepayment
+		wantStatuses        []string
+		wantDates           []time.Time
+		wantScheduleStatus  string
+		wantVariance        string
+		wantProjectedPayoff time.Time
+	}{
+		{
+			name:             "Behind schedule after a missed payment",
+			remainingBalance: "750",
+			payments:         []*DebtRepayment{newPayment(time.Date(2024, time.January, 20, 10, 0, 0, 0, time.UTC), "250")},
+			wantStatuses:     []string{AmortizationStatusPaid, AmortizationStatusMissed, AmortizationStatusProjected, AmortizationStatusProjected, AmortizationStatusProjected},
+			wantDates: []time.Time{
+				dueDate,
+				time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC),
+			},
+			wantScheduleStatus:  DebtScheduleStatusBehind,
+			wantVariance:        "-250",
+			wantProjectedPayoff: time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:             "Ahead of schedule after an extra payment",
+			remainingBalance: "250",
+			payments: []*DebtRepayment{
+				newPayment(time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC), "250"),
+				newPayment(time.Date(2024, time.February, 10, 0, 0, 0, 0, time.UTC), "500"),
+			},
+			wantStatuses:        []string{AmortizationStatusPaid, AmortizationStatusPaid, AmortizationStatusProjected},
+			wantScheduleStatus:  DebtScheduleStatusAhead,
+			wantVariance:        "250",
+			wantProjectedPayoff: time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:             "Partial payment",
+			remainingBalance: "500",
+			payments: []*DebtRepayment{
+				newPayment(time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC), "400"),
+				newPayment(time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), "100"),
+			},
+			wantStatuses:       []string{AmortizationStatusPaid, AmortizationStatusPartial, AmortizationStatusProjected, AmortizationStatusProjected},
+			wantScheduleStatus: DebtScheduleStatusOnTrack,
+			wantVariance:       "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			debt := &Debt{
+				Amount:           decimal.RequireFromString("1000"),
+				RemainingBalance: decimal.RequireFromString(tt.remainingBalance),
+				InterestRate:     decimal.Zero,
+				MinimumPayment:   decimal.RequireFromString("250"),
+				DueDate:          dueDate,
+			}
+			got, err := BuildDebtAmortizationSchedule(debt, tt.payments, asOf)
+			if err != nil {
+				t.Fatalf("BuildDebtAmortizationSchedule() error = %v", err)
+			}
+			if len(got.Entries) != len(tt.wantStatuses) {
+				t.Fatalf("BuildDebtAmortizationSchedule() entries = %v, want %v", len(got.Entries), len(tt.wantStatuses))
+			}
+			for i, entry := range got.Entries {
+				if entry.PaymentNumber != i+1 || entry.Status != tt.wantStatuses[i] {
+					t.Errorf("entry %d = #%v %v, want #%v %v", i, entry.PaymentNumber, entry.Status, i+1, tt.wantStatuses[i])
+				}
+				if tt.wantDates != nil && !entry.PaymentDate.Equal(tt.wantDates[i]) {
+					t.Errorf("entry %d date = %v, want %v", i, entry.PaymentDate, tt.wantDates[i])
+				}
+			}
+			if got.ScheduleStatus != tt.wantScheduleStatus || !got.Variance.Equal(decimal.RequireFromString(tt.wantVariance)) {
+				t.Errorf("BuildDebtAmortizationSchedule() = %v with variance %v, want %v with variance %v", got.ScheduleStatus, got.Variance, tt.wantScheduleStatus, tt.wantVariance)
+			}
+			if !tt.wantProjectedPayoff.IsZero() && !got.ProjectedPayoffDate.Equal(tt.wantProjectedPayoff) {
+				t.Errorf("BuildDebtAmortizationSchedule() projected payoff = %v, want %v", got.ProjectedPayoffDate, tt.wantProjectedPayoff)
+			}
+		})
+	}
+
+	t.Run("Interest is split from the principal", func(t *testing.T) {
+		debt := &Debt{
+			Amount:           decimal.RequireFromString("1000"),
+			RemainingBalance: decimal.RequireFromString("1000"),
+			InterestRate:     decimal.RequireFromString("12"),
+			MinimumPayment:   decimal.RequireFromString("100"),
+			DueDate:          dueDate,
+		}
+		got, err := BuildDebtAmortizationSchedule(debt, nil, dueDate.AddDate(0, 0, -1))
+		if err != nil {
+			t.Fatalf("BuildDebtAmortizationSchedule() error = %v", err)
+		}
+		first, last := got.Entries[0], got.Entries[len(got.Entries)-1]
+		if !first.InterestPortion.Equal(decimal.NewFromInt(10)) || !first.PrincipalPortion.Equal(decimal.NewFromInt(90)) {
+			t.Errorf("first entry = %v interest and %v principal, want 10 and 90", first.InterestPortion, first.PrincipalPortion)
+		}
+		if !last.RemainingBalance.IsZero() {
+			t.Errorf("last entry remaining balance = %v, want 0", last.RemainingBalance)
+		}
+		principal := decimal.Zero
+		for _, entry := range got.Entries {
+			principal = principal.Add(entry.PrincipalPortion)
+		}
+		if !principal.Equal(debt.Amount) {
+			t.Errorf("total principal = %v, want %v", principal, debt.Amount)
+		}
+	})
+
+	t.Run("Actual balance capitalizes unpaid interest and late fees", func(t *testing.T) {
+		debt := &Debt{
+			Amount:           decimal.RequireFromString("1000"),
+			RemainingBalance: decimal.RequireFromString("945"),
+			AccruedInterest:  decimal.RequireFromString("4.50"),
+			InterestRate:     decimal.RequireFromString("12"),
+			MinimumPayment:   decimal.RequireFromString("100"),
+			LateFee:          decimal.RequireFromString("25"),
+			DueDate:          dueDate,
+		}
+		payments := []*DebtRepayment{newPayment(time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC), "100")}
+		got, err := BuildDebtAmortizationSchedule(debt, payments, asOf)
+		if err != nil {
+			t.Fatalf("BuildDebtAmortizationSchedule() error = %v", err)
+		}
+		// 1000 + 10 interest - 100 paid, then 910 + 9.10 interest + 25 late fee for the missed payment
+		for i, want := range []string{"910", "944.10"} {
+			if got := got.Entries[i].ActualBalance; got == nil || !got.Equal(decimal.RequireFromString(want)) {
+				t.Errorf("entry %d actual balance = %v, want %v", i, got, want)
+			}
+		}
+		if !got.ActualBalance.Equal(decimal.RequireFromString("949.50")) {
+			t.Errorf("BuildDebtAmortizationSchedule() actual balance = %v, want 949.50", got.ActualBalance)
+		}
+	})
+
+	t.Run("Minimum payment does not cover the interest", func(t *testing.T) {
+		debt := &Debt{
+			Amount:           decimal.RequireFromString("10000"),
+			RemainingBalance: decimal.RequireFromString("10000"),
+			InterestRate:     decimal.RequireFromString("24"),
+			MinimumPayment:   decimal.RequireFromString("100"),
+			DueDate:          dueDate,
+		}
+		if _, err := BuildDebtAmortizationSchedule(debt, nil, asOf); !errors.Is(err, ErrDebtNeverAmortizes) {
+			t.Errorf("BuildDebtAmortizationSchedule() error = %v, want %v", err, ErrDebtNeverAmortizes)
+		}
+	})
+}
+
+// TestDebt_AccrueInterest tests the daily interest accrual of a debt for the different
+// compounding frequencies and interest rate types.
+func TestDebt_AccrueInterest(t *testing.T) {
+	lastCalculated := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		rate        string
+		rateType    database.DebtInterestRateTypeEnum
+		compounding database.DebtCompoundingFrequencyEnum
+		accrued     string
+		asOf        time.Time
+		want        string
+	}{
+		{
+			name:        "Monthly compounding accrues simple interest",
+			rate:        "36.5",
+			rateType:    DebtInterestRateAPR,
+			compounding: DebtCompoundingMonthly,
+			accrued:     "5",
+			asOf:        time.Date(2024, time.January, 11, 8, 0, 0, 0, time.UTC),
+			want:        "10",
+		},
+		{
+			name:        "Daily compounding includes the accrued interest",
+			rate:        "36.5",
+			rateType:    DebtInterestRateAPR,
+			compounding: DebtCompoundingDaily,
+			accrued:     "5",
+			asOf:        time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC),
+			want:        "2.01",
+		},
+		{
+			name:        "APY compounds to the annual rate",
+			rate:        "10",
+			rateType:    DebtInterestRateAPY,
+			compounding: DebtCompoundingDaily,
+			accrued:     "0",
+			asOf:        lastCalculated.AddDate(0, 0, 365),
+			want:        "100",
+		},
+		{
+			name:        "Nothing accrues on the same day",
+			rate:        "36.5",
+			rateType:    DebtInterestRateAPR,
+			compounding: DebtCompoundingDaily,
+			accrued:     "0",
+			asOf:        lastCalculated.Add(20 * time.Hour),
+			want:        "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			debt := &Debt{
+				RemainingBalance:       decimal.NewFromInt(1000),
+				InterestRate:           decimal.RequireFromString(tt.rate),
+				InterestRateType:       tt.rateType,
+				CompoundingFrequency:   tt.compounding,
+				AccruedInterest:        decimal.RequireFromString(tt.accrued),
+				InterestLastCalculated: lastCalculated,
+			}
+			got := debt.AccrueInterest(tt.asOf)
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("AccrueInterest() = %v, want %v", got, tt.want)
+			}
+			if !debt.AccruedInterest.Equal(decimal.RequireFromString(tt.accrued).Add(got)) {
+				t.Errorf("AccrueInterest() accrued interest = %v, want %v", debt.AccruedInterest, decimal.RequireFromString(tt.accrued).Add(got))
+			}
+		})
+	}
+
+	t.Run("Unset calculation date is set without accruing", func(t *testing.T) {
+		now := time.Now()
+		debt := &Debt{RemainingBalance: decimal.NewFromInt(100), InterestRate: decimal.NewFromInt(10), CreatedAt: now}
+		if got := debt.AccrueInterest(now); !got.IsZero() || !debt.InterestLastCalculated.Equal(now) {
+			t.Errorf("AccrueInterest() = %v with last calculated %v, want 0 with %v", got, debt.InterestLastCalculated, now)
+		}
+	})
+}
+
+// TestDebt_ApplyOverdueCharges tests that missed payments are detected after the grace period
+// and that every missed payment is only charged once.
+func TestDebt_ApplyOverdueCharges(t *testing.T) {
+	nextPayment := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+	debt := &Debt{
+		RemainingBalance:     decimal.NewFromInt(1000),
+		AccruedInterest:      decimal.NewFromInt(20),
+		LateFee:              decimal.NewFromInt(25),
+		GracePeriodDays:      5,
+		NextPaymentDate:      nextPayment,
+		CompoundingFrequency: DebtCompoundingMonthly,
+	}
+	if debt.IsOverdue(nextPayment.AddDate(0, 0, 5)) {
+		t.Fatalf("IsOverdue() = true within the grace period")
+	}
+	asOf := time.Date(2024, time.February, 25, 0, 0, 0, 0, time.UTC)
+	charged := 0
+	for debt.IsOverdue(asOf) {
+		missedDueDate, fee := debt.ApplyOverdueCharges()
+		if want := nextPayment.AddDate(0, charged, 0); !missedDueDate.Equal(want) || !fee.Equal(decimal.NewFromInt(25)) {
+			t.Errorf("ApplyOverdueCharges() = %v, %v, want %v, 25", missedDueDate, fee, want)
+		}
+		charged++
+	}
+	if charged != 2 {
+		t.Errorf("ApplyOverdueCharges() charged %v missed payments, want 2", charged)
+	}
+	// the accrued interest is capitalized once and both late fees are added
+	if !debt.RemainingBalance.Equal(decimal.NewFromInt(1070)) || !debt.AccruedInterest.IsZero() {
+		t.Errorf("RemainingBalance = %v, AccruedInterest = %v, want 1070 and 0", debt.RemainingBalance, debt.AccruedInterest)
+	}
+	// the next payment date stays on the oldest unpaid payment
+	if !debt.LastOverdueDueDate.Equal(nextPayment.AddDate(0, 1, 0)) || !debt.NextPaymentDate.Equal(nextPayment) {
+		t.Errorf("LastOverdueDueDate = %v, NextPaymentDate = %v", debt.LastOverdueDueDate, debt.NextPaymentDate)
+	}
+	// a late payment settles the missed payment without charging it again
+	debt.AdvanceNextPaymentDate()
+	if !debt.NextPaymentDate.Equal(nextPayment.AddDate(0, 1, 0)) || debt.IsOverdue(asOf) {
+		t.Errorf("NextPaymentDate = %v, IsOverdue() = %v, want %v and false", debt.NextPaymentDate, debt.IsOverdue(asOf), nextPayment.AddDate(0, 1, 0))
+	}
+	if !debt.IsOverdue(time.Date(2024, time.March, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("IsOverdue() = false after the following payment was missed")
+	}
+}
+
+// TestDebt_AdvanceNextPaymentDate tests that the next payment date keeps the day of the first due date.
+func TestDebt_AdvanceNextPaymentDate(t *testing.T) {
+	debt := &Debt{
+		DueDate:         time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+		NextPaymentDate: time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+	}
+	for _, want := range []time.Time{
+		time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, time.April, 30, 0, 0, 0, 0, time.UTC),
+	} {
+		debt.AdvanceNextPaymentDate()
+		if !debt.NextPaymentDate.Equal(want) {
+			t.Errorf("AdvanceNextPaymentDate() = %v, want %v", debt.NextPaymentDate, want)
+		}
+	}
+}
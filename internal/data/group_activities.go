@@ -0,0 +1,142 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+// The things members do in a group that make it onto the group's timeline
+const (
+	GroupActivityMemberJoined  = string(database.GroupActivityTypeEnumMemberJoined)
+	GroupActivityMemberLeft    = string(database.GroupActivityTypeEnumMemberLeft)
+	GroupActivityMemberRemoved = string(database.GroupActivityTypeEnumMemberRemoved)
+	GroupActivityRoleChanged   = string(database.GroupActivityTypeEnumRoleChanged)
+	GroupActivityGoalCreated   = string(database.GroupActivityTypeEnumGoalCreated)
+	GroupActivityContribution  = string(database.GroupActivityTypeEnumContribution)
+	GroupActivityExpense       = string(database.GroupActivityTypeEnumExpense)
+	GroupActivityComment       = string(database.GroupActivityTypeEnumComment)
+)
+
+// GroupActivityTypes lists every activity type, in the order they are offered as timeline filters
+var GroupActivityTypes = []string{
+	GroupActivityMemberJoined,
+	GroupActivityMemberLeft,
+	GroupActivityMemberRemoved,
+	GroupActivityRoleChanged,
+	GroupActivityGoalCreated,
+	GroupActivityContribution,
+	GroupActivityExpense,
+	GroupActivityComment,
+}
+
+// GroupActivity is one entry on a group's timeline. SubjectID is what the activity was done to,
+// the member, goal, transaction, expense or comment, and is 0 when there is nothing to point at
+type GroupActivity struct {
+	ID               int64     `json:"id"`
+	GroupID          int64     `json:"group_id"`
+	UserID           int64     `json:"user_id"`
+	ActivityType     string    `json:"activity_type"`
+	SubjectID        int64     `json:"subject_id,omitempty"`
+	Description      string    `json:"description"`
+	CreatedAt        time.Time `json:"created_at"`
+	FirstName        string    `json:"first_name"`
+	LastName         string    `json:"last_name"`
+	ProfileAvatarURL string    `json:"profile_avatar_url"`
+}
+
+// Message() reads the activity out as a sentence, e.g. "Jane Doe joined the group"
+func (a *GroupActivity) Message() string {
+	return fmt.Sprintf("%s %s %s", a.FirstName, a.LastName, a.Description)
+}
+
+// ValidateGroupActivityType() validates the activity type a timeline is filtered by,
+// an empty type means every activity
+func ValidateGroupActivityType(v *validator.Validator, activityType string) {
+	v.Check(activityType == "" || validator.PermittedValue(activityType, GroupActivityTypes...), "type", "invalid activity type")
+}
+
+// CreateGroupActivity() records an activity on a group's timeline and fills in its ID, when it
+// happened and the name of the member who did it
+func (m FinancialGroupManagerModel) CreateGroupActivity(activity *GroupActivity) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateGroupActivity(ctx, database.CreateGroupActivityParams{
+		GroupID:      activity.GroupID,
+		UserID:       activity.UserID,
+		ActivityType: database.GroupActivityTypeEnum(activity.ActivityType),
+		SubjectID:    sql.NullInt64{Int64: activity.SubjectID, Valid: activity.SubjectID != 0},
+		Description:  activity.Description,
+	})
+	if err != nil {
+		return err
+	}
+	activity.ID = row.ID
+	activity.CreatedAt = row.CreatedAt
+	activity.FirstName = row.FirstName
+	activity.LastName = row.LastName
+	activity.ProfileAvatarURL = row.ProfileAvatarUrl
+	return nil
+}
+
+// RecordGroupActivity() runs action and records activity in the same transaction, so nothing
+// happens in a group without making it onto the timeline. action gets a model bound to the
+// transaction and can fill in the activity's subject once it knows it. A nil activity only runs
+// the action, for actions that are not always logged
+func (m FinancialGroupManagerModel) RecordGroupActivity(activity *GroupActivity, action func(txModel FinancialGroupManagerModel) error) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	return withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		txModel := FinancialGroupManagerModel{DB: q}
+		err := action(txModel)
+		if err != nil {
+			return err
+		}
+		if activity == nil {
+			return nil
+		}
+		return txModel.CreateGroupActivity(activity)
+	})
+}
+
+// GetGroupActivitiesByGroupID() returns a page of a group's timeline, newest first.
+// An empty activityType returns every activity
+func (m FinancialGroupManagerModel) GetGroupActivitiesByGroupID(groupID int64, activityType string, filters Filters) ([]*GroupActivity, Metadata, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupActivitiesByGroupID(ctx, database.GetGroupActivitiesByGroupIDParams{
+		GroupID: groupID,
+		Column2: activityType,
+		Limit:   int32(filters.limit()),
+		Offset:  int32(filters.offset()),
+	})
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	totalActivities := 0
+	activities := []*GroupActivity{}
+	for _, row := range rows {
+		totalActivities = int(row.TotalActivities)
+		activities = append(activities, &GroupActivity{
+			ID:               row.ID,
+			GroupID:          row.GroupID,
+			UserID:           row.UserID,
+			ActivityType:     string(row.ActivityType),
+			SubjectID:        row.SubjectID.Int64,
+			Description:      row.Description,
+			CreatedAt:        row.CreatedAt,
+			FirstName:        row.FirstName,
+			LastName:         row.LastName,
+			ProfileAvatarURL: row.ProfileAvatarUrl,
+		})
+	}
+	metadata := calculateMetadata(totalActivities, filters.Page, filters.PageSize)
+	return activities, metadata, nil
+}
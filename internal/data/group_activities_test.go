@@ -0,0 +1,38 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+func TestValidateGroupActivityType(t *testing.T) {
+	tests := []struct {
+		name         string
+		activityType string
+		wantValid    bool
+	}{
+		{"every activity", "", true},
+		{"member joined", GroupActivityMemberJoined, true},
+		{"contribution", GroupActivityContribution, true},
+		{"comment", GroupActivityComment, true},
+		{"unknown type", "goal_deleted", false},
+		{"wrong case", "Contribution", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateGroupActivityType(v, tt.activityType)
+			if v.Valid() != tt.wantValid {
+				t.Errorf("ValidateGroupActivityType(%q) valid = %v, want %v (errors: %v)", tt.activityType, v.Valid(), tt.wantValid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestGroupActivityMessage(t *testing.T) {
+	activity := &GroupActivity{FirstName: "Jane", LastName: "Doe", Description: "contributed 50.00 to Holiday"}
+	if got, want := activity.Message(), "Jane Doe contributed 50.00 to Holiday"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+}
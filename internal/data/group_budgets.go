@@ -0,0 +1,291 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// The periods a group budget repeats over
+const (
+	GroupBudgetPeriodWeekly    = string(database.GroupBudgetPeriodEnumWeekly)
+	GroupBudgetPeriodMonthly   = string(database.GroupBudgetPeriodEnumMonthly)
+	GroupBudgetPeriodQuarterly = string(database.GroupBudgetPeriodEnumQuarterly)
+	GroupBudgetPeriodYearly    = string(database.GroupBudgetPeriodEnumYearly)
+)
+
+var (
+	ErrDuplicateGroupBudget = errors.New("the group already has a budget for this category")
+	ErrGroupBudgetExceeded  = errors.New("the expense is more than what is left of the group's strict budget")
+)
+
+// GroupBudget is a limit on what a group spends in an expense category each period.
+// Expenses over the limit of a strict budget are rejected, other budgets only warn
+type GroupBudget struct {
+	ID            int64           `json:"id"`
+	GroupID       int64           `json:"group_id"`
+	CreatorUserID int64           `json:"creator_user_id"`
+	Category      string          `json:"category"`
+	LimitAmount   decimal.Decimal `json:"limit_amount"`
+	Period        string          `json:"period"`
+	IsStrict      bool            `json:"is_strict"`
+	Description   string          `json:"description"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// GroupBudgetSummary is a group budget with what has been spent against it in the current period
+type GroupBudgetSummary struct {
+	*GroupBudget
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+	Spent       decimal.Decimal `json:"spent"`
+	Remaining   decimal.Decimal `json:"remaining"`
+	PercentUsed decimal.Decimal `json:"percent_used"`
+	IsOverLimit bool            `json:"is_over_limit"`
+}
+
+// ValidateGroupBudget() validates a group budget
+func ValidateGroupBudget(v *validator.Validator, budget *GroupBudget) {
+	ValidateURLID(v, budget.GroupID, "group_id")
+	v.Check(strings.TrimSpace(budget.Category) != "", "category", "must be provided")
+	v.Check(len(budget.Category) <= 100, "category", "must not be more than 100 bytes long")
+	ValidateAmount(v, budget.LimitAmount, "limit_amount")
+	v.Check(validator.PermittedValue(budget.Period, GroupBudgetPeriodWeekly, GroupBudgetPeriodMonthly, GroupBudgetPeriodQuarterly, GroupBudgetPeriodYearly),
+		"period", "must be one of weekly, monthly, quarterly or yearly")
+	v.Check(len(budget.Description) <= 500, "description", "must not be more than 500 bytes long")
+}
+
+// GroupBudgetPeriodEnd() returns when the budget period that started at periodStart ends
+func GroupBudgetPeriodEnd(period string, periodStart time.Time) time.Time {
+	switch period {
+	case GroupBudgetPeriodWeekly:
+		return periodStart.AddDate(0, 0, 7)
+	case GroupBudgetPeriodMonthly:
+		return periodStart.AddDate(0, 1, 0)
+	case GroupBudgetPeriodQuarterly:
+		return periodStart.AddDate(0, 3, 0)
+	default:
+		return periodStart.AddDate(1, 0, 0)
+	}
+}
+
+// NewGroupBudgetSummary() works out what is left of a budget in the period that started at periodStart
+func NewGroupBudgetSummary(budget *GroupBudget, periodStart time.Time, spent decimal.Decimal) *GroupBudgetSummary {
+	summary := &GroupBudgetSummary{
+		GroupBudget: budget,
+		PeriodStart: periodStart,
+		PeriodEnd:   GroupBudgetPeriodEnd(budget.Period, periodStart),
+		Spent:       spent,
+		Remaining:   budget.LimitAmount.Sub(spent),
+		IsOverLimit: spent.GreaterThan(budget.LimitAmount),
+	}
+	if budget.LimitAmount.IsPositive() {
+		summary.PercentUsed = spent.Div(budget.LimitAmount).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+	return summary
+}
+
+// Exceeds() reports whether spending amount more would take the budget over its limit
+func (s *GroupBudgetSummary) Exceeds(amount decimal.Decimal) bool {
+	return s.Spent.Add(amount).GreaterThan(s.LimitAmount)
+}
+
+// FindGroupBudgetForCategory() returns the budget for an expense category, ignoring case, or nil
+func FindGroupBudgetForCategory(summaries []*GroupBudgetSummary, category string) *GroupBudgetSummary {
+	for _, summary := range summaries {
+		if strings.EqualFold(strings.TrimSpace(summary.Category), strings.TrimSpace(category)) {
+			return summary
+		}
+	}
+	return nil
+}
+
+// CreateNewGroupBudget() creates a budget for a group. Only group admins can create budgets,
+// for anyone else ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) CreateNewGroupBudget(userID int64, budget *GroupBudget) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateNewGroupBudget(ctx, database.CreateNewGroupBudgetParams{
+		GroupID:       budget.GroupID,
+		CreatorUserID: sql.NullInt64{Int64: userID, Valid: true},
+		Category:      strings.TrimSpace(budget.Category),
+		LimitAmount:   budget.LimitAmount.String(),
+		Period:        database.GroupBudgetPeriodEnum(budget.Period),
+		IsStrict:      budget.IsStrict,
+		Description:   sql.NullString{String: budget.Description, Valid: budget.Description != ""},
+		Column8:       GroupPermissionRoles(GroupPermissionManageBudgets),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		case err.Error() == `pq: duplicate key value violates unique constraint "idx_group_budgets_group_id_category"`:
+			return ErrDuplicateGroupBudget
+		default:
+			return err
+		}
+	}
+	budget.ID = row.ID
+	budget.CreatorUserID = userID
+	budget.Category = strings.TrimSpace(budget.Category)
+	budget.CreatedAt = row.CreatedAt
+	budget.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+// UpdateGroupBudget() saves changes to a group budget. Only group admins can change budgets,
+// for anyone else ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) UpdateGroupBudget(userID int64, budget *GroupBudget) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	updatedAt, err := m.DB.UpdateGroupBudget(ctx, database.UpdateGroupBudgetParams{
+		Category:    strings.TrimSpace(budget.Category),
+		LimitAmount: budget.LimitAmount.String(),
+		Period:      database.GroupBudgetPeriodEnum(budget.Period),
+		IsStrict:    budget.IsStrict,
+		Description: sql.NullString{String: budget.Description, Valid: budget.Description != ""},
+		ID:          budget.ID,
+		UserID:      sql.NullInt64{Int64: userID, Valid: true},
+		Column8:     GroupPermissionRoles(GroupPermissionManageBudgets),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		case err.Error() == `pq: duplicate key value violates unique constraint "idx_group_budgets_group_id_category"`:
+			return ErrDuplicateGroupBudget
+		default:
+			return err
+		}
+	}
+	budget.Category = strings.TrimSpace(budget.Category)
+	budget.UpdatedAt = updatedAt
+	return nil
+}
+
+// DeleteGroupBudget() deletes a group budget. Only group admins can delete budgets,
+// for anyone else ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) DeleteGroupBudget(userID, budgetID int64) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	_, err := m.DB.DeleteGroupBudget(ctx, database.DeleteGroupBudgetParams{
+		ID:      budgetID,
+		UserID:  sql.NullInt64{Int64: userID, Valid: true},
+		Column3: GroupPermissionRoles(GroupPermissionManageBudgets),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGroupBudgetByID() returns a group budget
+func (m FinancialGroupManagerModel) GetGroupBudgetByID(budgetID int64) (*GroupBudget, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	budget, err := m.DB.GetGroupBudgetByID(ctx, budgetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return populateGroupBudget(budget), nil
+}
+
+// GetGroupBudgetSummariesByGroupID() returns the budgets of a group with what has been spent
+// against each of them in their current period
+func (m FinancialGroupManagerModel) GetGroupBudgetSummariesByGroupID(groupID int64) ([]*GroupBudgetSummary, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupBudgetSummariesByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	summaries := []*GroupBudgetSummary{}
+	for _, row := range rows {
+		budget := populateGroupBudget(database.GroupBudget{
+			ID:            row.ID,
+			GroupID:       row.GroupID,
+			CreatorUserID: row.CreatorUserID,
+			Category:      row.Category,
+			LimitAmount:   row.LimitAmount,
+			Period:        row.Period,
+			IsStrict:      row.IsStrict,
+			Description:   row.Description,
+			CreatedAt:     row.CreatedAt,
+			UpdatedAt:     row.UpdatedAt,
+		})
+		summaries = append(summaries, NewGroupBudgetSummary(budget, row.PeriodStart.UTC(), decimal.RequireFromString(row.Spent)))
+	}
+	return summaries, nil
+}
+
+// CreateNewGroupExpenseWithinBudget() creates a group expense after checking it against the group's
+// budget for its category. The budget stays locked until the expense is saved, so expenses added at
+// the same time can not go over a strict budget together. The budget is returned when the expense
+// goes over it, with ErrGroupBudgetExceeded and nothing saved when the budget is strict
+func (m FinancialGroupManagerModel) CreateNewGroupExpenseWithinBudget(userID int64, expense *GroupExpense) (*GroupBudgetSummary, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	var exceeded *GroupBudgetSummary
+	err := withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		_, err := q.LockGroupBudgetsByCategory(ctx, database.LockGroupBudgetsByCategoryParams{
+			GroupID: expense.GroupID,
+			Column2: expense.Category,
+		})
+		if err != nil {
+			return err
+		}
+		txModel := FinancialGroupManagerModel{DB: q}
+		budgets, err := txModel.GetGroupBudgetSummariesByGroupID(expense.GroupID)
+		if err != nil {
+			return err
+		}
+		budget := FindGroupBudgetForCategory(budgets, expense.Category)
+		if budget != nil && budget.Exceeds(expense.Amount) {
+			exceeded = budget
+			if budget.IsStrict {
+				return ErrGroupBudgetExceeded
+			}
+		}
+		return txModel.CreateNewGroupExpense(userID, expense)
+	})
+	return exceeded, err
+}
+
+// populateGroupBudget() maps a database group budget to a GroupBudget
+func populateGroupBudget(budget database.GroupBudget) *GroupBudget {
+	return &GroupBudget{
+		ID:            budget.ID,
+		GroupID:       budget.GroupID,
+		CreatorUserID: budget.CreatorUserID.Int64,
+		Category:      budget.Category,
+		LimitAmount:   decimal.RequireFromString(budget.LimitAmount),
+		Period:        string(budget.Period),
+		IsStrict:      budget.IsStrict,
+		Description:   budget.Description.String,
+		CreatedAt:     budget.CreatedAt,
+		UpdatedAt:     budget.UpdatedAt,
+	}
+}
@@ -0,0 +1,132 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+func TestGroupBudgetPeriodEnd(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		period string
+		want   time.Time
+	}{
+		{GroupBudgetPeriodWeekly, time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)},
+		{GroupBudgetPeriodMonthly, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{GroupBudgetPeriodQuarterly, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{GroupBudgetPeriodYearly, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			if got := GroupBudgetPeriodEnd(tt.period, start); !got.Equal(tt.want) {
+				t.Errorf("GroupBudgetPeriodEnd() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGroupBudgetSummary(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	budget := &GroupBudget{Category: "Groceries", LimitAmount: decimal.NewFromInt(400), Period: GroupBudgetPeriodMonthly}
+	tests := []struct {
+		name          string
+		spent         string
+		wantRemaining string
+		wantPercent   string
+		wantOver      bool
+	}{
+		{"nothing spent", "0", "400", "0", false},
+		{"part spent", "150", "250", "37.5", false},
+		{"exactly at the limit", "400", "0", "100", false},
+		{"over the limit", "450.5", "-50.5", "112.63", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := NewGroupBudgetSummary(budget, start, decimal.RequireFromString(tt.spent))
+			if !summary.Remaining.Equal(decimal.RequireFromString(tt.wantRemaining)) {
+				t.Errorf("remaining = %s, want %s", summary.Remaining, tt.wantRemaining)
+			}
+			if !summary.PercentUsed.Equal(decimal.RequireFromString(tt.wantPercent)) {
+				t.Errorf("percent used = %s, want %s", summary.PercentUsed, tt.wantPercent)
+			}
+			if summary.IsOverLimit != tt.wantOver {
+				t.Errorf("is over limit = %v, want %v", summary.IsOverLimit, tt.wantOver)
+			}
+			if !summary.PeriodEnd.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("period end = %v, want 2024-04-01", summary.PeriodEnd)
+			}
+		})
+	}
+}
+
+func TestGroupBudgetSummaryExceeds(t *testing.T) {
+	summary := NewGroupBudgetSummary(&GroupBudget{LimitAmount: decimal.NewFromInt(100)}, time.Now(), decimal.NewFromInt(60))
+	tests := []struct {
+		amount string
+		want   bool
+	}{
+		{"39.99", false},
+		{"40", false},
+		{"40.01", true},
+	}
+	for _, tt := range tests {
+		if got := summary.Exceeds(decimal.RequireFromString(tt.amount)); got != tt.want {
+			t.Errorf("Exceeds(%s) = %v, want %v", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestFindGroupBudgetForCategory(t *testing.T) {
+	summaries := []*GroupBudgetSummary{
+		{GroupBudget: &GroupBudget{ID: 1, Category: "Groceries"}},
+		{GroupBudget: &GroupBudget{ID: 2, Category: "Travel"}},
+	}
+	tests := []struct {
+		category string
+		wantID   int64
+	}{
+		{"Groceries", 1},
+		{"  travel ", 2},
+		{"Rent", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		got := FindGroupBudgetForCategory(summaries, tt.category)
+		switch {
+		case tt.wantID == 0 && got != nil:
+			t.Errorf("FindGroupBudgetForCategory(%q) = budget %d, want none", tt.category, got.ID)
+		case tt.wantID != 0 && (got == nil || got.ID != tt.wantID):
+			t.Errorf("FindGroupBudgetForCategory(%q) did not return budget %d", tt.category, tt.wantID)
+		}
+	}
+}
+
+func TestValidateGroupBudget(t *testing.T) {
+	limit := decimal.NewFromInt(300)
+	tests := []struct {
+		name    string
+		budget  GroupBudget
+		wantKey string
+	}{
+		{"valid", GroupBudget{GroupID: 1, Category: "Groceries", LimitAmount: limit, Period: GroupBudgetPeriodMonthly}, ""},
+		{"missing group", GroupBudget{Category: "Groceries", LimitAmount: limit, Period: GroupBudgetPeriodMonthly}, "group_id"},
+		{"blank category", GroupBudget{GroupID: 1, Category: "  ", LimitAmount: limit, Period: GroupBudgetPeriodMonthly}, "category"},
+		{"zero limit", GroupBudget{GroupID: 1, Category: "Groceries", Period: GroupBudgetPeriodMonthly}, "limit_amount"},
+		{"unknown period", GroupBudget{GroupID: 1, Category: "Groceries", LimitAmount: limit, Period: "daily"}, "period"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateGroupBudget(v, &tt.budget)
+			if tt.wantKey == "" && !v.Valid() {
+				t.Errorf("ValidateGroupBudget() errors = %v, want none", v.Errors)
+			}
+			if _, ok := v.Errors[tt.wantKey]; tt.wantKey != "" && !ok {
+				t.Errorf("ValidateGroupBudget() errors = %v, want an error for %s", v.Errors, tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// The kinds of group challenges. Savings challenges are won by contributing the target amount,
+// no-spend challenges by not adding a group expense, or not at weekends for weekends-only ones
+const (
+	GroupChallengeTypeSavings = string(database.GroupChallengeTypeEnumSavings)
+	GroupChallengeTypeNoSpend = string(database.GroupChallengeTypeEnumNoSpend)
+)
+
+// The states a challenge goes through. A challenge ends once its last day has passed and the
+// results are in
+const (
+	GroupChallengeStatusActive = string(database.GroupChallengeStatusEnumActive)
+	GroupChallengeStatusEnded  = string(database.GroupChallengeStatusEnumEnded)
+)
+
+const (
+	// GroupChallengeMaxDuration is the longest a challenge can run for
+	GroupChallengeMaxDuration = 365 * 24 * time.Hour
+	// GroupChallengeAwardCode is the award given for completing a group challenge
+	GroupChallengeAwardCode = "group_challenge_completed"
+)
+
+var (
+	ErrDuplicateGroupChallengeParticipant = errors.New("you are already taking part in this challenge")
+)
+
+// GroupChallenge is a time-boxed challenge inside a group that members opt into.
+// TargetAmount is only set for savings challenges and WeekendsOnly only for no-spend ones
+type GroupChallenge struct {
+	ID               int64           `json:"id"`
+	GroupID          int64           `json:"group_id"`
+	CreatorUserID    int64           `json:"creator_user_id"`
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	ChallengeType    string          `json:"challenge_type"`
+	TargetAmount     decimal.Decimal `json:"target_amount"`
+	WeekendsOnly     bool            `json:"weekends_only"`
+	StartsOn         time.Time       `json:"starts_on"`
+	EndsOn           time.Time       `json:"ends_on"`
+	Status           string          `json:"status"`
+	ParticipantCount int64           `json:"participant_count"`
+	IsParticipant    bool            `json:"is_participant"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+// GroupChallengeStanding is a participant's place on a challenge's leaderboard. Progress is the
+// amount saved for savings challenges and the number of days with spending for no-spend ones.
+// PreviousRank is the rank when the standings were last checked, 0 if they never were
+type GroupChallengeStanding struct {
+	Rank             int32           `json:"rank"`
+	PreviousRank     int32           `json:"-"`
+	UserID           int64           `json:"user_id"`
+	GoalID           int64           `json:"goal_id,omitempty"`
+	Progress         decimal.Decimal `json:"progress"`
+	CompletedAt      *time.Time      `json:"completed_at,omitempty"`
+	JoinedAt         time.Time       `json:"joined_at"`
+	FirstName        string          `json:"first_name"`
+	LastName         string          `json:"last_name"`
+	ProfileAvatarURL string          `json:"profile_avatar_url"`
+}
+
+// ValidateGroupChallenge() validates a new challenge. Challenges start today at the earliest and
+// run for at most GroupChallengeMaxDuration
+func ValidateGroupChallenge(v *validator.Validator, challenge *GroupChallenge, today time.Time) {
+	ValidateURLID(v, challenge.GroupID, "group_id")
+	v.Check(challenge.Name != "", "name", "must be provided")
+	v.Check(len(challenge.Name) <= 255, "name", "must not be more than 255 bytes long")
+	v.Check(len(challenge.Description) <= 500, "description", "must not be more than 500 bytes long")
+	v.Check(validator.PermittedValue(challenge.ChallengeType, GroupChallengeTypeSavings, GroupChallengeTypeNoSpend),
+		"challenge_type", "must be one of savings or no_spend")
+	switch challenge.ChallengeType {
+	case GroupChallengeTypeSavings:
+		ValidateAmount(v, challenge.TargetAmount, "target_amount")
+		v.Check(!challenge.WeekendsOnly, "weekends_only", "can only be set for no-spend challenges")
+	case GroupChallengeTypeNoSpend:
+		v.Check(challenge.TargetAmount.IsZero(), "target_amount", "can only be set for savings challenges")
+	}
+	v.Check(!challenge.StartsOn.IsZero(), "starts_on", "must be provided")
+	v.Check(!challenge.EndsOn.IsZero(), "ends_on", "must be provided")
+	v.Check(!challenge.StartsOn.Before(today), "starts_on", "cannot be in the past")
+	v.Check(!challenge.EndsOn.Before(challenge.StartsOn), "ends_on", "cannot be before the start date")
+	v.Check(challenge.EndsOn.Sub(challenge.StartsOn) <= GroupChallengeMaxDuration, "ends_on", "challenges can run for at most a year")
+}
+
+// HasEnded() reports whether the last day of the challenge is behind today
+func (c *GroupChallenge) HasEnded(today time.Time) bool {
+	return today.After(c.EndsOn)
+}
+
+// IsCompletedBy() reports whether a participant with the given progress has completed the
+// challenge. Savings challenges are completed as soon as the target is reached, no-spend
+// challenges only once they have ended without any spending
+func (c *GroupChallenge) IsCompletedBy(progress decimal.Decimal, ended bool) bool {
+	switch c.ChallengeType {
+	case GroupChallengeTypeSavings:
+		return progress.GreaterThanOrEqual(c.TargetAmount)
+	case GroupChallengeTypeNoSpend:
+		return ended && progress.IsZero()
+	default:
+		return false
+	}
+}
+
+// RankChange() returns how many places the participant moved since the standings were last
+// checked, positive when they moved up. Participants whose standing was never checked have not moved
+func (s *GroupChallengeStanding) RankChange() int32 {
+	if s.PreviousRank == 0 {
+		return 0
+	}
+	return s.PreviousRank - s.Rank
+}
+
+// CreateNewGroupChallenge() creates a challenge in a group
+func (m FinancialGroupManagerModel) CreateNewGroupChallenge(userID int64, challenge *GroupChallenge) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateNewGroupChallenge(ctx, database.CreateNewGroupChallengeParams{
+		GroupID:       challenge.GroupID,
+		CreatorUserID: sql.NullInt64{Int64: userID, Valid: true},
+		Name:          challenge.Name,
+		Description:   sql.NullString{String: challenge.Description, Valid: challenge.Description != ""},
+		ChallengeType: database.GroupChallengeTypeEnum(challenge.ChallengeType),
+		TargetAmount:  sql.NullString{String: challenge.TargetAmount.String(), Valid: challenge.ChallengeType == GroupChallengeTypeSavings},
+		WeekendsOnly:  challenge.WeekendsOnly,
+		StartsOn:      challenge.StartsOn,
+		EndsOn:        challenge.EndsOn,
+	})
+	if err != nil {
+		return err
+	}
+	challenge.ID = row.ID
+	challenge.CreatorUserID = userID
+	challenge.Status = string(row.Status)
+	challenge.CreatedAt = row.CreatedAt
+	challenge.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+// GetGroupChallengeByID() returns a challenge by its ID
+func (m FinancialGroupManagerModel) GetGroupChallengeByID(challengeID int64) (*GroupChallenge, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	challenge, err := m.DB.GetGroupChallengeByID(ctx, challengeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return populateGroupChallenge(challenge), nil
+}
+
+// GetGroupChallengesByGroupID() returns the challenges of a group, running ones first, with how
+// many members take part and whether the user is one of them
+func (m FinancialGroupManagerModel) GetGroupChallengesByGroupID(userID, groupID int64) ([]*GroupChallenge, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupChallengesByGroupID(ctx, database.GetGroupChallengesByGroupIDParams{
+		GroupID: groupID,
+		UserID:  userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	challenges := []*GroupChallenge{}
+	for _, row := range rows {
+		challenge := populateGroupChallenge(database.GroupChallenge{
+			ID:            row.ID,
+			GroupID:       row.GroupID,
+			CreatorUserID: row.CreatorUserID,
+			Name:          row.Name,
+			Description:   row.Description,
+			ChallengeType: row.ChallengeType,
+			TargetAmount:  row.TargetAmount,
+			WeekendsOnly:  row.WeekendsOnly,
+			StartsOn:      row.StartsOn,
+			EndsOn:        row.EndsOn,
+			Status:        row.Status,
+			CreatedAt:     row.CreatedAt,
+			UpdatedAt:     row.UpdatedAt,
+		})
+		challenge.ParticipantCount = row.ParticipantCount
+		challenge.IsParticipant = row.IsParticipant
+		challenges = append(challenges, challenge)
+	}
+	return challenges, nil
+}
+
+// DeleteGroupChallenge() deletes a challenge along with its participants
+func (m FinancialGroupManagerModel) DeleteGroupChallenge(challengeID int64) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	_, err := m.DB.DeleteGroupChallenge(ctx, challengeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetActiveGroupChallenges() returns the challenges that have started by today and have not yet
+// ended, leaving out those of archived groups
+func (m FinancialGroupManagerModel) GetActiveGroupChallenges(today time.Time) ([]*GroupChallenge, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetActiveGroupChallenges(ctx, today)
+	if err != nil {
+		return nil, err
+	}
+	challenges := []*GroupChallenge{}
+	for _, row := range rows {
+		challenges = append(challenges, populateGroupChallenge(row))
+	}
+	return challenges, nil
+}
+
+// EndGroupChallenge() marks a challenge as ended before its results are sent. It returns false
+// when the challenge had already been ended, so the results are only ever sent once
+func (m FinancialGroupManagerModel) EndGroupChallenge(challengeID int64) (bool, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	_, err := m.DB.EndGroupChallenge(ctx, challengeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// JoinGroupChallenge() opts a user into a challenge. A personal goal can be linked to a savings
+// challenge so the user's contributions to it count too, goalID is 0 when none is linked
+func (m FinancialGroupManagerModel) JoinGroupChallenge(challengeID, userID, goalID int64) (time.Time, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.JoinGroupChallenge(ctx, database.JoinGroupChallengeParams{
+		ChallengeID: challengeID,
+		UserID:      userID,
+		GoalID:      sql.NullInt64{Int64: goalID, Valid: goalID != 0},
+	})
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "group_challenge_participants_challenge_id_user_id_key"`:
+			return time.Time{}, ErrDuplicateGroupChallengeParticipant
+		default:
+			return time.Time{}, err
+		}
+	}
+	return row.JoinedAt, nil
+}
+
+// LeaveGroupChallenge() opts a user out of a challenge. ErrGeneralRecordNotFound is returned
+// when the user was not taking part
+func (m FinancialGroupManagerModel) LeaveGroupChallenge(challengeID, userID int64) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	_, err := m.DB.LeaveGroupChallenge(ctx, database.LeaveGroupChallengeParams{
+		ChallengeID: challengeID,
+		UserID:      userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGroupChallengeLeaderboard() returns the standings of a challenge's participants, leaders first
+func (m FinancialGroupManagerModel) GetGroupChallengeLeaderboard(challengeID int64) ([]*GroupChallengeStanding, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupChallengeLeaderboard(ctx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	standings := []*GroupChallengeStanding{}
+	for _, row := range rows {
+		standing := &GroupChallengeStanding{
+			Rank:             row.CurrentRank,
+			PreviousRank:     row.PreviousRank.Int32,
+			UserID:           row.UserID,
+			GoalID:           row.GoalID.Int64,
+			Progress:         decimal.RequireFromString(row.Progress),
+			JoinedAt:         row.JoinedAt,
+			FirstName:        row.FirstName,
+			LastName:         row.LastName,
+			ProfileAvatarURL: row.ProfileAvatarUrl,
+		}
+		if row.CompletedAt.Valid {
+			standing.CompletedAt = &row.CompletedAt.Time
+		}
+		standings = append(standings, standing)
+	}
+	return standings, nil
+}
+
+// UpdateGroupChallengeParticipantRank() saves a participant's rank so the next check can tell
+// whether it changed
+func (m FinancialGroupManagerModel) UpdateGroupChallengeParticipantRank(challengeID, userID int64, rank int32) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	return m.DB.UpdateGroupChallengeParticipantRank(ctx, database.UpdateGroupChallengeParticipantRankParams{
+		ChallengeID: challengeID,
+		UserID:      userID,
+		Rank:        sql.NullInt32{Int32: rank, Valid: true},
+	})
+}
+
+// CompleteGroupChallengeParticipant() marks a participant as having completed a challenge. It
+// reports false when they had already completed it
+func (m FinancialGroupManagerModel) CompleteGroupChallengeParticipant(challengeID, userID int64) (bool, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	_, err := m.DB.CompleteGroupChallengeParticipant(ctx, database.CompleteGroupChallengeParticipantParams{
+		ChallengeID: challengeID,
+		UserID:      userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// populateGroupChallenge() maps a database challenge to a GroupChallenge
+func populateGroupChallenge(challenge database.GroupChallenge) *GroupChallenge {
+	groupChallenge := &GroupChallenge{
+		ID:            challenge.ID,
+		GroupID:       challenge.GroupID,
+		CreatorUserID: challenge.CreatorUserID.Int64,
+		Name:          challenge.Name,
+		Description:   challenge.Description.String,
+		ChallengeType: string(challenge.ChallengeType),
+		WeekendsOnly:  challenge.WeekendsOnly,
+		StartsOn:      challenge.StartsOn,
+		EndsOn:        challenge.EndsOn,
+		Status:        string(challenge.Status),
+		CreatedAt:     challenge.CreatedAt,
+		UpdatedAt:     challenge.UpdatedAt,
+	}
+	if challenge.TargetAmount.Valid {
+		groupChallenge.TargetAmount = decimal.RequireFromString(challenge.TargetAmount.String)
+	}
+	return groupChallenge
+}
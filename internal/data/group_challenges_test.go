@@ -0,0 +1,246 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+func TestValidateGroupChallenge(t *testing.T) {
+	today := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		challenge *GroupChallenge
+		wantKey   string
+	}{
+		{
+			name: "valid savings challenge",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "Save 500 in June",
+				ChallengeType: GroupChallengeTypeSavings,
+				TargetAmount:  decimal.NewFromInt(500),
+				StartsOn:      today,
+				EndsOn:        today.AddDate(0, 0, 20),
+			},
+		},
+		{
+			name: "valid no-spend challenge",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "No-spend weekends",
+				ChallengeType: GroupChallengeTypeNoSpend,
+				TargetAmount:  decimal.Zero,
+				WeekendsOnly:  true,
+				StartsOn:      today,
+				EndsOn:        today.AddDate(0, 0, 20),
+			},
+		},
+		{
+			name: "missing name",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "",
+				ChallengeType: GroupChallengeTypeSavings,
+				TargetAmount:  decimal.NewFromInt(500),
+				StartsOn:      today,
+				EndsOn:        today.AddDate(0, 0, 20),
+			},
+			wantKey: "name",
+		},
+		{
+			name: "unknown type",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "Save 500 in June",
+				ChallengeType: "streak",
+				TargetAmount:  decimal.NewFromInt(500),
+				StartsOn:      today,
+				EndsOn:        today.AddDate(0, 0, 20),
+			},
+			wantKey: "challenge_type",
+		},
+		{
+			name: "savings without a target",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "Save 500 in June",
+				ChallengeType: GroupChallengeTypeSavings,
+				TargetAmount:  decimal.Zero,
+				StartsOn:      today,
+				EndsOn:        today.AddDate(0, 0, 20),
+			},
+			wantKey: "target_amount",
+		},
+		{
+			name: "savings at weekends only",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "Save 500 in June",
+				ChallengeType: GroupChallengeTypeSavings,
+				TargetAmount:  decimal.NewFromInt(500),
+				WeekendsOnly:  true,
+				StartsOn:      today,
+				EndsOn:        today.AddDate(0, 0, 20),
+			},
+			wantKey: "weekends_only",
+		},
+		{
+			name: "no-spend with a target",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "No-spend weekends",
+				ChallengeType: GroupChallengeTypeNoSpend,
+				TargetAmount:  decimal.NewFromInt(10),
+				WeekendsOnly:  true,
+				StartsOn:      today,
+				EndsOn:        today.AddDate(0, 0, 20),
+			},
+			wantKey: "target_amount",
+		},
+		{
+			name: "starts in the past",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "Save 500 in June",
+				ChallengeType: GroupChallengeTypeSavings,
+				TargetAmount:  decimal.NewFromInt(500),
+				StartsOn:      today.AddDate(0, 0, -1),
+				EndsOn:        today.AddDate(0, 0, 20),
+			},
+			wantKey: "starts_on",
+		},
+		{
+			name: "missing end",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "Save 500 in June",
+				ChallengeType: GroupChallengeTypeSavings,
+				TargetAmount:  decimal.NewFromInt(500),
+				StartsOn:      today,
+				EndsOn:        time.Time{},
+			},
+			wantKey: "ends_on",
+		},
+		{
+			name: "ends before it starts",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "Save 500 in June",
+				ChallengeType: GroupChallengeTypeSavings,
+				TargetAmount:  decimal.NewFromInt(500),
+				StartsOn:      today,
+				EndsOn:        today.AddDate(0, 0, -1),
+			},
+			wantKey: "ends_on",
+		},
+		{
+			name: "single day",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "Save 500 in June",
+				ChallengeType: GroupChallengeTypeSavings,
+				TargetAmount:  decimal.NewFromInt(500),
+				StartsOn:      today,
+				EndsOn:        today,
+			},
+		},
+		{
+			name: "runs for over a year",
+			challenge: &GroupChallenge{
+				GroupID:       1,
+				Name:          "Save 500 in June",
+				ChallengeType: GroupChallengeTypeSavings,
+				TargetAmount:  decimal.NewFromInt(500),
+				StartsOn:      today,
+				EndsOn:        today.AddDate(1, 0, 1),
+			},
+			wantKey: "ends_on",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateGroupChallenge(v, tt.challenge, today)
+			if tt.wantKey == "" && !v.Valid() {
+				t.Errorf("ValidateGroupChallenge() errors = %v, want none", v.Errors)
+			}
+			if tt.wantKey != "" {
+				if _, ok := v.Errors[tt.wantKey]; !ok {
+					t.Errorf("ValidateGroupChallenge() errors = %v, want an error for %q", v.Errors, tt.wantKey)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupChallengeHasEnded(t *testing.T) {
+	challenge := &GroupChallenge{EndsOn: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		name  string
+		today time.Time
+		want  bool
+	}{
+		{"before the last day", time.Date(2024, 6, 29, 0, 0, 0, 0, time.UTC), false},
+		{"on the last day", time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), false},
+		{"after the last day", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := challenge.HasEnded(tt.today); got != tt.want {
+				t.Errorf("HasEnded(%v) = %v, want %v", tt.today, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupChallengeIsCompletedBy(t *testing.T) {
+	savings := &GroupChallenge{ChallengeType: GroupChallengeTypeSavings, TargetAmount: decimal.NewFromInt(500)}
+	noSpend := &GroupChallenge{ChallengeType: GroupChallengeTypeNoSpend}
+	tests := []struct {
+		name      string
+		challenge *GroupChallenge
+		progress  decimal.Decimal
+		ended     bool
+		want      bool
+	}{
+		{"savings below target", savings, decimal.NewFromFloat(499.99), false, false},
+		{"savings reaches target", savings, decimal.NewFromInt(500), false, true},
+		{"savings passes target", savings, decimal.NewFromInt(750), false, true},
+		{"savings below target at the end", savings, decimal.NewFromInt(100), true, false},
+		{"no-spend still running", noSpend, decimal.Zero, false, false},
+		{"no-spend ended without spending", noSpend, decimal.Zero, true, true},
+		{"no-spend ended with spending", noSpend, decimal.NewFromInt(2), true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.challenge.IsCompletedBy(tt.progress, tt.ended); got != tt.want {
+				t.Errorf("IsCompletedBy(%v, %v) = %v, want %v", tt.progress, tt.ended, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupChallengeStandingRankChange(t *testing.T) {
+	tests := []struct {
+		name         string
+		rank         int32
+		previousRank int32
+		want         int32
+	}{
+		{"never checked", 3, 0, 0},
+		{"unchanged", 2, 2, 0},
+		{"moved up", 1, 3, 2},
+		{"dropped", 4, 2, -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standing := &GroupChallengeStanding{Rank: tt.rank, PreviousRank: tt.previousRank}
+			if got := standing.RankChange(); got != tt.want {
+				t.Errorf("RankChange() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// GroupDueReminderInterval is how long a member goes without hearing about an overdue due
+	GroupDueReminderInterval = 7 * 24 * time.Hour
+)
+
+var (
+	ErrDuplicateGroupContributionSchedule = errors.New("the goal already has a contribution schedule")
+)
+
+// GroupContributionSchedule is a recurring contribution (member dues) to a group goal. Every time
+// the schedule falls due, each accepted member of the group owes AmountPerMember to the goal
+type GroupContributionSchedule struct {
+	ID                 int64           `json:"id"`
+	GroupID            int64           `json:"group_id"`
+	GoalID             int64           `json:"goal_id"`
+	GoalName           string          `json:"goal_name"`
+	CreatorUserID      int64           `json:"creator_user_id"`
+	AmountPerMember    decimal.Decimal `json:"amount_per_member"`
+	RecurrenceInterval string          `json:"recurrence_interval"`
+	DueDay             int32           `json:"due_day"`
+	NextDueDate        time.Time       `json:"next_due_date"`
+	IsActive           bool            `json:"is_active"`
+	Description        string          `json:"description"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+// GroupContributionDue is a contribution a member owes and has not been reminded about recently.
+// Amount is what is still owed on the due after any part payments
+type GroupContributionDue struct {
+	ID        int64           `json:"id"`
+	MemberID  int64           `json:"member_id"`
+	Amount    decimal.Decimal `json:"amount"`
+	DueDate   time.Time       `json:"due_date"`
+	GoalName  string          `json:"goal_name"`
+	GroupName string          `json:"group_name"`
+}
+
+// GroupMemberArrears is what a member has paid and still owes of the dues of a group
+type GroupMemberArrears struct {
+	MemberID      int64           `json:"member_id"`
+	PendingDues   int64           `json:"pending_dues"`
+	AmountOwed    decimal.Decimal `json:"amount_owed"`
+	OldestDueDate *time.Time      `json:"oldest_due_date,omitempty"`
+	DaysOverdue   int             `json:"days_overdue"`
+	PaidDues      int64           `json:"paid_dues"`
+	AmountPaid    decimal.Decimal `json:"amount_paid"`
+}
+
+// ValidateGroupContributionSchedule() validates a contribution schedule. Dues can fall weekly,
+// monthly or yearly and only monthly and yearly schedules take a day of the month
+func ValidateGroupContributionSchedule(v *validator.Validator, schedule *GroupContributionSchedule) {
+	ValidateURLID(v, schedule.GoalID, "goal_id")
+	ValidateAmount(v, schedule.AmountPerMember, "amount_per_member")
+	v.Check(validator.PermittedValue(schedule.RecurrenceInterval,
+		string(database.RecurrenceIntervalEnumWeekly), string(database.RecurrenceIntervalEnumMonthly), string(database.RecurrenceIntervalEnumYearly)),
+		"recurrence_interval", "must be one of weekly, monthly or yearly")
+	v.Check(schedule.DueDay >= 0 && schedule.DueDay <= 31, "due_day", "must be between 1 and 31, or 0 to use the start date's day")
+	v.Check(schedule.DueDay == 0 || schedule.RecurrenceInterval != string(database.RecurrenceIntervalEnumWeekly), "due_day", "can only be set for monthly and yearly schedules")
+	v.Check(len(schedule.Description) <= 500, "description", "must not be more than 500 bytes long")
+}
+
+// InitializeSchedule() sets the first due date of a new schedule to the first due day on or after
+// the start date. Monthly and yearly schedules without a due day fall on the start date's day
+func (s *GroupContributionSchedule) InitializeSchedule(startDate time.Time) {
+	startDate = startDate.Truncate(24 * time.Hour)
+	if s.RecurrenceInterval == string(database.RecurrenceIntervalEnumWeekly) {
+		s.NextDueDate = startDate
+		return
+	}
+	if s.DueDay == 0 {
+		s.DueDay = int32(startDate.Day())
+	}
+	firstDue := addMonthsClamped(startDate, 0, s.DueDay)
+	if firstDue.Before(startDate) {
+		firstDue = addMonthsClamped(startDate, 1, s.DueDay)
+	}
+	s.NextDueDate = firstDue
+}
+
+// AdvanceDueDate() moves the schedule on to the due date after the current one
+func (s *GroupContributionSchedule) AdvanceDueDate() {
+	s.NextDueDate = calculateRecurrence(s.NextDueDate, database.RecurrenceIntervalEnum(s.RecurrenceInterval), 1, s.DueDay)
+}
+
+// FastForward() skips every due date before until without creating dues. It is used when a
+// paused schedule is resumed so members do not owe for the time it was paused
+func (s *GroupContributionSchedule) FastForward(until time.Time) {
+	until = until.Truncate(24 * time.Hour)
+	for s.NextDueDate.Before(until) {
+		s.AdvanceDueDate()
+	}
+}
+
+// calculateDaysOverdue() sets how many days the member's oldest pending due is overdue at asOf
+func (a *GroupMemberArrears) calculateDaysOverdue(asOf time.Time) {
+	a.DaysOverdue = 0
+	if a.OldestDueDate == nil {
+		return
+	}
+	if days := int(asOf.Truncate(24*time.Hour).Sub(a.OldestDueDate.Truncate(24*time.Hour)).Hours() / 24); days > 0 {
+		a.DaysOverdue = days
+	}
+}
+
+// CreateNewGroupContributionSchedule() schedules dues for a group goal. Only admins and moderators
+// of the goal's group can schedule dues, for anyone else ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) CreateNewGroupContributionSchedule(userID int64, schedule *GroupContributionSchedule) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateNewGroupContributionSchedule(ctx, database.CreateNewGroupContributionScheduleParams{
+		GoalID:             schedule.GoalID,
+		CreatorUserID:      sql.NullInt64{Int64: userID, Valid: true},
+		AmountPerMember:    schedule.AmountPerMember.String(),
+		RecurrenceInterval: database.RecurrenceIntervalEnum(schedule.RecurrenceInterval),
+		DueDay:             schedule.DueDay,
+		NextDueDate:        schedule.NextDueDate,
+		Description:        sql.NullString{String: schedule.Description, Valid: schedule.Description != ""},
+		Column8:            GroupPermissionRoles(GroupPermissionManageContributions),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_group_contribution_schedule_goal"`:
+			return ErrDuplicateGroupContributionSchedule
+		default:
+			return err
+		}
+	}
+	schedule.ID = row.ID
+	schedule.GroupID = row.GroupID
+	schedule.CreatorUserID = userID
+	schedule.IsActive = true
+	schedule.CreatedAt = row.CreatedAt
+	schedule.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+// UpdateGroupContributionSchedule() saves changes to a contribution schedule. Only admins and
+// moderators of the group can change schedules, for anyone else ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) UpdateGroupContributionSchedule(userID int64, schedule *GroupContributionSchedule) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	updatedAt, err := m.DB.UpdateGroupContributionSchedule(ctx, database.UpdateGroupContributionScheduleParams{
+		AmountPerMember: schedule.AmountPerMember.String(),
+		IsActive:        schedule.IsActive,
+		NextDueDate:     schedule.NextDueDate,
+		Description:     sql.NullString{String: schedule.Description, Valid: schedule.Description != ""},
+		ID:              schedule.ID,
+		UserID:          sql.NullInt64{Int64: userID, Valid: true},
+		Column7:         GroupPermissionRoles(GroupPermissionManageContributions),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	schedule.UpdatedAt = updatedAt
+	return nil
+}
+
+// DeleteGroupContributionSchedule() deletes a contribution schedule together with its dues. Only admins
+// and moderators of the group can delete schedules, for anyone else ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) DeleteGroupContributionSchedule(userID, scheduleID int64) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	_, err := m.DB.DeleteGroupContributionSchedule(ctx, database.DeleteGroupContributionScheduleParams{
+		ID:      scheduleID,
+		UserID:  sql.NullInt64{Int64: userID, Valid: true},
+		Column3: GroupPermissionRoles(GroupPermissionManageContributions),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGroupContributionScheduleByID() returns a contribution schedule
+func (m FinancialGroupManagerModel) GetGroupContributionScheduleByID(scheduleID int64) (*GroupContributionSchedule, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.GetGroupContributionScheduleByID(ctx, scheduleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return populateGroupContributionSchedule(row), nil
+}
+
+// GetGroupContributionSchedulesByGroupID() returns the contribution schedules of a group
+func (m FinancialGroupManagerModel) GetGroupContributionSchedulesByGroupID(groupID int64) ([]*GroupContributionSchedule, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupContributionSchedulesByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	schedules := []*GroupContributionSchedule{}
+	for _, row := range rows {
+		schedules = append(schedules, populateGroupContributionSchedule(database.GetGroupContributionScheduleByIDRow(row)))
+	}
+	return schedules, nil
+}
+
+// GetGroupContributionSchedulesDue() returns the active schedules of ongoing goals that are due on or before asOf
+func (m FinancialGroupManagerModel) GetGroupContributionSchedulesDue(asOf time.Time) ([]*GroupContributionSchedule, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupContributionSchedulesDue(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+	schedules := []*GroupContributionSchedule{}
+	for _, row := range rows {
+		schedules = append(schedules, populateGroupContributionSchedule(database.GetGroupContributionScheduleByIDRow(row)))
+	}
+	return schedules, nil
+}
+
+// UpdateGroupContributionScheduleNextDueDate() saves the next due date of a schedule
+func (m FinancialGroupManagerModel) UpdateGroupContributionScheduleNextDueDate(schedule *GroupContributionSchedule) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	return m.DB.UpdateGroupContributionScheduleNextDueDate(ctx, database.UpdateGroupContributionScheduleNextDueDateParams{
+		ID:          schedule.ID,
+		NextDueDate: schedule.NextDueDate,
+	})
+}
+
+// CreateGroupContributionDues() gives every accepted member of the schedule's group a pending due for
+// dueDate and returns the members that got one. Members that already have the due are skipped
+func (m FinancialGroupManagerModel) CreateGroupContributionDues(scheduleID int64, dueDate time.Time) ([]int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	return m.DB.CreateGroupContributionDues(ctx, database.CreateGroupContributionDuesParams{
+		ID:      scheduleID,
+		DueDate: dueDate,
+	})
+}
+
+// GetGroupContributionDuesToRemind() returns the dues overdue at asOf whose member has not been
+// reminded about them for GroupDueReminderInterval
+func (m FinancialGroupManagerModel) GetGroupContributionDuesToRemind(asOf time.Time) ([]*GroupContributionDue, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupContributionDuesToRemind(ctx, database.GetGroupContributionDuesToRemindParams{
+		DueDate:    asOf,
+		RemindedAt: sql.NullTime{Time: asOf.Add(-GroupDueReminderInterval), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	dues := []*GroupContributionDue{}
+	for _, row := range rows {
+		dues = append(dues, &GroupContributionDue{
+			ID:        row.ID,
+			MemberID:  row.MemberID,
+			Amount:    decimal.RequireFromString(row.Amount),
+			DueDate:   row.DueDate,
+			GoalName:  row.GoalName,
+			GroupName: row.GroupName,
+		})
+	}
+	return dues, nil
+}
+
+// MarkGroupContributionDuesReminded() records that the members of the dues were just reminded
+func (m FinancialGroupManagerModel) MarkGroupContributionDuesReminded(dueIDs []int64) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	return m.DB.MarkGroupContributionDuesReminded(ctx, dueIDs)
+}
+
+// GetGroupContributionArrearsByGroupID() returns what each member of a group has paid and still owes
+// of the dues that fell due by asOf, members owing the most first
+func (m FinancialGroupManagerModel) GetGroupContributionArrearsByGroupID(groupID int64, asOf time.Time) ([]*GroupMemberArrears, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupContributionArrearsByGroupID(ctx, database.GetGroupContributionArrearsByGroupIDParams{
+		GroupID: sql.NullInt64{Int64: groupID, Valid: true},
+		DueDate: asOf,
+	})
+	if err != nil {
+		return nil, err
+	}
+	arrears := []*GroupMemberArrears{}
+	for _, row := range rows {
+		memberArrears := &GroupMemberArrears{
+			MemberID:    row.MemberID.Int64,
+			PendingDues: row.PendingDues,
+			AmountOwed:  decimal.RequireFromString(row.AmountOwed),
+			PaidDues:    row.PaidDues,
+			AmountPaid:  decimal.RequireFromString(row.AmountPaid),
+		}
+		// the oldest due date is only meaningful when something is still owed
+		if row.PendingDues > 0 {
+			oldestDueDate := row.OldestDueDate
+			memberArrears.OldestDueDate = &oldestDueDate
+		}
+		memberArrears.calculateDaysOverdue(asOf)
+		arrears = append(arrears, memberArrears)
+	}
+	return arrears, nil
+}
+
+// populateGroupContributionSchedule() maps a schedule row to a GroupContributionSchedule. The rows of
+// the other schedule queries have the same columns and are converted to this row type
+func populateGroupContributionSchedule(row database.GetGroupContributionScheduleByIDRow) *GroupContributionSchedule {
+	return &GroupContributionSchedule{
+		ID:                 row.ID,
+		GroupID:            row.GroupID,
+		GoalID:             row.GoalID,
+		GoalName:           row.GoalName,
+		CreatorUserID:      row.CreatorUserID.Int64,
+		AmountPerMember:    decimal.RequireFromString(row.AmountPerMember),
+		RecurrenceInterval: string(row.RecurrenceInterval),
+		DueDay:             row.DueDay,
+		NextDueDate:        row.NextDueDate,
+		IsActive:           row.IsActive,
+		Description:        row.Description.String,
+		CreatedAt:          row.CreatedAt,
+		UpdatedAt:          row.UpdatedAt,
+	}
+}
@@ -0,0 +1,135 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+func groupDueDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestGroupContributionSchedule_InitializeSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		interval    string
+		dueDay      int32
+		startDate   time.Time
+		wantDueDate time.Time
+		wantDueDay  int32
+	}{
+		{"weekly starts on the start date", "weekly", 0, groupDueDate(2024, 3, 13), groupDueDate(2024, 3, 13), 0},
+		{"monthly without a due day", "monthly", 0, groupDueDate(2024, 3, 13), groupDueDate(2024, 3, 13), 13},
+		{"due day later in the month", "monthly", 25, groupDueDate(2024, 3, 13), groupDueDate(2024, 3, 25), 25},
+		{"due day already passed", "monthly", 5, groupDueDate(2024, 3, 13), groupDueDate(2024, 4, 5), 5},
+		{"due day clamped to a short month", "monthly", 31, groupDueDate(2024, 4, 2), groupDueDate(2024, 4, 30), 31},
+		{"yearly", "yearly", 1, groupDueDate(2024, 3, 13), groupDueDate(2024, 4, 1), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &GroupContributionSchedule{RecurrenceInterval: tt.interval, DueDay: tt.dueDay}
+			schedule.InitializeSchedule(tt.startDate)
+			if !schedule.NextDueDate.Equal(tt.wantDueDate) {
+				t.Errorf("next due date = %v, want %v", schedule.NextDueDate, tt.wantDueDate)
+			}
+			if schedule.DueDay != tt.wantDueDay {
+				t.Errorf("due day = %d, want %d", schedule.DueDay, tt.wantDueDay)
+			}
+		})
+	}
+}
+
+func TestGroupContributionSchedule_AdvanceDueDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		interval    string
+		dueDay      int32
+		dueDate     time.Time
+		wantDueDate time.Time
+	}{
+		{"weekly", "weekly", 0, groupDueDate(2024, 3, 13), groupDueDate(2024, 3, 20)},
+		{"monthly", "monthly", 15, groupDueDate(2024, 3, 15), groupDueDate(2024, 4, 15)},
+		{"monthly back on the 31st after a short month", "monthly", 31, groupDueDate(2024, 4, 30), groupDueDate(2024, 5, 31)},
+		{"yearly", "yearly", 29, groupDueDate(2024, 2, 29), groupDueDate(2025, 2, 28)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &GroupContributionSchedule{RecurrenceInterval: tt.interval, DueDay: tt.dueDay, NextDueDate: tt.dueDate}
+			schedule.AdvanceDueDate()
+			if !schedule.NextDueDate.Equal(tt.wantDueDate) {
+				t.Errorf("next due date = %v, want %v", schedule.NextDueDate, tt.wantDueDate)
+			}
+		})
+	}
+}
+
+func TestGroupContributionSchedule_FastForward(t *testing.T) {
+	schedule := &GroupContributionSchedule{RecurrenceInterval: "monthly", DueDay: 10, NextDueDate: groupDueDate(2024, 1, 10)}
+	schedule.FastForward(groupDueDate(2024, 5, 10))
+	if want := groupDueDate(2024, 5, 10); !schedule.NextDueDate.Equal(want) {
+		t.Errorf("next due date = %v, want %v", schedule.NextDueDate, want)
+	}
+	schedule.FastForward(groupDueDate(2024, 5, 11))
+	if want := groupDueDate(2024, 6, 10); !schedule.NextDueDate.Equal(want) {
+		t.Errorf("next due date = %v, want %v", schedule.NextDueDate, want)
+	}
+}
+
+func TestGroupMemberArrears_CalculateDaysOverdue(t *testing.T) {
+	oldest := groupDueDate(2024, 3, 1)
+	tests := []struct {
+		name          string
+		oldestDueDate *time.Time
+		asOf          time.Time
+		want          int
+	}{
+		{"nothing owed", nil, groupDueDate(2024, 3, 20), 0},
+		{"due today", &oldest, groupDueDate(2024, 3, 1), 0},
+		{"overdue", &oldest, groupDueDate(2024, 3, 20).Add(15 * time.Hour), 19},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arrears := &GroupMemberArrears{OldestDueDate: tt.oldestDueDate}
+			arrears.calculateDaysOverdue(tt.asOf)
+			if arrears.DaysOverdue != tt.want {
+				t.Errorf("days overdue = %d, want %d", arrears.DaysOverdue, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateGroupContributionSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		goalID   int64
+		amount   int64
+		interval string
+		dueDay   int32
+		wantKey  string
+	}{
+		{"monthly on a due day", 1, 50, "monthly", 5, ""},
+		{"monthly on the start date's day", 1, 50, "monthly", 0, ""},
+		{"weekly", 1, 50, "weekly", 0, ""},
+		{"missing goal", 0, 50, "monthly", 5, "goal_id"},
+		{"zero amount", 1, 0, "monthly", 5, "amount_per_member"},
+		{"daily dues", 1, 50, "daily", 5, "recurrence_interval"},
+		{"due day out of range", 1, 50, "monthly", 32, "due_day"},
+		{"weekly with a due day", 1, 50, "weekly", 5, "due_day"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &GroupContributionSchedule{GoalID: tt.goalID, AmountPerMember: decimal.NewFromInt(tt.amount), RecurrenceInterval: tt.interval, DueDay: tt.dueDay}
+			v := validator.New()
+			ValidateGroupContributionSchedule(v, schedule)
+			if tt.wantKey == "" && !v.Valid() {
+				t.Errorf("ValidateGroupContributionSchedule() errors = %v, want none", v.Errors)
+			}
+			if _, ok := v.Errors[tt.wantKey]; tt.wantKey != "" && !ok {
+				t.Errorf("ValidateGroupContributionSchedule() errors = %v, want an error for %s", v.Errors, tt.wantKey)
+			}
+		})
+	}
+}
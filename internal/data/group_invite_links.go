@@ -0,0 +1,217 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+const (
+	DefaultGroupInviteLinkTTL = 7 * 24 * time.Hour
+	MaxGroupInviteLinkTTL     = 30 * 24 * time.Hour
+)
+
+// The states an invite link can be in, worked out from its expiry, revocation and uses
+const (
+	GroupInviteLinkStatusActive  = "active"
+	GroupInviteLinkStatusExpired = "expired"
+	GroupInviteLinkStatusRevoked = "revoked"
+	GroupInviteLinkStatusUsedUp  = "used_up"
+)
+
+var (
+	ErrGroupInviteLinkInvalid = errors.New("the invite link is revoked, expired or used up")
+)
+
+// GroupInviteLink is a shareable link that lets anyone holding it join a group. Token is only
+// set when the link is created, afterwards only its hash is known
+type GroupInviteLink struct {
+	ID            int64      `json:"id"`
+	GroupID       int64      `json:"group_id"`
+	CreatorUserID int64      `json:"creator_user_id"`
+	Token         string     `json:"token,omitempty"`
+	Role          string     `json:"role"`
+	MaxUses       int32      `json:"max_uses"`
+	UseCount      int32      `json:"use_count"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// ValidateGroupInviteLink() validates a new invite link. Links can make members or moderators,
+// never admins, and must expire within MaxGroupInviteLinkTTL
+func ValidateGroupInviteLink(v *validator.Validator, link *GroupInviteLink, now time.Time) {
+	ValidateURLID(v, link.GroupID, "group_id")
+	v.Check(validator.PermittedValue(link.Role, string(GroupRoleMember), string(GroupRoleModerator)),
+		"role", "must be either member or moderator")
+	v.Check(link.MaxUses > 0, "max_uses", "must be greater than 0")
+	v.Check(link.MaxUses < 100, "max_uses", "must be less than 100")
+	v.Check(link.ExpiresAt.After(now), "expires_at", "must be in the future")
+	v.Check(!link.ExpiresAt.After(now.Add(MaxGroupInviteLinkTTL)), "expires_at", "must be within 30 days")
+}
+
+// StatusAt() works out whether the link can still be used at now
+func (l *GroupInviteLink) StatusAt(now time.Time) string {
+	switch {
+	case l.RevokedAt != nil:
+		return GroupInviteLinkStatusRevoked
+	case !l.ExpiresAt.After(now):
+		return GroupInviteLinkStatusExpired
+	case l.UseCount >= l.MaxUses:
+		return GroupInviteLinkStatusUsedUp
+	default:
+		return GroupInviteLinkStatusActive
+	}
+}
+
+// CreateNewGroupInviteLink() makes a new invite link with a fresh token. Only group admins can
+// make links, for anyone else ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) CreateNewGroupInviteLink(userID int64, link *GroupInviteLink) error {
+	token, err := generateToken(userID, time.Until(link.ExpiresAt), ScopeGroupInvite)
+	if err != nil {
+		return err
+	}
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateNewGroupInviteLink(ctx, database.CreateNewGroupInviteLinkParams{
+		GroupID:       link.GroupID,
+		CreatorUserID: sql.NullInt64{Int64: userID, Valid: true},
+		TokenHash:     token.Hash,
+		Role:          database.MembershipRole(link.Role),
+		MaxUses:       link.MaxUses,
+		ExpiresAt:     link.ExpiresAt,
+		Column7:       GroupPermissionRoles(GroupPermissionManageInviteLinks),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	link.ID = row.ID
+	link.CreatorUserID = userID
+	link.Token = token.Plaintext
+	link.CreatedAt = row.CreatedAt
+	link.UpdatedAt = row.UpdatedAt
+	link.Status = link.StatusAt(time.Now())
+	return nil
+}
+
+// GetGroupInviteLinksByGroupID() returns all the invite links of a group, newest first
+func (m FinancialGroupManagerModel) GetGroupInviteLinksByGroupID(groupID int64) ([]*GroupInviteLink, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupInviteLinksByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	links := []*GroupInviteLink{}
+	for _, row := range rows {
+		links = append(links, populateGroupInviteLink(database.GetGroupInviteLinkByTokenHashRow(row)))
+	}
+	return links, nil
+}
+
+// GetGroupInviteLinkByToken() returns the invite link a plaintext token belongs to
+func (m FinancialGroupManagerModel) GetGroupInviteLinkByToken(tokenPlaintext string) (*GroupInviteLink, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	tokenHash := sha256.Sum256([]byte(tokenPlaintext))
+	link, err := m.DB.GetGroupInviteLinkByTokenHash(ctx, tokenHash[:])
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return populateGroupInviteLink(link), nil
+}
+
+// RevokeGroupInviteLink() stops an invite link from being used. Only group admins can revoke
+// links, for anyone else or for a link that is already revoked ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) RevokeGroupInviteLink(userID, linkID int64) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	_, err := m.DB.RevokeGroupInviteLink(ctx, database.RevokeGroupInviteLinkParams{
+		ID:      linkID,
+		UserID:  sql.NullInt64{Int64: userID, Valid: true},
+		Column3: GroupPermissionRoles(GroupPermissionManageInviteLinks),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateGroupMembershipFromInviteLink() adds a user to the link's group with the link's role and
+// uses up one of the link's uses. ErrGroupMembersMaxedOut is returned when the group is full,
+// ErrGroupInviteLinkInvalid when the link can no longer be used and ErrUserGroupMembershipExists
+// when the user is already in the group
+func (m FinancialGroupManagerModel) CreateGroupMembershipFromInviteLink(userID int64, link *GroupInviteLink) (int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	membershipID, err := m.DB.CreateGroupMembershipFromInviteLink(ctx, database.CreateGroupMembershipFromInviteLinkParams{
+		ID:     link.ID,
+		UserID: sql.NullInt64{Int64: userID, Valid: true},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// tell a full group apart from a link that can no longer be used
+			isMaxedOut, err := m.CheckIfGroupMembersAreMaxedOut(link.GroupID)
+			if err != nil {
+				return 0, err
+			}
+			if isMaxedOut {
+				return 0, ErrGroupMembersMaxedOut
+			}
+			return 0, ErrGroupInviteLinkInvalid
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_group_user_membership"`:
+			return 0, ErrUserGroupMembershipExists
+		default:
+			return 0, err
+		}
+	}
+	return membershipID, nil
+}
+
+// populateGroupInviteLink() maps a database invite link to a GroupInviteLink
+func populateGroupInviteLink(link database.GetGroupInviteLinkByTokenHashRow) *GroupInviteLink {
+	inviteLink := &GroupInviteLink{
+		ID:            link.ID,
+		GroupID:       link.GroupID,
+		CreatorUserID: link.CreatorUserID.Int64,
+		Role:          string(link.Role),
+		MaxUses:       link.MaxUses,
+		UseCount:      link.UseCount,
+		ExpiresAt:     link.ExpiresAt,
+		CreatedAt:     link.CreatedAt,
+		UpdatedAt:     link.UpdatedAt,
+	}
+	if link.RevokedAt.Valid {
+		inviteLink.RevokedAt = &link.RevokedAt.Time
+	}
+	inviteLink.Status = inviteLink.StatusAt(time.Now())
+	return inviteLink
+}
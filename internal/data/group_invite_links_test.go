@@ -0,0 +1,64 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+func TestGroupInviteLinkStatusAt(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	revokedAt := now.Add(-time.Hour)
+	tests := []struct {
+		name string
+		link *GroupInviteLink
+		want string
+	}{
+		{"active", &GroupInviteLink{MaxUses: 5, UseCount: 4, ExpiresAt: now.Add(time.Hour)}, GroupInviteLinkStatusActive},
+		{"used up", &GroupInviteLink{MaxUses: 5, UseCount: 5, ExpiresAt: now.Add(time.Hour)}, GroupInviteLinkStatusUsedUp},
+		{"expires now", &GroupInviteLink{MaxUses: 5, ExpiresAt: now}, GroupInviteLinkStatusExpired},
+		{"expired and used up", &GroupInviteLink{MaxUses: 1, UseCount: 1, ExpiresAt: now.Add(-time.Hour)}, GroupInviteLinkStatusExpired},
+		{"revoked", &GroupInviteLink{MaxUses: 5, ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}, GroupInviteLinkStatusRevoked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.StatusAt(now); got != tt.want {
+				t.Errorf("StatusAt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateGroupInviteLink(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	member, moderator := string(GroupRoleMember), string(GroupRoleModerator)
+	week := now.Add(DefaultGroupInviteLinkTTL)
+	tests := []struct {
+		name    string
+		link    *GroupInviteLink
+		wantKey string
+	}{
+		{"member link", &GroupInviteLink{GroupID: 1, Role: member, MaxUses: 10, ExpiresAt: week}, ""},
+		{"moderator link", &GroupInviteLink{GroupID: 1, Role: moderator, MaxUses: 10, ExpiresAt: week}, ""},
+		{"longest expiry", &GroupInviteLink{GroupID: 1, Role: member, MaxUses: 10, ExpiresAt: now.Add(MaxGroupInviteLinkTTL)}, ""},
+		{"admin link", &GroupInviteLink{GroupID: 1, Role: string(GroupRoleAdmin), MaxUses: 10, ExpiresAt: week}, "role"},
+		{"missing group", &GroupInviteLink{Role: member, MaxUses: 10, ExpiresAt: week}, "group_id"},
+		{"no uses", &GroupInviteLink{GroupID: 1, Role: member, ExpiresAt: week}, "max_uses"},
+		{"too many uses", &GroupInviteLink{GroupID: 1, Role: member, MaxUses: 100, ExpiresAt: week}, "max_uses"},
+		{"already expired", &GroupInviteLink{GroupID: 1, Role: member, MaxUses: 10, ExpiresAt: now.Add(-time.Minute)}, "expires_at"},
+		{"expiry too far out", &GroupInviteLink{GroupID: 1, Role: member, MaxUses: 10, ExpiresAt: now.Add(MaxGroupInviteLinkTTL + time.Hour)}, "expires_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateGroupInviteLink(v, tt.link, now)
+			if tt.wantKey == "" && !v.Valid() {
+				t.Errorf("ValidateGroupInviteLink() errors = %v, want none", v.Errors)
+			}
+			if _, ok := v.Errors[tt.wantKey]; tt.wantKey != "" && !ok {
+				t.Errorf("ValidateGroupInviteLink() errors = %v, want an error for %s", v.Errors, tt.wantKey)
+			}
+		})
+	}
+}
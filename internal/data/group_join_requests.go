@@ -0,0 +1,238 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+// The states a request to join a group goes through
+const (
+	GroupJoinRequestStatusPending  = string(database.GroupJoinRequestStatusEnumPending)
+	GroupJoinRequestStatusApproved = string(database.GroupJoinRequestStatusEnumApproved)
+	GroupJoinRequestStatusRejected = string(database.GroupJoinRequestStatusEnumRejected)
+)
+
+var (
+	ErrDuplicateGroupJoinRequest = errors.New("a request to join this group is already pending")
+)
+
+// GroupJoinRequest is a user asking to join a discoverable private group.
+// The group's admins and moderators approve or reject it
+type GroupJoinRequest struct {
+	ID             int64      `json:"id"`
+	GroupID        int64      `json:"group_id"`
+	UserID         int64      `json:"user_id"`
+	Message        string     `json:"message"`
+	Status         string     `json:"status"`
+	ReviewerUserID int64      `json:"reviewer_user_id,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// PendingGroupJoinRequest is a join request waiting on review, with who sent it
+type PendingGroupJoinRequest struct {
+	*GroupJoinRequest
+	FirstName        string `json:"first_name"`
+	LastName         string `json:"last_name"`
+	ProfileAvatarURL string `json:"profile_avatar_url"`
+}
+
+// ValidateGroupJoinRequest() validates a new request to join a group
+func ValidateGroupJoinRequest(v *validator.Validator, request *GroupJoinRequest) {
+	ValidateURLID(v, request.GroupID, "group_id")
+	v.Check(len(request.Message) <= 500, "message", "must not be more than 500 bytes long")
+}
+
+// ValidateGroupJoinRequestReview() validates the decision on a join request
+func ValidateGroupJoinRequestReview(v *validator.Validator, status string) {
+	v.Check(validator.PermittedValue(status, GroupJoinRequestStatusApproved, GroupJoinRequestStatusRejected),
+		"status", "must be either approved or rejected")
+}
+
+// AcceptsJoinRequests() reports whether users can ask to join the group. Public groups are joined
+// directly, private groups that are not discoverable can only be joined through an invitation
+// and archived groups cannot be joined at all
+func (g *Group) AcceptsJoinRequests() bool {
+	return g.IsPrivate && g.IsDiscoverable && !g.IsArchived()
+}
+
+// CreateNewGroupJoinRequest() saves a request to join a group. A user can only have one
+// pending request per group, a second one returns ErrDuplicateGroupJoinRequest
+func (m FinancialGroupManagerModel) CreateNewGroupJoinRequest(request *GroupJoinRequest) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateNewGroupJoinRequest(ctx, database.CreateNewGroupJoinRequestParams{
+		GroupID: request.GroupID,
+		UserID:  request.UserID,
+		Message: sql.NullString{String: request.Message, Valid: request.Message != ""},
+	})
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "idx_group_join_requests_pending"`:
+			return ErrDuplicateGroupJoinRequest
+		default:
+			return err
+		}
+	}
+	request.ID = row.ID
+	request.Status = string(row.Status)
+	request.CreatedAt = row.CreatedAt
+	request.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+// GetGroupJoinRequestByID() returns a join request
+func (m FinancialGroupManagerModel) GetGroupJoinRequestByID(requestID int64) (*GroupJoinRequest, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	request, err := m.DB.GetGroupJoinRequestByID(ctx, requestID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return populateGroupJoinRequest(request), nil
+}
+
+// GetPendingGroupJoinRequestsByGroupID() returns the join requests of a group that are waiting
+// on review, oldest first
+func (m FinancialGroupManagerModel) GetPendingGroupJoinRequestsByGroupID(groupID int64) ([]*PendingGroupJoinRequest, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetPendingGroupJoinRequestsByGroupID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	requests := []*PendingGroupJoinRequest{}
+	for _, row := range rows {
+		requests = append(requests, &PendingGroupJoinRequest{
+			GroupJoinRequest: populateGroupJoinRequest(database.GroupJoinRequest{
+				ID:             row.ID,
+				GroupID:        row.GroupID,
+				UserID:         row.UserID,
+				Message:        row.Message,
+				Status:         row.Status,
+				ReviewerUserID: row.ReviewerUserID,
+				ReviewedAt:     row.ReviewedAt,
+				CreatedAt:      row.CreatedAt,
+				UpdatedAt:      row.UpdatedAt,
+			}),
+			FirstName:        row.FirstName,
+			LastName:         row.LastName,
+			ProfileAvatarURL: row.ProfileAvatarUrl,
+		})
+	}
+	return requests, nil
+}
+
+// ReviewGroupJoinRequest() approves or rejects a pending join request. Only admins and moderators
+// of the group can review requests, for anyone else or for a request that was already reviewed
+// ErrGeneralRecordNotFound is returned
+func (m FinancialGroupManagerModel) ReviewGroupJoinRequest(reviewerID int64, request *GroupJoinRequest, status string) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.ReviewGroupJoinRequest(ctx, database.ReviewGroupJoinRequestParams{
+		Status:         database.GroupJoinRequestStatusEnum(status),
+		ReviewerUserID: sql.NullInt64{Int64: reviewerID, Valid: true},
+		ID:             request.ID,
+		Column4:        GroupPermissionRoles(GroupPermissionReviewJoinRequests),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	request.Status = status
+	request.ReviewerUserID = reviewerID
+	request.ReviewedAt = &row.ReviewedAt.Time
+	request.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+// ApproveGroupJoinRequest() approves a pending join request and makes the user an accepted member
+// in one transaction. The group is locked while its members are counted, so two approvals cannot
+// both take the last place in the group
+func (m FinancialGroupManagerModel) ApproveGroupJoinRequest(reviewerID int64, request *GroupJoinRequest) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	return withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		_, err := q.LockGroupByID(ctx, request.GroupID)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrGeneralRecordNotFound
+			default:
+				return err
+			}
+		}
+		txModel := FinancialGroupManagerModel{DB: q}
+		isMaxedOut, err := txModel.CheckIfGroupMembersAreMaxedOut(request.GroupID)
+		if err != nil {
+			return err
+		}
+		if isMaxedOut {
+			return ErrGroupMembersMaxedOut
+		}
+		err = txModel.ReviewGroupJoinRequest(reviewerID, request, GroupJoinRequestStatusApproved)
+		if err != nil {
+			return err
+		}
+		// an approved request becomes an accepted membership, same as joining a public group
+		_, err = txModel.CreateNewPublicMembership(request.UserID, request.GroupID)
+		return err
+	})
+}
+
+// GetGroupModeratorUserIDs() returns the user IDs of the admins and moderators of a group
+func (m FinancialGroupManagerModel) GetGroupModeratorUserIDs(groupID int64) ([]int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupModeratorUserIDs(ctx, database.GetGroupModeratorUserIDsParams{
+		GroupID: sql.NullInt64{Int64: groupID, Valid: true},
+		Column2: GroupPermissionRoles(GroupPermissionReviewJoinRequests),
+	})
+	if err != nil {
+		return nil, err
+	}
+	userIDs := []int64{}
+	for _, row := range rows {
+		userIDs = append(userIDs, row.Int64)
+	}
+	return userIDs, nil
+}
+
+// populateGroupJoinRequest() maps a database join request to a GroupJoinRequest
+func populateGroupJoinRequest(request database.GroupJoinRequest) *GroupJoinRequest {
+	joinRequest := &GroupJoinRequest{
+		ID:             request.ID,
+		GroupID:        request.GroupID,
+		UserID:         request.UserID,
+		Message:        request.Message.String,
+		Status:         string(request.Status),
+		ReviewerUserID: request.ReviewerUserID.Int64,
+		CreatedAt:      request.CreatedAt,
+		UpdatedAt:      request.UpdatedAt,
+	}
+	if request.ReviewedAt.Valid {
+		joinRequest.ReviewedAt = &request.ReviewedAt.Time
+	}
+	return joinRequest
+}
@@ -0,0 +1,100 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+func TestGroupAcceptsJoinRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		isPrivate      bool
+		isDiscoverable bool
+		archived       bool
+		want           bool
+	}{
+		{"public", false, false, false, false},
+		{"private", true, false, false, false},
+		{"discoverable private", true, true, false, true},
+		{"archived discoverable private", true, true, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &Group{IsPrivate: tt.isPrivate, IsDiscoverable: tt.isDiscoverable}
+			if tt.archived {
+				archivedAt := time.Now()
+				group.ArchivedAt = &archivedAt
+			}
+			if got := group.AcceptsJoinRequests(); got != tt.want {
+				t.Errorf("AcceptsJoinRequests() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateGroupDiscoverability(t *testing.T) {
+	tests := []struct {
+		name           string
+		isPrivate      bool
+		isDiscoverable bool
+		wantValid      bool
+	}{
+		{"public", false, false, true},
+		{"private", true, false, true},
+		{"discoverable private", true, true, true},
+		{"discoverable public", false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateGroupDiscoverability(v, tt.isPrivate, tt.isDiscoverable)
+			if v.Valid() != tt.wantValid {
+				t.Errorf("ValidateGroupDiscoverability() valid = %v, want %v (errors: %v)", v.Valid(), tt.wantValid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateGroupJoinRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		request   *GroupJoinRequest
+		wantValid bool
+	}{
+		{"valid", &GroupJoinRequest{GroupID: 1, Message: "I'd like to save with you"}, true},
+		{"no message", &GroupJoinRequest{GroupID: 1}, true},
+		{"missing group", &GroupJoinRequest{Message: "hi"}, false},
+		{"message too long", &GroupJoinRequest{GroupID: 1, Message: strings.Repeat("a", 501)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateGroupJoinRequest(v, tt.request)
+			if v.Valid() != tt.wantValid {
+				t.Errorf("ValidateGroupJoinRequest() valid = %v, want %v (errors: %v)", v.Valid(), tt.wantValid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateGroupJoinRequestReview(t *testing.T) {
+	tests := []struct {
+		status    string
+		wantValid bool
+	}{
+		{GroupJoinRequestStatusApproved, true},
+		{GroupJoinRequestStatusRejected, true},
+		{GroupJoinRequestStatusPending, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		v := validator.New()
+		ValidateGroupJoinRequestReview(v, tt.status)
+		if v.Valid() != tt.wantValid {
+			t.Errorf("ValidateGroupJoinRequestReview(%q) valid = %v, want %v", tt.status, v.Valid(), tt.wantValid)
+		}
+	}
+}
@@ -0,0 +1,473 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// GroupDeletionGracePeriod is how long a deleted group is kept archived, so members can
+	// export its data, before it is purged
+	GroupDeletionGracePeriod = 14 * 24 * time.Hour
+	GroupDeletionBatchLimit  = 50
+)
+
+var (
+	ErrGroupArchived   = errors.New("this group is archived")
+	ErrGroupLastMember = errors.New("the user is the last member of the group")
+)
+
+// GroupExportMember is a member of a group as it appears in the group's data export
+type GroupExportMember struct {
+	UserID    int64      `json:"user_id"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	Role      string     `json:"role"`
+	JoinedAt  *time.Time `json:"joined_at,omitempty"`
+}
+
+// GroupExportContributionDue is a member's due as it appears in the group's data export
+type GroupExportContributionDue struct {
+	ID         int64           `json:"id"`
+	ScheduleID int64           `json:"schedule_id"`
+	GoalID     int64           `json:"goal_id"`
+	MemberID   int64           `json:"member_id"`
+	Amount     decimal.Decimal `json:"amount"`
+	AmountPaid decimal.Decimal `json:"amount_paid"`
+	DueDate    time.Time       `json:"due_date"`
+	Status     string          `json:"status"`
+	PaidAt     *time.Time      `json:"paid_at,omitempty"`
+}
+
+// GroupExport is everything a group holds, offered to its members before the group is deleted.
+// It holds everything DeleteGroup() purges, expenses come with their splits so settlements can be
+// followed
+type GroupExport struct {
+	Group                 *Group                        `json:"group"`
+	Members               []*GroupExportMember          `json:"members"`
+	Goals                 []*GroupGoal                  `json:"goals"`
+	Transactions          []*GroupTransaction           `json:"transactions"`
+	Expenses              []*GroupExpense               `json:"expenses"`
+	Budgets               []*GroupBudgetSummary         `json:"budgets"`
+	ContributionSchedules []*GroupContributionSchedule  `json:"contribution_schedules"`
+	ContributionDues      []*GroupExportContributionDue `json:"contribution_dues"`
+	Comments              []*Comment                    `json:"comments"`
+	ExportedAt            time.Time                     `json:"exported_at"`
+}
+
+// IsArchived() reports whether the group is archived, archived groups are read-only
+func (g *Group) IsArchived() bool {
+	return g.ArchivedAt != nil
+}
+
+// withGroupLifecycle() sets when a group was archived and when it is due to be deleted
+func withGroupLifecycle(group *Group, archivedAt, deletionScheduledAt sql.NullTime) *Group {
+	if archivedAt.Valid {
+		group.ArchivedAt = &archivedAt.Time
+	}
+	if deletionScheduledAt.Valid {
+		group.DeletionScheduledAt = &deletionScheduledAt.Time
+	}
+	return group
+}
+
+// GetGroupMemberAccess() returns the user's role in a group and whether the group is archived.
+// ErrGeneralRecordNotFound is returned when the user is not a member of the group
+func (m FinancialGroupManagerModel) GetGroupMemberAccess(userID, groupID int64) (database.MembershipRole, bool, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	access, err := m.DB.GetGroupMemberAccess(ctx, database.GetGroupMemberAccessParams{
+		GroupID: sql.NullInt64{Int64: groupID, Valid: true},
+		UserID:  sql.NullInt64{Int64: userID, Valid: true},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", false, ErrGeneralRecordNotFound
+		default:
+			return "", false, err
+		}
+	}
+	// members without a role are plain members
+	role := GroupRoleMember
+	if access.Role.Valid {
+		role = access.Role.MembershipRole
+	}
+	return role, access.ArchivedAt.Valid, nil
+}
+
+// TransferGroupOwnership() hands the group from its owner to another admin of the group.
+// ErrGeneralRecordNotFound is returned when the user does not own the group or the new owner
+// is not one of its admins
+func (m FinancialGroupManagerModel) TransferGroupOwnership(ownerID, newOwnerID int64, group *Group) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	transfer, err := m.DB.TransferGroupOwnership(ctx, database.TransferGroupOwnershipParams{
+		ID:              group.ID,
+		CreatorUserID:   sql.NullInt64{Int64: newOwnerID, Valid: true},
+		CreatorUserID_2: sql.NullInt64{Int64: ownerID, Valid: true},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		case err.Error() == `pq: duplicate key value violates unique constraint "groups_name_creator_user_id_key"`:
+			return ErrGroupNameExists
+		default:
+			return err
+		}
+	}
+	group.CreatorUserID = newOwnerID
+	group.UpdatedAt = transfer.UpdatedAt.Time
+	group.Version = int(transfer.Version.Int32)
+	return nil
+}
+
+// ArchiveGroup() makes a group read-only and takes it off the public listing. Only admins of the
+// group can archive it
+func (m FinancialGroupManagerModel) ArchiveGroup(userID int64, group *Group) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	archive, err := m.DB.ArchiveGroup(ctx, database.ArchiveGroupParams{
+		ID:      group.ID,
+		UserID:  sql.NullInt64{Int64: userID, Valid: true},
+		Column3: GroupPermissionRoles(GroupPermissionArchiveGroup),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	group.ArchivedAt = &archive.ArchivedAt.Time
+	group.UpdatedAt = archive.UpdatedAt.Time
+	group.Version = int(archive.Version.Int32)
+	return nil
+}
+
+// UnarchiveGroup() restores an archived group, cancelling its deletion if one was scheduled.
+// Only admins of the group can restore it
+func (m FinancialGroupManagerModel) UnarchiveGroup(userID int64, group *Group) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	unarchive, err := m.DB.UnarchiveGroup(ctx, database.UnarchiveGroupParams{
+		ID:      group.ID,
+		UserID:  sql.NullInt64{Int64: userID, Valid: true},
+		Column3: GroupPermissionRoles(GroupPermissionArchiveGroup),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	group.ArchivedAt = nil
+	group.DeletionScheduledAt = nil
+	group.UpdatedAt = unarchive.UpdatedAt.Time
+	group.Version = int(unarchive.Version.Int32)
+	return nil
+}
+
+// ScheduleGroupDeletion() archives a group and schedules it to be purged at deleteAt. Only the
+// owner of the group can delete it, and a group can only be scheduled for deletion once
+func (m FinancialGroupManagerModel) ScheduleGroupDeletion(ownerID int64, group *Group, deleteAt time.Time) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	deletion, err := m.DB.ScheduleGroupDeletion(ctx, database.ScheduleGroupDeletionParams{
+		ID:                  group.ID,
+		DeletionScheduledAt: sql.NullTime{Time: deleteAt, Valid: true},
+		CreatorUserID:       sql.NullInt64{Int64: ownerID, Valid: true},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	withGroupLifecycle(group, deletion.ArchivedAt, deletion.DeletionScheduledAt)
+	group.UpdatedAt = deletion.UpdatedAt.Time
+	group.Version = int(deletion.Version.Int32)
+	return nil
+}
+
+// GetGroupsDueForDeletion() returns up to limit groups whose deletion date has passed. Only the
+// ID and name of the groups are set
+func (m FinancialGroupManagerModel) GetGroupsDueForDeletion(now time.Time, limit int32) ([]*Group, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupsDueForDeletion(ctx, database.GetGroupsDueForDeletionParams{
+		DeletionScheduledAt: sql.NullTime{Time: now, Valid: true},
+		Limit:               limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	groups := []*Group{}
+	for _, row := range rows {
+		groups = append(groups, &Group{ID: row.ID, Name: row.Name})
+	}
+	return groups, nil
+}
+
+// DeleteGroup() permanently deletes a group that was scheduled for deletion along with
+// everything in it
+func (m FinancialGroupManagerModel) DeleteGroup(groupID int64) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	_, err := m.DB.DeleteGroup(ctx, groupID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// HandOverGroup() makes sure a group is left with an admin and an owner when an admin or the
+// owner leaves it. The user's successor is made an admin when no other admin is left, and
+// becomes the owner when the user owned the group. Both make it onto the group's timeline as done
+// by the leaving user. It returns the successor's ID when anything was handed over and 0
+// otherwise, or ErrGroupLastMember when nobody else is in the group
+func (m FinancialGroupManagerModel) HandOverGroup(userID int64, group *Group) (int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	successor, err := m.DB.GetGroupSuccessor(ctx, database.GetGroupSuccessorParams{
+		GroupID: sql.NullInt64{Int64: group.ID, Valid: true},
+		UserID:  sql.NullInt64{Int64: userID, Valid: true},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrGroupLastMember
+		default:
+			return 0, err
+		}
+	}
+	handedOver := false
+	// other admins come first, so a successor who is not an admin means none are left
+	if successor.Role.MembershipRole != GroupRoleAdmin {
+		err = m.DB.PromoteGroupMemberToAdmin(ctx, database.PromoteGroupMemberToAdminParams{
+			GroupID: sql.NullInt64{Int64: group.ID, Valid: true},
+			UserID:  successor.UserID,
+		})
+		if err != nil {
+			return 0, err
+		}
+		err = m.CreateGroupActivity(&GroupActivity{
+			GroupID:      group.ID,
+			UserID:       userID,
+			ActivityType: GroupActivityRoleChanged,
+			SubjectID:    successor.UserID.Int64,
+			Description:  "made a member an admin on leaving the group",
+		})
+		if err != nil {
+			return 0, err
+		}
+		handedOver = true
+	}
+	if group.CreatorUserID == userID {
+		err = m.DB.HandOverGroupOwnership(ctx, database.HandOverGroupOwnershipParams{
+			ID:            group.ID,
+			CreatorUserID: successor.UserID,
+		})
+		if err != nil {
+			switch {
+			case err.Error() == `pq: duplicate key value violates unique constraint "groups_name_creator_user_id_key"`:
+				return 0, ErrGroupNameExists
+			default:
+				return 0, err
+			}
+		}
+		err = m.CreateGroupActivity(&GroupActivity{
+			GroupID:      group.ID,
+			UserID:       userID,
+			ActivityType: GroupActivityRoleChanged,
+			SubjectID:    successor.UserID.Int64,
+			Description:  "made a member the owner of the group on leaving it",
+		})
+		if err != nil {
+			return 0, err
+		}
+		group.CreatorUserID = successor.UserID.Int64
+		handedOver = true
+	}
+	if !handedOver {
+		return 0, nil
+	}
+	return successor.UserID.Int64, nil
+}
+
+// LeaveGroup() takes a user out of a group. When handOver is set the group is first handed over
+// with HandOverGroup(), in the same transaction and with the group locked, so two admins leaving
+// at once cannot each hand the group to the other. It returns the successor's ID when anything
+// was handed over and 0 otherwise
+func (m FinancialGroupManagerModel) LeaveGroup(userID int64, group *Group, handOver bool) (int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	var successorID int64
+	err := withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		_, err := q.LockGroupByID(ctx, group.ID)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrGeneralRecordNotFound
+			default:
+				return err
+			}
+		}
+		txModel := FinancialGroupManagerModel{DB: q}
+		if handOver {
+			successorID, err = txModel.HandOverGroup(userID, group)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = txModel.UserLeaveGroup(userID, group.ID)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return successorID, nil
+}
+
+// GetGroupExport() gathers everything in a group: its members, goals, transactions, expenses and
+// their splits, budgets, contribution schedules and dues and the comments left on it
+func (m FinancialGroupManagerModel) GetGroupExport(group *Group) (*GroupExport, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	groupID := sql.NullInt64{Int64: group.ID, Valid: true}
+	export := &GroupExport{
+		Group:        group,
+		Members:      []*GroupExportMember{},
+		Goals:        []*GroupGoal{},
+		Transactions: []*GroupTransaction{},
+		Expenses:     []*GroupExpense{},
+		ExportedAt:   time.Now().UTC(),
+	}
+	export.ContributionDues = []*GroupExportContributionDue{}
+	export.Comments = []*Comment{}
+	members, err := m.DB.GetGroupExportMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		exportMember := &GroupExportMember{
+			UserID:    member.UserID.Int64,
+			FirstName: member.FirstName,
+			LastName:  member.LastName,
+			Role:      string(GroupRoleMember),
+		}
+		if member.Role.Valid {
+			exportMember.Role = string(member.Role.MembershipRole)
+		}
+		if member.ApprovalTime.Valid {
+			exportMember.JoinedAt = &member.ApprovalTime.Time
+		}
+		export.Members = append(export.Members, exportMember)
+	}
+	goals, err := m.DB.GetGroupExportGoals(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, goal := range goals {
+		export.Goals = append(export.Goals, populateGroupGoal(goal))
+	}
+	transactions, err := m.DB.GetGroupExportTransactions(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		export.Transactions = append(export.Transactions, populateTransactions(transaction))
+	}
+	expenses, err := m.DB.GetGroupExportExpenses(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	splits, err := m.DB.GetGroupExportExpenseSplits(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	expenseSplits := map[int64][]*GroupExpenseSplit{}
+	for _, split := range splits {
+		expenseSplits[split.ExpenseID] = append(expenseSplits[split.ExpenseID], &GroupExpenseSplit{
+			MemberID: split.MemberID,
+			Share:    decimal.RequireFromString(split.Share),
+			Amount:   decimal.RequireFromString(split.Amount),
+		})
+	}
+	for _, expense := range expenses {
+		exportExpense := populateExpenses(expense)
+		exportExpense.Splits = expenseSplits[exportExpense.ID]
+		export.Expenses = append(export.Expenses, exportExpense)
+	}
+	export.Budgets, err = m.GetGroupBudgetSummariesByGroupID(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	export.ContributionSchedules, err = m.GetGroupContributionSchedulesByGroupID(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	dues, err := m.DB.GetGroupExportContributionDues(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, due := range dues {
+		exportDue := &GroupExportContributionDue{
+			ID:         due.ID,
+			ScheduleID: due.ScheduleID,
+			GoalID:     due.GoalID,
+			MemberID:   due.MemberID,
+			Amount:     decimal.RequireFromString(due.Amount),
+			AmountPaid: decimal.RequireFromString(due.AmountPaid),
+			DueDate:    due.DueDate,
+			Status:     string(due.Status),
+		}
+		if due.PaidAt.Valid {
+			exportDue.PaidAt = &due.PaidAt.Time
+		}
+		export.ContributionDues = append(export.ContributionDues, exportDue)
+	}
+	comments, err := m.DB.GetGroupExportComments(ctx, group.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		export.Comments = append(export.Comments, &Comment{
+			ID:             comment.ID,
+			Content:        comment.Content,
+			UserID:         comment.UserID,
+			ParentID:       comment.ParentID.Int64,
+			AssociatedType: comment.AssociatedType,
+			AssociatedID:   comment.AssociatedID,
+			CreatedAt:      comment.CreatedAt.Time,
+			UpdatedAt:      comment.UpdatedAt.Time,
+		})
+	}
+	return export, nil
+}
@@ -0,0 +1,33 @@
+package data
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestWithGroupLifecycle(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name                string
+		archivedAt          sql.NullTime
+		deletionScheduledAt sql.NullTime
+		wantArchived        bool
+		wantDeletion        bool
+	}{
+		{"active", sql.NullTime{}, sql.NullTime{}, false, false},
+		{"archived", sql.NullTime{Time: now, Valid: true}, sql.NullTime{}, true, false},
+		{"being deleted", sql.NullTime{Time: now, Valid: true}, sql.NullTime{Time: now.Add(GroupDeletionGracePeriod), Valid: true}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := withGroupLifecycle(&Group{}, tt.archivedAt, tt.deletionScheduledAt)
+			if got := group.IsArchived(); got != tt.wantArchived {
+				t.Errorf("IsArchived() = %v, want %v", got, tt.wantArchived)
+			}
+			if got := group.DeletionScheduledAt != nil; got != tt.wantDeletion {
+				t.Errorf("DeletionScheduledAt set = %v, want %v", got, tt.wantDeletion)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package data
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+)
+
+// GroupPermission is something a member can do in a group. Which members can do it depends
+// on their role in the group, see groupPermissionMatrix
+type GroupPermission string
+
+const (
+	GroupPermissionViewGroup            GroupPermission = "view_group"
+	GroupPermissionContribute           GroupPermission = "contribute"
+	GroupPermissionAddExpense           GroupPermission = "add_expense"
+	GroupPermissionCreateGoal           GroupPermission = "create_goal"
+	GroupPermissionEditGoal             GroupPermission = "edit_goal"
+	GroupPermissionDeleteOthersExpenses GroupPermission = "delete_others_expenses"
+	GroupPermissionInviteMembers        GroupPermission = "invite_members"
+	GroupPermissionReviewJoinRequests   GroupPermission = "review_join_requests"
+	GroupPermissionModerateComments     GroupPermission = "moderate_comments"
+	GroupPermissionManageContributions  GroupPermission = "manage_contributions"
+	GroupPermissionRemoveMembers        GroupPermission = "remove_members"
+	GroupPermissionChangeRoles          GroupPermission = "change_roles"
+	GroupPermissionEditSettings         GroupPermission = "edit_settings"
+	GroupPermissionManageBudgets        GroupPermission = "manage_budgets"
+	GroupPermissionManageInviteLinks    GroupPermission = "manage_invite_links"
+	GroupPermissionArchiveGroup         GroupPermission = "archive_group"
+	GroupPermissionManageChallenges     GroupPermission = "manage_challenges"
+)
+
+var (
+	ErrGroupPermissionDenied = errors.New("your role in this group does not allow this action")
+)
+
+// groupPermissionMatrix lists what each group role can do. Moderators look after the group's
+// goals, members and comments day to day, admins also run the group itself
+var groupPermissionMatrix = map[database.MembershipRole][]GroupPermission{
+	GroupRoleMember: {
+		GroupPermissionViewGroup,
+		GroupPermissionContribute,
+		GroupPermissionAddExpense,
+	},
+	GroupRoleModerator: {
+		GroupPermissionViewGroup,
+		GroupPermissionContribute,
+		GroupPermissionAddExpense,
+		GroupPermissionCreateGoal,
+		GroupPermissionEditGoal,
+		GroupPermissionDeleteOthersExpenses,
+		GroupPermissionInviteMembers,
+		GroupPermissionReviewJoinRequests,
+		GroupPermissionModerateComments,
+		GroupPermissionManageContributions,
+		GroupPermissionManageChallenges,
+	},
+	GroupRoleAdmin: {
+		GroupPermissionViewGroup,
+		GroupPermissionContribute,
+		GroupPermissionAddExpense,
+		GroupPermissionCreateGoal,
+		GroupPermissionEditGoal,
+		GroupPermissionDeleteOthersExpenses,
+		GroupPermissionInviteMembers,
+		GroupPermissionReviewJoinRequests,
+		GroupPermissionModerateComments,
+		GroupPermissionManageContributions,
+		GroupPermissionManageChallenges,
+		GroupPermissionRemoveMembers,
+		GroupPermissionChangeRoles,
+		GroupPermissionEditSettings,
+		GroupPermissionManageBudgets,
+		GroupPermissionManageInviteLinks,
+		GroupPermissionArchiveGroup,
+	},
+}
+
+// groupArchivedPermissions lists what can still be done in an archived group, which is read-only
+// apart from restoring it
+var groupArchivedPermissions = []GroupPermission{
+	GroupPermissionViewGroup,
+	GroupPermissionArchiveGroup,
+}
+
+// GroupRolePermissions() returns everything a role can do in a group
+func GroupRolePermissions(role database.MembershipRole) []GroupPermission {
+	return slices.Clone(groupPermissionMatrix[role])
+}
+
+// GroupRoleHasPermission() reports whether a role allows a permission. Unknown roles allow nothing
+func GroupRoleHasPermission(role database.MembershipRole, permission GroupPermission) bool {
+	return slices.Contains(groupPermissionMatrix[role], permission)
+}
+
+// GroupPermissionRoles() returns the roles that allow a permission. Queries that check the acting
+// member's role themselves take these, so the matrix stays the only place roles are decided
+func GroupPermissionRoles(permission GroupPermission) []string {
+	roles := []string{}
+	for role, permissions := range groupPermissionMatrix {
+		if slices.Contains(permissions, permission) {
+			roles = append(roles, string(role))
+		}
+	}
+	slices.Sort(roles)
+	return roles
+}
+
+// GroupAccessPermissions() returns what a role can do in a group, leaving out what cannot be done
+// while the group is archived
+func GroupAccessPermissions(role database.MembershipRole, archived bool) []GroupPermission {
+	permissions := GroupRolePermissions(role)
+	if archived {
+		permissions = slices.DeleteFunc(permissions, func(permission GroupPermission) bool {
+			return !slices.Contains(groupArchivedPermissions, permission)
+		})
+	}
+	return permissions
+}
+
+// AuthorizeGroupAction() checks that a user may do something in a group and returns their role.
+// ErrGeneralRecordNotFound is returned when the user is not a member of the group,
+// ErrGroupPermissionDenied when their role does not allow the action and ErrGroupArchived when
+// the group is archived and the action would change it
+func (m FinancialGroupManagerModel) AuthorizeGroupAction(userID, groupID int64, permission GroupPermission) (database.MembershipRole, error) {
+	role, archived, err := m.GetGroupMemberAccess(userID, groupID)
+	if err != nil {
+		return "", err
+	}
+	if !GroupRoleHasPermission(role, permission) {
+		return role, ErrGroupPermissionDenied
+	}
+	if archived && !slices.Contains(groupArchivedPermissions, permission) {
+		return role, ErrGroupArchived
+	}
+	return role, nil
+}
@@ -0,0 +1,109 @@
+package data
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+)
+
+func TestGroupRoleHasPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       database.MembershipRole
+		permission GroupPermission
+		want       bool
+	}{
+		{"member views group", GroupRoleMember, GroupPermissionViewGroup, true},
+		{"member contributes", GroupRoleMember, GroupPermissionContribute, true},
+		{"member adds expense", GroupRoleMember, GroupPermissionAddExpense, true},
+		{"member cannot create goal", GroupRoleMember, GroupPermissionCreateGoal, false},
+		{"member cannot invite", GroupRoleMember, GroupPermissionInviteMembers, false},
+		{"member cannot moderate comments", GroupRoleMember, GroupPermissionModerateComments, false},
+		{"moderator edits goal", GroupRoleModerator, GroupPermissionEditGoal, true},
+		{"moderator deletes others expenses", GroupRoleModerator, GroupPermissionDeleteOthersExpenses, true},
+		{"moderator reviews join requests", GroupRoleModerator, GroupPermissionReviewJoinRequests, true},
+		{"moderator moderates comments", GroupRoleModerator, GroupPermissionModerateComments, true},
+		{"moderator cannot remove members", GroupRoleModerator, GroupPermissionRemoveMembers, false},
+		{"moderator cannot change roles", GroupRoleModerator, GroupPermissionChangeRoles, false},
+		{"moderator cannot edit settings", GroupRoleModerator, GroupPermissionEditSettings, false},
+		{"moderator cannot manage invite links", GroupRoleModerator, GroupPermissionManageInviteLinks, false},
+		{"admin changes roles", GroupRoleAdmin, GroupPermissionChangeRoles, true},
+		{"admin edits settings", GroupRoleAdmin, GroupPermissionEditSettings, true},
+		{"admin manages budgets", GroupRoleAdmin, GroupPermissionManageBudgets, true},
+		{"unknown role", database.MembershipRole("owner"), GroupPermissionViewGroup, false},
+		{"unknown permission", GroupRoleAdmin, GroupPermission("launch_rockets"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GroupRoleHasPermission(tt.role, tt.permission); got != tt.want {
+				t.Errorf("GroupRoleHasPermission(%q, %q) = %v, want %v", tt.role, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupRolePermissionsAreNested(t *testing.T) {
+	// every role can do everything the role below it can
+	roles := []database.MembershipRole{GroupRoleMember, GroupRoleModerator, GroupRoleAdmin}
+	for i := 1; i < len(roles); i++ {
+		for _, permission := range GroupRolePermissions(roles[i-1]) {
+			if !GroupRoleHasPermission(roles[i], permission) {
+				t.Errorf("%q can %q but %q cannot", roles[i-1], permission, roles[i])
+			}
+		}
+	}
+}
+
+func TestGroupRolePermissionsReturnsCopy(t *testing.T) {
+	permissions := GroupRolePermissions(GroupRoleMember)
+	permissions[0] = GroupPermissionEditSettings
+	if GroupRoleHasPermission(GroupRoleMember, GroupPermissionEditSettings) {
+		t.Error("changing the returned permissions changed the member role")
+	}
+}
+
+func TestGroupAccessPermissions(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       database.MembershipRole
+		archived   bool
+		permission GroupPermission
+		want       bool
+	}{
+		{"admin edits settings", GroupRoleAdmin, false, GroupPermissionEditSettings, true},
+		{"admin cannot edit archived settings", GroupRoleAdmin, true, GroupPermissionEditSettings, false},
+		{"admin restores archived group", GroupRoleAdmin, true, GroupPermissionArchiveGroup, true},
+		{"member views archived group", GroupRoleMember, true, GroupPermissionViewGroup, true},
+		{"member cannot contribute to archived group", GroupRoleMember, true, GroupPermissionContribute, false},
+		{"moderator cannot restore archived group", GroupRoleModerator, true, GroupPermissionArchiveGroup, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Contains(GroupAccessPermissions(tt.role, tt.archived), tt.permission)
+			if got != tt.want {
+				t.Errorf("GroupAccessPermissions(%q, %v) has %q = %v, want %v", tt.role, tt.archived, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupPermissionRoles(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission GroupPermission
+		want       []string
+	}{
+		{"everyone contributes", GroupPermissionContribute, []string{"admin", "member", "moderator"}},
+		{"moderators and admins manage contributions", GroupPermissionManageContributions, []string{"admin", "moderator"}},
+		{"only admins manage budgets", GroupPermissionManageBudgets, []string{"admin"}},
+		{"unknown permission", GroupPermission("launch_rockets"), []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GroupPermissionRoles(tt.permission); !slices.Equal(got, tt.want) {
+				t.Errorf("GroupPermissionRoles(%q) = %v, want %v", tt.permission, got, tt.want)
+			}
+		})
+	}
+}
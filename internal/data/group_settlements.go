@@ -0,0 +1,398 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// The ways a group expense can be split between members
+const (
+	GroupSplitEqual      = string(database.GroupSplitTypeEnumEqual)
+	GroupSplitShares     = string(database.GroupSplitTypeEnumShares)
+	GroupSplitExact      = string(database.GroupSplitTypeEnumExact)
+	GroupSplitPercentage = string(database.GroupSplitTypeEnumPercentage)
+)
+
+// GroupExpenseSplit is one member's part of a split group expense. Share is what was entered for
+// the member, their number of shares, exact amount or percentage (1 for an equal split), and
+// Amount is the part of the expense the member owes
+type GroupExpenseSplit struct {
+	MemberID int64           `json:"member_id"`
+	Share    decimal.Decimal `json:"share"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
+// GroupSplitEntry is a member's part of a split group expense together with the member who paid it
+type GroupSplitEntry struct {
+	ExpenseID int64
+	PaidBy    int64
+	MemberID  int64
+	Amount    decimal.Decimal
+}
+
+// GroupSettlement is a payment from one member (MemberID) to another (RecipientMemberID) to settle
+// what they owe. Settlements are saved as group transactions outside of any goal
+type GroupSettlement struct {
+	ID                int64           `json:"id"`
+	GroupID           int64           `json:"group_id"`
+	MemberID          int64           `json:"member_id"`
+	RecipientMemberID int64           `json:"recipient_member_id"`
+	Amount            decimal.Decimal `json:"amount"`
+	Description       string          `json:"description"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+// GroupMemberBalance is where a member stands in the group. Paid is the total of the split expenses
+// the member paid and Share the member's own part of all split expenses. A positive balance is what
+// the member is owed, a negative balance what the member owes
+type GroupMemberBalance struct {
+	UserID              int64           `json:"user_id"`
+	Paid                decimal.Decimal `json:"paid"`
+	Share               decimal.Decimal `json:"share"`
+	SettlementsPaid     decimal.Decimal `json:"settlements_paid"`
+	SettlementsReceived decimal.Decimal `json:"settlements_received"`
+	Balance             decimal.Decimal `json:"balance"`
+}
+
+// GroupDebt is an amount one member owes another
+type GroupDebt struct {
+	FromUserID int64           `json:"from_user_id"`
+	ToUserID   int64           `json:"to_user_id"`
+	Amount     decimal.Decimal `json:"amount"`
+}
+
+// GroupBalances holds the balances of a group. Pairwise is what each member owes each other member
+// directly, SettleUp is the smallest set of transfers that settles every balance
+type GroupBalances struct {
+	Members  []*GroupMemberBalance `json:"members"`
+	Pairwise []*GroupDebt          `json:"pairwise"`
+	SettleUp []*GroupDebt          `json:"settle_up"`
+}
+
+// ValidateGroupExpenseSplit() validates how a group expense is split. Exact amounts have to add up to
+// the expense and percentages to 100
+func ValidateGroupExpenseSplit(v *validator.Validator, amount decimal.Decimal, splitType string, members []*GroupExpenseSplit) {
+	v.Check(validator.PermittedValue(splitType, GroupSplitEqual, GroupSplitShares, GroupSplitExact, GroupSplitPercentage),
+		"split.split_type", "must be one of equal, shares, exact or percentage")
+	v.Check(len(members) > 0, "split.members", "must contain at least one member")
+	seen := make(map[int64]bool)
+	total := decimal.Zero
+	for _, member := range members {
+		v.Check(member.MemberID > 0, "split.members", "must only contain valid member IDs")
+		v.Check(!seen[member.MemberID], "split.members", "must not contain the same member twice")
+		v.Check(!member.Share.IsNegative(), "split.members", "must not contain a negative share")
+		seen[member.MemberID] = true
+		total = total.Add(member.Share)
+	}
+	if !v.Valid() {
+		return
+	}
+	switch splitType {
+	case GroupSplitShares:
+		v.Check(total.IsPositive(), "split.members", "shares must add up to more than 0")
+	case GroupSplitExact:
+		v.Check(total.Equal(amount), "split.members", "exact amounts must add up to the expense amount of "+amount.StringFixed(2))
+	case GroupSplitPercentage:
+		v.Check(total.Equal(decimal.NewFromInt(100)), "split.members", "percentages must add up to 100")
+	}
+}
+
+// ValidateGroupSettlement() validates a settlement from a member to another member
+func ValidateGroupSettlement(v *validator.Validator, settlement *GroupSettlement) {
+	ValidateURLID(v, settlement.GroupID, "group_id")
+	ValidateURLID(v, settlement.RecipientMemberID, "recipient_member_id")
+	v.Check(settlement.RecipientMemberID != settlement.MemberID, "recipient_member_id", "must be another member")
+	ValidateAmount(v, settlement.Amount, "amount")
+	v.Check(len(settlement.Description) <= 500, "description", "must not be more than 500 bytes long")
+}
+
+// CalculateGroupExpenseSplit() works out what each member owes of an expense. Amounts are rounded
+// to cents and the cents left over go to the members with the largest remainders, so the amounts
+// always add up to the expense
+func CalculateGroupExpenseSplit(amount decimal.Decimal, splitType string, members []*GroupExpenseSplit) {
+	if splitType == GroupSplitExact {
+		for _, member := range members {
+			member.Amount = member.Share
+		}
+		return
+	}
+	if splitType == GroupSplitEqual {
+		for _, member := range members {
+			member.Share = decimal.NewFromInt(1)
+		}
+	}
+	total := decimal.Zero
+	for _, member := range members {
+		total = total.Add(member.Share)
+	}
+	if !total.IsPositive() {
+		return
+	}
+	remainders := make([]decimal.Decimal, len(members))
+	allocated := decimal.Zero
+	for i, member := range members {
+		exact := amount.Mul(member.Share).Div(total)
+		member.Amount = exact.RoundDown(2)
+		remainders[i] = exact.Sub(member.Amount)
+		allocated = allocated.Add(member.Amount)
+	}
+	order := make([]int, len(members))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]].GreaterThan(remainders[order[b]])
+	})
+	cent := decimal.New(1, -2)
+	for i := 0; amount.Sub(allocated).GreaterThanOrEqual(cent); i++ {
+		member := members[order[i%len(order)]]
+		member.Amount = member.Amount.Add(cent)
+		allocated = allocated.Add(cent)
+	}
+}
+
+// SetGroupMemberBalances() sets the balance of each member from the group's balances, a member
+// without split expenses or settlements has a balance of zero
+func SetGroupMemberBalances(members []*GroupMember, balances *GroupBalances) {
+	byUserID := make(map[int64]decimal.Decimal, len(balances.Members))
+	for _, balance := range balances.Members {
+		byUserID[balance.UserID] = balance.Balance
+	}
+	for _, member := range members {
+		balance := byUserID[member.UserID]
+		member.Balance = &balance
+	}
+}
+
+// BuildGroupBalances() works out the balances of a group from its split expenses and settlements.
+// Every member of the group is listed, as is anyone that still has split expenses or settlements
+// in the group after leaving it
+func BuildGroupBalances(memberIDs []int64, splits []*GroupSplitEntry, settlements []*GroupSettlement) *GroupBalances {
+	balances := make(map[int64]*GroupMemberBalance)
+	balanceOf := func(userID int64) *GroupMemberBalance {
+		if _, ok := balances[userID]; !ok {
+			balances[userID] = &GroupMemberBalance{UserID: userID}
+		}
+		return balances[userID]
+	}
+	for _, memberID := range memberIDs {
+		balanceOf(memberID)
+	}
+	// owed holds what the first member of each pair owes the second, the pair is kept in ID order
+	type pair struct{ from, to int64 }
+	owed := make(map[pair]decimal.Decimal)
+	addDebt := func(from, to int64, amount decimal.Decimal) {
+		if from > to {
+			from, to, amount = to, from, amount.Neg()
+		}
+		owed[pair{from, to}] = owed[pair{from, to}].Add(amount)
+	}
+	for _, split := range splits {
+		if split.PaidBy == 0 {
+			continue
+		}
+		payer := balanceOf(split.PaidBy)
+		payer.Paid = payer.Paid.Add(split.Amount)
+		member := balanceOf(split.MemberID)
+		member.Share = member.Share.Add(split.Amount)
+		if split.MemberID != split.PaidBy {
+			addDebt(split.MemberID, split.PaidBy, split.Amount)
+		}
+	}
+	for _, settlement := range settlements {
+		payer := balanceOf(settlement.MemberID)
+		payer.SettlementsPaid = payer.SettlementsPaid.Add(settlement.Amount)
+		recipient := balanceOf(settlement.RecipientMemberID)
+		recipient.SettlementsReceived = recipient.SettlementsReceived.Add(settlement.Amount)
+		addDebt(settlement.MemberID, settlement.RecipientMemberID, settlement.Amount.Neg())
+	}
+	groupBalances := &GroupBalances{
+		Members:  []*GroupMemberBalance{},
+		Pairwise: []*GroupDebt{},
+	}
+	for _, balance := range balances {
+		balance.Balance = balance.Paid.Sub(balance.Share).Add(balance.SettlementsPaid).Sub(balance.SettlementsReceived)
+		groupBalances.Members = append(groupBalances.Members, balance)
+	}
+	sort.Slice(groupBalances.Members, func(i, j int) bool {
+		return groupBalances.Members[i].UserID < groupBalances.Members[j].UserID
+	})
+	for members, amount := range owed {
+		switch {
+		case amount.IsPositive():
+			groupBalances.Pairwise = append(groupBalances.Pairwise, &GroupDebt{FromUserID: members.from, ToUserID: members.to, Amount: amount})
+		case amount.IsNegative():
+			groupBalances.Pairwise = append(groupBalances.Pairwise, &GroupDebt{FromUserID: members.to, ToUserID: members.from, Amount: amount.Neg()})
+		}
+	}
+	sort.Slice(groupBalances.Pairwise, func(i, j int) bool {
+		a, b := groupBalances.Pairwise[i], groupBalances.Pairwise[j]
+		if a.FromUserID != b.FromUserID {
+			return a.FromUserID < b.FromUserID
+		}
+		return a.ToUserID < b.ToUserID
+	})
+	groupBalances.SettleUp = SimplifyGroupDebts(groupBalances.Members)
+	return groupBalances
+}
+
+// SimplifyGroupDebts() returns the transfers that settle every balance. The member who owes the most
+// repeatedly pays the member who is owed the most, which settles the group in at most one transfer
+// less than the number of members with a balance
+func SimplifyGroupDebts(balances []*GroupMemberBalance) []*GroupDebt {
+	type position struct {
+		userID int64
+		amount decimal.Decimal
+	}
+	var debtors, creditors []*position
+	for _, balance := range balances {
+		switch {
+		case balance.Balance.IsNegative():
+			debtors = append(debtors, &position{balance.UserID, balance.Balance.Neg()})
+		case balance.Balance.IsPositive():
+			creditors = append(creditors, &position{balance.UserID, balance.Balance})
+		}
+	}
+	largestFirst := func(positions []*position) {
+		sort.SliceStable(positions, func(i, j int) bool {
+			if !positions[i].amount.Equal(positions[j].amount) {
+				return positions[i].amount.GreaterThan(positions[j].amount)
+			}
+			return positions[i].userID < positions[j].userID
+		})
+	}
+	transfers := []*GroupDebt{}
+	for len(debtors) > 0 && len(creditors) > 0 {
+		largestFirst(debtors)
+		largestFirst(creditors)
+		debtor, creditor := debtors[0], creditors[0]
+		amount := decimal.Min(debtor.amount, creditor.amount)
+		transfers = append(transfers, &GroupDebt{FromUserID: debtor.userID, ToUserID: creditor.userID, Amount: amount})
+		debtor.amount = debtor.amount.Sub(amount)
+		creditor.amount = creditor.amount.Sub(amount)
+		if debtor.amount.IsZero() {
+			debtors = debtors[1:]
+		}
+		if creditor.amount.IsZero() {
+			creditors = creditors[1:]
+		}
+	}
+	return transfers
+}
+
+// SaveGroupExpenseSplit() replaces the split of an existing group expense
+func (m FinancialGroupManagerModel) SaveGroupExpenseSplit(expense *GroupExpense) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	memberIDs, shares, amounts := groupExpenseSplitColumns(expense.Splits)
+	_, err := m.DB.SaveGroupExpenseSplit(ctx, database.SaveGroupExpenseSplitParams{
+		ExpenseID: expense.ID,
+		SplitType: database.NullGroupSplitTypeEnum{GroupSplitTypeEnum: database.GroupSplitTypeEnum(expense.SplitType), Valid: true},
+		Column3:   memberIDs,
+		Column4:   shares,
+		Column5:   amounts,
+	})
+	return err
+}
+
+// GetAcceptedGroupMemberIDs() returns the IDs of the accepted members of a group
+func (m FinancialGroupManagerModel) GetAcceptedGroupMemberIDs(groupID int64) ([]int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetAcceptedGroupMemberIDs(ctx, sql.NullInt64{Int64: groupID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	memberIDs := []int64{}
+	for _, row := range rows {
+		memberIDs = append(memberIDs, row.Int64)
+	}
+	return memberIDs, nil
+}
+
+// GetGroupExpenseSplitsByGroupID() returns every member's part of the split expenses of a group
+func (m FinancialGroupManagerModel) GetGroupExpenseSplitsByGroupID(groupID int64) ([]*GroupSplitEntry, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupExpenseSplitsByGroupID(ctx, sql.NullInt64{Int64: groupID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	splits := []*GroupSplitEntry{}
+	for _, row := range rows {
+		splits = append(splits, &GroupSplitEntry{
+			ExpenseID: row.ExpenseID,
+			PaidBy:    row.PaidBy.Int64,
+			MemberID:  row.MemberID,
+			Amount:    decimal.RequireFromString(row.Amount),
+		})
+	}
+	return splits, nil
+}
+
+// CreateNewGroupSettlement() records a settlement from the user to another member of the group
+func (m FinancialGroupManagerModel) CreateNewGroupSettlement(userID int64, settlement *GroupSettlement) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	row, err := m.DB.CreateNewGroupSettlement(ctx, database.CreateNewGroupSettlementParams{
+		GroupID:           sql.NullInt64{Int64: settlement.GroupID, Valid: true},
+		MemberID:          sql.NullInt64{Int64: userID, Valid: true},
+		RecipientMemberID: sql.NullInt64{Int64: settlement.RecipientMemberID, Valid: true},
+		Amount:            settlement.Amount.String(),
+		Description:       sql.NullString{String: settlement.Description, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	settlement.ID = row.ID
+	settlement.MemberID = userID
+	settlement.CreatedAt = row.CreatedAt.Time
+	return nil
+}
+
+// GetGroupSettlementsByGroupID() returns the settlements of a group, latest first
+func (m FinancialGroupManagerModel) GetGroupSettlementsByGroupID(groupID int64) ([]*GroupSettlement, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefualtFinManGroupsContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetGroupSettlementsByGroupID(ctx, sql.NullInt64{Int64: groupID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	settlements := []*GroupSettlement{}
+	for _, row := range rows {
+		settlements = append(settlements, &GroupSettlement{
+			ID:                row.ID,
+			GroupID:           row.GroupID.Int64,
+			MemberID:          row.MemberID.Int64,
+			RecipientMemberID: row.RecipientMemberID.Int64,
+			Amount:            decimal.RequireFromString(row.Amount),
+			Description:       row.Description.String,
+			CreatedAt:         row.CreatedAt.Time,
+		})
+	}
+	return settlements, nil
+}
+
+// groupExpenseSplitColumns() turns a split into the member, share and amount arrays it is saved with
+func groupExpenseSplitColumns(splits []*GroupExpenseSplit) ([]int64, []string, []string) {
+	memberIDs := make([]int64, len(splits))
+	shares := make([]string, len(splits))
+	amounts := make([]string, len(splits))
+	for i, split := range splits {
+		memberIDs[i] = split.MemberID
+		shares[i] = split.Share.String()
+		amounts[i] = split.Amount.String()
+	}
+	return memberIDs, shares, amounts
+}
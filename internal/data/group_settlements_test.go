@@ -0,0 +1,190 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+// groupSplitMembers returns split members with the given shares, numbered from member 1
+func groupSplitMembers(shares ...string) []*GroupExpenseSplit {
+	members := make([]*GroupExpenseSplit, len(shares))
+	for i, share := range shares {
+		members[i] = &GroupExpenseSplit{MemberID: int64(i + 1), Share: decimal.RequireFromString(share)}
+	}
+	return members
+}
+
+func TestCalculateGroupExpenseSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    string
+		splitType string
+		members   []*GroupExpenseSplit
+		want      []string
+	}{
+		{"equal even", "90", GroupSplitEqual, groupSplitMembers("0", "0", "0"), []string{"30", "30", "30"}},
+		{"equal with leftover cents", "100", GroupSplitEqual, groupSplitMembers("0", "0", "0"), []string{"33.34", "33.33", "33.33"}},
+		{"shares", "100", GroupSplitShares, groupSplitMembers("2", "1", "1"), []string{"50", "25", "25"}},
+		{"shares with leftover cents", "10", GroupSplitShares, groupSplitMembers("1", "2"), []string{"3.33", "6.67"}},
+		{"exact", "75.5", GroupSplitExact, groupSplitMembers("50", "25.5"), []string{"50", "25.5"}},
+		{"percentage", "200", GroupSplitPercentage, groupSplitMembers("12.5", "87.5"), []string{"25", "175"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount := decimal.RequireFromString(tt.amount)
+			CalculateGroupExpenseSplit(amount, tt.splitType, tt.members)
+			total := decimal.Zero
+			for i, member := range tt.members {
+				if !member.Amount.Equal(decimal.RequireFromString(tt.want[i])) {
+					t.Errorf("member %d amount = %s, want %s", member.MemberID, member.Amount, tt.want[i])
+				}
+				total = total.Add(member.Amount)
+			}
+			if !total.Equal(amount) {
+				t.Errorf("amounts add up to %s, want %s", total, amount)
+			}
+		})
+	}
+}
+
+func TestValidateGroupExpenseSplit(t *testing.T) {
+	duplicate := groupSplitMembers("1", "1")
+	duplicate[1].MemberID = 1
+	tests := []struct {
+		name      string
+		splitType string
+		members   []*GroupExpenseSplit
+		wantValid bool
+	}{
+		{"equal", GroupSplitEqual, groupSplitMembers("0", "0"), true},
+		{"unknown type", "halves", groupSplitMembers("1"), false},
+		{"no members", GroupSplitEqual, nil, false},
+		{"same member twice", GroupSplitEqual, duplicate, false},
+		{"negative share", GroupSplitShares, groupSplitMembers("2", "-1"), false},
+		{"zero shares", GroupSplitShares, groupSplitMembers("0", "0"), false},
+		{"exact adds up", GroupSplitExact, groupSplitMembers("60", "40"), true},
+		{"exact does not add up", GroupSplitExact, groupSplitMembers("60", "30"), false},
+		{"percentage adds up", GroupSplitPercentage, groupSplitMembers("33.5", "66.5"), true},
+		{"percentage does not add up", GroupSplitPercentage, groupSplitMembers("50", "40"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateGroupExpenseSplit(v, decimal.NewFromInt(100), tt.splitType, tt.members)
+			if v.Valid() != tt.wantValid {
+				t.Errorf("ValidateGroupExpenseSplit() valid = %v, want %v (errors: %v)", v.Valid(), tt.wantValid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestBuildGroupBalances(t *testing.T) {
+	// member 1 paid 90 split three ways, member 2 paid 30 split with member 3,
+	// and member 3 has already paid member 1 back 10
+	splits := []*GroupSplitEntry{
+		{ExpenseID: 1, PaidBy: 1, MemberID: 1, Amount: decimal.NewFromInt(30)},
+		{ExpenseID: 1, PaidBy: 1, MemberID: 2, Amount: decimal.NewFromInt(30)},
+		{ExpenseID: 1, PaidBy: 1, MemberID: 3, Amount: decimal.NewFromInt(30)},
+		{ExpenseID: 2, PaidBy: 2, MemberID: 2, Amount: decimal.NewFromInt(15)},
+		{ExpenseID: 2, PaidBy: 2, MemberID: 3, Amount: decimal.NewFromInt(15)},
+	}
+	settlements := []*GroupSettlement{
+		{MemberID: 3, RecipientMemberID: 1, Amount: decimal.NewFromInt(10)},
+	}
+	balances := BuildGroupBalances([]int64{1, 2, 3, 4}, splits, settlements)
+
+	wantBalances := map[int64]string{1: "50", 2: "-15", 3: "-35", 4: "0"}
+	if len(balances.Members) != len(wantBalances) {
+		t.Fatalf("BuildGroupBalances() returned %d members, want %d", len(balances.Members), len(wantBalances))
+	}
+	for _, member := range balances.Members {
+		if !member.Balance.Equal(decimal.RequireFromString(wantBalances[member.UserID])) {
+			t.Errorf("member %d balance = %s, want %s", member.UserID, member.Balance, wantBalances[member.UserID])
+		}
+	}
+
+	wantPairwise := []GroupDebt{
+		{FromUserID: 2, ToUserID: 1, Amount: decimal.NewFromInt(30)},
+		{FromUserID: 3, ToUserID: 1, Amount: decimal.NewFromInt(20)},
+		{FromUserID: 3, ToUserID: 2, Amount: decimal.NewFromInt(15)},
+	}
+	if len(balances.Pairwise) != len(wantPairwise) {
+		t.Fatalf("BuildGroupBalances() returned %d pairwise debts, want %d", len(balances.Pairwise), len(wantPairwise))
+	}
+	for i, debt := range balances.Pairwise {
+		want := wantPairwise[i]
+		if debt.FromUserID != want.FromUserID || debt.ToUserID != want.ToUserID || !debt.Amount.Equal(want.Amount) {
+			t.Errorf("pairwise debt %d = %d->%d %s, want %d->%d %s", i, debt.FromUserID, debt.ToUserID, debt.Amount, want.FromUserID, want.ToUserID, want.Amount)
+		}
+	}
+
+	// the three pairwise debts settle in two transfers
+	wantSettleUp := []GroupDebt{
+		{FromUserID: 3, ToUserID: 1, Amount: decimal.NewFromInt(35)},
+		{FromUserID: 2, ToUserID: 1, Amount: decimal.NewFromInt(15)},
+	}
+	if len(balances.SettleUp) != len(wantSettleUp) {
+		t.Fatalf("BuildGroupBalances() returned %d settle up transfers, want %d", len(balances.SettleUp), len(wantSettleUp))
+	}
+	for i, transfer := range balances.SettleUp {
+		want := wantSettleUp[i]
+		if transfer.FromUserID != want.FromUserID || transfer.ToUserID != want.ToUserID || !transfer.Amount.Equal(want.Amount) {
+			t.Errorf("settle up transfer %d = %d->%d %s, want %d->%d %s", i, transfer.FromUserID, transfer.ToUserID, transfer.Amount, want.FromUserID, want.ToUserID, want.Amount)
+		}
+	}
+}
+
+func TestSimplifyGroupDebts(t *testing.T) {
+	balance := func(userID int64, amount int64) *GroupMemberBalance {
+		return &GroupMemberBalance{UserID: userID, Balance: decimal.NewFromInt(amount)}
+	}
+	tests := []struct {
+		name          string
+		balances      []*GroupMemberBalance
+		wantTransfers int
+	}{
+		{"settled", []*GroupMemberBalance{balance(1, 0), balance(2, 0)}, 0},
+		{"one debtor", []*GroupMemberBalance{balance(1, 40), balance(2, -40)}, 1},
+		{"chain", []*GroupMemberBalance{balance(1, 30), balance(2, 0), balance(3, -30)}, 1},
+		{"many to many", []*GroupMemberBalance{balance(1, 60), balance(2, 40), balance(3, -50), balance(4, -50)}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfers := SimplifyGroupDebts(tt.balances)
+			if len(transfers) != tt.wantTransfers {
+				t.Fatalf("SimplifyGroupDebts() returned %d transfers, want %d", len(transfers), tt.wantTransfers)
+			}
+			// applying the transfers has to leave every balance at zero
+			net := make(map[int64]decimal.Decimal)
+			for _, b := range tt.balances {
+				net[b.UserID] = b.Balance
+			}
+			for _, transfer := range transfers {
+				net[transfer.FromUserID] = net[transfer.FromUserID].Add(transfer.Amount)
+				net[transfer.ToUserID] = net[transfer.ToUserID].Sub(transfer.Amount)
+			}
+			for userID, amount := range net {
+				if !amount.IsZero() {
+					t.Errorf("member %d is left with a balance of %s", userID, amount)
+				}
+			}
+		})
+	}
+}
+
+func TestSetGroupMemberBalances(t *testing.T) {
+	members := []*GroupMember{{UserID: 1}, {UserID: 2}, {UserID: 3}}
+	balances := &GroupBalances{Members: []*GroupMemberBalance{
+		{UserID: 1, Balance: decimal.NewFromInt(25)},
+		{UserID: 2, Balance: decimal.NewFromInt(-25)},
+	}}
+	SetGroupMemberBalances(members, balances)
+	want := map[int64]string{1: "25", 2: "-25", 3: "0"}
+	for _, member := range members {
+		if member.Balance == nil || member.Balance.String() != want[member.UserID] {
+			t.Errorf("member %d balance = %v, want %s", member.UserID, member.Balance, want[member.UserID])
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"database/sql"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
@@ -12,6 +13,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/Blue-Davinci/OptiVest/internal/database"
 	"github.com/shopspring/decimal"
 )
 
@@ -32,6 +34,26 @@ func contextGenerator(ctx context.Context, timeout time.Duration) (context.Conte
 	return context.WithTimeout(ctx, timeout)
 }
 
+// withTransaction() runs fn with the queries bound to a single database transaction. The
+// transaction is committed when fn succeeds and rolled back when it returns an error.
+// Models bound to a transaction have no connection of their own, fn then joins that transaction
+func withTransaction(ctx context.Context, conn *sql.DB, db *database.Queries, fn func(q *database.Queries) error) error {
+	if conn == nil {
+		return fn(db)
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// rolling back after a commit does nothing
+	defer tx.Rollback()
+	err = fn(db.WithTx(tx))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // generateSecurityKey() generates a cryptographically secure AES key of the given length
 func generateSecurityKey(keyLength int) ([]byte, error) {
 	if keyLength != KeyLength16 && keyLength != KeyLength24 && keyLength != KeyLength32 {
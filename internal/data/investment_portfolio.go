@@ -38,6 +38,21 @@ func (ct *CustomTime1) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// Optional is a field of an update request that can be cleared by sending null. Set reports
+// whether the field was in the request at all, a null leaves Value as its zero value
+type Optional[T any] struct {
+	Value T
+	Set   bool
+}
+
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+	if string(b) == "null" {
+		return nil
+	}
+	return json.Unmarshal(b, &o.Value)
+}
+
 const (
 	DefaultInvPortContextTimeout              = 5 * time.Second
 	DefaultInvestmentPortfolioSummaryTTL      = 10 * time.Minute
@@ -170,16 +185,17 @@ type AlternativeInvestment struct {
 
 // InvestmentTransaction represents a transaction made by a user in the investment portfolio.
 type InvestmentTransaction struct {
-	ID                int64                        `json:"id"`                 // Auto-generated ID
-	UserID            int64                        `json:"user_id"`            // ID of the user making the transaction
-	InvestmentType    database.InvestmentTypeEnum  `json:"investment_type"`    // Type of investment (Stock, Bond, Alternative)
-	InvestmentID      int64                        `json:"investment_id"`      // ID of the investment
-	TransactionType   database.TransactionTypeEnum `json:"transaction_type"`   // Type of transaction (buy, sell, other)
-	TransactionDate   CustomTime1                  `json:"transaction_date"`   // Date of the transaction
-	TransactionAmount decimal.Decimal              `json:"transaction_amount"` // Amount involved in the transaction
-	Quantity          decimal.Decimal              `json:"quantity"`           // Number of units bought/sold
-	CreatedAt         time.Time                    `json:"created_at"`         // Record creation timestamp
-	UpdatedAt         time.Time                    `json:"updated_at"`         // Record update timestamp
+	ID                int64                        `json:"id"`                   // Auto-generated ID
+	UserID            int64                        `json:"user_id"`              // ID of the user making the transaction
+	InvestmentType    database.InvestmentTypeEnum  `json:"investment_type"`      // Type of investment (Stock, Bond, Alternative)
+	InvestmentID      int64                        `json:"investment_id"`        // ID of the investment
+	TransactionType   database.TransactionTypeEnum `json:"transaction_type"`     // Type of transaction (buy, sell, other)
+	TransactionDate   CustomTime1                  `json:"transaction_date"`     // Date of the transaction
+	TransactionAmount decimal.Decimal              `json:"transaction_amount"`   // Amount involved in the transaction
+	Quantity          decimal.Decimal              `json:"quantity"`             // Number of units bought/sold
+	AccountID         int64                        `json:"account_id,omitempty"` // Account the transaction was paid from or into
+	CreatedAt         time.Time                    `json:"created_at"`           // Record creation timestamp
+	UpdatedAt         time.Time                    `json:"updated_at"`           // Record update timestamp
 }
 
 // BondAnalysisStatistics struct to hold the bond analysis statistics
@@ -937,6 +953,7 @@ func (m InvestmentPortfolioModel) CreateNewInvestmentTransaction(userID int64, i
 		TransactionDate:   investmentTransaction.TransactionDate.Time,
 		TransactionAmount: investmentTransaction.TransactionAmount.String(),
 		Quantity:          investmentTransaction.Quantity.String(),
+		AccountID:         sql.NullInt64{Int64: investmentTransaction.AccountID, Valid: investmentTransaction.AccountID != 0},
 	})
 	if err != nil {
 		return err
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"database/sql"
 	"errors"
 
 	"github.com/Blue-Davinci/OptiVest/internal/database"
@@ -31,16 +32,30 @@ type Models struct {
 	GeneralManagerModel        GeneralManagerModel
 	AlgoManager                AlgoManager
 	MFAManager                 MFAManager
+	AttachmentManager          AttachmentManagerModel
+	ReceiptManager             ReceiptManagerModel
+	TagManager                 TagManagerModel
+	CalendarManager            CalendarManagerModel
+	TaxManager                 TaxManagerModel
+	ExchangeRateManager        ExchangeRateManagerModel
+	SubscriptionManager        SubscriptionManagerModel
+	AnomalyManager             AnomalyManagerModel
+	BatchManager               BatchManagerModel
+	NetWorthManager            NetWorthManagerModel
+	AccountManager             AccountManagerModel
 }
 
-func NewModels(db *database.Queries) Models {
+// NewModels() wraps the connection pool in the generated queries for the models,
+// the pool itself is kept for the models that run database transactions
+func NewModels(conn *sql.DB) Models {
+	db := database.New(conn)
 	return Models{
 		Users:                      UserModel{DB: db},
 		Tokens:                     TokenModel{DB: db},
 		ApiManager:                 ApiManagerModel{DB: db},
 		FinancialManager:           FinancialManagerModel{DB: db},
-		FinancialGroupManager:      FinancialGroupManagerModel{DB: db},
-		FinancialTrackingManager:   FinancialTrackingModel{DB: db},
+		FinancialGroupManager:      FinancialGroupManagerModel{DB: db, Conn: conn},
+		FinancialTrackingManager:   FinancialTrackingModel{DB: db, Conn: conn},
 		NotificationManager:        NotificationManagerModel{DB: db},
 		InvestmentPortfolioManager: InvestmentPortfolioModel{DB: db},
 		FeedManager:                FeedManagerModel{DB: db},
@@ -51,5 +66,16 @@ func NewModels(db *database.Queries) Models {
 		GeneralManagerModel:        GeneralManagerModel{DB: db},
 		AlgoManager:                AlgoManager{DB: db},
 		MFAManager:                 MFAManager{DB: db},
+		AttachmentManager:          AttachmentManagerModel{DB: db},
+		ReceiptManager:             ReceiptManagerModel{DB: db, Conn: conn},
+		TagManager:                 TagManagerModel{DB: db, Conn: conn},
+		CalendarManager:            CalendarManagerModel{DB: db},
+		TaxManager:                 TaxManagerModel{DB: db},
+		ExchangeRateManager:        ExchangeRateManagerModel{DB: db},
+		SubscriptionManager:        SubscriptionManagerModel{DB: db, Conn: conn},
+		AnomalyManager:             AnomalyManagerModel{DB: db},
+		BatchManager:               BatchManagerModel{DB: db, Conn: conn},
+		NetWorthManager:            NetWorthManagerModel{DB: db},
+		AccountManager:             AccountManagerModel{DB: db, Conn: conn},
 	}
 }
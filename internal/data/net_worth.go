@@ -0,0 +1,252 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type NetWorthManagerModel struct {
+	DB *database.Queries
+}
+
+var (
+	DefaultNetWorthDBContextTimeout = 10 * time.Second
+	// DefaultNetWorthHistoryDays is how far back the net worth history goes by default
+	DefaultNetWorthHistoryDays = 90
+	// MaxNetWorthHistoryDays is the longest history that can be requested at once
+	MaxNetWorthHistoryDays = 1830
+)
+
+// NetWorthAssets is what a user owns. Stocks and bonds are valued at their current value,
+// or at their purchase price while no current value is known. Accounts is the sum of the
+// accounts holding money
+type NetWorthAssets struct {
+	Stocks       decimal.Decimal `json:"stocks"`
+	Bonds        decimal.Decimal `json:"bonds"`
+	Alternatives decimal.Decimal `json:"alternatives"`
+	Accounts     decimal.Decimal `json:"accounts"`
+	Total        decimal.Decimal `json:"total"`
+}
+
+// NetWorthLiabilities is what a user owes. Accounts is the sum of the overdrawn accounts and
+// credit cards carrying debt, Debts leaves out the debts linked to a credit card account as
+// the card's balance already counts them
+type NetWorthLiabilities struct {
+	Debts    decimal.Decimal `json:"debts"`
+	Accounts decimal.Decimal `json:"accounts"`
+	Total    decimal.Decimal `json:"total"`
+}
+
+// NetWorthSnapshot is a user's net worth on a single day with its breakdown
+type NetWorthSnapshot struct {
+	ID           int64               `json:"id,omitempty"`
+	Date         time.Time           `json:"date"`
+	CurrencyCode string              `json:"currency_code"`
+	Assets       NetWorthAssets      `json:"assets"`
+	Liabilities  NetWorthLiabilities `json:"liabilities"`
+	NetWorth     decimal.Decimal     `json:"net_worth"`
+}
+
+// NetWorthChange is how much the net worth moved between the first and last snapshot of a history.
+// The percentage is relative to the first net worth and is left at zero when that was zero
+type NetWorthChange struct {
+	StartNetWorth    decimal.Decimal `json:"start_net_worth"`
+	EndNetWorth      decimal.Decimal `json:"end_net_worth"`
+	Change           decimal.Decimal `json:"change"`
+	ChangePercentage decimal.Decimal `json:"change_percentage"`
+}
+
+// ValidateNetWorthHistoryRange() validates the dates of a net worth history
+func ValidateNetWorthHistoryRange(v *validator.Validator, startDate, endDate time.Time) {
+	v.Check(!endDate.Before(startDate), "end_date", "must not be before the start date")
+	v.Check(endDate.Sub(startDate) < time.Duration(MaxNetWorthHistoryDays)*24*time.Hour, "end_date", fmt.Sprintf("range must not be longer than %d days", MaxNetWorthHistoryDays))
+}
+
+// NewNetWorthSnapshot() adds up the values of a user's assets and liabilities into a snapshot for a day
+func NewNetWorthSnapshot(date time.Time, currencyCode string, assets NetWorthAssets, liabilities NetWorthLiabilities) *NetWorthSnapshot {
+	assets.Total = assets.Stocks.Add(assets.Bonds).Add(assets.Alternatives).Add(assets.Accounts)
+	liabilities.Total = liabilities.Debts.Add(liabilities.Accounts)
+	return &NetWorthSnapshot{
+		Date:         dateOnly(date),
+		CurrencyCode: currencyCode,
+		Assets:       assets,
+		Liabilities:  liabilities,
+		NetWorth:     assets.Total.Sub(liabilities.Total),
+	}
+}
+
+// CalculateNetWorthChange() returns the change of the net worth over a history of snapshots in date order,
+// nil when there are no snapshots
+func CalculateNetWorthChange(snapshots []*NetWorthSnapshot) *NetWorthChange {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	start := snapshots[0].NetWorth
+	end := snapshots[len(snapshots)-1].NetWorth
+	change := &NetWorthChange{
+		StartNetWorth:    start,
+		EndNetWorth:      end,
+		Change:           end.Sub(start),
+		ChangePercentage: decimal.Zero,
+	}
+	if !start.IsZero() {
+		change.ChangePercentage = change.Change.Div(start.Abs()).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+	return change
+}
+
+// ConvertNetWorthInvestments() converts the stocks and bonds of a snapshot into the snapshot's currency.
+// They are valued at market prices quoted in the market currency, while alternatives and debts are
+// recorded in the user's currency already
+func ConvertNetWorthInvestments(snapshot *NetWorthSnapshot, marketCurrencyCode string, rates map[string]decimal.Decimal) *NetWorthSnapshot {
+	rate, ok := rates[marketCurrencyCode]
+	if !ok || marketCurrencyCode == snapshot.CurrencyCode {
+		return snapshot
+	}
+	assets := snapshot.Assets
+	assets.Stocks = assets.Stocks.Mul(rate).Round(2)
+	assets.Bonds = assets.Bonds.Mul(rate).Round(2)
+	updated := NewNetWorthSnapshot(snapshot.Date, snapshot.CurrencyCode, assets, snapshot.Liabilities)
+	updated.ID = snapshot.ID
+	return updated
+}
+
+// AddAccountsToNetWorth() adds the balances of the user's accounts to a snapshot. Balances are
+// converted into the snapshot's currency with the rates from each account's currency, positive
+// balances count as assets and negative balances as liabilities
+func AddAccountsToNetWorth(snapshot *NetWorthSnapshot, accounts []*Account, rates map[string]decimal.Decimal) *NetWorthSnapshot {
+	assets, liabilities := snapshot.Assets, snapshot.Liabilities
+	assets.Accounts, liabilities.Accounts = decimal.Zero, decimal.Zero
+	for _, account := range accounts {
+		balance := account.Balance
+		if rate, ok := rates[account.CurrencyCode]; ok && account.CurrencyCode != snapshot.CurrencyCode {
+			balance = balance.Mul(rate).Round(2)
+		}
+		if balance.IsNegative() {
+			liabilities.Accounts = liabilities.Accounts.Add(balance.Neg())
+			continue
+		}
+		assets.Accounts = assets.Accounts.Add(balance)
+	}
+	updated := NewNetWorthSnapshot(snapshot.Date, snapshot.CurrencyCode, assets, liabilities)
+	updated.ID = snapshot.ID
+	return updated
+}
+
+// GetCurrentNetWorthByUserID() calculates the user's net worth as it stands right now, without
+// the user's accounts which are added with AddAccountsToNetWorth(). Stocks and bonds are in the market
+// currency until they are converted with ConvertNetWorthInvestments().
+// The currency is the user's currency, empty when the user has not set one
+func (m NetWorthManagerModel) GetCurrentNetWorthByUserID(userID int64, now time.Time) (*NetWorthSnapshot, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultNetWorthDBContextTimeout)
+	defer cancel()
+	components, err := m.DB.GetNetWorthComponentsByUserID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	assets := NetWorthAssets{
+		Stocks:       decimal.RequireFromString(components.StocksValue),
+		Bonds:        decimal.RequireFromString(components.BondsValue),
+		Alternatives: decimal.RequireFromString(components.AlternativesValue),
+	}
+	liabilities := NetWorthLiabilities{
+		Debts: decimal.RequireFromString(components.DebtsBalance),
+	}
+	return NewNetWorthSnapshot(now, components.CurrencyCode, assets, liabilities), nil
+}
+
+// GetNetWorthSnapshotUserIDs() returns the users that hold investments, debts or accounts, the users
+// the daily snapshot is taken for
+func (m NetWorthManagerModel) GetNetWorthSnapshotUserIDs() ([]int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultNetWorthDBContextTimeout)
+	defer cancel()
+	return m.DB.GetNetWorthSnapshotUserIDs(ctx)
+}
+
+// SaveNetWorthSnapshot() saves a snapshot, replacing the snapshot already taken that day
+func (m NetWorthManagerModel) SaveNetWorthSnapshot(userID int64, snapshot *NetWorthSnapshot) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultNetWorthDBContextTimeout)
+	defer cancel()
+	row, err := m.DB.UpsertNetWorthSnapshot(ctx, database.UpsertNetWorthSnapshotParams{
+		UserID:            userID,
+		SnapshotDate:      snapshot.Date,
+		CurrencyCode:      snapshot.CurrencyCode,
+		StocksValue:       snapshot.Assets.Stocks.String(),
+		BondsValue:        snapshot.Assets.Bonds.String(),
+		AlternativesValue: snapshot.Assets.Alternatives.String(),
+		TotalAssets:       snapshot.Assets.Total.String(),
+		DebtsBalance:      snapshot.Liabilities.Debts.String(),
+		TotalLiabilities:  snapshot.Liabilities.Total.String(),
+		NetWorth:          snapshot.NetWorth.String(),
+		AccountsValue:     snapshot.Assets.Accounts.String(),
+		AccountsOwed:      snapshot.Liabilities.Accounts.String(),
+	})
+	if err != nil {
+		return err
+	}
+	snapshot.ID = row.ID
+	return nil
+}
+
+// GetNetWorthSnapshotsByUserID() returns the user's snapshots between two dates in date order
+func (m NetWorthManagerModel) GetNetWorthSnapshotsByUserID(userID int64, startDate, endDate time.Time) ([]*NetWorthSnapshot, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultNetWorthDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetNetWorthSnapshotsByUserID(ctx, database.GetNetWorthSnapshotsByUserIDParams{
+		UserID:  userID,
+		Column2: startDate,
+		Column3: endDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*NetWorthSnapshot, len(rows))
+	for i, row := range rows {
+		snapshots[i] = &NetWorthSnapshot{
+			ID:           row.ID,
+			Date:         row.SnapshotDate,
+			CurrencyCode: row.CurrencyCode,
+			Assets: NetWorthAssets{
+				Stocks:       decimal.RequireFromString(row.StocksValue),
+				Bonds:        decimal.RequireFromString(row.BondsValue),
+				Alternatives: decimal.RequireFromString(row.AlternativesValue),
+				Accounts:     decimal.RequireFromString(row.AccountsValue),
+				Total:        decimal.RequireFromString(row.TotalAssets),
+			},
+			Liabilities: NetWorthLiabilities{
+				Debts:    decimal.RequireFromString(row.DebtsBalance),
+				Accounts: decimal.RequireFromString(row.AccountsOwed),
+				Total:    decimal.RequireFromString(row.TotalLiabilities),
+			},
+			NetWorth: decimal.RequireFromString(row.NetWorth),
+		}
+	}
+	return snapshots, nil
+}
+
+// AppendCurrentNetWorth() ends a history with the current net worth so that it is up to date
+// between daily snapshots. A snapshot already taken on the same day is replaced
+func AppendCurrentNetWorth(snapshots []*NetWorthSnapshot, current *NetWorthSnapshot) []*NetWorthSnapshot {
+	if n := len(snapshots); n > 0 && snapshots[n-1].Date.Equal(current.Date) {
+		current.ID = snapshots[n-1].ID
+		snapshots[n-1] = current
+		return snapshots
+	}
+	return append(snapshots, current)
+}
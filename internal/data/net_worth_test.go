@@ -0,0 +1,225 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+func TestNewNetWorthSnapshot(t *testing.T) {
+	date := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name            string
+		assets          NetWorthAssets
+		liabilities     NetWorthLiabilities
+		wantAssets      string
+		wantLiabilities string
+		wantNetWorth    string
+	}{
+		{
+			name:            "Assets and debts",
+			assets:          NetWorthAssets{Stocks: decimal.NewFromInt(1000), Bonds: decimal.NewFromInt(500), Alternatives: decimal.RequireFromString("250.50")},
+			liabilities:     NetWorthLiabilities{Debts: decimal.NewFromInt(400)},
+			wantAssets:      "1750.5",
+			wantLiabilities: "400",
+			wantNetWorth:    "1350.5",
+		},
+		{
+			name:            "Debts larger than assets",
+			assets:          NetWorthAssets{Stocks: decimal.NewFromInt(100)},
+			liabilities:     NetWorthLiabilities{Debts: decimal.NewFromInt(300)},
+			wantAssets:      "100",
+			wantLiabilities: "300",
+			wantNetWorth:    "-200",
+		},
+		{
+			name:            "Nothing held",
+			wantAssets:      "0",
+			wantLiabilities: "0",
+			wantNetWorth:    "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := NewNetWorthSnapshot(date, "USD", tt.assets, tt.liabilities)
+			if !snapshot.Date.Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("NewNetWorthSnapshot() date = %v, want the start of the day", snapshot.Date)
+			}
+			if snapshot.Assets.Total.String() != tt.wantAssets {
+				t.Errorf("NewNetWorthSnapshot() assets = %s, want %s", snapshot.Assets.Total, tt.wantAssets)
+			}
+			if snapshot.Liabilities.Total.String() != tt.wantLiabilities {
+				t.Errorf("NewNetWorthSnapshot() liabilities = %s, want %s", snapshot.Liabilities.Total, tt.wantLiabilities)
+			}
+			if snapshot.NetWorth.String() != tt.wantNetWorth {
+				t.Errorf("NewNetWorthSnapshot() net worth = %s, want %s", snapshot.NetWorth, tt.wantNetWorth)
+			}
+		})
+	}
+}
+
+func TestCalculateNetWorthChange(t *testing.T) {
+	snapshot := func(netWorth int64) *NetWorthSnapshot {
+		return &NetWorthSnapshot{NetWorth: decimal.NewFromInt(netWorth)}
+	}
+	tests := []struct {
+		name           string
+		snapshots      []*NetWorthSnapshot
+		wantNil        bool
+		wantChange     string
+		wantPercentage string
+	}{
+		{name: "No snapshots", wantNil: true},
+		{name: "Growth", snapshots: []*NetWorthSnapshot{snapshot(1000), snapshot(900), snapshot(1250)}, wantChange: "250", wantPercentage: "25"},
+		{name: "Decline", snapshots: []*NetWorthSnapshot{snapshot(2000), snapshot(1500)}, wantChange: "-500", wantPercentage: "-25"},
+		{name: "Negative start", snapshots: []*NetWorthSnapshot{snapshot(-1000), snapshot(-500)}, wantChange: "500", wantPercentage: "50"},
+		{name: "Zero start", snapshots: []*NetWorthSnapshot{snapshot(0), snapshot(300)}, wantChange: "300", wantPercentage: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := CalculateNetWorthChange(tt.snapshots)
+			if tt.wantNil {
+				if change != nil {
+					t.Errorf("CalculateNetWorthChange() = %+v, want nil", change)
+				}
+				return
+			}
+			if change.Change.String() != tt.wantChange || change.ChangePercentage.String() != tt.wantPercentage {
+				t.Errorf("CalculateNetWorthChange() = %s (%s%%), want %s (%s%%)", change.Change, change.ChangePercentage, tt.wantChange, tt.wantPercentage)
+			}
+		})
+	}
+}
+
+func TestAppendCurrentNetWorth(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name      string
+		snapshots []*NetWorthSnapshot
+		wantLen   int
+		wantID    int64
+	}{
+		{name: "Empty history", wantLen: 1},
+		{name: "Snapshot taken earlier", snapshots: []*NetWorthSnapshot{{ID: 1, Date: day(14)}}, wantLen: 2},
+		{name: "Snapshot taken today", snapshots: []*NetWorthSnapshot{{ID: 1, Date: day(14)}, {ID: 2, Date: day(15)}}, wantLen: 2, wantID: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := &NetWorthSnapshot{Date: day(15), NetWorth: decimal.NewFromInt(10)}
+			history := AppendCurrentNetWorth(tt.snapshots, current)
+			if len(history) != tt.wantLen {
+				t.Fatalf("AppendCurrentNetWorth() length = %d, want %d", len(history), tt.wantLen)
+			}
+			last := history[len(history)-1]
+			if last != current || last.ID != tt.wantID {
+				t.Errorf("AppendCurrentNetWorth() last = %+v, want the current net worth with ID %d", last, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestValidateNetWorthHistoryRange(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		endDate time.Time
+		valid   bool
+	}{
+		{name: "Single day", endDate: start, valid: true},
+		{name: "Default range", endDate: start.AddDate(0, 0, DefaultNetWorthHistoryDays-1), valid: true},
+		{name: "End before start", endDate: start.AddDate(0, 0, -1), valid: false},
+		{name: "Range too long", endDate: start.AddDate(0, 0, MaxNetWorthHistoryDays), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateNetWorthHistoryRange(v, start, tt.endDate)
+			if v.Valid() != tt.valid {
+				t.Errorf("ValidateNetWorthHistoryRange() valid = %v, want %v (%v)", v.Valid(), tt.valid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestConvertNetWorthInvestments(t *testing.T) {
+	rates := map[string]decimal.Decimal{"USD": decimal.RequireFromString("0.9")}
+	tests := []struct {
+		name         string
+		currencyCode string
+		wantStocks   string
+		wantBonds    string
+		wantNetWorth string
+	}{
+		{name: "Market currency", currencyCode: "USD", wantStocks: "1000", wantBonds: "500", wantNetWorth: "1350"},
+		{name: "Converted investments", currencyCode: "EUR", wantStocks: "900", wantBonds: "450", wantNetWorth: "1200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := NewNetWorthSnapshot(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), tt.currencyCode,
+				NetWorthAssets{Stocks: decimal.NewFromInt(1000), Bonds: decimal.NewFromInt(500), Alternatives: decimal.NewFromInt(250)},
+				NetWorthLiabilities{Debts: decimal.NewFromInt(400)})
+			snapshot.ID = 7
+			updated := ConvertNetWorthInvestments(snapshot, "USD", rates)
+			if updated.ID != 7 {
+				t.Errorf("ConvertNetWorthInvestments() ID = %d, want 7", updated.ID)
+			}
+			if updated.Assets.Stocks.String() != tt.wantStocks || updated.Assets.Bonds.String() != tt.wantBonds {
+				t.Errorf("ConvertNetWorthInvestments() stocks, bonds = %s, %s, want %s, %s", updated.Assets.Stocks, updated.Assets.Bonds, tt.wantStocks, tt.wantBonds)
+			}
+			if updated.NetWorth.String() != tt.wantNetWorth {
+				t.Errorf("ConvertNetWorthInvestments() net worth = %s, want %s", updated.NetWorth, tt.wantNetWorth)
+			}
+		})
+	}
+}
+
+func TestAddAccountsToNetWorth(t *testing.T) {
+	account := func(currency, balance string) *Account {
+		return &Account{CurrencyCode: currency, Balance: decimal.RequireFromString(balance)}
+	}
+	rates := map[string]decimal.Decimal{"EUR": decimal.RequireFromString("1.1")}
+	tests := []struct {
+		name            string
+		accounts        []*Account
+		wantAccounts    string
+		wantOwed        string
+		wantNetWorth    string
+		wantLiabilities string
+	}{
+		{name: "No accounts", wantAccounts: "0", wantOwed: "0", wantNetWorth: "600", wantLiabilities: "400"},
+		{name: "Positive balances", accounts: []*Account{account("USD", "250"), account("USD", "50.25")}, wantAccounts: "300.25", wantOwed: "0", wantNetWorth: "900.25", wantLiabilities: "400"},
+		{name: "Credit card debt", accounts: []*Account{account("USD", "500"), account("USD", "-120")}, wantAccounts: "500", wantOwed: "120", wantNetWorth: "980", wantLiabilities: "520"},
+		{name: "Converted balance", accounts: []*Account{account("EUR", "100")}, wantAccounts: "110", wantOwed: "0", wantNetWorth: "710", wantLiabilities: "400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := NewNetWorthSnapshot(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), "USD",
+				NetWorthAssets{Stocks: decimal.NewFromInt(1000)}, NetWorthLiabilities{Debts: decimal.NewFromInt(400)})
+			snapshot.ID = 7
+			updated := AddAccountsToNetWorth(snapshot, tt.accounts, rates)
+			if updated.ID != 7 {
+				t.Errorf("AddAccountsToNetWorth() ID = %d, want 7", updated.ID)
+			}
+			if updated.Assets.Accounts.String() != tt.wantAccounts {
+				t.Errorf("AddAccountsToNetWorth() account assets = %s, want %s", updated.Assets.Accounts, tt.wantAccounts)
+			}
+			if updated.Liabilities.Accounts.String() != tt.wantOwed {
+				t.Errorf("AddAccountsToNetWorth() owed on accounts = %s, want %s", updated.Liabilities.Accounts, tt.wantOwed)
+			}
+			if updated.Liabilities.Total.String() != tt.wantLiabilities {
+				t.Errorf("AddAccountsToNetWorth() liabilities = %s, want %s", updated.Liabilities.Total, tt.wantLiabilities)
+			}
+			if updated.NetWorth.String() != tt.wantNetWorth {
+				t.Errorf("AddAccountsToNetWorth() net worth = %s, want %s", updated.NetWorth, tt.wantNetWorth)
+			}
+		})
+	}
+}
@@ -37,6 +37,12 @@ const (
 	NotificationTypeBudget              = "budget"
 	NotificationTypeAward               = "award"
 	NotificationTypeGroupInvite         = "group_invite"
+	NotificationTypeSpendingAnomaly     = "spending_anomaly"
+	NotificationTypeGroupContribution   = "group_contribution"
+	NotificationTypeGroupJoinRequest    = "group_join_request"
+	NotificationTypeGroupUpdate         = "group_update"
+	NotificationTypeGroupActivity       = "group_activity"
+	NotificationTypeGroupChallenge      = "group_challenge"
 )
 
 const (
@@ -0,0 +1,683 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type ReceiptManagerModel struct {
+	DB   *database.Queries
+	Conn *sql.DB
+}
+
+const (
+	ReceiptBudgetMatchCategoryRule = "category_rule"
+	ReceiptBudgetMatchCategory     = "budget_category"
+	ReceiptBudgetMatchName         = "budget_name"
+	DefaultReceiptDraftName        = "Receipt"
+	DefaultReceiptDraftCategory    = "uncategorized"
+)
+
+var (
+	DefaultReceiptDBContextTimeout = 5 * time.Second
+	DefaultReceiptDraftTTL         = 24 * time.Hour
+)
+
+var (
+	ErrReceiptNotReadable           = errors.New("the receipt could not be read, please upload a clearer image")
+	ErrReceiptTotalNotFound         = errors.New("no total amount could be found on the receipt")
+	ErrDuplicateExpenseCategoryRule = errors.New("a category rule with this pattern already exists")
+)
+
+// receiptDateLayouts are the date formats we accept from the receipt analysis, in order of preference
+var receiptDateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"02/01/2006",
+	"02-01-2006",
+	"02.01.2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	time.RFC3339,
+}
+
+// receiptCurrencySymbols maps unambiguous currency symbols to their ISO 4217 code.
+// The dollar sign is left out on purpose as it is used by too many currencies.
+var receiptCurrencySymbols = map[string]string{
+	"€": "EUR",
+	"£": "GBP",
+	"¥": "JPY",
+	"₹": "INR",
+	"₦": "NGN",
+}
+
+// ReceiptLineItem is a single purchased item read from a receipt
+type ReceiptLineItem struct {
+	Description string          `json:"description"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	Amount      decimal.Decimal `json:"amount"`
+}
+
+// ReceiptExtraction holds the structured data parsed from the OCR/LLM receipt analysis.
+// DateOccurred is zero when no date could be read
+type ReceiptExtraction struct {
+	Merchant     string            `json:"merchant"`
+	DateOccurred time.Time         `json:"date_occurred"`
+	Total        decimal.Decimal   `json:"total"`
+	CurrencyCode string            `json:"currency_code"`
+	LineItems    []ReceiptLineItem `json:"line_items"`
+}
+
+// ExpenseCategoryRule assigns a category (and optionally a budget) to receipts whose
+// merchant or line items contain the pattern
+type ExpenseCategoryRule struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Pattern   string    `json:"pattern"`
+	Category  string    `json:"category"`
+	BudgetID  int64     `json:"budget_id,omitempty"`
+	Priority  int32     `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BudgetSuggestion is the budget we think a drafted expense belongs to and why
+type BudgetSuggestion struct {
+	BudgetID     int64  `json:"budget_id"`
+	BudgetName   string `json:"budget_name"`
+	CurrencyCode string `json:"currency_code"`
+	Match        string `json:"match"`
+}
+
+// ReceiptExpenseDraft is an expense prepared from a receipt that is waiting for the user
+// to confirm it. The receipt becomes an attachment of the expense once confirmed
+type ReceiptExpenseDraft struct {
+	ID               int64              `json:"id"`
+	UserID           int64              `json:"user_id"`
+	Expense          *Expense           `json:"expense"`
+	Merchant         string             `json:"merchant"`
+	OriginalAmount   decimal.Decimal    `json:"original_amount"`
+	CurrencyCode     string             `json:"currency_code"`
+	LineItems        []ReceiptLineItem  `json:"line_items"`
+	BudgetSuggestion *BudgetSuggestion  `json:"budget_suggestion"`
+	CategoryRuleID   int64              `json:"category_rule_id,omitempty"`
+	Receipt          *ExpenseAttachment `json:"receipt"`
+	ExpiresAt        time.Time          `json:"expires_at"`
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
+// ValidateExpenseCategoryRule() validates a category rule
+func ValidateExpenseCategoryRule(v *validator.Validator, rule *ExpenseCategoryRule) {
+	v.Check(len(strings.TrimSpace(rule.Pattern)) >= 2, "pattern", "must be at least 2 characters long")
+	v.Check(len(rule.Pattern) <= 255, "pattern", "must not be more than 255 bytes long")
+	v.Check(rule.Category != "", "category", "must be provided")
+	v.Check(len(rule.Category) <= 255, "category", "must not be more than 255 bytes long")
+	v.Check(rule.Priority >= 0 && rule.Priority <= 1000, "priority", "must be between 0 and 1000")
+}
+
+// ParseReceiptAnalysis() turns the JSON returned by the LLM into a ReceiptExtraction.
+// We accept both the keys of the draft prompt (merchant, total, line_items ...) and the
+// older analysis prompt (store_name, total_amount_spent, items_purchased ...).
+// When no total is present we fall back to the sum of the line items.
+func ParseReceiptAnalysis(analysis map[string]interface{}) (*ReceiptExtraction, error) {
+	if len(analysis) == 0 {
+		return nil, ErrReceiptNotReadable
+	}
+	extraction := &ReceiptExtraction{
+		Merchant:  strings.TrimSpace(receiptString(analysis, "merchant", "store_name")),
+		LineItems: []ReceiptLineItem{},
+	}
+	// date of purchase
+	dateValue := receiptString(analysis, "date", "date_of_purchase")
+	for _, layout := range receiptDateLayouts {
+		if parsed, err := time.Parse(layout, strings.TrimSpace(dateValue)); err == nil {
+			extraction.DateOccurred = parsed
+			break
+		}
+	}
+	// currency, either as an explicit code or from the symbol on the total
+	currency := strings.ToUpper(strings.TrimSpace(receiptString(analysis, "currency", "currency_code")))
+	if isCurrencyCode(currency) {
+		extraction.CurrencyCode = currency
+	}
+	total, symbolCurrency, totalFound := parseReceiptAmount(receiptValue(analysis, "total", "total_amount_spent"))
+	if extraction.CurrencyCode == "" {
+		extraction.CurrencyCode = symbolCurrency
+	}
+	// line items
+	itemsSum := decimal.Zero
+	if items, ok := receiptValue(analysis, "line_items", "items_purchased").([]interface{}); ok {
+		for _, item := range items {
+			lineItem, ok := parseReceiptLineItem(item)
+			if !ok {
+				continue
+			}
+			itemsSum = itemsSum.Add(lineItem.Amount)
+			extraction.LineItems = append(extraction.LineItems, lineItem)
+		}
+	}
+	// total
+	switch {
+	case totalFound && total.IsPositive():
+		extraction.Total = total
+	case itemsSum.IsPositive():
+		extraction.Total = itemsSum
+	default:
+		if _, hasError := analysis["error"]; hasError {
+			return nil, ErrReceiptNotReadable
+		}
+		return nil, ErrReceiptTotalNotFound
+	}
+	return extraction, nil
+}
+
+// MatchExpenseCategoryRule() returns the first rule, by priority, whose pattern is found in the
+// merchant or any of the line item descriptions. Longer patterns win ties as they are more specific.
+func MatchExpenseCategoryRule(rules []*ExpenseCategoryRule, extraction *ReceiptExtraction) *ExpenseCategoryRule {
+	sortedRules := make([]*ExpenseCategoryRule, len(rules))
+	copy(sortedRules, rules)
+	sort.SliceStable(sortedRules, func(i, j int) bool {
+		if sortedRules[i].Priority != sortedRules[j].Priority {
+			return sortedRules[i].Priority > sortedRules[j].Priority
+		}
+		return len(sortedRules[i].Pattern) > len(sortedRules[j].Pattern)
+	})
+	haystacks := []string{strings.ToLower(extraction.Merchant)}
+	for _, item := range extraction.LineItems {
+		haystacks = append(haystacks, strings.ToLower(item.Description))
+	}
+	for _, rule := range sortedRules {
+		pattern := strings.ToLower(strings.TrimSpace(rule.Pattern))
+		if pattern == "" {
+			continue
+		}
+		for _, haystack := range haystacks {
+			if strings.Contains(haystack, pattern) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// SuggestBudgetForExpense() picks the budget a drafted expense most likely belongs to.
+// The budget of a matched rule wins, then a budget with the same category and lastly a
+// budget whose name mentions the category. We return nil if nothing matches.
+func SuggestBudgetForExpense(budgets []*Budget, category string, rule *ExpenseCategoryRule) *BudgetSuggestion {
+	suggest := func(budget *Budget, match string) *BudgetSuggestion {
+		return &BudgetSuggestion{
+			BudgetID:     budget.Id,
+			BudgetName:   budget.Name,
+			CurrencyCode: budget.CurrencyCode,
+			Match:        match,
+		}
+	}
+	if rule != nil && rule.BudgetID > 0 {
+		for _, budget := range budgets {
+			if budget.Id == rule.BudgetID {
+				return suggest(budget, ReceiptBudgetMatchCategoryRule)
+			}
+		}
+	}
+	if category == "" || category == DefaultReceiptDraftCategory {
+		return nil
+	}
+	for _, budget := range budgets {
+		if strings.EqualFold(budget.Category, category) {
+			return suggest(budget, ReceiptBudgetMatchCategory)
+		}
+	}
+	for _, budget := range budgets {
+		if strings.Contains(strings.ToLower(budget.Name), strings.ToLower(category)) {
+			return suggest(budget, ReceiptBudgetMatchName)
+		}
+	}
+	return nil
+}
+
+// BuildReceiptExpenseDraft() applies the user's category rules and budget suggestion to
+// the extracted receipt. The expense amount is the receipt total, converting it to the
+// suggested budget's currency is left to the caller
+func BuildReceiptExpenseDraft(extraction *ReceiptExtraction, rules []*ExpenseCategoryRule, budgets []*Budget, now time.Time) *ReceiptExpenseDraft {
+	rule := MatchExpenseCategoryRule(rules, extraction)
+	category := DefaultReceiptDraftCategory
+	var ruleID int64
+	if rule != nil {
+		category = rule.Category
+		ruleID = rule.ID
+	}
+	suggestion := SuggestBudgetForExpense(budgets, category, rule)
+	// fall back to today for missing or future dates
+	dateOccurred := extraction.DateOccurred
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if dateOccurred.IsZero() || dateOccurred.After(today) {
+		dateOccurred = today
+	}
+	name := extraction.Merchant
+	if name == "" {
+		name = DefaultReceiptDraftName
+	}
+	expense := &Expense{
+		Name:         name,
+		Category:     category,
+		Amount:       extraction.Total,
+		Description:  describeReceiptLineItems(extraction),
+		DateOccurred: dateOccurred,
+	}
+	if suggestion != nil {
+		expense.BudgetID = suggestion.BudgetID
+	}
+	return &ReceiptExpenseDraft{
+		Expense:          expense,
+		Merchant:         extraction.Merchant,
+		OriginalAmount:   extraction.Total,
+		CurrencyCode:     extraction.CurrencyCode,
+		LineItems:        extraction.LineItems,
+		BudgetSuggestion: suggestion,
+		CategoryRuleID:   ruleID,
+		ExpiresAt:        now.Add(DefaultReceiptDraftTTL),
+	}
+}
+
+// describeReceiptLineItems() builds a short description of the purchase, listing at most
+// five items so it stays within the expense description limit
+func describeReceiptLineItems(extraction *ReceiptExtraction) string {
+	var names []string
+	for _, item := range extraction.LineItems {
+		if item.Description != "" {
+			names = append(names, item.Description)
+		}
+	}
+	if len(names) == 0 {
+		if extraction.Merchant == "" {
+			return "Expense created from a receipt"
+		}
+		return fmt.Sprintf("Receipt from %s", extraction.Merchant)
+	}
+	description := strings.Join(names[:min(len(names), 5)], ", ")
+	if len(names) > 5 {
+		description = fmt.Sprintf("%s and %d more items", description, len(names)-5)
+	}
+	if len(description) > 500 {
+		description = description[:497] + "..."
+	}
+	return description
+}
+
+// receiptValue() returns the first non nil value of the given keys
+func receiptValue(analysis map[string]interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		if value, ok := analysis[key]; ok && value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// receiptString() returns the first string value of the given keys
+func receiptString(analysis map[string]interface{}, keys ...string) string {
+	if value, ok := receiptValue(analysis, keys...).(string); ok {
+		return value
+	}
+	return ""
+}
+
+// parseReceiptLineItem() reads a line item that is either an object or just a description
+func parseReceiptLineItem(item interface{}) (ReceiptLineItem, bool) {
+	switch item := item.(type) {
+	case string:
+		if strings.TrimSpace(item) == "" {
+			return ReceiptLineItem{}, false
+		}
+		return ReceiptLineItem{Description: strings.TrimSpace(item), Quantity: decimal.NewFromInt(1)}, true
+	case map[string]interface{}:
+		lineItem := ReceiptLineItem{
+			Description: strings.TrimSpace(receiptString(item, "description", "name", "item")),
+			Quantity:    decimal.NewFromInt(1),
+		}
+		if quantity, _, ok := parseReceiptAmount(receiptValue(item, "quantity", "qty")); ok && quantity.IsPositive() {
+			lineItem.Quantity = quantity
+		}
+		if amount, _, ok := parseReceiptAmount(receiptValue(item, "amount", "total", "price")); ok {
+			lineItem.Amount = amount
+		}
+		if lineItem.Description == "" && lineItem.Amount.IsZero() {
+			return ReceiptLineItem{}, false
+		}
+		return lineItem, true
+	default:
+		return ReceiptLineItem{}, false
+	}
+}
+
+// parseReceiptAmount() reads an amount that is either a JSON number or a string such as
+// "$1,234.50", "12,50 €" or "KES 300". We also return the currency of a known symbol.
+func parseReceiptAmount(value interface{}) (decimal.Decimal, string, bool) {
+	switch value := value.(type) {
+	case float64:
+		return decimal.NewFromFloat(value).Round(2), "", true
+	case string:
+		currency := ""
+		for symbol, code := range receiptCurrencySymbols {
+			if strings.Contains(value, symbol) {
+				currency = code
+				break
+			}
+		}
+		// keep only the digits and separators
+		cleaned := strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) || r == '.' || r == ',' || r == '-' {
+				return r
+			}
+			return -1
+		}, value)
+		lastComma := strings.LastIndex(cleaned, ",")
+		lastDot := strings.LastIndex(cleaned, ".")
+		switch {
+		case lastComma > lastDot && len(cleaned)-lastComma-1 == 2:
+			// a decimal comma i.e 1.234,50
+			cleaned = strings.ReplaceAll(cleaned, ".", "")
+			cleaned = strings.Replace(cleaned, ",", ".", 1)
+		default:
+			cleaned = strings.ReplaceAll(cleaned, ",", "")
+		}
+		amount, err := decimal.NewFromString(cleaned)
+		if err != nil {
+			return decimal.Zero, "", false
+		}
+		return amount.Round(2), currency, true
+	default:
+		return decimal.Zero, "", false
+	}
+}
+
+// isCurrencyCode() checks for a 3 letter ISO 4217 style code
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateNewExpenseCategoryRule() saves a new category rule for the user
+// We return ErrDuplicateExpenseCategoryRule if the user already has a rule with the same pattern
+func (m ReceiptManagerModel) CreateNewExpenseCategoryRule(userID int64, rule *ExpenseCategoryRule) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultReceiptDBContextTimeout)
+	defer cancel()
+	// save the rule
+	createdRule, err := m.DB.CreateNewExpenseCategoryRule(ctx, database.CreateNewExpenseCategoryRuleParams{
+		UserID:   userID,
+		Pattern:  rule.Pattern,
+		Category: rule.Category,
+		BudgetID: sql.NullInt64{Int64: rule.BudgetID, Valid: rule.BudgetID > 0},
+		Priority: rule.Priority,
+	})
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_expense_category_rule_pattern"`:
+			return ErrDuplicateExpenseCategoryRule
+		default:
+			return err
+		}
+	}
+	// fill in the generated values
+	rule.ID = createdRule.ID
+	rule.UserID = userID
+	rule.CreatedAt = createdRule.CreatedAt.Time
+	rule.UpdatedAt = createdRule.UpdatedAt.Time
+	return nil
+}
+
+// GetExpenseCategoryRulesByUserID() returns all of the user's category rules, highest priority first
+func (m ReceiptManagerModel) GetExpenseCategoryRulesByUserID(userID int64) ([]*ExpenseCategoryRule, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultReceiptDBContextTimeout)
+	defer cancel()
+	// get the rules
+	rules, err := m.DB.GetExpenseCategoryRulesByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	populatedRules := []*ExpenseCategoryRule{}
+	for _, rule := range rules {
+		populatedRules = append(populatedRules, populateExpenseCategoryRule(rule))
+	}
+	return populatedRules, nil
+}
+
+// DeleteExpenseCategoryRuleByID() deletes one of the user's category rules
+func (m ReceiptManagerModel) DeleteExpenseCategoryRuleByID(userID, ruleID int64) (int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultReceiptDBContextTimeout)
+	defer cancel()
+	// delete the rule
+	deletedRuleID, err := m.DB.DeleteExpenseCategoryRuleByID(ctx, database.DeleteExpenseCategoryRuleByIDParams{
+		ID:     ruleID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrGeneralRecordNotFound
+		default:
+			return 0, err
+		}
+	}
+	return deletedRuleID, nil
+}
+
+// CreateNewReceiptDraft() saves a receipt draft, the receipt must already be in storage
+func (m ReceiptManagerModel) CreateNewReceiptDraft(userID int64, draft *ReceiptExpenseDraft) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultReceiptDBContextTimeout)
+	defer cancel()
+	// marshal the line items
+	lineItems, err := json.Marshal(draft.LineItems)
+	if err != nil {
+		return err
+	}
+	budgetMatch := ""
+	if draft.BudgetSuggestion != nil {
+		budgetMatch = draft.BudgetSuggestion.Match
+	}
+	// save the draft
+	createdDraft, err := m.DB.CreateNewReceiptDraft(ctx, database.CreateNewReceiptDraftParams{
+		UserID:         userID,
+		BudgetID:       sql.NullInt64{Int64: draft.Expense.BudgetID, Valid: draft.Expense.BudgetID > 0},
+		BudgetMatch:    budgetMatch,
+		CategoryRuleID: sql.NullInt64{Int64: draft.CategoryRuleID, Valid: draft.CategoryRuleID > 0},
+		Merchant:       draft.Merchant,
+		Category:       draft.Expense.Category,
+		Amount:         draft.Expense.Amount.String(),
+		OriginalAmount: draft.OriginalAmount.String(),
+		CurrencyCode:   draft.CurrencyCode,
+		Description:    draft.Expense.Description,
+		DateOccurred:   draft.Expense.DateOccurred,
+		LineItems:      lineItems,
+		StorageKey:     draft.Receipt.StorageKey,
+		FileName:       draft.Receipt.FileName,
+		ContentType:    draft.Receipt.ContentType,
+		SizeBytes:      draft.Receipt.SizeBytes,
+		ExpiresAt:      draft.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	// fill in the generated values
+	draft.ID = createdDraft.ID
+	draft.UserID = userID
+	draft.Expense.UserID = userID
+	draft.CreatedAt = createdDraft.CreatedAt.Time
+	return nil
+}
+
+// GetReceiptDraftByID() returns one of the user's drafts that has not expired yet
+func (m ReceiptManagerModel) GetReceiptDraftByID(userID, draftID int64) (*ReceiptExpenseDraft, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultReceiptDBContextTimeout)
+	defer cancel()
+	// get the draft
+	draft, err := m.DB.GetReceiptDraftByID(ctx, database.GetReceiptDraftByIDParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return populateReceiptExpenseDraft(draft)
+}
+
+// DeleteReceiptDraftByID() deletes one of the user's drafts and returns the storage key of its
+// receipt. This is used both to discard a draft and to claim it while it is being confirmed
+func (m ReceiptManagerModel) DeleteReceiptDraftByID(userID, draftID int64) (string, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultReceiptDBContextTimeout)
+	defer cancel()
+	// delete the draft
+	storageKey, err := m.DB.DeleteReceiptDraftByID(ctx, database.DeleteReceiptDraftByIDParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return "", ErrGeneralRecordNotFound
+		default:
+			return "", err
+		}
+	}
+	return storageKey, nil
+}
+
+// ConfirmReceiptDraft() turns one of the user's drafts into an expense in one transaction: the expense
+// is created, the receipt is attached to it and the draft is deleted last. Deleting the draft claims it,
+// so if the same draft is confirmed twice the second confirmation finds no draft and saves nothing.
+// We return ErrGeneralRecordNotFound if the draft no longer exists.
+func (m ReceiptManagerModel) ConfirmReceiptDraft(userID, draftID int64, expense *Expense, attachment *ExpenseAttachment) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultReceiptDBContextTimeout)
+	defer cancel()
+	return withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		err := (&FinancialTrackingModel{DB: q}).CreateNewExpense(userID, expense)
+		if err != nil {
+			return err
+		}
+		attachment.ExpenseID = expense.ID
+		err = AttachmentManagerModel{DB: q}.CreateNewExpenseAttachment(userID, attachment)
+		if err != nil {
+			return err
+		}
+		_, err = ReceiptManagerModel{DB: q}.DeleteReceiptDraftByID(userID, draftID)
+		return err
+	})
+}
+
+// DeleteExpiredReceiptDrafts() deletes all expired drafts and returns the storage keys of their receipts
+func (m ReceiptManagerModel) DeleteExpiredReceiptDrafts() ([]string, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultReceiptDBContextTimeout)
+	defer cancel()
+	// delete the drafts
+	storageKeys, err := m.DB.DeleteExpiredReceiptDrafts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return storageKeys, nil
+}
+
+// populateExpenseCategoryRule() maps a database category rule to an ExpenseCategoryRule
+func populateExpenseCategoryRule(ruleRow interface{}) *ExpenseCategoryRule {
+	switch rule := ruleRow.(type) {
+	case database.ExpenseCategoryRule:
+		return &ExpenseCategoryRule{
+			ID:        rule.ID,
+			UserID:    rule.UserID,
+			Pattern:   rule.Pattern,
+			Category:  rule.Category,
+			BudgetID:  rule.BudgetID.Int64,
+			Priority:  rule.Priority,
+			CreatedAt: rule.CreatedAt.Time,
+			UpdatedAt: rule.UpdatedAt.Time,
+		}
+	default:
+		return nil
+	}
+}
+
+// populateReceiptExpenseDraft() maps a database draft to a ReceiptExpenseDraft
+func populateReceiptExpenseDraft(draftRow interface{}) (*ReceiptExpenseDraft, error) {
+	switch draft := draftRow.(type) {
+	case database.ExpenseReceiptDraft:
+		lineItems := []ReceiptLineItem{}
+		err := json.Unmarshal(draft.LineItems, &lineItems)
+		if err != nil {
+			return nil, err
+		}
+		name := draft.Merchant
+		if name == "" {
+			name = DefaultReceiptDraftName
+		}
+		populatedDraft := &ReceiptExpenseDraft{
+			ID:     draft.ID,
+			UserID: draft.UserID,
+			Expense: &Expense{
+				UserID:       draft.UserID,
+				BudgetID:     draft.BudgetID.Int64,
+				Name:         name,
+				Category:     draft.Category,
+				Amount:       decimal.RequireFromString(draft.Amount),
+				Description:  draft.Description,
+				DateOccurred: draft.DateOccurred,
+			},
+			Merchant:       draft.Merchant,
+			OriginalAmount: decimal.RequireFromString(draft.OriginalAmount),
+			CurrencyCode:   draft.CurrencyCode,
+			LineItems:      lineItems,
+			CategoryRuleID: draft.CategoryRuleID.Int64,
+			Receipt: &ExpenseAttachment{
+				UserID:      draft.UserID,
+				StorageKey:  draft.StorageKey,
+				FileName:    draft.FileName,
+				ContentType: draft.ContentType,
+				SizeBytes:   draft.SizeBytes,
+				CreatedAt:   draft.CreatedAt.Time,
+			},
+			ExpiresAt: draft.ExpiresAt,
+			CreatedAt: draft.CreatedAt.Time,
+		}
+		// the budget name is filled in by the caller as it is not stored with the draft
+		if draft.BudgetID.Valid {
+			populatedDraft.BudgetSuggestion = &BudgetSuggestion{
+				BudgetID: draft.BudgetID.Int64,
+				Match:    draft.BudgetMatch,
+			}
+		}
+		return populatedDraft, nil
+	default:
+		return nil, ErrTypeConversionError
+	}
+}
@@ -0,0 +1,309 @@
+package data
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestParseReceiptAnalysis(t *testing.T) {
+	tests := []struct {
+		name         string
+		analysis     map[string]interface{}
+		wantErr      error
+		wantMerchant string
+		wantDate     time.Time
+		wantTotal    string
+		wantCurrency string
+		wantItems    int
+	}{
+		{
+			name: "Draft prompt keys",
+			analysis: map[string]interface{}{
+				"merchant": " Carrefour ",
+				"date":     "2024-05-01",
+				"total":    23.5,
+				"currency": "kes",
+				"line_items": []interface{}{
+					map[string]interface{}{"description": "Milk", "quantity": 2.0, "amount": 3.5},
+					map[string]interface{}{"description": "Bread", "amount": "20"},
+				},
+			},
+			wantMerchant: "Carrefour",
+			wantDate:     time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+			wantTotal:    "23.5",
+			wantCurrency: "KES",
+			wantItems:    2,
+		},
+		{
+			name: "Legacy prompt keys with decimal comma and symbol",
+			analysis: map[string]interface{}{
+				"store_name":         "Lidl",
+				"date_of_purchase":   "01.05.2024",
+				"total_amount_spent": "1.234,50 €",
+				"items_purchased":    []interface{}{"Coffee machine"},
+			},
+			wantMerchant: "Lidl",
+			wantDate:     time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+			wantTotal:    "1234.5",
+			wantCurrency: "EUR",
+			wantItems:    1,
+		},
+		{
+			name: "Total falls back to the line items",
+			analysis: map[string]interface{}{
+				"merchant": "Kiosk",
+				"line_items": []interface{}{
+					map[string]interface{}{"name": "Water", "price": 1.25},
+					map[string]interface{}{"name": "Gum", "price": "$0.75"},
+					"",
+				},
+			},
+			wantMerchant: "Kiosk",
+			wantTotal:    "2",
+			wantItems:    2,
+		},
+		{
+			name:     "Empty analysis",
+			analysis: map[string]interface{}{},
+			wantErr:  ErrReceiptNotReadable,
+		},
+		{
+			name:     "LLM reported an error",
+			analysis: map[string]interface{}{"error": "not a receipt"},
+			wantErr:  ErrReceiptNotReadable,
+		},
+		{
+			name:     "No total",
+			analysis: map[string]interface{}{"merchant": "Kiosk", "total": "n/a"},
+			wantErr:  ErrReceiptTotalNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReceiptAnalysis(tt.analysis)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseReceiptAnalysis() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.Merchant != tt.wantMerchant {
+				t.Errorf("Merchant = %q, want %q", got.Merchant, tt.wantMerchant)
+			}
+			if !got.DateOccurred.Equal(tt.wantDate) {
+				t.Errorf("DateOccurred = %v, want %v", got.DateOccurred, tt.wantDate)
+			}
+			if !got.Total.Equal(decimal.RequireFromString(tt.wantTotal)) {
+				t.Errorf("Total = %s, want %s", got.Total, tt.wantTotal)
+			}
+			if got.CurrencyCode != tt.wantCurrency {
+				t.Errorf("CurrencyCode = %q, want %q", got.CurrencyCode, tt.wantCurrency)
+			}
+			if len(got.LineItems) != tt.wantItems {
+				t.Errorf("len(LineItems) = %d, want %d", len(got.LineItems), tt.wantItems)
+			}
+		})
+	}
+}
+
+func TestMatchExpenseCategoryRule(t *testing.T) {
+	rules := []*ExpenseCategoryRule{
+		{ID: 1, Pattern: "shell", Category: "fuel", Priority: 0},
+		{ID: 2, Pattern: "coffee", Category: "dining", Priority: 5},
+		{ID: 3, Pattern: "shell station", Category: "transport", Priority: 0},
+	}
+	tests := []struct {
+		name       string
+		extraction *ReceiptExtraction
+		wantRuleID int64
+	}{
+		{
+			name:       "Higher priority wins",
+			extraction: &ReceiptExtraction{Merchant: "Shell Station", LineItems: []ReceiptLineItem{{Description: "Coffee"}}},
+			wantRuleID: 2,
+		},
+		{
+			name:       "Longer pattern wins a tie",
+			extraction: &ReceiptExtraction{Merchant: "SHELL STATION Westlands"},
+			wantRuleID: 3,
+		},
+		{
+			name:       "Match on a line item",
+			extraction: &ReceiptExtraction{Merchant: "Java House", LineItems: []ReceiptLineItem{{Description: "Iced coffee"}}},
+			wantRuleID: 2,
+		},
+		{
+			name:       "No match",
+			extraction: &ReceiptExtraction{Merchant: "Naivas"},
+			wantRuleID: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchExpenseCategoryRule(rules, tt.extraction)
+			var gotID int64
+			if got != nil {
+				gotID = got.ID
+			}
+			if gotID != tt.wantRuleID {
+				t.Errorf("MatchExpenseCategoryRule() = rule %d, want rule %d", gotID, tt.wantRuleID)
+			}
+		})
+	}
+}
+
+func TestSuggestBudgetForExpense(t *testing.T) {
+	budgets := []*Budget{
+		{Id: 1, Name: "Monthly groceries", Category: "food", CurrencyCode: "KES"},
+		{Id: 2, Name: "Fuel and transport", Category: "car", CurrencyCode: "USD"},
+		{Id: 3, Name: "Eating out", Category: "dining", CurrencyCode: "EUR"},
+	}
+	tests := []struct {
+		name         string
+		category     string
+		rule         *ExpenseCategoryRule
+		wantBudgetID int64
+		wantMatch    string
+	}{
+		{
+			name:         "Rule budget",
+			category:     "dining",
+			rule:         &ExpenseCategoryRule{BudgetID: 1},
+			wantBudgetID: 1,
+			wantMatch:    ReceiptBudgetMatchCategoryRule,
+		},
+		{
+			name:         "Unknown rule budget falls back to the category",
+			category:     "Dining",
+			rule:         &ExpenseCategoryRule{BudgetID: 99},
+			wantBudgetID: 3,
+			wantMatch:    ReceiptBudgetMatchCategory,
+		},
+		{
+			name:         "Budget name",
+			category:     "transport",
+			wantBudgetID: 2,
+			wantMatch:    ReceiptBudgetMatchName,
+		},
+		{
+			name:     "Uncategorized",
+			category: DefaultReceiptDraftCategory,
+		},
+		{
+			name:     "No match",
+			category: "rent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SuggestBudgetForExpense(budgets, tt.category, tt.rule)
+			if tt.wantBudgetID == 0 {
+				if got != nil {
+					t.Errorf("SuggestBudgetForExpense() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("SuggestBudgetForExpense() = nil, want budget %d", tt.wantBudgetID)
+			}
+			if got.BudgetID != tt.wantBudgetID || got.Match != tt.wantMatch {
+				t.Errorf("SuggestBudgetForExpense() = budget %d (%s), want budget %d (%s)", got.BudgetID, got.Match, tt.wantBudgetID, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestBuildReceiptExpenseDraft(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 15, 30, 0, 0, time.UTC)
+	today := time.Date(2024, time.May, 10, 0, 0, 0, 0, time.UTC)
+	rules := []*ExpenseCategoryRule{{ID: 7, Pattern: "carrefour", Category: "food"}}
+	budgets := []*Budget{{Id: 4, Name: "Groceries", Category: "food", CurrencyCode: "KES"}}
+	tests := []struct {
+		name            string
+		extraction      *ReceiptExtraction
+		wantName        string
+		wantCategory    string
+		wantBudgetID    int64
+		wantRuleID      int64
+		wantDate        time.Time
+		wantDescription string
+	}{
+		{
+			name: "Rule and budget applied",
+			extraction: &ReceiptExtraction{
+				Merchant:     "Carrefour",
+				DateOccurred: time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+				Total:        decimal.NewFromInt(30),
+				LineItems:    []ReceiptLineItem{{Description: "Milk"}, {Description: "Bread"}},
+			},
+			wantName:        "Carrefour",
+			wantCategory:    "food",
+			wantBudgetID:    4,
+			wantRuleID:      7,
+			wantDate:        time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC),
+			wantDescription: "Milk, Bread",
+		},
+		{
+			name: "Future date falls back to today",
+			extraction: &ReceiptExtraction{
+				Merchant:     "Kiosk",
+				DateOccurred: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC),
+				Total:        decimal.NewFromInt(5),
+			},
+			wantName:        "Kiosk",
+			wantCategory:    DefaultReceiptDraftCategory,
+			wantDate:        today,
+			wantDescription: "Receipt from Kiosk",
+		},
+		{
+			name: "Missing merchant and date",
+			extraction: &ReceiptExtraction{
+				Total: decimal.NewFromInt(5),
+				LineItems: []ReceiptLineItem{
+					{Description: "A"}, {Description: "B"}, {Description: "C"},
+					{Description: "D"}, {Description: "E"}, {Description: "F"},
+				},
+			},
+			wantName:        DefaultReceiptDraftName,
+			wantCategory:    DefaultReceiptDraftCategory,
+			wantDate:        today,
+			wantDescription: "A, B, C, D, E and 1 more items",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildReceiptExpenseDraft(tt.extraction, rules, budgets, now)
+			if got.Expense.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", got.Expense.Name, tt.wantName)
+			}
+			if got.Expense.Category != tt.wantCategory {
+				t.Errorf("Category = %q, want %q", got.Expense.Category, tt.wantCategory)
+			}
+			if got.Expense.BudgetID != tt.wantBudgetID {
+				t.Errorf("BudgetID = %d, want %d", got.Expense.BudgetID, tt.wantBudgetID)
+			}
+			if got.CategoryRuleID != tt.wantRuleID {
+				t.Errorf("CategoryRuleID = %d, want %d", got.CategoryRuleID, tt.wantRuleID)
+			}
+			if !got.Expense.DateOccurred.Equal(tt.wantDate) {
+				t.Errorf("DateOccurred = %v, want %v", got.Expense.DateOccurred, tt.wantDate)
+			}
+			if got.Expense.Description != tt.wantDescription {
+				t.Errorf("Description = %q, want %q", got.Expense.Description, tt.wantDescription)
+			}
+			if !got.Expense.Amount.Equal(tt.extraction.Total) || !got.OriginalAmount.Equal(tt.extraction.Total) {
+				t.Errorf("Amount = %s, OriginalAmount = %s, want %s", got.Expense.Amount, got.OriginalAmount, tt.extraction.Total)
+			}
+			if !got.ExpiresAt.Equal(now.Add(DefaultReceiptDraftTTL)) {
+				t.Errorf("ExpiresAt = %v, want %v", got.ExpiresAt, now.Add(DefaultReceiptDraftTTL))
+			}
+		})
+	}
+}
@@ -0,0 +1,444 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type SubscriptionManagerModel struct {
+	DB   *database.Queries
+	Conn *sql.DB
+}
+
+// The statuses of a detected subscription
+const (
+	SubscriptionStatusActive    = database.SubscriptionStatusEnumActive
+	SubscriptionStatusConverted = database.SubscriptionStatusEnumConverted
+	SubscriptionStatusDismissed = database.SubscriptionStatusEnumDismissed
+)
+
+var (
+	DefaultSubscriptionDBContextTimeout = 5 * time.Second
+	// SubscriptionLookbackPeriod is how far back expenses are scanned, long enough to spot yearly charges
+	SubscriptionLookbackPeriod = 2 * 365 * 24 * time.Hour
+	// SubscriptionAmountTolerance is how much two consecutive charges may differ (25%) and still be
+	// considered the same subscription, it leaves room for price changes
+	SubscriptionAmountTolerance = decimal.NewFromFloat(0.25)
+)
+
+var (
+	ErrSubscriptionNotActive = errors.New("subscription has already been converted or dismissed")
+)
+
+// subscriptionPattern is a billing cycle a subscription can follow. Charges match the pattern
+// when they are days apart, give or take the tolerance
+type subscriptionPattern struct {
+	frequency     database.RecurrenceIntervalEnum
+	intervalCount int32
+	days          int
+	tolerance     int
+	minCharges    int
+}
+
+// subscriptionPatterns are the billing cycles we look for, yearly charges need fewer repetitions
+// as there are rarely more than two in the scanned history
+var subscriptionPatterns = []subscriptionPattern{
+	{frequency: database.RecurrenceIntervalEnumWeekly, intervalCount: 1, days: 7, tolerance: 1, minCharges: 3},
+	{frequency: database.RecurrenceIntervalEnumWeekly, intervalCount: 2, days: 14, tolerance: 2, minCharges: 3},
+	{frequency: database.RecurrenceIntervalEnumMonthly, intervalCount: 1, days: 30, tolerance: 4, minCharges: 3},
+	{frequency: database.RecurrenceIntervalEnumMonthly, intervalCount: 3, days: 91, tolerance: 7, minCharges: 3},
+	{frequency: database.RecurrenceIntervalEnumYearly, intervalCount: 1, days: 365, tolerance: 10, minCharges: 2},
+}
+
+// SubscriptionCharge is a one-off expense that may be part of a subscription
+type SubscriptionCharge struct {
+	ExpenseID    int64
+	BudgetID     int64
+	Name         string
+	Amount       decimal.Decimal
+	DateOccurred time.Time
+}
+
+// DetectedSubscription is a repeating charge found in a user's expenses. Amount is the latest
+// charge while PreviousAmount is the amount before the latest price change, zero when unchanged
+type DetectedSubscription struct {
+	ID                 int64                           `json:"id"`
+	UserID             int64                           `json:"user_id"`
+	BudgetID           int64                           `json:"budget_id"`
+	MerchantKey        string                          `json:"merchant_key"`
+	Name               string                          `json:"name"`
+	Amount             decimal.Decimal                 `json:"amount"`
+	PreviousAmount     decimal.Decimal                 `json:"previous_amount"`
+	RecurrenceInterval database.RecurrenceIntervalEnum `json:"recurrence_interval"`
+	IntervalCount      int32                           `json:"interval_count"`
+	ChargeCount        int32                           `json:"charge_count"`
+	FirstCharged       time.Time                       `json:"first_charged"`
+	LastCharged        time.Time                       `json:"last_charged"`
+	NextExpected       time.Time                       `json:"next_expected"`
+	Status             database.SubscriptionStatusEnum `json:"status"`
+	RecurringExpenseID int64                           `json:"recurring_expense_id,omitempty"`
+	CreatedAt          time.Time                       `json:"created_at"`
+	UpdatedAt          time.Time                       `json:"updated_at"`
+}
+
+// ValidateSubscriptionStatus() validates the status filter of the subscription list, empty means all
+func ValidateSubscriptionStatus(v *validator.Validator, status string) {
+	v.Check(validator.PermittedValue(status, "", string(SubscriptionStatusActive), string(SubscriptionStatusConverted), string(SubscriptionStatusDismissed)),
+		"status", "must be either active, converted or dismissed")
+}
+
+// SubscriptionMerchantKey() normalizes an expense name so that charges from the same merchant
+// group together, i.e "NETFLIX.COM #1234" and "Netflix.com" both become "netflix com"
+func SubscriptionMerchantKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}), " ")
+}
+
+// DetectSubscriptions() finds the subscriptions in a user's one-off charges. Charges are grouped by
+// merchant and a group is a subscription when its latest charges repeat on a regular billing cycle
+// with similar amounts. Merchants that already have a recurring expense are left out, as are
+// subscriptions that have missed two billing cycles and are most likely cancelled
+func DetectSubscriptions(charges []*SubscriptionCharge, recurringExpenseNames []string, now time.Time) []*DetectedSubscription {
+	registered := make(map[string]bool, len(recurringExpenseNames))
+	for _, name := range recurringExpenseNames {
+		registered[SubscriptionMerchantKey(name)] = true
+	}
+	groups := make(map[string][]*SubscriptionCharge)
+	keys := []string{}
+	for _, charge := range charges {
+		key := SubscriptionMerchantKey(charge.Name)
+		if key == "" || registered[key] {
+			continue
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], charge)
+	}
+	subscriptions := []*DetectedSubscription{}
+	for _, key := range keys {
+		if subscription := detectSubscription(key, groups[key], now); subscription != nil {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].NextExpected.Before(subscriptions[j].NextExpected)
+	})
+	return subscriptions
+}
+
+// detectSubscription() checks whether the charges of a single merchant form a subscription.
+// The billing cycle is taken from the last two charges and the run of charges is followed
+// backwards for as long as they keep to the cycle
+func detectSubscription(key string, charges []*SubscriptionCharge, now time.Time) *DetectedSubscription {
+	sort.SliceStable(charges, func(i, j int) bool {
+		return charges[i].DateOccurred.Before(charges[j].DateOccurred)
+	})
+	if len(charges) < 2 {
+		return nil
+	}
+	latest := charges[len(charges)-1]
+	run := []*SubscriptionCharge{latest}
+	var pattern *subscriptionPattern
+	for i := len(charges) - 2; i >= 0; i-- {
+		earliest := run[0]
+		gap := daysBetween(charges[i].DateOccurred, earliest.DateOccurred)
+		// several purchases on the same day are not part of a billing cycle
+		if gap == 0 {
+			continue
+		}
+		if pattern == nil {
+			pattern = matchSubscriptionPattern(gap)
+			if pattern == nil {
+				return nil
+			}
+		}
+		if absDays(gap-pattern.days) > pattern.tolerance || !similarSubscriptionAmounts(charges[i].Amount, earliest.Amount) {
+			break
+		}
+		run = append([]*SubscriptionCharge{charges[i]}, run...)
+	}
+	if pattern == nil || len(run) < pattern.minCharges {
+		return nil
+	}
+	// two missed billing cycles means the subscription was most likely cancelled
+	if daysBetween(latest.DateOccurred, now) > 2*pattern.days+pattern.tolerance {
+		return nil
+	}
+	subscription := &DetectedSubscription{
+		BudgetID:           latest.BudgetID,
+		MerchantKey:        key,
+		Name:               latest.Name,
+		Amount:             latest.Amount,
+		RecurrenceInterval: pattern.frequency,
+		IntervalCount:      pattern.intervalCount,
+		ChargeCount:        int32(len(run)),
+		FirstCharged:       dateOnly(run[0].DateOccurred),
+		LastCharged:        dateOnly(latest.DateOccurred),
+		Status:             SubscriptionStatusActive,
+	}
+	for i := len(run) - 2; i >= 0; i-- {
+		if !run[i].Amount.Equal(latest.Amount) {
+			subscription.PreviousAmount = run[i].Amount
+			break
+		}
+	}
+	subscription.NextExpected = calculateRecurrence(subscription.LastCharged, pattern.frequency, pattern.intervalCount, subscription.dayOfMonth())
+	return subscription
+}
+
+// matchSubscriptionPattern() returns the billing cycle a gap between two charges fits, if any
+func matchSubscriptionPattern(gap int) *subscriptionPattern {
+	for i := range subscriptionPatterns {
+		if absDays(gap-subscriptionPatterns[i].days) <= subscriptionPatterns[i].tolerance {
+			return &subscriptionPatterns[i]
+		}
+	}
+	return nil
+}
+
+// similarSubscriptionAmounts() checks that two charges differ by no more than SubscriptionAmountTolerance
+func similarSubscriptionAmounts(a, b decimal.Decimal) bool {
+	return a.Sub(b).Abs().LessThanOrEqual(decimal.Max(a, b).Mul(SubscriptionAmountTolerance))
+}
+
+// daysBetween() returns the number of whole days from one date to another
+func daysBetween(from, to time.Time) int {
+	return int(dateOnly(to).Sub(dateOnly(from)).Hours() / 24)
+}
+
+// absDays() returns the absolute difference in days
+func absDays(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// dayOfMonth() returns the day monthly and yearly subscriptions are charged on, 0 for the others
+func (s *DetectedSubscription) dayOfMonth() int32 {
+	if s.RecurrenceInterval == database.RecurrenceIntervalEnumMonthly || s.RecurrenceInterval == database.RecurrenceIntervalEnumYearly {
+		return int32(s.LastCharged.Day())
+	}
+	return 0
+}
+
+// PriceIncreaseSince() returns how much the subscription went up since it was last detected.
+// Only active subscriptions are reported, converted and dismissed ones are no longer followed
+func (s *DetectedSubscription) PriceIncreaseSince(previous *DetectedSubscription) (decimal.Decimal, bool) {
+	if previous == nil || previous.Status != SubscriptionStatusActive || !s.Amount.GreaterThan(previous.Amount) {
+		return decimal.Zero, false
+	}
+	return s.Amount.Sub(previous.Amount), true
+}
+
+// ToRecurringExpense() creates the recurring expense a subscription converts into. The schedule is
+// anchored to the latest charge, which is already recorded, and moved to the first charge from today on
+func (s *DetectedSubscription) ToRecurringExpense(now time.Time) *RecurringExpense {
+	recurringExpense := &RecurringExpense{
+		BudgetID:                s.BudgetID,
+		Amount:                  s.Amount,
+		Name:                    s.Name,
+		Description:             "Detected subscription",
+		RecurrenceInterval:      s.RecurrenceInterval,
+		IntervalCount:           s.IntervalCount,
+		DayOfMonth:              s.dayOfMonth(),
+		FirstOccurrenceRecorded: true,
+	}
+	recurringExpense.InitializeSchedule(s.LastCharged, dateOnly(now))
+	recurringExpense.FastForward(dateOnly(now))
+	recurringExpense.ProjectedAmount = recurringExpense.CalculateTotalAmountPerMonth()
+	return recurringExpense
+}
+
+// GetSubscriptionChargesByUserID() returns the user's one-off expenses since a date, oldest first.
+// Expenses posted by recurring expenses are left out
+func (m SubscriptionManagerModel) GetSubscriptionChargesByUserID(userID int64, since time.Time) ([]*SubscriptionCharge, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultSubscriptionDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetSubscriptionChargesByUserID(ctx, database.GetSubscriptionChargesByUserIDParams{
+		UserID:  userID,
+		Column2: since,
+	})
+	if err != nil {
+		return nil, err
+	}
+	charges := make([]*SubscriptionCharge, len(rows))
+	for i, row := range rows {
+		charges[i] = &SubscriptionCharge{
+			ExpenseID:    row.ID,
+			BudgetID:     row.BudgetID,
+			Name:         row.Name,
+			Amount:       decimal.RequireFromString(row.Amount),
+			DateOccurred: row.DateOccurred,
+		}
+	}
+	return charges, nil
+}
+
+// GetRecurringExpenseNamesByUserID() returns the names of all the user's recurring expenses
+func (m SubscriptionManagerModel) GetRecurringExpenseNamesByUserID(userID int64) ([]string, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultSubscriptionDBContextTimeout)
+	defer cancel()
+	return m.DB.GetRecurringExpenseNamesByUserID(ctx, userID)
+}
+
+// GetUserIDsWithExpensesSince() returns the users that recorded one-off expenses since a time,
+// they are the only ones whose subscriptions may have changed
+func (m SubscriptionManagerModel) GetUserIDsWithExpensesSince(since time.Time) ([]int64, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultSubscriptionDBContextTimeout)
+	defer cancel()
+	return m.DB.GetUserIDsWithExpensesSince(ctx, since)
+}
+
+// UpsertDetectedSubscription() saves a detection, updating the existing detection of the same merchant.
+// The status of an existing detection is kept
+func (m SubscriptionManagerModel) UpsertDetectedSubscription(userID int64, subscription *DetectedSubscription) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultSubscriptionDBContextTimeout)
+	defer cancel()
+	row, err := m.DB.UpsertDetectedSubscription(ctx, database.UpsertDetectedSubscriptionParams{
+		UserID:             userID,
+		BudgetID:           subscription.BudgetID,
+		MerchantKey:        subscription.MerchantKey,
+		Name:               subscription.Name,
+		Amount:             subscription.Amount.String(),
+		PreviousAmount:     sql.NullString{String: subscription.PreviousAmount.String(), Valid: !subscription.PreviousAmount.IsZero()},
+		RecurrenceInterval: subscription.RecurrenceInterval,
+		IntervalCount:      subscription.IntervalCount,
+		ChargeCount:        subscription.ChargeCount,
+		FirstCharged:       subscription.FirstCharged,
+		LastCharged:        subscription.LastCharged,
+		NextExpected:       subscription.NextExpected,
+	})
+	if err != nil {
+		return err
+	}
+	*subscription = *populateDetectedSubscription(row)
+	return nil
+}
+
+// GetDetectedSubscriptionsByUserID() returns the user's detected subscriptions, optionally of a single status
+func (m SubscriptionManagerModel) GetDetectedSubscriptionsByUserID(userID int64, status string) ([]*DetectedSubscription, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultSubscriptionDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetDetectedSubscriptionsByUserID(ctx, database.GetDetectedSubscriptionsByUserIDParams{
+		UserID:  userID,
+		Column2: status,
+	})
+	if err != nil {
+		return nil, err
+	}
+	subscriptions := make([]*DetectedSubscription, len(rows))
+	for i, row := range rows {
+		subscriptions[i] = populateDetectedSubscription(row)
+	}
+	return subscriptions, nil
+}
+
+// GetDetectedSubscriptionByID() returns a single detected subscription of the user
+func (m SubscriptionManagerModel) GetDetectedSubscriptionByID(userID, subscriptionID int64) (*DetectedSubscription, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultSubscriptionDBContextTimeout)
+	defer cancel()
+	row, err := m.DB.GetDetectedSubscriptionByID(ctx, database.GetDetectedSubscriptionByIDParams{
+		ID:     subscriptionID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrGeneralRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+	return populateDetectedSubscription(row), nil
+}
+
+// UpdateDetectedSubscriptionStatus() saves the status and recurring expense of a detected subscription
+func (m SubscriptionManagerModel) UpdateDetectedSubscriptionStatus(userID int64, subscription *DetectedSubscription) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultSubscriptionDBContextTimeout)
+	defer cancel()
+	row, err := m.DB.UpdateDetectedSubscriptionStatus(ctx, database.UpdateDetectedSubscriptionStatusParams{
+		ID:                 subscription.ID,
+		UserID:             userID,
+		Status:             subscription.Status,
+		RecurringExpenseID: sql.NullInt64{Int64: subscription.RecurringExpenseID, Valid: subscription.RecurringExpenseID != 0},
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	*subscription = *populateDetectedSubscription(row)
+	return nil
+}
+
+// ConvertDetectedSubscription() converts a detected subscription into the recurring expense in one
+// transaction: the recurring expense is created and the subscription is marked as converted to it,
+// so a failed conversion neither leaves a stray recurring expense nor a subscription to convert again
+func (m SubscriptionManagerModel) ConvertDetectedSubscription(userID int64, subscription *DetectedSubscription, recurringExpense *RecurringExpense) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultSubscriptionDBContextTimeout)
+	defer cancel()
+	converted := *subscription
+	err := withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		err := (&FinancialTrackingModel{DB: q}).CreateNewRecurringExpense(userID, recurringExpense)
+		if err != nil {
+			return err
+		}
+		converted.Status = SubscriptionStatusConverted
+		converted.RecurringExpenseID = recurringExpense.ID
+		return SubscriptionManagerModel{DB: q}.UpdateDetectedSubscriptionStatus(userID, &converted)
+	})
+	if err != nil {
+		return err
+	}
+	*subscription = converted
+	return nil
+}
+
+// populateDetectedSubscription() converts a stored detection into a DetectedSubscription
+func populateDetectedSubscription(row database.DetectedSubscription) *DetectedSubscription {
+	subscription := &DetectedSubscription{
+		ID:                 row.ID,
+		UserID:             row.UserID,
+		BudgetID:           row.BudgetID,
+		MerchantKey:        row.MerchantKey,
+		Name:               row.Name,
+		Amount:             decimal.RequireFromString(row.Amount),
+		RecurrenceInterval: row.RecurrenceInterval,
+		IntervalCount:      row.IntervalCount,
+		ChargeCount:        row.ChargeCount,
+		FirstCharged:       row.FirstCharged,
+		LastCharged:        row.LastCharged,
+		NextExpected:       row.NextExpected,
+		Status:             row.Status,
+		RecurringExpenseID: row.RecurringExpenseID.Int64,
+		CreatedAt:          row.CreatedAt.Time,
+		UpdatedAt:          row.UpdatedAt.Time,
+	}
+	if row.PreviousAmount.Valid {
+		subscription.PreviousAmount = decimal.RequireFromString(row.PreviousAmount.String)
+	}
+	return subscription
+}
@@ -0,0 +1,185 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/shopspring/decimal"
+)
+
+func TestSubscriptionMerchantKey(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "Card references are dropped", in: "NETFLIX.COM #1234", want: "netflix com"},
+		{name: "Spacing is normalized", in: "  Spotify   Premium ", want: "spotify premium"},
+		{name: "Only digits", in: "12345", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SubscriptionMerchantKey(tt.in); got != tt.want {
+				t.Errorf("SubscriptionMerchantKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectSubscriptions(t *testing.T) {
+	now := time.Date(2025, time.June, 20, 0, 0, 0, 0, time.UTC)
+	charge := func(name string, month time.Month, day int, amount string) *SubscriptionCharge {
+		return &SubscriptionCharge{BudgetID: 1, Name: name, Amount: decimal.RequireFromString(amount), DateOccurred: time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)}
+	}
+	tests := []struct {
+		name             string
+		charges          []*SubscriptionCharge
+		registered       []string
+		wantCount        int
+		wantInterval     database.RecurrenceIntervalEnum
+		wantCharges      int32
+		wantPrevious     string
+		wantNextExpected time.Time
+	}{
+		{
+			name: "Monthly subscription with a price increase",
+			charges: []*SubscriptionCharge{
+				charge("Netflix", time.March, 5, "15.49"),
+				charge("NETFLIX #88", time.April, 5, "15.49"),
+				charge("Netflix", time.May, 5, "17.99"),
+				charge("Netflix", time.June, 5, "17.99"),
+			},
+			wantCount:        1,
+			wantInterval:     database.RecurrenceIntervalEnumMonthly,
+			wantCharges:      4,
+			wantPrevious:     "15.49",
+			wantNextExpected: time.Date(2025, time.July, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Weekly charges",
+			charges: []*SubscriptionCharge{
+				charge("Gym", time.May, 30, "10"),
+				charge("Gym", time.June, 6, "10"),
+				charge("Gym", time.June, 13, "10"),
+			},
+			wantCount:        1,
+			wantInterval:     database.RecurrenceIntervalEnumWeekly,
+			wantCharges:      3,
+			wantPrevious:     "0",
+			wantNextExpected: time.Date(2025, time.June, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Irregular spacing is not a subscription",
+			charges: []*SubscriptionCharge{
+				charge("Coffee", time.April, 1, "4"),
+				charge("Coffee", time.April, 20, "4"),
+				charge("Coffee", time.June, 2, "4"),
+			},
+			wantCount: 0,
+		},
+		{
+			name: "Amounts that differ too much are not a subscription",
+			charges: []*SubscriptionCharge{
+				charge("Groceries", time.April, 1, "40"),
+				charge("Groceries", time.May, 1, "120"),
+				charge("Groceries", time.June, 1, "60"),
+			},
+			wantCount: 0,
+		},
+		{
+			name: "Already registered as a recurring expense",
+			charges: []*SubscriptionCharge{
+				charge("Spotify", time.April, 10, "9.99"),
+				charge("Spotify", time.May, 10, "9.99"),
+				charge("Spotify", time.June, 10, "9.99"),
+			},
+			registered: []string{"spotify"},
+			wantCount:  0,
+		},
+		{
+			name: "Cancelled subscriptions are left out",
+			charges: []*SubscriptionCharge{
+				charge("Magazine", time.January, 2, "5"),
+				charge("Magazine", time.February, 2, "5"),
+				charge("Magazine", time.March, 2, "5"),
+			},
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectSubscriptions(tt.charges, tt.registered, now)
+			if len(got) != tt.wantCount {
+				t.Fatalf("DetectSubscriptions() found %d subscriptions, want %d", len(got), tt.wantCount)
+			}
+			if tt.wantCount == 0 {
+				return
+			}
+			subscription := got[0]
+			if subscription.RecurrenceInterval != tt.wantInterval {
+				t.Errorf("DetectSubscriptions() interval = %s, want %s", subscription.RecurrenceInterval, tt.wantInterval)
+			}
+			if subscription.ChargeCount != tt.wantCharges {
+				t.Errorf("DetectSubscriptions() charges = %d, want %d", subscription.ChargeCount, tt.wantCharges)
+			}
+			if !subscription.PreviousAmount.Equal(decimal.RequireFromString(tt.wantPrevious)) {
+				t.Errorf("DetectSubscriptions() previous amount = %s, want %s", subscription.PreviousAmount, tt.wantPrevious)
+			}
+			if !subscription.NextExpected.Equal(tt.wantNextExpected) {
+				t.Errorf("DetectSubscriptions() next expected = %s, want %s", subscription.NextExpected, tt.wantNextExpected)
+			}
+		})
+	}
+}
+
+func TestDetectedSubscriptionPriceIncreaseSince(t *testing.T) {
+	subscription := func(amount string, status database.SubscriptionStatusEnum) *DetectedSubscription {
+		return &DetectedSubscription{Amount: decimal.RequireFromString(amount), Status: status}
+	}
+	tests := []struct {
+		name         string
+		previous     *DetectedSubscription
+		current      *DetectedSubscription
+		wantIncrease string
+		wantOK       bool
+	}{
+		{name: "Price went up", previous: subscription("9.99", SubscriptionStatusActive), current: subscription("12.99", SubscriptionStatusActive), wantIncrease: "3", wantOK: true},
+		{name: "Price went down", previous: subscription("12.99", SubscriptionStatusActive), current: subscription("9.99", SubscriptionStatusActive), wantOK: false},
+		{name: "Dismissed subscriptions are not followed", previous: subscription("9.99", SubscriptionStatusDismissed), current: subscription("12.99", SubscriptionStatusDismissed), wantOK: false},
+		{name: "First detection", previous: nil, current: subscription("12.99", SubscriptionStatusActive), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			increase, ok := tt.current.PriceIncreaseSince(tt.previous)
+			if ok != tt.wantOK {
+				t.Fatalf("PriceIncreaseSince() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !increase.Equal(decimal.RequireFromString(tt.wantIncrease)) {
+				t.Errorf("PriceIncreaseSince() = %s, want %s", increase, tt.wantIncrease)
+			}
+		})
+	}
+}
+
+func TestDetectedSubscriptionToRecurringExpense(t *testing.T) {
+	subscription := &DetectedSubscription{
+		BudgetID:           1,
+		Name:               "Netflix",
+		Amount:             decimal.RequireFromString("12.99"),
+		RecurrenceInterval: database.RecurrenceIntervalEnumMonthly,
+		IntervalCount:      1,
+		LastCharged:        time.Date(2026, time.September, 30, 0, 0, 0, 0, time.UTC),
+	}
+	got := subscription.ToRecurringExpense(time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC))
+	// the latest charge is already an expense, so the trigger must not post it again
+	if !got.FirstOccurrenceRecorded {
+		t.Errorf("ToRecurringExpense() FirstOccurrenceRecorded = false, want true")
+	}
+	if want := time.Date(2026, time.October, 30, 0, 0, 0, 0, time.UTC); !got.NextOccurrence.Equal(want) {
+		t.Errorf("ToRecurringExpense() NextOccurrence = %v, want %v", got.NextOccurrence, want)
+	}
+}
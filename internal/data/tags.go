@@ -0,0 +1,295 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type TagManagerModel struct {
+	DB   *database.Queries
+	Conn *sql.DB
+}
+
+const (
+	BulkTagActionAdd    = "add"
+	BulkTagActionRemove = "remove"
+)
+
+var (
+	DefaultTagDBContextTimeout = 5 * time.Second
+	MaxTagsPerOperation        = 20
+	MaxBulkTagRecords          = 500
+	MaxTagNameLength           = 50
+)
+
+// TagNameRX is the format of a normalized tag name i.e "business-trip-2026" or "tax:deductible"
+var TagNameRX = regexp.MustCompile(`^[a-z0-9][a-z0-9._:/-]*$`)
+
+// Tag is a free-form label a user puts on their expenses, incomes and group expenses.
+// The counts hold the number of records carrying the tag
+type Tag struct {
+	ID                int64     `json:"id"`
+	UserID            int64     `json:"user_id"`
+	Name              string    `json:"name"`
+	ExpenseCount      int64     `json:"expense_count"`
+	IncomeCount       int64     `json:"income_count"`
+	GroupExpenseCount int64     `json:"group_expense_count"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// TagReport is the spending and income of a single tag within a period.
+// TotalSpending adds up personal and group expenses, NetAmount is income less spending
+type TagReport struct {
+	TagID              int64           `json:"tag_id"`
+	Name               string          `json:"name"`
+	TotalExpenses      decimal.Decimal `json:"total_expenses"`
+	ExpenseCount       int64           `json:"expense_count"`
+	TotalGroupExpenses decimal.Decimal `json:"total_group_expenses"`
+	GroupExpenseCount  int64           `json:"group_expense_count"`
+	TotalIncome        decimal.Decimal `json:"total_income"`
+	IncomeCount        int64           `json:"income_count"`
+	TotalSpending      decimal.Decimal `json:"total_spending"`
+	NetAmount          decimal.Decimal `json:"net_amount"`
+}
+
+// BulkTagOperation adds or removes a set of tags on many records at once
+type BulkTagOperation struct {
+	Action          string   `json:"action"`
+	Tags            []string `json:"tags"`
+	ExpenseIDs      []int64  `json:"expense_ids"`
+	IncomeIDs       []int64  `json:"income_ids"`
+	GroupExpenseIDs []int64  `json:"group_expense_ids"`
+}
+
+// BulkTagResult holds the number of tag links that were added or removed per record type.
+// Records that do not belong to the user are skipped silently
+type BulkTagResult struct {
+	Action        string `json:"action"`
+	Expenses      int64  `json:"expenses"`
+	Incomes       int64  `json:"incomes"`
+	GroupExpenses int64  `json:"group_expenses"`
+}
+
+// NormalizeTags() lower cases and trims the tags, replaces inner whitespace with a dash and
+// drops empty and duplicate tags while keeping the original order
+func NormalizeTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// ValidateTags() validates a list of normalized tags under the given key
+func ValidateTags(v *validator.Validator, key string, tags []string) {
+	v.Check(len(tags) <= MaxTagsPerOperation, key, fmt.Sprintf("must not contain more than %d tags", MaxTagsPerOperation))
+	for _, tag := range tags {
+		if len(tag) > MaxTagNameLength || !validator.Matches(tag, TagNameRX) {
+			v.AddError(key, fmt.Sprintf("tags must be at most %d characters made of letters, numbers and . _ : / -", MaxTagNameLength))
+			return
+		}
+	}
+}
+
+// ValidateBulkTagOperation() validates a bulk tag operation, the tags should already be normalized
+func ValidateBulkTagOperation(v *validator.Validator, operation *BulkTagOperation) {
+	v.Check(validator.PermittedValue(operation.Action, BulkTagActionAdd, BulkTagActionRemove), "action", "must be either add or remove")
+	v.Check(len(operation.Tags) > 0, "tags", "must contain at least one tag")
+	ValidateTags(v, "tags", operation.Tags)
+	totalRecords := len(operation.ExpenseIDs) + len(operation.IncomeIDs) + len(operation.GroupExpenseIDs)
+	v.Check(totalRecords > 0, "records", "at least one expense, income or group expense id must be provided")
+	v.Check(totalRecords <= MaxBulkTagRecords, "records", fmt.Sprintf("must not contain more than %d records", MaxBulkTagRecords))
+}
+
+// GetTagsByUserID() returns all of the user's tags with their usage counts, sorted by name
+func (m TagManagerModel) GetTagsByUserID(userID int64) ([]*Tag, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultTagDBContextTimeout)
+	defer cancel()
+	// get the tags
+	tagRows, err := m.DB.GetTagsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tags := []*Tag{}
+	for _, tagRow := range tagRows {
+		tags = append(tags, &Tag{
+			ID:                tagRow.ID,
+			UserID:            tagRow.UserID,
+			Name:              tagRow.Name,
+			ExpenseCount:      tagRow.ExpenseCount,
+			IncomeCount:       tagRow.IncomeCount,
+			GroupExpenseCount: tagRow.GroupExpenseCount,
+			CreatedAt:         tagRow.CreatedAt.Time,
+		})
+	}
+	return tags, nil
+}
+
+// DeleteTagByID() deletes one of the user's tags, removing it from every record it was on
+func (m TagManagerModel) DeleteTagByID(userID, tagID int64) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultTagDBContextTimeout)
+	defer cancel()
+	// delete the tag
+	_, err := m.DB.DeleteTagByID(ctx, database.DeleteTagByIDParams{
+		ID:     tagID,
+		UserID: userID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// BulkTag() adds or removes the tags of the operation on all the listed records.
+// New tags are created on the fly when adding. Expenses and incomes must belong to the user
+// and group expenses must be in a group the user is an accepted member of.
+// The operation runs in one transaction so either all the records are changed or none are.
+func (m TagManagerModel) BulkTag(userID int64, operation *BulkTagOperation) (*BulkTagResult, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultTagDBContextTimeout)
+	defer cancel()
+	result := &BulkTagResult{Action: operation.Action}
+	err := withTransaction(ctx, m.Conn, m.DB, func(q *database.Queries) error {
+		return bulkTag(ctx, q, userID, operation, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// bulkTag() applies a bulk tag operation with the given queries, filling in the result
+func bulkTag(ctx context.Context, q *database.Queries, userID int64, operation *BulkTagOperation, result *BulkTagResult) error {
+	var err error
+	switch operation.Action {
+	case BulkTagActionAdd:
+		// make sure all the tags exist
+		err = q.CreateTagsIfNotExist(ctx, database.CreateTagsIfNotExistParams{
+			Column1: userID,
+			Column2: operation.Tags,
+		})
+		if err != nil {
+			return err
+		}
+		if len(operation.ExpenseIDs) > 0 {
+			result.Expenses, err = q.AddTagsToExpenses(ctx, database.AddTagsToExpensesParams{
+				UserID:  userID,
+				Column2: operation.ExpenseIDs,
+				Column3: operation.Tags,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(operation.IncomeIDs) > 0 {
+			result.Incomes, err = q.AddTagsToIncomes(ctx, database.AddTagsToIncomesParams{
+				UserID:  userID,
+				Column2: operation.IncomeIDs,
+				Column3: operation.Tags,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(operation.GroupExpenseIDs) > 0 {
+			result.GroupExpenses, err = q.AddTagsToGroupExpenses(ctx, database.AddTagsToGroupExpensesParams{
+				UserID:  userID,
+				Column2: operation.GroupExpenseIDs,
+				Column3: operation.Tags,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	case BulkTagActionRemove:
+		if len(operation.ExpenseIDs) > 0 {
+			result.Expenses, err = q.RemoveTagsFromExpenses(ctx, database.RemoveTagsFromExpensesParams{
+				UserID:  userID,
+				Column2: operation.ExpenseIDs,
+				Column3: operation.Tags,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(operation.IncomeIDs) > 0 {
+			result.Incomes, err = q.RemoveTagsFromIncomes(ctx, database.RemoveTagsFromIncomesParams{
+				UserID:  userID,
+				Column2: operation.IncomeIDs,
+				Column3: operation.Tags,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if len(operation.GroupExpenseIDs) > 0 {
+			result.GroupExpenses, err = q.RemoveTagsFromGroupExpenses(ctx, database.RemoveTagsFromGroupExpensesParams{
+				UserID:  userID,
+				Column2: operation.GroupExpenseIDs,
+				Column3: operation.Tags,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetTagSpendingReport() returns the spending and income of each of the user's tags between
+// the start and end dates (inclusive), the tags with the highest expenses come first.
+// Amounts are summed as stored i.e expenses in their budget's currency and incomes in the
+// user's default currency
+func (m TagManagerModel) GetTagSpendingReport(userID int64, startDate, endDate time.Time) ([]*TagReport, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultTagDBContextTimeout)
+	defer cancel()
+	// get the report
+	reportRows, err := m.DB.GetTagSpendingReportByUserID(ctx, database.GetTagSpendingReportByUserIDParams{
+		UserID:  userID,
+		Column2: startDate,
+		Column3: endDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	reports := []*TagReport{}
+	for _, reportRow := range reportRows {
+		report := &TagReport{
+			TagID:              reportRow.ID,
+			Name:               reportRow.Name,
+			TotalExpenses:      decimal.RequireFromString(reportRow.TotalExpenses),
+			ExpenseCount:       reportRow.ExpenseCount,
+			TotalGroupExpenses: decimal.RequireFromString(reportRow.TotalGroupExpenses),
+			GroupExpenseCount:  reportRow.GroupExpenseCount,
+			TotalIncome:        decimal.RequireFromString(reportRow.TotalIncome),
+			IncomeCount:        reportRow.IncomeCount,
+		}
+		report.TotalSpending = report.TotalExpenses.Add(report.TotalGroupExpenses)
+		report.NetAmount = report.TotalIncome.Sub(report.TotalSpending)
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
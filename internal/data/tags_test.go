@@ -0,0 +1,134 @@
+package data
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{
+			name: "Lower case and trim",
+			tags: []string{"  Tax-Deductible ", "BUSINESS-TRIP-2026"},
+			want: []string{"tax-deductible", "business-trip-2026"},
+		},
+		{
+			name: "Inner whitespace becomes a dash",
+			tags: []string{"business  trip\t2026"},
+			want: []string{"business-trip-2026"},
+		},
+		{
+			name: "Duplicates and empty tags are dropped",
+			tags: []string{"travel", "", "Travel", "   ", "food"},
+			want: []string{"travel", "food"},
+		},
+		{
+			name: "No tags",
+			tags: nil,
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeTags(tt.tags)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBulkTagOperation(t *testing.T) {
+	manyTags := make([]string, MaxTagsPerOperation+1)
+	for i := range manyTags {
+		manyTags[i] = "tag-" + strings.Repeat("a", i+1)
+	}
+	tests := []struct {
+		name      string
+		operation *BulkTagOperation
+		wantKeys  []string
+	}{
+		{
+			name: "Valid add",
+			operation: &BulkTagOperation{
+				Action:     BulkTagActionAdd,
+				Tags:       []string{"tax-deductible", "trip:2026"},
+				ExpenseIDs: []int64{1, 2},
+			},
+		},
+		{
+			name: "Valid remove on group expenses",
+			operation: &BulkTagOperation{
+				Action:          BulkTagActionRemove,
+				Tags:            []string{"shared"},
+				GroupExpenseIDs: []int64{9},
+			},
+		},
+		{
+			name: "Unknown action",
+			operation: &BulkTagOperation{
+				Action:    "replace",
+				Tags:      []string{"travel"},
+				IncomeIDs: []int64{1},
+			},
+			wantKeys: []string{"action"},
+		},
+		{
+			name: "Invalid tag name",
+			operation: &BulkTagOperation{
+				Action:     BulkTagActionAdd,
+				Tags:       []string{"-travel!"},
+				ExpenseIDs: []int64{1},
+			},
+			wantKeys: []string{"tags"},
+		},
+		{
+			name: "Too many tags",
+			operation: &BulkTagOperation{
+				Action:     BulkTagActionAdd,
+				Tags:       manyTags,
+				ExpenseIDs: []int64{1},
+			},
+			wantKeys: []string{"tags"},
+		},
+		{
+			name: "No tags and no records",
+			operation: &BulkTagOperation{
+				Action: BulkTagActionAdd,
+			},
+			wantKeys: []string{"tags", "records"},
+		},
+		{
+			name: "Too many records",
+			operation: &BulkTagOperation{
+				Action:     BulkTagActionAdd,
+				Tags:       []string{"travel"},
+				ExpenseIDs: make([]int64, MaxBulkTagRecords+1),
+			},
+			wantKeys: []string{"records"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validator.New()
+			ValidateBulkTagOperation(v, tt.operation)
+			if len(v.Errors) != len(tt.wantKeys) {
+				t.Fatalf("ValidateBulkTagOperation() errors = %v, want keys %v", v.Errors, tt.wantKeys)
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := v.Errors[key]; !ok {
+					t.Errorf("ValidateBulkTagOperation() missing error for %q, got %v", key, v.Errors)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/database"
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+type TaxManagerModel struct {
+	DB *database.Queries
+}
+
+// The tax categories expenses and incomes can be flagged with
+const (
+	TaxCategoryDeductible = string(database.TaxCategoryEnumDeductible)
+	TaxCategoryBusiness   = string(database.TaxCategoryEnumBusiness)
+	TaxCategoryMedical    = string(database.TaxCategoryEnumMedical)
+	TaxCategoryCharitable = string(database.TaxCategoryEnumCharitable)
+)
+
+// The kinds of records on the tax summary
+const (
+	TaxRecordExpense = "expense"
+	TaxRecordIncome  = "income"
+)
+
+var (
+	DefaultTaxDBContextTimeout = 5 * time.Second
+	// TaxCategories is the order categories appear in on the summary
+	TaxCategories = []string{TaxCategoryDeductible, TaxCategoryBusiness, TaxCategoryMedical, TaxCategoryCharitable}
+)
+
+// TaxRecord is an expense or income flagged with a tax category. A record with several
+// categories appears once per category. Amount is in the summary's currency while
+// OriginalAmount is in CurrencyCode, expenses are recorded in their budget's currency
+type TaxRecord struct {
+	ID             int64           `json:"id"`
+	Kind           string          `json:"kind"`
+	Name           string          `json:"name"`
+	Category       string          `json:"category,omitempty"`
+	TaxCategory    string          `json:"tax_category"`
+	Date           time.Time       `json:"date"`
+	Amount         decimal.Decimal `json:"amount"`
+	OriginalAmount decimal.Decimal `json:"original_amount"`
+	CurrencyCode   string          `json:"currency_code"`
+}
+
+// TaxCategoryTotal holds the expense and income totals of a single tax category
+type TaxCategoryTotal struct {
+	TaxCategory   string          `json:"tax_category"`
+	TotalExpenses decimal.Decimal `json:"total_expenses"`
+	ExpenseCount  int             `json:"expense_count"`
+	TotalIncome   decimal.Decimal `json:"total_income"`
+	IncomeCount   int             `json:"income_count"`
+}
+
+// TaxInvestmentTransaction is an investment buy or sell used to work out realized gains.
+// PurchasePrice is the unit price recorded on the investment itself, it is zero when unknown
+type TaxInvestmentTransaction struct {
+	ID              int64
+	InvestmentType  string
+	InvestmentID    int64
+	InvestmentName  string
+	TransactionType string
+	TransactionDate time.Time
+	Amount          decimal.Decimal
+	Quantity        decimal.Decimal
+	PurchasePrice   decimal.Decimal
+}
+
+// RealizedGain is the gain (or loss when negative) of a single sale
+type RealizedGain struct {
+	TransactionID  int64           `json:"transaction_id"`
+	InvestmentType string          `json:"investment_type"`
+	InvestmentID   int64           `json:"investment_id"`
+	InvestmentName string          `json:"investment_name"`
+	SaleDate       time.Time       `json:"sale_date"`
+	Quantity       decimal.Decimal `json:"quantity"`
+	Proceeds       decimal.Decimal `json:"proceeds"`
+	CostBasis      decimal.Decimal `json:"cost_basis"`
+	GainLoss       decimal.Decimal `json:"gain_loss"`
+}
+
+// AnnualTaxSummary groups the user's tax flagged expenses and incomes of a year by category
+// and lists the investment gains and losses realized in that year. The totals count a record
+// flagged with several categories only once
+type AnnualTaxSummary struct {
+	Year                        int                 `json:"year"`
+	StartDate                   time.Time           `json:"start_date"`
+	EndDate                     time.Time           `json:"end_date"`
+	CurrencyCode                string              `json:"currency_code"`
+	Categories                  []*TaxCategoryTotal `json:"categories"`
+	TotalTaxCategorizedExpenses decimal.Decimal     `json:"total_tax_categorized_expenses"`
+	TotalTaxCategorizedIncome   decimal.Decimal     `json:"total_tax_categorized_income"`
+	RealizedGains               []*RealizedGain     `json:"realized_gains"`
+	TotalRealizedGains          decimal.Decimal     `json:"total_realized_gains"`
+	TotalRealizedLosses         decimal.Decimal     `json:"total_realized_losses"`
+	NetRealizedGain             decimal.Decimal     `json:"net_realized_gain"`
+	Expenses                    []*TaxRecord        `json:"expenses"`
+	Incomes                     []*TaxRecord        `json:"incomes"`
+}
+
+// NormalizeTaxCategories() lower cases and trims the categories and drops empty and duplicate ones
+func NormalizeTaxCategories(categories []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, category := range categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category == "" || seen[category] {
+			continue
+		}
+		seen[category] = true
+		normalized = append(normalized, category)
+	}
+	return normalized
+}
+
+// ValidateTaxCategories() checks that every category is a known tax category.
+// An empty list is valid and clears the categories of a record
+func ValidateTaxCategories(v *validator.Validator, categories []string) {
+	for _, category := range categories {
+		if !validator.PermittedValue(category, TaxCategories...) {
+			v.AddError("tax_categories", "must only contain deductible, business, medical or charitable")
+			return
+		}
+	}
+}
+
+// ValidateTaxYear() validates the year of a tax summary
+func ValidateTaxYear(v *validator.Validator, year int) {
+	v.Check(year >= 1970, "year", "must not be before 1970")
+	v.Check(year <= time.Now().UTC().Year(), "year", "must not be in the future")
+}
+
+// ConvertTaxRecords() sets the amount of each record in the summary's currency using the stored rate
+// of the record's date. Records without one fall back to the rates map, which holds the current
+// multiplier from each foreign currency, records without any rate keep their amount
+func ConvertTaxRecords(records []*TaxRecord, currencyCode string, history *ExchangeRateHistory, rates map[string]decimal.Decimal) {
+	for _, record := range records {
+		if record.CurrencyCode == "" || record.CurrencyCode == currencyCode {
+			record.Amount = record.OriginalAmount
+			continue
+		}
+		// prefer the rate of the day the record happened on
+		if history != nil {
+			if rate, ok := history.Rate(record.CurrencyCode, currencyCode, record.Date); ok {
+				record.Amount = record.OriginalAmount.Mul(rate).Round(2)
+				continue
+			}
+		}
+		rate, ok := rates[record.CurrencyCode]
+		if !ok {
+			record.Amount = record.OriginalAmount
+			continue
+		}
+		record.Amount = record.OriginalAmount.Mul(rate).Round(2)
+	}
+}
+
+// CalculateRealizedGains() works out the gain of every sale between the start and end dates using
+// the average cost method. Transactions must be sorted by date and include every buy before the end date.
+// Units sold beyond what was bought through transactions are costed at the investment's purchase price.
+func CalculateRealizedGains(transactions []*TaxInvestmentTransaction, startDate, endDate time.Time) []*RealizedGain {
+	type position struct {
+		quantity decimal.Decimal
+		cost     decimal.Decimal
+	}
+	type positionKey struct {
+		investmentType string
+		investmentID   int64
+	}
+	startDate, endDate = dateOnly(startDate), dateOnly(endDate)
+	positions := make(map[positionKey]*position)
+	gains := []*RealizedGain{}
+	for _, transaction := range transactions {
+		key := positionKey{transaction.InvestmentType, transaction.InvestmentID}
+		held, ok := positions[key]
+		if !ok {
+			held = &position{}
+			positions[key] = held
+		}
+		switch transaction.TransactionType {
+		case string(database.TransactionTypeEnumBuy):
+			held.quantity = held.quantity.Add(transaction.Quantity)
+			held.cost = held.cost.Add(transaction.Amount)
+		case string(database.TransactionTypeEnumSell):
+			costBasis := decimal.Zero
+			covered := decimal.Min(transaction.Quantity, held.quantity)
+			if held.quantity.IsPositive() {
+				costBasis = held.cost.Mul(covered).Div(held.quantity)
+				held.cost = held.cost.Sub(costBasis)
+				held.quantity = held.quantity.Sub(covered)
+			}
+			costBasis = costBasis.Add(transaction.Quantity.Sub(covered).Mul(transaction.PurchasePrice)).Round(2)
+			saleDate := dateOnly(transaction.TransactionDate)
+			if saleDate.Before(startDate) || saleDate.After(endDate) {
+				continue
+			}
+			gains = append(gains, &RealizedGain{
+				TransactionID:  transaction.ID,
+				InvestmentType: transaction.InvestmentType,
+				InvestmentID:   transaction.InvestmentID,
+				InvestmentName: transaction.InvestmentName,
+				SaleDate:       saleDate,
+				Quantity:       transaction.Quantity,
+				Proceeds:       transaction.Amount,
+				CostBasis:      costBasis,
+				GainLoss:       transaction.Amount.Sub(costBasis),
+			})
+		}
+	}
+	return gains
+}
+
+// BuildAnnualTaxSummary() totals the converted tax records per category and adds up the realized gains.
+// Every category is listed, including those without records
+func BuildAnnualTaxSummary(year int, currencyCode string, expenses, incomes []*TaxRecord, gains []*RealizedGain) *AnnualTaxSummary {
+	summary := &AnnualTaxSummary{
+		Year:          year,
+		StartDate:     time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:       time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC),
+		CurrencyCode:  currencyCode,
+		Categories:    []*TaxCategoryTotal{},
+		RealizedGains: gains,
+		Expenses:      expenses,
+		Incomes:       incomes,
+	}
+	totals := make(map[string]*TaxCategoryTotal)
+	for _, category := range TaxCategories {
+		totals[category] = &TaxCategoryTotal{TaxCategory: category}
+		summary.Categories = append(summary.Categories, totals[category])
+	}
+	countedExpenses := make(map[int64]bool)
+	for _, expense := range expenses {
+		if total, ok := totals[expense.TaxCategory]; ok {
+			total.TotalExpenses = total.TotalExpenses.Add(expense.Amount)
+			total.ExpenseCount++
+		}
+		if !countedExpenses[expense.ID] {
+			countedExpenses[expense.ID] = true
+			summary.TotalTaxCategorizedExpenses = summary.TotalTaxCategorizedExpenses.Add(expense.Amount)
+		}
+	}
+	countedIncomes := make(map[int64]bool)
+	for _, income := range incomes {
+		if total, ok := totals[income.TaxCategory]; ok {
+			total.TotalIncome = total.TotalIncome.Add(income.Amount)
+			total.IncomeCount++
+		}
+		if !countedIncomes[income.ID] {
+			countedIncomes[income.ID] = true
+			summary.TotalTaxCategorizedIncome = summary.TotalTaxCategorizedIncome.Add(income.Amount)
+		}
+	}
+	for _, gain := range gains {
+		if gain.GainLoss.IsNegative() {
+			summary.TotalRealizedLosses = summary.TotalRealizedLosses.Add(gain.GainLoss.Neg())
+			continue
+		}
+		summary.TotalRealizedGains = summary.TotalRealizedGains.Add(gain.GainLoss)
+	}
+	summary.NetRealizedGain = summary.TotalRealizedGains.Sub(summary.TotalRealizedLosses)
+	return summary
+}
+
+// SetExpenseTaxCategories() replaces the tax categories of one of the user's expenses.
+// An empty list clears them. We return ErrGeneralRecordNotFound if the expense is not the user's
+func (m TaxManagerModel) SetExpenseTaxCategories(userID, expenseID int64, categories []string) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultTaxDBContextTimeout)
+	defer cancel()
+	_, err := m.DB.SetExpenseTaxCategories(ctx, database.SetExpenseTaxCategoriesParams{
+		ID:      expenseID,
+		UserID:  userID,
+		Column3: categories,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// SetIncomeTaxCategories() replaces the tax categories of one of the user's incomes.
+// An empty list clears them. We return ErrGeneralRecordNotFound if the income is not the user's
+func (m TaxManagerModel) SetIncomeTaxCategories(userID, incomeID int64, categories []string) error {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultTaxDBContextTimeout)
+	defer cancel()
+	_, err := m.DB.SetIncomeTaxCategories(ctx, database.SetIncomeTaxCategoriesParams{
+		ID:      incomeID,
+		UserID:  userID,
+		Column3: categories,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrGeneralRecordNotFound
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTaxRecordsByUserID() returns the user's tax flagged expenses and incomes between the start and end
+// dates. Incomes are recorded in the user's default currency so their currency code is left empty
+func (m TaxManagerModel) GetTaxRecordsByUserID(userID int64, startDate, endDate time.Time) ([]*TaxRecord, []*TaxRecord, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultTaxDBContextTimeout)
+	defer cancel()
+	expenseRows, err := m.DB.GetTaxCategorizedExpensesByUserID(ctx, database.GetTaxCategorizedExpensesByUserIDParams{
+		UserID:  userID,
+		Column2: startDate,
+		Column3: endDate,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	expenses := []*TaxRecord{}
+	for _, row := range expenseRows {
+		expenses = append(expenses, &TaxRecord{
+			ID:             row.ID,
+			Kind:           TaxRecordExpense,
+			Name:           row.Name,
+			Category:       row.Category,
+			TaxCategory:    string(row.TaxCategory),
+			Date:           row.DateOccurred,
+			OriginalAmount: decimal.RequireFromString(row.Amount),
+			CurrencyCode:   row.CurrencyCode,
+		})
+	}
+	incomeRows, err := m.DB.GetTaxCategorizedIncomesByUserID(ctx, database.GetTaxCategorizedIncomesByUserIDParams{
+		UserID:  userID,
+		Column2: startDate,
+		Column3: endDate,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	incomes := []*TaxRecord{}
+	for _, row := range incomeRows {
+		incomes = append(incomes, &TaxRecord{
+			ID:             row.ID,
+			Kind:           TaxRecordIncome,
+			Name:           row.Source,
+			TaxCategory:    string(row.TaxCategory),
+			Date:           row.DateReceived,
+			OriginalAmount: decimal.RequireFromString(row.Amount),
+		})
+	}
+	return expenses, incomes, nil
+}
+
+// GetInvestmentTransactionsForTax() returns all of the user's investment transactions up to the end date,
+// oldest first, as needed by CalculateRealizedGains()
+func (m TaxManagerModel) GetInvestmentTransactionsForTax(userID int64, endDate time.Time) ([]*TaxInvestmentTransaction, error) {
+	// get our context
+	ctx, cancel := contextGenerator(context.Background(), DefaultTaxDBContextTimeout)
+	defer cancel()
+	rows, err := m.DB.GetInvestmentTransactionsForTaxByUserID(ctx, database.GetInvestmentTransactionsForTaxByUserIDParams{
+		UserID:  userID,
+		Column2: endDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	transactions := []*TaxInvestmentTransaction{}
+	for _, row := range rows {
+		transactions = append(transactions, &TaxInvestmentTransaction{
+			ID:              row.ID,
+			InvestmentType:  string(row.InvestmentType),
+			InvestmentID:    row.InvestmentID,
+			InvestmentName:  row.InvestmentName,
+			TransactionType: string(row.TransactionType),
+			TransactionDate: row.TransactionDate,
+			Amount:          decimal.RequireFromString(row.TransactionAmount),
+			Quantity:        decimal.RequireFromString(row.Quantity),
+			PurchasePrice:   decimal.RequireFromString(row.PurchasePrice),
+		})
+	}
+	return transactions, nil
+}
@@ -0,0 +1,212 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Blue-Davinci/OptiVest/internal/validator"
+	"github.com/shopspring/decimal"
+)
+
+func TestValidateTaxCategories(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []string
+		want       []string
+		wantValid  bool
+	}{
+		{
+			name:       "Normalized and deduplicated",
+			categories: []string{" Business ", "deductible", "BUSINESS"},
+			want:       []string{"business", "deductible"},
+			wantValid:  true,
+		},
+		{
+			name:       "Empty list clears the categories",
+			categories: nil,
+			want:       []string{},
+			wantValid:  true,
+		},
+		{
+			name:       "Unknown category",
+			categories: []string{"medical", "groceries"},
+			want:       []string{"medical", "groceries"},
+			wantValid:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeTaxCategories(tt.categories)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeTaxCategories() = %v, want %v", got, tt.want)
+			}
+			v := validator.New()
+			ValidateTaxCategories(v, got)
+			if v.Valid() != tt.wantValid {
+				t.Errorf("ValidateTaxCategories() valid = %v, want %v (%v)", v.Valid(), tt.wantValid, v.Errors)
+			}
+		})
+	}
+}
+
+func TestCalculateRealizedGains(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	transaction := func(id int64, investmentID int64, kind string, on time.Time, amount, quantity, purchasePrice int64) *TaxInvestmentTransaction {
+		return &TaxInvestmentTransaction{
+			ID:              id,
+			InvestmentType:  "Stock",
+			InvestmentID:    investmentID,
+			InvestmentName:  "ACME",
+			TransactionType: kind,
+			TransactionDate: on,
+			Amount:          decimal.NewFromInt(amount),
+			Quantity:        decimal.NewFromInt(quantity),
+			PurchasePrice:   decimal.NewFromInt(purchasePrice),
+		}
+	}
+	tests := []struct {
+		name         string
+		transactions []*TaxInvestmentTransaction
+		wantBasis    []string
+		wantGainLoss []string
+	}{
+		{
+			name: "Average cost of several buys",
+			transactions: []*TaxInvestmentTransaction{
+				transaction(1, 1, "buy", date(time.January, 10), 1000, 10, 0),
+				transaction(2, 1, "buy", date(time.February, 10), 2000, 10, 0),
+				transaction(3, 1, "sell", date(time.March, 10), 2000, 10, 0),
+			},
+			wantBasis:    []string{"1500"},
+			wantGainLoss: []string{"500"},
+		},
+		{
+			name: "Sales before the year reduce the position but are not reported",
+			transactions: []*TaxInvestmentTransaction{
+				transaction(1, 1, "buy", time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), 1000, 10, 0),
+				transaction(2, 1, "sell", time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC), 600, 5, 0),
+				transaction(3, 1, "sell", date(time.April, 1), 400, 5, 0),
+			},
+			wantBasis:    []string{"500"},
+			wantGainLoss: []string{"-100"},
+		},
+		{
+			name: "Units without buys use the investment purchase price",
+			transactions: []*TaxInvestmentTransaction{
+				transaction(1, 1, "buy", date(time.January, 10), 100, 1, 80),
+				transaction(2, 1, "sell", date(time.May, 10), 450, 3, 80),
+			},
+			wantBasis:    []string{"260"},
+			wantGainLoss: []string{"190"},
+		},
+		{
+			name: "Positions are kept per investment and other transactions are ignored",
+			transactions: []*TaxInvestmentTransaction{
+				transaction(1, 1, "buy", date(time.January, 10), 100, 1, 0),
+				transaction(2, 2, "buy", date(time.January, 10), 300, 1, 0),
+				transaction(3, 2, "other", date(time.January, 11), 50, 1, 0),
+				transaction(4, 1, "sell", date(time.June, 10), 150, 1, 0),
+			},
+			wantBasis:    []string{"100"},
+			wantGainLoss: []string{"50"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gains := CalculateRealizedGains(tt.transactions, date(time.January, 1), date(time.December, 31))
+			if len(gains) != len(tt.wantBasis) {
+				t.Fatalf("CalculateRealizedGains() returned %d gains, want %d", len(gains), len(tt.wantBasis))
+			}
+			for i, gain := range gains {
+				if !gain.CostBasis.Equal(decimal.RequireFromString(tt.wantBasis[i])) {
+					t.Errorf("CalculateRealizedGains() cost basis = %s, want %s", gain.CostBasis, tt.wantBasis[i])
+				}
+				if !gain.GainLoss.Equal(decimal.RequireFromString(tt.wantGainLoss[i])) {
+					t.Errorf("CalculateRealizedGains() gain = %s, want %s", gain.GainLoss, tt.wantGainLoss[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildAnnualTaxSummary(t *testing.T) {
+	record := func(id int64, kind, taxCategory string, amount int64) *TaxRecord {
+		return &TaxRecord{ID: id, Kind: kind, TaxCategory: taxCategory, Amount: decimal.NewFromInt(amount)}
+	}
+	tests := []struct {
+		name           string
+		expenses       []*TaxRecord
+		incomes        []*TaxRecord
+		gains          []*RealizedGain
+		wantCategories map[string][2]string
+		wantExpenses   string
+		wantIncome     string
+		wantNetGain    string
+	}{
+		{
+			name: "Records with several categories are only counted once in the totals",
+			expenses: []*TaxRecord{
+				record(1, TaxRecordExpense, TaxCategoryBusiness, 100),
+				record(1, TaxRecordExpense, TaxCategoryDeductible, 100),
+				record(2, TaxRecordExpense, TaxCategoryMedical, 40),
+			},
+			incomes: []*TaxRecord{
+				record(7, TaxRecordIncome, TaxCategoryBusiness, 1000),
+			},
+			gains: []*RealizedGain{
+				{GainLoss: decimal.NewFromInt(300)},
+				{GainLoss: decimal.NewFromInt(-120)},
+			},
+			wantCategories: map[string][2]string{
+				TaxCategoryDeductible: {"100", "0"},
+				TaxCategoryBusiness:   {"100", "1000"},
+				TaxCategoryMedical:    {"40", "0"},
+				TaxCategoryCharitable: {"0", "0"},
+			},
+			wantExpenses: "140",
+			wantIncome:   "1000",
+			wantNetGain:  "180",
+		},
+		{
+			name: "Empty year",
+			wantCategories: map[string][2]string{
+				TaxCategoryDeductible: {"0", "0"},
+				TaxCategoryBusiness:   {"0", "0"},
+				TaxCategoryMedical:    {"0", "0"},
+				TaxCategoryCharitable: {"0", "0"},
+			},
+			wantExpenses: "0",
+			wantIncome:   "0",
+			wantNetGain:  "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := BuildAnnualTaxSummary(2025, "USD", tt.expenses, tt.incomes, tt.gains)
+			if len(summary.Categories) != len(TaxCategories) {
+				t.Fatalf("BuildAnnualTaxSummary() categories = %d, want %d", len(summary.Categories), len(TaxCategories))
+			}
+			for _, category := range summary.Categories {
+				want := tt.wantCategories[category.TaxCategory]
+				if !category.TotalExpenses.Equal(decimal.RequireFromString(want[0])) || !category.TotalIncome.Equal(decimal.RequireFromString(want[1])) {
+					t.Errorf("BuildAnnualTaxSummary() %s = %s/%s, want %s/%s", category.TaxCategory, category.TotalExpenses, category.TotalIncome, want[0], want[1])
+				}
+			}
+			if !summary.TotalTaxCategorizedExpenses.Equal(decimal.RequireFromString(tt.wantExpenses)) {
+				t.Errorf("BuildAnnualTaxSummary() total expenses = %s, want %s", summary.TotalTaxCategorizedExpenses, tt.wantExpenses)
+			}
+			if !summary.TotalTaxCategorizedIncome.Equal(decimal.RequireFromString(tt.wantIncome)) {
+				t.Errorf("BuildAnnualTaxSummary() total income = %s, want %s", summary.TotalTaxCategorizedIncome, tt.wantIncome)
+			}
+			if !summary.NetRealizedGain.Equal(decimal.RequireFromString(tt.wantNetGain)) {
+				t.Errorf("BuildAnnualTaxSummary() net gain = %s, want %s", summary.NetRealizedGain, tt.wantNetGain)
+			}
+		})
+	}
+}
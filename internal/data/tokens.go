@@ -30,6 +30,8 @@ const (
 	ScopePasswordReset  = "password-reset"
 	ScopeMFALogin       = "mfa-login"
 	ScopeRecovery       = "recovery-codes"
+	ScopeCalendarFeed   = "calendar-feed"
+	ScopeGroupInvite    = "group-invite"
 )
 
 // Define a Token struct to hold the data for an individual token. This includes the
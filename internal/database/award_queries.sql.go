@@ -28,6 +28,29 @@ func (q *Queries) CreateNewUserAward(ctx context.Context, arg CreateNewUserAward
 	return created_at, err
 }
 
+const createNewUserAwardByCode = `-- name: CreateNewUserAwardByCode :execrows
+INSERT INTO user_awards (user_id, award_id)
+SELECT $1, a.id
+FROM awards a
+WHERE a.code = $2
+ON CONFLICT (user_id, award_id) DO NOTHING
+`
+
+type CreateNewUserAwardByCodeParams struct {
+	UserID int64
+	Code   string
+}
+
+// Grants an award by its code. Awards are only ever granted once, so granting one the user
+// already holds does nothing
+func (q *Queries) CreateNewUserAwardByCode(ctx context.Context, arg CreateNewUserAwardByCodeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createNewUserAwardByCode, arg.UserID, arg.Code)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getAllAwards = `-- name: GetAllAwards :many
 SELECT 
     id,
@@ -105,6 +105,19 @@ func (q *Queries) DeleteComment(ctx context.Context, arg DeleteCommentParams) (i
 	return id, err
 }
 
+const deleteCommentByID = `-- name: DeleteCommentByID :one
+DELETE FROM comments
+WHERE id = $1
+RETURNING id
+`
+
+// used by group moderators, who can delete any comment on their group
+func (q *Queries) DeleteCommentByID(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, deleteCommentByID, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
 const deleteReaction = `-- name: DeleteReaction :one
 DELETE FROM comment_reactions
 WHERE comment_id = $1 AND user_id = $2
@@ -123,6 +136,24 @@ func (q *Queries) DeleteReaction(ctx context.Context, arg DeleteReactionParams)
 	return id, err
 }
 
+const getCommentAssociation = `-- name: GetCommentAssociation :one
+SELECT associated_type, associated_id
+FROM comments
+WHERE id = $1
+`
+
+type GetCommentAssociationRow struct {
+	AssociatedType CommentAssociatedType
+	AssociatedID   int64
+}
+
+func (q *Queries) GetCommentAssociation(ctx context.Context, id int64) (GetCommentAssociationRow, error) {
+	row := q.db.QueryRowContext(ctx, getCommentAssociation, id)
+	var i GetCommentAssociationRow
+	err := row.Scan(&i.AssociatedType, &i.AssociatedID)
+	return i, err
+}
+
 const getCommentById = `-- name: GetCommentById :one
 SELECT 
     id,
@@ -9,6 +9,8 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 const adminDeleteGroupMember = `-- name: AdminDeleteGroupMember :one
@@ -21,7 +23,7 @@ WHERE gm_target.group_id = $1
       FROM group_memberships gm_admin
       WHERE gm_admin.group_id = $1
         AND gm_admin.user_id = $3
-        AND gm_admin.role = 'admin'
+        AND gm_admin.role::TEXT = ANY($4::TEXT[])
         AND gm_admin.status = 'accepted'
   )
 RETURNING user_id
@@ -31,11 +33,12 @@ type AdminDeleteGroupMemberParams struct {
 	GroupID  sql.NullInt64
 	UserID   sql.NullInt64
 	UserID_2 sql.NullInt64
+	Column4  []string
 }
 
-// Admin can delete a member from a group who is not an admin
+// Members whose role is one of $4 can delete a member from a group who is not an admin
 func (q *Queries) AdminDeleteGroupMember(ctx context.Context, arg AdminDeleteGroupMemberParams) (sql.NullInt64, error) {
-	row := q.db.QueryRowContext(ctx, adminDeleteGroupMember, arg.GroupID, arg.UserID, arg.UserID_2)
+	row := q.db.QueryRowContext(ctx, adminDeleteGroupMember, arg.GroupID, arg.UserID, arg.UserID_2, pq.Array(arg.Column4))
 	var user_id sql.NullInt64
 	err := row.Scan(&user_id)
 	return user_id, err
@@ -88,15 +91,25 @@ func (q *Queries) CheckIfGroupMembersAreMaxedOut(ctx context.Context, groupID sq
 }
 
 const createNewGroupExpense = `-- name: CreateNewGroupExpense :one
-INSERT INTO group_expenses (
-    group_id, 
-    member_id, 
-    amount, 
-    description, 
-    category
-    )
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at
+WITH new_expense AS (
+    INSERT INTO group_expenses (
+        group_id, 
+        member_id, 
+        amount, 
+        description, 
+        category,
+        split_type
+        )
+    VALUES ($1, $2, $3, $4, $5, $6)
+    RETURNING id, created_at, updated_at
+),
+expense_splits AS (
+    INSERT INTO group_expense_splits (expense_id, member_id, share, amount)
+    SELECT ne.id, UNNEST($7::BIGINT[]), UNNEST($8::NUMERIC[]), UNNEST($9::NUMERIC[])
+    FROM new_expense ne
+)
+SELECT id, created_at, updated_at
+FROM new_expense
 `
 
 type CreateNewGroupExpenseParams struct {
@@ -105,6 +118,10 @@ type CreateNewGroupExpenseParams struct {
 	Amount      string
 	Description sql.NullString
 	Category    sql.NullString
+	SplitType   NullGroupSplitTypeEnum
+	Column7     []int64
+	Column8     []string
+	Column9     []string
 }
 
 type CreateNewGroupExpenseRow struct {
@@ -120,6 +137,10 @@ func (q *Queries) CreateNewGroupExpense(ctx context.Context, arg CreateNewGroupE
 		arg.Amount,
 		arg.Description,
 		arg.Category,
+		arg.SplitType,
+		pq.Array(arg.Column7),
+		pq.Array(arg.Column8),
+		pq.Array(arg.Column9),
 	)
 	var i CreateNewGroupExpenseRow
 	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
@@ -217,11 +238,12 @@ func (q *Queries) CreateNewGroupInvitation(ctx context.Context, arg CreateNewGro
 const createNewGroupTransaction = `-- name: CreateNewGroupTransaction :one
 INSERT INTO group_transactions (
     goal_id,
+    group_id,
     member_id, 
     amount, 
     description
 ) VALUES 
-($1, $2, $3, $4)
+($1, (SELECT gg.group_id FROM group_goals gg WHERE gg.id = $1), $2, $3, $4)
 RETURNING id, created_at, updated_at
 `
 
@@ -275,9 +297,9 @@ func (q *Queries) CreateNewPublicMembership(ctx context.Context, arg CreateNewPu
 
 const createNewUserGroup = `-- name: CreateNewUserGroup :one
 INSERT INTO groups (
-    creator_user_id, group_image_url, name, is_private, max_member_count, description
+    creator_user_id, group_image_url, name, is_private, max_member_count, description, is_discoverable
 ) VALUES 
-($1, $2, $3, $4, $5, $6)
+($1, $2, $3, $4, $5, $6, $7)
 RETURNING id, creator_user_id, activity_count, last_activity_at, created_at, updated_at, version
 `
 
@@ -288,6 +310,7 @@ type CreateNewUserGroupParams struct {
 	IsPrivate      sql.NullBool
 	MaxMemberCount sql.NullInt32
 	Description    sql.NullString
+	IsDiscoverable bool
 }
 
 type CreateNewUserGroupRow struct {
@@ -308,6 +331,7 @@ func (q *Queries) CreateNewUserGroup(ctx context.Context, arg CreateNewUserGroup
 		arg.IsPrivate,
 		arg.MaxMemberCount,
 		arg.Description,
+		arg.IsDiscoverable,
 	)
 	var i CreateNewUserGroupRow
 	err := row.Scan(
@@ -323,36 +347,62 @@ func (q *Queries) CreateNewUserGroup(ctx context.Context, arg CreateNewUserGroup
 }
 
 const deleteGroupExpense = `-- name: DeleteGroupExpense :one
-DELETE FROM group_expenses
-WHERE id = $1 AND member_id = $2
-RETURNING id
+-- Members can delete their own expenses, members whose role is one of $3 anyone's in the group
+DELETE FROM group_expenses ge
+WHERE ge.id = $1
+  AND (
+      ge.member_id = $2
+      OR EXISTS (
+          SELECT 1
+          FROM group_memberships gm
+          WHERE gm.group_id = ge.group_id
+            AND gm.user_id = $2
+            AND gm.role::TEXT = ANY($3::TEXT[])
+            AND gm.status = 'accepted'
+      )
+  )
+RETURNING ge.id
 `
 
 type DeleteGroupExpenseParams struct {
 	ID       int64
 	MemberID sql.NullInt64
+	Column3  []string
 }
 
 func (q *Queries) DeleteGroupExpense(ctx context.Context, arg DeleteGroupExpenseParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, deleteGroupExpense, arg.ID, arg.MemberID)
+	row := q.db.QueryRowContext(ctx, deleteGroupExpense, arg.ID, arg.MemberID, pq.Array(arg.Column3))
 	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
 
 const deleteGroupTransaction = `-- name: DeleteGroupTransaction :one
-DELETE FROM group_transactions
-WHERE id = $1 AND member_id = $2
-RETURNING id
+DELETE FROM group_transactions gt
+WHERE gt.id = $1
+  AND (
+      gt.member_id = $2
+      OR EXISTS (
+          SELECT 1
+          FROM group_memberships gm
+          WHERE gm.group_id = gt.group_id
+            AND gm.user_id = $2
+            AND gm.role::TEXT = ANY($3::TEXT[])
+            AND gm.status = 'accepted'
+      )
+  )
+RETURNING gt.id
 `
 
 type DeleteGroupTransactionParams struct {
 	ID       int64
 	MemberID sql.NullInt64
+	Column3  []string
 }
 
+// Members can delete their own transactions, members whose role is one of $3 anyone's in the group
 func (q *Queries) DeleteGroupTransaction(ctx context.Context, arg DeleteGroupTransactionParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, deleteGroupTransaction, arg.ID, arg.MemberID)
+	row := q.db.QueryRowContext(ctx, deleteGroupTransaction, arg.ID, arg.MemberID, pq.Array(arg.Column3))
 	var id int64
 	err := row.Scan(&id)
 	return id, err
@@ -376,7 +426,7 @@ func (q *Queries) DeleteNonPendingGroupInvitationsForUser(ctx context.Context, a
 
 const getAllGroupsCreatedByUser = `-- name: GetAllGroupsCreatedByUser :many
 WITH user_groups AS (
-    SELECT g.id, g.creator_user_id, g.group_image_url, g.name, g.is_private, g.max_member_count, g.description, g.activity_count, g.last_activity_at, g.created_at, g.updated_at, g.version
+    SELECT g.id, g.creator_user_id, g.group_image_url, g.name, g.is_private, g.max_member_count, g.description, g.activity_count, g.last_activity_at, g.created_at, g.updated_at, g.version, g.is_discoverable, g.archived_at, g.deletion_scheduled_at
     FROM groups g
     WHERE g.creator_user_id = $1 
 ),
@@ -425,7 +475,7 @@ group_transaction_stats AS (
     GROUP BY gg.group_id
 )
 
-SELECT ug.id, ug.creator_user_id, ug.group_image_url, ug.name, ug.is_private, ug.max_member_count, ug.description, ug.activity_count, ug.last_activity_at, ug.created_at, ug.updated_at, ug.version, 
+SELECT ug.id, ug.creator_user_id, ug.group_image_url, ug.name, ug.is_private, ug.max_member_count, ug.description, ug.activity_count, ug.last_activity_at, ug.created_at, ug.updated_at, ug.version, ug.is_discoverable, ug.archived_at, ug.deletion_scheduled_at, 
        COALESCE(
            (SELECT jsonb_agg(jsonb_build_object('user_id', tm.user_id, 'first_name', tm.first_name, 'role', tm.role, 'profile_avatar_url', tm.profile_avatar_url))
             FROM top_members tm
@@ -463,6 +513,9 @@ type GetAllGroupsCreatedByUserRow struct {
 	CreatedAt               sql.NullTime
 	UpdatedAt               sql.NullTime
 	Version                 sql.NullInt32
+	IsDiscoverable          bool
+	ArchivedAt              sql.NullTime
+	DeletionScheduledAt     sql.NullTime
 	TopMembers              interface{}
 	TotalMembers            sql.NullInt64
 	LatestMember            interface{}
@@ -494,6 +547,9 @@ func (q *Queries) GetAllGroupsCreatedByUser(ctx context.Context, creatorUserID s
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Version,
+			&i.IsDiscoverable,
+			&i.ArchivedAt,
+			&i.DeletionScheduledAt,
 			&i.TopMembers,
 			&i.TotalMembers,
 			&i.LatestMember,
@@ -517,7 +573,7 @@ func (q *Queries) GetAllGroupsCreatedByUser(ctx context.Context, creatorUserID s
 
 const getAllGroupsUserIsMemberOf = `-- name: GetAllGroupsUserIsMemberOf :many
 WITH user_groups AS (
-    SELECT g.id, g.creator_user_id, g.group_image_url, g.name, g.is_private, g.max_member_count, g.description, g.activity_count, g.last_activity_at, g.created_at, g.updated_at, g.version
+    SELECT g.id, g.creator_user_id, g.group_image_url, g.name, g.is_private, g.max_member_count, g.description, g.activity_count, g.last_activity_at, g.created_at, g.updated_at, g.version, g.is_discoverable, g.archived_at, g.deletion_scheduled_at
     FROM groups g
     JOIN group_memberships gm ON g.id = gm.group_id
     WHERE gm.user_id = $1 AND g.creator_user_id != $1 AND gm.status = 'accepted'
@@ -560,7 +616,7 @@ group_transaction_stats AS (
     GROUP BY gg.group_id
 )
 
-SELECT ug.id, ug.creator_user_id, ug.group_image_url, ug.name, ug.is_private, ug.max_member_count, ug.description, ug.activity_count, ug.last_activity_at, ug.created_at, ug.updated_at, ug.version, 
+SELECT ug.id, ug.creator_user_id, ug.group_image_url, ug.name, ug.is_private, ug.max_member_count, ug.description, ug.activity_count, ug.last_activity_at, ug.created_at, ug.updated_at, ug.version, ug.is_discoverable, ug.archived_at, ug.deletion_scheduled_at, 
        COALESCE(
            (SELECT jsonb_agg(jsonb_build_object('user_id', tm.user_id, 'first_name', tm.first_name, 'role', tm.role, 'profile_avatar_url', tm.profile_avatar_url))
             FROM top_members tm
@@ -596,6 +652,9 @@ type GetAllGroupsUserIsMemberOfRow struct {
 	CreatedAt               sql.NullTime
 	UpdatedAt               sql.NullTime
 	Version                 sql.NullInt32
+	IsDiscoverable          bool
+	ArchivedAt              sql.NullTime
+	DeletionScheduledAt     sql.NullTime
 	TopMembers              interface{}
 	TotalMembers            sql.NullInt64
 	LatestMember            interface{}
@@ -626,6 +685,9 @@ func (q *Queries) GetAllGroupsUserIsMemberOf(ctx context.Context, userID sql.Nul
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Version,
+			&i.IsDiscoverable,
+			&i.ArchivedAt,
+			&i.DeletionScheduledAt,
 			&i.TopMembers,
 			&i.TotalMembers,
 			&i.LatestMember,
@@ -648,9 +710,10 @@ func (q *Queries) GetAllGroupsUserIsMemberOf(ctx context.Context, userID sql.Nul
 
 const getAllPublicGroups = `-- name: GetAllPublicGroups :many
 WITH public_groups AS (
-    SELECT g.id, g.creator_user_id, g.group_image_url, g.name, g.is_private, g.max_member_count, g.description, g.activity_count, g.last_activity_at, g.created_at, g.updated_at, g.version
+    SELECT g.id, g.creator_user_id, g.group_image_url, g.name, g.is_private, g.max_member_count, g.description, g.activity_count, g.last_activity_at, g.created_at, g.updated_at, g.version, g.is_discoverable, g.archived_at, g.deletion_scheduled_at
     FROM groups g
-    WHERE g.is_private = FALSE
+    WHERE (g.is_private = FALSE OR g.is_discoverable = TRUE)   -- Discoverable private groups are listed so users can ask to join
+      AND g.archived_at IS NULL                                -- Archived groups are no longer listed
       AND ($1 = '' OR to_tsvector('simple', g.name) @@ plainto_tsquery('simple', $1))
 ),
 
@@ -702,7 +765,7 @@ user_memberships AS (
     WHERE gm.user_id = $4 AND gm.status = 'accepted'
 )
 
-SELECT pg.id, pg.creator_user_id, pg.group_image_url, pg.name, pg.is_private, pg.max_member_count, pg.description, pg.activity_count, pg.last_activity_at, pg.created_at, pg.updated_at, pg.version, 
+SELECT pg.id, pg.creator_user_id, pg.group_image_url, pg.name, pg.is_private, pg.max_member_count, pg.description, pg.activity_count, pg.last_activity_at, pg.created_at, pg.updated_at, pg.version, pg.is_discoverable, pg.archived_at, pg.deletion_scheduled_at, 
        (SELECT total_public_groups FROM total_count) AS total_public_groups,
        COALESCE(
            (SELECT jsonb_agg(jsonb_build_object('user_id', tm.user_id, 'first_name', tm.first_name, 'role', tm.role, 'profile_avatar_url', tm.profile_avatar_url))
@@ -750,6 +813,9 @@ type GetAllPublicGroupsRow struct {
 	CreatedAt               sql.NullTime
 	UpdatedAt               sql.NullTime
 	Version                 sql.NullInt32
+	IsDiscoverable          bool
+	ArchivedAt              sql.NullTime
+	DeletionScheduledAt     sql.NullTime
 	TotalPublicGroups       int64
 	TopMembers              interface{}
 	TotalMembers            sql.NullInt64
@@ -787,6 +853,9 @@ func (q *Queries) GetAllPublicGroups(ctx context.Context, arg GetAllPublicGroups
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Version,
+			&i.IsDiscoverable,
+			&i.ArchivedAt,
+			&i.DeletionScheduledAt,
 			&i.TotalPublicGroups,
 			&i.TopMembers,
 			&i.TotalMembers,
@@ -811,7 +880,7 @@ func (q *Queries) GetAllPublicGroups(ctx context.Context, arg GetAllPublicGroups
 
 const getDetailedGroupById = `-- name: GetDetailedGroupById :one
 WITH user_groups AS (
-    SELECT g.id, g.creator_user_id, g.group_image_url, g.name, g.is_private, g.max_member_count, g.description, g.activity_count, g.last_activity_at, g.created_at, g.updated_at, g.version
+    SELECT g.id, g.creator_user_id, g.group_image_url, g.name, g.is_private, g.max_member_count, g.description, g.activity_count, g.last_activity_at, g.created_at, g.updated_at, g.version, g.is_discoverable, g.archived_at, g.deletion_scheduled_at
     FROM groups g
     JOIN group_memberships gm ON gm.group_id = g.id
     WHERE g.id = $1 
@@ -843,6 +912,12 @@ group_members AS (
     GROUP BY gm.group_id, gm.user_id, u.first_name, gm.role, u.profile_avatar_url, gm.approval_time
 ),
 
+group_settlements AS (
+    SELECT gt.id, gt.group_id, gt.member_id, gt.recipient_member_id, gt.amount, gt.description, gt.created_at
+    FROM group_transactions gt
+    WHERE gt.group_id = $1 AND gt.transaction_type = 'settlement'
+),
+
 pending_invitations AS (
     SELECT gi.id, gi.group_id, gi.inviter_user_id, gi.invitee_user_email, gi.status, gi.sent_at, gi.responded_at, gi.expiration_date
     FROM group_invitations gi
@@ -883,7 +958,7 @@ goal_with_most_transactions AS (
 )
 
 SELECT 
-    ug.id, ug.creator_user_id, ug.group_image_url, ug.name, ug.is_private, ug.max_member_count, ug.description, ug.activity_count, ug.last_activity_at, ug.created_at, ug.updated_at, ug.version, 
+    ug.id, ug.creator_user_id, ug.group_image_url, ug.name, ug.is_private, ug.max_member_count, ug.description, ug.activity_count, ug.last_activity_at, ug.created_at, ug.updated_at, ug.version, ug.is_discoverable, ug.archived_at, ug.deletion_scheduled_at, 
 
     COALESCE(
         (SELECT jsonb_agg(
@@ -894,7 +969,7 @@ SELECT
                 'profile_avatar_url', gm.profile_avatar_url,
                 'join_date', gm.join_date,              
                 'transaction_count', gm.transaction_count,             
-                'total_transaction_amount', gm.total_transaction_amount 
+                'total_transaction_amount', gm.total_transaction_amount
             )
         )
         FROM group_members gm
@@ -949,7 +1024,23 @@ SELECT
             'current_amount', gmt.current_amount
         )
         FROM goal_with_most_transactions gmt), '{}'::jsonb
-    ) AS goal_with_most_transactions
+    ) AS goal_with_most_transactions,
+
+    COALESCE(
+        (SELECT jsonb_agg(
+            jsonb_build_object(
+                'id', gs.id,
+                'group_id', gs.group_id,
+                'member_id', gs.member_id,
+                'recipient_member_id', gs.recipient_member_id,
+                'amount', gs.amount,
+                'description', gs.description,
+                'created_at', gs.created_at
+            ) ORDER BY gs.created_at DESC, gs.id DESC
+        )
+        FROM group_settlements gs), '[]'::jsonb
+    ) AS settlements,
+    (SELECT COALESCE(SUM(gs.amount), 0)::NUMERIC FROM group_settlements gs) AS total_group_settlements
 
 FROM user_groups ug
 `
@@ -972,12 +1063,17 @@ type GetDetailedGroupByIdRow struct {
 	CreatedAt                sql.NullTime
 	UpdatedAt                sql.NullTime
 	Version                  sql.NullInt32
+	IsDiscoverable           bool
+	ArchivedAt               sql.NullTime
+	DeletionScheduledAt      sql.NullTime
 	Members                  interface{}
 	PendingInvitations       interface{}
 	Goals                    interface{}
 	TotalGroupTransactions   string
 	TotalGroupExpenses       string
 	GoalWithMostTransactions interface{}
+	Settlements              interface{}
+	TotalGroupSettlements    string
 }
 
 func (q *Queries) GetDetailedGroupById(ctx context.Context, arg GetDetailedGroupByIdParams) (GetDetailedGroupByIdRow, error) {
@@ -996,12 +1092,17 @@ func (q *Queries) GetDetailedGroupById(ctx context.Context, arg GetDetailedGroup
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Version,
+		&i.IsDiscoverable,
+		&i.ArchivedAt,
+		&i.DeletionScheduledAt,
 		&i.Members,
 		&i.PendingInvitations,
 		&i.Goals,
 		&i.TotalGroupTransactions,
 		&i.TotalGroupExpenses,
 		&i.GoalWithMostTransactions,
+		&i.Settlements,
+		&i.TotalGroupSettlements,
 	)
 	return i, err
 }
@@ -1019,7 +1120,10 @@ SELECT
     last_activity_at,
     created_at,
     updated_at,
-    version
+    version,
+    is_discoverable,
+    archived_at,
+    deletion_scheduled_at
 FROM groups
 WHERE id = $1
 `
@@ -1040,6 +1144,32 @@ func (q *Queries) GetGroupById(ctx context.Context, id int64) (Group, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Version,
+		&i.IsDiscoverable,
+		&i.ArchivedAt,
+		&i.DeletionScheduledAt,
+	)
+	return i, err
+}
+
+const getGroupExpenseByID = `-- name: GetGroupExpenseByID :one
+SELECT id, group_id, member_id, amount, description, category, created_at, updated_at, split_type
+FROM group_expenses
+WHERE id = $1
+`
+
+func (q *Queries) GetGroupExpenseByID(ctx context.Context, id int64) (GroupExpense, error) {
+	row := q.db.QueryRowContext(ctx, getGroupExpenseByID, id)
+	var i GroupExpense
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.MemberID,
+		&i.Amount,
+		&i.Description,
+		&i.Category,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SplitType,
 	)
 	return i, err
 }
@@ -1260,6 +1390,50 @@ func (q *Queries) GetGroupInvitationById(ctx context.Context, arg GetGroupInvita
 	return i, err
 }
 
+const getGroupMembershipRole = `-- name: GetGroupMembershipRole :one
+SELECT role
+FROM group_memberships
+WHERE group_id = $1
+  AND user_id = $2
+  AND status = 'accepted'
+`
+
+type GetGroupMembershipRoleParams struct {
+	GroupID sql.NullInt64
+	UserID  sql.NullInt64
+}
+
+func (q *Queries) GetGroupMembershipRole(ctx context.Context, arg GetGroupMembershipRoleParams) (NullMembershipRole, error) {
+	row := q.db.QueryRowContext(ctx, getGroupMembershipRole, arg.GroupID, arg.UserID)
+	var role NullMembershipRole
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getGroupTransactionByID = `-- name: GetGroupTransactionByID :one
+SELECT id, goal_id, member_id, amount, description, created_at, updated_at, transaction_type, group_id, recipient_member_id
+FROM group_transactions
+WHERE id = $1
+`
+
+func (q *Queries) GetGroupTransactionByID(ctx context.Context, id int64) (GroupTransaction, error) {
+	row := q.db.QueryRowContext(ctx, getGroupTransactionByID, id)
+	var i GroupTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.GoalID,
+		&i.MemberID,
+		&i.Amount,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TransactionType,
+		&i.GroupID,
+		&i.RecipientMemberID,
+	)
+	return i, err
+}
+
 const getGroupTransactionsByGroupId = `-- name: GetGroupTransactionsByGroupId :many
 WITH transaction_totals AS (
     SELECT 
@@ -1366,6 +1540,20 @@ func (q *Queries) GetGroupTransactionsByGroupId(ctx context.Context, arg GetGrou
 	return items, nil
 }
 
+const lockGroupByID = `-- name: LockGroupByID :one
+SELECT id
+FROM groups
+WHERE id = $1
+FOR UPDATE
+`
+
+// Locks the group until the transaction ends, so members joining it are counted one after the other
+func (q *Queries) LockGroupByID(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, lockGroupByID, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
 const updateExpiredGroupInvitations = `-- name: UpdateExpiredGroupInvitations :exec
 UPDATE group_invitations
 SET status = 'expired'
@@ -1437,7 +1625,8 @@ WHERE target.group_id = $2
       FROM group_memberships AS gm
       WHERE gm.group_id = $2
         AND gm.user_id = $4  -- The ID of the user performing the update
-        AND gm.role IN ('moderator', 'admin')
+        AND gm.role::TEXT = ANY($5::TEXT[])
+        AND gm.status = 'accepted'
   )
 RETURNING updated_at
 `
@@ -1447,6 +1636,7 @@ type UpdateGroupUserRoleParams struct {
 	GroupID  sql.NullInt64
 	UserID   sql.NullInt64
 	UserID_2 sql.NullInt64
+	Column5  []string
 }
 
 func (q *Queries) UpdateGroupUserRole(ctx context.Context, arg UpdateGroupUserRoleParams) (sql.NullTime, error) {
@@ -1455,6 +1645,7 @@ func (q *Queries) UpdateGroupUserRole(ctx context.Context, arg UpdateGroupUserRo
 		arg.GroupID,
 		arg.UserID,
 		arg.UserID_2,
+		pq.Array(arg.Column5),
 	)
 	var updated_at sql.NullTime
 	err := row.Scan(&updated_at)
@@ -1470,10 +1661,19 @@ UPDATE groups SET
     description = $5,
     activity_count = $6,
     last_activity_at = $7,
+    is_discoverable = $11,
     updated_at = NOW(),
     version = version + 1
 WHERE
-    id = $8 AND version = $9 AND creator_user_id = $10
+    id = $8 AND version = $9
+    AND EXISTS (                 -- Only members whose role is one of $11 can change its settings
+        SELECT 1
+        FROM group_memberships gm
+        WHERE gm.group_id = groups.id
+          AND gm.user_id = $10
+          AND gm.role::TEXT = ANY($11::TEXT[])
+          AND gm.status = 'accepted'
+    )
 RETURNING updated_at
 `
 
@@ -1487,7 +1687,9 @@ type UpdateUserGroupParams struct {
 	LastActivityAt sql.NullTime
 	ID             int64
 	Version        sql.NullInt32
-	CreatorUserID  sql.NullInt64
+	UserID         sql.NullInt64
+	IsDiscoverable bool
+	Column11       []string
 }
 
 func (q *Queries) UpdateUserGroup(ctx context.Context, arg UpdateUserGroupParams) (sql.NullTime, error) {
@@ -1501,7 +1703,9 @@ func (q *Queries) UpdateUserGroup(ctx context.Context, arg UpdateUserGroupParams
 		arg.LastActivityAt,
 		arg.ID,
 		arg.Version,
-		arg.CreatorUserID,
+		arg.UserID,
+		arg.IsDiscoverable,
+		pq.Array(arg.Column11),
 	)
 	var updated_at sql.NullTime
 	err := row.Scan(&updated_at)
@@ -159,6 +159,45 @@ func (q *Queries) CreateNewGoalPlan(ctx context.Context, arg CreateNewGoalPlanPa
 	return i, err
 }
 
+const createNewGoalTracking = `-- name: CreateNewGoalTracking :one
+INSERT INTO goal_tracking (user_id, goal_id, tracking_date, contributed_amount, tracking_type)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, updated_at, truncated_tracking_date
+`
+
+type CreateNewGoalTrackingParams struct {
+	UserID            int64
+	GoalID            sql.NullInt64
+	TrackingDate      time.Time
+	ContributedAmount string
+	TrackingType      TrackingTypeEnum
+}
+
+type CreateNewGoalTrackingRow struct {
+	ID                    int64
+	CreatedAt             sql.NullTime
+	UpdatedAt             sql.NullTime
+	TruncatedTrackingDate sql.NullTime
+}
+
+func (q *Queries) CreateNewGoalTracking(ctx context.Context, arg CreateNewGoalTrackingParams) (CreateNewGoalTrackingRow, error) {
+	row := q.db.QueryRowContext(ctx, createNewGoalTracking,
+		arg.UserID,
+		arg.GoalID,
+		arg.TrackingDate,
+		arg.ContributedAmount,
+		arg.TrackingType,
+	)
+	var i CreateNewGoalTrackingRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TruncatedTrackingDate,
+	)
+	return i, err
+}
+
 const deleteBudgetById = `-- name: DeleteBudgetById :one
 DELETE FROM budgets
 WHERE id = $1 AND user_id = $2
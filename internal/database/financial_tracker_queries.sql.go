@@ -9,14 +9,17 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 const createNewDebt = `-- name: CreateNewDebt :one
 INSERT INTO debts (
     user_id, name, amount, remaining_balance, interest_rate, description, 
     due_date, minimum_payment, next_payment_date, estimated_payoff_date, 
-    accrued_interest, interest_last_calculated, total_interest_paid
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    accrued_interest, interest_last_calculated, total_interest_paid,
+    compounding_frequency, interest_rate_type, late_fee, grace_period_days
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 RETURNING id, created_at, updated_at
 `
 
@@ -34,6 +37,10 @@ type CreateNewDebtParams struct {
 	AccruedInterest        sql.NullString
 	InterestLastCalculated sql.NullTime
 	TotalInterestPaid      sql.NullString
+	CompoundingFrequency   DebtCompoundingFrequencyEnum
+	InterestRateType       DebtInterestRateTypeEnum
+	LateFee                string
+	GracePeriodDays        int32
 }
 
 type CreateNewDebtRow struct {
@@ -57,12 +64,47 @@ func (q *Queries) CreateNewDebt(ctx context.Context, arg CreateNewDebtParams) (C
 		arg.AccruedInterest,
 		arg.InterestLastCalculated,
 		arg.TotalInterestPaid,
+		arg.CompoundingFrequency,
+		arg.InterestRateType,
+		arg.LateFee,
+		arg.GracePeriodDays,
 	)
 	var i CreateNewDebtRow
 	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
 	return i, err
 }
 
+const createNewDebtLateFee = `-- name: CreateNewDebtLateFee :one
+INSERT INTO debt_late_fees (
+    debt_id, user_id, amount, missed_due_date
+) VALUES ($1, $2, $3, $4)
+RETURNING id, created_at
+`
+
+type CreateNewDebtLateFeeParams struct {
+	DebtID        int64
+	UserID        int64
+	Amount        string
+	MissedDueDate time.Time
+}
+
+type CreateNewDebtLateFeeRow struct {
+	ID        int64
+	CreatedAt sql.NullTime
+}
+
+func (q *Queries) CreateNewDebtLateFee(ctx context.Context, arg CreateNewDebtLateFeeParams) (CreateNewDebtLateFeeRow, error) {
+	row := q.db.QueryRowContext(ctx, createNewDebtLateFee,
+		arg.DebtID,
+		arg.UserID,
+		arg.Amount,
+		arg.MissedDueDate,
+	)
+	var i CreateNewDebtLateFeeRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
 const createNewDebtPayment = `-- name: CreateNewDebtPayment :one
 INSERT INTO debtpayments (
     debt_id,
@@ -70,14 +112,16 @@ INSERT INTO debtpayments (
     payment_amount,
     payment_date,
     interest_payment,
-    principal_payment
+    principal_payment,
+    account_id
 ) VALUES (
     $1, -- debt_id
     $2, -- user_id
     $3, -- payment_amount
     $4, -- payment_date
     $5, -- interest_payment
-    $6  -- principal_payment
+    $6, -- principal_payment
+    $7  -- account_id
 )
 RETURNING id, created_at
 `
@@ -89,6 +133,7 @@ type CreateNewDebtPaymentParams struct {
 	PaymentDate      time.Time
 	InterestPayment  string
 	PrincipalPayment string
+	AccountID        sql.NullInt64
 }
 
 type CreateNewDebtPaymentRow struct {
@@ -104,6 +149,7 @@ func (q *Queries) CreateNewDebtPayment(ctx context.Context, arg CreateNewDebtPay
 		arg.PaymentDate,
 		arg.InterestPayment,
 		arg.PrincipalPayment,
+		arg.AccountID,
 	)
 	var i CreateNewDebtPaymentRow
 	err := row.Scan(&i.ID, &i.CreatedAt)
@@ -119,9 +165,10 @@ INSERT INTO expenses (
     amount, 
     is_recurring, 
     description, 
-    date_occurred
+    date_occurred,
+    account_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
 RETURNING id, created_at, updated_at
 `
@@ -135,6 +182,7 @@ type CreateNewExpenseParams struct {
 	IsRecurring  bool
 	Description  sql.NullString
 	DateOccurred time.Time
+	AccountID    sql.NullInt64
 }
 
 type CreateNewExpenseRow struct {
@@ -153,6 +201,7 @@ func (q *Queries) CreateNewExpense(ctx context.Context, arg CreateNewExpensePara
 		arg.IsRecurring,
 		arg.Description,
 		arg.DateOccurred,
+		arg.AccountID,
 	)
 	var i CreateNewExpenseRow
 	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
@@ -168,9 +217,10 @@ const createNewIncome = `-- name: CreateNewIncome :one
         amount, 
         exchange_rate, 
         description, 
-        date_received
+        date_received,
+        account_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING id, created_at, updated_at
 `
 
@@ -183,6 +233,7 @@ type CreateNewIncomeParams struct {
 	ExchangeRate         string
 	Description          sql.NullString
 	DateReceived         time.Time
+	AccountID            sql.NullInt64
 }
 
 type CreateNewIncomeRow struct {
@@ -201,6 +252,7 @@ func (q *Queries) CreateNewIncome(ctx context.Context, arg CreateNewIncomeParams
 		arg.ExchangeRate,
 		arg.Description,
 		arg.DateReceived,
+		arg.AccountID,
 	)
 	var i CreateNewIncomeRow
 	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
@@ -209,22 +261,32 @@ func (q *Queries) CreateNewIncome(ctx context.Context, arg CreateNewIncomeParams
 
 const createNewRecurringExpense = `-- name: CreateNewRecurringExpense :one
 INSERT INTO recurring_expenses (
-    user_id, budget_id, amount,name, description, recurrence_interval,projected_amount, next_occurrence
+    user_id, budget_id, amount,name, description, recurrence_interval,projected_amount, next_occurrence,
+    interval_count, day_of_month, start_date, last_occurrence, end_date, max_occurrences, occurrence_count,
+    first_occurrence_recorded
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
 )
 RETURNING id, created_at, updated_at
 `
 
 type CreateNewRecurringExpenseParams struct {
-	UserID             int64
-	BudgetID           int64
-	Amount             string
-	Name               string
-	Description        sql.NullString
-	RecurrenceInterval RecurrenceIntervalEnum
-	ProjectedAmount    string
-	NextOccurrence     time.Time
+	UserID                  int64
+	BudgetID                int64
+	Amount                  string
+	Name                    string
+	Description             sql.NullString
+	RecurrenceInterval      RecurrenceIntervalEnum
+	ProjectedAmount         string
+	NextOccurrence          time.Time
+	IntervalCount           int32
+	DayOfMonth              sql.NullInt16
+	StartDate               time.Time
+	LastOccurrence          sql.NullTime
+	EndDate                 sql.NullTime
+	MaxOccurrences          sql.NullInt32
+	OccurrenceCount         int32
+	FirstOccurrenceRecorded bool
 }
 
 type CreateNewRecurringExpenseRow struct {
@@ -243,12 +305,105 @@ func (q *Queries) CreateNewRecurringExpense(ctx context.Context, arg CreateNewRe
 		arg.RecurrenceInterval,
 		arg.ProjectedAmount,
 		arg.NextOccurrence,
+		arg.IntervalCount,
+		arg.DayOfMonth,
+		arg.StartDate,
+		arg.LastOccurrence,
+		arg.EndDate,
+		arg.MaxOccurrences,
+		arg.OccurrenceCount,
+		arg.FirstOccurrenceRecorded,
 	)
 	var i CreateNewRecurringExpenseRow
 	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
 	return i, err
 }
 
+const createNewRecurringIncome = `-- name: CreateNewRecurringIncome :one
+INSERT INTO recurring_incomes (
+    user_id, source, original_currency_code, amount_original, description,
+    recurrence_interval, next_occurrence, end_date, goal_id, goal_allocation_percentage, day_of_month
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+RETURNING id, created_at, updated_at
+`
+
+type CreateNewRecurringIncomeParams struct {
+	UserID                   int64
+	Source                   string
+	OriginalCurrencyCode     string
+	AmountOriginal           string
+	Description              sql.NullString
+	RecurrenceInterval       RecurrenceIntervalEnum
+	NextOccurrence           time.Time
+	EndDate                  sql.NullTime
+	GoalID                   sql.NullInt64
+	GoalAllocationPercentage string
+	DayOfMonth               int32
+}
+
+type CreateNewRecurringIncomeRow struct {
+	ID        int64
+	CreatedAt sql.NullTime
+	UpdatedAt sql.NullTime
+}
+
+func (q *Queries) CreateNewRecurringIncome(ctx context.Context, arg CreateNewRecurringIncomeParams) (CreateNewRecurringIncomeRow, error) {
+	row := q.db.QueryRowContext(ctx, createNewRecurringIncome,
+		arg.UserID,
+		arg.Source,
+		arg.OriginalCurrencyCode,
+		arg.AmountOriginal,
+		arg.Description,
+		arg.RecurrenceInterval,
+		arg.NextOccurrence,
+		arg.EndDate,
+		arg.GoalID,
+		arg.GoalAllocationPercentage,
+		arg.DayOfMonth,
+	)
+	var i CreateNewRecurringIncomeRow
+	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
+	return i, err
+}
+
+const deleteExpenseByID = `-- name: DeleteExpenseByID :one
+DELETE FROM expenses
+WHERE id = $1 AND user_id = $2
+RETURNING id
+`
+
+type DeleteExpenseByIDParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) DeleteExpenseByID(ctx context.Context, arg DeleteExpenseByIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, deleteExpenseByID, arg.ID, arg.UserID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const deleteIncomeByID = `-- name: DeleteIncomeByID :one
+DELETE FROM income
+WHERE id = $1 AND user_id = $2
+RETURNING id
+`
+
+type DeleteIncomeByIDParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) DeleteIncomeByID(ctx context.Context, arg DeleteIncomeByIDParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, deleteIncomeByID, arg.ID, arg.UserID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
 const getAllDebtsByUserID = `-- name: GetAllDebtsByUserID :many
 SELECT 
     id,
@@ -268,6 +423,11 @@ SELECT
     interest_last_calculated,
     last_payment_date,
     total_interest_paid,
+    compounding_frequency,
+    interest_rate_type,
+    late_fee,
+    grace_period_days,
+    last_overdue_due_date,
     COUNT(*) OVER() AS total_debts,
     CAST(SUM(amount) OVER() AS NUMERIC) AS total_amounts,                -- Cast after SUM
     CAST(SUM(remaining_balance) OVER() AS NUMERIC) AS total_remaining_balances
@@ -303,6 +463,11 @@ type GetAllDebtsByUserIDRow struct {
 	InterestLastCalculated sql.NullTime
 	LastPaymentDate        sql.NullTime
 	TotalInterestPaid      sql.NullString
+	CompoundingFrequency   DebtCompoundingFrequencyEnum
+	InterestRateType       DebtInterestRateTypeEnum
+	LateFee                string
+	GracePeriodDays        int32
+	LastOverdueDueDate     sql.NullTime
 	TotalDebts             int64
 	TotalAmounts           string
 	TotalRemainingBalances string
@@ -340,6 +505,11 @@ func (q *Queries) GetAllDebtsByUserID(ctx context.Context, arg GetAllDebtsByUser
 			&i.InterestLastCalculated,
 			&i.LastPaymentDate,
 			&i.TotalInterestPaid,
+			&i.CompoundingFrequency,
+			&i.InterestRateType,
+			&i.LateFee,
+			&i.GracePeriodDays,
+			&i.LastOverdueDueDate,
 			&i.TotalDebts,
 			&i.TotalAmounts,
 			&i.TotalRemainingBalances,
@@ -357,6 +527,97 @@ func (q *Queries) GetAllDebtsByUserID(ctx context.Context, arg GetAllDebtsByUser
 	return items, nil
 }
 
+const getAllDebtsDueForInterestAccrual = `-- name: GetAllDebtsDueForInterestAccrual :many
+SELECT 
+    id, 
+    user_id, 
+    name, 
+    amount, 
+    remaining_balance, 
+    interest_rate, 
+    description, 
+    due_date, 
+    minimum_payment, 
+    created_at, 
+    updated_at, 
+    next_payment_date, 
+    estimated_payoff_date, 
+    accrued_interest, 
+    interest_last_calculated, 
+    last_payment_date, 
+    total_interest_paid,
+    compounding_frequency,
+    interest_rate_type,
+    late_fee,
+    grace_period_days,
+    last_overdue_due_date
+FROM 
+    debts
+WHERE 
+    remaining_balance > 0  -- Debt is not fully paid
+AND (
+    interest_last_calculated IS NULL OR interest_last_calculated < CURRENT_DATE -- Interest has not been accrued today
+    OR (
+        -- The earliest missed payment that was not charged is overdue past the grace period
+        GREATEST(next_payment_date, (last_overdue_due_date + INTERVAL '1 month')::date) + grace_period_days < CURRENT_DATE
+    )
+)
+AND id > $1
+ORDER BY id ASC
+LIMIT $2
+`
+
+type GetAllDebtsDueForInterestAccrualParams struct {
+	ID    int64
+	Limit int32
+}
+
+func (q *Queries) GetAllDebtsDueForInterestAccrual(ctx context.Context, arg GetAllDebtsDueForInterestAccrualParams) ([]Debt, error) {
+	rows, err := q.db.QueryContext(ctx, getAllDebtsDueForInterestAccrual, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Debt
+	for rows.Next() {
+		var i Debt
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Amount,
+			&i.RemainingBalance,
+			&i.InterestRate,
+			&i.Description,
+			&i.DueDate,
+			&i.MinimumPayment,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.NextPaymentDate,
+			&i.EstimatedPayoffDate,
+			&i.AccruedInterest,
+			&i.InterestLastCalculated,
+			&i.LastPaymentDate,
+			&i.TotalInterestPaid,
+			&i.CompoundingFrequency,
+			&i.InterestRateType,
+			&i.LateFee,
+			&i.GracePeriodDays,
+			&i.LastOverdueDueDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAllExpensesByUserID = `-- name: GetAllExpensesByUserID :many
 SELECT 
     e.id,
@@ -370,11 +631,32 @@ SELECT
     e.date_occurred,
     e.created_at,
     e.updated_at,
+    e.account_id,
+    e.is_cleared,
+    COALESCE((
+        SELECT ARRAY_AGG(t.name ORDER BY t.name)
+        FROM expense_tags et
+        JOIN tags t ON t.id = et.tag_id
+        WHERE et.expense_id = e.id
+    ), '{}')::TEXT[] AS tags,
+    COALESCE((
+        SELECT ARRAY_AGG(etc.tax_category::TEXT ORDER BY etc.tax_category)
+        FROM expense_tax_categories etc
+        WHERE etc.expense_id = e.id
+    ), '{}')::TEXT[] AS tax_categories,
     COUNT(*) OVER () AS total_count
 FROM 
     expenses e
 WHERE e.user_id = $1  -- Filter by user ID
 AND ($2 = '' OR to_tsvector('simple', e.name) @@ plainto_tsquery('simple', $2))
+AND (COALESCE(CARDINALITY($5::TEXT[]), 0) = 0 OR e.id IN (
+    SELECT et.expense_id
+    FROM expense_tags et
+    JOIN tags t ON t.id = et.tag_id
+    WHERE t.user_id = $1 AND t.name = ANY($5::TEXT[])
+    GROUP BY et.expense_id
+    HAVING COUNT(*) = CARDINALITY($5::TEXT[])
+))
 ORDER BY 
     e.date_occurred DESC
 LIMIT 
@@ -386,21 +668,26 @@ type GetAllExpensesByUserIDParams struct {
 	Column2 interface{}
 	Limit   int32
 	Offset  int32
+	Column5 []string
 }
 
 type GetAllExpensesByUserIDRow struct {
-	ID           int64
-	UserID       int64
-	BudgetID     int64
-	Name         string
-	Category     string
-	Amount       string
-	IsRecurring  bool
-	Description  sql.NullString
-	DateOccurred time.Time
-	CreatedAt    sql.NullTime
-	UpdatedAt    sql.NullTime
-	TotalCount   int64
+	ID            int64
+	UserID        int64
+	BudgetID      int64
+	Name          string
+	Category      string
+	Amount        string
+	IsRecurring   bool
+	Description   sql.NullString
+	DateOccurred  time.Time
+	CreatedAt     sql.NullTime
+	UpdatedAt     sql.NullTime
+	AccountID     sql.NullInt64
+	IsCleared     bool
+	Tags          []string
+	TaxCategories []string
+	TotalCount    int64
 }
 
 func (q *Queries) GetAllExpensesByUserID(ctx context.Context, arg GetAllExpensesByUserIDParams) ([]GetAllExpensesByUserIDRow, error) {
@@ -409,6 +696,7 @@ func (q *Queries) GetAllExpensesByUserID(ctx context.Context, arg GetAllExpenses
 		arg.Column2,
 		arg.Limit,
 		arg.Offset,
+		pq.Array(arg.Column5),
 	)
 	if err != nil {
 		return nil, err
@@ -429,6 +717,10 @@ func (q *Queries) GetAllExpensesByUserID(ctx context.Context, arg GetAllExpenses
 			&i.DateOccurred,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.AccountID,
+			&i.IsCleared,
+			pq.Array(&i.Tags),
+			pq.Array(&i.TaxCategories),
 			&i.TotalCount,
 		); err != nil {
 			return nil, err
@@ -457,12 +749,33 @@ WITH income_data AS (
         income.description,
         income.date_received,
         income.created_at,
-        income.updated_at
+        income.updated_at,
+        income.account_id,
+        income.is_cleared,
+        COALESCE((
+            SELECT ARRAY_AGG(t.name ORDER BY t.name)
+            FROM income_tags it
+            JOIN tags t ON t.id = it.tag_id
+            WHERE it.income_id = income.id
+        ), '{}')::TEXT[] AS tags,
+        COALESCE((
+            SELECT ARRAY_AGG(itc.tax_category::TEXT ORDER BY itc.tax_category)
+            FROM income_tax_categories itc
+            WHERE itc.income_id = income.id
+        ), '{}')::TEXT[] AS tax_categories
     FROM 
         income
     WHERE 
         income.user_id = $1
         AND ($2 = '' OR to_tsvector('simple', income.source) @@ plainto_tsquery('simple', $2))
+        AND (COALESCE(CARDINALITY($5::TEXT[]), 0) = 0 OR income.id IN (
+            SELECT it.income_id
+            FROM income_tags it
+            JOIN tags t ON t.id = it.tag_id
+            WHERE t.user_id = $1 AND t.name = ANY($5::TEXT[])
+            GROUP BY it.income_id
+            HAVING COUNT(*) = CARDINALITY($5::TEXT[])
+        ))
     ORDER BY 
         income.date_received DESC
     LIMIT $3 OFFSET $4
@@ -481,7 +794,7 @@ most_used_currency AS (
     LIMIT 1
 )
 SELECT 
-    i.id, i.user_id, i.source, i.original_currency_code, i.amount_original, i.amount, i.exchange_rate, i.description, i.date_received, i.created_at, i.updated_at,
+    i.id, i.user_id, i.source, i.original_currency_code, i.amount_original, i.amount, i.exchange_rate, i.description, i.date_received, i.created_at, i.updated_at, i.account_id, i.is_cleared, i.tags, i.tax_categories,
     t.total_income_amount,
     m.original_currency_code AS most_used_currency,
     COUNT(*) OVER () AS total_rows
@@ -496,6 +809,7 @@ type GetAllIncomesByUserIDParams struct {
 	Column2 interface{}
 	Limit   int32
 	Offset  int32
+	Column5 []string
 }
 
 type GetAllIncomesByUserIDRow struct {
@@ -510,6 +824,10 @@ type GetAllIncomesByUserIDRow struct {
 	DateReceived         time.Time
 	CreatedAt            sql.NullTime
 	UpdatedAt            sql.NullTime
+	AccountID            sql.NullInt64
+	IsCleared            bool
+	Tags                 []string
+	TaxCategories        []string
 	TotalIncomeAmount    string
 	MostUsedCurrency     string
 	TotalRows            int64
@@ -521,6 +839,7 @@ func (q *Queries) GetAllIncomesByUserID(ctx context.Context, arg GetAllIncomesBy
 		arg.Column2,
 		arg.Limit,
 		arg.Offset,
+		pq.Array(arg.Column5),
 	)
 	if err != nil {
 		return nil, err
@@ -541,6 +860,10 @@ func (q *Queries) GetAllIncomesByUserID(ctx context.Context, arg GetAllIncomesBy
 			&i.DateReceived,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.AccountID,
+			&i.IsCleared,
+			pq.Array(&i.Tags),
+			pq.Array(&i.TaxCategories),
 			&i.TotalIncomeAmount,
 			&i.MostUsedCurrency,
 			&i.TotalRows,
@@ -558,102 +881,6 @@ func (q *Queries) GetAllIncomesByUserID(ctx context.Context, arg GetAllIncomesBy
 	return items, nil
 }
 
-const getAllOverdueDebts = `-- name: GetAllOverdueDebts :many
-SELECT 
-    COUNT(*) OVER() AS total_count,
-    id, 
-    user_id, 
-    name, 
-    amount, 
-    remaining_balance, 
-    interest_rate, 
-    description, 
-    due_date, 
-    minimum_payment, 
-    created_at, 
-    updated_at, 
-    next_payment_date, 
-    estimated_payoff_date, 
-    accrued_interest, 
-    interest_last_calculated, 
-    last_payment_date, 
-    total_interest_paid
-FROM 
-    debts
-WHERE 
-    remaining_balance > 0  -- Debt is not fully paid
-AND (interest_last_calculated IS NULL OR interest_last_calculated < CURRENT_DATE) -- Interest calculation is overdue
-LIMIT $1 OFFSET $2
-`
-
-type GetAllOverdueDebtsParams struct {
-	Limit  int32
-	Offset int32
-}
-
-type GetAllOverdueDebtsRow struct {
-	TotalCount             int64
-	ID                     int64
-	UserID                 int64
-	Name                   string
-	Amount                 string
-	RemainingBalance       string
-	InterestRate           sql.NullString
-	Description            sql.NullString
-	DueDate                time.Time
-	MinimumPayment         string
-	CreatedAt              sql.NullTime
-	UpdatedAt              sql.NullTime
-	NextPaymentDate        time.Time
-	EstimatedPayoffDate    sql.NullTime
-	AccruedInterest        sql.NullString
-	InterestLastCalculated sql.NullTime
-	LastPaymentDate        sql.NullTime
-	TotalInterestPaid      sql.NullString
-}
-
-func (q *Queries) GetAllOverdueDebts(ctx context.Context, arg GetAllOverdueDebtsParams) ([]GetAllOverdueDebtsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getAllOverdueDebts, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetAllOverdueDebtsRow
-	for rows.Next() {
-		var i GetAllOverdueDebtsRow
-		if err := rows.Scan(
-			&i.TotalCount,
-			&i.ID,
-			&i.UserID,
-			&i.Name,
-			&i.Amount,
-			&i.RemainingBalance,
-			&i.InterestRate,
-			&i.Description,
-			&i.DueDate,
-			&i.MinimumPayment,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.NextPaymentDate,
-			&i.EstimatedPayoffDate,
-			&i.AccruedInterest,
-			&i.InterestLastCalculated,
-			&i.LastPaymentDate,
-			&i.TotalInterestPaid,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const getAllRecurringExpensesByUserID = `-- name: GetAllRecurringExpensesByUserID :many
 SELECT 
     re.id,
@@ -668,6 +895,14 @@ SELECT
     re.next_occurrence,
     re.created_at,
     re.updated_at,
+    re.interval_count,
+    re.day_of_month,
+    re.start_date,
+    re.last_occurrence,
+    re.end_date,
+    re.max_occurrences,
+    re.occurrence_count,
+    re.is_paused,
     COALESCE(SUM(e.amount), 0)::NUMERIC AS total_expenses,
     COUNT(*) OVER() AS total_count
 FROM 
@@ -720,6 +955,14 @@ type GetAllRecurringExpensesByUserIDRow struct {
 	NextOccurrence     time.Time
 	CreatedAt          sql.NullTime
 	UpdatedAt          sql.NullTime
+	IntervalCount      int32
+	DayOfMonth         sql.NullInt16
+	StartDate          time.Time
+	LastOccurrence     sql.NullTime
+	EndDate            sql.NullTime
+	MaxOccurrences     sql.NullInt32
+	OccurrenceCount    int32
+	IsPaused           bool
 	TotalExpenses      string
 	TotalCount         int64
 }
@@ -751,6 +994,14 @@ func (q *Queries) GetAllRecurringExpensesByUserID(ctx context.Context, arg GetAl
 			&i.NextOccurrence,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IntervalCount,
+			&i.DayOfMonth,
+			&i.StartDate,
+			&i.LastOccurrence,
+			&i.EndDate,
+			&i.MaxOccurrences,
+			&i.OccurrenceCount,
+			&i.IsPaused,
 			&i.TotalExpenses,
 			&i.TotalCount,
 		); err != nil {
@@ -769,7 +1020,6 @@ func (q *Queries) GetAllRecurringExpensesByUserID(ctx context.Context, arg GetAl
 
 const getAllRecurringExpensesDueForProcessing = `-- name: GetAllRecurringExpensesDueForProcessing :many
 SELECT
-    COUNT(*) OVER() AS total_count,
     id, 
     user_id, 
     budget_id, 
@@ -780,20 +1030,31 @@ SELECT
     projected_amount,
     next_occurrence, 
     created_at, 
-    updated_at
+    updated_at,
+    interval_count,
+    day_of_month,
+    start_date,
+    last_occurrence,
+    end_date,
+    max_occurrences,
+    occurrence_count,
+    is_paused
 FROM recurring_expenses
 WHERE next_occurrence <= CURRENT_DATE
-ORDER BY next_occurrence ASC
-LIMIT $1 OFFSET $2
+AND is_paused = FALSE
+AND (end_date IS NULL OR next_occurrence <= end_date)
+AND (max_occurrences IS NULL OR occurrence_count < max_occurrences)
+AND id > $1
+ORDER BY id ASC
+LIMIT $2
 `
 
 type GetAllRecurringExpensesDueForProcessingParams struct {
-	Limit  int32
-	Offset int32
+	ID    int64
+	Limit int32
 }
 
 type GetAllRecurringExpensesDueForProcessingRow struct {
-	TotalCount         int64
 	ID                 int64
 	UserID             int64
 	BudgetID           int64
@@ -805,10 +1066,18 @@ type GetAllRecurringExpensesDueForProcessingRow struct {
 	NextOccurrence     time.Time
 	CreatedAt          sql.NullTime
 	UpdatedAt          sql.NullTime
+	IntervalCount      int32
+	DayOfMonth         sql.NullInt16
+	StartDate          time.Time
+	LastOccurrence     sql.NullTime
+	EndDate            sql.NullTime
+	MaxOccurrences     sql.NullInt32
+	OccurrenceCount    int32
+	IsPaused           bool
 }
 
 func (q *Queries) GetAllRecurringExpensesDueForProcessing(ctx context.Context, arg GetAllRecurringExpensesDueForProcessingParams) ([]GetAllRecurringExpensesDueForProcessingRow, error) {
-	rows, err := q.db.QueryContext(ctx, getAllRecurringExpensesDueForProcessing, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, getAllRecurringExpensesDueForProcessing, arg.ID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -817,7 +1086,6 @@ func (q *Queries) GetAllRecurringExpensesDueForProcessing(ctx context.Context, a
 	for rows.Next() {
 		var i GetAllRecurringExpensesDueForProcessingRow
 		if err := rows.Scan(
-			&i.TotalCount,
 			&i.ID,
 			&i.UserID,
 			&i.BudgetID,
@@ -829,6 +1097,202 @@ func (q *Queries) GetAllRecurringExpensesDueForProcessing(ctx context.Context, a
 			&i.NextOccurrence,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.IntervalCount,
+			&i.DayOfMonth,
+			&i.StartDate,
+			&i.LastOccurrence,
+			&i.EndDate,
+			&i.MaxOccurrences,
+			&i.OccurrenceCount,
+			&i.IsPaused,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllRecurringIncomesByUserID = `-- name: GetAllRecurringIncomesByUserID :many
+SELECT
+    COUNT(*) OVER() AS total_count,
+    id,
+    user_id,
+    source,
+    original_currency_code,
+    amount_original,
+    description,
+    recurrence_interval,
+    next_occurrence,
+    end_date,
+    goal_id,
+    goal_allocation_percentage,
+    last_posted_at,
+    created_at,
+    updated_at,
+    day_of_month
+FROM recurring_incomes
+WHERE
+    user_id = $1
+    AND ($2 = '' OR to_tsvector('simple', source) @@ plainto_tsquery('simple', $2))
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4
+`
+
+type GetAllRecurringIncomesByUserIDParams struct {
+	UserID  int64
+	Column2 interface{}
+	Limit   int32
+	Offset  int32
+}
+
+type GetAllRecurringIncomesByUserIDRow struct {
+	TotalCount               int64
+	ID                       int64
+	UserID                   int64
+	Source                   string
+	OriginalCurrencyCode     string
+	AmountOriginal           string
+	Description              sql.NullString
+	RecurrenceInterval       RecurrenceIntervalEnum
+	NextOccurrence           time.Time
+	EndDate                  sql.NullTime
+	GoalID                   sql.NullInt64
+	GoalAllocationPercentage string
+	LastPostedAt             sql.NullTime
+	CreatedAt                sql.NullTime
+	UpdatedAt                sql.NullTime
+	DayOfMonth               int32
+}
+
+func (q *Queries) GetAllRecurringIncomesByUserID(ctx context.Context, arg GetAllRecurringIncomesByUserIDParams) ([]GetAllRecurringIncomesByUserIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllRecurringIncomesByUserID,
+		arg.UserID,
+		arg.Column2,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllRecurringIncomesByUserIDRow
+	for rows.Next() {
+		var i GetAllRecurringIncomesByUserIDRow
+		if err := rows.Scan(
+			&i.TotalCount,
+			&i.ID,
+			&i.UserID,
+			&i.Source,
+			&i.OriginalCurrencyCode,
+			&i.AmountOriginal,
+			&i.Description,
+			&i.RecurrenceInterval,
+			&i.NextOccurrence,
+			&i.EndDate,
+			&i.GoalID,
+			&i.GoalAllocationPercentage,
+			&i.LastPostedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DayOfMonth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllRecurringIncomesDueForProcessing = `-- name: GetAllRecurringIncomesDueForProcessing :many
+SELECT
+    ri.id,
+    ri.user_id,
+    ri.source,
+    ri.original_currency_code,
+    ri.amount_original,
+    ri.description,
+    ri.recurrence_interval,
+    ri.next_occurrence,
+    ri.end_date,
+    ri.goal_id,
+    ri.goal_allocation_percentage,
+    ri.last_posted_at,
+    ri.created_at,
+    ri.updated_at,
+    ri.day_of_month,
+    u.currency_code AS user_currency_code
+FROM recurring_incomes ri
+JOIN users u ON ri.user_id = u.id
+WHERE ri.next_occurrence <= CURRENT_DATE
+AND (ri.end_date IS NULL OR ri.next_occurrence <= ri.end_date)
+AND ri.id > $1
+ORDER BY ri.id ASC
+LIMIT $2
+`
+
+type GetAllRecurringIncomesDueForProcessingParams struct {
+	ID    int64
+	Limit int32
+}
+
+type GetAllRecurringIncomesDueForProcessingRow struct {
+	ID                       int64
+	UserID                   int64
+	Source                   string
+	OriginalCurrencyCode     string
+	AmountOriginal           string
+	Description              sql.NullString
+	RecurrenceInterval       RecurrenceIntervalEnum
+	NextOccurrence           time.Time
+	EndDate                  sql.NullTime
+	GoalID                   sql.NullInt64
+	GoalAllocationPercentage string
+	LastPostedAt             sql.NullTime
+	CreatedAt                sql.NullTime
+	UpdatedAt                sql.NullTime
+	DayOfMonth               int32
+	UserCurrencyCode         sql.NullString
+}
+
+func (q *Queries) GetAllRecurringIncomesDueForProcessing(ctx context.Context, arg GetAllRecurringIncomesDueForProcessingParams) ([]GetAllRecurringIncomesDueForProcessingRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllRecurringIncomesDueForProcessing, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllRecurringIncomesDueForProcessingRow
+	for rows.Next() {
+		var i GetAllRecurringIncomesDueForProcessingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Source,
+			&i.OriginalCurrencyCode,
+			&i.AmountOriginal,
+			&i.Description,
+			&i.RecurrenceInterval,
+			&i.NextOccurrence,
+			&i.EndDate,
+			&i.GoalID,
+			&i.GoalAllocationPercentage,
+			&i.LastPostedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DayOfMonth,
+			&i.UserCurrencyCode,
 		); err != nil {
 			return nil, err
 		}
@@ -861,7 +1325,12 @@ SELECT
     accrued_interest, 
     interest_last_calculated, 
     last_payment_date, 
-    total_interest_paid
+    total_interest_paid,
+    compounding_frequency,
+    interest_rate_type,
+    late_fee,
+    grace_period_days,
+    last_overdue_due_date
 FROM debts
 WHERE id = $1
 `
@@ -887,6 +1356,11 @@ func (q *Queries) GetDebtByID(ctx context.Context, id int64) (Debt, error) {
 		&i.InterestLastCalculated,
 		&i.LastPaymentDate,
 		&i.TotalInterestPaid,
+		&i.CompoundingFrequency,
+		&i.InterestRateType,
+		&i.LateFee,
+		&i.GracePeriodDays,
+		&i.LastOverdueDueDate,
 	)
 	return i, err
 }
@@ -981,6 +1455,40 @@ func (q *Queries) GetDebtPaymentsByDebtUserID(ctx context.Context, arg GetDebtPa
 	return items, nil
 }
 
+const getDebtPayoffPlanByUserID = `-- name: GetDebtPayoffPlanByUserID :one
+SELECT
+    id,
+    user_id,
+    strategy,
+    monthly_budget,
+    custom_order,
+    projected_payoff_date,
+    total_months,
+    total_interest,
+    created_at,
+    updated_at
+FROM debt_payoff_plans
+WHERE user_id = $1
+`
+
+func (q *Queries) GetDebtPayoffPlanByUserID(ctx context.Context, userID int64) (DebtPayoffPlan, error) {
+	row := q.db.QueryRowContext(ctx, getDebtPayoffPlanByUserID, userID)
+	var i DebtPayoffPlan
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Strategy,
+		&i.MonthlyBudget,
+		pq.Array(&i.CustomOrder),
+		&i.ProjectedPayoffDate,
+		&i.TotalMonths,
+		&i.TotalInterest,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getExpenseByID = `-- name: GetExpenseByID :one
 SELECT 
     id, 
@@ -993,7 +1501,9 @@ SELECT
     description, 
     date_occurred, 
     created_at, 
-    updated_at
+    updated_at,
+    account_id,
+    is_cleared
 FROM expenses
 WHERE id = $1 AND user_id = $2
 `
@@ -1018,6 +1528,8 @@ func (q *Queries) GetExpenseByID(ctx context.Context, arg GetExpenseByIDParams)
 		&i.DateOccurred,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AccountID,
+		&i.IsCleared,
 	)
 	return i, err
 }
@@ -1034,7 +1546,9 @@ SELECT
     description,
     date_received,
     created_at,
-    updated_at
+    updated_at,
+    account_id,
+    is_cleared
 FROM income
 WHERE id = $1 AND user_id = $2
 `
@@ -1059,6 +1573,8 @@ func (q *Queries) GetIncomeByID(ctx context.Context, arg GetIncomeByIDParams) (I
 		&i.DateReceived,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AccountID,
+		&i.IsCleared,
 	)
 	return i, err
 }
@@ -1075,7 +1591,16 @@ SELECT
     projected_amount,
     next_occurrence, 
     created_at, 
-    updated_at
+    updated_at,
+    interval_count,
+    day_of_month,
+    start_date,
+    last_occurrence,
+    end_date,
+    max_occurrences,
+    occurrence_count,
+    is_paused,
+    first_occurrence_recorded
 FROM recurring_expenses
 WHERE id = $1 AND user_id = $2
 `
@@ -1100,10 +1625,113 @@ func (q *Queries) GetRecurringExpenseByID(ctx context.Context, arg GetRecurringE
 		&i.NextOccurrence,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.IntervalCount,
+		&i.DayOfMonth,
+		&i.StartDate,
+		&i.LastOccurrence,
+		&i.EndDate,
+		&i.MaxOccurrences,
+		&i.OccurrenceCount,
+		&i.IsPaused,
+		&i.FirstOccurrenceRecorded,
 	)
 	return i, err
 }
 
+const getRecurringIncomeByID = `-- name: GetRecurringIncomeByID :one
+SELECT
+    id,
+    user_id,
+    source,
+    original_currency_code,
+    amount_original,
+    description,
+    recurrence_interval,
+    next_occurrence,
+    end_date,
+    goal_id,
+    goal_allocation_percentage,
+    last_posted_at,
+    created_at,
+    updated_at,
+    day_of_month
+FROM recurring_incomes
+WHERE id = $1 AND user_id = $2
+`
+
+type GetRecurringIncomeByIDParams struct {
+	ID     int64
+	UserID int64
+}
+
+func (q *Queries) GetRecurringIncomeByID(ctx context.Context, arg GetRecurringIncomeByIDParams) (RecurringIncome, error) {
+	row := q.db.QueryRowContext(ctx, getRecurringIncomeByID, arg.ID, arg.UserID)
+	var i RecurringIncome
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Source,
+		&i.OriginalCurrencyCode,
+		&i.AmountOriginal,
+		&i.Description,
+		&i.RecurrenceInterval,
+		&i.NextOccurrence,
+		&i.EndDate,
+		&i.GoalID,
+		&i.GoalAllocationPercentage,
+		&i.LastPostedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DayOfMonth,
+	)
+	return i, err
+}
+
+const saveDebtPayoffPlan = `-- name: SaveDebtPayoffPlan :one
+INSERT INTO debt_payoff_plans (
+    user_id, strategy, monthly_budget, custom_order, projected_payoff_date, total_months, total_interest
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id) DO UPDATE SET
+    strategy = EXCLUDED.strategy,
+    monthly_budget = EXCLUDED.monthly_budget,
+    custom_order = EXCLUDED.custom_order,
+    projected_payoff_date = EXCLUDED.projected_payoff_date,
+    total_months = EXCLUDED.total_months,
+    total_interest = EXCLUDED.total_interest
+RETURNING id, created_at, updated_at
+`
+
+type SaveDebtPayoffPlanParams struct {
+	UserID              int64
+	Strategy            DebtPayoffStrategyEnum
+	MonthlyBudget       string
+	CustomOrder         []int64
+	ProjectedPayoffDate time.Time
+	TotalMonths         int32
+	TotalInterest       string
+}
+
+type SaveDebtPayoffPlanRow struct {
+	ID        int64
+	CreatedAt sql.NullTime
+	UpdatedAt sql.NullTime
+}
+
+func (q *Queries) SaveDebtPayoffPlan(ctx context.Context, arg SaveDebtPayoffPlanParams) (SaveDebtPayoffPlanRow, error) {
+	row := q.db.QueryRowContext(ctx, saveDebtPayoffPlan,
+		arg.UserID,
+		arg.Strategy,
+		arg.MonthlyBudget,
+		pq.Array(arg.CustomOrder),
+		arg.ProjectedPayoffDate,
+		arg.TotalMonths,
+		arg.TotalInterest,
+	)
+	var i SaveDebtPayoffPlanRow
+	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
+	return i, err
+}
+
 const updateDebtByID = `-- name: UpdateDebtByID :one
 UPDATE debts
 SET
@@ -1119,7 +1747,12 @@ SET
     total_interest_paid = $11,                  -- New total interest paid
     estimated_payoff_date = $12,                -- New estimated payoff date
     interest_last_calculated = $13,               -- New interest last calculated date
-    last_payment_date = $14                     -- New last payment date
+    last_payment_date = $14,                    -- New last payment date
+    compounding_frequency = $16,                -- New compounding frequency
+    interest_rate_type = $17,                   -- New interest rate type
+    late_fee = $18,                             -- New late fee
+    grace_period_days = $19,                    -- New grace period
+    last_overdue_due_date = $20                 -- New last overdue due date
 WHERE
     id = $1 AND user_id=$15                                   -- ID of the debt to update
 RETURNING updated_at
@@ -1141,6 +1774,11 @@ type UpdateDebtByIDParams struct {
 	InterestLastCalculated sql.NullTime
 	LastPaymentDate        sql.NullTime
 	UserID                 int64
+	CompoundingFrequency   DebtCompoundingFrequencyEnum
+	InterestRateType       DebtInterestRateTypeEnum
+	LateFee                string
+	GracePeriodDays        int32
+	LastOverdueDueDate     sql.NullTime
 }
 
 func (q *Queries) UpdateDebtByID(ctx context.Context, arg UpdateDebtByIDParams) (sql.NullTime, error) {
@@ -1160,6 +1798,11 @@ func (q *Queries) UpdateDebtByID(ctx context.Context, arg UpdateDebtByIDParams)
 		arg.InterestLastCalculated,
 		arg.LastPaymentDate,
 		arg.UserID,
+		arg.CompoundingFrequency,
+		arg.InterestRateType,
+		arg.LateFee,
+		arg.GracePeriodDays,
+		arg.LastOverdueDueDate,
 	)
 	var updated_at sql.NullTime
 	err := row.Scan(&updated_at)
@@ -1173,9 +1816,11 @@ UPDATE expenses SET
     amount = $3,
     is_recurring = $4,
     description = $5,
-    date_occurred = $6
+    date_occurred = $6,
+    is_cleared = CASE WHEN account_id IS DISTINCT FROM $7 THEN FALSE ELSE is_cleared END,
+    account_id = $7
 WHERE
-    id = $7 AND user_id = $8
+    id = $8 AND user_id = $9
 RETURNING updated_at
 `
 
@@ -1186,6 +1831,7 @@ type UpdateExpenseByIDParams struct {
 	IsRecurring  bool
 	Description  sql.NullString
 	DateOccurred time.Time
+	AccountID    sql.NullInt64
 	ID           int64
 	UserID       int64
 }
@@ -1198,6 +1844,7 @@ func (q *Queries) UpdateExpenseByID(ctx context.Context, arg UpdateExpenseByIDPa
 		arg.IsRecurring,
 		arg.Description,
 		arg.DateOccurred,
+		arg.AccountID,
 		arg.ID,
 		arg.UserID,
 	)
@@ -1215,9 +1862,11 @@ SET
     amount = $4,
     exchange_rate = $5,
     description = $6,
-    date_received = $7
+    date_received = $7,
+    is_cleared = CASE WHEN account_id IS DISTINCT FROM $8 THEN FALSE ELSE is_cleared END,
+    account_id = $8
 WHERE
-    id=$8 AND user_id=$9
+    id=$9 AND user_id=$10
 RETURNING updated_at
 `
 
@@ -1229,6 +1878,7 @@ type UpdateIncomeByIDParams struct {
 	ExchangeRate         string
 	Description          sql.NullString
 	DateReceived         time.Time
+	AccountID            sql.NullInt64
 	ID                   int64
 	UserID               int64
 }
@@ -1242,6 +1892,7 @@ func (q *Queries) UpdateIncomeByID(ctx context.Context, arg UpdateIncomeByIDPara
 		arg.ExchangeRate,
 		arg.Description,
 		arg.DateReceived,
+		arg.AccountID,
 		arg.ID,
 		arg.UserID,
 	)
@@ -1257,9 +1908,16 @@ UPDATE recurring_expenses SET
     description = $3,
     recurrence_interval = $4,
     projected_amount = $5,
-    next_occurrence = $6
+    next_occurrence = $6,
+    interval_count = $7,
+    day_of_month = $8,
+    last_occurrence = $9,
+    end_date = $10,
+    max_occurrences = $11,
+    occurrence_count = $12,
+    is_paused = $13
 WHERE
-    id = $7 AND user_id = $8
+    id = $14 AND user_id = $15
 RETURNING  updated_at
 `
 
@@ -1270,6 +1928,13 @@ type UpdateRecurringExpenseByIDParams struct {
 	RecurrenceInterval RecurrenceIntervalEnum
 	ProjectedAmount    string
 	NextOccurrence     time.Time
+	IntervalCount      int32
+	DayOfMonth         sql.NullInt16
+	LastOccurrence     sql.NullTime
+	EndDate            sql.NullTime
+	MaxOccurrences     sql.NullInt32
+	OccurrenceCount    int32
+	IsPaused           bool
 	ID                 int64
 	UserID             int64
 }
@@ -1282,6 +1947,68 @@ func (q *Queries) UpdateRecurringExpenseByID(ctx context.Context, arg UpdateRecu
 		arg.RecurrenceInterval,
 		arg.ProjectedAmount,
 		arg.NextOccurrence,
+		arg.IntervalCount,
+		arg.DayOfMonth,
+		arg.LastOccurrence,
+		arg.EndDate,
+		arg.MaxOccurrences,
+		arg.OccurrenceCount,
+		arg.IsPaused,
+		arg.ID,
+		arg.UserID,
+	)
+	var updated_at sql.NullTime
+	err := row.Scan(&updated_at)
+	return updated_at, err
+}
+
+const updateRecurringIncomeByID = `-- name: UpdateRecurringIncomeByID :one
+UPDATE recurring_incomes SET
+    source = $1,
+    original_currency_code = $2,
+    amount_original = $3,
+    description = $4,
+    recurrence_interval = $5,
+    next_occurrence = $6,
+    end_date = $7,
+    goal_id = $8,
+    goal_allocation_percentage = $9,
+    last_posted_at = $10,
+    day_of_month = $11
+WHERE
+    id = $12 AND user_id = $13
+RETURNING updated_at
+`
+
+type UpdateRecurringIncomeByIDParams struct {
+	Source                   string
+	OriginalCurrencyCode     string
+	AmountOriginal           string
+	Description              sql.NullString
+	RecurrenceInterval       RecurrenceIntervalEnum
+	NextOccurrence           time.Time
+	EndDate                  sql.NullTime
+	GoalID                   sql.NullInt64
+	GoalAllocationPercentage string
+	LastPostedAt             sql.NullTime
+	DayOfMonth               int32
+	ID                       int64
+	UserID                   int64
+}
+
+func (q *Queries) UpdateRecurringIncomeByID(ctx context.Context, arg UpdateRecurringIncomeByIDParams) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, updateRecurringIncomeByID,
+		arg.Source,
+		arg.OriginalCurrencyCode,
+		arg.AmountOriginal,
+		arg.Description,
+		arg.RecurrenceInterval,
+		arg.NextOccurrence,
+		arg.EndDate,
+		arg.GoalID,
+		arg.GoalAllocationPercentage,
+		arg.LastPostedAt,
+		arg.DayOfMonth,
 		arg.ID,
 		arg.UserID,
 	)
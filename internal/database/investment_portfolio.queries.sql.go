@@ -219,9 +219,10 @@ INSERT INTO investment_transactions (
     transaction_type,
     transaction_date,
     transaction_amount,
-    quantity
+    quantity,
+    account_id
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING id, created_at, updated_at
 `
 
@@ -233,6 +234,7 @@ type CreateNewInvestmentTransactionParams struct {
 	TransactionDate   time.Time
 	TransactionAmount string
 	Quantity          string
+	AccountID         sql.NullInt64
 }
 
 type CreateNewInvestmentTransactionRow struct {
@@ -250,6 +252,7 @@ func (q *Queries) CreateNewInvestmentTransaction(ctx context.Context, arg Create
 		arg.TransactionDate,
 		arg.TransactionAmount,
 		arg.Quantity,
+		arg.AccountID,
 	)
 	var i CreateNewInvestmentTransactionRow
 	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
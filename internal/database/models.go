@@ -14,6 +14,94 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+type AccountTypeEnum string
+
+const (
+	AccountTypeEnumChecking   AccountTypeEnum = "checking"
+	AccountTypeEnumSavings    AccountTypeEnum = "savings"
+	AccountTypeEnumCreditCard AccountTypeEnum = "credit_card"
+	AccountTypeEnumCash       AccountTypeEnum = "cash"
+	AccountTypeEnumBrokerage  AccountTypeEnum = "brokerage"
+)
+
+func (e *AccountTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AccountTypeEnum(s)
+	case string:
+		*e = AccountTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AccountTypeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullAccountTypeEnum struct {
+	AccountTypeEnum AccountTypeEnum
+	Valid           bool // Valid is true if AccountTypeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAccountTypeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.AccountTypeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AccountTypeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAccountTypeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AccountTypeEnum), nil
+}
+
+type AnomalyKindEnum string
+
+const (
+	AnomalyKindEnumUnusualTransaction AnomalyKindEnum = "unusual_transaction"
+	AnomalyKindEnumDuplicateCharge    AnomalyKindEnum = "duplicate_charge"
+	AnomalyKindEnumCategorySpike      AnomalyKindEnum = "category_spike"
+)
+
+func (e *AnomalyKindEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AnomalyKindEnum(s)
+	case string:
+		*e = AnomalyKindEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AnomalyKindEnum: %T", src)
+	}
+	return nil
+}
+
+type NullAnomalyKindEnum struct {
+	AnomalyKindEnum AnomalyKindEnum
+	Valid           bool // Valid is true if AnomalyKindEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAnomalyKindEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.AnomalyKindEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AnomalyKindEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAnomalyKindEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AnomalyKindEnum), nil
+}
+
 type CommentAssociatedType string
 
 const (
@@ -100,6 +188,133 @@ func (ns NullContactUsStatus) Value() (driver.Value, error) {
 	return string(ns.ContactUsStatus), nil
 }
 
+type DebtCompoundingFrequencyEnum string
+
+const (
+	DebtCompoundingFrequencyEnumDaily   DebtCompoundingFrequencyEnum = "daily"
+	DebtCompoundingFrequencyEnumMonthly DebtCompoundingFrequencyEnum = "monthly"
+)
+
+func (e *DebtCompoundingFrequencyEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DebtCompoundingFrequencyEnum(s)
+	case string:
+		*e = DebtCompoundingFrequencyEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DebtCompoundingFrequencyEnum: %T", src)
+	}
+	return nil
+}
+
+type NullDebtCompoundingFrequencyEnum struct {
+	DebtCompoundingFrequencyEnum DebtCompoundingFrequencyEnum
+	Valid                        bool // Valid is true if DebtCompoundingFrequencyEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDebtCompoundingFrequencyEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.DebtCompoundingFrequencyEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DebtCompoundingFrequencyEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDebtCompoundingFrequencyEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DebtCompoundingFrequencyEnum), nil
+}
+
+type DebtInterestRateTypeEnum string
+
+const (
+	DebtInterestRateTypeEnumApr DebtInterestRateTypeEnum = "apr"
+	DebtInterestRateTypeEnumApy DebtInterestRateTypeEnum = "apy"
+)
+
+func (e *DebtInterestRateTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DebtInterestRateTypeEnum(s)
+	case string:
+		*e = DebtInterestRateTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DebtInterestRateTypeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullDebtInterestRateTypeEnum struct {
+	DebtInterestRateTypeEnum DebtInterestRateTypeEnum
+	Valid                    bool // Valid is true if DebtInterestRateTypeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDebtInterestRateTypeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.DebtInterestRateTypeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DebtInterestRateTypeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDebtInterestRateTypeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DebtInterestRateTypeEnum), nil
+}
+
+type DebtPayoffStrategyEnum string
+
+const (
+	DebtPayoffStrategyEnumSnowball  DebtPayoffStrategyEnum = "snowball"
+	DebtPayoffStrategyEnumAvalanche DebtPayoffStrategyEnum = "avalanche"
+	DebtPayoffStrategyEnumCustom    DebtPayoffStrategyEnum = "custom"
+)
+
+func (e *DebtPayoffStrategyEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DebtPayoffStrategyEnum(s)
+	case string:
+		*e = DebtPayoffStrategyEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DebtPayoffStrategyEnum: %T", src)
+	}
+	return nil
+}
+
+type NullDebtPayoffStrategyEnum struct {
+	DebtPayoffStrategyEnum DebtPayoffStrategyEnum
+	Valid                  bool // Valid is true if DebtPayoffStrategyEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDebtPayoffStrategyEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.DebtPayoffStrategyEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DebtPayoffStrategyEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDebtPayoffStrategyEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DebtPayoffStrategyEnum), nil
+}
+
 type FeedApprovalStatus string
 
 const (
@@ -108,124 +323,471 @@ const (
 	FeedApprovalStatusRejected FeedApprovalStatus = "rejected"
 )
 
-func (e *FeedApprovalStatus) Scan(src interface{}) error {
+func (e *FeedApprovalStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = FeedApprovalStatus(s)
+	case string:
+		*e = FeedApprovalStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for FeedApprovalStatus: %T", src)
+	}
+	return nil
+}
+
+type NullFeedApprovalStatus struct {
+	FeedApprovalStatus FeedApprovalStatus
+	Valid              bool // Valid is true if FeedApprovalStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullFeedApprovalStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.FeedApprovalStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.FeedApprovalStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullFeedApprovalStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.FeedApprovalStatus), nil
+}
+
+type FeedType string
+
+const (
+	FeedTypeRss  FeedType = "rss"
+	FeedTypeJson FeedType = "json"
+)
+
+func (e *FeedType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = FeedType(s)
+	case string:
+		*e = FeedType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for FeedType: %T", src)
+	}
+	return nil
+}
+
+type NullFeedType struct {
+	FeedType FeedType
+	Valid    bool // Valid is true if FeedType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullFeedType) Scan(value interface{}) error {
+	if value == nil {
+		ns.FeedType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.FeedType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullFeedType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.FeedType), nil
+}
+
+type GoalStatus string
+
+const (
+	GoalStatusOngoing   GoalStatus = "ongoing"
+	GoalStatusCompleted GoalStatus = "completed"
+	GoalStatusCancelled GoalStatus = "cancelled"
+)
+
+func (e *GoalStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GoalStatus(s)
+	case string:
+		*e = GoalStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GoalStatus: %T", src)
+	}
+	return nil
+}
+
+type NullGoalStatus struct {
+	GoalStatus GoalStatus
+	Valid      bool // Valid is true if GoalStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullGoalStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.GoalStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.GoalStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullGoalStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.GoalStatus), nil
+}
+
+type GroupActivityTypeEnum string
+
+const (
+	GroupActivityTypeEnumMemberJoined  GroupActivityTypeEnum = "member_joined"
+	GroupActivityTypeEnumMemberLeft    GroupActivityTypeEnum = "member_left"
+	GroupActivityTypeEnumMemberRemoved GroupActivityTypeEnum = "member_removed"
+	GroupActivityTypeEnumRoleChanged   GroupActivityTypeEnum = "role_changed"
+	GroupActivityTypeEnumGoalCreated   GroupActivityTypeEnum = "goal_created"
+	GroupActivityTypeEnumContribution  GroupActivityTypeEnum = "contribution"
+	GroupActivityTypeEnumExpense       GroupActivityTypeEnum = "expense"
+	GroupActivityTypeEnumComment       GroupActivityTypeEnum = "comment"
+)
+
+func (e *GroupActivityTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GroupActivityTypeEnum(s)
+	case string:
+		*e = GroupActivityTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GroupActivityTypeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullGroupActivityTypeEnum struct {
+	GroupActivityTypeEnum GroupActivityTypeEnum
+	Valid                 bool // Valid is true if GroupActivityTypeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullGroupActivityTypeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.GroupActivityTypeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.GroupActivityTypeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullGroupActivityTypeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.GroupActivityTypeEnum), nil
+}
+
+type GroupBudgetPeriodEnum string
+
+const (
+	GroupBudgetPeriodEnumWeekly    GroupBudgetPeriodEnum = "weekly"
+	GroupBudgetPeriodEnumMonthly   GroupBudgetPeriodEnum = "monthly"
+	GroupBudgetPeriodEnumQuarterly GroupBudgetPeriodEnum = "quarterly"
+	GroupBudgetPeriodEnumYearly    GroupBudgetPeriodEnum = "yearly"
+)
+
+func (e *GroupBudgetPeriodEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GroupBudgetPeriodEnum(s)
+	case string:
+		*e = GroupBudgetPeriodEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GroupBudgetPeriodEnum: %T", src)
+	}
+	return nil
+}
+
+type NullGroupBudgetPeriodEnum struct {
+	GroupBudgetPeriodEnum GroupBudgetPeriodEnum
+	Valid                 bool // Valid is true if GroupBudgetPeriodEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullGroupBudgetPeriodEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.GroupBudgetPeriodEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.GroupBudgetPeriodEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullGroupBudgetPeriodEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.GroupBudgetPeriodEnum), nil
+}
+
+type GroupChallengeStatusEnum string
+
+const (
+	GroupChallengeStatusEnumActive GroupChallengeStatusEnum = "active"
+	GroupChallengeStatusEnumEnded  GroupChallengeStatusEnum = "ended"
+)
+
+func (e *GroupChallengeStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GroupChallengeStatusEnum(s)
+	case string:
+		*e = GroupChallengeStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GroupChallengeStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullGroupChallengeStatusEnum struct {
+	GroupChallengeStatusEnum GroupChallengeStatusEnum
+	Valid                    bool // Valid is true if GroupChallengeStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullGroupChallengeStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.GroupChallengeStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.GroupChallengeStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullGroupChallengeStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.GroupChallengeStatusEnum), nil
+}
+
+type GroupChallengeTypeEnum string
+
+const (
+	GroupChallengeTypeEnumSavings GroupChallengeTypeEnum = "savings"
+	GroupChallengeTypeEnumNoSpend GroupChallengeTypeEnum = "no_spend"
+)
+
+func (e *GroupChallengeTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GroupChallengeTypeEnum(s)
+	case string:
+		*e = GroupChallengeTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GroupChallengeTypeEnum: %T", src)
+	}
+	return nil
+}
+
+type NullGroupChallengeTypeEnum struct {
+	GroupChallengeTypeEnum GroupChallengeTypeEnum
+	Valid                  bool // Valid is true if GroupChallengeTypeEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullGroupChallengeTypeEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.GroupChallengeTypeEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.GroupChallengeTypeEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullGroupChallengeTypeEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.GroupChallengeTypeEnum), nil
+}
+
+type GroupDueStatusEnum string
+
+const (
+	GroupDueStatusEnumPending GroupDueStatusEnum = "pending"
+	GroupDueStatusEnumPaid    GroupDueStatusEnum = "paid"
+)
+
+func (e *GroupDueStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GroupDueStatusEnum(s)
+	case string:
+		*e = GroupDueStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GroupDueStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullGroupDueStatusEnum struct {
+	GroupDueStatusEnum GroupDueStatusEnum
+	Valid              bool // Valid is true if GroupDueStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullGroupDueStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.GroupDueStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.GroupDueStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullGroupDueStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.GroupDueStatusEnum), nil
+}
+
+type GroupJoinRequestStatusEnum string
+
+const (
+	GroupJoinRequestStatusEnumPending  GroupJoinRequestStatusEnum = "pending"
+	GroupJoinRequestStatusEnumApproved GroupJoinRequestStatusEnum = "approved"
+	GroupJoinRequestStatusEnumRejected GroupJoinRequestStatusEnum = "rejected"
+)
+
+func (e *GroupJoinRequestStatusEnum) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = FeedApprovalStatus(s)
+		*e = GroupJoinRequestStatusEnum(s)
 	case string:
-		*e = FeedApprovalStatus(s)
+		*e = GroupJoinRequestStatusEnum(s)
 	default:
-		return fmt.Errorf("unsupported scan type for FeedApprovalStatus: %T", src)
+		return fmt.Errorf("unsupported scan type for GroupJoinRequestStatusEnum: %T", src)
 	}
 	return nil
 }
 
-type NullFeedApprovalStatus struct {
-	FeedApprovalStatus FeedApprovalStatus
-	Valid              bool // Valid is true if FeedApprovalStatus is not NULL
+type NullGroupJoinRequestStatusEnum struct {
+	GroupJoinRequestStatusEnum GroupJoinRequestStatusEnum
+	Valid                      bool // Valid is true if GroupJoinRequestStatusEnum is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullFeedApprovalStatus) Scan(value interface{}) error {
+func (ns *NullGroupJoinRequestStatusEnum) Scan(value interface{}) error {
 	if value == nil {
-		ns.FeedApprovalStatus, ns.Valid = "", false
+		ns.GroupJoinRequestStatusEnum, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.FeedApprovalStatus.Scan(value)
+	return ns.GroupJoinRequestStatusEnum.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullFeedApprovalStatus) Value() (driver.Value, error) {
+func (ns NullGroupJoinRequestStatusEnum) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.FeedApprovalStatus), nil
+	return string(ns.GroupJoinRequestStatusEnum), nil
 }
 
-type FeedType string
+type GroupSplitTypeEnum string
 
 const (
-	FeedTypeRss  FeedType = "rss"
-	FeedTypeJson FeedType = "json"
+	GroupSplitTypeEnumEqual      GroupSplitTypeEnum = "equal"
+	GroupSplitTypeEnumShares     GroupSplitTypeEnum = "shares"
+	GroupSplitTypeEnumExact      GroupSplitTypeEnum = "exact"
+	GroupSplitTypeEnumPercentage GroupSplitTypeEnum = "percentage"
 )
 
-func (e *FeedType) Scan(src interface{}) error {
+func (e *GroupSplitTypeEnum) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = FeedType(s)
+		*e = GroupSplitTypeEnum(s)
 	case string:
-		*e = FeedType(s)
+		*e = GroupSplitTypeEnum(s)
 	default:
-		return fmt.Errorf("unsupported scan type for FeedType: %T", src)
+		return fmt.Errorf("unsupported scan type for GroupSplitTypeEnum: %T", src)
 	}
 	return nil
 }
 
-type NullFeedType struct {
-	FeedType FeedType
-	Valid    bool // Valid is true if FeedType is not NULL
+type NullGroupSplitTypeEnum struct {
+	GroupSplitTypeEnum GroupSplitTypeEnum
+	Valid              bool // Valid is true if GroupSplitTypeEnum is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullFeedType) Scan(value interface{}) error {
+func (ns *NullGroupSplitTypeEnum) Scan(value interface{}) error {
 	if value == nil {
-		ns.FeedType, ns.Valid = "", false
+		ns.GroupSplitTypeEnum, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.FeedType.Scan(value)
+	return ns.GroupSplitTypeEnum.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullFeedType) Value() (driver.Value, error) {
+func (ns NullGroupSplitTypeEnum) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.FeedType), nil
+	return string(ns.GroupSplitTypeEnum), nil
 }
 
-type GoalStatus string
+type GroupTransactionTypeEnum string
 
 const (
-	GoalStatusOngoing   GoalStatus = "ongoing"
-	GoalStatusCompleted GoalStatus = "completed"
-	GoalStatusCancelled GoalStatus = "cancelled"
+	GroupTransactionTypeEnumContribution GroupTransactionTypeEnum = "contribution"
+	GroupTransactionTypeEnumSettlement   GroupTransactionTypeEnum = "settlement"
 )
 
-func (e *GoalStatus) Scan(src interface{}) error {
+func (e *GroupTransactionTypeEnum) Scan(src interface{}) error {
 	switch s := src.(type) {
 	case []byte:
-		*e = GoalStatus(s)
+		*e = GroupTransactionTypeEnum(s)
 	case string:
-		*e = GoalStatus(s)
+		*e = GroupTransactionTypeEnum(s)
 	default:
-		return fmt.Errorf("unsupported scan type for GoalStatus: %T", src)
+		return fmt.Errorf("unsupported scan type for GroupTransactionTypeEnum: %T", src)
 	}
 	return nil
 }
 
-type NullGoalStatus struct {
-	GoalStatus GoalStatus
-	Valid      bool // Valid is true if GoalStatus is not NULL
+type NullGroupTransactionTypeEnum struct {
+	GroupTransactionTypeEnum GroupTransactionTypeEnum
+	Valid                    bool // Valid is true if GroupTransactionTypeEnum is not NULL
 }
 
 // Scan implements the Scanner interface.
-func (ns *NullGoalStatus) Scan(value interface{}) error {
+func (ns *NullGroupTransactionTypeEnum) Scan(value interface{}) error {
 	if value == nil {
-		ns.GoalStatus, ns.Valid = "", false
+		ns.GroupTransactionTypeEnum, ns.Valid = "", false
 		return nil
 	}
 	ns.Valid = true
-	return ns.GoalStatus.Scan(value)
+	return ns.GroupTransactionTypeEnum.Scan(value)
 }
 
 // Value implements the driver Valuer interface.
-func (ns NullGoalStatus) Value() (driver.Value, error) {
+func (ns NullGroupTransactionTypeEnum) Value() (driver.Value, error) {
 	if !ns.Valid {
 		return nil, nil
 	}
-	return string(ns.GoalStatus), nil
+	return string(ns.GroupTransactionTypeEnum), nil
 }
 
 type InvestmentTypeEnum string
@@ -532,6 +1094,93 @@ func (ns NullRiskToleranceType) Value() (driver.Value, error) {
 	return string(ns.RiskToleranceType), nil
 }
 
+type SubscriptionStatusEnum string
+
+const (
+	SubscriptionStatusEnumActive    SubscriptionStatusEnum = "active"
+	SubscriptionStatusEnumConverted SubscriptionStatusEnum = "converted"
+	SubscriptionStatusEnumDismissed SubscriptionStatusEnum = "dismissed"
+)
+
+func (e *SubscriptionStatusEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SubscriptionStatusEnum(s)
+	case string:
+		*e = SubscriptionStatusEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SubscriptionStatusEnum: %T", src)
+	}
+	return nil
+}
+
+type NullSubscriptionStatusEnum struct {
+	SubscriptionStatusEnum SubscriptionStatusEnum
+	Valid                  bool // Valid is true if SubscriptionStatusEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSubscriptionStatusEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.SubscriptionStatusEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SubscriptionStatusEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSubscriptionStatusEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SubscriptionStatusEnum), nil
+}
+
+type TaxCategoryEnum string
+
+const (
+	TaxCategoryEnumDeductible TaxCategoryEnum = "deductible"
+	TaxCategoryEnumBusiness   TaxCategoryEnum = "business"
+	TaxCategoryEnumMedical    TaxCategoryEnum = "medical"
+	TaxCategoryEnumCharitable TaxCategoryEnum = "charitable"
+)
+
+func (e *TaxCategoryEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TaxCategoryEnum(s)
+	case string:
+		*e = TaxCategoryEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TaxCategoryEnum: %T", src)
+	}
+	return nil
+}
+
+type NullTaxCategoryEnum struct {
+	TaxCategoryEnum TaxCategoryEnum
+	Valid           bool // Valid is true if TaxCategoryEnum is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTaxCategoryEnum) Scan(value interface{}) error {
+	if value == nil {
+		ns.TaxCategoryEnum, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TaxCategoryEnum.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTaxCategoryEnum) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TaxCategoryEnum), nil
+}
+
 type TimeHorizonType string
 
 const (
@@ -661,6 +1310,36 @@ func (ns NullTransactionTypeEnum) Value() (driver.Value, error) {
 	return string(ns.TransactionTypeEnum), nil
 }
 
+type Account struct {
+	ID                int64
+	UserID            int64
+	Name              string
+	AccountType       AccountTypeEnum
+	CurrencyCode      string
+	OpeningBalance    string
+	OpeningDate       time.Time
+	ReconciledBalance sql.NullString
+	ReconciledAt      sql.NullTime
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+	DebtID            sql.NullInt64
+}
+
+type AccountTransfer struct {
+	ID            int64
+	UserID        int64
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        string
+	ToAmount      string
+	ExchangeRate  string
+	Description   sql.NullString
+	TransferDate  time.Time
+	FromCleared   bool
+	ToCleared     bool
+	CreatedAt     sql.NullTime
+}
+
 type AlternativeInvestment struct {
 	ID                 int64
 	UserID             int64
@@ -784,6 +1463,33 @@ type Debt struct {
 	InterestLastCalculated sql.NullTime
 	LastPaymentDate        sql.NullTime
 	TotalInterestPaid      sql.NullString
+	CompoundingFrequency   DebtCompoundingFrequencyEnum
+	InterestRateType       DebtInterestRateTypeEnum
+	LateFee                string
+	GracePeriodDays        int32
+	LastOverdueDueDate     sql.NullTime
+}
+
+type DebtLateFee struct {
+	ID            int64
+	DebtID        int64
+	UserID        int64
+	Amount        string
+	MissedDueDate time.Time
+	CreatedAt     sql.NullTime
+}
+
+type DebtPayoffPlan struct {
+	ID                  int64
+	UserID              int64
+	Strategy            DebtPayoffStrategyEnum
+	MonthlyBudget       string
+	CustomOrder         []int64
+	ProjectedPayoffDate time.Time
+	TotalMonths         int32
+	TotalInterest       string
+	CreatedAt           sql.NullTime
+	UpdatedAt           sql.NullTime
 }
 
 type Debtpayment struct {
@@ -795,6 +1501,37 @@ type Debtpayment struct {
 	InterestPayment  string
 	PrincipalPayment string
 	CreatedAt        sql.NullTime
+	AccountID        sql.NullInt64
+	IsCleared        bool
+}
+
+type DetectedSubscription struct {
+	ID                 int64
+	UserID             int64
+	BudgetID           int64
+	MerchantKey        string
+	Name               string
+	Amount             string
+	PreviousAmount     sql.NullString
+	RecurrenceInterval RecurrenceIntervalEnum
+	IntervalCount      int32
+	ChargeCount        int32
+	FirstCharged       time.Time
+	LastCharged        time.Time
+	NextExpected       time.Time
+	Status             SubscriptionStatusEnum
+	RecurringExpenseID sql.NullInt64
+	CreatedAt          sql.NullTime
+	UpdatedAt          sql.NullTime
+}
+
+type ExchangeRate struct {
+	ID             int64
+	BaseCurrency   string
+	TargetCurrency string
+	Rate           string
+	RateDate       time.Time
+	CreatedAt      sql.NullTime
 }
 
 type Expense struct {
@@ -809,6 +1546,65 @@ type Expense struct {
 	DateOccurred time.Time
 	CreatedAt    sql.NullTime
 	UpdatedAt    sql.NullTime
+	AccountID    sql.NullInt64
+	IsCleared    bool
+}
+
+type ExpenseAttachment struct {
+	ID             int64
+	UserID         int64
+	ExpenseID      sql.NullInt64
+	GroupExpenseID sql.NullInt64
+	StorageKey     string
+	FileName       string
+	ContentType    string
+	SizeBytes      int64
+	CreatedAt      sql.NullTime
+}
+
+type ExpenseCategoryRule struct {
+	ID        int64
+	UserID    int64
+	Pattern   string
+	Category  string
+	BudgetID  sql.NullInt64
+	Priority  int32
+	CreatedAt sql.NullTime
+	UpdatedAt sql.NullTime
+}
+
+type ExpenseReceiptDraft struct {
+	ID             int64
+	UserID         int64
+	BudgetID       sql.NullInt64
+	BudgetMatch    string
+	CategoryRuleID sql.NullInt64
+	Merchant       string
+	Category       string
+	Amount         string
+	OriginalAmount string
+	CurrencyCode   string
+	Description    string
+	DateOccurred   time.Time
+	LineItems      json.RawMessage
+	StorageKey     string
+	FileName       string
+	ContentType    string
+	SizeBytes      int64
+	ExpiresAt      time.Time
+	CreatedAt      sql.NullTime
+}
+
+type ExpenseTag struct {
+	ExpenseID int64
+	TagID     int64
+	CreatedAt sql.NullTime
+}
+
+type ExpenseTaxCategory struct {
+	ExpenseID   int64
+	TaxCategory TaxCategoryEnum
+	CreatedAt   sql.NullTime
 }
 
 type FavoritePost struct {
@@ -877,18 +1673,100 @@ type GoalTracking struct {
 }
 
 type Group struct {
-	ID             int64
-	CreatorUserID  sql.NullInt64
-	GroupImageUrl  string
-	Name           string
-	IsPrivate      sql.NullBool
-	MaxMemberCount sql.NullInt32
-	Description    sql.NullString
-	ActivityCount  sql.NullInt32
-	LastActivityAt sql.NullTime
-	CreatedAt      sql.NullTime
-	UpdatedAt      sql.NullTime
-	Version        sql.NullInt32
+	ID                  int64
+	CreatorUserID       sql.NullInt64
+	GroupImageUrl       string
+	Name                string
+	IsPrivate           sql.NullBool
+	MaxMemberCount      sql.NullInt32
+	Description         sql.NullString
+	ActivityCount       sql.NullInt32
+	LastActivityAt      sql.NullTime
+	CreatedAt           sql.NullTime
+	UpdatedAt           sql.NullTime
+	Version             sql.NullInt32
+	IsDiscoverable      bool
+	ArchivedAt          sql.NullTime
+	DeletionScheduledAt sql.NullTime
+}
+
+type GroupActivity struct {
+	ID           int64
+	GroupID      int64
+	UserID       int64
+	ActivityType GroupActivityTypeEnum
+	SubjectID    sql.NullInt64
+	Description  string
+	CreatedAt    time.Time
+}
+
+type GroupBudget struct {
+	ID            int64
+	GroupID       int64
+	CreatorUserID sql.NullInt64
+	Category      string
+	LimitAmount   string
+	Period        GroupBudgetPeriodEnum
+	IsStrict      bool
+	Description   sql.NullString
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type GroupChallenge struct {
+	ID            int64
+	GroupID       int64
+	CreatorUserID sql.NullInt64
+	Name          string
+	Description   sql.NullString
+	ChallengeType GroupChallengeTypeEnum
+	TargetAmount  sql.NullString
+	WeekendsOnly  bool
+	StartsOn      time.Time
+	EndsOn        time.Time
+	Status        GroupChallengeStatusEnum
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type GroupChallengeParticipant struct {
+	ID          int64
+	ChallengeID int64
+	UserID      int64
+	GoalID      sql.NullInt64
+	Rank        sql.NullInt32
+	CompletedAt sql.NullTime
+	JoinedAt    time.Time
+}
+
+type GroupContributionDue struct {
+	ID         int64
+	ScheduleID int64
+	GroupID    int64
+	GoalID     int64
+	MemberID   int64
+	Amount     string
+	DueDate    time.Time
+	Status     GroupDueStatusEnum
+	PaidAt     sql.NullTime
+	RemindedAt sql.NullTime
+	CreatedAt  time.Time
+	AmountPaid string
+}
+
+type GroupContributionSchedule struct {
+	ID                 int64
+	GroupID            int64
+	GoalID             int64
+	CreatorUserID      sql.NullInt64
+	AmountPerMember    string
+	RecurrenceInterval RecurrenceIntervalEnum
+	DueDay             int32
+	NextDueDate        time.Time
+	IsActive           bool
+	Description        sql.NullString
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
 }
 
 type GroupExpense struct {
@@ -900,6 +1778,22 @@ type GroupExpense struct {
 	Category    sql.NullString
 	CreatedAt   sql.NullTime
 	UpdatedAt   sql.NullTime
+	SplitType   NullGroupSplitTypeEnum
+}
+
+type GroupExpenseSplit struct {
+	ID        int64
+	ExpenseID int64
+	MemberID  int64
+	Share     string
+	Amount    string
+	CreatedAt sql.NullTime
+}
+
+type GroupExpenseTag struct {
+	GroupExpenseID int64
+	TagID          int64
+	CreatedAt      sql.NullTime
 }
 
 type GroupGoal struct {
@@ -928,6 +1822,32 @@ type GroupInvitation struct {
 	ExpirationDate   time.Time
 }
 
+type GroupInviteLink struct {
+	ID            int64
+	GroupID       int64
+	CreatorUserID sql.NullInt64
+	TokenHash     []byte
+	Role          MembershipRole
+	MaxUses       int32
+	UseCount      int32
+	ExpiresAt     time.Time
+	RevokedAt     sql.NullTime
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type GroupJoinRequest struct {
+	ID             int64
+	GroupID        int64
+	UserID         int64
+	Message        sql.NullString
+	Status         GroupJoinRequestStatusEnum
+	ReviewerUserID sql.NullInt64
+	ReviewedAt     sql.NullTime
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
 type GroupMembership struct {
 	ID           int64
 	GroupID      sql.NullInt64
@@ -941,13 +1861,16 @@ type GroupMembership struct {
 }
 
 type GroupTransaction struct {
-	ID          int64
-	GoalID      sql.NullInt64
-	MemberID    sql.NullInt64
-	Amount      string
-	Description sql.NullString
-	CreatedAt   sql.NullTime
-	UpdatedAt   sql.NullTime
+	ID                int64
+	GoalID            sql.NullInt64
+	MemberID          sql.NullInt64
+	Amount            string
+	Description       sql.NullString
+	CreatedAt         sql.NullTime
+	UpdatedAt         sql.NullTime
+	TransactionType   GroupTransactionTypeEnum
+	GroupID           sql.NullInt64
+	RecipientMemberID sql.NullInt64
 }
 
 type Income struct {
@@ -962,6 +1885,20 @@ type Income struct {
 	DateReceived         time.Time
 	CreatedAt            sql.NullTime
 	UpdatedAt            sql.NullTime
+	AccountID            sql.NullInt64
+	IsCleared            bool
+}
+
+type IncomeTag struct {
+	IncomeID  int64
+	TagID     int64
+	CreatedAt sql.NullTime
+}
+
+type IncomeTaxCategory struct {
+	IncomeID    int64
+	TaxCategory TaxCategoryEnum
+	CreatedAt   sql.NullTime
 }
 
 type InvestmentTransaction struct {
@@ -975,6 +1912,8 @@ type InvestmentTransaction struct {
 	Quantity          string
 	CreatedAt         sql.NullTime
 	UpdatedAt         sql.NullTime
+	AccountID         sql.NullInt64
+	IsCleared         bool
 }
 
 type LlmAnalysisResponse struct {
@@ -986,6 +1925,23 @@ type LlmAnalysisResponse struct {
 	Footer    sql.NullString
 }
 
+type NetWorthSnapshot struct {
+	ID                int64
+	UserID            int64
+	SnapshotDate      time.Time
+	CurrencyCode      string
+	StocksValue       string
+	BondsValue        string
+	AlternativesValue string
+	TotalAssets       string
+	DebtsBalance      string
+	TotalLiabilities  string
+	NetWorth          string
+	CreatedAt         sql.NullTime
+	AccountsValue     string
+	AccountsOwed      string
+}
+
 type Notification struct {
 	ID               int64
 	UserID           int64
@@ -1010,17 +1966,44 @@ type RecoveryCode struct {
 }
 
 type RecurringExpense struct {
-	ID                 int64
-	UserID             int64
-	BudgetID           int64
-	Amount             string
-	Name               string
-	Description        sql.NullString
-	RecurrenceInterval RecurrenceIntervalEnum
-	ProjectedAmount    string
-	NextOccurrence     time.Time
-	CreatedAt          sql.NullTime
-	UpdatedAt          sql.NullTime
+	ID                      int64
+	UserID                  int64
+	BudgetID                int64
+	Amount                  string
+	Name                    string
+	Description             sql.NullString
+	RecurrenceInterval      RecurrenceIntervalEnum
+	ProjectedAmount         string
+	NextOccurrence          time.Time
+	CreatedAt               sql.NullTime
+	UpdatedAt               sql.NullTime
+	IntervalCount           int32
+	DayOfMonth              sql.NullInt16
+	StartDate               time.Time
+	LastOccurrence          sql.NullTime
+	EndDate                 sql.NullTime
+	MaxOccurrences          sql.NullInt32
+	OccurrenceCount         int32
+	IsPaused                bool
+	FirstOccurrenceRecorded bool
+}
+
+type RecurringIncome struct {
+	ID                       int64
+	UserID                   int64
+	Source                   string
+	OriginalCurrencyCode     string
+	AmountOriginal           string
+	Description              sql.NullString
+	RecurrenceInterval       RecurrenceIntervalEnum
+	NextOccurrence           time.Time
+	EndDate                  sql.NullTime
+	GoalID                   sql.NullInt64
+	GoalAllocationPercentage string
+	LastPostedAt             sql.NullTime
+	CreatedAt                sql.NullTime
+	UpdatedAt                sql.NullTime
+	DayOfMonth               int32
 }
 
 type RssfeedPost struct {
@@ -1040,6 +2023,23 @@ type RssfeedPost struct {
 	FeedID             int64
 }
 
+type SpendingAnomaly struct {
+	ID             int64
+	UserID         int64
+	ExpenseID      sql.NullInt64
+	Kind           AnomalyKindEnum
+	Category       string
+	Name           string
+	CurrencyCode   string
+	Amount         string
+	ExpectedAmount string
+	Score          string
+	OccurredOn     time.Time
+	Message        string
+	DedupeKey      string
+	CreatedAt      sql.NullTime
+}
+
 type StockAnalysis struct {
 	ID                int64
 	UserID            int64
@@ -1068,6 +2068,13 @@ type StockInvestment struct {
 	UpdatedAt              sql.NullTime
 }
 
+type Tag struct {
+	ID        int64
+	UserID    int64
+	Name      string
+	CreatedAt sql.NullTime
+}
+
 type Token struct {
 	Hash   []byte
 	UserID int64
@@ -0,0 +1,159 @@
+// Package pdf renders simple text reports, such as the annual tax summary, as PDF documents.
+// Only the standard Type 1 fonts are used so nothing has to be embedded: headings are set in
+// Helvetica-Bold and the body in Courier so that table columns line up.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A4 portrait in points
+const (
+	pageWidth   = 595.0
+	pageHeight  = 842.0
+	margin      = 50.0
+	bodySize    = 9.0
+	headingSize = 13.0
+	leading     = 13.0
+	// Courier glyphs are 0.6em wide, which leaves room for 91 characters per line
+	MaxLineLength = 91
+)
+
+type line struct {
+	text    string
+	heading bool
+}
+
+// Document is a text document made of headings and monospaced body lines.
+// Lines are laid out top to bottom and new pages are started as needed.
+type Document struct {
+	Title  string
+	Author string
+	lines  []line
+}
+
+// New returns an empty document with the given title
+func New(title, author string) *Document {
+	return &Document{Title: title, Author: author}
+}
+
+// Heading adds a bold heading line
+func (d *Document) Heading(text string) {
+	d.lines = append(d.lines, line{text: text, heading: true})
+}
+
+// Text adds a body line, lines longer than MaxLineLength are wrapped
+func (d *Document) Text(text string) {
+	runes := []rune(text)
+	for len(runes) > MaxLineLength {
+		d.lines = append(d.lines, line{text: string(runes[:MaxLineLength])})
+		runes = runes[MaxLineLength:]
+	}
+	d.lines = append(d.lines, line{text: string(runes)})
+}
+
+// Blank adds an empty line
+func (d *Document) Blank() {
+	d.lines = append(d.lines, line{})
+}
+
+// Bytes renders the document, the creation date is set to now
+func (d *Document) Bytes(now time.Time) []byte {
+	pages := d.paginate()
+	// object numbers: 1 catalog, 2 pages, 3 body font, 4 heading font, 5 info,
+	// then a page object and its content stream for every page
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+i*2)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Author (%s) /Producer (OptiVest) /CreationDate (D:%s) >>",
+			escapeText(d.Title), escapeText(d.Author), now.UTC().Format("20060102150405Z")),
+	)
+	for i, page := range pages {
+		content := renderPage(page, i+1, len(pages))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pageWidth, pageHeight, 7+i*2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	// write the objects and remember where each one starts for the cross-reference table
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// paginate() splits the lines into pages, keeping room at the bottom for the page number
+func (d *Document) paginate() [][]line {
+	var usableHeight float64 = pageHeight - 2*margin - leading
+	linesPerPage := int(usableHeight / leading)
+	pages := [][]line{}
+	current := []line{}
+	for _, l := range d.lines {
+		if len(current) == linesPerPage {
+			pages = append(pages, current)
+			current = []line{}
+		}
+		current = append(current, l)
+	}
+	// an empty document still has one page
+	return append(pages, current)
+}
+
+// renderPage() returns the content stream of a single page
+func renderPage(lines []line, pageNumber, pageCount int) string {
+	var builder strings.Builder
+	y := pageHeight - margin
+	for _, l := range lines {
+		if l.text != "" {
+			font, size := "F1", bodySize
+			if l.heading {
+				font, size = "F2", headingSize
+			}
+			fmt.Fprintf(&builder, "BT /%s %.0f Tf %.0f %.0f Td (%s) Tj ET\n", font, size, margin, y, escapeText(l.text))
+		}
+		y -= leading
+	}
+	fmt.Fprintf(&builder, "BT /F1 8 Tf %.0f %.0f Td (Page %d of %d) Tj ET", margin, margin/2, pageNumber, pageCount)
+	return builder.String()
+}
+
+// escapeText() escapes a PDF string literal. Characters outside of Latin-1 cannot be shown
+// with the standard fonts and are replaced with a question mark.
+func escapeText(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			builder.WriteByte(' ')
+		case r < 32 || r > 255:
+			builder.WriteByte('?')
+		default:
+			builder.WriteByte(byte(r))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocumentBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     int
+		wantPages int
+	}{
+		{name: "empty document", lines: 0, wantPages: 1},
+		{name: "single page", lines: 10, wantPages: 1},
+		{name: "several pages", lines: 150, wantPages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New("Tax Summary 2026", "OptiVest")
+			for i := 0; i < tt.lines; i++ {
+				doc.Text(fmt.Sprintf("line %d", i))
+			}
+			out := doc.Bytes(time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC))
+			if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+				t.Fatalf("Bytes() is not framed as a PDF document")
+			}
+			if got := strings.Count(string(out), "/Type /Page /Parent"); got != tt.wantPages {
+				t.Errorf("Bytes() pages = %d, want %d", got, tt.wantPages)
+			}
+			if !strings.Contains(string(out), fmt.Sprintf("(Page %d of %d)", tt.wantPages, tt.wantPages)) {
+				t.Errorf("Bytes() is missing the last page number")
+			}
+			// every cross-reference entry must point at the start of its object
+			offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(string(out), -1)
+			if len(offsets) != 5+tt.wantPages*2 {
+				t.Fatalf("Bytes() xref entries = %d, want %d", len(offsets), 5+tt.wantPages*2)
+			}
+			for i, match := range offsets {
+				offset, _ := strconv.Atoi(match[1])
+				if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(out[offset:], []byte(want)) {
+					t.Errorf("xref entry %d points at %q, want %q", i+1, out[offset:offset+len(want)], want)
+				}
+			}
+		})
+	}
+}
+
+func TestDocumentText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantLines int
+	}{
+		{name: "short line", text: "Deductible expenses", wantLines: 1},
+		{name: "exactly the maximum", text: strings.Repeat("a", MaxLineLength), wantLines: 1},
+		{name: "wrapped line", text: strings.Repeat("a", MaxLineLength*2+1), wantLines: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New("", "")
+			doc.Text(tt.text)
+			if len(doc.lines) != tt.wantLines {
+				t.Errorf("Text() lines = %d, want %d", len(doc.lines), tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain", text: "Total 1200.00 USD", want: "Total 1200.00 USD"},
+		{name: "parentheses and backslash", text: `Loss (net) \ gain`, want: `Loss \(net\) \\ gain`},
+		{name: "Latin-1 is kept", text: "Café", want: "Caf\xe9"},
+		{name: "other characters are replaced", text: "Rent €", want: "Rent ?"},
+		{name: "control characters", text: "a\tb\nc", want: "a b c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeText(tt.text); got != tt.want {
+				t.Errorf("escapeText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+-- name: CreateNewAccount :one
+INSERT INTO accounts (
+    user_id,
+    name,
+    account_type,
+    currency_code,
+    opening_balance,
+    opening_date,
+    debt_id
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at, updated_at;
+
+-- name: GetAccountByID :one
+SELECT
+    id,
+    user_id,
+    name,
+    account_type,
+    currency_code,
+    opening_balance,
+    opening_date,
+    reconciled_balance,
+    reconciled_at,
+    created_at,
+    updated_at,
+    debt_id
+FROM accounts
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetAccountsByUserID :many
+WITH entries AS (
+    SELECT account_id, -amount AS amount, is_cleared
+    FROM expenses
+    WHERE user_id = $1 AND account_id IS NOT NULL
+    UNION ALL
+    SELECT account_id, amount_original, is_cleared
+    FROM income
+    WHERE user_id = $1 AND account_id IS NOT NULL
+    UNION ALL
+    SELECT account_id, -payment_amount, is_cleared
+    FROM debtpayments
+    WHERE user_id = $1 AND account_id IS NOT NULL
+    UNION ALL
+    SELECT account_id, CASE WHEN transaction_type = 'sell' THEN transaction_amount ELSE -transaction_amount END, is_cleared
+    FROM investment_transactions
+    WHERE user_id = $1 AND account_id IS NOT NULL
+    UNION ALL
+    SELECT from_account_id, -amount, from_cleared
+    FROM account_transfers
+    WHERE user_id = $1
+    UNION ALL
+    SELECT to_account_id, to_amount, to_cleared
+    FROM account_transfers
+    WHERE user_id = $1
+)
+SELECT
+    a.id,
+    a.user_id,
+    a.name,
+    a.account_type,
+    a.currency_code,
+    a.opening_balance,
+    a.opening_date,
+    a.reconciled_balance,
+    a.reconciled_at,
+    a.created_at,
+    a.updated_at,
+    a.debt_id,
+    (a.opening_balance + COALESCE(SUM(e.amount), 0))::NUMERIC AS balance,
+    (a.opening_balance + COALESCE(SUM(e.amount) FILTER (WHERE e.is_cleared), 0))::NUMERIC AS cleared_balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.user_id = $1
+GROUP BY a.id
+ORDER BY a.name;
+
+-- name: LockAccountByID :one
+-- Locks the account until the transaction ends. Posting to the account checks its key and waits
+SELECT id
+FROM accounts
+WHERE id = $1 AND user_id = $2
+FOR UPDATE;
+
+-- name: UpdateAccountByID :one
+UPDATE accounts SET
+    name = $1,
+    account_type = $2,
+    opening_balance = $3,
+    opening_date = $4,
+    debt_id = $5
+WHERE id = $6 AND user_id = $7
+RETURNING updated_at;
+
+-- name: DeleteAccountByID :one
+DELETE FROM accounts
+WHERE id = $1 AND user_id = $2
+RETURNING id;
+
+-- name: GetAccountTransactionsByAccountID :many
+SELECT
+    'expense'::TEXT AS transaction_type,
+    e.id,
+    e.name::TEXT AS description,
+    (-e.amount)::NUMERIC AS amount,
+    e.date_occurred AS transaction_date,
+    e.is_cleared,
+    e.created_at::TIMESTAMPTZ AS created_at
+FROM expenses e
+WHERE e.account_id = $1
+UNION ALL
+SELECT 'income', i.id, i.source, i.amount_original, i.date_received, i.is_cleared, i.created_at
+FROM income i
+WHERE i.account_id = $1
+UNION ALL
+SELECT 'debt_payment', p.id, 'Payment: ' || d.name, -p.payment_amount, p.payment_date::DATE, p.is_cleared, p.created_at
+FROM debtpayments p
+JOIN debts d ON d.id = p.debt_id
+WHERE p.account_id = $1
+UNION ALL
+SELECT
+    'investment_transaction', t.id, INITCAP(t.transaction_type::TEXT) || ' ' || t.investment_type::TEXT,
+    CASE WHEN t.transaction_type = 'sell' THEN t.transaction_amount ELSE -t.transaction_amount END,
+    t.transaction_date, t.is_cleared, t.created_at
+FROM investment_transactions t
+WHERE t.account_id = $1
+UNION ALL
+SELECT 'transfer_out', tr.id, COALESCE(NULLIF(tr.description, ''), 'Transfer to ' || a.name), -tr.amount, tr.transfer_date, tr.from_cleared, tr.created_at
+FROM account_transfers tr
+JOIN accounts a ON a.id = tr.to_account_id
+WHERE tr.from_account_id = $1
+UNION ALL
+SELECT 'transfer_in', tr.id, COALESCE(NULLIF(tr.description, ''), 'Transfer from ' || a.name), tr.to_amount, tr.transfer_date, tr.to_cleared, tr.created_at
+FROM account_transfers tr
+JOIN accounts a ON a.id = tr.from_account_id
+WHERE tr.to_account_id = $1
+ORDER BY transaction_date, created_at, id;
+
+-- name: CreateNewAccountTransfer :one
+INSERT INTO account_transfers (
+    user_id,
+    from_account_id,
+    to_account_id,
+    amount,
+    to_amount,
+    exchange_rate,
+    description,
+    transfer_date
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, created_at;
+
+-- name: DeleteAccountTransferByID :one
+DELETE FROM account_transfers
+WHERE id = $1 AND user_id = $2
+RETURNING id;
+
+-- name: ReconcileAccountByID :one
+WITH cleared_expenses AS (
+    UPDATE expenses SET is_cleared = TRUE
+    WHERE account_id = $1 AND date_occurred <= $2::DATE AND is_cleared = FALSE
+),
+cleared_incomes AS (
+    UPDATE income SET is_cleared = TRUE
+    WHERE account_id = $1 AND date_received <= $2::DATE AND is_cleared = FALSE
+),
+cleared_debt_payments AS (
+    UPDATE debtpayments SET is_cleared = TRUE
+    WHERE account_id = $1 AND payment_date::DATE <= $2::DATE AND is_cleared = FALSE
+),
+cleared_investment_transactions AS (
+    UPDATE investment_transactions SET is_cleared = TRUE
+    WHERE account_id = $1 AND transaction_date <= $2::DATE AND is_cleared = FALSE
+),
+cleared_transfers_out AS (
+    UPDATE account_transfers SET from_cleared = TRUE
+    WHERE from_account_id = $1 AND transfer_date <= $2::DATE AND from_cleared = FALSE
+),
+cleared_transfers_in AS (
+    UPDATE account_transfers SET to_cleared = TRUE
+    WHERE to_account_id = $1 AND transfer_date <= $2::DATE AND to_cleared = FALSE
+)
+UPDATE accounts SET
+    reconciled_balance = $3,
+    reconciled_at = $2::DATE
+WHERE id = $1 AND user_id = $4
+RETURNING updated_at;
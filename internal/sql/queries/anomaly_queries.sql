@@ -0,0 +1,38 @@
+-- name: GetAnomalyExpensesByUserID :many
+SELECT
+    e.id,
+    e.name,
+    e.category,
+    e.amount,
+    e.is_recurring,
+    e.date_occurred,
+    b.currency_code
+FROM expenses e
+JOIN budgets b ON e.budget_id = b.id
+WHERE e.user_id = $1
+AND e.date_occurred >= $2::DATE
+ORDER BY e.date_occurred, e.id;
+
+-- name: CreateSpendingAnomaly :one
+INSERT INTO spending_anomalies (
+    user_id, expense_id, kind, category, name, currency_code, amount,
+    expected_amount, score, occurred_on, message, dedupe_key
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (user_id, dedupe_key) DO NOTHING
+RETURNING id, created_at;
+
+-- name: GetSpendingAnomaliesByUserID :many
+SELECT *
+FROM spending_anomalies
+WHERE user_id = $1
+AND created_at >= $2::TIMESTAMPTZ
+AND ($3::TEXT = '' OR kind::TEXT = $3::TEXT)
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetAnomalyUserIDsSince :many
+-- Expenses posted by recurring expenses count as well, they add to the category spending
+SELECT DISTINCT user_id
+FROM expenses
+WHERE created_at >= $1::TIMESTAMP;
@@ -0,0 +1,40 @@
+-- name: CreateNewExpenseAttachment :one
+INSERT INTO expense_attachments (
+    user_id,
+    expense_id,
+    group_expense_id,
+    storage_key,
+    file_name,
+    content_type,
+    size_bytes
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at;
+
+-- name: GetExpenseAttachmentByID :one
+SELECT id, user_id, expense_id, group_expense_id, storage_key, file_name, content_type, size_bytes, created_at
+FROM expense_attachments
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetExpenseAttachmentsByExpenseID :many
+SELECT id, user_id, expense_id, group_expense_id, storage_key, file_name, content_type, size_bytes, created_at
+FROM expense_attachments
+WHERE expense_id = $1 AND user_id = $2
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetExpenseAttachmentsByGroupID :many
+SELECT ea.id, ea.user_id, ea.expense_id, ea.group_expense_id, ea.storage_key, ea.file_name, ea.content_type, ea.size_bytes, ea.created_at
+FROM expense_attachments ea
+JOIN group_expenses ge ON ge.id = ea.group_expense_id
+WHERE ge.group_id = $1
+ORDER BY ea.created_at DESC, ea.id DESC;
+
+-- name: GetExpenseAttachmentsByGroupExpenseID :many
+SELECT id, user_id, expense_id, group_expense_id, storage_key, file_name, content_type, size_bytes, created_at
+FROM expense_attachments
+WHERE group_expense_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: DeleteExpenseAttachmentByID :one
+DELETE FROM expense_attachments
+WHERE id = $1 AND user_id = $2
+RETURNING storage_key;
@@ -33,3 +33,12 @@ SELECT
     updated_at
 FROM awards;
 
+
+-- name: CreateNewUserAwardByCode :execrows
+-- Grants an award by its code. Awards are only ever granted once, so granting one the user
+-- already holds does nothing
+INSERT INTO user_awards (user_id, award_id)
+SELECT $1, a.id
+FROM awards a
+WHERE a.code = $2
+ON CONFLICT (user_id, award_id) DO NOTHING;
@@ -0,0 +1,73 @@
+-- name: GetCalendarRecurringExpensesByUserID :many
+SELECT
+    re.id,
+    re.name,
+    re.amount,
+    re.recurrence_interval,
+    re.next_occurrence,
+    re.interval_count,
+    re.day_of_month,
+    re.end_date,
+    re.max_occurrences,
+    re.occurrence_count,
+    b.currency_code
+FROM recurring_expenses re
+JOIN budgets b ON b.id = re.budget_id
+WHERE re.user_id = $1
+AND re.is_paused = FALSE
+AND re.next_occurrence <= $2::DATE
+ORDER BY re.next_occurrence ASC, re.id ASC;
+
+-- name: GetCalendarRecurringIncomesByUserID :many
+SELECT
+    id,
+    source,
+    amount_original,
+    original_currency_code,
+    recurrence_interval,
+    next_occurrence,
+    end_date,
+    day_of_month
+FROM recurring_incomes
+WHERE user_id = $1
+AND next_occurrence <= $2::DATE
+AND (end_date IS NULL OR end_date >= next_occurrence)
+ORDER BY next_occurrence ASC, id ASC;
+
+-- name: GetCalendarDebtsByUserID :many
+SELECT
+    id,
+    name,
+    remaining_balance,
+    minimum_payment,
+    next_payment_date
+FROM debts
+WHERE user_id = $1
+AND remaining_balance > 0
+AND next_payment_date <= $2::DATE
+ORDER BY next_payment_date ASC, id ASC;
+
+-- name: GetCalendarGoalsByUserID :many
+SELECT
+    id,
+    name,
+    current_amount,
+    target_amount,
+    end_date
+FROM goals
+WHERE user_id = $1
+AND status = 'ongoing'
+AND end_date BETWEEN $2::DATE AND $3::DATE
+ORDER BY end_date ASC, id ASC;
+
+-- name: GetCalendarBondMaturitiesByUserID :many
+SELECT
+    id,
+    bond_symbol,
+    quantity,
+    purchase_price,
+    maturity_date
+FROM bond_investments
+WHERE user_id = $1
+AND maturity_date BETWEEN $2::DATE AND $3::DATE
+ORDER BY maturity_date ASC, id ASC;
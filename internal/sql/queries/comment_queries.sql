@@ -99,6 +99,17 @@ DELETE FROM comments
 WHERE id = $1 AND user_id = $2
 RETURNING id;
 
+-- name: DeleteCommentByID :one
+-- used by group moderators, who can delete any comment on their group
+DELETE FROM comments
+WHERE id = $1
+RETURNING id;
+
+-- name: GetCommentAssociation :one
+SELECT associated_type, associated_id
+FROM comments
+WHERE id = $1;
+
 -- name: DeleteReaction :one
 DELETE FROM comment_reactions
 WHERE comment_id = $1 AND user_id = $2
@@ -0,0 +1,54 @@
+-- name: SaveExchangeRates :execrows
+INSERT INTO exchange_rates (base_currency, target_currency, rate, rate_date)
+SELECT $1::TEXT, UNNEST($2::TEXT[]), UNNEST($3::NUMERIC[]), $4::DATE
+ON CONFLICT (base_currency, target_currency, rate_date) DO UPDATE
+SET rate = EXCLUDED.rate;
+
+-- name: GetLatestExchangeRatesOnDate :many
+SELECT DISTINCT ON (er.target_currency)
+    er.target_currency,
+    er.rate,
+    er.rate_date
+FROM exchange_rates er
+WHERE er.base_currency = $1
+AND er.target_currency = ANY($2::TEXT[])
+AND er.rate_date BETWEEN $3::DATE AND $4::DATE
+ORDER BY er.target_currency, er.rate_date DESC;
+
+-- name: GetExchangeRateHistory :many
+SELECT
+    er.target_currency,
+    er.rate,
+    er.rate_date
+FROM exchange_rates er
+WHERE er.base_currency = $1
+AND er.target_currency = ANY($2::TEXT[])
+AND er.rate_date BETWEEN $3::DATE AND $4::DATE
+ORDER BY er.rate_date, er.target_currency;
+
+-- name: GetForeignCurrencyIncomesByUserID :many
+SELECT
+    i.id,
+    i.source,
+    i.original_currency_code,
+    i.amount_original,
+    i.amount,
+    i.exchange_rate,
+    i.date_received
+FROM income i
+WHERE i.user_id = $1
+AND i.original_currency_code <> $2
+ORDER BY i.date_received DESC, i.id DESC;
+
+-- name: GetForeignCurrencyBudgetsByUserID :many
+SELECT
+    b.id,
+    b.name,
+    b.currency_code,
+    b.total_amount,
+    b.conversion_rate,
+    b.created_at
+FROM budgets b
+WHERE b.user_id = $1
+AND b.currency_code <> $2
+ORDER BY b.created_at DESC, b.id DESC;
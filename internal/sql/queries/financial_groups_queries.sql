@@ -1,9 +1,9 @@
 
 -- name: CreateNewUserGroup :one
 INSERT INTO groups (
-    creator_user_id, group_image_url, name, is_private, max_member_count, description
+    creator_user_id, group_image_url, name, is_private, max_member_count, description, is_discoverable
 ) VALUES 
-($1, $2, $3, $4, $5, $6)
+($1, $2, $3, $4, $5, $6, $7)
 RETURNING id, creator_user_id, activity_count, last_activity_at, created_at, updated_at, version;
 
 -- name: GetGroupById :one
@@ -19,7 +19,10 @@ SELECT
     last_activity_at,
     created_at,
     updated_at,
-    version
+    version,
+    is_discoverable,
+    archived_at,
+    deletion_scheduled_at
 FROM groups
 WHERE id = $1;
 
@@ -32,10 +35,19 @@ UPDATE groups SET
     description = $5,
     activity_count = $6,
     last_activity_at = $7,
+    is_discoverable = $11,
     updated_at = NOW(),
     version = version + 1
 WHERE
-    id = $8 AND version = $9 AND creator_user_id = $10
+    id = $8 AND version = $9
+    AND EXISTS (                 -- Only members whose role is one of $11 can change its settings
+        SELECT 1
+        FROM group_memberships gm
+        WHERE gm.group_id = groups.id
+          AND gm.user_id = $10
+          AND gm.role::TEXT = ANY($11::TEXT[])
+          AND gm.status = 'accepted'
+    )
 RETURNING updated_at;
 
 -- name: CheckIfGroupMembersAreMaxedOut :one
@@ -45,6 +57,13 @@ JOIN groups g ON g.id = gm.group_id
 WHERE gm.group_id = $1
 GROUP BY g.max_member_count;
 
+-- name: LockGroupByID :one
+-- Locks the group until the transaction ends, so members joining it are counted one after the other
+SELECT id
+FROM groups
+WHERE id = $1
+FOR UPDATE;
+
 -- name: CreateNewGroupInvitation :one
 INSERT INTO group_invitations (
     group_id, inviter_user_id, invitee_user_email, status) 
@@ -119,7 +138,8 @@ WHERE target.group_id = $2
       FROM group_memberships AS gm
       WHERE gm.group_id = $2
         AND gm.user_id = $4  -- The ID of the user performing the update
-        AND gm.role IN ('moderator', 'admin')
+        AND gm.role::TEXT = ANY($5::TEXT[])
+        AND gm.status = 'accepted'
   )
 RETURNING updated_at;
 
@@ -242,17 +262,35 @@ LIMIT $4 OFFSET $5;
 -- name: CreateNewGroupTransaction :one
 INSERT INTO group_transactions (
     goal_id,
+    group_id,
     member_id, 
     amount, 
     description
 ) VALUES 
-($1, $2, $3, $4)
+($1, (SELECT gg.group_id FROM group_goals gg WHERE gg.id = $1), $2, $3, $4)
 RETURNING id, created_at, updated_at;
 
 -- name: DeleteGroupTransaction :one
-DELETE FROM group_transactions
-WHERE id = $1 AND member_id = $2
-RETURNING id;
+-- Members can delete their own transactions, members whose role is one of $3 anyone's in the group
+DELETE FROM group_transactions gt
+WHERE gt.id = $1
+  AND (
+      gt.member_id = $2
+      OR EXISTS (
+          SELECT 1
+          FROM group_memberships gm
+          WHERE gm.group_id = gt.group_id
+            AND gm.user_id = $2
+            AND gm.role::TEXT = ANY($3::TEXT[])
+            AND gm.status = 'accepted'
+      )
+  )
+RETURNING gt.id;
+
+-- name: GetGroupTransactionByID :one
+SELECT id, goal_id, member_id, amount, description, created_at, updated_at, transaction_type, group_id, recipient_member_id
+FROM group_transactions
+WHERE id = $1;
 
 -- name: CheckIfGroupExistsAndUserIsMember :one
 SELECT g.id, g.name
@@ -262,22 +300,56 @@ WHERE g.id = $1         -- Check if the group with this ID exists
   AND gm.user_id = $2  -- Check if this user is a member of the group
   AND gm.status = 'accepted';
 
+-- name: GetGroupMembershipRole :one
+SELECT role
+FROM group_memberships
+WHERE group_id = $1
+  AND user_id = $2
+  AND status = 'accepted';
+
 
 -- name: CreateNewGroupExpense :one
-INSERT INTO group_expenses (
-    group_id, 
-    member_id, 
-    amount, 
-    description, 
-    category
-    )
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at;
+WITH new_expense AS (
+    INSERT INTO group_expenses (
+        group_id, 
+        member_id, 
+        amount, 
+        description, 
+        category,
+        split_type
+        )
+    VALUES ($1, $2, $3, $4, $5, $6)
+    RETURNING id, created_at, updated_at
+),
+expense_splits AS (
+    INSERT INTO group_expense_splits (expense_id, member_id, share, amount)
+    SELECT ne.id, UNNEST($7::BIGINT[]), UNNEST($8::NUMERIC[]), UNNEST($9::NUMERIC[])
+    FROM new_expense ne
+)
+SELECT id, created_at, updated_at
+FROM new_expense;
 
 -- name: DeleteGroupExpense :one
-DELETE FROM group_expenses
-WHERE id = $1 AND member_id = $2
-RETURNING id;
+-- Members can delete their own expenses, members whose role is one of $3 anyone's in the group
+DELETE FROM group_expenses ge
+WHERE ge.id = $1
+  AND (
+      ge.member_id = $2
+      OR EXISTS (
+          SELECT 1
+          FROM group_memberships gm
+          WHERE gm.group_id = ge.group_id
+            AND gm.user_id = $2
+            AND gm.role::TEXT = ANY($3::TEXT[])
+            AND gm.status = 'accepted'
+      )
+  )
+RETURNING ge.id;
+
+-- name: GetGroupExpenseByID :one
+SELECT id, group_id, member_id, amount, description, category, created_at, updated_at, split_type
+FROM group_expenses
+WHERE id = $1;
 
 -- name: GetAllGroupsCreatedByUser :many
 WITH user_groups AS (
@@ -455,6 +527,12 @@ group_members AS (
     GROUP BY gm.group_id, gm.user_id, u.first_name, gm.role, u.profile_avatar_url, gm.approval_time
 ),
 
+group_settlements AS (
+    SELECT gt.id, gt.group_id, gt.member_id, gt.recipient_member_id, gt.amount, gt.description, gt.created_at
+    FROM group_transactions gt
+    WHERE gt.group_id = $1 AND gt.transaction_type = 'settlement'
+),
+
 pending_invitations AS (
     SELECT gi.id, gi.group_id, gi.inviter_user_id, gi.invitee_user_email, gi.status, gi.sent_at, gi.responded_at, gi.expiration_date
     FROM group_invitations gi
@@ -506,7 +584,7 @@ SELECT
                 'profile_avatar_url', gm.profile_avatar_url,
                 'join_date', gm.join_date,              
                 'transaction_count', gm.transaction_count,             
-                'total_transaction_amount', gm.total_transaction_amount 
+                'total_transaction_amount', gm.total_transaction_amount
             )
         )
         FROM group_members gm
@@ -561,7 +639,23 @@ SELECT
             'current_amount', gmt.current_amount
         )
         FROM goal_with_most_transactions gmt), '{}'::jsonb
-    ) AS goal_with_most_transactions
+    ) AS goal_with_most_transactions,
+
+    COALESCE(
+        (SELECT jsonb_agg(
+            jsonb_build_object(
+                'id', gs.id,
+                'group_id', gs.group_id,
+                'member_id', gs.member_id,
+                'recipient_member_id', gs.recipient_member_id,
+                'amount', gs.amount,
+                'description', gs.description,
+                'created_at', gs.created_at
+            ) ORDER BY gs.created_at DESC, gs.id DESC
+        )
+        FROM group_settlements gs), '[]'::jsonb
+    ) AS settlements,
+    (SELECT COALESCE(SUM(gs.amount), 0)::NUMERIC FROM group_settlements gs) AS total_group_settlements
 
 FROM user_groups ug;
 
@@ -569,7 +663,8 @@ FROM user_groups ug;
 WITH public_groups AS (
     SELECT g.*
     FROM groups g
-    WHERE g.is_private = FALSE
+    WHERE (g.is_private = FALSE OR g.is_discoverable = TRUE)   -- Discoverable private groups are listed so users can ask to join
+      AND g.archived_at IS NULL                                -- Archived groups are no longer listed
       AND ($1 = '' OR to_tsvector('simple', g.name) @@ plainto_tsquery('simple', $1))
 ),
 
@@ -651,7 +746,7 @@ OFFSET $2 LIMIT $3;
 
 
 -- name: AdminDeleteGroupMember :one
--- Admin can delete a member from a group who is not an admin
+-- Members whose role is one of $4 can delete a member from a group who is not an admin
 DELETE FROM group_memberships gm_target
 WHERE gm_target.group_id = $1
   AND gm_target.user_id = $2
@@ -661,7 +756,7 @@ WHERE gm_target.group_id = $1
       FROM group_memberships gm_admin
       WHERE gm_admin.group_id = $1
         AND gm_admin.user_id = $3
-        AND gm_admin.role = 'admin'
+        AND gm_admin.role::TEXT = ANY($4::TEXT[])
         AND gm_admin.status = 'accepted'
   )
 RETURNING user_id;
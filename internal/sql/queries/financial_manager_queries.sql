@@ -458,3 +458,8 @@ WHERE b.user_id = $1
 GROUP BY b.id, es.total_expenses, res.total_projected_recurring_expenses, res.recurring_expenses;
 
 
+
+-- name: CreateNewGoalTracking :one
+INSERT INTO goal_tracking (user_id, goal_id, tracking_date, contributed_amount, tracking_type)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, updated_at, truncated_tracking_date;
@@ -1,15 +1,16 @@
 
 -- name: CreateNewRecurringExpense :one
 INSERT INTO recurring_expenses (
-    user_id, budget_id, amount,name, description, recurrence_interval,projected_amount, next_occurrence
+    user_id, budget_id, amount,name, description, recurrence_interval,projected_amount, next_occurrence,
+    interval_count, day_of_month, start_date, last_occurrence, end_date, max_occurrences, occurrence_count,
+    first_occurrence_recorded
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
 )
 RETURNING id, created_at, updated_at;
 
 -- name: GetAllRecurringExpensesDueForProcessing :many
 SELECT
-    COUNT(*) OVER() AS total_count,
     id, 
     user_id, 
     budget_id, 
@@ -20,11 +21,23 @@ SELECT
     projected_amount,
     next_occurrence, 
     created_at, 
-    updated_at
+    updated_at,
+    interval_count,
+    day_of_month,
+    start_date,
+    last_occurrence,
+    end_date,
+    max_occurrences,
+    occurrence_count,
+    is_paused
 FROM recurring_expenses
 WHERE next_occurrence <= CURRENT_DATE
-ORDER BY next_occurrence ASC
-LIMIT $1 OFFSET $2;
+AND is_paused = FALSE
+AND (end_date IS NULL OR next_occurrence <= end_date)
+AND (max_occurrences IS NULL OR occurrence_count < max_occurrences)
+AND id > $1
+ORDER BY id ASC
+LIMIT $2;
 
 -- name: UpdateRecurringExpenseByID :one
 UPDATE recurring_expenses SET
@@ -33,9 +46,16 @@ UPDATE recurring_expenses SET
     description = $3,
     recurrence_interval = $4,
     projected_amount = $5,
-    next_occurrence = $6
+    next_occurrence = $6,
+    interval_count = $7,
+    day_of_month = $8,
+    last_occurrence = $9,
+    end_date = $10,
+    max_occurrences = $11,
+    occurrence_count = $12,
+    is_paused = $13
 WHERE
-    id = $7 AND user_id = $8
+    id = $14 AND user_id = $15
 RETURNING  updated_at;
 
 -- name: GetRecurringExpenseByID :one
@@ -50,7 +70,16 @@ SELECT
     projected_amount,
     next_occurrence, 
     created_at, 
-    updated_at
+    updated_at,
+    interval_count,
+    day_of_month,
+    start_date,
+    last_occurrence,
+    end_date,
+    max_occurrences,
+    occurrence_count,
+    is_paused,
+    first_occurrence_recorded
 FROM recurring_expenses
 WHERE id = $1 AND user_id = $2;
 
@@ -63,9 +92,10 @@ INSERT INTO expenses (
     amount, 
     is_recurring, 
     description, 
-    date_occurred
+    date_occurred,
+    account_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
 RETURNING id, created_at, updated_at;
 
@@ -81,10 +111,17 @@ SELECT
     description, 
     date_occurred, 
     created_at, 
-    updated_at
+    updated_at,
+    account_id,
+    is_cleared
 FROM expenses
 WHERE id = $1 AND user_id = $2;
 
+-- name: DeleteExpenseByID :one
+DELETE FROM expenses
+WHERE id = $1 AND user_id = $2
+RETURNING id;
+
 -- name: UpdateExpenseByID :one
 UPDATE expenses SET
     name = $1,
@@ -92,9 +129,11 @@ UPDATE expenses SET
     amount = $3,
     is_recurring = $4,
     description = $5,
-    date_occurred = $6
+    date_occurred = $6,
+    is_cleared = CASE WHEN account_id IS DISTINCT FROM $7 THEN FALSE ELSE is_cleared END,
+    account_id = $7
 WHERE
-    id = $7 AND user_id = $8
+    id = $8 AND user_id = $9
 RETURNING updated_at;
 
 -- name: CreateNewIncome :one
@@ -106,9 +145,10 @@ RETURNING updated_at;
         amount, 
         exchange_rate, 
         description, 
-        date_received
+        date_received,
+        account_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING id, created_at, updated_at;
 
 -- name: GetAllIncomesByUserID :many
@@ -124,12 +164,33 @@ WITH income_data AS (
         income.description,
         income.date_received,
         income.created_at,
-        income.updated_at
+        income.updated_at,
+        income.account_id,
+        income.is_cleared,
+        COALESCE((
+            SELECT ARRAY_AGG(t.name ORDER BY t.name)
+            FROM income_tags it
+            JOIN tags t ON t.id = it.tag_id
+            WHERE it.income_id = income.id
+        ), '{}')::TEXT[] AS tags,
+        COALESCE((
+            SELECT ARRAY_AGG(itc.tax_category::TEXT ORDER BY itc.tax_category)
+            FROM income_tax_categories itc
+            WHERE itc.income_id = income.id
+        ), '{}')::TEXT[] AS tax_categories
     FROM 
         income
     WHERE 
         income.user_id = $1
         AND ($2 = '' OR to_tsvector('simple', income.source) @@ plainto_tsquery('simple', $2))
+        AND (COALESCE(CARDINALITY($5::TEXT[]), 0) = 0 OR income.id IN (
+            SELECT it.income_id
+            FROM income_tags it
+            JOIN tags t ON t.id = it.tag_id
+            WHERE t.user_id = $1 AND t.name = ANY($5::TEXT[])
+            GROUP BY it.income_id
+            HAVING COUNT(*) = CARDINALITY($5::TEXT[])
+        ))
     ORDER BY 
         income.date_received DESC
     LIMIT $3 OFFSET $4
@@ -163,10 +224,17 @@ FROM
 INSERT INTO debts (
     user_id, name, amount, remaining_balance, interest_rate, description, 
     due_date, minimum_payment, next_payment_date, estimated_payoff_date, 
-    accrued_interest, interest_last_calculated, total_interest_paid
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    accrued_interest, interest_last_calculated, total_interest_paid,
+    compounding_frequency, interest_rate_type, late_fee, grace_period_days
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 RETURNING id, created_at, updated_at;
 
+-- name: CreateNewDebtLateFee :one
+INSERT INTO debt_late_fees (
+    debt_id, user_id, amount, missed_due_date
+) VALUES ($1, $2, $3, $4)
+RETURNING id, created_at;
+
 -- name: UpdateIncomeByID :one
 UPDATE income
 SET
@@ -176,9 +244,11 @@ SET
     amount = $4,
     exchange_rate = $5,
     description = $6,
-    date_received = $7
+    date_received = $7,
+    is_cleared = CASE WHEN account_id IS DISTINCT FROM $8 THEN FALSE ELSE is_cleared END,
+    account_id = $8
 WHERE
-    id=$8 AND user_id=$9
+    id=$9 AND user_id=$10
 RETURNING updated_at;
 
 -- name: GetIncomeByID :one
@@ -193,10 +263,17 @@ SELECT
     description,
     date_received,
     created_at,
-    updated_at
+    updated_at,
+    account_id,
+    is_cleared
 FROM income
 WHERE id = $1 AND user_id = $2;
 
+-- name: DeleteIncomeByID :one
+DELETE FROM income
+WHERE id = $1 AND user_id = $2
+RETURNING id;
+
 
 -- name: UpdateDebtByID :one
 UPDATE debts
@@ -213,7 +290,12 @@ SET
     total_interest_paid = $11,                  -- New total interest paid
     estimated_payoff_date = $12,                -- New estimated payoff date
     interest_last_calculated = $13,               -- New interest last calculated date
-    last_payment_date = $14                     -- New last payment date
+    last_payment_date = $14,                    -- New last payment date
+    compounding_frequency = $16,                -- New compounding frequency
+    interest_rate_type = $17,                   -- New interest rate type
+    late_fee = $18,                             -- New late fee
+    grace_period_days = $19,                    -- New grace period
+    last_overdue_due_date = $20                 -- New last overdue due date
 WHERE
     id = $1 AND user_id=$15                                   -- ID of the debt to update
 RETURNING updated_at;
@@ -237,6 +319,11 @@ SELECT
     interest_last_calculated,
     last_payment_date,
     total_interest_paid,
+    compounding_frequency,
+    interest_rate_type,
+    late_fee,
+    grace_period_days,
+    last_overdue_due_date,
     COUNT(*) OVER() AS total_debts,
     CAST(SUM(amount) OVER() AS NUMERIC) AS total_amounts,                -- Cast after SUM
     CAST(SUM(remaining_balance) OVER() AS NUMERIC) AS total_remaining_balances
@@ -289,7 +376,12 @@ SELECT
     accrued_interest, 
     interest_last_calculated, 
     last_payment_date, 
-    total_interest_paid
+    total_interest_paid,
+    compounding_frequency,
+    interest_rate_type,
+    late_fee,
+    grace_period_days,
+    last_overdue_due_date
 FROM debts
 WHERE id = $1;
 
@@ -300,20 +392,21 @@ INSERT INTO debtpayments (
     payment_amount,
     payment_date,
     interest_payment,
-    principal_payment
+    principal_payment,
+    account_id
 ) VALUES (
     $1, -- debt_id
     $2, -- user_id
     $3, -- payment_amount
     $4, -- payment_date
     $5, -- interest_payment
-    $6  -- principal_payment
+    $6, -- principal_payment
+    $7  -- account_id
 )
 RETURNING id, created_at;
 
--- name: GetAllOverdueDebts :many
+-- name: GetAllDebtsDueForInterestAccrual :many
 SELECT 
-    COUNT(*) OVER() AS total_count,
     id, 
     user_id, 
     name, 
@@ -330,13 +423,26 @@ SELECT
     accrued_interest, 
     interest_last_calculated, 
     last_payment_date, 
-    total_interest_paid
+    total_interest_paid,
+    compounding_frequency,
+    interest_rate_type,
+    late_fee,
+    grace_period_days,
+    last_overdue_due_date
 FROM 
     debts
 WHERE 
     remaining_balance > 0  -- Debt is not fully paid
-AND (interest_last_calculated IS NULL OR interest_last_calculated < CURRENT_DATE) -- Interest calculation is overdue
-LIMIT $1 OFFSET $2;
+AND (
+    interest_last_calculated IS NULL OR interest_last_calculated < CURRENT_DATE -- Interest has not been accrued today
+    OR (
+        -- The earliest missed payment that was not charged is overdue past the grace period
+        GREATEST(next_payment_date, (last_overdue_due_date + INTERVAL '1 month')::date) + grace_period_days < CURRENT_DATE
+    )
+)
+AND id > $1
+ORDER BY id ASC
+LIMIT $2;
 
 -- name: GetAllExpensesByUserID :many
 SELECT 
@@ -351,11 +457,32 @@ SELECT
     e.date_occurred,
     e.created_at,
     e.updated_at,
+    e.account_id,
+    e.is_cleared,
+    COALESCE((
+        SELECT ARRAY_AGG(t.name ORDER BY t.name)
+        FROM expense_tags et
+        JOIN tags t ON t.id = et.tag_id
+        WHERE et.expense_id = e.id
+    ), '{}')::TEXT[] AS tags,
+    COALESCE((
+        SELECT ARRAY_AGG(etc.tax_category::TEXT ORDER BY etc.tax_category)
+        FROM expense_tax_categories etc
+        WHERE etc.expense_id = e.id
+    ), '{}')::TEXT[] AS tax_categories,
     COUNT(*) OVER () AS total_count
 FROM 
     expenses e
 WHERE e.user_id = $1  -- Filter by user ID
 AND ($2 = '' OR to_tsvector('simple', e.name) @@ plainto_tsquery('simple', $2))
+AND (COALESCE(CARDINALITY($5::TEXT[]), 0) = 0 OR e.id IN (
+    SELECT et.expense_id
+    FROM expense_tags et
+    JOIN tags t ON t.id = et.tag_id
+    WHERE t.user_id = $1 AND t.name = ANY($5::TEXT[])
+    GROUP BY et.expense_id
+    HAVING COUNT(*) = CARDINALITY($5::TEXT[])
+))
 ORDER BY 
     e.date_occurred DESC
 LIMIT 
@@ -375,6 +502,14 @@ SELECT
     re.next_occurrence,
     re.created_at,
     re.updated_at,
+    re.interval_count,
+    re.day_of_month,
+    re.start_date,
+    re.last_occurrence,
+    re.end_date,
+    re.max_occurrences,
+    re.occurrence_count,
+    re.is_paused,
     COALESCE(SUM(e.amount), 0)::NUMERIC AS total_expenses,
     COUNT(*) OVER() AS total_count
 FROM 
@@ -405,3 +540,129 @@ LIMIT
     $3  -- Limit value for pagination
 OFFSET 
     $4; -- Offset value for pagination
+
+
+-- name: CreateNewRecurringIncome :one
+INSERT INTO recurring_incomes (
+    user_id, source, original_currency_code, amount_original, description,
+    recurrence_interval, next_occurrence, end_date, goal_id, goal_allocation_percentage, day_of_month
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+RETURNING id, created_at, updated_at;
+
+-- name: UpdateRecurringIncomeByID :one
+UPDATE recurring_incomes SET
+    source = $1,
+    original_currency_code = $2,
+    amount_original = $3,
+    description = $4,
+    recurrence_interval = $5,
+    next_occurrence = $6,
+    end_date = $7,
+    goal_id = $8,
+    goal_allocation_percentage = $9,
+    last_posted_at = $10,
+    day_of_month = $11
+WHERE
+    id = $12 AND user_id = $13
+RETURNING updated_at;
+
+-- name: GetRecurringIncomeByID :one
+SELECT
+    id,
+    user_id,
+    source,
+    original_currency_code,
+    amount_original,
+    description,
+    recurrence_interval,
+    next_occurrence,
+    end_date,
+    goal_id,
+    goal_allocation_percentage,
+    last_posted_at,
+    created_at,
+    updated_at,
+    day_of_month
+FROM recurring_incomes
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetAllRecurringIncomesByUserID :many
+SELECT
+    COUNT(*) OVER() AS total_count,
+    id,
+    user_id,
+    source,
+    original_currency_code,
+    amount_original,
+    description,
+    recurrence_interval,
+    next_occurrence,
+    end_date,
+    goal_id,
+    goal_allocation_percentage,
+    last_posted_at,
+    created_at,
+    updated_at,
+    day_of_month
+FROM recurring_incomes
+WHERE
+    user_id = $1
+    AND ($2 = '' OR to_tsvector('simple', source) @@ plainto_tsquery('simple', $2))
+ORDER BY created_at DESC
+LIMIT $3 OFFSET $4;
+
+-- name: GetAllRecurringIncomesDueForProcessing :many
+SELECT
+    ri.id,
+    ri.user_id,
+    ri.source,
+    ri.original_currency_code,
+    ri.amount_original,
+    ri.description,
+    ri.recurrence_interval,
+    ri.next_occurrence,
+    ri.end_date,
+    ri.goal_id,
+    ri.goal_allocation_percentage,
+    ri.last_posted_at,
+    ri.created_at,
+    ri.updated_at,
+    ri.day_of_month,
+    u.currency_code AS user_currency_code
+FROM recurring_incomes ri
+JOIN users u ON ri.user_id = u.id
+WHERE ri.next_occurrence <= CURRENT_DATE
+AND (ri.end_date IS NULL OR ri.next_occurrence <= ri.end_date)
+AND ri.id > $1
+ORDER BY ri.id ASC
+LIMIT $2;
+
+-- name: SaveDebtPayoffPlan :one
+INSERT INTO debt_payoff_plans (
+    user_id, strategy, monthly_budget, custom_order, projected_payoff_date, total_months, total_interest
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (user_id) DO UPDATE SET
+    strategy = EXCLUDED.strategy,
+    monthly_budget = EXCLUDED.monthly_budget,
+    custom_order = EXCLUDED.custom_order,
+    projected_payoff_date = EXCLUDED.projected_payoff_date,
+    total_months = EXCLUDED.total_months,
+    total_interest = EXCLUDED.total_interest
+RETURNING id, created_at, updated_at;
+
+-- name: GetDebtPayoffPlanByUserID :one
+SELECT
+    id,
+    user_id,
+    strategy,
+    monthly_budget,
+    custom_order,
+    projected_payoff_date,
+    total_months,
+    total_interest,
+    created_at,
+    updated_at
+FROM debt_payoff_plans
+WHERE user_id = $1;
@@ -0,0 +1,23 @@
+-- name: CreateGroupActivity :one
+-- Records an activity and returns who did it, so it can be shown to the group straight away
+WITH activity AS (
+    INSERT INTO group_activities (group_id, user_id, activity_type, subject_id, description)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING id, user_id, created_at
+)
+SELECT a.id, a.created_at, u.first_name, u.last_name, u.profile_avatar_url
+FROM activity a
+JOIN users u ON u.id = a.user_id;
+
+-- name: GetGroupActivitiesByGroupID :many
+-- Returns a page of a group's timeline, newest first, optionally narrowed to one type of activity
+SELECT
+    COUNT(*) OVER() AS total_activities,
+    ga.id, ga.group_id, ga.user_id, ga.activity_type, ga.subject_id, ga.description, ga.created_at,
+    u.first_name, u.last_name, u.profile_avatar_url
+FROM group_activities ga
+JOIN users u ON u.id = ga.user_id
+WHERE ga.group_id = $1
+  AND ($2::text = '' OR ga.activity_type::text = $2::text)
+ORDER BY ga.created_at DESC, ga.id DESC
+LIMIT $3 OFFSET $4;
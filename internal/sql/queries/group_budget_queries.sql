@@ -0,0 +1,99 @@
+-- name: CreateNewGroupBudget :one
+-- Only members whose role is one of $8, the roles that manage budgets, can create them
+INSERT INTO group_budgets (group_id, creator_user_id, category, limit_amount, period, is_strict, description)
+SELECT $1, $2, $3, $4, $5, $6, $7
+WHERE EXISTS (
+    SELECT 1
+    FROM group_memberships gm
+    WHERE gm.group_id = $1
+      AND gm.user_id = $2
+      AND gm.role::TEXT = ANY($8::TEXT[])
+      AND gm.status = 'accepted'
+)
+RETURNING id, created_at, updated_at;
+
+-- name: UpdateGroupBudget :one
+-- Only members whose role is one of $8, the roles that manage budgets, can change them
+UPDATE group_budgets gb SET
+    category = $1,
+    limit_amount = $2,
+    period = $3,
+    is_strict = $4,
+    description = $5
+WHERE gb.id = $6
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = gb.group_id
+        AND gm.user_id = $7
+        AND gm.role::TEXT = ANY($8::TEXT[])
+        AND gm.status = 'accepted'
+  )
+RETURNING updated_at;
+
+-- name: DeleteGroupBudget :one
+-- Only members whose role is one of $3, the roles that manage budgets, can delete them
+DELETE FROM group_budgets gb
+WHERE gb.id = $1
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = gb.group_id
+        AND gm.user_id = $2
+        AND gm.role::TEXT = ANY($3::TEXT[])
+        AND gm.status = 'accepted'
+  )
+RETURNING id;
+
+-- name: GetGroupBudgetByID :one
+SELECT id, group_id, creator_user_id, category, limit_amount, period, is_strict, description, created_at, updated_at
+FROM group_budgets
+WHERE id = $1;
+
+-- name: GetGroupBudgetSummariesByGroupID :many
+-- Every budget of a group with what the group has spent in its category since the current period started.
+-- Periods start on UTC days whatever the session's time zone is
+WITH budget_periods AS (
+    SELECT
+        gb.id,
+        DATE_TRUNC(
+            CASE gb.period
+                WHEN 'weekly' THEN 'week'
+                WHEN 'monthly' THEN 'month'
+                WHEN 'quarterly' THEN 'quarter'
+                ELSE 'year'
+            END,
+            NOW() AT TIME ZONE 'UTC'
+        ) AT TIME ZONE 'UTC' AS period_start
+    FROM group_budgets gb
+    WHERE gb.group_id = $1
+)
+SELECT
+    gb.id,
+    gb.group_id,
+    gb.creator_user_id,
+    gb.category,
+    gb.limit_amount,
+    gb.period,
+    gb.is_strict,
+    gb.description,
+    gb.created_at,
+    gb.updated_at,
+    bp.period_start,
+    COALESCE(SUM(ge.amount), 0)::NUMERIC AS spent
+FROM group_budgets gb
+JOIN budget_periods bp ON bp.id = gb.id
+LEFT JOIN group_expenses ge
+    ON ge.group_id = gb.group_id
+   AND LOWER(ge.category) = LOWER(gb.category)
+   AND ge.created_at >= bp.period_start
+GROUP BY gb.id, bp.period_start
+ORDER BY gb.category;
+
+-- name: LockGroupBudgetsByCategory :many
+-- Locks the group's budgets for a category until the transaction ends, so expenses checked against
+-- them are saved one after the other
+SELECT id
+FROM group_budgets
+WHERE group_id = $1 AND LOWER(TRIM(category)) = LOWER(TRIM($2::TEXT))
+FOR UPDATE;
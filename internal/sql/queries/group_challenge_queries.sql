@@ -0,0 +1,128 @@
+-- name: CreateNewGroupChallenge :one
+INSERT INTO group_challenges (
+    group_id, creator_user_id, name, description, challenge_type, target_amount, weekends_only, starts_on, ends_on
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, status, created_at, updated_at;
+
+-- name: GetGroupChallengeByID :one
+SELECT
+    id, group_id, creator_user_id, name, description, challenge_type, target_amount, weekends_only,
+    starts_on, ends_on, status, created_at, updated_at
+FROM group_challenges
+WHERE id = $1;
+
+-- name: GetGroupChallengesByGroupID :many
+-- Returns the challenges of a group, running ones first, with how many members took part and
+-- whether the user is one of them
+SELECT
+    gc.id, gc.group_id, gc.creator_user_id, gc.name, gc.description, gc.challenge_type, gc.target_amount, gc.weekends_only,
+    gc.starts_on, gc.ends_on, gc.status, gc.created_at, gc.updated_at,
+    (SELECT COUNT(*) FROM group_challenge_participants p WHERE p.challenge_id = gc.id) AS participant_count,
+    EXISTS (
+        SELECT 1 FROM group_challenge_participants p WHERE p.challenge_id = gc.id AND p.user_id = $2
+    ) AS is_participant
+FROM group_challenges gc
+WHERE gc.group_id = $1
+ORDER BY gc.status ASC, gc.ends_on ASC, gc.id ASC;
+
+-- name: DeleteGroupChallenge :one
+DELETE FROM group_challenges
+WHERE id = $1
+RETURNING id;
+
+-- name: GetActiveGroupChallenges :many
+-- Returns the challenges that have started and not yet ended, in groups that are not archived
+SELECT
+    gc.id, gc.group_id, gc.creator_user_id, gc.name, gc.description, gc.challenge_type, gc.target_amount, gc.weekends_only,
+    gc.starts_on, gc.ends_on, gc.status, gc.created_at, gc.updated_at
+FROM group_challenges gc
+JOIN groups g ON g.id = gc.group_id
+WHERE gc.status = 'active'
+  AND gc.starts_on <= $1
+  AND g.archived_at IS NULL
+ORDER BY gc.ends_on ASC, gc.id ASC;
+
+-- name: EndGroupChallenge :one
+-- Ends a challenge that is still active, so only one run gets to settle it
+UPDATE group_challenges
+SET status = 'ended'
+WHERE id = $1 AND status = 'active'
+RETURNING id;
+
+-- name: JoinGroupChallenge :one
+INSERT INTO group_challenge_participants (challenge_id, user_id, goal_id)
+VALUES ($1, $2, $3)
+RETURNING id, joined_at;
+
+-- name: LeaveGroupChallenge :one
+DELETE FROM group_challenge_participants
+WHERE challenge_id = $1 AND user_id = $2
+RETURNING id;
+
+-- name: GetGroupChallengeLeaderboard :many
+-- Ranks the participants of a challenge. Savings progress is what they contributed to the group's
+-- goals plus their linked personal goal within the challenge's dates, highest first. No-spend
+-- progress is the number of days they added a group expense on, lowest first. Participants who
+-- are no longer members of the group are left out
+WITH progress AS (
+    SELECT
+        p.user_id,
+        p.goal_id,
+        p.rank,
+        p.completed_at,
+        p.joined_at,
+        gc.challenge_type,
+        (CASE
+            WHEN gc.challenge_type = 'savings' THEN
+                COALESCE((
+                    SELECT SUM(gt.amount)
+                    FROM group_transactions gt
+                    WHERE gt.group_id = gc.group_id
+                      AND gt.member_id = p.user_id
+                      AND gt.transaction_type = 'contribution'
+                      AND gt.created_at::DATE BETWEEN gc.starts_on AND gc.ends_on
+                ), 0)
+                + COALESCE((
+                    SELECT SUM(t.contributed_amount)
+                    FROM goal_tracking t
+                    WHERE t.goal_id = p.goal_id
+                      AND t.user_id = p.user_id
+                      AND t.tracking_date BETWEEN gc.starts_on AND gc.ends_on
+                ), 0)
+            ELSE (
+                SELECT COUNT(DISTINCT ge.created_at::DATE)
+                FROM group_expenses ge
+                WHERE ge.group_id = gc.group_id
+                  AND ge.member_id = p.user_id
+                  AND ge.created_at::DATE BETWEEN gc.starts_on AND gc.ends_on
+                  AND (NOT gc.weekends_only OR EXTRACT(ISODOW FROM ge.created_at) >= 6)
+            )
+        END)::NUMERIC AS progress
+    FROM group_challenge_participants p
+    JOIN group_challenges gc ON gc.id = p.challenge_id
+    JOIN group_memberships gm ON gm.group_id = gc.group_id AND gm.user_id = p.user_id AND gm.status = 'accepted'
+    WHERE p.challenge_id = $1
+)
+SELECT
+    (RANK() OVER (
+        ORDER BY CASE WHEN pr.challenge_type = 'savings' THEN -pr.progress ELSE pr.progress END
+    ))::INTEGER AS current_rank,
+    pr.user_id, pr.goal_id, pr.rank AS previous_rank, pr.completed_at, pr.joined_at,
+    pr.progress::TEXT AS progress,
+    u.first_name, u.last_name, u.profile_avatar_url
+FROM progress pr
+JOIN users u ON u.id = pr.user_id
+ORDER BY current_rank ASC, pr.joined_at ASC;
+
+-- name: UpdateGroupChallengeParticipantRank :exec
+UPDATE group_challenge_participants
+SET rank = $3
+WHERE challenge_id = $1 AND user_id = $2;
+
+-- name: CompleteGroupChallengeParticipant :one
+-- Marks a participant as having completed the challenge, only the first time
+UPDATE group_challenge_participants
+SET completed_at = NOW()
+WHERE challenge_id = $1 AND user_id = $2 AND completed_at IS NULL
+RETURNING completed_at;
@@ -0,0 +1,144 @@
+-- name: CreateNewGroupContributionSchedule :one
+-- Only members of the goal's group whose role is one of $8, the roles that manage contributions, can
+-- schedule them
+INSERT INTO group_contribution_schedules (
+    group_id, goal_id, creator_user_id, amount_per_member, recurrence_interval, due_day, next_due_date, description)
+SELECT gg.group_id, $1, $2, $3, $4, $5, $6, $7
+FROM group_goals gg
+WHERE gg.id = $1
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = gg.group_id
+        AND gm.user_id = $2
+        AND gm.role::TEXT = ANY($8::TEXT[])
+        AND gm.status = 'accepted'
+  )
+RETURNING id, group_id, created_at, updated_at;
+
+-- name: UpdateGroupContributionSchedule :one
+-- Only members whose role is one of $7, the roles that manage contributions, can change a schedule
+UPDATE group_contribution_schedules s SET
+    amount_per_member = $1,
+    is_active = $2,
+    next_due_date = $3,
+    description = $4
+WHERE s.id = $5
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = s.group_id
+        AND gm.user_id = $6
+        AND gm.role::TEXT = ANY($7::TEXT[])
+        AND gm.status = 'accepted'
+  )
+RETURNING updated_at;
+
+-- name: DeleteGroupContributionSchedule :one
+-- Only members whose role is one of $3, the roles that manage contributions, can delete a schedule,
+-- its dues go with it
+DELETE FROM group_contribution_schedules s
+WHERE s.id = $1
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = s.group_id
+        AND gm.user_id = $2
+        AND gm.role::TEXT = ANY($3::TEXT[])
+        AND gm.status = 'accepted'
+  )
+RETURNING id;
+
+-- name: GetGroupContributionScheduleByID :one
+SELECT
+    s.id, s.group_id, s.goal_id, s.creator_user_id, s.amount_per_member, s.recurrence_interval,
+    s.due_day, s.next_due_date, s.is_active, s.description, s.created_at, s.updated_at,
+    gg.goal_name
+FROM group_contribution_schedules s
+JOIN group_goals gg ON gg.id = s.goal_id
+WHERE s.id = $1;
+
+-- name: GetGroupContributionSchedulesByGroupID :many
+SELECT
+    s.id, s.group_id, s.goal_id, s.creator_user_id, s.amount_per_member, s.recurrence_interval,
+    s.due_day, s.next_due_date, s.is_active, s.description, s.created_at, s.updated_at,
+    gg.goal_name
+FROM group_contribution_schedules s
+JOIN group_goals gg ON gg.id = s.goal_id
+WHERE s.group_id = $1
+ORDER BY s.next_due_date, s.id;
+
+-- name: GetGroupContributionSchedulesDue :many
+-- Active schedules of ongoing goals that have fallen due
+SELECT
+    s.id, s.group_id, s.goal_id, s.creator_user_id, s.amount_per_member, s.recurrence_interval,
+    s.due_day, s.next_due_date, s.is_active, s.description, s.created_at, s.updated_at,
+    gg.goal_name
+FROM group_contribution_schedules s
+JOIN group_goals gg ON gg.id = s.goal_id
+JOIN groups g ON g.id = s.group_id
+WHERE s.is_active
+  AND s.next_due_date <= $1
+  AND gg.status = 'ongoing'
+  AND g.archived_at IS NULL
+ORDER BY s.next_due_date, s.id;
+
+-- name: UpdateGroupContributionScheduleNextDueDate :exec
+UPDATE group_contribution_schedules
+SET next_due_date = $2
+WHERE id = $1;
+
+-- name: CreateGroupContributionDues :many
+-- Gives every accepted member of the group a pending due, the members are told about it straight away
+INSERT INTO group_contribution_dues (schedule_id, group_id, goal_id, member_id, amount, due_date, reminded_at)
+SELECT s.id, s.group_id, s.goal_id, gm.user_id, s.amount_per_member, $2, NOW()
+FROM group_contribution_schedules s
+JOIN group_memberships gm ON gm.group_id = s.group_id AND gm.status = 'accepted'
+WHERE s.id = $1
+ON CONFLICT (schedule_id, member_id, due_date) DO NOTHING
+RETURNING member_id;
+
+-- name: GetGroupContributionDuesToRemind :many
+-- Overdue dues the member has not been told about since $2, with what is still owed on them. Only
+-- members still in the group are reminded and archived groups are left alone
+SELECT
+    d.id,
+    d.member_id,
+    (d.amount - d.amount_paid)::NUMERIC AS amount,
+    d.due_date,
+    gg.goal_name,
+    g.name AS group_name
+FROM group_contribution_dues d
+JOIN group_goals gg ON gg.id = d.goal_id
+JOIN groups g ON g.id = d.group_id
+JOIN group_memberships gm ON gm.group_id = d.group_id AND gm.user_id = d.member_id AND gm.status = 'accepted'
+WHERE d.status = 'pending'
+  AND g.archived_at IS NULL
+  AND d.due_date < $1
+  AND (d.reminded_at IS NULL OR d.reminded_at < $2)
+ORDER BY d.member_id, d.due_date;
+
+-- name: MarkGroupContributionDuesReminded :exec
+UPDATE group_contribution_dues
+SET reminded_at = NOW()
+WHERE id = ANY($1::BIGINT[]);
+
+-- name: GetGroupContributionArrearsByGroupID :many
+-- What each accepted member has paid and still owes of the dues that fell due by $2, partly paid
+-- dues count towards both
+SELECT
+    gm.user_id AS member_id,
+    COUNT(d.id) FILTER (WHERE d.status = 'pending') AS pending_dues,
+    COALESCE(SUM(d.amount - d.amount_paid) FILTER (WHERE d.status = 'pending'), 0)::NUMERIC AS amount_owed,
+    COALESCE(MIN(d.due_date) FILTER (WHERE d.status = 'pending'), $2)::DATE AS oldest_due_date,
+    COUNT(d.id) FILTER (WHERE d.status = 'paid') AS paid_dues,
+    COALESCE(SUM(d.amount_paid), 0)::NUMERIC AS amount_paid
+FROM group_memberships gm
+LEFT JOIN group_contribution_dues d
+    ON d.group_id = gm.group_id
+   AND d.member_id = gm.user_id
+   AND d.due_date <= $2
+WHERE gm.group_id = $1
+  AND gm.status = 'accepted'
+GROUP BY gm.user_id
+ORDER BY amount_owed DESC, gm.user_id;
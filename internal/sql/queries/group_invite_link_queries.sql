@@ -0,0 +1,69 @@
+-- name: CreateNewGroupInviteLink :one
+-- Only members whose role is one of $7, the roles that manage invite links, can make them
+INSERT INTO group_invite_links (group_id, creator_user_id, token_hash, role, max_uses, expires_at)
+SELECT $1, $2, $3, $4, $5, $6
+WHERE EXISTS (
+    SELECT 1
+    FROM group_memberships gm
+    WHERE gm.group_id = $1
+      AND gm.user_id = $2
+      AND gm.role::TEXT = ANY($7::TEXT[])
+      AND gm.status = 'accepted'
+)
+RETURNING id, created_at, updated_at;
+
+-- name: GetGroupInviteLinksByGroupID :many
+SELECT
+    id, group_id, creator_user_id, role, max_uses, use_count, expires_at, revoked_at, created_at, updated_at
+FROM group_invite_links
+WHERE group_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetGroupInviteLinkByTokenHash :one
+SELECT
+    id, group_id, creator_user_id, role, max_uses, use_count, expires_at, revoked_at, created_at, updated_at
+FROM group_invite_links
+WHERE token_hash = $1;
+
+-- name: RevokeGroupInviteLink :one
+-- Only members whose role is one of $3, the roles that manage invite links, can revoke them
+UPDATE group_invite_links l SET
+    revoked_at = NOW()
+WHERE l.id = $1
+  AND l.revoked_at IS NULL
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = l.group_id
+        AND gm.user_id = $2
+        AND gm.role::TEXT = ANY($3::TEXT[])
+        AND gm.status = 'accepted'
+  )
+RETURNING revoked_at, updated_at;
+
+-- name: CreateGroupMembershipFromInviteLink :one
+-- Uses up one of the link's uses and adds the user to the group with the link's role in one go,
+-- nothing is returned when the link is revoked, expired or used up or the group is full. The group
+-- is locked so joins through its other links wait for this one
+WITH link_group AS (
+    SELECT g.id, g.max_member_count
+    FROM groups g
+    JOIN group_invite_links l ON l.group_id = g.id
+    WHERE l.id = $1
+    FOR UPDATE OF g
+),
+used_link AS (
+    UPDATE group_invite_links l SET
+        use_count = l.use_count + 1
+    FROM link_group lg
+    WHERE l.id = $1
+      AND l.revoked_at IS NULL
+      AND l.expires_at > NOW()
+      AND l.use_count < l.max_uses
+      AND (SELECT COUNT(*) FROM group_memberships gm WHERE gm.group_id = lg.id) < lg.max_member_count
+    RETURNING l.group_id, l.role
+)
+INSERT INTO group_memberships (group_id, user_id, status, approval_time, role)
+SELECT group_id, $2, 'accepted', NOW(), role
+FROM used_link
+RETURNING id;
@@ -0,0 +1,48 @@
+-- name: CreateNewGroupJoinRequest :one
+INSERT INTO group_join_requests (group_id, user_id, message)
+VALUES ($1, $2, $3)
+RETURNING id, status, created_at, updated_at;
+
+-- name: GetGroupJoinRequestByID :one
+SELECT
+    id, group_id, user_id, message, status, reviewer_user_id, reviewed_at, created_at, updated_at
+FROM group_join_requests
+WHERE id = $1;
+
+-- name: GetPendingGroupJoinRequestsByGroupID :many
+SELECT
+    r.id, r.group_id, r.user_id, r.message, r.status, r.reviewer_user_id, r.reviewed_at, r.created_at, r.updated_at,
+    u.first_name, u.last_name, u.profile_avatar_url
+FROM group_join_requests r
+JOIN users u ON u.id = r.user_id
+WHERE r.group_id = $1
+  AND r.status = 'pending'
+ORDER BY r.created_at ASC, r.id ASC;
+
+-- name: ReviewGroupJoinRequest :one
+-- Only members whose role is one of $4, the roles that review join requests, can approve or reject a
+-- request, and only while it is pending
+UPDATE group_join_requests r SET
+    status = $1,
+    reviewer_user_id = $2,
+    reviewed_at = NOW()
+WHERE r.id = $3
+  AND r.status = 'pending'
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = r.group_id
+        AND gm.user_id = $2
+        AND gm.role::TEXT = ANY($4::TEXT[])
+        AND gm.status = 'accepted'
+  )
+RETURNING reviewed_at, updated_at;
+
+-- name: GetGroupModeratorUserIDs :many
+-- Returns the members of a group whose role is one of $2, the roles that review its join requests
+SELECT user_id
+FROM group_memberships
+WHERE group_id = $1
+  AND role::TEXT = ANY($2::TEXT[])
+  AND status = 'accepted'
+ORDER BY user_id;
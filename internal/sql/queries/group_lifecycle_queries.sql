@@ -0,0 +1,152 @@
+-- name: GetGroupMemberAccess :one
+-- The user's role in the group and whether the group is archived, used to authorise group actions
+SELECT gm.role, g.archived_at
+FROM group_memberships gm
+JOIN groups g ON g.id = gm.group_id
+WHERE gm.group_id = $1
+  AND gm.user_id = $2
+  AND gm.status = 'accepted';
+
+-- name: TransferGroupOwnership :one
+-- Only the owner can hand the group over, and only to another admin of the group
+UPDATE groups g
+SET creator_user_id = $2, updated_at = NOW(), version = g.version + 1
+WHERE g.id = $1
+  AND g.creator_user_id = $3
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = g.id
+        AND gm.user_id = $2
+        AND gm.role = 'admin'
+        AND gm.status = 'accepted'
+  )
+RETURNING g.updated_at, g.version;
+
+-- name: ArchiveGroup :one
+UPDATE groups g
+SET archived_at = NOW(), updated_at = NOW(), version = g.version + 1
+WHERE g.id = $1
+  AND g.archived_at IS NULL
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = g.id
+        AND gm.user_id = $2
+        AND gm.role::TEXT = ANY($3::TEXT[])
+        AND gm.status = 'accepted'
+  )
+RETURNING g.archived_at, g.updated_at, g.version;
+
+-- name: UnarchiveGroup :one
+-- Restoring a group also cancels its deletion
+UPDATE groups g
+SET archived_at = NULL, deletion_scheduled_at = NULL, updated_at = NOW(), version = g.version + 1
+WHERE g.id = $1
+  AND g.archived_at IS NOT NULL
+  AND EXISTS (
+      SELECT 1
+      FROM group_memberships gm
+      WHERE gm.group_id = g.id
+        AND gm.user_id = $2
+        AND gm.role::TEXT = ANY($3::TEXT[])
+        AND gm.status = 'accepted'
+  )
+RETURNING g.updated_at, g.version;
+
+-- name: ScheduleGroupDeletion :one
+-- Only the owner can delete the group. It is archived straight away and purged once the date passes
+UPDATE groups g
+SET archived_at = COALESCE(g.archived_at, NOW()), deletion_scheduled_at = $2, updated_at = NOW(), version = g.version + 1
+WHERE g.id = $1
+  AND g.creator_user_id = $3
+  AND g.deletion_scheduled_at IS NULL
+RETURNING g.archived_at, g.deletion_scheduled_at, g.updated_at, g.version;
+
+-- name: GetGroupsDueForDeletion :many
+SELECT id, name
+FROM groups
+WHERE deletion_scheduled_at <= $1
+ORDER BY deletion_scheduled_at, id
+LIMIT $2;
+
+-- name: DeleteGroup :one
+-- Everything in the group goes with it, except comments which are not tied to it by a foreign key
+WITH deleted_comments AS (
+    DELETE FROM comments
+    WHERE associated_type = 'group' AND associated_id = $1
+)
+DELETE FROM groups
+WHERE id = $1 AND deletion_scheduled_at IS NOT NULL
+RETURNING id;
+
+-- name: GetGroupSuccessor :one
+-- The member who takes over when the given user leaves: another admin if there is one, then the
+-- longest standing moderator and then the longest standing member
+SELECT gm.user_id, gm.role
+FROM group_memberships gm
+WHERE gm.group_id = $1
+  AND gm.user_id != $2
+  AND gm.status = 'accepted'
+ORDER BY
+    CASE gm.role WHEN 'admin' THEN 0 WHEN 'moderator' THEN 1 ELSE 2 END,
+    gm.approval_time NULLS LAST,
+    gm.id
+LIMIT 1;
+
+-- name: PromoteGroupMemberToAdmin :exec
+UPDATE group_memberships
+SET role = 'admin', updated_at = NOW()
+WHERE group_id = $1 AND user_id = $2 AND status = 'accepted';
+
+-- name: HandOverGroupOwnership :exec
+-- Used when the owner leaves, unlike TransferGroupOwnership the new owner is not checked
+UPDATE groups
+SET creator_user_id = $2, updated_at = NOW(), version = version + 1
+WHERE id = $1;
+
+-- name: GetGroupExportMembers :many
+SELECT gm.user_id, u.first_name, u.last_name, gm.role, gm.approval_time
+FROM group_memberships gm
+JOIN users u ON u.id = gm.user_id
+WHERE gm.group_id = $1 AND gm.status = 'accepted'
+ORDER BY gm.approval_time NULLS LAST, gm.id;
+
+-- name: GetGroupExportGoals :many
+SELECT id, group_id, creator_user_id, goal_name, target_amount, current_amount, start_date, deadline, description, status, created_at, updated_at
+FROM group_goals
+WHERE group_id = $1
+ORDER BY created_at, id;
+
+-- name: GetGroupExportTransactions :many
+SELECT id, goal_id, member_id, amount, description, created_at, updated_at, transaction_type, group_id, recipient_member_id
+FROM group_transactions
+WHERE group_id = $1
+ORDER BY created_at, id;
+
+-- name: GetGroupExportExpenses :many
+SELECT id, group_id, member_id, amount, description, category, created_at, updated_at, split_type
+FROM group_expenses
+WHERE group_id = $1
+ORDER BY created_at, id;
+
+-- name: GetGroupExportExpenseSplits :many
+-- What each member owes of the group's split expenses, settlements are made against these
+SELECT ges.expense_id, ges.member_id, ges.share, ges.amount
+FROM group_expense_splits ges
+JOIN group_expenses ge ON ge.id = ges.expense_id
+WHERE ge.group_id = $1
+ORDER BY ges.expense_id, ges.member_id;
+
+-- name: GetGroupExportContributionDues :many
+SELECT id, schedule_id, group_id, goal_id, member_id, amount, due_date, status, paid_at, reminded_at, created_at, amount_paid
+FROM group_contribution_dues
+WHERE group_id = $1
+ORDER BY due_date, member_id, id;
+
+-- name: GetGroupExportComments :many
+-- Comments left on the group, they are not tied to it by a foreign key
+SELECT id, content, user_id, parent_id, associated_type, associated_id, created_at, updated_at, version
+FROM comments
+WHERE associated_type = 'group' AND associated_id = $1
+ORDER BY created_at, id;
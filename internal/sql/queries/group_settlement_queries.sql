@@ -0,0 +1,55 @@
+-- name: GetAcceptedGroupMemberIDs :many
+SELECT user_id
+FROM group_memberships
+WHERE group_id = $1 AND status = 'accepted'
+ORDER BY user_id;
+
+-- name: SaveGroupExpenseSplit :execrows
+WITH removed_splits AS (
+    DELETE FROM group_expense_splits
+    WHERE expense_id = $1 AND member_id <> ALL($3::BIGINT[])
+),
+split_expense AS (
+    UPDATE group_expenses
+    SET split_type = $2
+    WHERE id = $1
+)
+INSERT INTO group_expense_splits (expense_id, member_id, share, amount)
+SELECT $1, UNNEST($3::BIGINT[]), UNNEST($4::NUMERIC[]), UNNEST($5::NUMERIC[])
+ON CONFLICT (expense_id, member_id) DO UPDATE
+SET share = EXCLUDED.share, amount = EXCLUDED.amount;
+
+-- name: GetGroupExpenseSplitsByGroupID :many
+SELECT
+    ge.id AS expense_id,
+    ge.member_id AS paid_by,
+    ges.member_id,
+    ges.amount
+FROM group_expense_splits ges
+JOIN group_expenses ge ON ge.id = ges.expense_id
+WHERE ge.group_id = $1
+ORDER BY ge.id, ges.member_id;
+
+-- name: CreateNewGroupSettlement :one
+INSERT INTO group_transactions (
+    group_id,
+    member_id,
+    recipient_member_id,
+    amount,
+    description,
+    transaction_type
+) VALUES ($1, $2, $3, $4, $5, 'settlement')
+RETURNING id, created_at, updated_at;
+
+-- name: GetGroupSettlementsByGroupID :many
+SELECT
+    id,
+    group_id,
+    member_id,
+    recipient_member_id,
+    amount,
+    description,
+    created_at
+FROM group_transactions
+WHERE group_id = $1 AND transaction_type = 'settlement'
+ORDER BY created_at DESC, id DESC;
@@ -420,9 +420,10 @@ INSERT INTO investment_transactions (
     transaction_type,
     transaction_date,
     transaction_amount,
-    quantity
+    quantity,
+    account_id
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING id, created_at, updated_at;
 
 -- name: DeleteInvestmentTransactionByID :one
@@ -0,0 +1,57 @@
+-- name: GetNetWorthComponentsByUserID :one
+SELECT
+    COALESCE(u.currency_code, '')::VARCHAR(3) AS currency_code,
+    (SELECT COALESCE(SUM(s.quantity * CASE WHEN s.current_value > 0 THEN s.current_value ELSE s.purchase_price END), 0)
+     FROM stock_investments s
+     WHERE s.user_id = u.id)::NUMERIC AS stocks_value,
+    (SELECT COALESCE(SUM(b.quantity * CASE WHEN b.current_value > 0 THEN b.current_value ELSE b.purchase_price END), 0)
+     FROM bond_investments b
+     WHERE b.user_id = u.id)::NUMERIC AS bonds_value,
+    (SELECT COALESCE(SUM(a.valuation), 0)
+     FROM alternative_investments a
+     WHERE a.user_id = u.id)::NUMERIC AS alternatives_value,
+    (SELECT COALESCE(SUM(d.remaining_balance), 0)
+     FROM debts d
+     WHERE d.user_id = u.id
+     AND NOT EXISTS (SELECT 1 FROM accounts ac WHERE ac.debt_id = d.id))::NUMERIC AS debts_balance
+FROM users u
+WHERE u.id = $1;
+
+-- name: GetNetWorthSnapshotUserIDs :many
+SELECT user_id FROM stock_investments
+UNION
+SELECT user_id FROM bond_investments
+UNION
+SELECT user_id FROM alternative_investments
+UNION
+SELECT user_id FROM debts
+UNION
+SELECT user_id FROM accounts;
+
+-- name: UpsertNetWorthSnapshot :one
+INSERT INTO net_worth_snapshots (
+    user_id, snapshot_date, currency_code, stocks_value, bonds_value, alternatives_value,
+    total_assets, debts_balance, total_liabilities, net_worth, accounts_value, accounts_owed
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (user_id, snapshot_date) DO UPDATE SET
+    currency_code = EXCLUDED.currency_code,
+    stocks_value = EXCLUDED.stocks_value,
+    bonds_value = EXCLUDED.bonds_value,
+    alternatives_value = EXCLUDED.alternatives_value,
+    total_assets = EXCLUDED.total_assets,
+    debts_balance = EXCLUDED.debts_balance,
+    total_liabilities = EXCLUDED.total_liabilities,
+    net_worth = EXCLUDED.net_worth,
+    accounts_value = EXCLUDED.accounts_value,
+    accounts_owed = EXCLUDED.accounts_owed
+RETURNING id, created_at;
+
+-- name: GetNetWorthSnapshotsByUserID :many
+SELECT id, user_id, snapshot_date, currency_code, stocks_value, bonds_value, alternatives_value, total_assets, debts_balance, total_liabilities, net_worth, created_at, accounts_value, accounts_owed
+FROM net_worth_snapshots
+WHERE user_id = $1
+AND snapshot_date >= $2::DATE
+AND snapshot_date <= $3::DATE
+ORDER BY snapshot_date;
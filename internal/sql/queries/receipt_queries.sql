@@ -0,0 +1,59 @@
+-- name: CreateNewExpenseCategoryRule :one
+INSERT INTO expense_category_rules (
+    user_id,
+    pattern,
+    category,
+    budget_id,
+    priority
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, updated_at;
+
+-- name: GetExpenseCategoryRulesByUserID :many
+SELECT id, user_id, pattern, category, budget_id, priority, created_at, updated_at
+FROM expense_category_rules
+WHERE user_id = $1
+ORDER BY priority DESC, id ASC;
+
+-- name: DeleteExpenseCategoryRuleByID :one
+DELETE FROM expense_category_rules
+WHERE id = $1 AND user_id = $2
+RETURNING id;
+
+-- name: CreateNewReceiptDraft :one
+INSERT INTO expense_receipt_drafts (
+    user_id,
+    budget_id,
+    budget_match,
+    category_rule_id,
+    merchant,
+    category,
+    amount,
+    original_amount,
+    currency_code,
+    description,
+    date_occurred,
+    line_items,
+    storage_key,
+    file_name,
+    content_type,
+    size_bytes,
+    expires_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+RETURNING id, created_at;
+
+-- name: GetReceiptDraftByID :one
+SELECT id, user_id, budget_id, budget_match, category_rule_id, merchant, category, amount, original_amount,
+       currency_code, description, date_occurred, line_items, storage_key, file_name, content_type,
+       size_bytes, expires_at, created_at
+FROM expense_receipt_drafts
+WHERE id = $1 AND user_id = $2 AND expires_at > NOW();
+
+-- name: DeleteReceiptDraftByID :one
+DELETE FROM expense_receipt_drafts
+WHERE id = $1 AND user_id = $2
+RETURNING storage_key;
+
+-- name: DeleteExpiredReceiptDrafts :many
+DELETE FROM expense_receipt_drafts
+WHERE expires_at <= NOW()
+RETURNING storage_key;
@@ -0,0 +1,64 @@
+-- name: GetSubscriptionChargesByUserID :many
+SELECT
+    e.id,
+    e.budget_id,
+    e.name,
+    e.amount,
+    e.date_occurred
+FROM expenses e
+WHERE e.user_id = $1
+AND e.is_recurring = FALSE
+AND e.date_occurred >= $2::DATE
+ORDER BY e.date_occurred, e.id;
+
+-- name: GetRecurringExpenseNamesByUserID :many
+SELECT DISTINCT name
+FROM recurring_expenses
+WHERE user_id = $1;
+
+-- name: GetUserIDsWithExpensesSince :many
+SELECT DISTINCT user_id
+FROM expenses
+WHERE is_recurring = FALSE
+AND created_at >= $1::TIMESTAMP;
+
+-- name: UpsertDetectedSubscription :one
+INSERT INTO detected_subscriptions (
+    user_id, budget_id, merchant_key, name, amount, previous_amount, recurrence_interval,
+    interval_count, charge_count, first_charged, last_charged, next_expected
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (user_id, merchant_key) DO UPDATE
+SET budget_id = EXCLUDED.budget_id,
+    name = EXCLUDED.name,
+    amount = EXCLUDED.amount,
+    previous_amount = EXCLUDED.previous_amount,
+    recurrence_interval = EXCLUDED.recurrence_interval,
+    interval_count = EXCLUDED.interval_count,
+    charge_count = EXCLUDED.charge_count,
+    first_charged = EXCLUDED.first_charged,
+    last_charged = EXCLUDED.last_charged,
+    next_expected = EXCLUDED.next_expected,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetDetectedSubscriptionsByUserID :many
+SELECT *
+FROM detected_subscriptions
+WHERE user_id = $1
+AND ($2::TEXT = '' OR status::TEXT = $2::TEXT)
+ORDER BY next_expected, id;
+
+-- name: GetDetectedSubscriptionByID :one
+SELECT *
+FROM detected_subscriptions
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdateDetectedSubscriptionStatus :one
+UPDATE detected_subscriptions
+SET status = $3,
+    recurring_expense_id = $4,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
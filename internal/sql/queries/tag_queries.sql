@@ -0,0 +1,112 @@
+-- name: CreateTagsIfNotExist :exec
+INSERT INTO tags (user_id, name)
+SELECT $1::BIGINT, UNNEST($2::TEXT[])
+ON CONFLICT (user_id, name) DO NOTHING;
+
+-- name: GetTagsByUserID :many
+SELECT
+    t.id,
+    t.user_id,
+    t.name,
+    t.created_at,
+    (SELECT COUNT(*) FROM expense_tags et WHERE et.tag_id = t.id) AS expense_count,
+    (SELECT COUNT(*) FROM income_tags it WHERE it.tag_id = t.id) AS income_count,
+    (SELECT COUNT(*) FROM group_expense_tags gt WHERE gt.tag_id = t.id) AS group_expense_count
+FROM tags t
+WHERE t.user_id = $1
+ORDER BY t.name ASC;
+
+-- name: DeleteTagByID :one
+DELETE FROM tags
+WHERE id = $1 AND user_id = $2
+RETURNING id;
+
+-- name: AddTagsToExpenses :execrows
+INSERT INTO expense_tags (expense_id, tag_id)
+SELECT e.id, t.id
+FROM expenses e
+JOIN tags t ON t.user_id = e.user_id
+WHERE e.user_id = $1
+AND e.id = ANY($2::BIGINT[])
+AND t.name = ANY($3::TEXT[])
+ON CONFLICT DO NOTHING;
+
+-- name: AddTagsToIncomes :execrows
+INSERT INTO income_tags (income_id, tag_id)
+SELECT i.id, t.id
+FROM income i
+JOIN tags t ON t.user_id = i.user_id
+WHERE i.user_id = $1
+AND i.id = ANY($2::BIGINT[])
+AND t.name = ANY($3::TEXT[])
+ON CONFLICT DO NOTHING;
+
+-- name: AddTagsToGroupExpenses :execrows
+INSERT INTO group_expense_tags (group_expense_id, tag_id)
+SELECT ge.id, t.id
+FROM group_expenses ge
+JOIN group_memberships gm ON gm.group_id = ge.group_id AND gm.status = 'accepted'
+JOIN tags t ON t.user_id = gm.user_id
+WHERE t.user_id = $1
+AND ge.id = ANY($2::BIGINT[])
+AND t.name = ANY($3::TEXT[])
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTagsFromExpenses :execrows
+DELETE FROM expense_tags et
+USING tags t
+WHERE et.tag_id = t.id
+AND t.user_id = $1
+AND et.expense_id = ANY($2::BIGINT[])
+AND t.name = ANY($3::TEXT[]);
+
+-- name: RemoveTagsFromIncomes :execrows
+DELETE FROM income_tags it
+USING tags t
+WHERE it.tag_id = t.id
+AND t.user_id = $1
+AND it.income_id = ANY($2::BIGINT[])
+AND t.name = ANY($3::TEXT[]);
+
+-- name: RemoveTagsFromGroupExpenses :execrows
+DELETE FROM group_expense_tags gt
+USING tags t
+WHERE gt.tag_id = t.id
+AND t.user_id = $1
+AND gt.group_expense_id = ANY($2::BIGINT[])
+AND t.name = ANY($3::TEXT[]);
+
+-- name: GetTagSpendingReportByUserID :many
+SELECT
+    t.id,
+    t.name,
+    COALESCE(ex.total_expenses, 0)::NUMERIC AS total_expenses,
+    COALESCE(ex.expense_count, 0)::BIGINT AS expense_count,
+    COALESCE(gex.total_group_expenses, 0)::NUMERIC AS total_group_expenses,
+    COALESCE(gex.group_expense_count, 0)::BIGINT AS group_expense_count,
+    COALESCE(inc.total_income, 0)::NUMERIC AS total_income,
+    COALESCE(inc.income_count, 0)::BIGINT AS income_count
+FROM tags t
+LEFT JOIN LATERAL (
+    SELECT SUM(e.amount) AS total_expenses, COUNT(*) AS expense_count
+    FROM expense_tags et
+    JOIN expenses e ON e.id = et.expense_id
+    WHERE et.tag_id = t.id
+    AND e.date_occurred BETWEEN $2::DATE AND $3::DATE
+) ex ON TRUE
+LEFT JOIN LATERAL (
+    SELECT SUM(ge.amount) AS total_group_expenses, COUNT(*) AS group_expense_count
+    FROM group_expense_tags gt
+    JOIN group_expenses ge ON ge.id = gt.group_expense_id
+    WHERE gt.tag_id = t.id
+    AND ge.created_at::DATE BETWEEN $2::DATE AND $3::DATE
+) gex ON TRUE
+LEFT JOIN LATERAL (
+    SELECT SUM(i.amount) AS total_income, COUNT(*) AS income_count
+    FROM income_tags it
+    JOIN income i ON i.id = it.income_id
+    WHERE it.tag_id = t.id
+    AND i.date_received BETWEEN $2::DATE AND $3::DATE
+) inc ON TRUE
+WHERE t.user_id = $1
+ORDER BY total_expenses DESC, t.name ASC;
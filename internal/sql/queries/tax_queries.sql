@@ -0,0 +1,85 @@
+-- name: SetExpenseTaxCategories :one
+WITH owned AS (
+    SELECT e.id
+    FROM expenses e
+    WHERE e.id = $1 AND e.user_id = $2
+),
+removed AS (
+    DELETE FROM expense_tax_categories etc
+    WHERE etc.expense_id IN (SELECT id FROM owned)
+    AND etc.tax_category::TEXT <> ALL($3::TEXT[])
+),
+added AS (
+    INSERT INTO expense_tax_categories (expense_id, tax_category)
+    SELECT owned.id, UNNEST($3::TEXT[])::tax_category_enum
+    FROM owned
+    ON CONFLICT DO NOTHING
+)
+SELECT id FROM owned;
+
+-- name: SetIncomeTaxCategories :one
+WITH owned AS (
+    SELECT i.id
+    FROM income i
+    WHERE i.id = $1 AND i.user_id = $2
+),
+removed AS (
+    DELETE FROM income_tax_categories itc
+    WHERE itc.income_id IN (SELECT id FROM owned)
+    AND itc.tax_category::TEXT <> ALL($3::TEXT[])
+),
+added AS (
+    INSERT INTO income_tax_categories (income_id, tax_category)
+    SELECT owned.id, UNNEST($3::TEXT[])::tax_category_enum
+    FROM owned
+    ON CONFLICT DO NOTHING
+)
+SELECT id FROM owned;
+
+-- name: GetTaxCategorizedExpensesByUserID :many
+SELECT
+    e.id,
+    e.name,
+    e.category,
+    e.amount,
+    b.currency_code,
+    e.date_occurred,
+    etc.tax_category
+FROM expense_tax_categories etc
+JOIN expenses e ON e.id = etc.expense_id
+JOIN budgets b ON b.id = e.budget_id
+WHERE e.user_id = $1
+AND e.date_occurred BETWEEN $2::DATE AND $3::DATE
+ORDER BY etc.tax_category, e.date_occurred, e.id;
+
+-- name: GetTaxCategorizedIncomesByUserID :many
+SELECT
+    i.id,
+    i.source,
+    i.amount,
+    i.date_received,
+    itc.tax_category
+FROM income_tax_categories itc
+JOIN income i ON i.id = itc.income_id
+WHERE i.user_id = $1
+AND i.date_received BETWEEN $2::DATE AND $3::DATE
+ORDER BY itc.tax_category, i.date_received, i.id;
+
+-- name: GetInvestmentTransactionsForTaxByUserID :many
+SELECT
+    it.id,
+    it.investment_type,
+    it.investment_id,
+    it.transaction_type,
+    it.transaction_date,
+    it.transaction_amount,
+    it.quantity,
+    COALESCE(si.stock_symbol, bi.bond_symbol, ai.investment_name, ai.investment_type, '')::TEXT AS investment_name,
+    COALESCE(si.purchase_price, bi.purchase_price, 0)::NUMERIC AS purchase_price
+FROM investment_transactions it
+LEFT JOIN stock_investments si ON it.investment_type = 'Stock' AND si.id = it.investment_id
+LEFT JOIN bond_investments bi ON it.investment_type = 'Bond' AND bi.id = it.investment_id
+LEFT JOIN alternative_investments ai ON it.investment_type = 'Alternative' AND ai.id = it.investment_id
+WHERE it.user_id = $1
+AND it.transaction_date <= $2::DATE
+ORDER BY it.transaction_date, it.id;
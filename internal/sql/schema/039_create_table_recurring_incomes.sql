@@ -0,0 +1,40 @@
+-- +goose Up
+-- Recurring incomes reuse the recurrence_interval_enum from the recurring expenses table
+CREATE TABLE recurring_incomes (
+    id BIGSERIAL PRIMARY KEY,                                           -- Unique ID for the recurring income
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,     -- Reference to the user
+    source VARCHAR(255) NOT NULL,                                       -- Source of the income (e.g salary, rent, dividends)
+    original_currency_code CHAR(3) NOT NULL,                            -- Currency the income is received in
+    amount_original NUMERIC(15, 2) NOT NULL CHECK (amount_original > 0), -- Amount in the original currency
+    description TEXT,                                                   -- Description of the income
+    recurrence_interval recurrence_interval_enum NOT NULL DEFAULT 'monthly', -- Interval type (e.g., daily, weekly, monthly, etc.)
+    next_occurrence DATE NOT NULL,                                      -- The next date the income should be posted
+    end_date DATE,                                                      -- Optional date after which no more incomes are posted
+    goal_id BIGINT REFERENCES goals(id) ON DELETE SET NULL,             -- Optional goal to allocate part of each posting to
+    goal_allocation_percentage NUMERIC(5, 2) NOT NULL DEFAULT 0 CHECK (goal_allocation_percentage >= 0 AND goal_allocation_percentage <= 100),
+    last_posted_at DATE,                                                -- The last date an income was posted
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),               -- Creation timestamp
+    updated_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),               -- Last updated timestamp
+    CONSTRAINT unique_recurring_income UNIQUE (user_id, source, recurrence_interval)
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_recurring_incomes_timestamp
+BEFORE UPDATE ON recurring_incomes
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- Indexes
+CREATE INDEX idx_recurring_incomes_user_id ON recurring_incomes(user_id);
+CREATE INDEX idx_recurring_incomes_goal_id ON recurring_incomes(goal_id);
+CREATE INDEX idx_recurring_incomes_next_occurrence ON recurring_incomes(next_occurrence);
+CREATE INDEX idx_recurring_incomes_created_at ON recurring_incomes(created_at);
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_update_recurring_incomes_timestamp ON recurring_incomes;
+DROP INDEX IF EXISTS idx_recurring_incomes_user_id;
+DROP INDEX IF EXISTS idx_recurring_incomes_goal_id;
+DROP INDEX IF EXISTS idx_recurring_incomes_next_occurrence;
+DROP INDEX IF EXISTS idx_recurring_incomes_created_at;
+DROP TABLE IF EXISTS recurring_incomes;
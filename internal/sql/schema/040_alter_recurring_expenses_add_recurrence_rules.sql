@@ -0,0 +1,56 @@
+-- +goose Up
+-- Recurrence rules modelled on iCalendar RRULEs. The recurrence_interval acts as the frequency,
+-- interval_count as the number of intervals between occurrences (e.g every 2 weeks) and
+-- day_of_month pins monthly and yearly rules to a specific day, clamped to the end of shorter months.
+ALTER TABLE recurring_expenses
+    ADD COLUMN interval_count INT NOT NULL DEFAULT 1 CHECK (interval_count > 0),           -- Number of intervals between occurrences
+    ADD COLUMN day_of_month SMALLINT CHECK (day_of_month BETWEEN 1 AND 31),              -- Day of the month for monthly/yearly rules
+    ADD COLUMN start_date DATE NOT NULL DEFAULT CURRENT_DATE,                             -- The original date the schedule is anchored to
+    ADD COLUMN last_occurrence DATE,                                                      -- The last occurrence that was posted or skipped
+    ADD COLUMN end_date DATE,                                                             -- Optional date after which the schedule ends
+    ADD COLUMN max_occurrences INT CHECK (max_occurrences > 0),                           -- Optional number of occurrences after which the schedule ends
+    ADD COLUMN occurrence_count INT NOT NULL DEFAULT 0,                                   -- Number of occurrences that have elapsed (posted or skipped)
+    ADD COLUMN is_paused BOOLEAN NOT NULL DEFAULT FALSE;                                  -- Paused schedules are not processed
+
+-- Anchor existing schedules to their creation date and their current day of the month
+UPDATE recurring_expenses SET start_date = COALESCE(created_at::DATE, CURRENT_DATE);
+UPDATE recurring_expenses SET day_of_month = EXTRACT(DAY FROM next_occurrence)
+WHERE recurrence_interval IN ('monthly', 'yearly');
+
+-- The first occurrence is posted on the start date rather than the next occurrence
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION insert_recurring_expense_to_expenses()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO expenses (user_id, budget_id, category, amount,name,  description,is_recurring, date_occurred, created_at, updated_at)
+    VALUES (NEW.user_id, NEW.budget_id, 'Recurring', NEW.amount, NEW.name, NEW.description, true, NEW.start_date, NOW(), NOW());
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE INDEX idx_recurring_expenses_is_paused ON recurring_expenses(is_paused);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_recurring_expenses_is_paused;
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION insert_recurring_expense_to_expenses()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO expenses (user_id, budget_id, category, amount,name,  description,is_recurring, date_occurred, created_at, updated_at)
+    VALUES (NEW.user_id, NEW.budget_id, 'Recurring', NEW.amount, NEW.name, NEW.description, true, NEW.next_occurrence, NOW(), NOW());
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+ALTER TABLE recurring_expenses
+    DROP COLUMN IF EXISTS interval_count,
+    DROP COLUMN IF EXISTS day_of_month,
+    DROP COLUMN IF EXISTS start_date,
+    DROP COLUMN IF EXISTS last_occurrence,
+    DROP COLUMN IF EXISTS end_date,
+    DROP COLUMN IF EXISTS max_occurrences,
+    DROP COLUMN IF EXISTS occurrence_count,
+    DROP COLUMN IF EXISTS is_paused;
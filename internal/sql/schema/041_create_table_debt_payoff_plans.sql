@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TYPE debt_payoff_strategy_enum AS ENUM ('snowball', 'avalanche', 'custom');
+
+-- A user's chosen debt payoff plan. Each user has a single saved plan which is replaced when a new one is chosen.
+CREATE TABLE debt_payoff_plans (
+    id BIGSERIAL PRIMARY KEY,                                               -- Unique ID for the payoff plan
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,         -- Reference to the user
+    strategy debt_payoff_strategy_enum NOT NULL DEFAULT 'avalanche',        -- Order in which extra payments are applied
+    monthly_budget NUMERIC(15, 2) NOT NULL CHECK (monthly_budget > 0),      -- Total amount paid towards debts every month
+    custom_order BIGINT[] NOT NULL DEFAULT '{}',                            -- Debt IDs in payoff order for the custom strategy
+    projected_payoff_date DATE NOT NULL,                                    -- Date the last debt is paid off at the time of saving
+    total_months INT NOT NULL DEFAULT 0,                                    -- Number of months until all debts are paid off
+    total_interest NUMERIC(15, 2) NOT NULL DEFAULT 0,                       -- Total interest paid under the plan
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),                   -- Creation timestamp
+    updated_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),                   -- Last updated timestamp
+    CONSTRAINT unique_debt_payoff_plan_per_user UNIQUE (user_id)
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_debt_payoff_plans_timestamp
+BEFORE UPDATE ON debt_payoff_plans
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trigger_update_debt_payoff_plans_timestamp ON debt_payoff_plans;
+DROP TABLE IF EXISTS debt_payoff_plans;
+DROP TYPE IF EXISTS debt_payoff_strategy_enum;
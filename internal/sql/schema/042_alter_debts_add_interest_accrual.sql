@@ -0,0 +1,42 @@
+-- +goose Up
+CREATE TYPE debt_compounding_frequency_enum AS ENUM ('daily', 'monthly');
+CREATE TYPE debt_interest_rate_type_enum AS ENUM ('apr', 'apy');
+
+-- Interest is accrued daily by a scheduled job. The interest rate is either a nominal APR or an
+-- effective APY, and compounds either daily or monthly. Monthly compounding accrues simple interest
+-- on the remaining balance which is capitalized when a payment is missed.
+ALTER TABLE debts
+    ADD COLUMN compounding_frequency debt_compounding_frequency_enum NOT NULL DEFAULT 'monthly', -- How often accrued interest compounds
+    ADD COLUMN interest_rate_type debt_interest_rate_type_enum NOT NULL DEFAULT 'apr',            -- Whether the interest rate is an APR or APY
+    ADD COLUMN late_fee NUMERIC(12, 2) NOT NULL DEFAULT 0 CHECK (late_fee >= 0),                  -- Fee charged when a payment is missed
+    ADD COLUMN grace_period_days INT NOT NULL DEFAULT 0 CHECK (grace_period_days >= 0),            -- Days after the payment date before a payment is overdue
+    ADD COLUMN last_overdue_due_date DATE;                                                        -- The last missed payment date the user was notified and charged for
+
+-- Late fees charged on missed debt payments
+CREATE TABLE debt_late_fees (
+    id BIGSERIAL PRIMARY KEY,                                           -- Unique ID for the late fee
+    debt_id BIGINT NOT NULL REFERENCES debts(id) ON DELETE CASCADE,     -- Reference to the debt
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,     -- Reference to the user
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount > 0),                  -- Fee charged
+    missed_due_date DATE NOT NULL,                                      -- The payment date that was missed
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),               -- Creation timestamp
+    CONSTRAINT unique_debt_late_fee_per_due_date UNIQUE (debt_id, missed_due_date)
+);
+
+CREATE INDEX idx_debt_late_fees_debt_id ON debt_late_fees(debt_id);
+CREATE INDEX idx_debt_late_fees_user_id ON debt_late_fees(user_id);
+CREATE INDEX idx_debts_interest_last_calculated ON debts(interest_last_calculated);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_debts_interest_last_calculated;
+DROP INDEX IF EXISTS idx_debt_late_fees_debt_id;
+DROP INDEX IF EXISTS idx_debt_late_fees_user_id;
+DROP TABLE IF EXISTS debt_late_fees;
+ALTER TABLE debts
+    DROP COLUMN IF EXISTS compounding_frequency,
+    DROP COLUMN IF EXISTS interest_rate_type,
+    DROP COLUMN IF EXISTS late_fee,
+    DROP COLUMN IF EXISTS grace_period_days,
+    DROP COLUMN IF EXISTS last_overdue_due_date;
+DROP TYPE IF EXISTS debt_compounding_frequency_enum;
+DROP TYPE IF EXISTS debt_interest_rate_type_enum;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Receipts and other files attached to an expense or a group expense. The file itself lives in blob storage,
+-- this table only keeps the key and metadata. Rows are removed together with their parent expense.
+CREATE TABLE expense_attachments (
+    id BIGSERIAL PRIMARY KEY,                                               -- Unique ID for the attachment
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,         -- User who uploaded the file
+    expense_id BIGINT REFERENCES expenses(id) ON DELETE CASCADE,            -- Personal expense the file belongs to
+    group_expense_id BIGINT REFERENCES group_expenses(id) ON DELETE CASCADE, -- Group expense the file belongs to
+    storage_key TEXT NOT NULL,                                              -- Key of the object in blob storage
+    file_name VARCHAR(255) NOT NULL,                                        -- Original name of the uploaded file
+    content_type VARCHAR(100) NOT NULL,                                     -- Detected MIME type of the file
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),                      -- Size of the file in bytes
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),                   -- Upload timestamp
+    CONSTRAINT unique_expense_attachment_storage_key UNIQUE (storage_key),
+    CONSTRAINT chk_expense_attachment_single_parent CHECK (num_nonnulls(expense_id, group_expense_id) = 1)
+);
+
+CREATE INDEX idx_expense_attachments_expense_id ON expense_attachments(expense_id);
+CREATE INDEX idx_expense_attachments_group_expense_id ON expense_attachments(group_expense_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_expense_attachments_group_expense_id;
+DROP INDEX IF EXISTS idx_expense_attachments_expense_id;
+DROP TABLE IF EXISTS expense_attachments;
@@ -0,0 +1,53 @@
+-- +goose Up
+-- User defined rules that categorize expenses created from receipts. A rule matches when its pattern
+-- is found (case-insensitively) in the merchant name or in one of the receipt's line items.
+CREATE TABLE expense_category_rules (
+    id BIGSERIAL PRIMARY KEY,                                               -- Unique ID for the rule
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,         -- Owner of the rule
+    pattern VARCHAR(255) NOT NULL,                                          -- Text matched against the merchant and line items
+    category VARCHAR(255) NOT NULL,                                         -- Category applied when the rule matches
+    budget_id BIGINT REFERENCES budgets(id) ON DELETE SET NULL,             -- Optional budget suggested when the rule matches
+    priority INT NOT NULL DEFAULT 0,                                        -- Higher priority rules are checked first
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),                   -- Creation timestamp
+    updated_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),                   -- Last updated timestamp
+    CONSTRAINT unique_expense_category_rule_pattern UNIQUE (user_id, pattern)
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_expense_category_rules_timestamp
+BEFORE UPDATE ON expense_category_rules
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- Expenses drafted from an uploaded receipt waiting for the user to confirm them. The receipt is already
+-- in blob storage and becomes an attachment of the expense once the draft is confirmed.
+CREATE TABLE expense_receipt_drafts (
+    id BIGSERIAL PRIMARY KEY,                                                   -- Unique ID for the draft
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,             -- Owner of the draft
+    budget_id BIGINT REFERENCES budgets(id) ON DELETE SET NULL,                 -- Suggested budget
+    budget_match VARCHAR(50) NOT NULL DEFAULT '',                               -- How the budget was suggested
+    category_rule_id BIGINT REFERENCES expense_category_rules(id) ON DELETE SET NULL, -- Rule that set the category
+    merchant VARCHAR(255) NOT NULL,                                             -- Merchant/store name read from the receipt
+    category VARCHAR(255) NOT NULL,                                             -- Category of the drafted expense
+    amount NUMERIC(15, 2) NOT NULL,                                             -- Amount in the suggested budget's currency
+    original_amount NUMERIC(15, 2) NOT NULL,                                    -- Total as printed on the receipt
+    currency_code VARCHAR(3) NOT NULL DEFAULT '',                               -- Currency of the receipt, empty when unknown
+    description TEXT NOT NULL DEFAULT '',                                       -- Drafted description
+    date_occurred DATE NOT NULL,                                                -- Purchase date read from the receipt
+    line_items JSONB NOT NULL DEFAULT '[]',                                     -- Parsed line items
+    storage_key TEXT NOT NULL,                                                  -- Key of the receipt in blob storage
+    file_name VARCHAR(255) NOT NULL,                                            -- Original name of the uploaded receipt
+    content_type VARCHAR(100) NOT NULL,                                         -- Detected MIME type of the receipt
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),                          -- Size of the receipt in bytes
+    expires_at TIMESTAMP(0) WITH TIME ZONE NOT NULL,                            -- Drafts are discarded after this time
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW()                        -- Creation timestamp
+);
+
+CREATE INDEX idx_expense_receipt_drafts_expires_at ON expense_receipt_drafts(expires_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_expense_receipt_drafts_expires_at;
+DROP TABLE IF EXISTS expense_receipt_drafts;
+DROP TRIGGER IF EXISTS trigger_update_expense_category_rules_timestamp ON expense_category_rules;
+DROP TABLE IF EXISTS expense_category_rules;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Free-form tags a user can put on their expenses, incomes and the group expenses they can see.
+-- Tags are owned by the user, names are stored normalized (lower case, no spaces).
+CREATE TABLE tags (
+    id BIGSERIAL PRIMARY KEY,                                       -- Unique ID for the tag
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- Owner of the tag
+    name VARCHAR(50) NOT NULL,                                      -- Normalized tag name i.e "tax-deductible"
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),           -- Creation timestamp
+    CONSTRAINT unique_user_tag_name UNIQUE (user_id, name)
+);
+
+CREATE TABLE expense_tags (
+    expense_id BIGINT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (expense_id, tag_id)
+);
+
+CREATE TABLE income_tags (
+    income_id BIGINT NOT NULL REFERENCES income(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (income_id, tag_id)
+);
+
+CREATE TABLE group_expense_tags (
+    group_expense_id BIGINT NOT NULL REFERENCES group_expenses(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (group_expense_id, tag_id)
+);
+
+-- lookups go from a tag to its records
+CREATE INDEX idx_expense_tags_tag_id ON expense_tags(tag_id);
+CREATE INDEX idx_income_tags_tag_id ON income_tags(tag_id);
+CREATE INDEX idx_group_expense_tags_tag_id ON group_expense_tags(tag_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_group_expense_tags_tag_id;
+DROP INDEX IF EXISTS idx_income_tags_tag_id;
+DROP INDEX IF EXISTS idx_expense_tags_tag_id;
+DROP TABLE IF EXISTS group_expense_tags;
+DROP TABLE IF EXISTS income_tags;
+DROP TABLE IF EXISTS expense_tags;
+DROP TABLE IF EXISTS tags;
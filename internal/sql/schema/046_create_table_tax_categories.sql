@@ -0,0 +1,29 @@
+-- +goose Up
+-- Tax categories a user can flag their expenses and incomes with for the annual tax summary.
+-- A record can carry more than one category i.e a business trip that is also deductible.
+CREATE TYPE tax_category_enum AS ENUM ('deductible', 'business', 'medical', 'charitable');
+
+CREATE TABLE expense_tax_categories (
+    expense_id BIGINT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    tax_category tax_category_enum NOT NULL,
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (expense_id, tax_category)
+);
+
+CREATE TABLE income_tax_categories (
+    income_id BIGINT NOT NULL REFERENCES income(id) ON DELETE CASCADE,
+    tax_category tax_category_enum NOT NULL,
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (income_id, tax_category)
+);
+
+-- the summary goes from a category to its records
+CREATE INDEX idx_expense_tax_categories_tax_category ON expense_tax_categories(tax_category);
+CREATE INDEX idx_income_tax_categories_tax_category ON income_tax_categories(tax_category);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_income_tax_categories_tax_category;
+DROP INDEX IF EXISTS idx_expense_tax_categories_tax_category;
+DROP TABLE IF EXISTS income_tax_categories;
+DROP TABLE IF EXISTS expense_tax_categories;
+DROP TYPE IF EXISTS tax_category_enum;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Daily exchange rates from a base currency (the API's default currency) to every other currency.
+-- Rates between two other currencies are derived from their rates to the base currency.
+CREATE TABLE exchange_rates (
+    id BIGSERIAL PRIMARY KEY,
+    base_currency CHAR(3) NOT NULL,                         -- Currency the rates are quoted from i.e "USD"
+    target_currency CHAR(3) NOT NULL,                       -- Currency the rate converts to
+    rate NUMERIC(24, 10) NOT NULL,                          -- Units of target currency for one unit of base currency
+    rate_date DATE NOT NULL,                                -- Day the rate applies to
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT unique_exchange_rate_pair_date UNIQUE (base_currency, target_currency, rate_date),
+    CONSTRAINT chk_exchange_rate_positive CHECK (rate > 0)
+);
+
+-- lookups go from a pair to the latest rate on or before a date
+CREATE INDEX idx_exchange_rates_pair_date ON exchange_rates(base_currency, target_currency, rate_date DESC);
+
+-- budgets in other currencies keep the rate they were booked at, two decimals are not enough for that
+ALTER TABLE budgets ALTER COLUMN conversion_rate TYPE NUMERIC(20, 6);
+
+-- +goose Down
+-- back to the precision the column was created with in 003_create_table_budget.sql
+ALTER TABLE budgets ALTER COLUMN conversion_rate TYPE NUMERIC(20, 2) USING ROUND(conversion_rate, 2);
+DROP INDEX IF EXISTS idx_exchange_rates_pair_date;
+DROP TABLE IF EXISTS exchange_rates;
@@ -0,0 +1,64 @@
+-- +goose Up
+-- Subscriptions detected from repeating expenses. A detection is kept per merchant so that
+-- price changes can be spotted and the user's choice (convert or dismiss) is remembered.
+CREATE TYPE subscription_status_enum AS ENUM ('active', 'converted', 'dismissed');
+CREATE TABLE detected_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    budget_id BIGINT NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,       -- Budget of the latest charge
+    merchant_key VARCHAR(255) NOT NULL,                                        -- Normalized merchant/expense name
+    name VARCHAR(255) NOT NULL,                                                -- Name of the latest charge
+    amount NUMERIC(15, 2) NOT NULL CHECK (amount > 0),                         -- Amount of the latest charge
+    previous_amount NUMERIC(15, 2),                                            -- Amount before the latest price change
+    recurrence_interval recurrence_interval_enum NOT NULL,
+    interval_count INT NOT NULL DEFAULT 1 CHECK (interval_count > 0),
+    charge_count INT NOT NULL,                                                 -- Number of charges the detection is based on
+    first_charged DATE NOT NULL,
+    last_charged DATE NOT NULL,
+    next_expected DATE NOT NULL,
+    status subscription_status_enum NOT NULL DEFAULT 'active',
+    recurring_expense_id BIGINT REFERENCES recurring_expenses(id) ON DELETE SET NULL, -- Set once converted
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT unique_detected_subscription UNIQUE (user_id, merchant_key)
+);
+
+CREATE INDEX idx_detected_subscriptions_user_id_status ON detected_subscriptions(user_id, status);
+
+-- A converted subscription starts on a charge that was already recorded, so the first
+-- occurrence is only posted when it is not in the expenses yet
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION insert_recurring_expense_to_expenses()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM expenses
+        WHERE user_id = NEW.user_id
+        AND budget_id = NEW.budget_id
+        AND name = NEW.name
+        AND date_occurred = NEW.start_date
+    ) THEN
+        INSERT INTO expenses (user_id, budget_id, category, amount,name,  description,is_recurring, date_occurred, created_at, updated_at)
+        VALUES (NEW.user_id, NEW.budget_id, 'Recurring', NEW.amount, NEW.name, NEW.description, true, NEW.start_date, NOW(), NOW());
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION insert_recurring_expense_to_expenses()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO expenses (user_id, budget_id, category, amount,name,  description,is_recurring, date_occurred, created_at, updated_at)
+    VALUES (NEW.user_id, NEW.budget_id, 'Recurring', NEW.amount, NEW.name, NEW.description, true, NEW.start_date, NOW(), NOW());
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_detected_subscriptions_user_id_status;
+DROP TABLE IF EXISTS detected_subscriptions;
+DROP TYPE IF EXISTS subscription_status_enum;
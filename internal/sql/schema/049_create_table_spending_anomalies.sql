@@ -0,0 +1,28 @@
+-- +goose Up
+-- Unusual spending found in users' expenses. The dedupe key identifies an anomaly across scans
+-- (i.e the expense it was raised for) so that the user is only alerted once about it.
+CREATE TYPE anomaly_kind_enum AS ENUM ('unusual_transaction', 'duplicate_charge', 'category_spike');
+CREATE TABLE spending_anomalies (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expense_id BIGINT REFERENCES expenses(id) ON DELETE CASCADE,      -- Flagged expense, NULL for category spikes
+    kind anomaly_kind_enum NOT NULL,
+    category VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',                              -- Name of the flagged expense
+    currency_code CHAR(3) NOT NULL,                                     -- Currency of the budget the spending was recorded in
+    amount NUMERIC(15, 2) NOT NULL,                                     -- Flagged amount or category total
+    expected_amount NUMERIC(15, 2) NOT NULL,                            -- Usual amount the spending was compared to
+    score NUMERIC(10, 2) NOT NULL DEFAULT 0,                            -- Standard deviations above the usual amount
+    occurred_on DATE NOT NULL,
+    message TEXT NOT NULL,
+    dedupe_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT unique_spending_anomaly UNIQUE (user_id, dedupe_key)
+);
+
+CREATE INDEX idx_spending_anomalies_user_id_created_at ON spending_anomalies(user_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_spending_anomalies_user_id_created_at;
+DROP TABLE IF EXISTS spending_anomalies;
+DROP TYPE IF EXISTS anomaly_kind_enum;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- A daily record of each user's net worth so that its trend can be shown.
+-- Assets are the market value of stocks and bonds plus the valuation of alternative
+-- investments, liabilities are the remaining balances of debts.
+CREATE TABLE net_worth_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    currency_code VARCHAR(3) NOT NULL DEFAULT '',                  -- User's currency when the snapshot was taken
+    stocks_value NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    bonds_value NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    alternatives_value NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    total_assets NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    debts_balance NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    total_liabilities NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    net_worth NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT unique_net_worth_snapshot_per_day UNIQUE (user_id, snapshot_date)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS net_worth_snapshots;
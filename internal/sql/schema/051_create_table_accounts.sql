@@ -0,0 +1,91 @@
+-- +goose Up
+-- Accounts the user's money sits in. Expenses, incomes, debt payments and investment transactions
+-- can reference the account they were paid from or into, which gives every account a running balance.
+-- A transaction is posted in the account's currency and is cleared once it shows on a statement.
+CREATE TYPE account_type_enum AS ENUM ('checking', 'savings', 'credit_card', 'cash', 'brokerage');
+
+CREATE TABLE accounts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    account_type account_type_enum NOT NULL,
+    currency_code VARCHAR(3) NOT NULL,
+    opening_balance NUMERIC(15, 2) NOT NULL DEFAULT 0,     -- Negative for a credit card that starts with debt
+    opening_date DATE NOT NULL,
+    reconciled_balance NUMERIC(15, 2),                     -- Statement balance of the last reconciliation
+    reconciled_at DATE,                                    -- Statement date of the last reconciliation
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT unique_account_name UNIQUE (user_id, name)
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_accounts_timestamp
+BEFORE UPDATE ON accounts
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- Money moved between two of the user's accounts. The amount leaves the source account and
+-- to_amount arrives in the destination, they only differ when the currencies do
+CREATE TABLE account_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    to_account_id BIGINT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    amount NUMERIC(15, 2) NOT NULL CHECK (amount > 0),
+    to_amount NUMERIC(15, 2) NOT NULL CHECK (to_amount > 0),
+    exchange_rate NUMERIC(15, 6) NOT NULL DEFAULT 1,
+    description TEXT,
+    transfer_date DATE NOT NULL,
+    from_cleared BOOLEAN NOT NULL DEFAULT FALSE,
+    to_cleared BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT chk_transfer_accounts CHECK (from_account_id <> to_account_id)
+);
+
+CREATE INDEX idx_account_transfers_from_account_id ON account_transfers(from_account_id);
+CREATE INDEX idx_account_transfers_to_account_id ON account_transfers(to_account_id);
+
+-- Transactions keep their history when their account is deleted
+ALTER TABLE expenses
+    ADD COLUMN account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL,
+    ADD COLUMN is_cleared BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE income
+    ADD COLUMN account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL,
+    ADD COLUMN is_cleared BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE debtpayments
+    ADD COLUMN account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL,
+    ADD COLUMN is_cleared BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE investment_transactions
+    ADD COLUMN account_id BIGINT REFERENCES accounts(id) ON DELETE SET NULL,
+    ADD COLUMN is_cleared BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_expenses_account_id ON expenses(account_id);
+CREATE INDEX idx_incomes_account_id ON income(account_id);
+CREATE INDEX idx_debt_payments_account_id ON debtpayments(account_id);
+CREATE INDEX idx_investment_transactions_account_id ON investment_transactions(account_id);
+
+-- Positive account balances are assets, overdrawn accounts and credit card debt are liabilities
+ALTER TABLE net_worth_snapshots
+    ADD COLUMN accounts_value NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN accounts_owed NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE net_worth_snapshots
+    DROP COLUMN IF EXISTS accounts_owed,
+    DROP COLUMN IF EXISTS accounts_value;
+DROP INDEX IF EXISTS idx_investment_transactions_account_id;
+DROP INDEX IF EXISTS idx_debt_payments_account_id;
+DROP INDEX IF EXISTS idx_incomes_account_id;
+DROP INDEX IF EXISTS idx_expenses_account_id;
+ALTER TABLE investment_transactions DROP COLUMN IF EXISTS is_cleared, DROP COLUMN IF EXISTS account_id;
+ALTER TABLE debtpayments DROP COLUMN IF EXISTS is_cleared, DROP COLUMN IF EXISTS account_id;
+ALTER TABLE income DROP COLUMN IF EXISTS is_cleared, DROP COLUMN IF EXISTS account_id;
+ALTER TABLE expenses DROP COLUMN IF EXISTS is_cleared, DROP COLUMN IF EXISTS account_id;
+DROP INDEX IF EXISTS idx_account_transfers_to_account_id;
+DROP INDEX IF EXISTS idx_account_transfers_from_account_id;
+DROP TABLE IF EXISTS account_transfers;
+DROP TRIGGER IF EXISTS trigger_update_accounts_timestamp ON accounts;
+DROP TABLE IF EXISTS accounts;
+DROP TYPE IF EXISTS account_type_enum;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- How a group expense is shared between the members. The member who paid is owed each share,
+-- every member in the split owes their own share. Expenses without a split are not shared
+CREATE TYPE group_split_type_enum AS ENUM ('equal', 'shares', 'exact', 'percentage');
+
+ALTER TABLE group_expenses ADD COLUMN split_type group_split_type_enum;
+
+CREATE TABLE group_expense_splits (
+    id BIGSERIAL PRIMARY KEY,
+    expense_id BIGINT NOT NULL REFERENCES group_expenses(id) ON DELETE CASCADE,
+    member_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    share NUMERIC(12, 4) NOT NULL CHECK (share >= 0),         -- Shares, exact amount or percentage as entered, 1 for an equal split
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),       -- The member's part of the expense
+    created_at TIMESTAMP(0) WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT unique_group_expense_split_member UNIQUE (expense_id, member_id)
+);
+
+CREATE INDEX idx_group_expense_splits_member_id ON group_expense_splits(member_id);
+
+-- Settlements are group transactions from one member to another outside of any goal.
+-- Transactions now also carry their group so settlements can be found without a goal
+CREATE TYPE group_transaction_type_enum AS ENUM ('contribution', 'settlement');
+
+ALTER TABLE group_transactions
+    ADD COLUMN transaction_type group_transaction_type_enum NOT NULL DEFAULT 'contribution',
+    ADD COLUMN group_id BIGINT REFERENCES groups(id) ON DELETE CASCADE,
+    ADD COLUMN recipient_member_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT chk_group_settlement CHECK (
+        transaction_type <> 'settlement'
+        OR (group_id IS NOT NULL AND recipient_member_id IS NOT NULL AND recipient_member_id <> member_id)
+    );
+
+UPDATE group_transactions gt
+SET group_id = gg.group_id
+FROM group_goals gg
+WHERE gt.goal_id = gg.id;
+
+CREATE INDEX idx_group_transactions_group_id_type ON group_transactions(group_id, transaction_type);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_group_transactions_group_id_type;
+DELETE FROM group_transactions WHERE transaction_type = 'settlement';
+ALTER TABLE group_transactions
+    DROP CONSTRAINT IF EXISTS chk_group_settlement,
+    DROP COLUMN IF EXISTS recipient_member_id,
+    DROP COLUMN IF EXISTS group_id,
+    DROP COLUMN IF EXISTS transaction_type;
+DROP TYPE IF EXISTS group_transaction_type_enum;
+DROP INDEX IF EXISTS idx_group_expense_splits_member_id;
+DROP TABLE IF EXISTS group_expense_splits;
+ALTER TABLE group_expenses DROP COLUMN IF EXISTS split_type;
+DROP TYPE IF EXISTS group_split_type_enum;
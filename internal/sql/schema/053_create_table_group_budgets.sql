@@ -0,0 +1,36 @@
+-- +goose Up
+-- Group budgets cap what a group spends per expense category over a repeating period.
+-- Spending is the total of the group's expenses in the category since the period started
+CREATE TYPE group_budget_period_enum AS ENUM ('weekly', 'monthly', 'quarterly', 'yearly');
+
+CREATE TABLE group_budgets (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    creator_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    category VARCHAR(100) NOT NULL,                                   -- Matched against group expense categories, ignoring case
+    limit_amount NUMERIC(12, 2) NOT NULL CHECK (limit_amount > 0),    -- Most the group can spend in the category each period
+    period group_budget_period_enum NOT NULL DEFAULT 'monthly',
+    is_strict BOOLEAN NOT NULL DEFAULT FALSE,                         -- Strict budgets reject expenses over the limit, others only warn
+    description TEXT,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_group_budgets_timestamp
+BEFORE UPDATE ON group_budgets
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+CREATE UNIQUE INDEX idx_group_budgets_group_id_category ON group_budgets(group_id, LOWER(category));
+CREATE INDEX idx_group_expenses_group_id_created_at ON group_expenses(group_id, created_at);
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_update_group_budgets_timestamp ON group_budgets;
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_group_expenses_group_id_created_at;
+DROP INDEX IF EXISTS idx_group_budgets_group_id_category;
+DROP TABLE IF EXISTS group_budgets;
+DROP TYPE IF EXISTS group_budget_period_enum;
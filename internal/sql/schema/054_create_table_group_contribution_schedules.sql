@@ -0,0 +1,108 @@
+-- +goose Up
+-- Recurring contribution schedules (member dues) for group goals. Every time a schedule falls due each
+-- accepted member of the group gets a pending due, which is paid by contributing to the goal.
+-- Schedules reuse the recurrence_interval_enum from the recurring expenses table
+CREATE TYPE group_due_status_enum AS ENUM ('pending', 'paid');
+
+CREATE TABLE group_contribution_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    goal_id BIGINT NOT NULL REFERENCES group_goals(id) ON DELETE CASCADE,
+    creator_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    amount_per_member NUMERIC(12, 2) NOT NULL CHECK (amount_per_member > 0),  -- What each member owes every time the schedule falls due
+    recurrence_interval recurrence_interval_enum NOT NULL DEFAULT 'monthly',
+    due_day INT NOT NULL DEFAULT 0 CHECK (due_day BETWEEN 0 AND 31),          -- Day of the month dues fall on for monthly and yearly schedules
+    next_due_date DATE NOT NULL,                                              -- The next date dues are created for
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,                                  -- Paused schedules create no dues
+    description TEXT,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT unique_group_contribution_schedule_goal UNIQUE (goal_id)
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_group_contribution_schedules_timestamp
+BEFORE UPDATE ON group_contribution_schedules
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+CREATE INDEX idx_group_contribution_schedules_group_id ON group_contribution_schedules(group_id);
+CREATE INDEX idx_group_contribution_schedules_next_due_date ON group_contribution_schedules(next_due_date) WHERE is_active;
+
+CREATE TABLE group_contribution_dues (
+    id BIGSERIAL PRIMARY KEY,
+    schedule_id BIGINT NOT NULL REFERENCES group_contribution_schedules(id) ON DELETE CASCADE,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    goal_id BIGINT NOT NULL REFERENCES group_goals(id) ON DELETE CASCADE,
+    member_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount > 0),
+    due_date DATE NOT NULL,
+    status group_due_status_enum NOT NULL DEFAULT 'pending',
+    transaction_id BIGINT REFERENCES group_transactions(id) ON DELETE SET NULL,  -- The contribution that paid the due
+    paid_at TIMESTAMP(0) WITH TIME ZONE,
+    reminded_at TIMESTAMP(0) WITH TIME ZONE,                                     -- When the member was last told about the due
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT unique_group_contribution_due UNIQUE (schedule_id, member_id, due_date)
+);
+
+CREATE INDEX idx_group_contribution_dues_goal_id_member_id_status ON group_contribution_dues(goal_id, member_id, status);
+CREATE INDEX idx_group_contribution_dues_group_id_status ON group_contribution_dues(group_id, status);
+
+-- A contribution to a goal pays off the member's oldest pending dues for the goal, as many as it fully covers
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION pay_group_contribution_dues()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE group_contribution_dues d
+    SET status = 'paid', transaction_id = NEW.id, paid_at = NOW()
+    FROM (
+        SELECT id, SUM(amount) OVER (ORDER BY due_date, id) AS running_total
+        FROM group_contribution_dues
+        WHERE goal_id = NEW.goal_id AND member_id = NEW.member_id AND status = 'pending'
+    ) pending
+    WHERE d.id = pending.id AND pending.running_total <= NEW.amount;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_pay_group_contribution_dues
+AFTER INSERT ON group_transactions
+FOR EACH ROW
+WHEN (NEW.transaction_type = 'contribution' AND NEW.goal_id IS NOT NULL)
+EXECUTE FUNCTION pay_group_contribution_dues();
+-- +goose StatementEnd
+
+-- Deleting a contribution opens the dues it paid again
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION reopen_group_contribution_dues()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE group_contribution_dues
+    SET status = 'pending', transaction_id = NULL, paid_at = NULL
+    WHERE transaction_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_reopen_group_contribution_dues
+BEFORE DELETE ON group_transactions
+FOR EACH ROW
+EXECUTE FUNCTION reopen_group_contribution_dues();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_reopen_group_contribution_dues ON group_transactions;
+DROP FUNCTION IF EXISTS reopen_group_contribution_dues();
+DROP TRIGGER IF EXISTS trigger_pay_group_contribution_dues ON group_transactions;
+DROP FUNCTION IF EXISTS pay_group_contribution_dues();
+DROP TRIGGER IF EXISTS trigger_update_group_contribution_schedules_timestamp ON group_contribution_schedules;
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_group_contribution_dues_group_id_status;
+DROP INDEX IF EXISTS idx_group_contribution_dues_goal_id_member_id_status;
+DROP TABLE IF EXISTS group_contribution_dues;
+DROP INDEX IF EXISTS idx_group_contribution_schedules_next_due_date;
+DROP INDEX IF EXISTS idx_group_contribution_schedules_group_id;
+DROP TABLE IF EXISTS group_contribution_schedules;
+DROP TYPE IF EXISTS group_due_status_enum;
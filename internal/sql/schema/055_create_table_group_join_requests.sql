@@ -0,0 +1,39 @@
+-- +goose Up
+-- Discoverable private groups show up in the public group listing but cannot be joined directly.
+-- Users ask to join them instead and the group's admins and moderators approve or reject the request
+ALTER TABLE groups ADD COLUMN is_discoverable BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TYPE group_join_request_status_enum AS ENUM ('pending', 'approved', 'rejected');
+
+CREATE TABLE group_join_requests (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT,                                                     -- Optional note from the user to the group's admins
+    status group_join_request_status_enum NOT NULL DEFAULT 'pending',
+    reviewer_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,  -- Admin or moderator who approved or rejected the request
+    reviewed_at TIMESTAMP(0) WITH TIME ZONE,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_group_join_requests_timestamp
+BEFORE UPDATE ON group_join_requests
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- A user can only have one request waiting on a group at a time
+CREATE UNIQUE INDEX idx_group_join_requests_pending ON group_join_requests(group_id, user_id) WHERE status = 'pending';
+CREATE INDEX idx_group_join_requests_group_id_status ON group_join_requests(group_id, status);
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_update_group_join_requests_timestamp ON group_join_requests;
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_group_join_requests_group_id_status;
+DROP INDEX IF EXISTS idx_group_join_requests_pending;
+DROP TABLE IF EXISTS group_join_requests;
+DROP TYPE IF EXISTS group_join_request_status_enum;
+ALTER TABLE groups DROP COLUMN IF EXISTS is_discoverable;
@@ -0,0 +1,32 @@
+-- +goose Up
+-- Invite links let group admins invite people without knowing their email. Only the hash of a
+-- link's token is kept, the plaintext is shown to the admin once when the link is made
+CREATE TABLE group_invite_links (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    creator_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    token_hash BYTEA NOT NULL UNIQUE,                                  -- SHA-256 of the plaintext token
+    role membership_role NOT NULL DEFAULT 'member',                    -- Role given to members who join through the link
+    max_uses INTEGER NOT NULL CHECK (max_uses > 0),
+    use_count INTEGER NOT NULL DEFAULT 0 CHECK (use_count >= 0),
+    expires_at TIMESTAMP(0) WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP(0) WITH TIME ZONE,
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_group_invite_links_timestamp
+BEFORE UPDATE ON group_invite_links
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+CREATE INDEX idx_group_invite_links_group_id ON group_invite_links(group_id);
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_update_group_invite_links_timestamp ON group_invite_links;
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_group_invite_links_group_id;
+DROP TABLE IF EXISTS group_invite_links;
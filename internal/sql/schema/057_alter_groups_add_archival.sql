@@ -0,0 +1,12 @@
+-- +goose Up
+-- Archived groups are read-only and no longer listed publicly. Deleting a group archives it and
+-- schedules it to be purged, giving the members time to export the group's data first
+ALTER TABLE groups ADD COLUMN archived_at TIMESTAMP(0) WITH TIME ZONE;
+ALTER TABLE groups ADD COLUMN deletion_scheduled_at TIMESTAMP(0) WITH TIME ZONE;
+
+CREATE INDEX idx_groups_deletion_scheduled_at ON groups(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_groups_deletion_scheduled_at;
+ALTER TABLE groups DROP COLUMN IF EXISTS deletion_scheduled_at;
+ALTER TABLE groups DROP COLUMN IF EXISTS archived_at;
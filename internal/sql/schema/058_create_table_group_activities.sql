@@ -0,0 +1,50 @@
+-- +goose Up
+-- The activity log behind a group's timeline. Each row is something a member did in the group,
+-- subject_id points at what it was done to: the member, goal, transaction, expense or comment
+CREATE TYPE group_activity_type_enum AS ENUM (
+    'member_joined',
+    'member_left',
+    'member_removed',
+    'role_changed',
+    'goal_created',
+    'contribution',
+    'expense',
+    'comment'
+);
+
+CREATE TABLE group_activities (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,   -- Member who did it
+    activity_type group_activity_type_enum NOT NULL,
+    subject_id BIGINT,                                                -- What it was done to, if anything
+    description TEXT NOT NULL,                                        -- e.g. "contributed 50.00 to Holiday"
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- activity_count and last_activity_at now follow the activity log rather than new memberships,
+-- which are logged as activity themselves once accepted
+DROP TRIGGER IF EXISTS trigger_update_group_activity_on_new_membership ON group_memberships;
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_group_activity_on_new_activity
+AFTER INSERT ON group_activities
+FOR EACH ROW
+EXECUTE FUNCTION update_group_activity();
+-- +goose StatementEnd
+
+CREATE INDEX idx_group_activities_group_id_created_at ON group_activities(group_id, created_at DESC);
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_update_group_activity_on_new_activity ON group_activities;
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_group_activity_on_new_membership
+AFTER INSERT ON group_memberships
+FOR EACH ROW
+EXECUTE FUNCTION update_group_activity();
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_group_activities_group_id_created_at;
+DROP TABLE IF EXISTS group_activities;
+DROP TYPE IF EXISTS group_activity_type_enum;
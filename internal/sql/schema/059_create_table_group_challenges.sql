@@ -0,0 +1,63 @@
+-- +goose Up
+-- Time-boxed challenges inside a group that members opt into. Savings challenges are won by
+-- contributing target_amount between starts_on and ends_on, counting group contributions and
+-- contributions to a personal goal the participant links. No-spend challenges are won by not
+-- adding a group expense in that time, or only at weekends when weekends_only is set
+CREATE TYPE group_challenge_type_enum AS ENUM ('savings', 'no_spend');
+CREATE TYPE group_challenge_status_enum AS ENUM ('active', 'ended');
+
+CREATE TABLE group_challenges (
+    id BIGSERIAL PRIMARY KEY,
+    group_id BIGINT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    creator_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    challenge_type group_challenge_type_enum NOT NULL,
+    target_amount NUMERIC(12, 2),                                     -- Savings challenges only
+    weekends_only BOOLEAN NOT NULL DEFAULT FALSE,                     -- No-spend challenges only
+    starts_on DATE NOT NULL,
+    ends_on DATE NOT NULL,
+    status group_challenge_status_enum NOT NULL DEFAULT 'active',     -- Ended once ends_on has passed and the results are in
+    created_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT group_challenge_dates CHECK (ends_on >= starts_on),
+    CONSTRAINT group_challenge_target CHECK ((challenge_type = 'savings') = (target_amount IS NOT NULL AND target_amount > 0))
+);
+
+CREATE TABLE group_challenge_participants (
+    id BIGSERIAL PRIMARY KEY,
+    challenge_id BIGINT NOT NULL REFERENCES group_challenges(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    goal_id BIGINT REFERENCES goals(id) ON DELETE SET NULL,           -- Personal goal whose contributions count towards a savings challenge
+    rank INTEGER,                                                     -- Standing when last checked, used to tell participants when it changes
+    completed_at TIMESTAMP(0) WITH TIME ZONE,
+    joined_at TIMESTAMP(0) WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (challenge_id, user_id)
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER trigger_update_group_challenges_timestamp
+BEFORE UPDATE ON group_challenges
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+CREATE INDEX idx_group_challenges_group_id ON group_challenges(group_id);
+CREATE INDEX idx_group_challenges_status_starts_on ON group_challenges(status, starts_on);
+CREATE INDEX idx_group_challenge_participants_user_id ON group_challenge_participants(user_id);
+
+INSERT INTO awards (code, description, points, created_at, updated_at)
+VALUES ('group_challenge_completed', 'Awarded for completing your first group challenge.', 30, NOW(), NOW());
+
+-- +goose Down
+DELETE FROM awards WHERE code = 'group_challenge_completed';
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_update_group_challenges_timestamp ON group_challenges;
+-- +goose StatementEnd
+DROP INDEX IF EXISTS idx_group_challenge_participants_user_id;
+DROP INDEX IF EXISTS idx_group_challenges_status_starts_on;
+DROP INDEX IF EXISTS idx_group_challenges_group_id;
+DROP TABLE IF EXISTS group_challenge_participants;
+DROP TABLE IF EXISTS group_challenges;
+DROP TYPE IF EXISTS group_challenge_status_enum;
+DROP TYPE IF EXISTS group_challenge_type_enum;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Monthly and yearly incomes are anchored to a day of the month, so a salary paid on the 31st
+-- is posted on the last day of shorter months and goes back to the 31st afterwards
+ALTER TABLE recurring_incomes ADD COLUMN day_of_month INTEGER NOT NULL DEFAULT 0 CHECK (day_of_month >= 0 AND day_of_month <= 31);
+
+UPDATE recurring_incomes
+SET day_of_month = EXTRACT(DAY FROM next_occurrence)
+WHERE recurrence_interval IN ('monthly', 'yearly');
+
+-- +goose Down
+ALTER TABLE recurring_incomes DROP COLUMN IF EXISTS day_of_month;
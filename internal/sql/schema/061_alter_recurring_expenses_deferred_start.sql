@@ -0,0 +1,44 @@
+-- +goose Up
+-- Recurring expenses can start on a later date. Nothing has elapsed on such a schedule, so the
+-- insert trigger only posts the first occurrence of schedules that start straight away and the
+-- scheduler posts the rest once they are due
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION insert_recurring_expense_to_expenses()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.occurrence_count > 0 AND NOT EXISTS (
+        SELECT 1 FROM expenses
+        WHERE user_id = NEW.user_id
+        AND budget_id = NEW.budget_id
+        AND name = NEW.name
+        AND date_occurred = NEW.start_date
+    ) THEN
+        INSERT INTO expenses (user_id, budget_id, category, amount,name,  description,is_recurring, date_occurred, created_at, updated_at)
+        VALUES (NEW.user_id, NEW.budget_id, 'Recurring', NEW.amount, NEW.name, NEW.description, true, NEW.start_date, NOW(), NOW());
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION insert_recurring_expense_to_expenses()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM expenses
+        WHERE user_id = NEW.user_id
+        AND budget_id = NEW.budget_id
+        AND name = NEW.name
+        AND date_occurred = NEW.start_date
+    ) THEN
+        INSERT INTO expenses (user_id, budget_id, category, amount,name,  description,is_recurring, date_occurred, created_at, updated_at)
+        VALUES (NEW.user_id, NEW.budget_id, 'Recurring', NEW.amount, NEW.name, NEW.description, true, NEW.start_date, NOW(), NOW());
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- Recurring expenses converted from a detected subscription start on a charge that is already in the
+-- expenses. They are flagged explicitly so the insert trigger skips posting that first occurrence,
+-- instead of skipping any schedule that happens to share its name and start date with an expense
+ALTER TABLE recurring_expenses
+    ADD COLUMN first_occurrence_recorded BOOLEAN NOT NULL DEFAULT FALSE; -- The first occurrence is already an expense
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION insert_recurring_expense_to_expenses()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.occurrence_count > 0 AND NOT NEW.first_occurrence_recorded THEN
+        INSERT INTO expenses (user_id, budget_id, category, amount,name,  description,is_recurring, date_occurred, created_at, updated_at)
+        VALUES (NEW.user_id, NEW.budget_id, 'Recurring', NEW.amount, NEW.name, NEW.description, true, NEW.start_date, NOW(), NOW());
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION insert_recurring_expense_to_expenses()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.occurrence_count > 0 AND NOT EXISTS (
+        SELECT 1 FROM expenses
+        WHERE user_id = NEW.user_id
+        AND budget_id = NEW.budget_id
+        AND name = NEW.name
+        AND date_occurred = NEW.start_date
+    ) THEN
+        INSERT INTO expenses (user_id, budget_id, category, amount,name,  description,is_recurring, date_occurred, created_at, updated_at)
+        VALUES (NEW.user_id, NEW.budget_id, 'Recurring', NEW.amount, NEW.name, NEW.description, true, NEW.start_date, NOW(), NOW());
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+ALTER TABLE recurring_expenses DROP COLUMN IF EXISTS first_occurrence_recorded;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- A credit card account can be linked to the debt that tracks the same card. The account's balance
+-- already counts what is owed on the card, so net worth leaves the linked debt out
+ALTER TABLE accounts ADD COLUMN debt_id BIGINT REFERENCES debts(id) ON DELETE SET NULL;
+ALTER TABLE accounts ADD CONSTRAINT unique_account_debt UNIQUE (debt_id);
+
+-- +goose Down
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS unique_account_debt;
+ALTER TABLE accounts DROP COLUMN IF EXISTS debt_id;
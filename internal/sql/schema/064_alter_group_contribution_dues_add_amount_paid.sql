@@ -0,0 +1,131 @@
+-- +goose Up
+-- Dues are paid off by what a member has contributed to the goal in total, rather than by a single
+-- contribution covering them. Every contribution since the schedule was created counts, including the
+-- ones made before a due was created, and is allocated to the member's dues oldest first. A due is paid
+-- once its amount is allocated in full, amount_paid holds what is allocated to it so far
+ALTER TABLE group_contribution_dues ADD COLUMN amount_paid NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE group_contribution_dues ADD CONSTRAINT valid_group_contribution_due_amount_paid CHECK (amount_paid >= 0 AND amount_paid <= amount);
+
+DROP TRIGGER IF EXISTS trigger_reopen_group_contribution_dues ON group_transactions;
+DROP FUNCTION IF EXISTS reopen_group_contribution_dues();
+DROP TRIGGER IF EXISTS trigger_pay_group_contribution_dues ON group_transactions;
+DROP FUNCTION IF EXISTS pay_group_contribution_dues();
+-- no single contribution pays a due anymore
+ALTER TABLE group_contribution_dues DROP COLUMN transaction_id;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION allocate_group_contribution_dues(p_goal_id BIGINT, p_member_id BIGINT)
+RETURNS VOID AS $$
+BEGIN
+    WITH contributed AS (
+        SELECT COALESCE(SUM(gt.amount), 0) AS total
+        FROM group_transactions gt
+        JOIN group_contribution_schedules s ON s.goal_id = gt.goal_id
+        WHERE gt.goal_id = p_goal_id
+          AND gt.member_id = p_member_id
+          AND gt.transaction_type = 'contribution'
+          AND gt.created_at >= s.created_at
+    ),
+    allocation AS (
+        SELECT d.id, d.amount,
+               GREATEST(c.total - (SUM(d.amount) OVER (ORDER BY d.due_date, d.id) - d.amount), 0) AS available
+        FROM group_contribution_dues d
+        CROSS JOIN contributed c
+        WHERE d.goal_id = p_goal_id AND d.member_id = p_member_id
+    )
+    UPDATE group_contribution_dues d
+    SET amount_paid = LEAST(a.amount, a.available),
+        status = CASE WHEN a.available >= a.amount THEN 'paid' ELSE 'pending' END::group_due_status_enum,
+        paid_at = CASE WHEN a.available >= a.amount THEN COALESCE(d.paid_at, NOW()) END
+    FROM allocation a
+    WHERE d.id = a.id
+      AND (d.amount_paid <> LEAST(a.amount, a.available) OR d.status <> CASE WHEN a.available >= a.amount THEN 'paid' ELSE 'pending' END::group_due_status_enum);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Adding, changing or deleting a contribution allocates the member's contributions again
+CREATE OR REPLACE FUNCTION reallocate_group_contribution_dues()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.transaction_type = 'contribution' AND OLD.goal_id IS NOT NULL THEN
+        PERFORM allocate_group_contribution_dues(OLD.goal_id, OLD.member_id);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.transaction_type = 'contribution' AND NEW.goal_id IS NOT NULL THEN
+        PERFORM allocate_group_contribution_dues(NEW.goal_id, NEW.member_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_reallocate_group_contribution_dues
+AFTER INSERT OR UPDATE OR DELETE ON group_transactions
+FOR EACH ROW
+EXECUTE FUNCTION reallocate_group_contribution_dues();
+
+-- A new due is paid straight away from what the member contributed ahead of it
+CREATE OR REPLACE FUNCTION allocate_new_group_contribution_due()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM allocate_group_contribution_dues(NEW.goal_id, NEW.member_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_allocate_new_group_contribution_due
+AFTER INSERT ON group_contribution_dues
+FOR EACH ROW
+EXECUTE FUNCTION allocate_new_group_contribution_due();
+-- +goose StatementEnd
+
+SELECT allocate_group_contribution_dues(pairs.goal_id, pairs.member_id)
+FROM (SELECT DISTINCT goal_id, member_id FROM group_contribution_dues) pairs;
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_allocate_new_group_contribution_due ON group_contribution_dues;
+DROP FUNCTION IF EXISTS allocate_new_group_contribution_due();
+DROP TRIGGER IF EXISTS trigger_reallocate_group_contribution_dues ON group_transactions;
+DROP FUNCTION IF EXISTS reallocate_group_contribution_dues();
+DROP FUNCTION IF EXISTS allocate_group_contribution_dues(BIGINT, BIGINT);
+-- +goose StatementEnd
+ALTER TABLE group_contribution_dues ADD COLUMN transaction_id BIGINT REFERENCES group_transactions(id) ON DELETE SET NULL;
+ALTER TABLE group_contribution_dues DROP CONSTRAINT IF EXISTS valid_group_contribution_due_amount_paid;
+ALTER TABLE group_contribution_dues DROP COLUMN IF EXISTS amount_paid;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION pay_group_contribution_dues()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE group_contribution_dues d
+    SET status = 'paid', transaction_id = NEW.id, paid_at = NOW()
+    FROM (
+        SELECT id, SUM(amount) OVER (ORDER BY due_date, id) AS running_total
+        FROM group_contribution_dues
+        WHERE goal_id = NEW.goal_id AND member_id = NEW.member_id AND status = 'pending'
+    ) pending
+    WHERE d.id = pending.id AND pending.running_total <= NEW.amount;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_pay_group_contribution_dues
+AFTER INSERT ON group_transactions
+FOR EACH ROW
+WHEN (NEW.transaction_type = 'contribution' AND NEW.goal_id IS NOT NULL)
+EXECUTE FUNCTION pay_group_contribution_dues();
+
+CREATE OR REPLACE FUNCTION reopen_group_contribution_dues()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE group_contribution_dues
+    SET status = 'pending', transaction_id = NULL, paid_at = NULL
+    WHERE transaction_id = OLD.id;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_reopen_group_contribution_dues
+BEFORE DELETE ON group_transactions
+FOR EACH ROW
+EXECUTE FUNCTION reopen_group_contribution_dues();
+-- +goose StatementEnd
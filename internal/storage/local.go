@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// LocalStorage stores objects on the local filesystem under a root directory.
+// Signed URLs point back to the API (baseURL) and are authorised by an HMAC
+// of the key and the expiry time.
+type LocalStorage struct {
+	root       string
+	baseURL    string
+	signingKey []byte
+	now        func() time.Time
+}
+
+// NewLocalStorage() creates the root directory if it does not exist and returns a LocalStorage
+func NewLocalStorage(root, baseURL, signingKey string) (*LocalStorage, error) {
+	if signingKey == "" {
+		return nil, errors.New("local storage requires a signing key")
+	}
+	err := os.MkdirAll(root, 0o750)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalStorage{
+		root:       root,
+		baseURL:    baseURL,
+		signingKey: []byte(signingKey),
+		now:        time.Now,
+	}, nil
+}
+
+// objectPath() maps a key to its location on disk
+func (s *LocalStorage) objectPath(key string) (string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put() writes the object to a temporary file first and renames it into place so
+// readers never see a partially written file
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	objectPath, err := s.objectPath(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(objectPath), 0o750)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(objectPath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	// remove the temp file on any failure, after a successful rename this is a no-op
+	defer os.Remove(tmpFile.Name())
+	_, err = io.Copy(tmpFile, body)
+	if err != nil {
+		tmpFile.Close()
+		return err
+	}
+	err = tmpFile.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), objectPath)
+}
+
+// Get() opens the object for reading. The returned *os.File also implements io.ReadSeeker
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	objectPath, err := s.objectPath(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(objectPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// Delete() removes the object from disk, missing objects are ignored
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	objectPath, err := s.objectPath(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(objectPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL() returns baseURL with the key, expiry and signature as query parameters
+func (s *LocalStorage) SignedURL(key string, expiry time.Duration) (string, error) {
+	if err := validateObjectKey(key); err != nil {
+		return "", err
+	}
+	expires := strconv.FormatInt(s.now().Add(expiry).Unix(), 10)
+	query := url.Values{}
+	query.Set("key", key)
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return s.baseURL + "?" + query.Encode(), nil
+}
+
+// VerifySignedURL() checks the parameters of a URL created by SignedURL()
+// We return ErrSignedURLExpired if the link is too old and ErrInvalidSignature if
+// any of the parameters were tampered with
+func (s *LocalStorage) VerifySignedURL(key, expires, signature string) error {
+	if err := validateObjectKey(key); err != nil {
+		return ErrInvalidSignature
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(s.sign(key, expires)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	if s.now().Unix() > expiresAt {
+		return ErrSignedURLExpired
+	}
+	return nil
+}
+
+// sign() creates a hex encoded HMAC-SHA256 of the key and expiry
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
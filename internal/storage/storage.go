@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+var (
+	ErrObjectNotFound     = errors.New("object not found")
+	ErrInvalidObjectKey   = errors.New("invalid object key")
+	ErrInvalidSignature   = errors.New("invalid signature")
+	ErrSignedURLExpired   = errors.New("signed url has expired")
+	ErrUnsupportedBackend = errors.New("unsupported storage backend")
+)
+
+// Storage is the blob-storage abstraction used for user uploaded files such as
+// receipts. Keys are slash separated paths that are relative to the storage root/bucket.
+type Storage interface {
+	// Put stores the content of body under key, overwriting any existing object
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get returns a reader for the object stored under key, the caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key. Deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that can be used to download the object until expiry passes
+	SignedURL(key string, expiry time.Duration) (string, error)
+}
+
+// GenerateObjectKey() builds a unique key for a new object using the prefix, the owner's
+// ID and a random identifier. The extension is kept so downloads are served with the
+// right content type i.e receipts/12/3f9a...c1.pdf
+func GenerateObjectKey(prefix string, ownerID int64, extension string) (string, error) {
+	randomBytes := make([]byte, 16)
+	_, err := rand.Read(randomBytes)
+	if err != nil {
+		return "", err
+	}
+	if extension != "" && !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	return path.Join(prefix, fmt.Sprintf("%d", ownerID), hex.EncodeToString(randomBytes)+extension), nil
+}
+
+// validateObjectKey() makes sure a key is relative and cannot escape the storage root
+func validateObjectKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidObjectKey
+	}
+	if path.Clean(key) != key {
+		return ErrInvalidObjectKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." || segment == "." {
+			return ErrInvalidObjectKey
+		}
+	}
+	return nil
+}